@@ -69,6 +69,8 @@ type SimulatedBackend struct {
 
 	events *filters.EventSystem // Event system for filtering log events live
 
+	snapshots []uint64 // Head block numbers of the chain snapshots, the id of each being its index + 1
+
 	config *params.ChainConfig
 }
 
@@ -76,7 +78,14 @@ type SimulatedBackend struct {
 // and uses a simulated blockchain for testing purposes.
 // A simulated backend always uses chainID 1337.
 func NewSimulatedBackendWithDatabase(database ethdb.Database, alloc core.GenesisAlloc, gasLimit uint64) *SimulatedBackend {
-	genesis := core.Genesis{Config: params.AllEthashProtocolChanges, GasLimit: gasLimit, Alloc: alloc}
+	return NewSimulatedBackendWithConfig(database, params.AllEthashProtocolChanges, alloc, gasLimit)
+}
+
+// NewSimulatedBackendWithConfig creates a new binding backend based on the given
+// database and uses a simulated blockchain with the given chain configuration,
+// allowing several simulated chains with distinct chain ids to run side by side.
+func NewSimulatedBackendWithConfig(database ethdb.Database, config *params.ChainConfig, alloc core.GenesisAlloc, gasLimit uint64) *SimulatedBackend {
+	genesis := core.Genesis{Config: config, GasLimit: gasLimit, Alloc: alloc}
 	genesis.MustCommit(database)
 	blockchain, _ := core.NewBlockChain(database, nil, genesis.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
 
@@ -115,6 +124,62 @@ func (b *SimulatedBackend) Commit() {
 	b.rollback()
 }
 
+// CommitAt imports all the pending transactions as a single block with the given
+// timestamp and starts a fresh new state. The timestamp must be after the one of
+// the last committed block.
+func (b *SimulatedBackend) CommitAt(timestamp uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	parent := b.blockchain.CurrentBlock()
+	if timestamp <= parent.Time() {
+		return fmt.Errorf("timestamp %d not after parent %d", timestamp, parent.Time())
+	}
+	blocks, _ := core.GenerateChain(b.config, parent, ethash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
+		block.OffsetTime(int64(timestamp) - int64(block.Timestamp()))
+		for _, tx := range b.pendingBlock.Transactions() {
+			block.AddTxWithChain(b.blockchain, tx)
+		}
+	})
+	if _, err := b.blockchain.InsertChain(blocks); err != nil {
+		panic(err) // This cannot happen unless the simulator is wrong, fail in that case
+	}
+	b.rollback()
+	return nil
+}
+
+// Snapshot records the last committed block, for the chain to be rewound to it
+// via Revert. It returns the id of the snapshot.
+func (b *SimulatedBackend) Snapshot() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.snapshots = append(b.snapshots, b.blockchain.CurrentBlock().NumberU64())
+	return len(b.snapshots)
+}
+
+// Revert rewinds the chain to the given snapshot and aborts all pending
+// transactions. The snapshot and all the ones taken after it are discarded.
+func (b *SimulatedBackend) Revert(id int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if id <= 0 || id > len(b.snapshots) {
+		return fmt.Errorf("unknown snapshot %d", id)
+	}
+	number := b.snapshots[id-1]
+	b.snapshots = b.snapshots[:id-1]
+
+	if err := b.blockchain.SetHead(number); err != nil {
+		return err
+	}
+	if head := b.blockchain.CurrentBlock().NumberU64(); head != number {
+		return fmt.Errorf("state of block %d unavailable, rewound to %d", number, head)
+	}
+	b.rollback()
+	return nil
+}
+
 // Rollback aborts all pending transactions, reverting to the last committed state.
 func (b *SimulatedBackend) Rollback() {
 	b.mu.Lock()
@@ -560,7 +625,7 @@ func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transa
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	sender, err := types.Sender(types.NewEIP155Signer(b.config.ChainID), tx)
+	sender, err := types.Sender(types.LatestSigner(b.config), tx)
 	if err != nil {
 		panic(fmt.Errorf("invalid transaction: %v", err))
 	}
@@ -708,14 +773,17 @@ type callMsg struct {
 	ethereum.CallMsg
 }
 
-func (m callMsg) From() common.Address { return m.CallMsg.From }
-func (m callMsg) Nonce() uint64        { return 0 }
-func (m callMsg) CheckNonce() bool     { return false }
-func (m callMsg) To() *common.Address  { return m.CallMsg.To }
-func (m callMsg) GasPrice() *big.Int   { return m.CallMsg.GasPrice }
-func (m callMsg) Gas() uint64          { return m.CallMsg.Gas }
-func (m callMsg) Value() *big.Int      { return m.CallMsg.Value }
-func (m callMsg) Data() []byte         { return m.CallMsg.Data }
+func (m callMsg) From() common.Address         { return m.CallMsg.From }
+func (m callMsg) Nonce() uint64                { return 0 }
+func (m callMsg) CheckNonce() bool             { return false }
+func (m callMsg) To() *common.Address          { return m.CallMsg.To }
+func (m callMsg) GasPrice() *big.Int           { return m.CallMsg.GasPrice }
+func (m callMsg) Gas() uint64                  { return m.CallMsg.Gas }
+func (m callMsg) Value() *big.Int              { return m.CallMsg.Value }
+func (m callMsg) Data() []byte                 { return m.CallMsg.Data }
+func (m callMsg) FeePayer() common.Address     { return m.CallMsg.From }
+func (m callMsg) Anonymous() bool              { return false }
+func (m callMsg) AccessList() types.AccessList { return nil }
 
 // filterBackend implements filters.Backend to support filtering for logs without
 // taking bloom-bits acceleration structures into account.
@@ -782,6 +850,10 @@ func (fb *filterBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event
 	return nullSubscription()
 }
 
+func (fb *filterBackend) SubscribeStateDiffEvent(ch chan<- core.StateDiffEvent) event.Subscription {
+	return fb.bc.SubscribeStateDiffEvent(ch)
+}
+
 func (fb *filterBackend) BloomStatus() (uint64, uint64) { return 4096, 0 }
 
 func (fb *filterBackend) ServiceFilter(ctx context.Context, ms *bloombits.MatcherSession) {
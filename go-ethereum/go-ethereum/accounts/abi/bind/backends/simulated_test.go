@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
@@ -136,6 +137,31 @@ func TestNewSimulatedBackend(t *testing.T) {
 	}
 }
 
+func TestNewSimulatedBackendWithConfig(t *testing.T) {
+	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
+
+	config := *params.AllEthashProtocolChanges
+	config.ChainID = big.NewInt(42)
+	sim := NewSimulatedBackendWithConfig(rawdb.NewMemoryDatabase(), &config, core.GenesisAlloc{
+		testAddr: {Balance: big.NewInt(10000000000)},
+	}, 10000000)
+	defer sim.Close()
+
+	if sim.blockchain.Config().ChainID.Cmp(config.ChainID) != 0 {
+		t.Errorf("chain id mismatch: have %v, want %v", sim.blockchain.Config().ChainID, config.ChainID)
+	}
+	// Transactions are signed for the configured chain
+	tx := types.NewTransaction(0, testAddr, big.NewInt(1), params.TxGas, big.NewInt(1), nil)
+	signed, _ := types.SignTx(tx, types.NewEIP155Signer(config.ChainID), testKey)
+	if err := sim.SendTransaction(context.Background(), signed); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	sim.Commit()
+	if nonce, _ := sim.NonceAt(context.Background(), testAddr, nil); nonce != 1 {
+		t.Errorf("nonce mismatch: have %d, want 1", nonce)
+	}
+}
+
 func TestSimulatedBackend_AdjustTime(t *testing.T) {
 	sim := NewSimulatedBackend(
 		core.GenesisAlloc{}, 10000000,
@@ -191,6 +217,91 @@ func TestNewSimulatedBackend_AdjustTimeFail(t *testing.T) {
 	}
 }
 
+func TestSimulatedBackend_CommitAt(t *testing.T) {
+	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	parent := sim.blockchain.CurrentBlock()
+	if err := sim.CommitAt(parent.Time()); err == nil {
+		t.Error("expected commit at the parent timestamp to fail")
+	}
+	tx := types.NewTransaction(0, testAddr, big.NewInt(1000), params.TxGas, big.NewInt(1), nil)
+	signedTx, err := types.SignTx(tx, types.HomesteadSigner{}, testKey)
+	if err != nil {
+		t.Fatalf("could not sign tx: %v", err)
+	}
+	sim.SendTransaction(context.Background(), signedTx)
+
+	timestamp := parent.Time() + 3600
+	if err := sim.CommitAt(timestamp); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	head := sim.blockchain.CurrentBlock()
+	if head.Time() != timestamp {
+		t.Errorf("timestamp mismatch: have %d, want %d", head.Time(), timestamp)
+	}
+	if len(head.Transactions()) != 1 || head.Transactions()[0].Hash() != signedTx.Hash() {
+		t.Errorf("pending transaction not included")
+	}
+	if sim.pendingBlock.Time() <= timestamp {
+		t.Errorf("pending block not after committed one: have %d, committed %d", sim.pendingBlock.Time(), timestamp)
+	}
+}
+
+func TestSimulatedBackend_SnapshotRevert(t *testing.T) {
+	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+	bgCtx := context.Background()
+
+	send := func(nonce uint64) {
+		tx := types.NewTransaction(nonce, testAddr, big.NewInt(1000), params.TxGas, big.NewInt(1), nil)
+		signedTx, err := types.SignTx(tx, types.HomesteadSigner{}, testKey)
+		if err != nil {
+			t.Fatalf("could not sign tx: %v", err)
+		}
+		sim.SendTransaction(bgCtx, signedTx)
+	}
+	first := sim.Snapshot()
+	send(0)
+	sim.Commit()
+	second := sim.Snapshot()
+	send(1)
+	sim.Commit()
+
+	// Reverting to the second snapshot drops the last block and the snapshot
+	if err := sim.Revert(second); err != nil {
+		t.Fatalf("failed to revert: %v", err)
+	}
+	if head := sim.blockchain.CurrentBlock().NumberU64(); head != 1 {
+		t.Errorf("head mismatch: have %d, want %d", head, 1)
+	}
+	if nonce, _ := sim.NonceAt(bgCtx, testAddr, nil); nonce != 1 {
+		t.Errorf("nonce mismatch: have %d, want %d", nonce, 1)
+	}
+	if err := sim.Revert(second); err == nil {
+		t.Error("expected revert to a discarded snapshot to fail")
+	}
+	// The chain continues from the reverted head
+	send(1)
+	sim.Commit()
+	if head := sim.blockchain.CurrentBlock().NumberU64(); head != 2 {
+		t.Errorf("head mismatch: have %d, want %d", head, 2)
+	}
+	// Reverting to the first snapshot aborts the pending transactions too
+	send(2)
+	if err := sim.Revert(first); err != nil {
+		t.Fatalf("failed to revert: %v", err)
+	}
+	if head := sim.blockchain.CurrentBlock().NumberU64(); head != 0 {
+		t.Errorf("head mismatch: have %d, want %d", head, 0)
+	}
+	if nonce, _ := sim.PendingNonceAt(bgCtx, testAddr); nonce != 0 {
+		t.Errorf("pending nonce mismatch: have %d, want %d", nonce, 0)
+	}
+}
+
 func TestSimulatedBackend_BalanceAt(t *testing.T) {
 	testAddr := crypto.PubkeyToAddress(testKey.PublicKey)
 	expectedBal := big.NewInt(10000000000)
@@ -40,6 +40,8 @@ const (
 	MimetypeTypedData         = "data/typed"
 	MimetypeClique            = "application/x-clique-header"
 	MimetypeTextPlain         = "text/plain"
+	MimetypeFeePayer          = "application/x-fee-payer"
+	MimetypeChannelState      = "application/x-channel-state"
 )
 
 // Wallet represents a software or hardware wallet that might contain one or more
@@ -0,0 +1,329 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+)
+
+// PrivateChannelAPI provides access to the payment channels of the local
+// account. Updates are exchanged with connected counterparties over the xch
+// protocol. They are also returned for them to be delivered out of band to the
+// other party, which hands them to its own node via Receive.
+type PrivateChannelAPI struct {
+	s *Service
+}
+
+// NewPrivateChannelAPI creates a new payment channel API.
+func NewPrivateChannelAPI(s *Service) *PrivateChannelAPI {
+	return &PrivateChannelAPI{s: s}
+}
+
+// RPCChannel is a payment channel as reported over RPC.
+type RPCChannel struct {
+	ID           common.Hash     `json:"id"`
+	Counterparty common.Address  `json:"counterparty"`
+	PartyA       common.Address  `json:"partyA"`
+	PartyB       common.Address  `json:"partyB"`
+	DepositA     *hexutil.Big    `json:"depositA"`
+	DepositB     *hexutil.Big    `json:"depositB"`
+	Challenge    hexutil.Uint64  `json:"challenge"`
+	Status       Status          `json:"status"`
+	Deadline     hexutil.Uint64  `json:"deadline"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	BalanceA     *hexutil.Big    `json:"balanceA"`
+	BalanceB     *hexutil.Big    `json:"balanceB"`
+	Locks        []xchannel.Lock `json:"locks"`
+	Pending      *State          `json:"pending"`
+}
+
+// newRPCChannel returns the RPC representation of a channel.
+func (api *PrivateChannelAPI) newRPCChannel(c *Channel) *RPCChannel {
+	balanceA, balanceB := c.balances()
+	return &RPCChannel{
+		ID:           c.ID,
+		Counterparty: c.counterparty(api.s.account),
+		PartyA:       c.PartyA,
+		PartyB:       c.PartyB,
+		DepositA:     (*hexutil.Big)(c.DepositA),
+		DepositB:     (*hexutil.Big)(c.DepositB),
+		Challenge:    hexutil.Uint64(c.Challenge),
+		Status:       c.Status,
+		Deadline:     hexutil.Uint64(c.Deadline),
+		Nonce:        hexutil.Uint64(c.latest().Nonce),
+		BalanceA:     (*hexutil.Big)(balanceA),
+		BalanceB:     (*hexutil.Big)(balanceB),
+		Locks:        c.latest().Locks,
+		Pending:      c.Pending,
+	}
+}
+
+// Open opens a channel with a counterparty, funded with the given deposit and
+// disputes on it lasting the given number of blocks. It waits for the channel
+// to be opened on-chain and returns its id.
+func (api *PrivateChannelAPI) Open(ctx context.Context, counterparty common.Address, deposit hexutil.Big, challenge hexutil.Uint64) (common.Hash, error) {
+	tx, err := api.s.Open(ctx, counterparty, (*big.Int)(&deposit), uint64(challenge))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	receipt, err := bind.WaitMined(ctx, api.s.backend, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return api.s.opened(receipt)
+}
+
+// Deposit adds to the deposit of the local account in a channel opened by the
+// counterparty, returning the hash of the transaction.
+func (api *PrivateChannelAPI) Deposit(ctx context.Context, id common.Hash, amount hexutil.Big) (common.Hash, error) {
+	tx, err := api.s.Deposit(ctx, id, (*big.Int)(&amount))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+// Pay proposes a payment to the counterparty of a channel, returning the update
+// to be countersigned by the counterparty.
+func (api *PrivateChannelAPI) Pay(id common.Hash, amount hexutil.Big) (*State, error) {
+	return api.s.Pay(id, (*big.Int)(&amount))
+}
+
+// Receive processes an update delivered by the counterparty, returning it signed
+// by both parties.
+func (api *PrivateChannelAPI) Receive(ctx context.Context, update State) (*State, error) {
+	return api.s.Receive(ctx, &update)
+}
+
+// Reveal discloses the preimage of a hashlock pending in a channel to the
+// counterparty.
+func (api *PrivateChannelAPI) Reveal(id common.Hash, secret common.Hash) (bool, error) {
+	if err := api.s.Reveal(id, secret); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Close closes a channel. Unless forced, a cooperative close is proposed and
+// returned to be countersigned by the counterparty. Forcing the close raises a
+// dispute on the latest state instead, which is returned.
+func (api *PrivateChannelAPI) Close(ctx context.Context, id common.Hash, force *bool) (*State, error) {
+	if force != nil && *force {
+		return api.s.Dispute(ctx, id)
+	}
+	return api.s.Close(id)
+}
+
+// List returns all the channels of the local account.
+func (api *PrivateChannelAPI) List() []*RPCChannel {
+	channels := api.s.Channels()
+
+	list := make([]*RPCChannel, 0, len(channels))
+	for _, c := range channels {
+		list = append(list, api.newRPCChannel(c))
+	}
+	return list
+}
+
+// maxRoutes is the maximum number of candidate routes returned.
+const maxRoutes = 5
+
+// Invoice creates a payment request for an amount, returning the hashlock the
+// payer locks its transfer under.
+func (api *PrivateChannelAPI) Invoice(amount hexutil.Big) (common.Hash, error) {
+	return api.s.Invoice((*big.Int)(&amount))
+}
+
+// RPCRoute is a candidate route as reported over RPC.
+type RPCRoute struct {
+	Hops       Route          `json:"hops"`
+	Amount     *hexutil.Big   `json:"amount"`
+	Fee        *hexutil.Big   `json:"fee"`
+	Expiration hexutil.Uint64 `json:"expiration"`
+}
+
+// Routes returns candidate routes to pay an amount to a payee over the channel
+// graph, cheapest first, optionally charging at most the given fee.
+func (api *PrivateChannelAPI) Routes(ctx context.Context, payee common.Address, amount hexutil.Big, maxFee *hexutil.Big) ([]*RPCRoute, error) {
+	routes, err := api.s.Routes(ctx, payee, (*big.Int)(&amount), (*big.Int)(maxFee), maxRoutes)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*RPCRoute, 0, len(routes))
+	for _, route := range routes {
+		list = append(list, &RPCRoute{
+			Hops:       route,
+			Amount:     (*hexutil.Big)(route.Amount()),
+			Fee:        (*hexutil.Big)(route.Fee()),
+			Expiration: hexutil.Uint64(route[0].Expiration),
+		})
+	}
+	return list, nil
+}
+
+// RPCTransfer is a multi-hop transfer as reported over RPC.
+type RPCTransfer struct {
+	Hashlock common.Hash     `json:"hashlock"`
+	Route    Route           `json:"route"`
+	Hop      hexutil.Uint64  `json:"hop"`
+	Status   TransferStatus  `json:"status"`
+	FailedAt *common.Address `json:"failedAt"`
+	Reason   string          `json:"reason,omitempty"`
+}
+
+// newRPCTransfer returns the RPC representation of a transfer.
+func newRPCTransfer(t *Transfer) *RPCTransfer {
+	transfer := &RPCTransfer{
+		Hashlock: t.Hashlock,
+		Route:    t.Route,
+		Hop:      hexutil.Uint64(t.Hop),
+		Status:   t.Status,
+		Reason:   t.Reason,
+	}
+	if t.Status == TransferFailed {
+		node := t.FailedAt
+		transfer.FailedAt = &node
+	}
+	return transfer
+}
+
+// Send pays over a route returned by Routes, under the hashlock of an invoice of
+// the payee.
+func (api *PrivateChannelAPI) Send(route Route, hashlock common.Hash) (*RPCTransfer, error) {
+	t, err := api.s.Send(route, hashlock)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCTransfer(t), nil
+}
+
+// Transfer returns the multi-hop transfer with the given hashlock.
+func (api *PrivateChannelAPI) Transfer(hashlock common.Hash) (*RPCTransfer, error) {
+	t, err := api.s.Transfer(hashlock)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCTransfer(t), nil
+}
+
+// Transfers returns all the multi-hop transfers the local node took part in.
+func (api *PrivateChannelAPI) Transfers() []*RPCTransfer {
+	transfers := api.s.Transfers()
+
+	list := make([]*RPCTransfer, 0, len(transfers))
+	for _, t := range transfers {
+		list = append(list, newRPCTransfer(t))
+	}
+	return list
+}
+
+// RPCVirtual is a virtual channel as reported over RPC.
+type RPCVirtual struct {
+	ID        common.Hash    `json:"id"`
+	PartyA    common.Address `json:"partyA"`
+	PartyB    common.Address `json:"partyB"`
+	Hub       common.Address `json:"hub"`
+	DepositA  *hexutil.Big   `json:"depositA"`
+	DepositB  *hexutil.Big   `json:"depositB"`
+	Challenge hexutil.Uint64 `json:"challenge"`
+	LedgerA   common.Hash    `json:"ledgerA"`
+	LedgerB   common.Hash    `json:"ledgerB"`
+	Status    VirtualStatus  `json:"status"`
+	Deadline  hexutil.Uint64 `json:"deadline"`
+	Nonce     hexutil.Uint64 `json:"nonce"`
+	BalanceA  *hexutil.Big   `json:"balanceA"`
+	BalanceB  *hexutil.Big   `json:"balanceB"`
+	Pending   *State         `json:"pending"`
+	Final     *State         `json:"final"`
+}
+
+// newRPCVirtual returns the RPC representation of a virtual channel.
+func newRPCVirtual(v *Virtual) *RPCVirtual {
+	return &RPCVirtual{
+		ID:        v.ID,
+		PartyA:    v.PartyA,
+		PartyB:    v.PartyB,
+		Hub:       v.Hub,
+		DepositA:  (*hexutil.Big)(v.DepositA),
+		DepositB:  (*hexutil.Big)(v.DepositB),
+		Challenge: hexutil.Uint64(v.Challenge),
+		LedgerA:   v.LedgerA,
+		LedgerB:   v.LedgerB,
+		Status:    v.Status,
+		Deadline:  hexutil.Uint64(v.Deadline),
+		Nonce:     hexutil.Uint64(v.Latest.Nonce),
+		BalanceA:  (*hexutil.Big)(v.Latest.BalanceA),
+		BalanceB:  (*hexutil.Big)(v.Latest.BalanceB),
+		Pending:   v.Pending,
+		Final:     v.Final,
+	}
+}
+
+// OpenVirtual proposes a virtual channel to a counterparty sharing a hub with
+// the local account, funded from the ledger channels both have with the hub and
+// disputes on it lasting the given number of blocks. It opens once the hub
+// allocated the deposits.
+func (api *PrivateChannelAPI) OpenVirtual(hub, counterparty common.Address, depositA, depositB hexutil.Big, challenge hexutil.Uint64) (*RPCVirtual, error) {
+	v, err := api.s.OpenVirtual(hub, counterparty, (*big.Int)(&depositA), (*big.Int)(&depositB), uint64(challenge))
+	if err != nil {
+		return nil, err
+	}
+	return newRPCVirtual(v), nil
+}
+
+// PayVirtual proposes a payment to the counterparty of a virtual channel,
+// returning the update to be countersigned by the counterparty.
+func (api *PrivateChannelAPI) PayVirtual(id common.Hash, amount hexutil.Big) (*State, error) {
+	return api.s.PayVirtual(id, (*big.Int)(&amount))
+}
+
+// CloseVirtual closes a virtual channel. Unless forced, a cooperative close is
+// proposed to the counterparty and returned. Forcing the close brings the
+// virtual channel on-chain instead, returning the state it was brought with.
+func (api *PrivateChannelAPI) CloseVirtual(ctx context.Context, id common.Hash, force *bool) (*State, error) {
+	if force != nil && *force {
+		return api.s.DisputeVirtual(ctx, id)
+	}
+	return api.s.CloseVirtual(id)
+}
+
+// Virtual returns the virtual channel with the given id.
+func (api *PrivateChannelAPI) Virtual(id common.Hash) (*RPCVirtual, error) {
+	v, err := api.s.Virtual(id)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCVirtual(v), nil
+}
+
+// Virtuals returns all the virtual channels the local account is party to or
+// the hub of.
+func (api *PrivateChannelAPI) Virtuals() []*RPCVirtual {
+	virtuals := api.s.Virtuals()
+
+	list := make([]*RPCVirtual, 0, len(virtuals))
+	for _, v := range virtuals {
+		list = append(list, newRPCVirtual(v))
+	}
+	return list
+}
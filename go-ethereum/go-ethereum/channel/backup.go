@@ -0,0 +1,91 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/channel/watchtower"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// backupTimeout is the maximum time handing a justice blob to a watchtower may
+// take.
+const backupTimeout = 10 * time.Second
+
+// towerClient is a watchtower the channel states are backed up to.
+type towerClient interface {
+	// Add hands the watchtower a justice blob tagged with a hint, along with the
+	// signature of the local party of the upload data.
+	Add(ctx context.Context, hint watchtower.Hint, blob []byte, sig []byte) error
+
+	// Close disconnects from the watchtower.
+	Close()
+}
+
+// dialTowers connects to the configured watchtowers, skipping unreachable ones.
+func (s *Service) dialTowers() {
+	for _, endpoint := range s.config.Watchtowers {
+		client, err := watchtower.Dial(endpoint)
+		if err != nil {
+			log.Warn("Failed to connect to watchtower", "endpoint", endpoint, "err", err)
+			continue
+		}
+		s.towers = append(s.towers, client)
+	}
+}
+
+// backup hands the watchtowers the justice transaction contesting a dispute on
+// a revoked state with the state superseding it, in case the counterparty
+// cheats while the local party is offline.
+func (s *Service) backup(revoked, latest *State) {
+	if len(s.towers) == 0 {
+		return
+	}
+	justice, err := watchtower.DisputeJustice(s.config.Contract, latest.Channel, latest.Nonce, latest.BalanceA, latest.BalanceB,
+		xchannel.LocksRoot(latest.Locks), latest.SigA, latest.SigB)
+	if err != nil {
+		log.Error("Failed to create justice transaction", "id", latest.Channel, "nonce", latest.Nonce, "err", err)
+		return
+	}
+	key := watchtower.StateKey(revoked.Channel, revoked.SigA, revoked.SigB)
+	blob, err := watchtower.Seal(key, justice)
+	if err != nil {
+		log.Error("Failed to seal justice transaction", "id", latest.Channel, "nonce", latest.Nonce, "err", err)
+		return
+	}
+	sig, err := s.signer.SignData(watchtower.UploadData(key.Hint(), blob))
+	if err != nil {
+		log.Error("Failed to sign justice upload", "id", latest.Channel, "nonce", latest.Nonce, "err", err)
+		return
+	}
+	for _, tower := range s.towers {
+		s.wg.Add(1)
+		go func(tower towerClient) {
+			defer s.wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+			defer cancel()
+
+			if err := tower.Add(ctx, key.Hint(), blob, sig); err != nil {
+				log.Warn("Failed to back up channel state", "id", latest.Channel, "nonce", revoked.Nonce, "err", err)
+			}
+		}(tower)
+	}
+}
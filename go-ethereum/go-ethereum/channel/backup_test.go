@@ -0,0 +1,86 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/channel/watchtower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testTower is a watchtower recording the justice blobs handed to it by its
+// client.
+type testTower struct {
+	lock   sync.Mutex
+	client common.Address
+	blobs  map[watchtower.Hint][]byte
+}
+
+func (t *testTower) Add(ctx context.Context, hint watchtower.Hint, blob []byte, sig []byte) error {
+	pub, err := crypto.SigToPub(crypto.Keccak256(watchtower.UploadData(hint, blob)), sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != t.client {
+		return errors.New("unauthorized client")
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.blobs[hint] = blob
+	return nil
+}
+
+func (t *testTower) Close() {}
+
+func (t *testTower) has(hint watchtower.Hint) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	_, ok := t.blobs[hint]
+	return ok
+}
+
+func TestBackup(t *testing.T) {
+	tt := newTester(t)
+	id := tt.open(ether(10), ether(5))
+
+	tower := &testTower{client: addrB, blobs: make(map[watchtower.Hint][]byte)}
+	tt.b.towers = []towerClient{tower}
+
+	// Every update revokes the previous state, the opening one first
+	first := tt.pay(tt.a, tt.b, id, ether(3))
+	second := tt.pay(tt.a, tt.b, id, ether(1))
+	tt.b.wg.Wait()
+
+	for i, hint := range []watchtower.Hint{
+		watchtower.StateKey(id, nil, nil).Hint(),
+		watchtower.StateKey(id, first.SigA, first.SigB).Hint(),
+	} {
+		if !tower.has(hint) {
+			t.Errorf("state %d: no justice blob backed up", i)
+		}
+	}
+	if tower.has(watchtower.StateKey(id, second.SigA, second.SigB).Hint()) {
+		t.Errorf("latest state backed up as revoked")
+	}
+	if len(tower.blobs) != 2 {
+		t.Errorf("justice blob count mismatch: have %d, want 2", len(tower.blobs))
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	keyA, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	keyB, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	addrA   = crypto.PubkeyToAddress(keyA.PublicKey)
+	addrB   = crypto.PubkeyToAddress(keyB.PublicKey)
+)
+
+// simBackend is a simulated chain with the chain id lookup the service needs.
+type simBackend struct {
+	*backends.SimulatedBackend
+}
+
+func (b *simBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1337), nil
+}
+
+// keySigner signs with a private key held in memory.
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *keySigner) SignData(data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), s.key)
+}
+
+func (s *keySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// tester runs the channel services of two parties on a simulated chain. The
+// services are driven manually instead of following the chain head.
+type tester struct {
+	t       *testing.T
+	backend *simBackend
+	a, b    *Service
+}
+
+func newTester(t *testing.T) *tester {
+	funds := new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+	backend := &simBackend{backends.NewSimulatedBackend(core.GenesisAlloc{
+		addrA: {Balance: funds},
+		addrB: {Balance: funds},
+	}, 10000000)}
+
+	opts, _ := bind.NewKeyedTransactorWithChainID(keyA, big.NewInt(1337))
+	address, _, _, err := contract.DeployXChannel(opts, backend)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	backend.Commit()
+
+	tt := &tester{t: t, backend: backend}
+	dial := func() (Backend, error) { return backend, nil }
+
+	nodeKeyA, _ := crypto.GenerateKey()
+	nodeKeyB, _ := crypto.GenerateKey()
+	tt.a = newService(&Config{Contract: address}, rawdb.NewMemoryDatabase(), addrA, &keySigner{keyA}, nodeKeyA, dial)
+	tt.b = newService(&Config{Contract: address}, rawdb.NewMemoryDatabase(), addrB, &keySigner{keyB}, nodeKeyB, dial)
+	for _, s := range []*Service{tt.a, tt.b} {
+		if err := s.setup(); err != nil {
+			t.Fatalf("failed to set up service: %v", err)
+		}
+	}
+	return tt
+}
+
+// commit mines the pending transactions and lets both services process the
+// new block.
+func (tt *tester) commit() {
+	tt.backend.Commit()
+	tt.a.sync()
+	tt.b.sync()
+}
+
+// open opens a channel from A to B, funded with the given deposits.
+func (tt *tester) open(depositA, depositB *big.Int) common.Hash {
+	tt.t.Helper()
+
+	tx, err := tt.a.Open(context.Background(), addrB, depositA, 5)
+	if err != nil {
+		tt.t.Fatalf("failed to open channel: %v", err)
+	}
+	tt.commit()
+
+	receipt, _ := tt.backend.TransactionReceipt(context.Background(), tx.Hash())
+	id, err := tt.a.opened(receipt)
+	if err != nil {
+		tt.t.Fatalf("channel not opened: %v", err)
+	}
+	if depositB.Sign() > 0 {
+		if _, err := tt.b.Deposit(context.Background(), id, depositB); err != nil {
+			tt.t.Fatalf("failed to deposit: %v", err)
+		}
+		tt.commit()
+	}
+	return id
+}
+
+// pay transfers an amount over a channel, exchanging the update between the
+// parties, and returns the state signed by both.
+func (tt *tester) pay(from, to *Service, id common.Hash, amount *big.Int) *State {
+	tt.t.Helper()
+
+	update, err := from.Pay(id, amount)
+	if err != nil {
+		tt.t.Fatalf("failed to propose payment: %v", err)
+	}
+	signed, err := to.Receive(context.Background(), update)
+	if err != nil {
+		tt.t.Fatalf("failed to countersign payment: %v", err)
+	}
+	if _, err := from.Receive(context.Background(), signed); err != nil {
+		tt.t.Fatalf("failed to record payment: %v", err)
+	}
+	return signed
+}
+
+// check verifies the view both parties have of a channel.
+func (tt *tester) check(id common.Hash, status Status, nonce uint64, balanceA, balanceB *big.Int) {
+	tt.t.Helper()
+
+	for _, s := range []*Service{tt.a, tt.b} {
+		c := readChannel(s.db, id)
+		if c == nil {
+			tt.t.Fatalf("%x: channel unknown", s.account)
+		}
+		if c.Status != status {
+			tt.t.Errorf("%x: status mismatch: have %v, want %v", s.account, c.Status, status)
+		}
+		if have := c.latest().Nonce; have != nonce {
+			tt.t.Errorf("%x: nonce mismatch: have %d, want %d", s.account, have, nonce)
+		}
+		a, b := c.balances()
+		if a.Cmp(balanceA) != 0 || b.Cmp(balanceB) != 0 {
+			tt.t.Errorf("%x: balances mismatch: have %v/%v, want %v/%v", s.account, a, b, balanceA, balanceB)
+		}
+	}
+}
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Ether))
+}
+
+func TestCooperativeClose(t *testing.T) {
+	tt := newTester(t)
+
+	id := tt.open(ether(10), ether(5))
+	tt.check(id, StatusOpen, 0, ether(10), ether(5))
+
+	tt.pay(tt.a, tt.b, id, ether(3))
+	tt.check(id, StatusOpen, 1, ether(7), ether(8))
+	tt.pay(tt.b, tt.a, id, ether(1))
+	tt.check(id, StatusOpen, 2, ether(8), ether(7))
+
+	// B proposes, A countersigns and submits the close
+	proposal, err := tt.b.Close(id)
+	if err != nil {
+		t.Fatalf("failed to propose close: %v", err)
+	}
+	if _, err := tt.b.Pay(id, ether(1)); err != errChannelNotOpen {
+		t.Fatalf("payment on closing channel: have %v, want %v", err, errChannelNotOpen)
+	}
+	signed, err := tt.a.Receive(context.Background(), proposal)
+	if err != nil {
+		t.Fatalf("failed to countersign close: %v", err)
+	}
+	if _, err := tt.b.Receive(context.Background(), signed); err != nil {
+		t.Fatalf("failed to record close: %v", err)
+	}
+	tt.check(id, StatusClosing, 2, ether(8), ether(7))
+
+	// The close is queued rather than sent while processing the message, the
+	// service loop submits it on the next sync
+	if closes := readCloses(tt.a.db); len(closes) != 1 || closes[0].Nonce != 2 {
+		t.Fatalf("queued closes mismatch: have %v, want nonce 2", closes)
+	}
+	tt.commit()
+	tt.check(id, StatusClosing, 2, ether(8), ether(7))
+	tt.commit()
+	tt.check(id, StatusClosed, 2, ether(8), ether(7))
+
+	if closes := readCloses(tt.a.db); len(closes) != 0 {
+		t.Fatalf("submitted closes left queued: %v", closes)
+	}
+	if balance := mustBalance(t, tt.backend, tt.a.config.Contract); balance.Sign() != 0 {
+		t.Fatalf("funds left in contract: %v", balance)
+	}
+}
+
+func TestInvalidUpdates(t *testing.T) {
+	tt := newTester(t)
+	id := tt.open(ether(10), ether(5))
+
+	// A payment tampered with after signing
+	update, err := tt.a.Pay(id, ether(3))
+	if err != nil {
+		t.Fatalf("failed to propose payment: %v", err)
+	}
+	tampered := update.copy()
+	tampered.BalanceB = ether(6)
+	if _, err := tt.b.Receive(context.Background(), tampered); err != errInvalidSignature {
+		t.Fatalf("tampered update: have %v, want %v", err, errInvalidSignature)
+	}
+	// A second update while one is pending
+	if _, err := tt.a.Pay(id, ether(1)); err != errUpdatePending {
+		t.Fatalf("concurrent update: have %v, want %v", err, errUpdatePending)
+	}
+	signed, err := tt.b.Receive(context.Background(), update)
+	if err != nil {
+		t.Fatalf("failed to countersign payment: %v", err)
+	}
+	if _, err := tt.a.Receive(context.Background(), signed); err != nil {
+		t.Fatalf("failed to record payment: %v", err)
+	}
+	// Properly signed updates the receiving party loses from, or which skip or
+	// replay nonces
+	tests := []struct {
+		nonce              uint64
+		balanceA, balanceB *big.Int
+		err                error
+	}{
+		{2, ether(8), ether(7), errInvalidBalances},
+		{2, ether(7), ether(9), errInvalidBalances},
+		{3, ether(6), ether(9), errInvalidNonce},
+		{1, ether(6), ether(9), errInvalidNonce},
+	}
+	c := readChannel(tt.a.db, id)
+	for i, test := range tests {
+		state := &State{Channel: id, Nonce: test.nonce, BalanceA: test.balanceA, BalanceB: test.balanceB}
+		if err := tt.a.sign(c, state); err != nil {
+			t.Fatalf("test %d: failed to sign: %v", i, err)
+		}
+		if _, err := tt.b.Receive(context.Background(), state); err != test.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+	tt.check(id, StatusOpen, 1, ether(7), ether(8))
+
+	// Payments beyond the balance
+	if _, err := tt.b.Pay(id, ether(9)); err != errInsufficientBalance {
+		t.Fatalf("overdraft: have %v, want %v", err, errInsufficientBalance)
+	}
+}
+
+func TestStaleDispute(t *testing.T) {
+	tt := newTester(t)
+	id := tt.open(ether(10), ether(5))
+
+	stale := tt.pay(tt.a, tt.b, id, ether(3))
+	tt.pay(tt.a, tt.b, id, ether(4))
+
+	// A disputes on the outdated state, paying it more than it's due
+	if _, err := tt.a.contract.Dispute(tt.a.transactOpts(context.Background(), nil), id, new(big.Int).SetUint64(stale.Nonce),
+		stale.BalanceA, stale.BalanceB, xchannel.LocksRoot(stale.Locks), stale.SigA, stale.SigB); err != nil {
+		t.Fatalf("failed to dispute: %v", err)
+	}
+	tt.backend.Commit()
+
+	// B notices and contests with the latest state
+	tt.b.sync()
+	tt.backend.Commit()
+	tt.a.sync()
+	tt.b.sync()
+	tt.check(id, StatusDisputed, 2, ether(3), ether(12))
+
+	onchain, err := tt.a.contract.Channels(nil, id)
+	if err != nil {
+		t.Fatalf("failed to retrieve channel: %v", err)
+	}
+	if onchain.Nonce.Uint64() != 2 {
+		t.Fatalf("dispute not contested: on-chain nonce %d", onchain.Nonce)
+	}
+	// The channel is settled on the latest state once the dispute window closed
+	before := mustBalance(t, tt.backend, addrA)
+	for tt.backend.Blockchain().CurrentBlock().NumberU64() < onchain.Deadline.Uint64() {
+		tt.backend.Commit()
+		tt.b.sync()
+	}
+	tt.commit()
+	tt.check(id, StatusClosed, 2, ether(3), ether(12))
+
+	if have, want := new(big.Int).Sub(mustBalance(t, tt.backend, addrA), before), ether(3); have.Cmp(want) != 0 {
+		t.Fatalf("payout mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that contract events are only processed once confirmed, and that the
+// channels are reconciled with the contract if processed events get reorged.
+func TestConfirmationsAndReorg(t *testing.T) {
+	tt := newTester(t)
+	for _, s := range []*Service{tt.a, tt.b} {
+		s.config.Confirmations = 1
+	}
+	snapshot := tt.backend.Snapshot()
+
+	tx, err := tt.a.Open(context.Background(), addrB, ether(10), 5)
+	if err != nil {
+		t.Fatalf("failed to open channel: %v", err)
+	}
+	tt.commit()
+
+	receipt, _ := tt.backend.TransactionReceipt(context.Background(), tx.Hash())
+	id, err := tt.a.opened(receipt)
+	if err != nil {
+		t.Fatalf("channel not opened: %v", err)
+	}
+	for _, s := range []*Service{tt.a, tt.b} {
+		if readChannel(s.db, id) != nil {
+			t.Fatalf("%x: unconfirmed channel processed", s.account)
+		}
+	}
+	tt.commit()
+	tt.check(id, StatusOpen, 0, ether(10), ether(0))
+
+	// Replace the blocks processed with empty ones, dropping the channel
+	if err := tt.backend.Revert(snapshot); err != nil {
+		t.Fatalf("failed to revert chain: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		tt.commit()
+	}
+	for _, s := range []*Service{tt.a, tt.b} {
+		if readChannel(s.db, id) != nil {
+			t.Errorf("%x: reorged channel kept", s.account)
+		}
+		if s.graph.edges[id] != nil {
+			t.Errorf("%x: reorged channel kept in graph", s.account)
+		}
+	}
+}
+
+func mustBalance(t *testing.T, backend *simBackend, addr common.Address) *big.Int {
+	balance, err := backend.BalanceAt(context.Background(), addr, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve balance: %v", err)
+	}
+	return balance
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultConfig contains the default settings of the payment channel service.
+var DefaultConfig = Config{
+	FeeBase:       big.NewInt(0),
+	FeeRate:       1000,
+	TimelockDelta: 40,
+	FinalTimelock: 40,
+	MaxTimelock:   2016,
+	Confirmations: 6,
+}
+
+// Config contains the configuration options of the payment channel service.
+type Config struct {
+	// Contract is the address of the XChannel contract the channels live in.
+	Contract common.Address
+
+	// Account is the account of the local party, signing channel states and
+	// transactions. The first account available is used if unset.
+	Account common.Address `toml:",omitempty"`
+
+	// Watchtowers are the RPC endpoints of the watchtowers the channel states
+	// are backed up to, contesting disputes on revoked states while offline.
+	Watchtowers []string `toml:",omitempty"`
+
+	// FeeBase and FeeRate are the fee charged for forwarding transfers of other
+	// parties: a flat amount plus the given millionths of the amount forwarded.
+	FeeBase *big.Int `toml:",omitempty"`
+	FeeRate uint64   `toml:",omitempty"`
+
+	// TimelockDelta is the minimum number of blocks between the expiration of an
+	// incoming transfer and the transfer forwarded for it, leaving the time to
+	// claim the incoming one on-chain once the secret was revealed.
+	TimelockDelta uint64 `toml:",omitempty"`
+
+	// FinalTimelock is the number of blocks the transfer to the payee of a route
+	// is locked for.
+	FinalTimelock uint64 `toml:",omitempty"`
+
+	// MaxTimelock is the maximum number of blocks funds are locked for when
+	// paying over a route.
+	MaxTimelock uint64 `toml:",omitempty"`
+
+	// Confirmations is the number of blocks the contract events are buried under
+	// before they are processed, keeping reorgs from undoing them. It must stay
+	// well below the challenge periods of the channels, which it eats into.
+	Confirmations uint64 `toml:",omitempty"`
+}
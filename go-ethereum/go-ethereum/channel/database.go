@@ -0,0 +1,352 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// syncedKey tracks the last block the contract events were processed up to.
+	syncedKey = []byte("LastSyncedBlock")
+
+	channelPrefix  = []byte("c") // channelPrefix + id -> channel
+	secretPrefix   = []byte("s") // secretPrefix + hashlock -> secret
+	edgePrefix     = []byte("e") // edgePrefix + id -> channel of the channel graph
+	transferPrefix = []byte("t") // transferPrefix + hashlock -> multi-hop transfer
+	invoicePrefix  = []byte("i") // invoicePrefix + hashlock -> invoice
+	virtualPrefix  = []byte("v") // virtualPrefix + id -> virtual channel
+	closePrefix    = []byte("f") // closePrefix + id -> cooperative close to submit
+)
+
+// channelKey = channelPrefix + id
+func channelKey(id common.Hash) []byte {
+	return append(append([]byte{}, channelPrefix...), id.Bytes()...)
+}
+
+// readChannel retrieves a channel from the database, nil if unknown.
+func readChannel(db ethdb.KeyValueReader, id common.Hash) *Channel {
+	data, _ := db.Get(channelKey(id))
+	if len(data) == 0 {
+		return nil
+	}
+	c := new(Channel)
+	if err := rlp.DecodeBytes(data, c); err != nil {
+		log.Error("Invalid channel RLP", "id", id, "err", err)
+		return nil
+	}
+	return c
+}
+
+// writeChannel stores a channel into the database.
+func writeChannel(db ethdb.KeyValueWriter, c *Channel) {
+	data, err := rlp.EncodeToBytes(c)
+	if err != nil {
+		log.Crit("Failed to RLP encode channel", "err", err)
+	}
+	if err := db.Put(channelKey(c.ID), data); err != nil {
+		log.Crit("Failed to store channel", "err", err)
+	}
+}
+
+// deleteChannel removes a channel from the database.
+func deleteChannel(db ethdb.KeyValueWriter, id common.Hash) {
+	if err := db.Delete(channelKey(id)); err != nil {
+		log.Crit("Failed to delete channel", "err", err)
+	}
+}
+
+// readChannels retrieves all the channels from the database.
+func readChannels(db ethdb.Iteratee) []*Channel {
+	it := db.NewIterator(channelPrefix, nil)
+	defer it.Release()
+
+	var channels []*Channel
+	for it.Next() {
+		if len(it.Key()) != len(channelPrefix)+common.HashLength {
+			continue
+		}
+		c := new(Channel)
+		if err := rlp.DecodeBytes(it.Value(), c); err != nil {
+			log.Error("Invalid channel RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		channels = append(channels, c)
+	}
+	return channels
+}
+
+// readSyncedBlock retrieves the number and the hash of the last block the
+// contract events were processed up to.
+func readSyncedBlock(db ethdb.KeyValueReader) (uint64, common.Hash) {
+	data, _ := db.Get(syncedKey)
+	if len(data) < 8 {
+		return 0, common.Hash{}
+	}
+	return binary.BigEndian.Uint64(data[:8]), common.BytesToHash(data[8:])
+}
+
+// writeSyncedBlock stores the number and the hash of the last block the
+// contract events were processed up to.
+func writeSyncedBlock(db ethdb.KeyValueWriter, number uint64, hash common.Hash) {
+	enc := make([]byte, 8+common.HashLength)
+	binary.BigEndian.PutUint64(enc, number)
+	copy(enc[8:], hash[:])
+	if err := db.Put(syncedKey, enc); err != nil {
+		log.Crit("Failed to store last synced block", "err", err)
+	}
+}
+
+// secretKey = secretPrefix + hashlock
+func secretKey(hashlock common.Hash) []byte {
+	return append(append([]byte{}, secretPrefix...), hashlock.Bytes()...)
+}
+
+// readSecret retrieves the preimage of a hashlock, zero if unknown.
+func readSecret(db ethdb.KeyValueReader, hashlock common.Hash) common.Hash {
+	data, _ := db.Get(secretKey(hashlock))
+	return common.BytesToHash(data)
+}
+
+// writeSecret stores the preimage of a hashlock.
+func writeSecret(db ethdb.KeyValueWriter, hashlock common.Hash, secret common.Hash) {
+	if err := db.Put(secretKey(hashlock), secret.Bytes()); err != nil {
+		log.Crit("Failed to store secret", "err", err)
+	}
+}
+
+// edgeKey = edgePrefix + id
+func edgeKey(id common.Hash) []byte {
+	return append(append([]byte{}, edgePrefix...), id.Bytes()...)
+}
+
+// readEdges retrieves all the channels of the channel graph from the database.
+func readEdges(db ethdb.Iteratee) []*edge {
+	it := db.NewIterator(edgePrefix, nil)
+	defer it.Release()
+
+	var edges []*edge
+	for it.Next() {
+		if len(it.Key()) != len(edgePrefix)+common.HashLength {
+			continue
+		}
+		e := new(edge)
+		if err := rlp.DecodeBytes(it.Value(), e); err != nil {
+			log.Error("Invalid channel graph edge RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		edges = append(edges, e)
+	}
+	return edges
+}
+
+// writeEdge stores a channel of the channel graph into the database.
+func writeEdge(db ethdb.KeyValueWriter, e *edge) {
+	data, err := rlp.EncodeToBytes(e)
+	if err != nil {
+		log.Crit("Failed to RLP encode channel graph edge", "err", err)
+	}
+	if err := db.Put(edgeKey(e.ID), data); err != nil {
+		log.Crit("Failed to store channel graph edge", "err", err)
+	}
+}
+
+// deleteEdge removes a channel of the channel graph from the database.
+func deleteEdge(db ethdb.KeyValueWriter, id common.Hash) {
+	if err := db.Delete(edgeKey(id)); err != nil {
+		log.Crit("Failed to delete channel graph edge", "err", err)
+	}
+}
+
+// transferKey = transferPrefix + hashlock
+func transferKey(hashlock common.Hash) []byte {
+	return append(append([]byte{}, transferPrefix...), hashlock.Bytes()...)
+}
+
+// readTransfer retrieves a multi-hop transfer from the database, nil if unknown.
+func readTransfer(db ethdb.KeyValueReader, hashlock common.Hash) *Transfer {
+	data, _ := db.Get(transferKey(hashlock))
+	if len(data) == 0 {
+		return nil
+	}
+	t := new(Transfer)
+	if err := rlp.DecodeBytes(data, t); err != nil {
+		log.Error("Invalid transfer RLP", "hashlock", hashlock, "err", err)
+		return nil
+	}
+	return t
+}
+
+// writeTransfer stores a multi-hop transfer into the database.
+func writeTransfer(db ethdb.KeyValueWriter, t *Transfer) {
+	data, err := rlp.EncodeToBytes(t)
+	if err != nil {
+		log.Crit("Failed to RLP encode transfer", "err", err)
+	}
+	if err := db.Put(transferKey(t.Hashlock), data); err != nil {
+		log.Crit("Failed to store transfer", "err", err)
+	}
+}
+
+// readTransfers retrieves all the multi-hop transfers from the database.
+func readTransfers(db ethdb.Iteratee) []*Transfer {
+	it := db.NewIterator(transferPrefix, nil)
+	defer it.Release()
+
+	var transfers []*Transfer
+	for it.Next() {
+		if len(it.Key()) != len(transferPrefix)+common.HashLength {
+			continue
+		}
+		t := new(Transfer)
+		if err := rlp.DecodeBytes(it.Value(), t); err != nil {
+			log.Error("Invalid transfer RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		transfers = append(transfers, t)
+	}
+	return transfers
+}
+
+// invoiceKey = invoicePrefix + hashlock
+func invoiceKey(hashlock common.Hash) []byte {
+	return append(append([]byte{}, invoicePrefix...), hashlock.Bytes()...)
+}
+
+// readInvoice retrieves an invoice from the database, nil if unknown.
+func readInvoice(db ethdb.KeyValueReader, hashlock common.Hash) *invoice {
+	data, _ := db.Get(invoiceKey(hashlock))
+	if len(data) == 0 {
+		return nil
+	}
+	inv := new(invoice)
+	if err := rlp.DecodeBytes(data, inv); err != nil {
+		log.Error("Invalid invoice RLP", "hashlock", hashlock, "err", err)
+		return nil
+	}
+	return inv
+}
+
+// writeInvoice stores an invoice into the database.
+func writeInvoice(db ethdb.KeyValueWriter, hashlock common.Hash, inv *invoice) {
+	data, err := rlp.EncodeToBytes(inv)
+	if err != nil {
+		log.Crit("Failed to RLP encode invoice", "err", err)
+	}
+	if err := db.Put(invoiceKey(hashlock), data); err != nil {
+		log.Crit("Failed to store invoice", "err", err)
+	}
+}
+
+// virtualKey = virtualPrefix + id
+func virtualKey(id common.Hash) []byte {
+	return append(append([]byte{}, virtualPrefix...), id.Bytes()...)
+}
+
+// readVirtual retrieves a virtual channel from the database, nil if unknown.
+func readVirtual(db ethdb.KeyValueReader, id common.Hash) *Virtual {
+	data, _ := db.Get(virtualKey(id))
+	if len(data) == 0 {
+		return nil
+	}
+	v := new(Virtual)
+	if err := rlp.DecodeBytes(data, v); err != nil {
+		log.Error("Invalid virtual channel RLP", "id", id, "err", err)
+		return nil
+	}
+	return v
+}
+
+// writeVirtual stores a virtual channel into the database.
+func writeVirtual(db ethdb.KeyValueWriter, v *Virtual) {
+	data, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		log.Crit("Failed to RLP encode virtual channel", "err", err)
+	}
+	if err := db.Put(virtualKey(v.ID), data); err != nil {
+		log.Crit("Failed to store virtual channel", "err", err)
+	}
+}
+
+// readVirtuals retrieves all the virtual channels from the database.
+func readVirtuals(db ethdb.Iteratee) []*Virtual {
+	it := db.NewIterator(virtualPrefix, nil)
+	defer it.Release()
+
+	var virtuals []*Virtual
+	for it.Next() {
+		if len(it.Key()) != len(virtualPrefix)+common.HashLength {
+			continue
+		}
+		v := new(Virtual)
+		if err := rlp.DecodeBytes(it.Value(), v); err != nil {
+			log.Error("Invalid virtual channel RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		virtuals = append(virtuals, v)
+	}
+	return virtuals
+}
+
+// closeKey = closePrefix + id
+func closeKey(id common.Hash) []byte {
+	return append(append([]byte{}, closePrefix...), id.Bytes()...)
+}
+
+// readCloses retrieves the cooperative closes to submit on-chain.
+func readCloses(db ethdb.Iteratee) []*State {
+	it := db.NewIterator(closePrefix, nil)
+	defer it.Release()
+
+	var closes []*State
+	for it.Next() {
+		if len(it.Key()) != len(closePrefix)+common.HashLength {
+			continue
+		}
+		state := new(State)
+		if err := rlp.DecodeBytes(it.Value(), state); err != nil {
+			log.Error("Invalid channel close RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		closes = append(closes, state)
+	}
+	return closes
+}
+
+// writeClose stores a cooperative close signed by both parties, to be submitted
+// on-chain.
+func writeClose(db ethdb.KeyValueWriter, state *State) {
+	data, err := rlp.EncodeToBytes(state)
+	if err != nil {
+		log.Crit("Failed to RLP encode channel close", "err", err)
+	}
+	if err := db.Put(closeKey(state.Channel), data); err != nil {
+		log.Crit("Failed to store channel close", "err", err)
+	}
+}
+
+// deleteClose removes a cooperative close from the database.
+func deleteClose(db ethdb.KeyValueWriter, id common.Hash) {
+	if err := db.Delete(closeKey(id)); err != nil {
+		log.Crit("Failed to delete channel close", "err", err)
+	}
+}
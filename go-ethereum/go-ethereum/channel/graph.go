@@ -0,0 +1,433 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+//go:generate gencodec -type Hop -field-override hopMarshaling -out gen_hop_json.go
+
+import (
+	"container/heap"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// hintMaxSkew is how far in the future the timestamp of a capacity hint may be.
+	hintMaxSkew = 10 * time.Minute
+
+	// hintRefresh is the interval the capacity hints of the local channels are
+	// reannounced at even if unchanged, reaching nodes which missed them.
+	hintRefresh = time.Hour
+)
+
+var (
+	errInvalidHint = errors.New("invalid capacity hint")
+	errStaleHint   = errors.New("stale capacity hint")
+)
+
+// hintPrefix prefixes the data the parties sign for capacity hints, so the
+// signature can't be mistaken for one over anything else.
+var hintPrefix = []byte("XChannel hint")
+
+// Hop is a transfer along a route: the channel it is locked in, the node it
+// pays and the terms of its lock.
+type Hop struct {
+	Channel    common.Hash    `json:"channel" gencodec:"required"`
+	Node       common.Address `json:"node" gencodec:"required"`
+	Amount     *big.Int       `json:"amount" gencodec:"required"`
+	Expiration uint64         `json:"expiration" gencodec:"required"`
+}
+
+type hopMarshaling struct {
+	Amount     *hexutil.Big
+	Expiration hexutil.Uint64
+}
+
+// Route is a path of transfers from a payer to a payee over the channel graph.
+// The transfer of every hop is locked under the same hashlock, each expiring a
+// number of blocks before the one it is forwarded for, and paying less by the
+// fee of the forwarding node.
+type Route []Hop
+
+// Amount returns the amount the payer sends over the route.
+func (r Route) Amount() *big.Int {
+	return new(big.Int).Set(r[0].Amount)
+}
+
+// Fee returns the total fee the forwarding nodes of the route charge.
+func (r Route) Fee() *big.Int {
+	return new(big.Int).Sub(r[0].Amount, r[len(r)-1].Amount)
+}
+
+// equal reports whether two routes take the same channels.
+func (r Route) equal(other Route) bool {
+	if len(r) != len(other) {
+		return false
+	}
+	for i := range r {
+		if r[i].Channel != other[i].Channel {
+			return false
+		}
+	}
+	return true
+}
+
+// Hint is the capacity and forwarding policy a party announces for a channel,
+// gossiped across the network for payers to find routes with. Capacities are
+// hints only, they are outdated as soon as the channel is updated again.
+type Hint struct {
+	Channel       common.Hash
+	Account       common.Address // Party forwarding transfers over the channel
+	Capacity      *big.Int       // Amount the party can send over the channel
+	FeeBase       *big.Int       // Flat fee charged for forwarding
+	FeeRate       uint64         // Fee charged for forwarding, in millionths of the amount
+	TimelockDelta uint64         // Blocks the transfers forwarded expire before the incoming ones
+	Timestamp     uint64         // Time of the announcement, later ones supersede it
+	Signature     []byte
+}
+
+// data returns the data the announcing party signs for the hint.
+func (h *Hint) data(domain xchannel.Domain) []byte {
+	blob, _ := rlp.EncodeToBytes([]interface{}{
+		domain.ChainID, domain.Contract, h.Channel, h.Account, h.Capacity,
+		h.FeeBase, h.FeeRate, h.TimelockDelta, h.Timestamp,
+	})
+	return append(append([]byte{}, hintPrefix...), blob...)
+}
+
+// fee returns the fee the announcing party charges for forwarding an amount
+// over the channel.
+func (h *Hint) fee(amount *big.Int) *big.Int {
+	return forwardingFee(h.FeeBase, h.FeeRate, amount)
+}
+
+// forwardingFee returns the fee for forwarding an amount, given a flat fee and
+// a rate in millionths of the amount.
+func forwardingFee(base *big.Int, rate uint64, amount *big.Int) *big.Int {
+	fee := new(big.Int).Mul(amount, new(big.Int).SetUint64(rate))
+	fee.Div(fee, big.NewInt(1000000))
+	if base != nil {
+		fee.Add(fee, base)
+	}
+	return fee
+}
+
+// edge is a channel of the channel graph, as opened on-chain.
+type edge struct {
+	ID       common.Hash
+	PartyA   common.Address
+	PartyB   common.Address
+	DepositA *big.Int
+	DepositB *big.Int
+}
+
+// hintKey identifies the hint of a party for a channel.
+type hintKey struct {
+	channel common.Hash
+	account common.Address
+}
+
+// graph is the network of the open channels of the contract, along with the
+// capacity hints announced for them.
+type graph struct {
+	db     ethdb.KeyValueStore // Database the channels are persisted in
+	domain xchannel.Domain     // Domain the capacity hints are signed for
+	edges  map[common.Hash]*edge
+	hints  map[hintKey]*Hint // Hints are kept in memory only, being reannounced
+	lock   sync.RWMutex
+}
+
+// newGraph creates the channel graph, loading the channels from the database.
+func newGraph(db ethdb.KeyValueStore, domain xchannel.Domain) *graph {
+	g := &graph{
+		db:     db,
+		domain: domain,
+		edges:  make(map[common.Hash]*edge),
+		hints:  make(map[hintKey]*Hint),
+	}
+	for _, e := range readEdges(db) {
+		g.edges[e.ID] = e
+	}
+	return g
+}
+
+// open adds a channel opened on-chain to the graph.
+func (g *graph) open(e *edge) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.edges[e.ID] = e
+	writeEdge(g.db, e)
+}
+
+// deposit updates the deposit of the second party of a channel.
+func (g *graph) deposit(id common.Hash, total *big.Int) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if e := g.edges[id]; e != nil {
+		e.DepositB = total
+		writeEdge(g.db, e)
+	}
+}
+
+// close drops a channel which can't be updated anymore from the graph.
+func (g *graph) close(id common.Hash) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	e := g.edges[id]
+	if e == nil {
+		return
+	}
+	delete(g.edges, id)
+	delete(g.hints, hintKey{id, e.PartyA})
+	delete(g.hints, hintKey{id, e.PartyB})
+	deleteEdge(g.db, id)
+}
+
+// hint returns the latest hint of a party for a channel, nil if none known.
+func (g *graph) hint(id common.Hash, account common.Address) *Hint {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	return g.hints[hintKey{id, account}]
+}
+
+// allHints returns all the hints known.
+func (g *graph) allHints() []*Hint {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	hints := make([]*Hint, 0, len(g.hints))
+	for _, h := range g.hints {
+		hints = append(hints, h)
+	}
+	return hints
+}
+
+// addHint validates a hint and stores it unless a later one is known.
+func (g *graph) addHint(h *Hint) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	e := g.edges[h.Channel]
+	if e == nil {
+		return errUnknownChannel
+	}
+	if h.Account != e.PartyA && h.Account != e.PartyB {
+		return errInvalidHint
+	}
+	if h.Capacity == nil || h.Capacity.Sign() < 0 || h.Capacity.Cmp(new(big.Int).Add(e.DepositA, e.DepositB)) > 0 {
+		return errInvalidHint
+	}
+	if h.FeeBase == nil || h.FeeBase.Sign() < 0 {
+		return errInvalidHint
+	}
+	if h.Timestamp > uint64(time.Now().Add(hintMaxSkew).Unix()) {
+		return errInvalidHint
+	}
+	key := hintKey{h.Channel, h.Account}
+	if prev := g.hints[key]; prev != nil && prev.Timestamp >= h.Timestamp {
+		return errStaleHint
+	}
+	signer, err := recoverSigner(h.data(g.domain), h.Signature)
+	if err != nil {
+		return err
+	}
+	if signer != h.Account {
+		return errInvalidSignature
+	}
+	g.hints[key] = h
+	return nil
+}
+
+// routeLimits are the constraints routes have to satisfy.
+type routeLimits struct {
+	maxFee      *big.Int // Maximum total fee, unlimited if nil
+	maxTimelock uint64   // Maximum number of blocks the payer's funds are locked for
+	final       uint64   // Number of blocks the transfer to the payee is locked for
+}
+
+// label is the cheapest way found from a node to the payee of a route.
+type label struct {
+	node     common.Address
+	amount   *big.Int // Amount to lock in the transfer to the node
+	timelock uint64   // Number of blocks the transfer to the node is locked for
+	hops     int
+	channel  common.Hash // Channel to the next node towards the payee
+	next     *label
+	index    int // Index in the heap
+}
+
+// less orders labels by fee first, timelock then.
+func (l *label) less(other *label) bool {
+	if c := l.amount.Cmp(other.amount); c != 0 {
+		return c < 0
+	}
+	if l.timelock != other.timelock {
+		return l.timelock < other.timelock
+	}
+	return l.hops < other.hops
+}
+
+type labelHeap []*label
+
+func (h labelHeap) Len() int            { return len(h) }
+func (h labelHeap) Less(i, j int) bool  { return h[i].less(h[j]) }
+func (h labelHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i]; h[i].index = i; h[j].index = j }
+func (h *labelHeap) Push(x interface{}) { l := x.(*label); l.index = len(*h); *h = append(*h, l) }
+func (h *labelHeap) Pop() interface{} {
+	old := *h
+	l := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return l
+}
+
+// routes returns up to n routes from a payer to a payee delivering an amount,
+// cheapest first. Beside the cheapest route, the cheapest ones avoiding each of
+// its channels in turn are returned.
+func (g *graph) routes(payer, payee common.Address, amount *big.Int, head uint64, limits routeLimits, n int) []Route {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	best := g.route(payer, payee, amount, head, limits, nil)
+	if best == nil {
+		return nil
+	}
+	routes := []Route{best}
+	for _, hop := range best {
+		route := g.route(payer, payee, amount, head, limits, map[common.Hash]bool{hop.Channel: true})
+		if route == nil {
+			continue
+		}
+		known := false
+		for _, r := range routes {
+			if r.equal(route) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			routes = append(routes, route)
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if c := routes[i].Fee().Cmp(routes[j].Fee()); c != 0 {
+			return c < 0
+		}
+		if routes[i][0].Expiration != routes[j][0].Expiration {
+			return routes[i][0].Expiration < routes[j][0].Expiration
+		}
+		return len(routes[i]) < len(routes[j])
+	})
+	if len(routes) > n {
+		routes = routes[:n]
+	}
+	return routes
+}
+
+// route finds the cheapest route from a payer to a payee delivering an amount,
+// avoiding the excluded channels. The search runs backwards from the payee, as
+// the amount to lock in every hop depends on the fees charged after it.
+//
+// Only channels whose forwarding party announced a hint are taken, in the
+// direction the hint is for, as far as the hinted capacity allows.
+func (g *graph) route(payer, payee common.Address, amount *big.Int, head uint64, limits routeLimits, excluded map[common.Hash]bool) Route {
+	adjacent := make(map[common.Address][]*edge)
+	for _, e := range g.edges {
+		if !excluded[e.ID] {
+			adjacent[e.PartyA] = append(adjacent[e.PartyA], e)
+			adjacent[e.PartyB] = append(adjacent[e.PartyB], e)
+		}
+	}
+	var (
+		labels = map[common.Address]*label{payee: {node: payee, amount: amount, timelock: limits.final}}
+		done   = make(map[common.Address]bool)
+		queue  = &labelHeap{labels[payee]}
+	)
+	for queue.Len() > 0 {
+		next := heap.Pop(queue).(*label)
+		if next.node == payer {
+			break
+		}
+		done[next.node] = true
+
+		for _, e := range adjacent[next.node] {
+			node := e.PartyA
+			if node == next.node {
+				node = e.PartyB
+			}
+			if done[node] {
+				continue
+			}
+			h := g.hints[hintKey{e.ID, node}]
+			if h == nil || h.Capacity.Cmp(next.amount) < 0 {
+				continue
+			}
+			l := &label{
+				node:     node,
+				amount:   next.amount,
+				timelock: next.timelock,
+				hops:     next.hops + 1,
+				channel:  e.ID,
+				next:     next,
+			}
+			// The payer doesn't charge itself for the first hop
+			if node != payer {
+				l.amount = new(big.Int).Add(next.amount, h.fee(next.amount))
+				l.timelock += h.TimelockDelta
+			}
+			if l.timelock > limits.maxTimelock {
+				continue
+			}
+			if limits.maxFee != nil && new(big.Int).Sub(l.amount, amount).Cmp(limits.maxFee) > 0 {
+				continue
+			}
+			if prev := labels[node]; prev != nil {
+				if !l.less(prev) {
+					continue
+				}
+				heap.Remove(queue, prev.index)
+			}
+			labels[node] = l
+			heap.Push(queue, l)
+		}
+	}
+	l := labels[payer]
+	if l == nil || l.next == nil {
+		return nil
+	}
+	var route Route
+	for ; l.next != nil; l = l.next {
+		route = append(route, Hop{
+			Channel:    l.channel,
+			Node:       l.next.node,
+			Amount:     new(big.Int).Set(l.next.amount),
+			Expiration: head + l.next.timelock,
+		})
+	}
+	return route
+}
@@ -0,0 +1,222 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// graphTester is a channel graph between named nodes, with the channels opened
+// and hinted at manually.
+type graphTester struct {
+	t     *testing.T
+	graph *graph
+	keys  map[string]*ecdsa.PrivateKey
+	time  uint64
+}
+
+func newGraphTester(t *testing.T, nodes ...string) *graphTester {
+	gt := &graphTester{
+		t:     t,
+		graph: newGraph(rawdb.NewMemoryDatabase(), xchannel.Domain{ChainID: big.NewInt(1337), Contract: common.HexToAddress("0xc0ffee")}),
+		keys:  make(map[string]*ecdsa.PrivateKey),
+		time:  uint64(time.Now().Unix()),
+	}
+	for _, node := range nodes {
+		gt.keys[node], _ = crypto.GenerateKey()
+	}
+	return gt
+}
+
+func (gt *graphTester) addr(node string) common.Address {
+	return crypto.PubkeyToAddress(gt.keys[node].PublicKey)
+}
+
+// open adds a channel between two nodes, funded by the first one.
+func (gt *graphTester) open(id byte, a, b string, deposit int64) common.Hash {
+	gt.graph.open(&edge{ID: common.Hash{id}, PartyA: gt.addr(a), PartyB: gt.addr(b), DepositA: big.NewInt(deposit), DepositB: new(big.Int)})
+	return common.Hash{id}
+}
+
+// hint creates a hint of a node for a channel, signed by the given key.
+func (gt *graphTester) hint(id common.Hash, node string, capacity, feeBase int64, delta uint64, key *ecdsa.PrivateKey) *Hint {
+	gt.time++
+	h := &Hint{
+		Channel:       id,
+		Account:       gt.addr(node),
+		Capacity:      big.NewInt(capacity),
+		FeeBase:       big.NewInt(feeBase),
+		TimelockDelta: delta,
+		Timestamp:     gt.time,
+	}
+	h.Signature, _ = crypto.Sign(crypto.Keccak256(h.data(gt.graph.domain)), key)
+	h.Signature[crypto.RecoveryIDOffset] += 27
+	return h
+}
+
+// announce adds a valid hint of a node for a channel to the graph.
+func (gt *graphTester) announce(id common.Hash, node string, capacity, feeBase int64, delta uint64) {
+	gt.t.Helper()
+
+	if err := gt.graph.addHint(gt.hint(id, node, capacity, feeBase, delta, gt.keys[node])); err != nil {
+		gt.t.Fatalf("failed to add hint of %s: %v", node, err)
+	}
+}
+
+func TestRouteFinding(t *testing.T) {
+	gt := newGraphTester(t, "payer", "x", "y", "z", "payee")
+
+	// Three ways to the payee: through x, through y charging less but locking
+	// funds for longer, through z charging least but lacking capacity
+	for i, node := range []string{"x", "y", "z"} {
+		in := gt.open(byte(2*i+1), "payer", node, 1000)
+		out := gt.open(byte(2*i+2), node, "payee", 1000)
+		gt.announce(in, "payer", 1000, 0, 0)
+
+		switch node {
+		case "x":
+			gt.announce(out, node, 1000, 10, 10)
+		case "y":
+			gt.announce(out, node, 1000, 5, 30)
+		case "z":
+			gt.announce(out, node, 50, 1, 10)
+		}
+	}
+	limits := routeLimits{maxTimelock: 100, final: 20}
+	routes := gt.graph.routes(gt.addr("payer"), gt.addr("payee"), big.NewInt(100), 1000, limits, 5)
+	if len(routes) != 2 {
+		t.Fatalf("route count mismatch: have %d, want 2", len(routes))
+	}
+	want := Route{
+		{Channel: common.Hash{3}, Node: gt.addr("y"), Amount: big.NewInt(105), Expiration: 1050},
+		{Channel: common.Hash{4}, Node: gt.addr("payee"), Amount: big.NewInt(100), Expiration: 1020},
+	}
+	checkRoute(t, routes[0], want)
+	if routes[1][0].Node != gt.addr("x") || routes[1].Fee().Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("second route mismatch: have fee %v via %x", routes[1].Fee(), routes[1][0].Node)
+	}
+	// Locking funds for too long rules y out, fee limits rule all out
+	limits.maxTimelock = 40
+	routes = gt.graph.routes(gt.addr("payer"), gt.addr("payee"), big.NewInt(100), 1000, limits, 5)
+	if len(routes) != 1 || routes[0][0].Node != gt.addr("x") {
+		t.Fatalf("timelock limited routes mismatch: have %v", routes)
+	}
+	limits.maxFee = big.NewInt(9)
+	if routes = gt.graph.routes(gt.addr("payer"), gt.addr("payee"), big.NewInt(100), 1000, limits, 5); len(routes) != 0 {
+		t.Fatalf("fee limited routes found: %v", routes)
+	}
+	// Small amounts fit through z
+	limits = routeLimits{maxTimelock: 100, final: 20}
+	routes = gt.graph.routes(gt.addr("payer"), gt.addr("payee"), big.NewInt(50), 1000, limits, 1)
+	if len(routes) != 1 || routes[0][0].Node != gt.addr("z") {
+		t.Fatalf("small amount route mismatch: have %v", routes)
+	}
+	// Channels without hints are not taken, nor closed ones
+	gt.open(7, "payer", "payee", 1000)
+	gt.graph.close(common.Hash{3})
+	routes = gt.graph.routes(gt.addr("payer"), gt.addr("payee"), big.NewInt(100), 1000, limits, 5)
+	if len(routes) != 1 || routes[0][0].Node != gt.addr("x") {
+		t.Fatalf("routes mismatch after close: have %v", routes)
+	}
+}
+
+func TestRouteMultiHop(t *testing.T) {
+	gt := newGraphTester(t, "a", "b", "c", "d")
+
+	ab := gt.open(1, "a", "b", 1000)
+	bc := gt.open(2, "b", "c", 1000)
+	cd := gt.open(3, "c", "d", 1000)
+	gt.announce(ab, "a", 1000, 0, 0)
+	gt.announce(bc, "b", 1000, 2, 10)
+	gt.announce(cd, "c", 1000, 3, 15)
+
+	routes := gt.graph.routes(gt.addr("a"), gt.addr("d"), big.NewInt(100), 500, routeLimits{maxTimelock: 100, final: 20}, 5)
+	if len(routes) != 1 {
+		t.Fatalf("route count mismatch: have %d, want 1", len(routes))
+	}
+	// Amounts grow by the fee of every forwarding node, expirations by its delta
+	checkRoute(t, routes[0], Route{
+		{Channel: ab, Node: gt.addr("b"), Amount: big.NewInt(105), Expiration: 545},
+		{Channel: bc, Node: gt.addr("c"), Amount: big.NewInt(103), Expiration: 535},
+		{Channel: cd, Node: gt.addr("d"), Amount: big.NewInt(100), Expiration: 520},
+	})
+	// Hints only apply to the direction they are announced for
+	if routes := gt.graph.routes(gt.addr("d"), gt.addr("a"), big.NewInt(100), 500, routeLimits{maxTimelock: 100, final: 20}, 5); len(routes) != 0 {
+		t.Fatalf("reverse route found: %v", routes)
+	}
+}
+
+func TestHintValidation(t *testing.T) {
+	gt := newGraphTester(t, "a", "b", "c")
+	id := gt.open(1, "a", "b", 1000)
+
+	tests := []struct {
+		hint *Hint
+		err  error
+	}{
+		{gt.hint(common.Hash{2}, "a", 100, 0, 0, gt.keys["a"]), errUnknownChannel},
+		{gt.hint(id, "c", 100, 0, 0, gt.keys["c"]), errInvalidHint},
+		{gt.hint(id, "a", 1001, 0, 0, gt.keys["a"]), errInvalidHint},
+		{gt.hint(id, "a", 100, -1, 0, gt.keys["a"]), errInvalidHint},
+		{gt.hint(id, "a", 100, 0, 0, gt.keys["b"]), errInvalidSignature},
+	}
+	for i, test := range tests {
+		if err := gt.graph.addHint(test.hint); err != test.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+	// Hints are superseded by later ones only
+	old := gt.hint(id, "a", 100, 0, 0, gt.keys["a"])
+	gt.announce(id, "a", 200, 0, 0)
+	if err := gt.graph.addHint(old); err != errStaleHint {
+		t.Errorf("stale hint error mismatch: have %v, want %v", err, errStaleHint)
+	}
+	if h := gt.graph.hint(id, gt.addr("a")); h.Capacity.Cmp(big.NewInt(200)) != 0 {
+		t.Errorf("hint capacity mismatch: have %v, want 200", h.Capacity)
+	}
+	// Channels persist, hints don't
+	reloaded := newGraph(gt.graph.db, gt.graph.domain)
+	if reloaded.edges[id] == nil {
+		t.Fatalf("channel not persisted")
+	}
+	if reloaded.hint(id, gt.addr("a")) != nil {
+		t.Fatalf("hint persisted")
+	}
+}
+
+// checkRoute verifies the hops of a route.
+func checkRoute(t *testing.T, have, want Route) {
+	t.Helper()
+
+	if len(have) != len(want) {
+		t.Fatalf("hop count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for i := range want {
+		if have[i].Channel != want[i].Channel || have[i].Node != want[i].Node ||
+			have[i].Amount.Cmp(want[i].Amount) != 0 || have[i].Expiration != want[i].Expiration {
+			t.Errorf("hop %d mismatch: have %+v, want %+v", i, have[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// NodeInfo represents a short summary of the xch sub-protocol metadata known
+// about the host peer.
+type NodeInfo struct {
+	Contract common.Address `json:"contract"` // Channel contract the channels live in
+	Account  common.Address `json:"account"`  // Account the node operates channels with
+}
+
+// Protocols returns the p2p protocols the payment channel service runs, over
+// which the parties of channels exchange their updates.
+func (s *Service) Protocols() []p2p.Protocol {
+	protos := make([]p2p.Protocol, len(ProtocolVersions))
+	for i, version := range ProtocolVersions {
+		version := version // Closure for the run
+
+		protos[i] = p2p.Protocol{
+			Name:    ProtocolName,
+			Version: version,
+			Length:  protocolLengths[version],
+			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+				return s.handle(newPeer(int(version), p, newMeteredMsgWriter(rw), p.Node().Pubkey()))
+			},
+			NodeInfo: func() interface{} {
+				return &NodeInfo{Contract: s.config.Contract, Account: s.account}
+			},
+			PeerInfo: func(id enode.ID) interface{} {
+				if p := s.peers.Node(id); p != nil {
+					return p.info()
+				}
+				return nil
+			},
+		}
+	}
+	return protos
+}
+
+// handle is the callback invoked to manage the life cycle of an xch peer. When
+// this function terminates, the peer is disconnected.
+func (s *Service) handle(p *peer) error {
+	s.peerWG.Add(1)
+	defer s.peerWG.Done()
+
+	// The handshake needs the chain the service is connected to
+	select {
+	case <-s.ready:
+	case <-s.quit:
+		return p2p.DiscQuitting
+	}
+	p.Log().Debug("Channel peer connected", "name", p.Name())
+
+	status := &statusData{
+		ChainID:  s.domain.ChainID,
+		Genesis:  s.genesis,
+		Contract: s.config.Contract,
+		Account:  s.account,
+	}
+	if err := p.Handshake(s.self, status, s.signData); err != nil {
+		p.Log().Debug("Channel handshake failed", "err", err)
+		return err
+	}
+	if err := s.peers.Register(p); err != nil {
+		p.Log().Debug("Channel peer registration failed", "err", err)
+		return err
+	}
+	defer s.peers.Unregister(p)
+
+	go p.sender()
+	defer p.close()
+
+	p.Log().Debug("Channel peer registered", "account", p.account)
+
+	// Hand the peer the capacity hints known for it to find routes with
+	for _, h := range s.graph.allHints() {
+		p.queue(HintMsg, h)
+	}
+	for {
+		if err := s.handleMsg(p); err != nil {
+			p.Log().Debug("Channel message handling failed", "err", err)
+			return err
+		}
+	}
+}
+
+// handleMsg is invoked whenever an inbound message is received from a remote
+// peer. The remote connection is torn down upon returning any error.
+func (s *Service) handleMsg(p *peer) error {
+	// Read the next message from the remote peer, and ensure it's fully consumed
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Size > protocolMaxMsgSize {
+		return errResp(ErrMsgTooLarge, "%v > %v", msg.Size, protocolMaxMsgSize)
+	}
+	defer msg.Discard()
+
+	if msg.Code == StatusMsg {
+		// Status messages should never arrive after the handshake
+		return errResp(ErrExtraStatusMsg, "uncontrolled status message")
+	}
+	// Open the envelope, which is only ever addressed to the local node
+	var env envelope
+	if err := msg.Decode(&env); err != nil {
+		return errResp(ErrDecode, "%v: %v", msg, err)
+	}
+	if env.To != s.self {
+		return errResp(ErrMisaddressed, "%x", env.To)
+	}
+	payload, err := s.nodeKey.Decrypt(env.Payload, nil, nil)
+	if err != nil {
+		return errResp(ErrDecode, "%v: %v", msg, err)
+	}
+	// Handle the message depending on its contents
+	switch msg.Code {
+	case ProposeMsg, CloseMsg:
+		var update State
+		if err := rlp.DecodeBytes(payload, &update); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		if update.Final != (msg.Code == CloseMsg) {
+			return errResp(ErrDecode, "%v: update finality mismatch", msg)
+		}
+		s.handleProposal(p, &update)
+
+	case AckMsg:
+		var update State
+		if err := rlp.DecodeBytes(payload, &update); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		s.handleAck(p, &update)
+
+	case RejectMsg:
+		var reject rejectData
+		if err := rlp.DecodeBytes(payload, &reject); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		s.handleReject(p, &reject)
+
+	case RevealMsg:
+		var reveal revealData
+		if err := rlp.DecodeBytes(payload, &reveal); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		s.handleReveal(p, &reveal)
+
+	case TransferMsg:
+		var transfer transferData
+		if err := rlp.DecodeBytes(payload, &transfer); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		if transfer.Update == nil || transfer.Update.Final {
+			return errResp(ErrDecode, "%v: missing transfer update", msg)
+		}
+		s.handleTransfer(p, &transfer)
+
+	case CancelMsg:
+		var cancel cancelData
+		if err := rlp.DecodeBytes(payload, &cancel); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		if cancel.Update == nil || cancel.Update.Final {
+			return errResp(ErrDecode, "%v: missing cancel update", msg)
+		}
+		s.handleCancel(p, &cancel)
+
+	case HintMsg:
+		var hint Hint
+		if err := rlp.DecodeBytes(payload, &hint); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		s.handleHint(p, &hint)
+
+	case VirtualMsg:
+		var data virtualData
+		if err := rlp.DecodeBytes(payload, &data); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		if data.Opening == nil {
+			return errResp(ErrDecode, "%v: missing opening state", msg)
+		}
+		s.handleVirtual(p, &data)
+
+	case FundMsg:
+		var fund fundData
+		if err := rlp.DecodeBytes(payload, &fund); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		if fund.State == nil || fund.Update == nil || fund.Update.Final {
+			return errResp(ErrDecode, "%v: missing funding update", msg)
+		}
+		s.handleFund(p, &fund)
+
+	default:
+		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
+	}
+	return nil
+}
+
+// handleProposal countersigns an update of a channel or virtual channel proposed
+// by a peer, replying with either the countersigned update or the reason it was
+// rejected.
+func (s *Service) handleProposal(p *peer, update *State) {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	var (
+		signed *State
+		err    error
+	)
+	switch p.account {
+	case s.counterparty(update.Channel):
+		signed, err = s.Receive(ctx, update)
+	case s.virtualCounterparty(update.Channel):
+		signed, err = s.receiveVirtual(update)
+	default:
+		err = errUnknownChannel
+	}
+	s.reply(p, update, signed, err)
+
+	// Closing a virtual channel settles its allocation
+	if err == nil && update.Final {
+		s.settleVirtuals()
+	}
+}
+
+// reply answers an update proposed by a peer with either the countersigned
+// update or the reason it was rejected.
+func (s *Service) reply(p *peer, update *State, signed *State, err error) {
+	if err != nil {
+		p.Log().Debug("Rejecting channel update", "id", update.Channel, "nonce", update.Nonce, "err", err)
+		p.queue(RejectMsg, &rejectData{Channel: update.Channel, Nonce: update.Nonce, Final: update.Final, Reason: err.Error()})
+		return
+	}
+	p.queue(AckMsg, signed)
+}
+
+// handleTransfer countersigns an update locking a transfer proposed by a peer,
+// forwarding the transfer along its route.
+func (s *Service) handleTransfer(p *peer, transfer *transferData) {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	var (
+		signed, forward *State
+		err             error
+	)
+	if s.counterparty(transfer.Update.Channel) != p.account {
+		err = errUnknownChannel
+	} else {
+		signed, forward, err = s.acceptTransfer(ctx, transfer)
+	}
+	s.reply(p, transfer.Update, signed, err)
+	if err != nil {
+		return
+	}
+	if forward != nil {
+		s.deliver(s.counterparty(forward.Channel), TransferMsg, &transferData{Update: forward, Route: transfer.Route})
+	}
+	// Cancel the transfer if it couldn't be forwarded, reveal the secret if paid
+	s.resolve()
+	s.revealSecrets(transfer.Update.Locks[len(transfer.Update.Locks)-1].Hashlock)
+}
+
+// handleCancel countersigns an update proposed by a peer cancelling a transfer
+// that failed further along its route, carrying the failure back along it.
+func (s *Service) handleCancel(p *peer, cancel *cancelData) {
+	ctx, cancelCtx := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancelCtx()
+
+	var (
+		signed *State
+		err    error
+	)
+	if s.counterparty(cancel.Update.Channel) != p.account {
+		err = errUnknownChannel
+	} else {
+		signed, err = s.receive(ctx, cancel.Update, &failure{Node: cancel.Node, Reason: cancel.Reason})
+	}
+	s.reply(p, cancel.Update, signed, err)
+	if err == nil {
+		s.resolve()
+	}
+}
+
+// handleHint stores a capacity hint gossiped by a peer, relaying it further if
+// it wasn't known yet.
+func (s *Service) handleHint(p *peer, hint *Hint) {
+	if err := s.graph.addHint(hint); err != nil {
+		if err != errStaleHint {
+			p.Log().Trace("Dropping capacity hint", "id", hint.Channel, "account", hint.Account, "err", err)
+		}
+		return
+	}
+	s.relay(hint, p.account)
+}
+
+// handleAck records an update proposed by the local party and countersigned by
+// the peer.
+func (s *Service) handleAck(p *peer, update *State) {
+	if len(update.SigA) == 0 || len(update.SigB) == 0 {
+		p.Log().Debug("Dropping unsigned channel update", "id", update.Channel, "nonce", update.Nonce)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	var err error
+	switch p.account {
+	case s.counterparty(update.Channel):
+		_, err = s.Receive(ctx, update)
+	case s.virtualCounterparty(update.Channel):
+		_, err = s.receiveVirtual(update)
+	default:
+		return
+	}
+	if err != nil {
+		p.Log().Warn("Failed to record channel update", "id", update.Channel, "nonce", update.Nonce, "err", err)
+		return
+	}
+	// The channel can be updated again, there might be transfers to resolve and
+	// virtual channel allocations to settle
+	s.resolve()
+	s.settleVirtuals()
+}
+
+// handleReject drops an update proposed by the local party that the peer
+// refused to countersign, so that the channel can be updated again. A transfer
+// the update locked fails.
+func (s *Service) handleReject(p *peer, reject *rejectData) {
+	if !s.rejected(p, reject) && !s.rejectedVirtual(p, reject) {
+		return
+	}
+	s.resolve()
+}
+
+// rejected drops a rejected update, reporting whether it was pending.
+func (s *Service) rejected(p *peer, reject *rejectData) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := readChannel(s.db, reject.Channel)
+	if c == nil || c.counterparty(s.account) != p.account || c.Pending == nil {
+		return false
+	}
+	if c.Pending.Nonce != reject.Nonce || c.Pending.Final != reject.Final {
+		return false
+	}
+	if c.Pending.Final && c.Status == StatusClosing {
+		c.Status = StatusOpen
+	}
+	if locks := c.Pending.Locks; len(locks) == len(c.latest().Locks)+1 {
+		if lock := locks[len(locks)-1]; lock.Virtual() {
+			if v := readVirtual(s.db, lock.Hashlock); v != nil && v.Status == VirtualFunding {
+				v.Status = VirtualClosed
+				writeVirtual(s.db, v)
+			}
+		} else if t := readTransfer(s.db, locks[len(locks)-1].Hashlock); t != nil && t.Status == TransferPending && t.outgoing() != nil && t.outgoing().Channel == c.ID {
+			t.Status, t.FailedAt, t.Reason = TransferFailed, p.account, reject.Reason
+			writeTransfer(s.db, t)
+		}
+	}
+	c.Pending = nil
+	writeChannel(s.db, c)
+
+	log.Warn("Channel update rejected", "id", c.ID, "nonce", reject.Nonce, "reason", reject.Reason)
+	return true
+}
+
+// handleReveal stores the preimage of a hashlock pending in a channel, revealed
+// by the peer.
+func (s *Service) handleReveal(p *peer, reveal *revealData) {
+	if s.counterparty(reveal.Channel) != p.account {
+		return
+	}
+	if err := s.learn(reveal.Channel, reveal.Secret); err != nil {
+		p.Log().Debug("Dropping revealed secret", "id", reveal.Channel, "err", err)
+		return
+	}
+	// Unlock the transfer paid to the peer, reveal the secret back along the route
+	s.resolve()
+	s.revealSecrets(xchannel.Hashlock(reveal.Secret))
+}
+
+// deliver queues a message to the counterparty of a channel, if connected. The
+// parties can still exchange updates out of band otherwise.
+func (s *Service) deliver(counterparty common.Address, code uint64, data interface{}) {
+	p := s.peers.Peer(counterparty)
+	if p == nil {
+		log.Debug("Channel counterparty not connected", "account", counterparty)
+		return
+	}
+	p.queue(code, data)
+}
@@ -0,0 +1,191 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// newTestPeer creates the peer a service appears as to the other end of a
+// message pipe.
+func newTestPeer(s *Service, rw p2p.MsgReadWriter) *peer {
+	return newPeer(xch1, p2p.NewPeer(s.self, "test", nil), rw, s.nodeKey.PublicKey.ExportECDSA())
+}
+
+// connect runs the xch protocol between the two parties of the tester, returning
+// a function tearing the connection down.
+func (tt *tester) connect() func() {
+	tt.t.Helper()
+
+	app, net := p2p.MsgPipe()
+	go tt.a.handle(newTestPeer(tt.b, app))
+	go tt.b.handle(newTestPeer(tt.a, net))
+
+	tt.waitFor("peers registered", func() bool {
+		return tt.a.peers.Peer(addrB) != nil && tt.b.peers.Peer(addrA) != nil
+	})
+	return func() {
+		app.Close()
+		net.Close()
+	}
+}
+
+// waitFor waits until a condition holds, failing the test if it doesn't in time.
+func (tt *tester) waitFor(what string, cond func() bool) {
+	tt.t.Helper()
+	waitFor(tt.t, what, cond)
+}
+
+// waitFor waits until a condition holds, failing the test if it doesn't in time.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timeout waiting for %s", what)
+}
+
+// settled reports whether both parties agree on the latest state of a channel
+// at the given nonce, with no update pending.
+func (tt *tester) settled(id common.Hash, nonce uint64) bool {
+	for _, s := range []*Service{tt.a, tt.b} {
+		s.lock.Lock()
+		c := readChannel(s.db, id)
+		s.lock.Unlock()
+
+		if c.Pending != nil || c.latest().Nonce != nonce {
+			return false
+		}
+	}
+	return true
+}
+
+func TestProtocolHandshake(t *testing.T) {
+	tt := newTester(t)
+
+	tests := []struct {
+		tamper func(status *statusData) enode.ID
+		code   errCode
+	}{
+		{
+			tamper: func(status *statusData) enode.ID {
+				status.Contract = common.HexToAddress("0xdeadbeef")
+				return tt.b.self
+			},
+			code: ErrContractMismatch,
+		},
+		{
+			tamper: func(status *statusData) enode.ID {
+				status.Genesis = common.HexToHash("0xdeadbeef")
+				return tt.b.self
+			},
+			code: ErrGenesisMismatch,
+		},
+		{
+			// Claiming an account signed for by someone else
+			tamper: func(status *statusData) enode.ID {
+				status.Account = common.HexToAddress("0xdeadbeef")
+				return tt.b.self
+			},
+			code: ErrInvalidAuth,
+		},
+		{
+			// Replaying the signature of another connection
+			tamper: func(status *statusData) enode.ID {
+				return enode.ID{0x01}
+			},
+			code: ErrInvalidAuth,
+		},
+	}
+	for i, test := range tests {
+		app, net := p2p.MsgPipe()
+
+		status := &statusData{ChainID: tt.b.domain.ChainID, Genesis: tt.b.genesis, Contract: tt.b.config.Contract, Account: addrB}
+		self := test.tamper(status)
+		go newTestPeer(tt.a, net).Handshake(self, status, tt.b.signData)
+
+		err := newTestPeer(tt.b, app).Handshake(tt.a.self, &statusData{
+			ChainID:  tt.a.domain.ChainID,
+			Genesis:  tt.a.genesis,
+			Contract: tt.a.config.Contract,
+			Account:  addrA,
+		}, tt.a.signData)
+		if err == nil || !strings.Contains(err.Error(), test.code.String()) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.code)
+		}
+		app.Close()
+		net.Close()
+	}
+}
+
+func TestProtocolUpdates(t *testing.T) {
+	tt := newTester(t)
+	id := tt.open(ether(10), ether(5))
+
+	disconnect := tt.connect()
+	defer disconnect()
+
+	// Payments are countersigned and acknowledged over the connection
+	if _, err := tt.a.Pay(id, ether(3)); err != nil {
+		t.Fatalf("failed to pay: %v", err)
+	}
+	tt.waitFor("first payment", func() bool { return tt.settled(id, 1) })
+
+	if _, err := tt.b.Pay(id, ether(1)); err != nil {
+		t.Fatalf("failed to pay: %v", err)
+	}
+	tt.waitFor("second payment", func() bool { return tt.settled(id, 2) })
+	tt.check(id, StatusOpen, 2, ether(8), ether(7))
+
+	// An invalid update is rejected, unblocking the channel of the proposer
+	tt.a.lock.Lock()
+	c := readChannel(tt.a.db, id)
+	bogus := &State{Channel: id, Nonce: 3, BalanceA: ether(9), BalanceB: ether(6)}
+	if err := tt.a.sign(c, bogus); err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	c.Pending = bogus
+	writeChannel(tt.a.db, c)
+	tt.a.lock.Unlock()
+
+	tt.a.deliver(addrB, ProposeMsg, bogus)
+	tt.waitFor("rejection", func() bool { return tt.settled(id, 2) })
+
+	// Secrets can only be revealed for pending transfers
+	if err := tt.a.Reveal(id, common.HexToHash("0x5ec4e7")); err != errUnknownLock {
+		t.Fatalf("reveal error mismatch: have %v, want %v", err, errUnknownLock)
+	}
+	// The counterparty submits a close it countersigned
+	if _, err := tt.a.Close(id); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	tt.waitFor("close", func() bool { return tt.settled(id, 2) })
+	tt.check(id, StatusClosing, 2, ether(8), ether(7))
+
+	tt.commit()
+	tt.commit()
+	tt.check(id, StatusClosed, 2, ether(8), ether(7))
+}
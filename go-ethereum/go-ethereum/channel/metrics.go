@@ -0,0 +1,108 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+var (
+	updateInPacketsMeter  = metrics.NewRegisteredMeter("xch/update/in/packets", nil)
+	updateInTrafficMeter  = metrics.NewRegisteredMeter("xch/update/in/traffic", nil)
+	updateOutPacketsMeter = metrics.NewRegisteredMeter("xch/update/out/packets", nil)
+	updateOutTrafficMeter = metrics.NewRegisteredMeter("xch/update/out/traffic", nil)
+
+	transferInPacketsMeter  = metrics.NewRegisteredMeter("xch/transfer/in/packets", nil)
+	transferInTrafficMeter  = metrics.NewRegisteredMeter("xch/transfer/in/traffic", nil)
+	transferOutPacketsMeter = metrics.NewRegisteredMeter("xch/transfer/out/packets", nil)
+	transferOutTrafficMeter = metrics.NewRegisteredMeter("xch/transfer/out/traffic", nil)
+
+	hintInPacketsMeter  = metrics.NewRegisteredMeter("xch/hint/in/packets", nil)
+	hintInTrafficMeter  = metrics.NewRegisteredMeter("xch/hint/in/traffic", nil)
+	hintOutPacketsMeter = metrics.NewRegisteredMeter("xch/hint/out/packets", nil)
+	hintOutTrafficMeter = metrics.NewRegisteredMeter("xch/hint/out/traffic", nil)
+
+	virtualInPacketsMeter  = metrics.NewRegisteredMeter("xch/virtual/in/packets", nil)
+	virtualInTrafficMeter  = metrics.NewRegisteredMeter("xch/virtual/in/traffic", nil)
+	virtualOutPacketsMeter = metrics.NewRegisteredMeter("xch/virtual/out/packets", nil)
+	virtualOutTrafficMeter = metrics.NewRegisteredMeter("xch/virtual/out/traffic", nil)
+
+	miscInPacketsMeter  = metrics.NewRegisteredMeter("xch/misc/in/packets", nil)
+	miscInTrafficMeter  = metrics.NewRegisteredMeter("xch/misc/in/traffic", nil)
+	miscOutPacketsMeter = metrics.NewRegisteredMeter("xch/misc/out/packets", nil)
+	miscOutTrafficMeter = metrics.NewRegisteredMeter("xch/misc/out/traffic", nil)
+)
+
+// meteredMsgReadWriter is a wrapper around a p2p.MsgReadWriter, capable of
+// accumulating the above defined metrics based on the data stream contents.
+type meteredMsgReadWriter struct {
+	p2p.MsgReadWriter // Wrapped message stream to meter
+}
+
+// newMeteredMsgWriter wraps a p2p MsgReadWriter with metering support. If the
+// metrics system is disabled, this function returns the original object.
+func newMeteredMsgWriter(rw p2p.MsgReadWriter) p2p.MsgReadWriter {
+	if !metrics.Enabled {
+		return rw
+	}
+	return &meteredMsgReadWriter{MsgReadWriter: rw}
+}
+
+func (rw *meteredMsgReadWriter) ReadMsg() (p2p.Msg, error) {
+	// Read the message and short circuit in case of an error
+	msg, err := rw.MsgReadWriter.ReadMsg()
+	if err != nil {
+		return msg, err
+	}
+	// Account for the data traffic
+	packets, traffic := miscInPacketsMeter, miscInTrafficMeter
+	switch msg.Code {
+	case ProposeMsg, AckMsg, RejectMsg, CloseMsg:
+		packets, traffic = updateInPacketsMeter, updateInTrafficMeter
+	case TransferMsg, CancelMsg, RevealMsg:
+		packets, traffic = transferInPacketsMeter, transferInTrafficMeter
+	case HintMsg:
+		packets, traffic = hintInPacketsMeter, hintInTrafficMeter
+	case VirtualMsg, FundMsg:
+		packets, traffic = virtualInPacketsMeter, virtualInTrafficMeter
+	}
+	packets.Mark(1)
+	traffic.Mark(int64(msg.Size))
+
+	return msg, err
+}
+
+func (rw *meteredMsgReadWriter) WriteMsg(msg p2p.Msg) error {
+	// Account for the data traffic
+	packets, traffic := miscOutPacketsMeter, miscOutTrafficMeter
+	switch msg.Code {
+	case ProposeMsg, AckMsg, RejectMsg, CloseMsg:
+		packets, traffic = updateOutPacketsMeter, updateOutTrafficMeter
+	case TransferMsg, CancelMsg, RevealMsg:
+		packets, traffic = transferOutPacketsMeter, transferOutTrafficMeter
+	case HintMsg:
+		packets, traffic = hintOutPacketsMeter, hintOutTrafficMeter
+	case VirtualMsg, FundMsg:
+		packets, traffic = virtualOutPacketsMeter, virtualOutTrafficMeter
+	}
+	packets.Mark(1)
+	traffic.Mark(int64(msg.Size))
+
+	// Send the packet to the p2p layer
+	return rw.MsgReadWriter.WriteMsg(msg)
+}
@@ -0,0 +1,331 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	errClosed            = errors.New("peer set is closed")
+	errAlreadyRegistered = errors.New("peer is already registered")
+	errNotRegistered     = errors.New("peer is not registered")
+)
+
+const (
+	handshakeTimeout = 5 * time.Second
+
+	// maxQueuedMsgs is the maximum number of messages queued to be sent to a
+	// peer, beyond which further ones are dropped.
+	maxQueuedMsgs = 1024
+)
+
+// authPrefix prefixes the data the accounts of the peers sign in the handshake,
+// so the signature can't be mistaken for one over anything else.
+var authPrefix = []byte("XChannel peer")
+
+// authData returns the data the account of a node signs to prove it operates
+// its channels to a peer.
+func authData(from, to enode.ID, chainID *big.Int, contract common.Address) []byte {
+	data := make([]byte, 0, len(authPrefix)+2*len(enode.ID{})+common.HashLength+common.AddressLength)
+	data = append(data, authPrefix...)
+	data = append(data, from[:]...)
+	data = append(data, to[:]...)
+	data = append(data, common.LeftPadBytes(chainID.Bytes(), common.HashLength)...)
+	return append(data, contract[:]...)
+}
+
+// peer is a remote node speaking the xch protocol.
+type peer struct {
+	*p2p.Peer
+
+	id      string
+	rw      p2p.MsgReadWriter
+	version int
+	pubkey  *ecies.PublicKey // Node key of the peer, payloads are encrypted to
+	account common.Address   // Account the peer operates channels with
+
+	queued chan queuedMsg // Messages queued to be sent to the peer
+	term   chan struct{}  // Termination channel to stop the sender
+}
+
+// queuedMsg is a message queued to be sent to a peer.
+type queuedMsg struct {
+	code uint64
+	data interface{}
+}
+
+func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter, pubkey *ecdsa.PublicKey) *peer {
+	return &peer{
+		Peer:    p,
+		id:      p.ID().String(),
+		rw:      rw,
+		version: version,
+		pubkey:  ecies.ImportECDSAPublic(pubkey),
+		queued:  make(chan queuedMsg, maxQueuedMsgs),
+		term:    make(chan struct{}),
+	}
+}
+
+// info gathers and returns a collection of metadata known about a peer.
+func (p *peer) info() *PeerInfo {
+	return &PeerInfo{
+		Version: p.version,
+		Account: p.account,
+	}
+}
+
+// send encrypts a message to the peer and sends it.
+func (p *peer) send(code uint64, data interface{}) error {
+	payload, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return err
+	}
+	sealed, err := ecies.Encrypt(rand.Reader, p.pubkey, payload, nil, nil)
+	if err != nil {
+		return err
+	}
+	return p2p.Send(p.rw, code, &envelope{To: p.ID(), Payload: sealed})
+}
+
+// queue schedules a message to be sent to the peer, dropping it if the queue is
+// full. Unlike send, it never waits for the peer to read the message.
+func (p *peer) queue(code uint64, data interface{}) {
+	select {
+	case p.queued <- queuedMsg{code, data}:
+	default:
+		p.Log().Debug("Dropping channel message", "code", code)
+	}
+}
+
+// sender sends the queued messages to the peer until the connection is torn
+// down.
+func (p *peer) sender() {
+	for {
+		select {
+		case msg := <-p.queued:
+			if err := p.send(msg.code, msg.data); err != nil {
+				p.Log().Debug("Failed to send channel message", "code", msg.code, "err", err)
+				return
+			}
+		case <-p.term:
+			return
+		}
+	}
+}
+
+// close signals the sender to terminate.
+func (p *peer) close() {
+	close(p.term)
+}
+
+// Handshake executes the xch protocol handshake, exchanging the chain and
+// contract the channels live on and authenticating the accounts of both ends.
+func (p *peer) Handshake(local enode.ID, status *statusData, sign func([]byte) ([]byte, error)) error {
+	sig, err := sign(authData(local, p.ID(), status.ChainID, status.Contract))
+	if err != nil {
+		return err
+	}
+	status.ProtocolVersion, status.Signature = uint32(p.version), sig
+
+	// Send out own handshake in a new thread
+	errc := make(chan error, 2)
+
+	var remote statusData // safe to read after two values have been received from errc
+	go func() {
+		errc <- p2p.Send(p.rw, StatusMsg, status)
+	}()
+	go func() {
+		errc <- p.readStatus(local, status, &remote)
+	}()
+	timeout := time.NewTimer(handshakeTimeout)
+	defer timeout.Stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errc:
+			if err != nil {
+				return err
+			}
+		case <-timeout.C:
+			return p2p.DiscReadTimeout
+		}
+	}
+	p.account = remote.Account
+	return nil
+}
+
+func (p *peer) readStatus(local enode.ID, status *statusData, remote *statusData) error {
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	defer msg.Discard()
+
+	if msg.Code != StatusMsg {
+		return errResp(ErrNoStatusMsg, "first msg has code %x (!= %x)", msg.Code, StatusMsg)
+	}
+	if msg.Size > protocolMaxMsgSize {
+		return errResp(ErrMsgTooLarge, "%v > %v", msg.Size, protocolMaxMsgSize)
+	}
+	// Decode the handshake and make sure everything matches
+	if err := msg.Decode(remote); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	if int(remote.ProtocolVersion) != p.version {
+		return errResp(ErrProtocolVersionMismatch, "%d (!= %d)", remote.ProtocolVersion, p.version)
+	}
+	if remote.ChainID == nil || remote.ChainID.Cmp(status.ChainID) != 0 {
+		return errResp(ErrChainIDMismatch, "%v (!= %v)", remote.ChainID, status.ChainID)
+	}
+	if remote.Genesis != status.Genesis {
+		return errResp(ErrGenesisMismatch, "%x (!= %x)", remote.Genesis, status.Genesis)
+	}
+	if remote.Contract != status.Contract {
+		return errResp(ErrContractMismatch, "%x (!= %x)", remote.Contract, status.Contract)
+	}
+	// Make sure the peer controls the account it claims
+	signer, err := recoverSigner(authData(p.ID(), local, remote.ChainID, remote.Contract), remote.Signature)
+	if err != nil {
+		return errResp(ErrInvalidAuth, "%v", err)
+	}
+	if signer != remote.Account || signer == status.Account {
+		return errResp(ErrInvalidAuth, "account %x signed by %x", remote.Account, signer)
+	}
+	return nil
+}
+
+// String implements fmt.Stringer.
+func (p *peer) String() string {
+	return fmt.Sprintf("Peer %s [%s]", p.id,
+		fmt.Sprintf("xch/%d", p.version),
+	)
+}
+
+// PeerInfo represents a short summary of the xch sub-protocol metadata known
+// about a connected peer.
+type PeerInfo struct {
+	Version int            `json:"version"` // Xch protocol version negotiated
+	Account common.Address `json:"account"` // Account the peer operates channels with
+}
+
+// peerSet represents the collection of active peers participating in the xch
+// protocol, indexed by the account they operate channels with.
+type peerSet struct {
+	peers  map[common.Address]*peer
+	lock   sync.RWMutex
+	closed bool
+}
+
+// newPeerSet creates a new peer set to track the active participants.
+func newPeerSet() *peerSet {
+	return &peerSet{
+		peers: make(map[common.Address]*peer),
+	}
+}
+
+// Register injects a new peer into the working set, or returns an error if the
+// peer is already known or another peer operates the same account.
+func (ps *peerSet) Register(p *peer) error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	if ps.closed {
+		return errClosed
+	}
+	if _, ok := ps.peers[p.account]; ok {
+		return errAlreadyRegistered
+	}
+	ps.peers[p.account] = p
+	return nil
+}
+
+// Unregister removes a remote peer from the active set.
+func (ps *peerSet) Unregister(p *peer) error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	if ps.peers[p.account] != p {
+		return errNotRegistered
+	}
+	delete(ps.peers, p.account)
+	return nil
+}
+
+// Peer retrieves the peer operating the given account.
+func (ps *peerSet) Peer(account common.Address) *peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	return ps.peers[account]
+}
+
+// Peers retrieves all the peers in the set.
+func (ps *peerSet) Peers() []*peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	list := make([]*peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		list = append(list, p)
+	}
+	return list
+}
+
+// Node retrieves the peer with the given node id.
+func (ps *peerSet) Node(id enode.ID) *peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	for _, p := range ps.peers {
+		if p.ID() == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// Len returns the current number of peers in the set.
+func (ps *peerSet) Len() int {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	return len(ps.peers)
+}
+
+// Close disconnects all peers. No new peers can be registered after Close has
+// returned.
+func (ps *peerSet) Close() {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	for _, p := range ps.peers {
+		p.Disconnect(p2p.DiscQuitting)
+	}
+	ps.closed = true
+}
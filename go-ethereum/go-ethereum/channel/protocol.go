@@ -0,0 +1,165 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Constants to match up protocol versions and messages
+const (
+	xch1 = 1
+)
+
+// ProtocolName is the official short name of the protocol used during capability
+// negotiation.
+const ProtocolName = "xch"
+
+// ProtocolVersions are the supported versions of the xch protocol (first is primary).
+var ProtocolVersions = []uint{xch1}
+
+// protocolLengths are the number of implemented message corresponding to different
+// protocol versions.
+var protocolLengths = map[uint]uint64{xch1: 11}
+
+const protocolMaxMsgSize = 1024 * 1024 // Maximum cap on the size of a protocol message
+
+// xch protocol message codes
+const (
+	StatusMsg  = 0x00
+	ProposeMsg = 0x01 // Channel update proposed for countersigning
+	AckMsg     = 0x02 // Channel update countersigned
+	RejectMsg  = 0x03 // Channel update refused
+	RevealMsg  = 0x04 // Preimage of a hash-locked transfer revealed
+	CloseMsg   = 0x05 // Cooperative close proposed for countersigning
+
+	TransferMsg = 0x06 // Hash-locked transfer proposed for countersigning, with its route
+	CancelMsg   = 0x07 // Removal of a failed transfer proposed for countersigning
+	HintMsg     = 0x08 // Capacity hint of a channel of the network
+
+	VirtualMsg = 0x09 // Virtual channel proposed, or its opening state countersigned
+	FundMsg    = 0x0a // Ledger channel update (de)allocating a virtual channel, proposed to the hub
+)
+
+type errCode int
+
+const (
+	ErrMsgTooLarge = iota
+	ErrDecode
+	ErrInvalidMsgCode
+	ErrProtocolVersionMismatch
+	ErrChainIDMismatch
+	ErrGenesisMismatch
+	ErrContractMismatch
+	ErrInvalidAuth
+	ErrNoStatusMsg
+	ErrExtraStatusMsg
+	ErrMisaddressed
+)
+
+func (e errCode) String() string {
+	return errorToString[int(e)]
+}
+
+var errorToString = map[int]string{
+	ErrMsgTooLarge:             "Message too long",
+	ErrDecode:                  "Invalid message",
+	ErrInvalidMsgCode:          "Invalid message code",
+	ErrProtocolVersionMismatch: "Protocol version mismatch",
+	ErrChainIDMismatch:         "Chain ID mismatch",
+	ErrGenesisMismatch:         "Genesis mismatch",
+	ErrContractMismatch:        "Channel contract mismatch",
+	ErrInvalidAuth:             "Invalid account authentication",
+	ErrNoStatusMsg:             "No status message",
+	ErrExtraStatusMsg:          "Extra status message",
+	ErrMisaddressed:            "Message addressed to another node",
+}
+
+func errResp(code errCode, format string, v ...interface{}) error {
+	return fmt.Errorf("%v - %v", code, fmt.Sprintf(format, v...))
+}
+
+// statusData is the network packet for the status message. Beside the chain
+// and contract the channels live on, it carries the account the node operates
+// channels with, authenticated by a signature over the node ids of both ends
+// of the connection.
+type statusData struct {
+	ProtocolVersion uint32
+	ChainID         *big.Int
+	Genesis         common.Hash
+	Contract        common.Address
+	Account         common.Address
+	Signature       []byte
+}
+
+// envelope is the network packet of every message after the handshake. The
+// payload is the RLP encoding of the message, ECIES encrypted to the node key
+// of the recipient.
+type envelope struct {
+	To      enode.ID
+	Payload []byte
+}
+
+// rejectData is the network packet for the rejection of a channel update.
+type rejectData struct {
+	Channel common.Hash
+	Nonce   uint64
+	Final   bool
+	Reason  string
+}
+
+// revealData is the network packet for the reveal of the preimage of a hash
+// lock pending in a channel.
+type revealData struct {
+	Channel common.Hash
+	Secret  common.Hash
+}
+
+// transferData is the network packet for the proposal of an update locking a
+// transfer, along with the route the transfer is to be forwarded along.
+type transferData struct {
+	Update *State
+	Route  Route
+}
+
+// cancelData is the network packet for the proposal of an update refunding a
+// transfer that failed, along with the node it failed at and why.
+type cancelData struct {
+	Update *State
+	Node   common.Address
+	Reason string
+}
+
+// virtualData is the network packet for the proposal of a virtual channel, and
+// for its opening state countersigned by the counterparty.
+type virtualData struct {
+	Terms   VirtualTerms
+	Opening *State
+}
+
+// fundData is the network packet for the proposal to the hub of an update of a
+// ledger channel allocating funds to a virtual channel, along with its opening
+// state, or settling the allocation, along with its final state.
+type fundData struct {
+	Terms  VirtualTerms
+	State  *State
+	Update *State
+}
@@ -0,0 +1,864 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package channel implements two party payment channels on top of the XChannel
+// contract.
+//
+// Channels are opened and funded on-chain, after which the parties pay each other
+// by co-signing channel states with increasing nonces off-chain, exchanged over
+// the xch p2p protocol or out of band via the RPC API. A channel is
+// closed either cooperatively, on final balances signed by both parties, or
+// through an on-chain dispute in which the state with the highest nonce wins.
+// The service follows the contract events, contesting disputes raised on
+// outdated states and settling channels once their dispute window closed.
+//
+// Parties without a channel between them pay each other over routes through
+// intermediaries, found on the graph of the channels opened on-chain and the
+// capacity hints the parties gossip over the xch protocol. The transfer of
+// every hop of a route is locked under the hashlock of an invoice of the payee,
+// expiring earlier than the one before it. The payee reveals the secret to
+// claim its transfer, which is revealed back along the route for every node to
+// claim the transfer paying it. A transfer failing at a node is cancelled back
+// along the route instead, carrying where and why it failed.
+//
+// Parties with ledger channels to a common hub open virtual channels between
+// them without going on-chain, funded by allocations in the ledger channels
+// which the hub countersigns for both at once. The virtual channel is updated
+// by its parties alone, and its final balances settle the allocations. Either
+// party or the hub can bring a virtual channel on-chain if the others stop
+// cooperating, see virtual.md for the protocol.
+package channel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// syncTimeout is the maximum time a round of processing the contract events
+// may take.
+const syncTimeout = 30 * time.Second
+
+var (
+	errNoAccount           = errors.New("no account to operate channels with")
+	errInvalidCounterparty = errors.New("invalid counterparty")
+	errNotCounterparty     = errors.New("only the counterparty of a channel can deposit")
+	errOpenFailed          = errors.New("channel open transaction failed")
+)
+
+// channelABI is the parsed ABI of the channel contract, used to tell its events
+// apart.
+var channelABI, _ = abi.JSON(strings.NewReader(contract.XChannelABI))
+
+// Backend is the chain access needed by the payment channel service.
+type Backend interface {
+	bind.ContractBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// signer signs channel states and transactions on behalf of the local party.
+type signer interface {
+	// SignData signs the keccak256 hash of the given data.
+	SignData(data []byte) ([]byte, error)
+
+	// SignTx signs a transaction for the given chain.
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// walletSigner is a signer backed by an account of the account manager.
+type walletSigner struct {
+	wallet  accounts.Wallet
+	account accounts.Account
+}
+
+func (s *walletSigner) SignData(data []byte) ([]byte, error) {
+	return s.wallet.SignData(s.account, accounts.MimetypeTypedData, data)
+}
+
+func (s *walletSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return s.wallet.SignTx(s.account, tx, chainID)
+}
+
+// Service maintains the payment channels of the local account.
+type Service struct {
+	config  *Config
+	db      ethdb.Database // Database holding the channels
+	account common.Address // Account of the local party
+	signer  signer
+	dial    func() (Backend, error)
+
+	nodeKey *ecies.PrivateKey // Node key decrypting the messages of peers
+	self    enode.ID          // Node id messages of peers are addressed to
+	peers   *peerSet
+	peerWG  sync.WaitGroup
+
+	backend  Backend
+	contract *contract.XChannel
+	domain   xchannel.Domain
+	genesis  common.Hash
+	ready    chan struct{} // Closed once connected to the chain
+	towers   []towerClient // Watchtowers the channel states are backed up to
+	graph    *graph        // Channel graph transfers are routed over
+
+	closing   map[common.Hash]bool           // Channels a cooperative close was submitted for
+	closeReq  chan struct{}                  // Notification of cooperative closes to submit
+	settling  map[common.Hash]bool           // Channels a settlement was submitted for
+	revealing map[common.Hash]bool           // Hashlocks a secret reveal was submitted for
+	disputing map[common.Hash]bool           // Virtual channels a dispute was submitted for
+	funding   map[common.Hash]*fundingHalves // Allocations to virtual channels held by the hub
+	lock      sync.Mutex                     // Lock serialising channel updates
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a payment channel service and registers it with the node. The
+// channels are operated via the node's own RPC endpoint, the updates of channels
+// are exchanged with the counterparties over the xch protocol.
+func New(stack *node.Node, config *Config) (*Service, error) {
+	manager := stack.AccountManager()
+
+	account := config.Account
+	if account == (common.Address{}) {
+		for _, wallet := range manager.Wallets() {
+			if accounts := wallet.Accounts(); len(accounts) > 0 {
+				account = accounts[0].Address
+				break
+			}
+		}
+		if account == (common.Address{}) {
+			return nil, errNoAccount
+		}
+	}
+	wallet, err := manager.Find(accounts.Account{Address: account})
+	if err != nil {
+		return nil, err
+	}
+	db, err := stack.OpenDatabase("channels", 16, 16, "channel/db/")
+	if err != nil {
+		return nil, err
+	}
+	dial := func() (Backend, error) {
+		client, err := stack.Attach()
+		if err != nil {
+			return nil, err
+		}
+		return ethclient.NewClient(client), nil
+	}
+	s := newService(config, db, account, &walletSigner{wallet, accounts.Account{Address: account}}, stack.Server().PrivateKey, dial)
+
+	stack.RegisterAPIs(s.APIs())
+	stack.RegisterProtocols(s.Protocols())
+	stack.RegisterLifecycle(s)
+	return s, nil
+}
+
+// newService creates a payment channel service operating on the given account,
+// with peers addressing their messages to the given node key.
+func newService(config *Config, db ethdb.Database, account common.Address, signer signer, nodeKey *ecdsa.PrivateKey, dial func() (Backend, error)) *Service {
+	return &Service{
+		config:    config,
+		db:        db,
+		account:   account,
+		signer:    signer,
+		dial:      dial,
+		nodeKey:   ecies.ImportECDSA(nodeKey),
+		self:      enode.PubkeyToIDV4(&nodeKey.PublicKey),
+		peers:     newPeerSet(),
+		ready:     make(chan struct{}),
+		closing:   make(map[common.Hash]bool),
+		closeReq:  make(chan struct{}, 1),
+		settling:  make(map[common.Hash]bool),
+		revealing: make(map[common.Hash]bool),
+		disputing: make(map[common.Hash]bool),
+		funding:   make(map[common.Hash]*fundingHalves),
+		quit:      make(chan struct{}),
+	}
+}
+
+// APIs returns the RPC APIs the payment channel service offers.
+func (s *Service) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "channel",
+			Version:   "1.0",
+			Service:   NewPrivateChannelAPI(s),
+			Public:    false,
+		},
+	}
+}
+
+// Start implements node.Lifecycle, connecting to the chain and starting to
+// follow the contract events.
+func (s *Service) Start() error {
+	if err := s.setup(); err != nil {
+		return err
+	}
+	s.wg.Add(1)
+	go s.loop()
+
+	log.Info("Started payment channel service", "contract", s.config.Contract, "account", s.account)
+	return nil
+}
+
+// setup connects to the chain and binds the channel contract.
+func (s *Service) setup() error {
+	backend, err := s.dial()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	genesis, err := backend.HeaderByNumber(ctx, big.NewInt(0))
+	if err != nil {
+		return err
+	}
+	contract, err := contract.NewXChannel(s.config.Contract, backend)
+	if err != nil {
+		return err
+	}
+	switch err := xchannel.CheckCode(ctx, backend, s.config.Contract, xchannel.ChannelCode); err {
+	case xchannel.ErrNoCode:
+		log.Warn("No channel contract deployed yet", "address", s.config.Contract)
+	case xchannel.ErrCodeMismatch:
+		log.Warn("Unknown channel contract deployed", "address", s.config.Contract, "version", xchannel.Version)
+	}
+	s.backend, s.contract = backend, contract
+	s.domain = xchannel.Domain{ChainID: chainID, Contract: s.config.Contract}
+	s.genesis = genesis.Hash()
+	s.graph = newGraph(s.db, s.domain)
+	s.dialTowers()
+
+	close(s.ready)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the service.
+func (s *Service) Stop() error {
+	close(s.quit)
+	s.peers.Close()
+	s.peerWG.Wait()
+	s.wg.Wait()
+	for _, tower := range s.towers {
+		tower.Close()
+	}
+
+	log.Info("Payment channel service stopped")
+	return nil
+}
+
+// loop processes the contract events of every new block.
+func (s *Service) loop() {
+	defer s.wg.Done()
+
+	heads := make(chan *types.Header, 16)
+	sub, err := s.backend.SubscribeNewHead(context.Background(), heads)
+	if err != nil {
+		log.Error("Failed to subscribe to chain heads", "err", err)
+		return
+	}
+	defer sub.Unsubscribe()
+
+	s.sync()
+	for {
+		select {
+		case <-heads:
+			s.sync()
+		case <-s.closeReq:
+			ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+			s.submitCloses(ctx)
+			cancel()
+		case err := <-sub.Err():
+			log.Error("Chain head subscription failed", "err", err)
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// sync processes the contract events up to the current head and settles the
+// channels whose dispute window closed.
+//
+// Events are only processed once buried under the configured confirmations. If
+// a reorg replaced processed blocks nonetheless, the channels are reconciled
+// with the contract and the events of the new blocks processed.
+func (s *Service) sync() {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	head, err := s.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Warn("Failed to retrieve chain head", "err", err)
+		return
+	}
+	number := head.Number.Uint64()
+
+	synced, hash := readSyncedBlock(s.db)
+	if hash != (common.Hash{}) && synced <= number {
+		header, err := s.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(synced))
+		if err != nil {
+			log.Warn("Failed to retrieve last synced block", "number", synced, "err", err)
+			return
+		}
+		if header.Hash() != hash {
+			log.Warn("Processed channel events reorged", "number", synced, "hash", hash, "new", header.Hash())
+			s.recheck(ctx)
+
+			// The new blocks may fork off below the processed one, go back as deep
+			// as reorgs are expected to be
+			if synced > s.config.Confirmations {
+				synced -= s.config.Confirmations
+			} else {
+				synced = 0
+			}
+		}
+	}
+	if number >= s.config.Confirmations {
+		confirmed, err := s.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(number-s.config.Confirmations))
+		if err != nil {
+			log.Warn("Failed to retrieve confirmed block", "number", number-s.config.Confirmations, "err", err)
+			return
+		}
+		if from, to := synced+1, confirmed.Number.Uint64(); from <= to {
+			logs, err := s.backend.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(from),
+				ToBlock:   new(big.Int).SetUint64(to),
+				Addresses: []common.Address{s.config.Contract},
+			})
+			if err != nil {
+				log.Warn("Failed to retrieve channel events", "from", from, "to", to, "err", err)
+				return
+			}
+			for _, l := range logs {
+				s.handleLog(ctx, l, number)
+			}
+			writeSyncedBlock(s.db, to, confirmed.Hash())
+		}
+	}
+	s.submitCloses(ctx)
+	s.settle(ctx, number)
+	s.claimExpiring(ctx, number)
+	s.finaliseVirtuals(ctx, number)
+
+	// Retry resolving the pending transfers, in case messages got lost
+	s.resolve()
+	s.revealSecrets(common.Hash{})
+	s.settleVirtuals()
+	s.expireFunding()
+	s.announce()
+}
+
+// updateGraph updates the channel graph according to a contract event. Unlike
+// the local view of the channels, the graph covers the channels of all parties.
+func (s *Service) updateGraph(l types.Log) {
+	switch l.Topics[0] {
+	case channelABI.Events["Opened"].ID:
+		event, err := s.contract.ParseOpened(l)
+		if err != nil {
+			return
+		}
+		s.graph.open(&edge{
+			ID:       event.Id,
+			PartyA:   event.PartyA,
+			PartyB:   event.PartyB,
+			DepositA: event.Deposit,
+			DepositB: new(big.Int),
+		})
+	case channelABI.Events["Deposited"].ID:
+		event, err := s.contract.ParseDeposited(l)
+		if err != nil {
+			return
+		}
+		s.graph.deposit(event.Id, event.Total)
+
+	case channelABI.Events["Disputed"].ID, channelABI.Events["Closed"].ID:
+		s.graph.close(l.Topics[1])
+	}
+}
+
+// handleLog updates the local view of a channel according to a contract event.
+func (s *Service) handleLog(ctx context.Context, l types.Log, head uint64) {
+	if len(l.Topics) < 2 {
+		return
+	}
+	s.updateGraph(l)
+
+	switch l.Topics[0] {
+	case channelABI.Events["VirtualDisputed"].ID, channelABI.Events["VirtualClosed"].ID:
+		s.handleVirtualLog(ctx, l, head)
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if l.Topics[0] == channelABI.Events["Opened"].ID {
+		event, err := s.contract.ParseOpened(l)
+		if err != nil {
+			log.Warn("Invalid channel event", "err", err)
+			return
+		}
+		if event.PartyA != s.account && event.PartyB != s.account {
+			return
+		}
+		if readChannel(s.db, event.Id) != nil {
+			return
+		}
+		writeChannel(s.db, &Channel{
+			ID:        event.Id,
+			PartyA:    event.PartyA,
+			PartyB:    event.PartyB,
+			DepositA:  event.Deposit,
+			DepositB:  new(big.Int),
+			Challenge: event.Challenge.Uint64(),
+			Status:    StatusOpen,
+		})
+		log.Info("Payment channel opened", "id", common.Hash(event.Id), "counterparty", event.PartyB, "deposit", event.Deposit)
+		return
+	}
+	// Any other event concerns an existing channel, closed ones are final
+	c := readChannel(s.db, l.Topics[1])
+	if c == nil || c.Status == StatusClosed {
+		return
+	}
+	switch l.Topics[0] {
+	case channelABI.Events["Deposited"].ID:
+		event, err := s.contract.ParseDeposited(l)
+		if err != nil {
+			log.Warn("Invalid channel event", "err", err)
+			return
+		}
+		c.DepositB = event.Total
+
+	case channelABI.Events["Disputed"].ID:
+		event, err := s.contract.ParseDisputed(l)
+		if err != nil {
+			log.Warn("Invalid channel event", "err", err)
+			return
+		}
+		c.Status, c.Deadline = StatusDisputed, event.Deadline.Uint64()
+
+		// Contest the dispute if it was raised on an outdated state
+		if c.Latest != nil && c.Latest.Nonce > event.Nonce.Uint64() && head+1 < c.Deadline {
+			log.Warn("Contesting channel dispute", "id", c.ID, "nonce", event.Nonce, "latest", c.Latest.Nonce)
+			if _, err := s.dispute(ctx, c); err != nil {
+				log.Error("Failed to contest channel dispute", "id", c.ID, "err", err)
+			}
+		}
+	case channelABI.Events["Closed"].ID:
+		event, err := s.contract.ParseClosed(l)
+		if err != nil {
+			log.Warn("Invalid channel event", "err", err)
+			return
+		}
+		c.Status, c.Pending = StatusClosed, nil
+		delete(s.closing, c.ID)
+		delete(s.settling, c.ID)
+		deleteClose(s.db, c.ID)
+		writeChannel(s.db, c)
+
+		// Virtual channels allocated funds by the channel are settled with it
+		for _, v := range readVirtuals(s.db) {
+			if v.LedgerA == c.ID || v.LedgerB == c.ID {
+				s.settled(v)
+				writeVirtual(s.db, v)
+			}
+		}
+
+		log.Info("Payment channel closed", "id", c.ID, "balanceA", event.BalanceA, "balanceB", event.BalanceB)
+	default:
+		return
+	}
+	writeChannel(s.db, c)
+}
+
+// recheck reconciles the local channels with the contract after a reorg undid
+// processed events: channels no longer on-chain are dropped, the deposits and
+// the status of the others are taken from the contract.
+func (s *Service) recheck(ctx context.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, c := range readChannels(s.db) {
+		if c.Status == StatusClosed {
+			continue
+		}
+		onchain, err := s.contract.Channels(&bind.CallOpts{Context: ctx}, c.ID)
+		if err != nil {
+			log.Warn("Failed to recheck channel", "id", c.ID, "err", err)
+			continue
+		}
+		switch onchain.Status.Uint64() {
+		case xchannel.StatusUnknown:
+			log.Warn("Payment channel reorged out", "id", c.ID)
+			s.graph.close(c.ID)
+			deleteChannel(s.db, c.ID)
+			continue
+
+		case xchannel.StatusOpen:
+			if c.Status == StatusDisputed {
+				c.Status, c.Deadline = StatusOpen, 0
+			}
+		case xchannel.StatusDisputed:
+			c.Status, c.Deadline = StatusDisputed, onchain.Deadline.Uint64()
+
+		case xchannel.StatusClosed:
+			c.Status, c.Pending = StatusClosed, nil
+		}
+		delete(s.closing, c.ID)
+		delete(s.settling, c.ID)
+		c.DepositB = onchain.DepositB
+		writeChannel(s.db, c)
+	}
+}
+
+// submitCloses sends the cooperative closes countersigned by the local party
+// on-chain. Closes of channels no longer closing cooperatively are dropped.
+func (s *Service) submitCloses(ctx context.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, state := range readCloses(s.db) {
+		c := readChannel(s.db, state.Channel)
+		if c == nil || c.Status != StatusClosing {
+			delete(s.closing, state.Channel)
+			deleteClose(s.db, state.Channel)
+			continue
+		}
+		if s.closing[c.ID] {
+			continue
+		}
+		if _, err := s.contract.Close(s.transactOpts(ctx, nil), c.ID, new(big.Int).SetUint64(state.Nonce),
+			state.BalanceA, state.BalanceB, state.SigA, state.SigB); err != nil {
+			log.Warn("Failed to close channel", "id", c.ID, "err", err)
+			continue
+		}
+		s.closing[c.ID] = true
+		log.Info("Closing payment channel", "id", c.ID, "balanceA", state.BalanceA, "balanceB", state.BalanceB)
+	}
+}
+
+// settle submits the settlement of the disputed channels whose dispute window
+// closed and whose pending transfers can all be resolved. Until then, the known
+// secrets of the transfers paying the local party are revealed on-chain.
+func (s *Service) settle(ctx context.Context, head uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, c := range readChannels(s.db) {
+		if c.Status != StatusDisputed || s.settling[c.ID] {
+			continue
+		}
+		onchain, err := s.contract.Channels(&bind.CallOpts{Context: ctx}, c.ID)
+		if err != nil {
+			log.Warn("Failed to retrieve disputed channel", "id", c.ID, "err", err)
+			continue
+		}
+		// Find the transfers pending in the disputed state
+		var locks []xchannel.Lock
+		if root := common.Hash(onchain.LocksRoot); root != (common.Hash{}) {
+			var found bool
+			for _, state := range []*State{c.Latest, c.Pending} {
+				if state != nil && xchannel.LocksRoot(state.Locks) == root {
+					locks, found = state.Locks, true
+					break
+				}
+			}
+			if !found {
+				log.Error("Unknown transfers in disputed channel", "id", c.ID, "root", root)
+				continue
+			}
+		}
+		s.reveal(ctx, locks, head+1)
+		s.enforceVirtuals(ctx, locks)
+
+		if head+1 < c.Deadline || !s.resolvable(ctx, locks, head+1) {
+			continue
+		}
+		if _, err := s.contract.Settle(s.transactOpts(ctx, nil), c.ID, xchannel.EncodeLocks(locks)); err != nil {
+			log.Warn("Failed to settle channel", "id", c.ID, "err", err)
+			continue
+		}
+		s.settling[c.ID] = true
+		log.Info("Settling payment channel", "id", c.ID)
+	}
+}
+
+// resolvable reports whether all the pending transfers can be resolved in the
+// given block: either their secret was revealed in time or they expired. The
+// virtual channels allocated funds must be final on-chain.
+func (s *Service) resolvable(ctx context.Context, locks []xchannel.Lock, number uint64) bool {
+	for _, lock := range locks {
+		if lock.Virtual() {
+			onchain, err := s.contract.Virtuals(&bind.CallOpts{Context: ctx}, lock.Hashlock)
+			if err != nil {
+				return false
+			}
+			switch onchain.Status.Uint64() {
+			case xchannel.StatusClosed:
+				continue
+			case xchannel.StatusDisputed:
+				if number >= onchain.Deadline.Uint64() {
+					continue
+				}
+			}
+			return false
+		}
+		if number > lock.Expiration {
+			continue
+		}
+		revealed, err := s.contract.Secrets(&bind.CallOpts{Context: ctx}, lock.Hashlock)
+		if err != nil || revealed.Sign() == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// reveal submits the known secrets of the pending transfers paying the local
+// party that can still be claimed in the given block.
+func (s *Service) reveal(ctx context.Context, locks []xchannel.Lock, number uint64) {
+	for _, lock := range locks {
+		if lock.Payer == s.account || number > lock.Expiration || s.revealing[lock.Hashlock] {
+			continue
+		}
+		secret := readSecret(s.db, lock.Hashlock)
+		if secret == (common.Hash{}) {
+			continue
+		}
+		if revealed, err := s.contract.Secrets(&bind.CallOpts{Context: ctx}, lock.Hashlock); err != nil || revealed.Sign() != 0 {
+			continue
+		}
+		if _, err := s.contract.Reveal(s.transactOpts(ctx, nil), secret); err != nil {
+			log.Warn("Failed to reveal secret", "hashlock", lock.Hashlock, "err", err)
+			continue
+		}
+		s.revealing[lock.Hashlock] = true
+		log.Info("Revealing secret of pending transfer", "hashlock", lock.Hashlock)
+	}
+}
+
+// claimExpiring goes on-chain for the transfers paying the local party in open
+// channels which the payer didn't unlock despite their secret being known, once
+// they get within the timelock delta of expiring. The secrets are revealed for
+// the transfers to stay claimable past their expiration and the channels are
+// disputed, settling the transfers without the payer.
+func (s *Service) claimExpiring(ctx context.Context, head uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, c := range readChannels(s.db) {
+		if c.Status != StatusOpen {
+			continue
+		}
+		var expiring []xchannel.Lock
+		for _, lock := range c.latest().Locks {
+			if lock.Payer == s.account || lock.Virtual() || head >= lock.Expiration || head+s.config.TimelockDelta < lock.Expiration {
+				continue
+			}
+			if readSecret(s.db, lock.Hashlock) != (common.Hash{}) {
+				expiring = append(expiring, lock)
+			}
+		}
+		if len(expiring) == 0 {
+			continue
+		}
+		log.Warn("Transfers left locked close to expiry", "id", c.ID, "transfers", len(expiring))
+		s.reveal(ctx, expiring, head+1)
+
+		if _, err := s.dispute(ctx, c); err != nil {
+			log.Error("Failed to dispute channel", "id", c.ID, "err", err)
+			continue
+		}
+		c.Status = StatusDisputed
+		writeChannel(s.db, c)
+	}
+}
+
+// transactOpts returns the options to send a transaction to the channel
+// contract with from the local account.
+func (s *Service) transactOpts(ctx context.Context, value *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From:    s.account,
+		Value:   value,
+		Context: ctx,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != s.account {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.signer.SignTx(tx, s.domain.ChainID)
+		},
+	}
+}
+
+// Open sends the transaction opening a channel with a counterparty, funded with
+// the given deposit and disputes on it lasting the given number of blocks. The
+// channel shows up once the transaction is mined.
+func (s *Service) Open(ctx context.Context, counterparty common.Address, deposit *big.Int, challenge uint64) (*types.Transaction, error) {
+	if counterparty == s.account || counterparty == (common.Address{}) {
+		return nil, errInvalidCounterparty
+	}
+	return s.contract.Open(s.transactOpts(ctx, deposit), counterparty, new(big.Int).SetUint64(challenge))
+}
+
+// opened returns the id of the channel opened by a mined transaction.
+func (s *Service) opened(receipt *types.Receipt) (common.Hash, error) {
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return common.Hash{}, errOpenFailed
+	}
+	for _, l := range receipt.Logs {
+		if len(l.Topics) > 0 && l.Topics[0] == channelABI.Events["Opened"].ID {
+			event, err := s.contract.ParseOpened(*l)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			return event.Id, nil
+		}
+	}
+	return common.Hash{}, errOpenFailed
+}
+
+// Deposit sends the transaction adding to the deposit of the local party, which
+// must be the counterparty of the channel.
+func (s *Service) Deposit(ctx context.Context, id common.Hash, amount *big.Int) (*types.Transaction, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := readChannel(s.db, id)
+	if c == nil {
+		return nil, errUnknownChannel
+	}
+	if c.Status != StatusOpen {
+		return nil, errChannelNotOpen
+	}
+	if c.PartyB != s.account {
+		return nil, errNotCounterparty
+	}
+	return s.contract.Deposit(s.transactOpts(ctx, amount), id)
+}
+
+// Dispute closes a channel unilaterally, raising a dispute on its latest state.
+// The channel is settled once the dispute window closed. The disputed state is
+// returned.
+func (s *Service) Dispute(ctx context.Context, id common.Hash) (*State, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := readChannel(s.db, id)
+	if c == nil {
+		return nil, errUnknownChannel
+	}
+	if c.Status != StatusOpen && c.Status != StatusClosing {
+		return nil, errChannelNotOpen
+	}
+	if _, err := s.dispute(ctx, c); err != nil {
+		return nil, err
+	}
+	c.Status = StatusDisputed
+	writeChannel(s.db, c)
+
+	return c.latest().copy(), nil
+}
+
+// dispute sends the transaction raising a dispute on the latest state of a
+// channel.
+func (s *Service) dispute(ctx context.Context, c *Channel) (*types.Transaction, error) {
+	state := c.latest()
+	return s.contract.Dispute(s.transactOpts(ctx, nil), c.ID, new(big.Int).SetUint64(state.Nonce),
+		state.BalanceA, state.BalanceB, xchannel.LocksRoot(state.Locks), state.SigA, state.SigB)
+}
+
+// Reveal discloses the preimage of a hashlock pending in a channel to the
+// counterparty, who can then unlock the transfer off-chain.
+func (s *Service) Reveal(id common.Hash, secret common.Hash) error {
+	if err := s.learn(id, secret); err != nil {
+		return err
+	}
+	s.deliver(s.counterparty(id), RevealMsg, &revealData{Channel: id, Secret: secret})
+	return nil
+}
+
+// learn stores the preimage of a hashlock pending in a channel.
+func (s *Service) learn(id common.Hash, secret common.Hash) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := readChannel(s.db, id)
+	if c == nil {
+		return errUnknownChannel
+	}
+	hashlock := xchannel.Hashlock(secret)
+	for _, state := range []*State{c.latest(), c.Pending} {
+		if state == nil {
+			continue
+		}
+		for _, lock := range state.Locks {
+			if lock.Hashlock == hashlock {
+				writeSecret(s.db, hashlock, secret)
+				return nil
+			}
+		}
+	}
+	return errUnknownLock
+}
+
+// counterparty returns the counterparty of a channel, zero if unknown.
+func (s *Service) counterparty(id common.Hash) common.Address {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := readChannel(s.db, id)
+	if c == nil {
+		return common.Address{}
+	}
+	return c.counterparty(s.account)
+}
+
+// Channels returns all the channels of the local account.
+func (s *Service) Channels() []*Channel {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return readChannels(s.db)
+}
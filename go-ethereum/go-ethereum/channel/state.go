@@ -0,0 +1,203 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+//go:generate gencodec -type State -field-override stateMarshaling -out gen_state_json.go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Status is the stage of its lifecycle a channel is in.
+type Status uint8
+
+const (
+	StatusOpen     Status = iota + 1 // Funded on-chain, accepting updates
+	StatusClosing                    // Cooperative close proposed or submitted
+	StatusDisputed                   // Dispute running on-chain
+	StatusClosed                     // Paid out on-chain
+)
+
+// String implements fmt.Stringer.
+func (s Status) String() string {
+	switch s {
+	case StatusOpen:
+		return "open"
+	case StatusClosing:
+		return "closing"
+	case StatusDisputed:
+		return "disputed"
+	case StatusClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("unknown(%d)", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// State is a state of a channel, signed by one or both of its parties. Only
+// states signed by both can be brought on-chain.
+type State struct {
+	Channel  common.Hash     `json:"channel" gencodec:"required"`
+	Nonce    uint64          `json:"nonce" gencodec:"required"`
+	BalanceA *big.Int        `json:"balanceA" gencodec:"required"`
+	BalanceB *big.Int        `json:"balanceB" gencodec:"required"`
+	Locks    []xchannel.Lock `json:"locks"`
+	Final    bool            `json:"final"` // Whether the state closes the channel cooperatively
+	SigA     []byte          `json:"sigA"`
+	SigB     []byte          `json:"sigB"`
+}
+
+type stateMarshaling struct {
+	Nonce    hexutil.Uint64
+	BalanceA *hexutil.Big
+	BalanceB *hexutil.Big
+	SigA     hexutil.Bytes
+	SigB     hexutil.Bytes
+}
+
+// data returns the typed data the parties sign for the state.
+func (s *State) data(domain xchannel.Domain) []byte {
+	if s.Final {
+		return domain.CloseData(s.Channel, s.Nonce, s.BalanceA, s.BalanceB)
+	}
+	return domain.StateData(s.Channel, s.Nonce, s.BalanceA, s.BalanceB, xchannel.LocksRoot(s.Locks))
+}
+
+// signer recovers the account that produced a signature over the state.
+func (s *State) signer(domain xchannel.Domain, sig []byte) (common.Address, error) {
+	return recoverSigner(s.data(domain), sig)
+}
+
+// recoverSigner recovers the account that signed the keccak256 hash of some
+// data, with the legacy recovery id the contract expects.
+func recoverSigner(data []byte, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, errors.New("invalid signature length")
+	}
+	if sig[crypto.RecoveryIDOffset] != 27 && sig[crypto.RecoveryIDOffset] != 28 {
+		return common.Address{}, errors.New("invalid signature recovery id")
+	}
+	sig = common.CopyBytes(sig)
+	sig[crypto.RecoveryIDOffset] -= 27
+
+	pub, err := crypto.SigToPub(crypto.Keccak256(data), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// locked returns the total amount of the pending transfers of the state.
+func (s *State) locked() *big.Int {
+	total := new(big.Int)
+	for _, lock := range s.Locks {
+		total.Add(total, lock.Amount)
+	}
+	return total
+}
+
+// equal reports whether two states are the same, disregarding signatures.
+func (s *State) equal(other *State) bool {
+	return s.Channel == other.Channel && s.Nonce == other.Nonce && s.Final == other.Final &&
+		s.BalanceA.Cmp(other.BalanceA) == 0 && s.BalanceB.Cmp(other.BalanceB) == 0 &&
+		bytes.Equal(xchannel.EncodeLocks(s.Locks), xchannel.EncodeLocks(other.Locks))
+}
+
+// copy returns a deep copy of the state.
+func (s *State) copy() *State {
+	cpy := &State{
+		Channel:  s.Channel,
+		Nonce:    s.Nonce,
+		BalanceA: new(big.Int).Set(s.BalanceA),
+		BalanceB: new(big.Int).Set(s.BalanceB),
+		Final:    s.Final,
+		SigA:     common.CopyBytes(s.SigA),
+		SigB:     common.CopyBytes(s.SigB),
+	}
+	for _, lock := range s.Locks {
+		lock.Amount = new(big.Int).Set(lock.Amount)
+		cpy.Locks = append(cpy.Locks, lock)
+	}
+	return cpy
+}
+
+// Channel is the local view of a channel the local account is party to.
+type Channel struct {
+	ID        common.Hash
+	PartyA    common.Address
+	PartyB    common.Address
+	DepositA  *big.Int
+	DepositB  *big.Int
+	Challenge uint64 // Length of the dispute window in blocks
+	Status    Status
+	Deadline  uint64 // Block number the dispute window closes at, if disputed
+
+	Latest  *State `rlp:"nil"` // Most recent state signed by both parties, nil if none yet
+	Pending *State `rlp:"nil"` // Update proposed by the local party, awaiting countersigning
+}
+
+// latest returns the most recent state signed by both parties, which is the
+// unsigned opening state of the channel until the first update.
+func (c *Channel) latest() *State {
+	if c.Latest != nil {
+		return c.Latest
+	}
+	return &State{
+		Channel:  c.ID,
+		BalanceA: new(big.Int).Set(c.DepositA),
+		BalanceB: new(big.Int).Set(c.DepositB),
+	}
+}
+
+// total returns the total deposits of the channel.
+func (c *Channel) total() *big.Int {
+	return new(big.Int).Add(c.DepositA, c.DepositB)
+}
+
+// balances returns the balances of the parties in the latest state, crediting
+// partyB with the deposits made after it was signed, as the contract does.
+func (c *Channel) balances() (*big.Int, *big.Int) {
+	state := c.latest()
+
+	excess := c.total()
+	excess.Sub(excess, state.BalanceA)
+	excess.Sub(excess, state.BalanceB)
+	excess.Sub(excess, state.locked())
+
+	return new(big.Int).Set(state.BalanceA), excess.Add(excess, state.BalanceB)
+}
+
+// counterparty returns the other party of the channel.
+func (c *Channel) counterparty(account common.Address) common.Address {
+	if c.PartyA == account {
+		return c.PartyB
+	}
+	return c.PartyA
+}
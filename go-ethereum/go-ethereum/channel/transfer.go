@@ -0,0 +1,530 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	errNoRoute              = errors.New("no route found")
+	errInvalidRoute         = errors.New("invalid route")
+	errRouteMismatch        = errors.New("transfer doesn't match route")
+	errKnownTransfer        = errors.New("transfer already known")
+	errUnknownTransfer      = errors.New("unknown transfer")
+	errUnknownInvoice       = errors.New("unknown invoice")
+	errInsufficientFee      = errors.New("insufficient forwarding fee")
+	errInsufficientTimelock = errors.New("insufficient timelock")
+	errNotConnected         = errors.New("counterparty not connected")
+)
+
+// TransferStatus is the stage a multi-hop transfer is in.
+type TransferStatus uint8
+
+const (
+	TransferPending   TransferStatus = iota + 1 // Locked along the route, awaiting the secret
+	TransferSucceeded                           // Secret revealed by the payee
+	TransferFailed                              // Refunded along the route
+)
+
+// String implements fmt.Stringer.
+func (s TransferStatus) String() string {
+	switch s {
+	case TransferPending:
+		return "pending"
+	case TransferSucceeded:
+		return "succeeded"
+	case TransferFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("unknown(%d)", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s TransferStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Transfer is a hash-locked transfer over a route the local node takes part
+// in, as the payer, a forwarding node or the payee.
+type Transfer struct {
+	Hashlock common.Hash
+	Route    Route
+	Hop      uint64 // Index of the hop the local node pays, the length of the route for the payee
+	Status   TransferStatus
+	FailedAt common.Address // Node the transfer failed at, if it did
+	Reason   string         // Why the transfer failed, if it did
+}
+
+// incoming returns the hop paying the local node, nil for the payer.
+func (t *Transfer) incoming() *Hop {
+	if t.Hop == 0 {
+		return nil
+	}
+	return &t.Route[t.Hop-1]
+}
+
+// outgoing returns the hop the local node pays, nil for the payee.
+func (t *Transfer) outgoing() *Hop {
+	if t.Hop >= uint64(len(t.Route)) {
+		return nil
+	}
+	return &t.Route[t.Hop]
+}
+
+// failure is why a transfer failed, propagated back along its route.
+type failure struct {
+	Node   common.Address // Node the transfer failed at
+	Reason string
+}
+
+// message is a message to the counterparty of a channel, delivered once the
+// service lock is released.
+type message struct {
+	to   common.Address
+	code uint64
+	data interface{}
+}
+
+// invoice is a payment request of the local node. Its secret is revealed upon
+// receiving a transfer of at least the amount under its hashlock.
+type invoice struct {
+	Secret common.Hash
+	Amount *big.Int
+}
+
+// Invoice creates a payment request for an amount, returning the hashlock the
+// payer locks its transfer under.
+func (s *Service) Invoice(amount *big.Int) (common.Hash, error) {
+	if amount.Sign() <= 0 {
+		return common.Hash{}, errInvalidAmount
+	}
+	var secret common.Hash
+	if _, err := crand.Read(secret[:]); err != nil {
+		return common.Hash{}, err
+	}
+	hashlock := xchannel.Hashlock(secret)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	writeInvoice(s.db, hashlock, &invoice{Secret: secret, Amount: new(big.Int).Set(amount)})
+	return hashlock, nil
+}
+
+// Routes returns up to n candidate routes to pay an amount to a payee, cheapest
+// first, charging at most maxFee in fees if given.
+func (s *Service) Routes(ctx context.Context, payee common.Address, amount *big.Int, maxFee *big.Int, n int) ([]Route, error) {
+	if amount.Sign() <= 0 {
+		return nil, errInvalidAmount
+	}
+	head, err := s.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Make sure the capacities of the local channels are current
+	s.announce()
+
+	routes := s.graph.routes(s.account, payee, amount, head.Number.Uint64(), routeLimits{
+		maxFee:      maxFee,
+		maxTimelock: s.config.MaxTimelock,
+		final:       s.config.FinalTimelock,
+	}, n)
+	if len(routes) == 0 {
+		return nil, errNoRoute
+	}
+	return routes, nil
+}
+
+// Send pays over a route, locking the transfer to the first hop under the
+// hashlock of an invoice of the payee. The transfer is forwarded by the nodes
+// along the route and the secret revealed back along it, the progress being
+// reported by Transfer.
+//
+// A transfer paid by the local party which is neither unlocked nor cancelled
+// by the counterparty before it expires can only be refunded by a dispute.
+func (s *Service) Send(route Route, hashlock common.Hash) (*Transfer, error) {
+	t, update, err := s.send(route, hashlock)
+	if err != nil {
+		return nil, err
+	}
+	s.deliver(route[0].Node, TransferMsg, &transferData{Update: update, Route: route})
+	return t, nil
+}
+
+// send creates and signs the update locking the transfer to the first hop of a
+// route.
+func (s *Service) send(route Route, hashlock common.Hash) (*Transfer, *State, error) {
+	if len(route) == 0 {
+		return nil, nil, errInvalidRoute
+	}
+	for i, hop := range route {
+		if hop.Amount == nil || hop.Amount.Sign() <= 0 {
+			return nil, nil, errInvalidRoute
+		}
+		if i > 0 && (hop.Amount.Cmp(route[i-1].Amount) > 0 || hop.Expiration > route[i-1].Expiration) {
+			return nil, nil, errInvalidRoute
+		}
+	}
+	if s.peers.Peer(route[0].Node) == nil {
+		return nil, nil, errNotConnected
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if readTransfer(s.db, hashlock) != nil {
+		return nil, nil, errKnownTransfer
+	}
+	c := readChannel(s.db, route[0].Channel)
+	if c == nil {
+		return nil, nil, errUnknownChannel
+	}
+	if c.counterparty(s.account) != route[0].Node {
+		return nil, nil, errRouteMismatch
+	}
+	update, err := s.lockTransfer(c, route[0].Amount, route[0].Expiration, hashlock)
+	if err != nil {
+		return nil, nil, err
+	}
+	t := &Transfer{Hashlock: hashlock, Route: route, Status: TransferPending}
+	writeTransfer(s.db, t)
+
+	log.Info("Sending transfer", "hashlock", hashlock, "amount", route.Amount(), "fee", route.Fee(), "hops", len(route))
+	return t, update, nil
+}
+
+// Transfer returns the multi-hop transfer with the given hashlock.
+func (s *Service) Transfer(hashlock common.Hash) (*Transfer, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t := readTransfer(s.db, hashlock)
+	if t == nil {
+		return nil, errUnknownTransfer
+	}
+	return t, nil
+}
+
+// Transfers returns all the multi-hop transfers the local node took part in.
+func (s *Service) Transfers() []*Transfer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return readTransfers(s.db)
+}
+
+// acceptTransfer countersigns an update proposed by the counterparty locking a
+// transfer to be forwarded along a route, after checking the local node gets
+// its due for forwarding it. The update locking the transfer to the next hop is
+// returned, nil if the local node is the payee or forwarding failed. Failed
+// transfers are cancelled by resolve.
+func (s *Service) acceptTransfer(ctx context.Context, transfer *transferData) (*State, *State, error) {
+	head, err := s.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	update, route := transfer.Update, transfer.Route
+	c := readChannel(s.db, update.Channel)
+	if c == nil {
+		return nil, nil, errUnknownChannel
+	}
+	if len(update.Locks) != len(c.latest().Locks)+1 {
+		return nil, nil, errInvalidTransfers
+	}
+	lock := update.Locks[len(update.Locks)-1]
+	if lock.Amount == nil {
+		return nil, nil, errInvalidTransfers
+	}
+	hop, err := s.checkRoute(c, lock, route, head.Number.Uint64())
+	if err != nil {
+		return nil, nil, err
+	}
+	signed, err := s.accept(ctx, update, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	t := &Transfer{Hashlock: lock.Hashlock, Route: route, Hop: uint64(hop) + 1, Status: TransferPending}
+
+	// If the local node is the payee, reveal the secret of the invoice
+	if t.outgoing() == nil {
+		writeSecret(s.db, lock.Hashlock, readInvoice(s.db, lock.Hashlock).Secret)
+		t.Status = TransferSucceeded
+		writeTransfer(s.db, t)
+
+		log.Info("Received transfer", "hashlock", lock.Hashlock, "amount", lock.Amount)
+		return signed, nil, nil
+	}
+	// Otherwise forward the transfer to the next hop
+	var (
+		next    = t.outgoing()
+		forward *State
+	)
+	if s.peers.Peer(next.Node) == nil {
+		err = errNotConnected
+	} else {
+		forward, err = s.lockTransfer(readChannel(s.db, next.Channel), next.Amount, next.Expiration, lock.Hashlock)
+	}
+	if err != nil {
+		log.Debug("Failed to forward transfer", "hashlock", lock.Hashlock, "next", next.Node, "err", err)
+		t.Status, t.FailedAt, t.Reason = TransferFailed, s.account, err.Error()
+	}
+	writeTransfer(s.db, t)
+	return signed, forward, nil
+}
+
+// checkRoute checks the route of a transfer locked to the local node, returning
+// the index of the hop paying it.
+func (s *Service) checkRoute(c *Channel, lock xchannel.Lock, route Route, head uint64) (int, error) {
+	hop := -1
+	for i := range route {
+		if route[i].Channel == c.ID && route[i].Node == s.account {
+			hop = i
+			break
+		}
+	}
+	if hop < 0 || route[hop].Amount == nil || route[hop].Amount.Cmp(lock.Amount) != 0 || route[hop].Expiration != lock.Expiration {
+		return 0, errRouteMismatch
+	}
+	if readTransfer(s.db, lock.Hashlock) != nil {
+		return 0, errKnownTransfer
+	}
+	if lock.Expiration <= head+s.config.TimelockDelta {
+		return 0, errInsufficientTimelock
+	}
+	if hop == len(route)-1 {
+		inv := readInvoice(s.db, lock.Hashlock)
+		if inv == nil {
+			return 0, errUnknownInvoice
+		}
+		if lock.Amount.Cmp(inv.Amount) < 0 {
+			return 0, errInvalidAmount
+		}
+		return hop, nil
+	}
+	next := route[hop+1]
+	out := readChannel(s.db, next.Channel)
+	if out == nil || out.Status != StatusOpen {
+		return 0, errUnknownChannel
+	}
+	if out.counterparty(s.account) != next.Node {
+		return 0, errRouteMismatch
+	}
+	if next.Amount == nil || next.Amount.Sign() <= 0 {
+		return 0, errInvalidRoute
+	}
+	fee := new(big.Int).Sub(lock.Amount, next.Amount)
+	if fee.Cmp(forwardingFee(s.config.FeeBase, s.config.FeeRate, next.Amount)) < 0 {
+		return 0, errInsufficientFee
+	}
+	if lock.Expiration < next.Expiration+s.config.TimelockDelta {
+		return 0, errInsufficientTimelock
+	}
+	return hop, nil
+}
+
+// unlocked updates the transfers paid by the local party whose locks an update
+// of a channel removed: they succeeded if the secret is known, as the payer
+// only unlocks transfers then, or failed for the given reason otherwise, as the
+// payee only cancels them. The caller must hold the service lock.
+func (s *Service) unlocked(c *Channel, prev *State, fail *failure) {
+	latest := c.latest()
+	for _, lock := range prev.Locks {
+		if lock.Payer != s.account || hasLock(latest.Locks, lock.Hashlock) {
+			continue
+		}
+		t := readTransfer(s.db, lock.Hashlock)
+		if t == nil || t.Status != TransferPending || t.outgoing() == nil || t.outgoing().Channel != c.ID {
+			continue
+		}
+		if readSecret(s.db, lock.Hashlock) != (common.Hash{}) {
+			t.Status = TransferSucceeded
+			log.Info("Transfer succeeded", "hashlock", t.Hashlock)
+		} else {
+			t.Status, t.FailedAt, t.Reason = TransferFailed, c.counterparty(s.account), "transfer cancelled"
+			if fail != nil {
+				t.FailedAt, t.Reason = fail.Node, fail.Reason
+			}
+			log.Info("Transfer failed", "hashlock", t.Hashlock, "node", t.FailedAt, "reason", t.Reason)
+		}
+		writeTransfer(s.db, t)
+	}
+}
+
+// hasLock reports whether a list of locks contains one with the given hashlock.
+func hasLock(locks []xchannel.Lock, hashlock common.Hash) bool {
+	for _, lock := range locks {
+		if lock.Hashlock == hashlock {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve advances the transfers pending in the open channels: transfers paid
+// by the local party are unlocked once their secret is known, while transfers
+// paying it which failed further along their route are cancelled, carrying the
+// failure back along the route. Transfers paying it that the payer doesn't
+// unlock are claimed on-chain by claimExpiring.
+func (s *Service) resolve() {
+	var msgs []message
+
+	s.lock.Lock()
+	for _, c := range readChannels(s.db) {
+		if c.Status != StatusOpen {
+			continue
+		}
+		for _, lock := range c.latest().Locks {
+			if c.Pending != nil {
+				break
+			}
+			if lock.Payer == s.account {
+				if readSecret(s.db, lock.Hashlock) == (common.Hash{}) {
+					continue
+				}
+				update, err := s.removeLock(c, lock.Hashlock)
+				if err != nil {
+					log.Warn("Failed to unlock transfer", "id", c.ID, "hashlock", lock.Hashlock, "err", err)
+					continue
+				}
+				msgs = append(msgs, message{c.counterparty(s.account), ProposeMsg, update})
+				continue
+			}
+			t := readTransfer(s.db, lock.Hashlock)
+			if t == nil || t.Status != TransferFailed || t.incoming() == nil || t.incoming().Channel != c.ID {
+				continue
+			}
+			update, err := s.removeLock(c, lock.Hashlock)
+			if err != nil {
+				log.Warn("Failed to cancel transfer", "id", c.ID, "hashlock", lock.Hashlock, "err", err)
+				continue
+			}
+			msgs = append(msgs, message{c.counterparty(s.account), CancelMsg, &cancelData{Update: update, Node: t.FailedAt, Reason: t.Reason}})
+		}
+	}
+	s.lock.Unlock()
+
+	for _, msg := range msgs {
+		s.deliver(msg.to, msg.code, msg.data)
+	}
+}
+
+// revealSecrets delivers the known secrets of the transfers paying the local
+// party to their payers, for them to unlock the transfers. Only the secret of
+// the given hashlock is delivered unless zero.
+func (s *Service) revealSecrets(hashlock common.Hash) {
+	var msgs []message
+
+	s.lock.Lock()
+	for _, c := range readChannels(s.db) {
+		if c.Status != StatusOpen {
+			continue
+		}
+		for _, lock := range c.latest().Locks {
+			if lock.Payer == s.account || (hashlock != (common.Hash{}) && lock.Hashlock != hashlock) {
+				continue
+			}
+			if secret := readSecret(s.db, lock.Hashlock); secret != (common.Hash{}) {
+				msgs = append(msgs, message{c.counterparty(s.account), RevealMsg, &revealData{Channel: c.ID, Secret: secret}})
+			}
+		}
+	}
+	s.lock.Unlock()
+
+	for _, msg := range msgs {
+		s.deliver(msg.to, msg.code, msg.data)
+	}
+}
+
+// announce signs and gossips the capacity hints of the open channels of the
+// local party whose capacity or forwarding policy changed since announced, or
+// which weren't announced for a while.
+func (s *Service) announce() {
+	var hints []*Hint
+
+	s.lock.Lock()
+	now := uint64(time.Now().Unix())
+	for _, c := range readChannels(s.db) {
+		if c.Status != StatusOpen {
+			continue
+		}
+		balanceA, balanceB := c.balances()
+		capacity := balanceA
+		if c.PartyB == s.account {
+			capacity = balanceB
+		}
+		feeBase := new(big.Int)
+		if s.config.FeeBase != nil {
+			feeBase.Set(s.config.FeeBase)
+		}
+		prev := s.graph.hint(c.ID, s.account)
+		if prev != nil && prev.Capacity.Cmp(capacity) == 0 && prev.FeeBase.Cmp(feeBase) == 0 &&
+			prev.FeeRate == s.config.FeeRate && prev.TimelockDelta == s.config.TimelockDelta &&
+			prev.Timestamp+uint64(hintRefresh/time.Second) > now {
+			continue
+		}
+		h := &Hint{
+			Channel:       c.ID,
+			Account:       s.account,
+			Capacity:      capacity,
+			FeeBase:       feeBase,
+			FeeRate:       s.config.FeeRate,
+			TimelockDelta: s.config.TimelockDelta,
+			Timestamp:     now,
+		}
+		if prev != nil && h.Timestamp <= prev.Timestamp {
+			h.Timestamp = prev.Timestamp + 1
+		}
+		sig, err := s.signData(h.data(s.domain))
+		if err != nil {
+			log.Warn("Failed to sign capacity hint", "id", c.ID, "err", err)
+			continue
+		}
+		h.Signature = sig
+		if err := s.graph.addHint(h); err != nil {
+			log.Warn("Failed to add capacity hint", "id", c.ID, "err", err)
+			continue
+		}
+		hints = append(hints, h)
+	}
+	s.lock.Unlock()
+
+	for _, h := range hints {
+		s.relay(h, common.Address{})
+	}
+}
+
+// relay gossips a capacity hint to all peers but the one it came from.
+func (s *Service) relay(h *Hint, from common.Address) {
+	for _, p := range s.peers.Peers() {
+		if p.account != from {
+			p.queue(HintMsg, h)
+		}
+	}
+}
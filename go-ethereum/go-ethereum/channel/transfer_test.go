@@ -0,0 +1,359 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+var (
+	keyC, _ = crypto.HexToECDSA("49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee")
+	addrC   = crypto.PubkeyToAddress(keyC.PublicKey)
+)
+
+// routeTester runs the channel services of three parties on a simulated chain,
+// with channels from A to B and from B to C, connected over the xch protocol.
+type routeTester struct {
+	t        *testing.T
+	backend  *simBackend
+	a, b, c  *Service
+	ab, bc   common.Hash
+	teardown []func()
+}
+
+func newRouteTester(t *testing.T) *routeTester {
+	backend := &simBackend{backends.NewSimulatedBackend(core.GenesisAlloc{
+		addrA: {Balance: ether(100)},
+		addrB: {Balance: ether(100)},
+		addrC: {Balance: ether(100)},
+	}, 10000000)}
+
+	opts, _ := bind.NewKeyedTransactorWithChainID(keyA, big.NewInt(1337))
+	address, _, _, err := contract.DeployXChannel(opts, backend)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	backend.Commit()
+
+	rt := &routeTester{t: t, backend: backend}
+	dial := func() (Backend, error) { return backend, nil }
+	config := &Config{
+		Contract:      address,
+		FeeBase:       big.NewInt(1000),
+		FeeRate:       1000,
+		TimelockDelta: 10,
+		FinalTimelock: 20,
+		MaxTimelock:   100,
+	}
+	var services []*Service
+	for _, key := range []*ecdsa.PrivateKey{keyA, keyB, keyC} {
+		nodeKey, _ := crypto.GenerateKey()
+		s := newService(config, rawdb.NewMemoryDatabase(), crypto.PubkeyToAddress(key.PublicKey), &keySigner{key}, nodeKey, dial)
+		if err := s.setup(); err != nil {
+			t.Fatalf("failed to set up service: %v", err)
+		}
+		services = append(services, s)
+	}
+	rt.a, rt.b, rt.c = services[0], services[1], services[2]
+
+	rt.ab = rt.open(rt.a, rt.b, ether(10))
+	rt.bc = rt.open(rt.b, rt.c, ether(10))
+	rt.connect(rt.a, rt.b)
+	rt.connect(rt.b, rt.c)
+	return rt
+}
+
+// close tears down the connections between the services.
+func (rt *routeTester) close() {
+	for _, teardown := range rt.teardown {
+		teardown()
+	}
+}
+
+// commit mines the pending transactions and lets the services process the new
+// block.
+func (rt *routeTester) commit() {
+	rt.backend.Commit()
+	for _, s := range []*Service{rt.a, rt.b, rt.c} {
+		s.sync()
+	}
+}
+
+// open opens a channel funded by its first party.
+func (rt *routeTester) open(from, to *Service, deposit *big.Int) common.Hash {
+	rt.t.Helper()
+
+	tx, err := from.Open(context.Background(), to.account, deposit, 5)
+	if err != nil {
+		rt.t.Fatalf("failed to open channel: %v", err)
+	}
+	rt.commit()
+
+	receipt, _ := rt.backend.TransactionReceipt(context.Background(), tx.Hash())
+	id, err := from.opened(receipt)
+	if err != nil {
+		rt.t.Fatalf("channel not opened: %v", err)
+	}
+	return id
+}
+
+// connect runs the xch protocol between two services.
+func (rt *routeTester) connect(x, y *Service) {
+	rt.t.Helper()
+	rt.connectSilent(x, y)
+}
+
+// silentMsgReadWriter is a message stream dropping the messages written to it
+// with the given codes.
+type silentMsgReadWriter struct {
+	p2p.MsgReadWriter
+	codes []uint64
+}
+
+func (rw *silentMsgReadWriter) WriteMsg(msg p2p.Msg) error {
+	for _, code := range rw.codes {
+		if msg.Code == code {
+			return msg.Discard()
+		}
+	}
+	return rw.MsgReadWriter.WriteMsg(msg)
+}
+
+// connectSilent runs the xch protocol between two services like connect, the
+// first one never delivering messages with the given codes to the second.
+func (rt *routeTester) connectSilent(x, y *Service, codes ...uint64) {
+	rt.t.Helper()
+
+	app, net := p2p.MsgPipe()
+	var rw p2p.MsgReadWriter = app
+	if len(codes) > 0 {
+		rw = &silentMsgReadWriter{MsgReadWriter: app, codes: codes}
+	}
+	go x.handle(newTestPeer(y, rw))
+	go y.handle(newTestPeer(x, net))
+
+	rt.waitFor("peers registered", func() bool {
+		return x.peers.Peer(y.account) != nil && y.peers.Peer(x.account) != nil
+	})
+	rt.teardown = append(rt.teardown, func() {
+		app.Close()
+		net.Close()
+	})
+}
+
+// waitFor waits until a condition holds, failing the test if it doesn't in time.
+func (rt *routeTester) waitFor(what string, cond func() bool) {
+	rt.t.Helper()
+	waitFor(rt.t, what, cond)
+}
+
+// transferred reports whether a service saw a transfer reach the given status,
+// with no transfer or update left pending in its channels.
+func (rt *routeTester) transferred(s *Service, hashlock common.Hash, status TransferStatus) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t := readTransfer(s.db, hashlock)
+	if t == nil || t.Status != status {
+		return false
+	}
+	for _, c := range readChannels(s.db) {
+		if c.Pending != nil || len(c.latest().Locks) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// balances returns the balances of the parties of a channel, as seen by one.
+func (rt *routeTester) balances(s *Service, id common.Hash) (*big.Int, *big.Int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return readChannel(s.db, id).balances()
+}
+
+// checkBalances verifies the view both parties have of the balances of a channel.
+func (rt *routeTester) checkBalances(x, y *Service, id common.Hash, balanceA, balanceB *big.Int) {
+	rt.t.Helper()
+
+	for _, s := range []*Service{x, y} {
+		a, b := rt.balances(s, id)
+		if a.Cmp(balanceA) != 0 || b.Cmp(balanceB) != 0 {
+			rt.t.Errorf("%x: balances mismatch: have %v/%v, want %v/%v", s.account, a, b, balanceA, balanceB)
+		}
+	}
+}
+
+func TestMultiHopTransfer(t *testing.T) {
+	rt := newRouteTester(t)
+	defer rt.close()
+
+	// A finds the route to C through B once the capacity hints arrived
+	hashlock, err := rt.c.Invoice(ether(1))
+	if err != nil {
+		t.Fatalf("failed to create invoice: %v", err)
+	}
+	var routes []Route
+	rt.waitFor("route", func() bool {
+		routes, err = rt.a.Routes(context.Background(), addrC, ether(1), nil, 5)
+		return err == nil
+	})
+	fee := big.NewInt(1000 + 1000000000000000) // Flat fee plus a thousandth
+	route := routes[0]
+	if len(route) != 2 || route[0].Node != addrB || route[1].Node != addrC {
+		t.Fatalf("route mismatch: have %+v", route)
+	}
+	if route.Fee().Cmp(fee) != 0 {
+		t.Fatalf("fee mismatch: have %v, want %v", route.Fee(), fee)
+	}
+	if route[0].Expiration != route[1].Expiration+10 {
+		t.Fatalf("expirations not decrementing: %d, %d", route[0].Expiration, route[1].Expiration)
+	}
+	if _, err := rt.a.Send(route, hashlock); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	if _, err := rt.a.Send(route, hashlock); err != errKnownTransfer {
+		t.Fatalf("resend error mismatch: have %v, want %v", err, errKnownTransfer)
+	}
+	// The secret travels back along the route, unlocking every transfer
+	for _, s := range []*Service{rt.c, rt.b, rt.a} {
+		s := s
+		rt.waitFor("transfer unlocked", func() bool { return rt.transferred(s, hashlock, TransferSucceeded) })
+	}
+	sent := new(big.Int).Add(ether(1), fee)
+	rt.checkBalances(rt.a, rt.b, rt.ab, new(big.Int).Sub(ether(10), sent), sent)
+	rt.checkBalances(rt.b, rt.c, rt.bc, ether(9), ether(1))
+
+	// Capacities drop along the route as hinted
+	rt.commit()
+	rt.waitFor("capacity hint", func() bool {
+		h := rt.a.graph.hint(rt.bc, addrB)
+		return h != nil && h.Capacity.Cmp(ether(9)) == 0
+	})
+	if _, err := rt.a.Routes(context.Background(), addrC, ether(10), nil, 5); err != errNoRoute {
+		t.Fatalf("route beyond capacity: have %v, want %v", err, errNoRoute)
+	}
+}
+
+func TestMultiHopFailure(t *testing.T) {
+	rt := newRouteTester(t)
+	defer rt.close()
+
+	var (
+		routes []Route
+		err    error
+	)
+	rt.waitFor("route", func() bool {
+		routes, err = rt.a.Routes(context.Background(), addrC, ether(1), nil, 5)
+		return err == nil
+	})
+	// A transfer to C without an invoice is refused by C, a transfer leaving too
+	// little in fees for B by B
+	stingy := append(Route{}, routes[0]...)
+	stingy[0].Amount = new(big.Int).Add(ether(1), big.NewInt(1))
+
+	tests := []struct {
+		route  Route
+		failed common.Address
+		reason error
+	}{
+		{routes[0], addrC, errUnknownInvoice},
+		{stingy, addrB, errInsufficientFee},
+	}
+	for i, test := range tests {
+		hashlock := common.Hash{byte(i + 1)}
+		if _, err := rt.a.Send(test.route, hashlock); err != nil {
+			t.Fatalf("test %d: failed to send: %v", i, err)
+		}
+		rt.waitFor("transfer cancelled", func() bool { return rt.transferred(rt.a, hashlock, TransferFailed) })
+
+		transfer, _ := rt.a.Transfer(hashlock)
+		if transfer.FailedAt != test.failed || !strings.Contains(transfer.Reason, test.reason.Error()) {
+			t.Errorf("test %d: failure mismatch: have %x %q, want %x %q", i, transfer.FailedAt, transfer.Reason, test.failed, test.reason)
+		}
+	}
+	// Refunds leave the balances unchanged
+	rt.waitFor("refunds", func() bool { return rt.transferred(rt.b, common.Hash{1}, TransferFailed) })
+	rt.checkBalances(rt.a, rt.b, rt.ab, ether(10), new(big.Int))
+	rt.checkBalances(rt.b, rt.c, rt.bc, ether(10), new(big.Int))
+}
+
+func TestSilentPayer(t *testing.T) {
+	rt := newRouteTester(t)
+	defer rt.close()
+
+	// A pays C through B, but never proposes to unlock the transfer to B
+	rt.teardown[0]()
+	rt.waitFor("peers unregistered", func() bool {
+		return rt.a.peers.Peer(addrB) == nil && rt.b.peers.Peer(addrA) == nil
+	})
+	rt.connectSilent(rt.a, rt.b, ProposeMsg)
+
+	hashlock, err := rt.c.Invoice(ether(1))
+	if err != nil {
+		t.Fatalf("failed to create invoice: %v", err)
+	}
+	var routes []Route
+	rt.waitFor("route", func() bool {
+		routes, err = rt.a.Routes(context.Background(), addrC, ether(1), nil, 5)
+		return err == nil
+	})
+	if _, err := rt.a.Send(routes[0], hashlock); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	rt.waitFor("transfer unlocked by B", func() bool { return rt.transferred(rt.c, hashlock, TransferSucceeded) })
+
+	// Close to the expiration of the transfer from A, B claims it on-chain
+	rt.b.lock.Lock()
+	lock := readChannel(rt.b.db, rt.ab).latest().Locks[0]
+	rt.b.lock.Unlock()
+
+	for rt.backend.Blockchain().CurrentBlock().NumberU64()+1+rt.b.config.TimelockDelta < lock.Expiration {
+		rt.commit()
+		if readChannel(rt.b.db, rt.ab).Status != StatusOpen {
+			t.Fatalf("channel disputed early")
+		}
+	}
+	rt.commit()
+	rt.commit()
+	if revealed, _ := rt.b.contract.Secrets(nil, hashlock); revealed.Sign() == 0 {
+		t.Fatalf("secret not revealed on-chain")
+	}
+	if c := readChannel(rt.b.db, rt.ab); c.Status != StatusDisputed {
+		t.Fatalf("channel status mismatch: have %v, want %v", c.Status, StatusDisputed)
+	}
+	// Once the dispute window closed, the transfer is paid out to B
+	rt.mineUntil(readChannel(rt.b.db, rt.ab).Deadline, rt.a, rt.b, rt.c)
+	rt.commit()
+	rt.commit()
+
+	rt.checkClosed(rt.ab, new(big.Int).Sub(ether(10), lock.Amount), lock.Amount)
+}
@@ -0,0 +1,499 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	errUnknownChannel      = errors.New("unknown channel")
+	errChannelNotOpen      = errors.New("channel not open")
+	errUpdatePending       = errors.New("channel update pending")
+	errPendingTransfers    = errors.New("channel has pending transfers")
+	errInvalidAmount       = errors.New("invalid amount")
+	errInsufficientBalance = errors.New("insufficient balance")
+	errInvalidSignature    = errors.New("invalid signature")
+	errInvalidNonce        = errors.New("invalid nonce")
+	errInvalidBalances     = errors.New("invalid balances")
+	errInvalidTransfers    = errors.New("pending transfers changed")
+	errStaleUpdate         = errors.New("stale update")
+	errUnknownLock         = errors.New("unknown pending transfer")
+	errDuplicateLock       = errors.New("duplicate hashlock")
+	errTooManyTransfers    = errors.New("too many pending transfers")
+)
+
+// maxPendingTransfers is the maximum number of transfers pending in a channel,
+// bounding the gas the settlement of a disputed channel takes.
+const maxPendingTransfers = 32
+
+// signData signs the keccak256 hash of some data with the local account.
+func (s *Service) signData(data []byte) ([]byte, error) {
+	sig, err := s.signer.SignData(data)
+	if err != nil {
+		return nil, err
+	}
+	// Signatures are verified on-chain, which expects the legacy recovery id
+	if sig[crypto.RecoveryIDOffset] < 27 {
+		sig[crypto.RecoveryIDOffset] += 27
+	}
+	return sig, nil
+}
+
+// sign signs a state on behalf of the local party.
+func (s *Service) sign(c *Channel, state *State) error {
+	sig, err := s.signData(state.data(s.domain))
+	if err != nil {
+		return err
+	}
+	if c.PartyA == s.account {
+		state.SigA = sig
+	} else {
+		state.SigB = sig
+	}
+	return nil
+}
+
+// verify checks that a signature over a state was produced by the given party.
+func (s *Service) verify(state *State, sig []byte, party common.Address) error {
+	signer, err := state.signer(s.domain, sig)
+	if err != nil {
+		return err
+	}
+	if signer != party {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// Pay proposes an update of a channel transferring an amount to the counterparty.
+// The returned update is signed by the local party only. It is delivered to the
+// counterparty for countersigning if connected, otherwise it has to be delivered
+// out of band and the countersigned update handed back via Receive. No other
+// update can be proposed in the meantime.
+func (s *Service) Pay(id common.Hash, amount *big.Int) (*State, error) {
+	c, state, err := s.pay(id, amount)
+	if err != nil {
+		return nil, err
+	}
+	s.deliver(c.counterparty(s.account), ProposeMsg, state)
+	return state.copy(), nil
+}
+
+// pay creates and signs an update of a channel transferring an amount to the
+// counterparty.
+func (s *Service) pay(id common.Hash, amount *big.Int) (*Channel, *State, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := readChannel(s.db, id)
+	if c == nil {
+		return nil, nil, errUnknownChannel
+	}
+	if c.Status != StatusOpen {
+		return nil, nil, errChannelNotOpen
+	}
+	if c.Pending != nil {
+		return nil, nil, errUpdatePending
+	}
+	if amount.Sign() <= 0 {
+		return nil, nil, errInvalidAmount
+	}
+	from, to := c.balances()
+	if c.PartyB == s.account {
+		from, to = to, from
+	}
+	if from.Cmp(amount) < 0 {
+		return nil, nil, errInsufficientBalance
+	}
+	from.Sub(from, amount)
+	to.Add(to, amount)
+
+	latest := c.latest().copy()
+	state := &State{
+		Channel:  id,
+		Nonce:    latest.Nonce + 1,
+		BalanceA: from,
+		BalanceB: to,
+		Locks:    latest.Locks,
+	}
+	if c.PartyB == s.account {
+		state.BalanceA, state.BalanceB = to, from
+	}
+	if err := s.sign(c, state); err != nil {
+		return nil, nil, err
+	}
+	c.Pending = state
+	writeChannel(s.db, c)
+
+	return c, state.copy(), nil
+}
+
+// Close proposes to close a channel cooperatively on its current balances. The
+// returned proposal is signed by the local party only. It is delivered to the
+// counterparty if connected, or has to be delivered out of band otherwise. The
+// counterparty submits the close on-chain once countersigned. Channels with
+// pending transfers can only be closed through a dispute.
+func (s *Service) Close(id common.Hash) (*State, error) {
+	c, state, err := s.close(id)
+	if err != nil {
+		return nil, err
+	}
+	s.deliver(c.counterparty(s.account), CloseMsg, state)
+	return state.copy(), nil
+}
+
+// close creates and signs the final state of a channel on its current balances.
+func (s *Service) close(id common.Hash) (*Channel, *State, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := readChannel(s.db, id)
+	if c == nil {
+		return nil, nil, errUnknownChannel
+	}
+	if c.Status != StatusOpen {
+		return nil, nil, errChannelNotOpen
+	}
+	if c.Pending != nil {
+		return nil, nil, errUpdatePending
+	}
+	latest := c.latest()
+	if len(latest.Locks) > 0 {
+		return nil, nil, errPendingTransfers
+	}
+	balanceA, balanceB := c.balances()
+	state := &State{
+		Channel:  id,
+		Nonce:    latest.Nonce,
+		BalanceA: balanceA,
+		BalanceB: balanceB,
+		Final:    true,
+	}
+	if err := s.sign(c, state); err != nil {
+		return nil, nil, err
+	}
+	c.Status, c.Pending = StatusClosing, state
+	writeChannel(s.db, c)
+
+	return c, state.copy(), nil
+}
+
+// lockTransfer creates and signs an update of a channel locking an amount for
+// the counterparty under a hashlock, refunded to the local party if the secret
+// isn't revealed by the expiration block. The caller must hold the service lock.
+func (s *Service) lockTransfer(c *Channel, amount *big.Int, expiration uint64, hashlock common.Hash) (*State, error) {
+	if c.Status != StatusOpen {
+		return nil, errChannelNotOpen
+	}
+	if c.Pending != nil {
+		return nil, errUpdatePending
+	}
+	if amount.Sign() <= 0 {
+		return nil, errInvalidAmount
+	}
+	latest := c.latest().copy()
+	if len(latest.Locks) >= maxPendingTransfers {
+		return nil, errTooManyTransfers
+	}
+	for _, lock := range latest.Locks {
+		if lock.Hashlock == hashlock {
+			return nil, errDuplicateLock
+		}
+	}
+	from, to := c.balances()
+	if c.PartyB == s.account {
+		from, to = to, from
+	}
+	if from.Cmp(amount) < 0 {
+		return nil, errInsufficientBalance
+	}
+	from.Sub(from, amount)
+
+	state := &State{
+		Channel:  c.ID,
+		Nonce:    latest.Nonce + 1,
+		BalanceA: from,
+		BalanceB: to,
+		Locks: append(latest.Locks, xchannel.Lock{
+			Amount:     new(big.Int).Set(amount),
+			Expiration: expiration,
+			Hashlock:   hashlock,
+			Payer:      s.account,
+		}),
+	}
+	if c.PartyB == s.account {
+		state.BalanceA, state.BalanceB = to, from
+	}
+	if err := s.sign(c, state); err != nil {
+		return nil, err
+	}
+	c.Pending = state
+	writeChannel(s.db, c)
+
+	return state.copy(), nil
+}
+
+// removeLock creates and signs an update of a channel removing a pending
+// transfer, crediting its amount to the counterparty: to the payee if the local
+// party paid it, as the secret is known, or back to the payer otherwise, as the
+// transfer failed. The caller must hold the service lock.
+func (s *Service) removeLock(c *Channel, hashlock common.Hash) (*State, error) {
+	if c.Status != StatusOpen {
+		return nil, errChannelNotOpen
+	}
+	if c.Pending != nil {
+		return nil, errUpdatePending
+	}
+	latest := c.latest().copy()
+
+	removed := -1
+	for i, lock := range latest.Locks {
+		if lock.Hashlock == hashlock {
+			removed = i
+			break
+		}
+	}
+	if removed < 0 {
+		return nil, errUnknownLock
+	}
+	balanceA, balanceB := c.balances()
+	if c.PartyA == s.account {
+		balanceB.Add(balanceB, latest.Locks[removed].Amount)
+	} else {
+		balanceA.Add(balanceA, latest.Locks[removed].Amount)
+	}
+	state := &State{
+		Channel:  c.ID,
+		Nonce:    latest.Nonce + 1,
+		BalanceA: balanceA,
+		BalanceB: balanceB,
+		Locks:    append(latest.Locks[:removed], latest.Locks[removed+1:]...),
+	}
+	if err := s.sign(c, state); err != nil {
+		return nil, err
+	}
+	c.Pending = state
+	writeChannel(s.db, c)
+
+	return state.copy(), nil
+}
+
+// Receive processes an update delivered by the counterparty of a channel and
+// returns it signed by both parties.
+//
+// An update proposed by the counterparty is validated and countersigned, an
+// update proposed by the local party and countersigned by the counterparty is
+// recorded. A countersigned cooperative close is queued for submission on-chain.
+func (s *Service) Receive(ctx context.Context, update *State) (*State, error) {
+	return s.receive(ctx, update, nil)
+}
+
+// receive processes an update delivered by the counterparty of a channel. The
+// failure, if given, is why the transfers the update cancels failed.
+func (s *Service) receive(ctx context.Context, update *State, fail *failure) (*State, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.accept(ctx, update, fail)
+}
+
+// accept processes an update delivered by the counterparty of a channel. The
+// caller must hold the service lock.
+func (s *Service) accept(ctx context.Context, update *State, fail *failure) (*State, error) {
+	c := readChannel(s.db, update.Channel)
+	if c == nil {
+		return nil, errUnknownChannel
+	}
+	if c.Status != StatusOpen && c.Status != StatusClosing {
+		return nil, errChannelNotOpen
+	}
+	if update.BalanceA == nil || update.BalanceB == nil {
+		return nil, errInvalidBalances
+	}
+	ours, theirs := update.SigA, update.SigB
+	if c.PartyB == s.account {
+		ours, theirs = theirs, ours
+	}
+	if err := s.verify(update, theirs, c.counterparty(s.account)); err != nil {
+		return nil, err
+	}
+	prev := c.latest()
+
+	// If the update was proposed by the local party, record it
+	if len(ours) > 0 {
+		if err := s.verify(update, ours, s.account); err != nil {
+			return nil, err
+		}
+		if err := s.record(ctx, c, update, false); err != nil {
+			return nil, err
+		}
+		s.unlocked(c, prev, fail)
+		s.reallocated(c, prev)
+		return update.copy(), nil
+	}
+	// Otherwise countersign the proposal of the counterparty
+	if c.Pending != nil {
+		return nil, errUpdatePending
+	}
+	if err := s.validate(c, update); err != nil {
+		return nil, err
+	}
+	update = update.copy()
+	if err := s.sign(c, update); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, c, update, true); err != nil {
+		return nil, err
+	}
+	s.unlocked(c, prev, fail)
+	s.reallocated(c, prev)
+	return update.copy(), nil
+}
+
+// validate checks an update proposed by the counterparty, which can only pay
+// the local party or close the channel on its current balances.
+func (s *Service) validate(c *Channel, update *State) error {
+	latest := c.latest()
+	balanceA, balanceB := c.balances()
+
+	if update.Final {
+		if update.Nonce != latest.Nonce {
+			return errInvalidNonce
+		}
+		if len(latest.Locks) > 0 || len(update.Locks) > 0 {
+			return errPendingTransfers
+		}
+		if update.BalanceA.Cmp(balanceA) != 0 || update.BalanceB.Cmp(balanceB) != 0 {
+			return errInvalidBalances
+		}
+		return nil
+	}
+	if c.Status != StatusOpen {
+		return errChannelNotOpen
+	}
+	if update.Nonce != latest.Nonce+1 {
+		return errInvalidNonce
+	}
+	if update.BalanceA.Sign() < 0 || update.BalanceB.Sign() < 0 {
+		return errInvalidBalances
+	}
+	for _, lock := range update.Locks {
+		if lock.Amount == nil || lock.Amount.Sign() <= 0 {
+			return errInvalidTransfers
+		}
+	}
+	total := new(big.Int).Add(update.BalanceA, update.BalanceB)
+	if total.Add(total, update.locked()).Cmp(c.total()) != 0 {
+		return errInvalidBalances
+	}
+	own, prev := update.BalanceA, balanceA
+	if c.PartyB == s.account {
+		own, prev = update.BalanceB, balanceB
+	}
+	// Beside paying the local party, the counterparty can lock a transfer to it
+	// or remove a pending transfer in its favour, one at a time
+	switch len(update.Locks) {
+	case len(latest.Locks):
+		if !bytes.Equal(xchannel.EncodeLocks(update.Locks), xchannel.EncodeLocks(latest.Locks)) {
+			return errInvalidTransfers
+		}
+		if own.Cmp(prev) < 0 {
+			return errInvalidBalances
+		}
+	case len(latest.Locks) + 1:
+		if !bytes.Equal(xchannel.EncodeLocks(update.Locks[:len(latest.Locks)]), xchannel.EncodeLocks(latest.Locks)) {
+			return errInvalidTransfers
+		}
+		if len(update.Locks) > maxPendingTransfers {
+			return errTooManyTransfers
+		}
+		lock := update.Locks[len(latest.Locks)]
+		if lock.Payer != c.counterparty(s.account) || lock.Virtual() {
+			return errInvalidTransfers
+		}
+		for _, pending := range latest.Locks {
+			if pending.Hashlock == lock.Hashlock {
+				return errDuplicateLock
+			}
+		}
+		if own.Cmp(prev) != 0 {
+			return errInvalidBalances
+		}
+	case len(latest.Locks) - 1:
+		removed := -1
+		for i := range latest.Locks {
+			rest := append(append([]xchannel.Lock{}, latest.Locks[:i]...), latest.Locks[i+1:]...)
+			if bytes.Equal(xchannel.EncodeLocks(update.Locks), xchannel.EncodeLocks(rest)) {
+				removed = i
+				break
+			}
+		}
+		if removed < 0 {
+			return errInvalidTransfers
+		}
+		if own.Cmp(new(big.Int).Add(prev, latest.Locks[removed].Amount)) != 0 {
+			return errInvalidBalances
+		}
+	default:
+		return errInvalidTransfers
+	}
+	return nil
+}
+
+// record stores an update signed by both parties. A cooperative close is queued
+// for the service loop to send on-chain if submit is set, which the party
+// completing the signatures does.
+func (s *Service) record(ctx context.Context, c *Channel, update *State, submit bool) error {
+	latest := c.latest()
+	if update.Final {
+		if update.Nonce != latest.Nonce {
+			return errStaleUpdate
+		}
+		if submit {
+			writeClose(s.db, update)
+			select {
+			case s.closeReq <- struct{}{}:
+			default:
+			}
+		}
+		c.Status, c.Pending = StatusClosing, nil
+		writeChannel(s.db, c)
+		return nil
+	}
+	// Re-deliveries of the latest state are fine, anything older isn't
+	if update.Nonce <= latest.Nonce {
+		if update.Nonce == latest.Nonce && update.equal(latest) {
+			return nil
+		}
+		return errStaleUpdate
+	}
+	c.Latest = update.copy()
+	if c.Pending != nil && c.Pending.Nonce <= update.Nonce {
+		c.Pending = nil
+	}
+	writeChannel(s.db, c)
+	s.backup(latest, c.Latest)
+	return nil
+}
@@ -0,0 +1,1247 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	errUnknownVirtual = errors.New("unknown virtual channel")
+	errKnownVirtual   = errors.New("virtual channel already known")
+	errVirtualNotOpen = errors.New("virtual channel not open")
+	errInvalidTerms   = errors.New("invalid virtual channel terms")
+	errInvalidFunding = errors.New("invalid virtual channel funding")
+	errNotFinal       = errors.New("virtual channel not final")
+	errNoLedger       = errors.New("no ledger channel with the hub")
+)
+
+// fundingTimeout is how long the hub holds on to the allocation proposed by one
+// ledger channel of a virtual channel, waiting for the other to propose its own.
+const fundingTimeout = time.Minute
+
+// VirtualStatus is the stage of its lifecycle a virtual channel is in.
+type VirtualStatus uint8
+
+const (
+	VirtualProposed VirtualStatus = iota + 1 // Opening state proposed to the counterparty
+	VirtualFunding                           // Opening state signed by both, allocation proposed to the hub
+	VirtualOpen                              // Allocated in the ledger channel, accepting updates
+	VirtualClosing                           // Final balances known, allocation being settled
+	VirtualDisputed                          // Dispute running on-chain
+	VirtualClosed                            // Allocation settled, or never made
+)
+
+// String implements fmt.Stringer.
+func (s VirtualStatus) String() string {
+	switch s {
+	case VirtualProposed:
+		return "proposed"
+	case VirtualFunding:
+		return "funding"
+	case VirtualOpen:
+		return "open"
+	case VirtualClosing:
+		return "closing"
+	case VirtualDisputed:
+		return "disputed"
+	case VirtualClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("unknown(%d)", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s VirtualStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// VirtualTerms are the terms of a virtual channel, agreed on by its parties and
+// checked by the hub before allocating funds to it.
+type VirtualTerms struct {
+	PartyA    common.Address
+	PartyB    common.Address
+	Hub       common.Address
+	DepositA  *big.Int
+	DepositB  *big.Int
+	Challenge uint64      // Length of the dispute window in blocks
+	Salt      common.Hash // Randomness telling apart virtual channels on the same terms
+	LedgerA   common.Hash // Ledger channel between partyA and the hub
+	LedgerB   common.Hash // Ledger channel between the hub and partyB
+}
+
+// total returns the total deposits of the virtual channel.
+func (t *VirtualTerms) total() *big.Int {
+	return new(big.Int).Add(t.DepositA, t.DepositB)
+}
+
+// id returns the id of the virtual channel in the given channel contract.
+func (t *VirtualTerms) id(contract common.Address) common.Hash {
+	return xchannel.VirtualID(contract, t.PartyA, t.PartyB, t.total(), t.Challenge, t.Salt)
+}
+
+// opening returns the unsigned opening state of the virtual channel.
+func (t *VirtualTerms) opening(id common.Hash) *State {
+	return &State{
+		Channel:  id,
+		BalanceA: new(big.Int).Set(t.DepositA),
+		BalanceB: new(big.Int).Set(t.DepositB),
+	}
+}
+
+// allocation returns the lock allocating the total of the virtual channel in
+// one of its ledger channels. The payer of the lock stands in for partyA: it is
+// partyA itself in the ledger channel of partyA, the hub in that of partyB.
+func (t *VirtualTerms) allocation(id common.Hash, ledger common.Hash) xchannel.Lock {
+	payer := t.PartyA
+	if ledger == t.LedgerB {
+		payer = t.Hub
+	}
+	return xchannel.Lock{Amount: t.total(), Hashlock: id, Payer: payer}
+}
+
+// equal reports whether two sets of terms are the same.
+func (t *VirtualTerms) equal(other *VirtualTerms) bool {
+	return t.PartyA == other.PartyA && t.PartyB == other.PartyB && t.Hub == other.Hub &&
+		t.DepositA.Cmp(other.DepositA) == 0 && t.DepositB.Cmp(other.DepositB) == 0 &&
+		t.Challenge == other.Challenge && t.Salt == other.Salt &&
+		t.LedgerA == other.LedgerA && t.LedgerB == other.LedgerB
+}
+
+// Virtual is the local view of a virtual channel the local account is party to
+// or the hub of. Virtual channels are funded by allocations in the ledger
+// channels both parties have with the hub, instead of deposits on-chain.
+type Virtual struct {
+	ID common.Hash
+	VirtualTerms
+	Status   VirtualStatus
+	Deadline uint64 // Block number the dispute window closes at, if disputed
+
+	Latest  *State // Most recent state signed by both parties, from the opening state on
+	Pending *State `rlp:"nil"` // Update proposed by the local party, awaiting countersigning
+	Final   *State `rlp:"nil"` // Final balances, signed by both parties or settled on-chain
+}
+
+// counterparty returns the other party of the virtual channel.
+func (v *Virtual) counterparty(account common.Address) common.Address {
+	if v.PartyA == account {
+		return v.PartyB
+	}
+	return v.PartyA
+}
+
+// ledger returns the ledger channel a party of the virtual channel has with the
+// hub.
+func (v *Virtual) ledger(account common.Address) common.Hash {
+	if v.PartyA == account {
+		return v.LedgerA
+	}
+	return v.LedgerB
+}
+
+// closable reports whether the final state of the virtual channel is signed by
+// both parties, rather than settled on-chain.
+func (v *Virtual) closable() bool {
+	return v.Final != nil && v.Final.Final && len(v.Final.SigA) > 0 && len(v.Final.SigB) > 0
+}
+
+// fundingHalves are the allocations to a virtual channel proposed to the hub by
+// its ledger channels, held until both arrived.
+type fundingHalves struct {
+	terms   VirtualTerms
+	opening *State
+	updates [2]*State // Allocations in the ledger channels of partyA and partyB
+	time    time.Time
+}
+
+// allocated returns the update of a ledger channel allocating the deposits of a
+// virtual channel: the party standing in for partyA contributes the deposit of
+// partyA, the other party the deposit of partyB.
+func allocated(c *Channel, id common.Hash, t *VirtualTerms) (*State, error) {
+	if c.Status != StatusOpen {
+		return nil, errChannelNotOpen
+	}
+	if c.Pending != nil {
+		return nil, errUpdatePending
+	}
+	latest := c.latest().copy()
+	if len(latest.Locks) >= maxPendingTransfers {
+		return nil, errTooManyTransfers
+	}
+	if hasLock(latest.Locks, id) {
+		return nil, errDuplicateLock
+	}
+	lock := t.allocation(id, c.ID)
+	if lock.Payer != c.PartyA && lock.Payer != c.PartyB {
+		return nil, errInvalidTerms
+	}
+	depositA, depositB := t.DepositA, t.DepositB
+	if lock.Payer != c.PartyA {
+		depositA, depositB = depositB, depositA
+	}
+	balanceA, balanceB := c.balances()
+	if balanceA.Cmp(depositA) < 0 || balanceB.Cmp(depositB) < 0 {
+		return nil, errInsufficientBalance
+	}
+	return &State{
+		Channel:  c.ID,
+		Nonce:    latest.Nonce + 1,
+		BalanceA: balanceA.Sub(balanceA, depositA),
+		BalanceB: balanceB.Sub(balanceB, depositB),
+		Locks:    append(latest.Locks, lock),
+	}, nil
+}
+
+// deallocated returns the update of a ledger channel settling the allocation of
+// a virtual channel on its final balances.
+func deallocated(c *Channel, id common.Hash, final *State) (*State, error) {
+	if c.Status != StatusOpen {
+		return nil, errChannelNotOpen
+	}
+	if c.Pending != nil {
+		return nil, errUpdatePending
+	}
+	latest := c.latest().copy()
+
+	removed := -1
+	for i, lock := range latest.Locks {
+		if lock.Virtual() && lock.Hashlock == id {
+			removed = i
+			break
+		}
+	}
+	if removed < 0 {
+		return nil, errUnknownLock
+	}
+	lock := latest.Locks[removed]
+	if new(big.Int).Add(final.BalanceA, final.BalanceB).Cmp(lock.Amount) != 0 {
+		return nil, errInvalidBalances
+	}
+	shareA, shareB := final.BalanceA, final.BalanceB
+	if lock.Payer != c.PartyA {
+		shareA, shareB = shareB, shareA
+	}
+	balanceA, balanceB := c.balances()
+	return &State{
+		Channel:  c.ID,
+		Nonce:    latest.Nonce + 1,
+		BalanceA: balanceA.Add(balanceA, shareA),
+		BalanceB: balanceB.Add(balanceB, shareB),
+		Locks:    append(latest.Locks[:removed], latest.Locks[removed+1:]...),
+	}, nil
+}
+
+// funds reports whether a ledger channel still allocates funds to a virtual
+// channel. The caller must hold the service lock.
+func (s *Service) funds(ledger common.Hash, id common.Hash) bool {
+	c := readChannel(s.db, ledger)
+	return c != nil && c.Status != StatusClosed && hasLock(c.latest().Locks, id)
+}
+
+// settled marks a virtual channel closed once none of the ledger channels of the
+// local party allocates funds to it anymore. The caller must hold the service
+// lock.
+func (s *Service) settled(v *Virtual) {
+	if v.Status == VirtualProposed || v.Status == VirtualFunding || v.Status == VirtualClosed {
+		return
+	}
+	if v.Hub == s.account {
+		if s.funds(v.LedgerA, v.ID) || s.funds(v.LedgerB, v.ID) {
+			return
+		}
+	} else if s.funds(v.ledger(s.account), v.ID) {
+		return
+	}
+	v.Status = VirtualClosed
+	log.Info("Virtual channel settled", "id", v.ID)
+}
+
+// ledgerWith returns an open ledger channel of the local party with an account,
+// with no update pending and the given amount available to allocate. The caller
+// must hold the service lock.
+func (s *Service) ledgerWith(account common.Address, amount *big.Int) *Channel {
+	for _, c := range readChannels(s.db) {
+		if c.Status != StatusOpen || c.Pending != nil || c.counterparty(s.account) != account {
+			continue
+		}
+		own, other := c.balances()
+		if c.PartyB == s.account {
+			own = other
+		}
+		if own.Cmp(amount) >= 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+// proposeLedger signs an update of a ledger channel proposed by the local party
+// and marks it pending. The caller must hold the service lock.
+func (s *Service) proposeLedger(c *Channel, update *State) error {
+	if err := s.sign(c, update); err != nil {
+		return err
+	}
+	c.Pending = update
+	writeChannel(s.db, c)
+	return nil
+}
+
+// signVirtual signs a state of a virtual channel on behalf of the local party.
+func (s *Service) signVirtual(v *Virtual, state *State) error {
+	sig, err := s.signData(state.data(s.domain))
+	if err != nil {
+		return err
+	}
+	if v.PartyA == s.account {
+		state.SigA = sig
+	} else {
+		state.SigB = sig
+	}
+	return nil
+}
+
+// checkTerms checks the terms of a virtual channel and its opening state signed
+// by partyA, returning the id of the virtual channel.
+func (s *Service) checkTerms(t *VirtualTerms, opening *State) (common.Hash, error) {
+	if t.DepositA == nil || t.DepositB == nil || t.DepositA.Sign() < 0 || t.DepositB.Sign() < 0 || t.total().Sign() == 0 {
+		return common.Hash{}, errInvalidTerms
+	}
+	if t.PartyA == t.PartyB || t.Hub == t.PartyA || t.Hub == t.PartyB || t.Hub == (common.Address{}) || t.Challenge == 0 {
+		return common.Hash{}, errInvalidTerms
+	}
+	id := t.id(s.config.Contract)
+	if opening == nil || opening.BalanceA == nil || opening.BalanceB == nil || !opening.equal(t.opening(id)) {
+		return common.Hash{}, errInvalidTerms
+	}
+	if err := s.verify(opening, opening.SigA, t.PartyA); err != nil {
+		return common.Hash{}, err
+	}
+	return id, nil
+}
+
+// checkFinal checks the final balances of a virtual channel, which are either
+// signed by both parties or settled on-chain. The hub only settles allocations
+// on the one outcome it can enforce.
+func (s *Service) checkFinal(ctx context.Context, v *Virtual, final *State) error {
+	if final == nil || final.Channel != v.ID || final.BalanceA == nil || final.BalanceB == nil {
+		return errNotFinal
+	}
+	if final.BalanceA.Sign() < 0 || final.BalanceB.Sign() < 0 || new(big.Int).Add(final.BalanceA, final.BalanceB).Cmp(v.total()) != 0 {
+		return errInvalidBalances
+	}
+	if v.Final != nil {
+		if final.BalanceA.Cmp(v.Final.BalanceA) != 0 || final.BalanceB.Cmp(v.Final.BalanceB) != 0 {
+			return errInvalidBalances
+		}
+		return nil
+	}
+	if final.Final {
+		if err := s.verify(final, final.SigA, v.PartyA); err != nil {
+			return err
+		}
+		return s.verify(final, final.SigB, v.PartyB)
+	}
+	onchain, err := s.onchainFinal(ctx, v)
+	if err != nil {
+		return err
+	}
+	if final.BalanceA.Cmp(onchain.BalanceA) != 0 || final.BalanceB.Cmp(onchain.BalanceB) != 0 {
+		return errInvalidBalances
+	}
+	return nil
+}
+
+// onchainFinal returns the final balances of a virtual channel settled on-chain,
+// either closed or past its dispute window.
+func (s *Service) onchainFinal(ctx context.Context, v *Virtual) (*State, error) {
+	onchain, err := s.contract.Virtuals(&bind.CallOpts{Context: ctx}, v.ID)
+	if err != nil {
+		return nil, err
+	}
+	head, err := s.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch onchain.Status.Uint64() {
+	case xchannel.StatusClosed:
+	case xchannel.StatusDisputed:
+		if head.Number.Cmp(onchain.Deadline) < 0 {
+			return nil, errNotFinal
+		}
+	default:
+		return nil, errNotFinal
+	}
+	return &State{
+		Channel:  v.ID,
+		Nonce:    onchain.Nonce.Uint64(),
+		BalanceA: onchain.BalanceA,
+		BalanceB: onchain.BalanceB,
+	}, nil
+}
+
+// OpenVirtual proposes a virtual channel to a counterparty with which the local
+// party shares a hub, funded with the given deposits from the ledger channels
+// both have with the hub instead of on-chain. Disputes on the virtual channel
+// last the given number of blocks.
+//
+// The counterparty countersigns the opening state, then both propose the hub to
+// allocate the deposits in their ledger channels. The virtual channel opens for
+// each party once the hub countersigned the allocation of its ledger channel.
+func (s *Service) OpenVirtual(hub, counterparty common.Address, depositA, depositB *big.Int, challenge uint64) (*Virtual, error) {
+	v, err := s.openVirtual(hub, counterparty, depositA, depositB, challenge)
+	if err != nil {
+		return nil, err
+	}
+	s.deliver(counterparty, VirtualMsg, &virtualData{Terms: v.VirtualTerms, Opening: v.Latest})
+	return v, nil
+}
+
+// openVirtual creates a virtual channel and signs its opening state.
+func (s *Service) openVirtual(hub, counterparty common.Address, depositA, depositB *big.Int, challenge uint64) (*Virtual, error) {
+	if counterparty == s.account || counterparty == (common.Address{}) || hub == s.account || hub == counterparty || hub == (common.Address{}) {
+		return nil, errInvalidCounterparty
+	}
+	if depositA.Sign() < 0 || depositB.Sign() < 0 || depositA.Sign()+depositB.Sign() == 0 {
+		return nil, errInvalidAmount
+	}
+	if challenge == 0 {
+		return nil, errInvalidTerms
+	}
+	if s.peers.Peer(counterparty) == nil || s.peers.Peer(hub) == nil {
+		return nil, errNotConnected
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ledger := s.ledgerWith(hub, depositA)
+	if ledger == nil {
+		return nil, errNoLedger
+	}
+	terms := VirtualTerms{
+		PartyA:    s.account,
+		PartyB:    counterparty,
+		Hub:       hub,
+		DepositA:  new(big.Int).Set(depositA),
+		DepositB:  new(big.Int).Set(depositB),
+		Challenge: challenge,
+		LedgerA:   ledger.ID,
+	}
+	if _, err := crand.Read(terms.Salt[:]); err != nil {
+		return nil, err
+	}
+	id := terms.id(s.config.Contract)
+
+	// Make sure the hub can allocate the deposits before proposing anything
+	if _, err := allocated(ledger, id, &terms); err != nil {
+		return nil, err
+	}
+	v := &Virtual{ID: id, VirtualTerms: terms, Status: VirtualProposed, Latest: terms.opening(id)}
+	if err := s.signVirtual(v, v.Latest); err != nil {
+		return nil, err
+	}
+	writeVirtual(s.db, v)
+
+	log.Info("Proposing virtual channel", "id", id, "counterparty", counterparty, "hub", hub)
+	return v, nil
+}
+
+// handleVirtual processes a virtual channel proposed by a peer, or the opening
+// state of a virtual channel proposed by the local party countersigned by it.
+func (s *Service) handleVirtual(p *peer, data *virtualData) {
+	msgs, err := s.acceptVirtual(p.account, data)
+	if err != nil {
+		p.Log().Debug("Rejecting virtual channel", "id", data.Opening.Channel, "err", err)
+		p.queue(RejectMsg, &rejectData{Channel: data.Opening.Channel, Reason: err.Error()})
+		return
+	}
+	for _, msg := range msgs {
+		s.deliver(msg.to, msg.code, msg.data)
+	}
+}
+
+// acceptVirtual countersigns the opening state of a virtual channel proposed by
+// a peer, or records the opening state countersigned by the peer. Either way,
+// the local party proposes the hub to allocate its deposit in its ledger channel.
+func (s *Service) acceptVirtual(from common.Address, data *virtualData) ([]message, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	terms, opening := data.Terms, data.Opening
+	id, err := s.checkTerms(&terms, opening)
+	if err != nil {
+		return nil, err
+	}
+	v := readVirtual(s.db, id)
+	switch {
+	case v == nil && terms.PartyA == from && terms.PartyB == s.account:
+		// Virtual channel proposed, fill in the ledger channel with the hub
+		if s.peers.Peer(terms.Hub) == nil {
+			return nil, errNotConnected
+		}
+		ledger := s.ledgerWith(terms.Hub, terms.DepositB)
+		if ledger == nil {
+			return nil, errNoLedger
+		}
+		terms.LedgerB = ledger.ID
+
+		update, err := allocated(ledger, id, &terms)
+		if err != nil {
+			return nil, err
+		}
+		v = &Virtual{ID: id, VirtualTerms: terms, Status: VirtualFunding, Latest: opening.copy()}
+		if err := s.signVirtual(v, v.Latest); err != nil {
+			return nil, err
+		}
+		if err := s.proposeLedger(ledger, update); err != nil {
+			return nil, err
+		}
+		writeVirtual(s.db, v)
+
+		log.Info("Accepted virtual channel", "id", id, "counterparty", from, "hub", terms.Hub)
+		return []message{
+			{from, VirtualMsg, &virtualData{Terms: terms, Opening: v.Latest.copy()}},
+			{terms.Hub, FundMsg, &fundData{Terms: terms, State: v.Latest.copy(), Update: update.copy()}},
+		}, nil
+
+	case v != nil && v.PartyA == s.account && v.PartyB == from && v.Status == VirtualProposed:
+		// Opening state countersigned, with the ledger channel of the counterparty
+		proposed := terms
+		proposed.LedgerB = common.Hash{}
+		if !proposed.equal(&v.VirtualTerms) || terms.LedgerB == (common.Hash{}) {
+			return nil, errInvalidTerms
+		}
+		if err := s.verify(opening, opening.SigB, v.PartyB); err != nil {
+			return nil, err
+		}
+		v.LedgerB, v.Latest = terms.LedgerB, opening.copy()
+
+		update, err := allocated(readChannel(s.db, v.LedgerA), id, &v.VirtualTerms)
+		if err == nil {
+			err = s.proposeLedger(readChannel(s.db, v.LedgerA), update)
+		}
+		if err != nil {
+			v.Status = VirtualClosed
+			writeVirtual(s.db, v)
+			return nil, err
+		}
+		v.Status = VirtualFunding
+		writeVirtual(s.db, v)
+
+		return []message{{v.Hub, FundMsg, &fundData{Terms: v.VirtualTerms, State: v.Latest.copy(), Update: update.copy()}}}, nil
+
+	default:
+		return nil, errKnownVirtual
+	}
+}
+
+// handleFund processes an update of a ledger channel proposed by a peer to the
+// local party as the hub of a virtual channel, either allocating funds to it or
+// settling the allocation.
+func (s *Service) handleFund(p *peer, fund *fundData) {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	msgs, err := s.fund(ctx, p.account, fund)
+	if err != nil {
+		s.reply(p, fund.Update, nil, err)
+		return
+	}
+	for _, msg := range msgs {
+		s.deliver(msg.to, msg.code, msg.data)
+	}
+}
+
+// fund processes an update of a ledger channel proposed to the local party as
+// the hub of a virtual channel.
+func (s *Service) fund(ctx context.Context, from common.Address, fund *fundData) ([]message, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if fund.Terms.Hub != s.account || fund.Terms.DepositA == nil || fund.Terms.DepositB == nil {
+		return nil, errInvalidFunding
+	}
+	c := readChannel(s.db, fund.Update.Channel)
+	if c == nil || c.counterparty(s.account) != from {
+		return nil, errUnknownChannel
+	}
+	if fund.Update.BalanceA == nil || fund.Update.BalanceB == nil {
+		return nil, errInvalidBalances
+	}
+	if v := readVirtual(s.db, fund.Terms.id(s.config.Contract)); v != nil {
+		return s.deallocate(ctx, v, c, from, fund)
+	}
+	return s.allocate(ctx, c, from, fund)
+}
+
+// checkUpdate checks that an update of a ledger channel proposed by the
+// counterparty is the expected one and signed by it.
+func (s *Service) checkUpdate(c *Channel, update *State, expected *State) error {
+	if !expected.equal(update) {
+		return errInvalidFunding
+	}
+	theirs := update.SigA
+	if c.PartyA == s.account {
+		theirs = update.SigB
+	}
+	return s.verify(update, theirs, c.counterparty(s.account))
+}
+
+// allocate holds on to the allocation of a virtual channel proposed by one of
+// its ledger channels until the other proposed its own, then countersigns both.
+// The hub thus never funds one side of a virtual channel only. The caller must
+// hold the service lock.
+func (s *Service) allocate(ctx context.Context, c *Channel, from common.Address, fund *fundData) ([]message, error) {
+	id, err := s.checkTerms(&fund.Terms, fund.State)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verify(fund.State, fund.State.SigB, fund.Terms.PartyB); err != nil {
+		return nil, err
+	}
+	var side int
+	switch {
+	case from == fund.Terms.PartyA && c.ID == fund.Terms.LedgerA:
+		side = 0
+	case from == fund.Terms.PartyB && c.ID == fund.Terms.LedgerB:
+		side = 1
+	default:
+		return nil, errInvalidFunding
+	}
+	expected, err := allocated(c, id, &fund.Terms)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUpdate(c, fund.Update, expected); err != nil {
+		return nil, err
+	}
+	halves := s.funding[id]
+	if halves == nil {
+		halves = &fundingHalves{terms: fund.Terms, opening: fund.State.copy(), time: time.Now()}
+		s.funding[id] = halves
+	} else if !halves.terms.equal(&fund.Terms) {
+		return nil, errInvalidFunding
+	}
+	halves.updates[side] = fund.Update.copy()
+	if halves.updates[0] == nil || halves.updates[1] == nil {
+		return nil, nil
+	}
+	delete(s.funding, id)
+
+	// Both ledger channels proposed their allocation, countersign them at once
+	// unless either can't be made anymore
+	var msgs []message
+	for _, update := range halves.updates {
+		ledger := readChannel(s.db, update.Channel)
+		expected, err := allocated(ledger, id, &halves.terms)
+		if err == nil {
+			err = s.checkUpdate(ledger, update, expected)
+		}
+		if err != nil {
+			return rejectHalves(halves, err), nil
+		}
+	}
+	for _, update := range halves.updates {
+		ledger := readChannel(s.db, update.Channel)
+		signed := update.copy()
+		if err := s.sign(ledger, signed); err != nil {
+			return rejectHalves(halves, err), nil
+		}
+		if err := s.record(ctx, ledger, signed, false); err != nil {
+			return rejectHalves(halves, err), nil
+		}
+		msgs = append(msgs, message{ledger.counterparty(s.account), AckMsg, signed})
+	}
+	writeVirtual(s.db, &Virtual{ID: id, VirtualTerms: halves.terms, Status: VirtualOpen, Latest: halves.opening})
+
+	log.Info("Virtual channel funded", "id", id, "partyA", halves.terms.PartyA, "partyB", halves.terms.PartyB)
+	return msgs, nil
+}
+
+// rejectHalves returns the rejections of the allocations held for a virtual
+// channel.
+func rejectHalves(halves *fundingHalves, err error) []message {
+	var msgs []message
+	for i, update := range halves.updates {
+		if update == nil {
+			continue
+		}
+		to := halves.terms.PartyA
+		if i == 1 {
+			to = halves.terms.PartyB
+		}
+		msgs = append(msgs, message{to, RejectMsg, &rejectData{Channel: update.Channel, Nonce: update.Nonce, Reason: err.Error()}})
+	}
+	return msgs
+}
+
+// expireFunding rejects the allocations held for virtual channels for which the
+// other ledger channel didn't propose its own in time.
+func (s *Service) expireFunding() {
+	var msgs []message
+
+	s.lock.Lock()
+	for id, halves := range s.funding {
+		if time.Since(halves.time) < fundingTimeout {
+			continue
+		}
+		delete(s.funding, id)
+		msgs = append(msgs, rejectHalves(halves, errInvalidFunding)...)
+		log.Warn("Virtual channel funding timed out", "id", id)
+	}
+	s.lock.Unlock()
+
+	for _, msg := range msgs {
+		s.deliver(msg.to, msg.code, msg.data)
+	}
+}
+
+// deallocate countersigns the settlement of the allocation of a virtual channel
+// in one of its ledger channels, on the final balances of the virtual channel.
+// The caller must hold the service lock.
+func (s *Service) deallocate(ctx context.Context, v *Virtual, c *Channel, from common.Address, fund *fundData) ([]message, error) {
+	if !(from == v.PartyA && c.ID == v.LedgerA) && !(from == v.PartyB && c.ID == v.LedgerB) {
+		return nil, errInvalidFunding
+	}
+	if err := s.checkFinal(ctx, v, fund.State); err != nil {
+		return nil, err
+	}
+	expected, err := deallocated(c, v.ID, fund.State)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUpdate(c, fund.Update, expected); err != nil {
+		return nil, err
+	}
+	signed := fund.Update.copy()
+	if err := s.sign(c, signed); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, c, signed, false); err != nil {
+		return nil, err
+	}
+	if v.Final == nil {
+		v.Final = fund.State.copy()
+	}
+	if v.Status != VirtualDisputed {
+		v.Status = VirtualClosing
+	}
+	s.settled(v)
+	writeVirtual(s.db, v)
+
+	return []message{{from, AckMsg, signed}}, nil
+}
+
+// reallocated updates the virtual channels of the local party whose allocation
+// an update of its ledger channel with the hub added or removed. The caller
+// must hold the service lock.
+func (s *Service) reallocated(c *Channel, prev *State) {
+	latest := c.latest()
+	for _, lock := range latest.Locks {
+		if !lock.Virtual() || hasLock(prev.Locks, lock.Hashlock) {
+			continue
+		}
+		if v := readVirtual(s.db, lock.Hashlock); v != nil && v.Status == VirtualFunding {
+			v.Status = VirtualOpen
+			writeVirtual(s.db, v)
+			log.Info("Virtual channel opened", "id", v.ID, "hub", v.Hub)
+		}
+	}
+	for _, lock := range prev.Locks {
+		if !lock.Virtual() || hasLock(latest.Locks, lock.Hashlock) {
+			continue
+		}
+		if v := readVirtual(s.db, lock.Hashlock); v != nil {
+			s.settled(v)
+			writeVirtual(s.db, v)
+		}
+	}
+}
+
+// PayVirtual proposes an update of a virtual channel transferring an amount to
+// the counterparty, delivered to it for countersigning.
+func (s *Service) PayVirtual(id common.Hash, amount *big.Int) (*State, error) {
+	v, state, err := s.payVirtual(id, amount)
+	if err != nil {
+		return nil, err
+	}
+	s.deliver(v.counterparty(s.account), ProposeMsg, state)
+	return state.copy(), nil
+}
+
+// payVirtual creates and signs an update of a virtual channel transferring an
+// amount to the counterparty.
+func (s *Service) payVirtual(id common.Hash, amount *big.Int) (*Virtual, *State, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	v, err := s.partyOf(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if v.Status != VirtualOpen {
+		return nil, nil, errVirtualNotOpen
+	}
+	if v.Pending != nil {
+		return nil, nil, errUpdatePending
+	}
+	if amount.Sign() <= 0 {
+		return nil, nil, errInvalidAmount
+	}
+	state := v.Latest.copy()
+	state.Nonce++
+	state.SigA, state.SigB = nil, nil
+
+	from, to := state.BalanceA, state.BalanceB
+	if v.PartyB == s.account {
+		from, to = to, from
+	}
+	if from.Cmp(amount) < 0 {
+		return nil, nil, errInsufficientBalance
+	}
+	from.Sub(from, amount)
+	to.Add(to, amount)
+
+	if err := s.signVirtual(v, state); err != nil {
+		return nil, nil, err
+	}
+	v.Pending = state
+	writeVirtual(s.db, v)
+
+	return v, state.copy(), nil
+}
+
+// CloseVirtual proposes to close a virtual channel cooperatively on its current
+// balances, delivered to the counterparty for countersigning. Once closed, both
+// parties propose the hub to settle the allocations of their ledger channels.
+func (s *Service) CloseVirtual(id common.Hash) (*State, error) {
+	v, state, err := s.closeVirtual(id)
+	if err != nil {
+		return nil, err
+	}
+	s.deliver(v.counterparty(s.account), CloseMsg, state)
+	return state.copy(), nil
+}
+
+// closeVirtual creates and signs the final state of a virtual channel on its
+// current balances.
+func (s *Service) closeVirtual(id common.Hash) (*Virtual, *State, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	v, err := s.partyOf(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if v.Status != VirtualOpen {
+		return nil, nil, errVirtualNotOpen
+	}
+	if v.Pending != nil {
+		return nil, nil, errUpdatePending
+	}
+	state := v.Latest.copy()
+	state.Final, state.SigA, state.SigB = true, nil, nil
+
+	if err := s.signVirtual(v, state); err != nil {
+		return nil, nil, err
+	}
+	v.Pending = state
+	writeVirtual(s.db, v)
+
+	return v, state.copy(), nil
+}
+
+// partyOf retrieves a virtual channel the local account is party to. The caller
+// must hold the service lock.
+func (s *Service) partyOf(id common.Hash) (*Virtual, error) {
+	v := readVirtual(s.db, id)
+	if v == nil || (v.PartyA != s.account && v.PartyB != s.account) {
+		return nil, errUnknownVirtual
+	}
+	return v, nil
+}
+
+// virtualCounterparty returns the counterparty of a virtual channel, zero if
+// unknown.
+func (s *Service) virtualCounterparty(id common.Hash) common.Address {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	v, err := s.partyOf(id)
+	if err != nil {
+		return common.Address{}
+	}
+	return v.counterparty(s.account)
+}
+
+// receiveVirtual processes an update of a virtual channel delivered by the
+// counterparty, returning it signed by both parties. Updates are only accepted
+// once the ledger channel of the local party allocated funds to the virtual
+// channel.
+func (s *Service) receiveVirtual(update *State) (*State, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	v, err := s.partyOf(update.Channel)
+	if err != nil {
+		return nil, err
+	}
+	if update.BalanceA == nil || update.BalanceB == nil {
+		return nil, errInvalidBalances
+	}
+	ours, theirs := update.SigA, update.SigB
+	if v.PartyB == s.account {
+		ours, theirs = theirs, ours
+	}
+	if err := s.verify(update, theirs, v.counterparty(s.account)); err != nil {
+		return nil, err
+	}
+	// If the update was proposed by the local party, record it
+	if len(ours) > 0 {
+		if err := s.verify(update, ours, s.account); err != nil {
+			return nil, err
+		}
+		if err := s.recordVirtual(v, update); err != nil {
+			return nil, err
+		}
+		return update.copy(), nil
+	}
+	// Otherwise countersign the proposal of the counterparty
+	if v.Status != VirtualOpen {
+		return nil, errVirtualNotOpen
+	}
+	if v.Pending != nil {
+		return nil, errUpdatePending
+	}
+	if err := s.validateVirtual(v, update); err != nil {
+		return nil, err
+	}
+	update = update.copy()
+	if err := s.signVirtual(v, update); err != nil {
+		return nil, err
+	}
+	if err := s.recordVirtual(v, update); err != nil {
+		return nil, err
+	}
+	return update.copy(), nil
+}
+
+// validateVirtual checks an update of a virtual channel proposed by the
+// counterparty, which can only pay the local party or close the virtual channel
+// on its current balances.
+func (s *Service) validateVirtual(v *Virtual, update *State) error {
+	latest := v.Latest
+	if len(update.Locks) > 0 {
+		return errInvalidTransfers
+	}
+	if update.Final {
+		if update.Nonce != latest.Nonce {
+			return errInvalidNonce
+		}
+		if update.BalanceA.Cmp(latest.BalanceA) != 0 || update.BalanceB.Cmp(latest.BalanceB) != 0 {
+			return errInvalidBalances
+		}
+		return nil
+	}
+	if update.Nonce != latest.Nonce+1 {
+		return errInvalidNonce
+	}
+	if update.BalanceA.Sign() < 0 || update.BalanceB.Sign() < 0 {
+		return errInvalidBalances
+	}
+	if new(big.Int).Add(update.BalanceA, update.BalanceB).Cmp(v.total()) != 0 {
+		return errInvalidBalances
+	}
+	own, prev := update.BalanceA, latest.BalanceA
+	if v.PartyB == s.account {
+		own, prev = update.BalanceB, latest.BalanceB
+	}
+	if own.Cmp(prev) < 0 {
+		return errInvalidBalances
+	}
+	return nil
+}
+
+// recordVirtual stores an update of a virtual channel signed by both parties.
+// The caller must hold the service lock.
+func (s *Service) recordVirtual(v *Virtual, update *State) error {
+	latest := v.Latest
+	if update.Final {
+		if update.Nonce != latest.Nonce || update.BalanceA.Cmp(latest.BalanceA) != 0 || update.BalanceB.Cmp(latest.BalanceB) != 0 {
+			return errStaleUpdate
+		}
+		if v.closable() {
+			return nil
+		}
+		v.Final, v.Pending, v.Status = update.copy(), nil, VirtualClosing
+		writeVirtual(s.db, v)
+
+		log.Info("Virtual channel closed", "id", v.ID, "balanceA", update.BalanceA, "balanceB", update.BalanceB)
+		return nil
+	}
+	// Re-deliveries of the latest state are fine, anything older isn't
+	if update.Nonce <= latest.Nonce {
+		if update.Nonce == latest.Nonce && update.equal(latest) {
+			return nil
+		}
+		return errStaleUpdate
+	}
+	v.Latest = update.copy()
+	if v.Pending != nil && v.Pending.Nonce <= update.Nonce {
+		v.Pending = nil
+	}
+	writeVirtual(s.db, v)
+	return nil
+}
+
+// rejectedVirtual drops a rejected update of a virtual channel, reporting
+// whether it was pending. A rejected proposal closes the virtual channel.
+func (s *Service) rejectedVirtual(p *peer, reject *rejectData) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	v, err := s.partyOf(reject.Channel)
+	if err != nil || v.counterparty(s.account) != p.account {
+		return false
+	}
+	if v.Status == VirtualProposed && reject.Nonce == 0 && !reject.Final {
+		v.Status = VirtualClosed
+		writeVirtual(s.db, v)
+
+		log.Warn("Virtual channel rejected", "id", v.ID, "reason", reject.Reason)
+		return true
+	}
+	if v.Pending == nil || v.Pending.Nonce != reject.Nonce || v.Pending.Final != reject.Final {
+		return false
+	}
+	v.Pending = nil
+	writeVirtual(s.db, v)
+
+	log.Warn("Virtual channel update rejected", "id", v.ID, "nonce", reject.Nonce, "reason", reject.Reason)
+	return true
+}
+
+// DisputeVirtual brings a virtual channel on-chain: it is closed on its final
+// balances if signed by both parties, or a dispute is raised on its latest
+// state otherwise. The allocations of the virtual channel are settled on the
+// outcome, off-chain with the hub or through disputes of the ledger channels.
+func (s *Service) DisputeVirtual(ctx context.Context, id common.Hash) (*State, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	v, err := s.partyOf(id)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status != VirtualOpen && v.Status != VirtualClosing {
+		return nil, errVirtualNotOpen
+	}
+	if _, err := s.disputeVirtual(ctx, v); err != nil {
+		return nil, err
+	}
+	s.disputing[v.ID] = true
+	if v.closable() {
+		return v.Final.copy(), nil
+	}
+	v.Status = VirtualDisputed
+	writeVirtual(s.db, v)
+
+	return v.Latest.copy(), nil
+}
+
+// disputeVirtual sends the transaction closing a virtual channel on its final
+// balances if signed by both parties, or raising a dispute on its latest state
+// otherwise.
+func (s *Service) disputeVirtual(ctx context.Context, v *Virtual) (*types.Transaction, error) {
+	var (
+		total     = v.total()
+		challenge = new(big.Int).SetUint64(v.Challenge)
+	)
+	if v.closable() {
+		final := v.Final
+		return s.contract.CloseVirtual(s.transactOpts(ctx, nil), v.PartyA, v.PartyB, total, challenge, v.Salt,
+			new(big.Int).SetUint64(final.Nonce), final.BalanceA, final.BalanceB, final.SigA, final.SigB)
+	}
+	state := v.Latest
+	return s.contract.DisputeVirtual(s.transactOpts(ctx, nil), v.PartyA, v.PartyB, total, challenge, v.Salt,
+		new(big.Int).SetUint64(state.Nonce), state.BalanceA, state.BalanceB, state.SigA, state.SigB)
+}
+
+// handleVirtualLog updates the local view of a virtual channel according to a
+// contract event, contesting disputes raised on outdated states.
+func (s *Service) handleVirtualLog(ctx context.Context, l types.Log, head uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	v := readVirtual(s.db, l.Topics[1])
+	if v == nil {
+		return
+	}
+	delete(s.disputing, v.ID)
+
+	switch l.Topics[0] {
+	case channelABI.Events["VirtualDisputed"].ID:
+		event, err := s.contract.ParseVirtualDisputed(l)
+		if err != nil {
+			log.Warn("Invalid channel event", "err", err)
+			return
+		}
+		v.Deadline = event.Deadline.Uint64()
+		if v.Status != VirtualClosed && !v.closable() {
+			v.Status = VirtualDisputed
+		}
+		// Contest the dispute with the final balances, or a more recent state
+		if head+1 < v.Deadline {
+			signed := len(v.Latest.SigA) > 0 && len(v.Latest.SigB) > 0
+			if v.closable() || (signed && v.Latest.Nonce > event.Nonce.Uint64()) {
+				log.Warn("Contesting virtual channel dispute", "id", v.ID, "nonce", event.Nonce, "latest", v.Latest.Nonce)
+				if _, err := s.disputeVirtual(ctx, v); err != nil {
+					log.Error("Failed to contest virtual channel dispute", "id", v.ID, "err", err)
+				} else {
+					s.disputing[v.ID] = true
+				}
+			}
+		}
+	case channelABI.Events["VirtualClosed"].ID:
+		event, err := s.contract.ParseVirtualClosed(l)
+		if err != nil {
+			log.Warn("Invalid channel event", "err", err)
+			return
+		}
+		if v.Final == nil || v.Final.BalanceA.Cmp(event.BalanceA) != 0 || v.Final.BalanceB.Cmp(event.BalanceB) != 0 {
+			v.Final = &State{Channel: v.ID, Nonce: v.Latest.Nonce, BalanceA: event.BalanceA, BalanceB: event.BalanceB}
+		}
+		if v.Status != VirtualClosed {
+			v.Status = VirtualClosing
+		}
+		log.Info("Virtual channel closed on-chain", "id", v.ID, "balanceA", event.BalanceA, "balanceB", event.BalanceB)
+	default:
+		return
+	}
+	writeVirtual(s.db, v)
+}
+
+// finaliseVirtuals takes the final balances of the disputed virtual channels
+// whose dispute window closed from the chain.
+func (s *Service) finaliseVirtuals(ctx context.Context, head uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, v := range readVirtuals(s.db) {
+		if v.Status != VirtualDisputed || head < v.Deadline {
+			continue
+		}
+		final, err := s.onchainFinal(ctx, v)
+		if err != nil {
+			log.Warn("Failed to retrieve disputed virtual channel", "id", v.ID, "err", err)
+			continue
+		}
+		v.Final, v.Status = final, VirtualClosing
+		writeVirtual(s.db, v)
+
+		log.Info("Virtual channel dispute ended", "id", v.ID, "balanceA", final.BalanceA, "balanceB", final.BalanceB)
+	}
+}
+
+// settleVirtuals proposes the hub to settle the allocations of the final
+// virtual channels of the local party in its ledger channel.
+func (s *Service) settleVirtuals() {
+	var msgs []message
+
+	s.lock.Lock()
+	for _, v := range readVirtuals(s.db) {
+		if v.Status != VirtualClosing || v.Hub == s.account {
+			continue
+		}
+		c := readChannel(s.db, v.ledger(s.account))
+		if c == nil || c.Status != StatusOpen || c.Pending != nil || !hasLock(c.latest().Locks, v.ID) {
+			continue
+		}
+		update, err := deallocated(c, v.ID, v.Final)
+		if err == nil {
+			err = s.proposeLedger(c, update)
+		}
+		if err != nil {
+			log.Warn("Failed to settle virtual channel", "id", v.ID, "err", err)
+			continue
+		}
+		msgs = append(msgs, message{v.Hub, FundMsg, &fundData{Terms: v.VirtualTerms, State: v.Final.copy(), Update: update.copy()}})
+	}
+	s.lock.Unlock()
+
+	for _, msg := range msgs {
+		s.deliver(msg.to, msg.code, msg.data)
+	}
+}
+
+// enforceVirtuals brings the virtual channels allocated funds by the pending
+// locks of a disputed ledger channel on-chain, for the allocations to become
+// resolvable. The caller must hold the service lock.
+func (s *Service) enforceVirtuals(ctx context.Context, locks []xchannel.Lock) {
+	for _, lock := range locks {
+		if !lock.Virtual() || s.disputing[lock.Hashlock] {
+			continue
+		}
+		onchain, err := s.contract.Virtuals(&bind.CallOpts{Context: ctx}, lock.Hashlock)
+		if err != nil || onchain.Status.Uint64() != xchannel.StatusUnknown {
+			continue
+		}
+		v := readVirtual(s.db, lock.Hashlock)
+		if v == nil {
+			log.Error("Unknown virtual channel in disputed channel", "id", lock.Hashlock)
+			continue
+		}
+		if _, err := s.disputeVirtual(ctx, v); err != nil {
+			log.Warn("Failed to dispute virtual channel", "id", v.ID, "err", err)
+			continue
+		}
+		s.disputing[v.ID] = true
+		log.Info("Disputing virtual channel of disputed ledger channel", "id", v.ID)
+	}
+}
+
+// Virtual returns the virtual channel with the given id.
+func (s *Service) Virtual(id common.Hash) (*Virtual, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	v := readVirtual(s.db, id)
+	if v == nil {
+		return nil, errUnknownVirtual
+	}
+	return v, nil
+}
+
+// Virtuals returns all the virtual channels the local account is party to or
+// the hub of.
+func (s *Service) Virtuals() []*Virtual {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return readVirtuals(s.db)
+}
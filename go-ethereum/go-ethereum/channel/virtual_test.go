@@ -0,0 +1,296 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package channel
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// newVirtualTester returns a route tester set up for virtual channels between A
+// and C with B as the hub: the hub and C deposit into the ledger channels opened
+// to them, and A and C are connected directly.
+//
+// The connections are torn down in the order A-B, B-C, A-C.
+func newVirtualTester(t *testing.T) *routeTester {
+	rt := newRouteTester(t)
+
+	if _, err := rt.b.Deposit(context.Background(), rt.ab, ether(10)); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if _, err := rt.c.Deposit(context.Background(), rt.bc, ether(10)); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	rt.commit()
+	rt.connect(rt.a, rt.c)
+	return rt
+}
+
+// mine mines the pending transactions and lets the given services, the ones
+// online, process the new block.
+func (rt *routeTester) mine(online ...*Service) {
+	rt.backend.Commit()
+	for _, s := range online {
+		s.sync()
+	}
+}
+
+// mineUntil mines blocks until the given block number.
+func (rt *routeTester) mineUntil(number uint64, online ...*Service) {
+	for rt.backend.Blockchain().CurrentBlock().NumberU64() < number {
+		rt.mine(online...)
+	}
+}
+
+// virtual returns the view a service has of a virtual channel.
+func (rt *routeTester) virtual(s *Service, id common.Hash) *Virtual {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return readVirtual(s.db, id)
+}
+
+// virtualIs reports whether the given services all see a virtual channel in the
+// given status, with no update pending.
+func (rt *routeTester) virtualIs(id common.Hash, status VirtualStatus, services ...*Service) bool {
+	for _, s := range services {
+		v := rt.virtual(s, id)
+		if v == nil || v.Status != status || v.Pending != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// openVirtual opens a virtual channel from A to C through the hub B, funded with
+// 3 ether by A and 2 ether by C.
+func (rt *routeTester) openVirtual() common.Hash {
+	rt.t.Helper()
+
+	v, err := rt.a.OpenVirtual(addrB, addrC, ether(3), ether(2), 5)
+	if err != nil {
+		rt.t.Fatalf("failed to open virtual channel: %v", err)
+	}
+	rt.waitFor("virtual channel opened", func() bool {
+		return rt.virtualIs(v.ID, VirtualOpen, rt.a, rt.b, rt.c)
+	})
+	rt.checkBalances(rt.a, rt.b, rt.ab, ether(7), ether(8))
+	rt.checkBalances(rt.b, rt.c, rt.bc, ether(7), ether(8))
+	return v.ID
+}
+
+// payVirtual transfers an amount over a virtual channel, waiting for both parties
+// to agree on the update.
+func (rt *routeTester) payVirtual(from *Service, id common.Hash, amount *big.Int) {
+	rt.t.Helper()
+
+	update, err := from.PayVirtual(id, amount)
+	if err != nil {
+		rt.t.Fatalf("failed to pay over virtual channel: %v", err)
+	}
+	rt.waitFor("virtual payment", func() bool {
+		for _, s := range []*Service{rt.a, rt.c} {
+			if v := rt.virtual(s, id); v.Pending != nil || v.Latest.Nonce != update.Nonce {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// closedBalances returns the balances a channel was paid out on.
+func (rt *routeTester) closedBalances(id common.Hash) (*big.Int, *big.Int) {
+	rt.t.Helper()
+
+	it, err := rt.a.contract.FilterClosed(&bind.FilterOpts{}, [][32]byte{id})
+	if err != nil {
+		rt.t.Fatalf("failed to filter close events: %v", err)
+	}
+	defer it.Close()
+
+	if !it.Next() {
+		rt.t.Fatalf("channel %x not closed", id)
+	}
+	return it.Event.BalanceA, it.Event.BalanceB
+}
+
+// checkClosed verifies the balances a channel was paid out on.
+func (rt *routeTester) checkClosed(id common.Hash, balanceA, balanceB *big.Int) {
+	rt.t.Helper()
+
+	if a, b := rt.closedBalances(id); a.Cmp(balanceA) != 0 || b.Cmp(balanceB) != 0 {
+		rt.t.Errorf("channel %x payout mismatch: have %v/%v, want %v/%v", id, a, b, balanceA, balanceB)
+	}
+}
+
+func TestVirtualChannel(t *testing.T) {
+	rt := newVirtualTester(t)
+	defer rt.close()
+
+	id := rt.openVirtual()
+
+	// The parties pay each other without involving the hub
+	rt.payVirtual(rt.a, id, ether(2))
+	rt.payVirtual(rt.c, id, ether(1))
+	if _, err := rt.c.PayVirtual(id, ether(4)); err != errInsufficientBalance {
+		t.Fatalf("overpayment error mismatch: have %v, want %v", err, errInsufficientBalance)
+	}
+	if _, err := rt.b.PayVirtual(id, ether(1)); err != errUnknownVirtual {
+		t.Fatalf("hub payment error mismatch: have %v, want %v", err, errUnknownVirtual)
+	}
+	if v := rt.virtual(rt.b, id); v.Latest.Nonce != 0 {
+		t.Fatalf("hub saw virtual channel updates: nonce %d", v.Latest.Nonce)
+	}
+	// Closing the virtual channel settles the allocations with the hub
+	if _, err := rt.a.CloseVirtual(id); err != nil {
+		t.Fatalf("failed to close virtual channel: %v", err)
+	}
+	rt.waitFor("virtual channel settled", func() bool {
+		return rt.virtualIs(id, VirtualClosed, rt.a, rt.b, rt.c)
+	})
+	rt.checkBalances(rt.a, rt.b, rt.ab, ether(9), ether(11))
+	rt.checkBalances(rt.b, rt.c, rt.bc, ether(9), ether(11))
+
+	if _, err := rt.a.PayVirtual(id, ether(1)); err != errVirtualNotOpen {
+		t.Fatalf("payment after close error mismatch: have %v, want %v", err, errVirtualNotOpen)
+	}
+}
+
+func TestVirtualCounterpartyDispute(t *testing.T) {
+	rt := newVirtualTester(t)
+	defer rt.close()
+
+	id := rt.openVirtual()
+	rt.payVirtual(rt.a, id, ether(1))
+
+	// C goes offline, A brings the virtual channel on-chain
+	rt.teardown[1]()
+	rt.teardown[2]()
+
+	if _, err := rt.a.DisputeVirtual(context.Background(), id); err != nil {
+		t.Fatalf("failed to dispute virtual channel: %v", err)
+	}
+	rt.mine(rt.a, rt.b)
+	if !rt.virtualIs(id, VirtualDisputed, rt.a, rt.b) {
+		t.Fatalf("virtual channel dispute not seen")
+	}
+	// Once the dispute window closed, A settles its allocation with the hub
+	rt.mineUntil(rt.virtual(rt.a, id).Deadline, rt.a, rt.b)
+	rt.waitFor("allocation of A settled", func() bool {
+		return rt.virtualIs(id, VirtualClosed, rt.a)
+	})
+	rt.checkBalances(rt.a, rt.b, rt.ab, ether(9), ether(11))
+
+	// The hub settles the allocation of C through a dispute of their ledger channel
+	if _, err := rt.b.Dispute(context.Background(), rt.bc); err != nil {
+		t.Fatalf("failed to dispute ledger channel: %v", err)
+	}
+	rt.mine(rt.a, rt.b)
+	rt.mineUntil(readChannel(rt.b.db, rt.bc).Deadline, rt.a, rt.b)
+	rt.mine(rt.a, rt.b)
+	rt.mine(rt.a, rt.b)
+
+	rt.checkClosed(rt.bc, ether(9), ether(11))
+	if !rt.virtualIs(id, VirtualClosed, rt.b) {
+		t.Fatalf("virtual channel not settled by the hub")
+	}
+}
+
+func TestVirtualHubDispute(t *testing.T) {
+	rt := newVirtualTester(t)
+	defer rt.close()
+
+	id := rt.openVirtual()
+	rt.payVirtual(rt.a, id, ether(1))
+
+	// The hub goes offline, the parties still close the virtual channel
+	rt.teardown[0]()
+	rt.teardown[1]()
+
+	if _, err := rt.a.CloseVirtual(id); err != nil {
+		t.Fatalf("failed to close virtual channel: %v", err)
+	}
+	rt.waitFor("virtual channel closed", func() bool {
+		return rt.virtualIs(id, VirtualClosing, rt.a, rt.c)
+	})
+	// Both dispute their ledger channels, closing the virtual channel on-chain
+	// for the allocations to be settled
+	if _, err := rt.a.Dispute(context.Background(), rt.ab); err != nil {
+		t.Fatalf("failed to dispute ledger channel: %v", err)
+	}
+	if _, err := rt.c.Dispute(context.Background(), rt.bc); err != nil {
+		t.Fatalf("failed to dispute ledger channel: %v", err)
+	}
+	rt.mine(rt.a, rt.c)
+	rt.mine(rt.a, rt.c)
+
+	onchain, err := rt.a.contract.Virtuals(nil, id)
+	if err != nil {
+		t.Fatalf("failed to retrieve virtual channel: %v", err)
+	}
+	if onchain.Status.Uint64() != 3 || onchain.BalanceA.Cmp(ether(2)) != 0 || onchain.BalanceB.Cmp(ether(3)) != 0 {
+		t.Fatalf("virtual channel not closed on-chain: %+v", onchain)
+	}
+	rt.mineUntil(readChannel(rt.a.db, rt.ab).Deadline, rt.a, rt.c)
+	rt.mine(rt.a, rt.c)
+	rt.mine(rt.a, rt.c)
+
+	rt.checkClosed(rt.ab, ether(9), ether(11))
+	rt.checkClosed(rt.bc, ether(9), ether(11))
+	if !rt.virtualIs(id, VirtualClosed, rt.a, rt.c) {
+		t.Fatalf("virtual channel not settled")
+	}
+}
+
+func TestVirtualStaleDispute(t *testing.T) {
+	rt := newVirtualTester(t)
+	defer rt.close()
+
+	id := rt.openVirtual()
+	stale := rt.virtual(rt.c, id).Latest
+	rt.payVirtual(rt.c, id, ether(1))
+
+	// C disputes on the opening state, paying it more than it's due
+	v := rt.virtual(rt.c, id)
+	if _, err := rt.c.contract.DisputeVirtual(rt.c.transactOpts(context.Background(), nil), addrA, addrC, v.total(),
+		big.NewInt(5), v.Salt, new(big.Int), stale.BalanceA, stale.BalanceB, stale.SigA, stale.SigB); err != nil {
+		t.Fatalf("failed to dispute virtual channel: %v", err)
+	}
+	// A notices and contests with the latest state
+	rt.mine(rt.a, rt.b)
+	rt.mine(rt.a, rt.b)
+
+	onchain, err := rt.a.contract.Virtuals(nil, id)
+	if err != nil {
+		t.Fatalf("failed to retrieve virtual channel: %v", err)
+	}
+	if onchain.Nonce.Uint64() != 1 {
+		t.Fatalf("dispute not contested: on-chain nonce %d", onchain.Nonce)
+	}
+	// Once the dispute window closed, both settle their allocations with the hub
+	// on the latest state
+	rt.mineUntil(onchain.Deadline.Uint64(), rt.a, rt.b, rt.c)
+	rt.waitFor("allocations settled", func() bool {
+		return rt.virtualIs(id, VirtualClosed, rt.a, rt.b, rt.c)
+	})
+	rt.checkBalances(rt.a, rt.b, rt.ab, ether(11), ether(9))
+	rt.checkBalances(rt.b, rt.c, rt.bc, ether(11), ether(9))
+}
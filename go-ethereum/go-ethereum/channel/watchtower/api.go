@@ -0,0 +1,96 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package watchtower
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var errInvalidHint = errors.New("invalid hint length")
+
+// PublicWatchtowerAPI provides access to the watchtower, for channel parties to
+// hand it their justice blobs.
+type PublicWatchtowerAPI struct {
+	t *Tower
+}
+
+// NewPublicWatchtowerAPI creates a new watchtower API.
+func NewPublicWatchtowerAPI(t *Tower) *PublicWatchtowerAPI {
+	return &PublicWatchtowerAPI{t: t}
+}
+
+// Add stores a justice blob tagged with a hint, uploaded by the client whose
+// signature of the upload data is given.
+func (api *PublicWatchtowerAPI) Add(hint hexutil.Bytes, blob hexutil.Bytes, sig hexutil.Bytes) (bool, error) {
+	if len(hint) != HintLength {
+		return false, errInvalidHint
+	}
+	var h Hint
+	copy(h[:], hint)
+
+	if err := api.t.Add(h, blob, sig); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Status is the status of the watchtower.
+type Status struct {
+	Account      common.Address `json:"account"`      // Account paying for the justice transactions
+	Synced       hexutil.Uint64 `json:"synced"`       // Last block the disputes were processed up to
+	Appointments int            `json:"appointments"` // Number of hints justice blobs are held for, per client
+	Responses    int            `json:"responses"`    // Number of justice transactions sent
+}
+
+// Status returns the status of the watchtower.
+func (api *PublicWatchtowerAPI) Status() *Status {
+	return &Status{
+		Account:      api.t.account,
+		Synced:       hexutil.Uint64(readSyncedBlock(api.t.db)),
+		Appointments: countAppointments(api.t.db),
+		Responses:    len(readResponses(api.t.db)),
+	}
+}
+
+// RPCResponse is a justice transaction sent by the watchtower.
+type RPCResponse struct {
+	Channel common.Hash    `json:"channel"`
+	Nonce   hexutil.Uint64 `json:"nonce"`
+	Tx      common.Hash    `json:"tx"`
+	Block   hexutil.Uint64 `json:"block"`
+	Done    bool           `json:"done"`
+}
+
+// Responses returns the justice transactions sent by the watchtower.
+func (api *PublicWatchtowerAPI) Responses() []*RPCResponse {
+	responses := readResponses(api.t.db)
+
+	list := make([]*RPCResponse, 0, len(responses))
+	for _, r := range responses {
+		list = append(list, &RPCResponse{
+			Channel: r.Channel,
+			Nonce:   hexutil.Uint64(r.Nonce),
+			Tx:      r.Tx.Hash(),
+			Block:   hexutil.Uint64(r.Block),
+			Done:    r.Done,
+		})
+	}
+	return list
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package watchtower
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Client is a connection to a remote watchtower.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	c, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given RPC client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// Close closes the underlying RPC connection.
+func (c *Client) Close() {
+	c.c.Close()
+}
+
+// Add hands the watchtower a justice blob tagged with a hint, along with the
+// signature of the client of the upload data.
+func (c *Client) Add(ctx context.Context, hint Hint, blob []byte, sig []byte) error {
+	var ok bool
+	return c.c.CallContext(ctx, &ok, "watchtower_add", hexutil.Bytes(hint[:]), hexutil.Bytes(blob), hexutil.Bytes(sig))
+}
+
+// Status retrieves the status of the watchtower.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	if err := c.c.CallContext(ctx, &status, "watchtower_status"); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
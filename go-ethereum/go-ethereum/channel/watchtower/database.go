@@ -0,0 +1,212 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package watchtower
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// syncedKey tracks the last block the dispute events were processed up to.
+	syncedKey = []byte("LastSyncedBlock")
+
+	appointmentPrefix = []byte("a") // appointmentPrefix + hint + client -> justice blobs
+	responsePrefix    = []byte("r") // responsePrefix + hint -> response
+	usagePrefix       = []byte("u") // usagePrefix + client -> number of justice blobs stored
+)
+
+// response is a justice transaction sent by the tower.
+type response struct {
+	Channel  common.Hash        // Channel the dispute was raised in
+	Nonce    uint64             // Nonce of the contested state
+	Tx       *types.Transaction // Justice transaction last sent
+	Block    uint64             // Number of the block the dispute was found in
+	Deadline uint64             // Block the dispute window closes in
+	Sent     uint64             // Head the justice transaction was last sent at
+	Priced   uint64             // Head the gas price of the justice transaction was last set at
+	Done     bool               // Whether the justice transaction got mined or the window closed
+}
+
+// appointmentKey = appointmentPrefix + hint + client
+func appointmentKey(hint Hint, client common.Address) []byte {
+	return append(append(append([]byte{}, appointmentPrefix...), hint[:]...), client.Bytes()...)
+}
+
+// usageKey = usagePrefix + client
+func usageKey(client common.Address) []byte {
+	return append(append([]byte{}, usagePrefix...), client.Bytes()...)
+}
+
+// responseKey = responsePrefix + hint
+func responseKey(hint Hint) []byte {
+	return append(append([]byte{}, responsePrefix...), hint[:]...)
+}
+
+// readAppointment retrieves the justice blobs tagged with a hint uploaded by a
+// client.
+func readAppointment(db ethdb.KeyValueReader, hint Hint, client common.Address) [][]byte {
+	data, _ := db.Get(appointmentKey(hint, client))
+	if len(data) == 0 {
+		return nil
+	}
+	var blobs [][]byte
+	if err := rlp.DecodeBytes(data, &blobs); err != nil {
+		log.Error("Invalid appointment RLP", "hint", common.Bytes2Hex(hint[:]), "client", client, "err", err)
+		return nil
+	}
+	return blobs
+}
+
+// readAppointments retrieves the justice blobs tagged with a hint uploaded by
+// any client.
+func readAppointments(db ethdb.Iteratee, hint Hint) [][]byte {
+	prefix := append(append([]byte{}, appointmentPrefix...), hint[:]...)
+
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var all [][]byte
+	for it.Next() {
+		if len(it.Key()) != len(prefix)+common.AddressLength {
+			continue
+		}
+		var blobs [][]byte
+		if err := rlp.DecodeBytes(it.Value(), &blobs); err != nil {
+			log.Error("Invalid appointment RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		all = append(all, blobs...)
+	}
+	return all
+}
+
+// writeAppointment stores the justice blobs tagged with a hint uploaded by a
+// client.
+func writeAppointment(db ethdb.KeyValueWriter, hint Hint, client common.Address, blobs [][]byte) {
+	data, err := rlp.EncodeToBytes(blobs)
+	if err != nil {
+		log.Crit("Failed to RLP encode appointment", "err", err)
+	}
+	if err := db.Put(appointmentKey(hint, client), data); err != nil {
+		log.Crit("Failed to store appointment", "err", err)
+	}
+}
+
+// countAppointments returns the number of hints justice blobs are stored for,
+// counted per client.
+func countAppointments(db ethdb.Iteratee) int {
+	it := db.NewIterator(appointmentPrefix, nil)
+	defer it.Release()
+
+	var count int
+	for it.Next() {
+		if len(it.Key()) == len(appointmentPrefix)+HintLength+common.AddressLength {
+			count++
+		}
+	}
+	return count
+}
+
+// readUsage retrieves the number of justice blobs stored for a client.
+func readUsage(db ethdb.KeyValueReader, client common.Address) uint64 {
+	data, _ := db.Get(usageKey(client))
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// writeUsage stores the number of justice blobs stored for a client.
+func writeUsage(db ethdb.KeyValueWriter, client common.Address, usage uint64) {
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], usage)
+	if err := db.Put(usageKey(client), enc[:]); err != nil {
+		log.Crit("Failed to store client usage", "err", err)
+	}
+}
+
+// readResponse retrieves the response sent for the dispute matching a hint,
+// nil if none.
+func readResponse(db ethdb.KeyValueReader, hint Hint) *response {
+	data, _ := db.Get(responseKey(hint))
+	if len(data) == 0 {
+		return nil
+	}
+	r := new(response)
+	if err := rlp.DecodeBytes(data, r); err != nil {
+		log.Error("Invalid response RLP", "hint", common.Bytes2Hex(hint[:]), "err", err)
+		return nil
+	}
+	return r
+}
+
+// writeResponse stores the response sent for the dispute matching a hint.
+func writeResponse(db ethdb.KeyValueWriter, hint Hint, r *response) {
+	data, err := rlp.EncodeToBytes(r)
+	if err != nil {
+		log.Crit("Failed to RLP encode response", "err", err)
+	}
+	if err := db.Put(responseKey(hint), data); err != nil {
+		log.Crit("Failed to store response", "err", err)
+	}
+}
+
+// readResponses retrieves all the responses sent.
+func readResponses(db ethdb.Iteratee) []*response {
+	it := db.NewIterator(responsePrefix, nil)
+	defer it.Release()
+
+	var responses []*response
+	for it.Next() {
+		if len(it.Key()) != len(responsePrefix)+HintLength {
+			continue
+		}
+		r := new(response)
+		if err := rlp.DecodeBytes(it.Value(), r); err != nil {
+			log.Error("Invalid response RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		responses = append(responses, r)
+	}
+	return responses
+}
+
+// readSyncedBlock retrieves the number of the last block the dispute events
+// were processed up to.
+func readSyncedBlock(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(syncedKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// writeSyncedBlock stores the number of the last block the dispute events were
+// processed up to.
+func writeSyncedBlock(db ethdb.KeyValueWriter, number uint64) {
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], number)
+	if err := db.Put(syncedKey, enc[:]); err != nil {
+		log.Crit("Failed to store last synced block", "err", err)
+	}
+}
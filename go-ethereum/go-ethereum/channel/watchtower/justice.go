@@ -0,0 +1,181 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package watchtower
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// HintLength is the length of the hints justice blobs are tagged with.
+const HintLength = 16
+
+var (
+	errInvalidBlob      = errors.New("invalid justice blob")
+	errInvalidJustice   = errors.New("invalid justice transaction")
+	errInvalidSignature = errors.New("invalid upload signature")
+)
+
+// channelABI is the parsed ABI of the channel contract, used to encode and
+// decode disputes.
+var channelABI, _ = abi.JSON(strings.NewReader(contract.XChannelABI))
+
+// Hint tags a justice blob with the state whose dispute it contests, without
+// revealing the state.
+type Hint [HintLength]byte
+
+// Key is the key a justice blob is encrypted with. It derives from the signatures
+// of the state whose dispute it contests, which the tower learns from the
+// dispute transaction.
+type Key common.Hash
+
+// StateKey returns the key of the justice blob contesting a dispute on the state
+// of a channel with the given signatures. The opening state of a channel isn't
+// signed, so its key derives from the channel id only: anyone can decrypt the
+// blob contesting it.
+func StateKey(id common.Hash, sigA, sigB []byte) Key {
+	return Key(crypto.Keccak256Hash(id[:], sigA, sigB))
+}
+
+// Hint returns the hint the justice blob encrypted with the key is tagged with.
+func (k Key) Hint() Hint {
+	var hint Hint
+	copy(hint[:], crypto.Keccak256([]byte("hint"), k[:]))
+	return hint
+}
+
+// UploadData returns the data a client signs to hand the tower a justice blob.
+// The tower only stores blobs uploaded by the clients it serves.
+func UploadData(hint Hint, blob []byte) []byte {
+	return append(append([]byte("watchtower upload"), hint[:]...), blob...)
+}
+
+// uploader recovers the client which signed the upload of a justice blob.
+func uploader(hint Hint, blob []byte, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, errInvalidSignature
+	}
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27 // Transform yellow paper V to 0/1
+	}
+	pub, err := crypto.SigToPub(crypto.Keccak256(UploadData(hint, blob)), sig)
+	if err != nil {
+		return common.Address{}, errInvalidSignature
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Justice is a transaction contesting a dispute. The tower sends it from its own
+// account, so it doesn't depend on the nonce of the account of the party.
+type Justice struct {
+	To   common.Address // Channel contract the dispute was raised in
+	Data []byte         // Call raising the dispute on a more recent state
+	Gas  uint64         // Gas limit of the transaction, estimated if zero
+}
+
+// DisputeJustice returns the justice transaction raising a dispute on a state
+// of a channel signed by both parties.
+func DisputeJustice(address common.Address, id common.Hash, nonce uint64, balanceA, balanceB *big.Int, locksRoot common.Hash, sigA, sigB []byte) (*Justice, error) {
+	data, err := channelABI.Pack("dispute", id, new(big.Int).SetUint64(nonce), balanceA, balanceB, locksRoot, sigA, sigB)
+	if err != nil {
+		return nil, err
+	}
+	return &Justice{To: address, Data: data}, nil
+}
+
+// Seal encrypts a justice transaction with the given key.
+func Seal(key Key, justice *Justice) ([]byte, error) {
+	plain, err := rlp.EncodeToBytes(justice)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+// open decrypts a justice blob with the given key.
+func open(key Key, blob []byte) (*Justice, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) < aead.NonceSize() {
+		return nil, errInvalidBlob
+	}
+	plain, err := aead.Open(nil, blob[:aead.NonceSize()], blob[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errInvalidBlob
+	}
+	justice := new(Justice)
+	if err := rlp.DecodeBytes(plain, justice); err != nil {
+		return nil, err
+	}
+	return justice, nil
+}
+
+func newAEAD(key Key) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// dispute is a dispute call to the channel contract.
+type dispute struct {
+	id         common.Hash
+	nonce      uint64
+	sigA, sigB []byte
+}
+
+// decodeDispute decodes the input of a transaction raising a dispute.
+func decodeDispute(input []byte) (*dispute, error) {
+	method := channelABI.Methods["dispute"]
+	if len(input) < 4 || !bytes.Equal(input[:4], method.ID) {
+		return nil, errors.New("not a dispute call")
+	}
+	args, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, err
+	}
+	return &dispute{
+		id:    args[0].([32]byte),
+		nonce: args[1].(*big.Int).Uint64(),
+		sigA:  args[5].([]byte),
+		sigB:  args[6].([]byte),
+	}, nil
+}
@@ -0,0 +1,565 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package watchtower implements a watchtower contesting the disputes raised on
+// outdated states of payment channels while their parties are offline.
+//
+// Parties hand the tower a justice blob for every state that got superseded: a
+// transaction contesting a dispute on the state, encrypted with a key deriving
+// from the signatures of the state and tagged with a hint deriving from the key.
+// The tower learns neither until the state is disputed on-chain, at which point
+// it finds the signatures in the dispute transaction, decrypts the matching
+// blobs and sends the justice transaction from its own account, until mined.
+//
+// Blobs are only accepted from the configured clients, which sign their uploads,
+// and are stored per client up to a quota: a counterparty knowing the hints of
+// the states can't crowd out the blobs of the party.
+package watchtower
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// syncTimeout is the maximum time a round of processing the dispute events
+	// may take.
+	syncTimeout = 30 * time.Second
+
+	maxBlobSize     = 2048    // Maximum size of a justice blob
+	maxBlobsPerHint = 4       // Maximum number of justice blobs a client tags with the same hint
+	maxJusticeGas   = 1000000 // Maximum gas a justice transaction may use
+
+	rebroadcastBlocks = 3  // Blocks a justice transaction may stay pending before being repriced
+	gasPriceBump      = 25 // Percentage the gas price of a pending justice transaction is raised by
+)
+
+var (
+	errNoAccount     = errors.New("no account to send justice transactions from")
+	errBlobTooLarge  = errors.New("justice blob too large")
+	errTooManyBlobs  = errors.New("too many justice blobs for hint")
+	errUnauthorized  = errors.New("unauthorized client")
+	errQuotaExceeded = errors.New("client quota exceeded")
+)
+
+// disputedTopic is the topic of the events of disputes being raised.
+var disputedTopic = channelABI.Events["Disputed"].ID
+
+// DefaultConfig contains the default settings of the watchtower.
+var DefaultConfig = Config{
+	Quota: 10000,
+}
+
+// Config contains the configuration options of the watchtower.
+type Config struct {
+	// Contracts are the channel contracts whose disputes are watched. The
+	// disputes of any contract are watched if unset.
+	Contracts []common.Address `toml:",omitempty"`
+
+	// Account is the account paying for the justice transactions. The first
+	// account available is used if unset.
+	Account common.Address `toml:",omitempty"`
+
+	// Clients are the accounts allowed to hand the tower justice blobs, signing
+	// their uploads. No blobs are accepted if unset.
+	Clients []common.Address `toml:",omitempty"`
+
+	// Quota is the maximum number of justice blobs stored for a client.
+	Quota uint64
+}
+
+// Backend is the chain access needed by the watchtower.
+type Backend interface {
+	bind.ContractBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// SignerFn signs a justice transaction for the given chain.
+type SignerFn func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+
+// Tower watches the chain for disputes on the states it holds justice blobs
+// for, and contests them.
+type Tower struct {
+	config  *Config
+	db      ethdb.Database // Database holding the justice blobs and responses
+	account common.Address // Account paying for the justice transactions
+	sign    SignerFn
+	dial    func() (Backend, error)
+
+	backend  Backend
+	chainID  *big.Int
+	filterer *contract.XChannelFilterer
+
+	lock sync.Mutex // Lock serialising the appointment updates
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a watchtower following the chain of the node, sending justice
+// transactions from an account of the node's account manager, and registers it
+// with the node.
+func New(stack *node.Node, config *Config) (*Tower, error) {
+	manager := stack.AccountManager()
+
+	account := config.Account
+	if account == (common.Address{}) {
+		for _, wallet := range manager.Wallets() {
+			if accounts := wallet.Accounts(); len(accounts) > 0 {
+				account = accounts[0].Address
+				break
+			}
+		}
+		if account == (common.Address{}) {
+			return nil, errNoAccount
+		}
+	}
+	wallet, err := manager.Find(accounts.Account{Address: account})
+	if err != nil {
+		return nil, err
+	}
+	sign := func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return wallet.SignTx(accounts.Account{Address: account}, tx, chainID)
+	}
+	dial := func() (Backend, error) {
+		client, err := stack.Attach()
+		if err != nil {
+			return nil, err
+		}
+		return ethclient.NewClient(client), nil
+	}
+	return register(stack, config, account, sign, dial)
+}
+
+// NewRemote creates a watchtower following the chain of the node at the given
+// RPC endpoint, sending justice transactions signed with the given key, and
+// registers it with the local node.
+func NewRemote(stack *node.Node, config *Config, endpoint string, key *ecdsa.PrivateKey) (*Tower, error) {
+	sign := func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+	}
+	dial := func() (Backend, error) {
+		return ethclient.Dial(endpoint)
+	}
+	return register(stack, config, crypto.PubkeyToAddress(key.PublicKey), sign, dial)
+}
+
+// register creates a watchtower and registers it with the node.
+func register(stack *node.Node, config *Config, account common.Address, sign SignerFn, dial func() (Backend, error)) (*Tower, error) {
+	db, err := stack.OpenDatabase("watchtower", 16, 16, "watchtower/db/")
+	if err != nil {
+		return nil, err
+	}
+	t := newTower(config, db, account, sign, dial)
+
+	stack.RegisterAPIs(t.APIs())
+	stack.RegisterLifecycle(t)
+	return t, nil
+}
+
+// newTower creates a watchtower sending justice transactions from the given
+// account.
+func newTower(config *Config, db ethdb.Database, account common.Address, sign SignerFn, dial func() (Backend, error)) *Tower {
+	if config.Quota == 0 {
+		log.Warn("Sanitizing invalid watchtower quota", "provided", config.Quota, "updated", DefaultConfig.Quota)
+		conf := *config
+		conf.Quota = DefaultConfig.Quota
+		config = &conf
+	}
+	filterer, _ := contract.NewXChannelFilterer(common.Address{}, nil)
+	return &Tower{
+		config:   config,
+		db:       db,
+		account:  account,
+		sign:     sign,
+		dial:     dial,
+		filterer: filterer,
+		quit:     make(chan struct{}),
+	}
+}
+
+// APIs returns the RPC APIs the watchtower offers.
+func (t *Tower) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "watchtower",
+			Version:   "1.0",
+			Service:   NewPublicWatchtowerAPI(t),
+			Public:    true,
+		},
+	}
+}
+
+// Start implements node.Lifecycle, connecting to the chain and starting to
+// watch for disputes.
+func (t *Tower) Start() error {
+	if err := t.setup(); err != nil {
+		return err
+	}
+	if len(t.config.Clients) == 0 {
+		log.Warn("No watchtower clients configured, justice blobs are refused")
+	}
+	t.wg.Add(1)
+	go t.loop()
+
+	log.Info("Started watchtower", "account", t.account, "contracts", len(t.config.Contracts))
+	return nil
+}
+
+// setup connects to the chain. Disputes are watched from the current head on
+// when the tower starts for the first time.
+func (t *Tower) setup() error {
+	backend, err := t.dial()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if readSyncedBlock(t.db) == 0 {
+		head, err := backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return err
+		}
+		writeSyncedBlock(t.db, head.Number.Uint64())
+	}
+	t.backend, t.chainID = backend, chainID
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the watchtower.
+func (t *Tower) Stop() error {
+	close(t.quit)
+	t.wg.Wait()
+
+	log.Info("Watchtower stopped")
+	return nil
+}
+
+// loop processes the dispute events of every new block.
+func (t *Tower) loop() {
+	defer t.wg.Done()
+
+	heads := make(chan *types.Header, 16)
+	sub, err := t.backend.SubscribeNewHead(context.Background(), heads)
+	if err != nil {
+		log.Error("Failed to subscribe to chain heads", "err", err)
+		return
+	}
+	defer sub.Unsubscribe()
+
+	t.sync()
+	for {
+		select {
+		case <-heads:
+			t.sync()
+		case err := <-sub.Err():
+			log.Error("Chain head subscription failed", "err", err)
+			return
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+// sync processes the dispute events up to the current head and makes sure the
+// justice transactions sent get mined.
+func (t *Tower) sync() {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	head, err := t.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Warn("Failed to retrieve chain head", "err", err)
+		return
+	}
+	number := head.Number.Uint64()
+
+	if from := readSyncedBlock(t.db) + 1; from <= number {
+		logs, err := t.backend.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(number),
+			Addresses: t.config.Contracts,
+			Topics:    [][]common.Hash{{disputedTopic}},
+		})
+		if err != nil {
+			log.Warn("Failed to retrieve dispute events", "from", from, "to", number, "err", err)
+			return
+		}
+		for _, l := range logs {
+			t.handleLog(ctx, l, number)
+		}
+		writeSyncedBlock(t.db, number)
+	}
+	t.rebroadcast(ctx, number)
+}
+
+// handleLog contests a dispute if justice blobs are held for the disputed state.
+func (t *Tower) handleLog(ctx context.Context, l types.Log, head uint64) {
+	if l.Removed {
+		return
+	}
+	event, err := t.filterer.ParseDisputed(l)
+	if err != nil {
+		log.Debug("Invalid dispute event", "err", err)
+		return
+	}
+	if head+1 >= event.Deadline.Uint64() {
+		log.Warn("Dispute window closed", "contract", l.Address, "id", common.Hash(event.Id), "deadline", event.Deadline)
+		return
+	}
+	// Recover the key of the justice blobs from the disputed state
+	tx, _, err := t.backend.TransactionByHash(ctx, l.TxHash)
+	if err != nil {
+		log.Warn("Failed to retrieve dispute transaction", "tx", l.TxHash, "err", err)
+		return
+	}
+	d, err := decodeDispute(tx.Data())
+	if err != nil || d.id != event.Id || d.nonce != event.Nonce.Uint64() {
+		log.Debug("Dispute not raised directly", "tx", l.TxHash)
+		return
+	}
+	key := StateKey(d.id, d.sigA, d.sigB)
+	hint := key.Hint()
+
+	if readResponse(t.db, hint) != nil {
+		return
+	}
+	for _, blob := range readAppointments(t.db, hint) {
+		justice, err := open(key, blob)
+		if err != nil {
+			continue
+		}
+		if err := validate(justice, l.Address, d); err != nil {
+			log.Debug("Invalid justice transaction", "id", d.id, "err", err)
+			continue
+		}
+		tx, err := t.respond(ctx, justice)
+		if err != nil {
+			log.Warn("Failed to send justice transaction", "id", d.id, "err", err)
+			continue
+		}
+		writeResponse(t.db, hint, &response{
+			Channel:  d.id,
+			Nonce:    d.nonce,
+			Tx:       tx,
+			Block:    l.BlockNumber,
+			Deadline: event.Deadline.Uint64(),
+			Sent:     head,
+			Priced:   head,
+		})
+		log.Info("Contested channel dispute", "contract", l.Address, "id", d.id, "nonce", d.nonce, "tx", tx.Hash())
+		return
+	}
+}
+
+// validate checks that a justice transaction contests the given dispute with a
+// more recent state of the channel, and nothing else.
+func validate(justice *Justice, address common.Address, disputed *dispute) error {
+	if justice.To != address || justice.Gas > maxJusticeGas {
+		return errInvalidJustice
+	}
+	d, err := decodeDispute(justice.Data)
+	if err != nil {
+		return err
+	}
+	if d.id != disputed.id || d.nonce <= disputed.nonce {
+		return errInvalidJustice
+	}
+	return nil
+}
+
+// respond sends a justice transaction from the account of the tower.
+func (t *Tower) respond(ctx context.Context, justice *Justice) (*types.Transaction, error) {
+	nonce, err := t.backend.PendingNonceAt(ctx, t.account)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := t.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gas := justice.Gas
+	if gas == 0 {
+		gas, err = t.backend.EstimateGas(ctx, ethereum.CallMsg{From: t.account, To: &justice.To, Data: justice.Data})
+		if err != nil {
+			return nil, err
+		}
+	}
+	tx, err := t.sign(types.NewTransaction(nonce, justice.To, new(big.Int), gas, gasPrice, justice.Data), t.chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.backend.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// rebroadcast sends the justice transactions not mined yet again, in case they
+// got dropped, raising their gas price if they stayed pending for too long.
+func (t *Tower) rebroadcast(ctx context.Context, head uint64) {
+	// Any transaction with the nonce of a justice transaction being mined means
+	// one of its versions was
+	nonce, err := t.backend.NonceAt(ctx, t.account, nil)
+	if err != nil {
+		log.Warn("Failed to retrieve tower nonce", "err", err)
+		return
+	}
+	it := t.db.NewIterator(responsePrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(responsePrefix)+HintLength {
+			continue
+		}
+		var hint Hint
+		copy(hint[:], it.Key()[len(responsePrefix):])
+
+		r := new(response)
+		if err := rlp.DecodeBytes(it.Value(), r); err != nil {
+			log.Error("Invalid response RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		if r.Done || r.Sent >= head {
+			continue
+		}
+		switch {
+		case nonce > r.Tx.Nonce():
+			log.Info("Justice transaction mined", "id", r.Channel, "nonce", r.Nonce)
+			r.Done = true
+
+		case head+1 >= r.Deadline:
+			log.Error("Justice transaction not mined within dispute window", "id", r.Channel, "nonce", r.Nonce, "tx", r.Tx.Hash())
+			r.Done = true
+
+		case head >= r.Priced+rebroadcastBlocks:
+			tx, err := t.reprice(ctx, r.Tx)
+			if err != nil {
+				log.Warn("Failed to reprice justice transaction", "id", r.Channel, "err", err)
+				continue
+			}
+			log.Info("Repriced pending justice transaction", "id", r.Channel, "nonce", r.Nonce, "tx", tx.Hash(), "gasprice", tx.GasPrice())
+			r.Tx, r.Sent, r.Priced = tx, head, head
+
+		default:
+			if err := t.backend.SendTransaction(ctx, r.Tx); err != nil {
+				log.Debug("Failed to resend justice transaction", "id", r.Channel, "err", err)
+			}
+			r.Sent = head
+		}
+		writeResponse(t.db, hint, r)
+	}
+}
+
+// reprice sends a pending justice transaction again with a raised gas price, at
+// least the currently suggested one.
+func (t *Tower) reprice(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	gasPrice, err := t.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bumped := new(big.Int).Mul(tx.GasPrice(), big.NewInt(100+gasPriceBump))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(tx.GasPrice()) <= 0 {
+		bumped.Add(tx.GasPrice(), common.Big1)
+	}
+	if bumped.Cmp(gasPrice) < 0 {
+		bumped = gasPrice
+	}
+	repriced, err := t.sign(types.NewTransaction(tx.Nonce(), *tx.To(), tx.Value(), tx.Gas(), bumped, tx.Data()), t.chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.backend.SendTransaction(ctx, repriced); err != nil {
+		return nil, err
+	}
+	return repriced, nil
+}
+
+// Add stores a justice blob tagged with a hint, uploaded by the client which
+// signed the upload.
+func (t *Tower) Add(hint Hint, blob []byte, sig []byte) error {
+	if len(blob) > maxBlobSize {
+		return errBlobTooLarge
+	}
+	client, err := uploader(hint, blob, sig)
+	if err != nil {
+		return err
+	}
+	if !t.authorized(client) {
+		return errUnauthorized
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	blobs := readAppointment(t.db, hint, client)
+	for _, b := range blobs {
+		if bytes.Equal(b, blob) {
+			return nil
+		}
+	}
+	// Blobs are never replaced, or a client could be made to overwrite its own
+	// ones. Clients having their own slots, the counterparty, which knows the
+	// hints too, can't exhaust the ones of the party.
+	if len(blobs) >= maxBlobsPerHint {
+		return errTooManyBlobs
+	}
+	usage := readUsage(t.db, client)
+	if usage >= t.config.Quota {
+		return errQuotaExceeded
+	}
+	writeAppointment(t.db, hint, client, append(blobs, common.CopyBytes(blob)))
+	writeUsage(t.db, client, usage+1)
+	return nil
+}
+
+// authorized reports whether the tower accepts justice blobs from a client.
+func (t *Tower) authorized(client common.Address) bool {
+	for _, c := range t.config.Clients {
+		if c == client {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,401 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package watchtower
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	keyA, _     = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	keyB, _     = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	keyTower, _ = crypto.HexToECDSA("49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee")
+	addrA       = crypto.PubkeyToAddress(keyA.PublicKey)
+	addrB       = crypto.PubkeyToAddress(keyB.PublicKey)
+	addrTower   = crypto.PubkeyToAddress(keyTower.PublicKey)
+	simChainID  = big.NewInt(1337)
+)
+
+// simBackend is a simulated chain with the chain id lookup the tower needs.
+type simBackend struct {
+	*backends.SimulatedBackend
+}
+
+func (b *simBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return simChainID, nil
+}
+
+// state is a channel state signed by both parties.
+type state struct {
+	nonce              uint64
+	balanceA, balanceB *big.Int
+	sigA, sigB         []byte
+}
+
+// tester is a watchtower guarding a channel opened on a simulated chain.
+type tester struct {
+	t        *testing.T
+	backend  *simBackend
+	contract *contract.XChannel
+	domain   xchannel.Domain
+	id       common.Hash
+	tower    *Tower
+}
+
+func newTester(t *testing.T) *tester {
+	funds := new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+	backend := &simBackend{backends.NewSimulatedBackend(core.GenesisAlloc{
+		addrA:     {Balance: funds},
+		addrB:     {Balance: funds},
+		addrTower: {Balance: funds},
+	}, 10000000)}
+
+	address, _, xchannel, err := contract.DeployXChannel(transactor(keyA), backend)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	backend.Commit()
+
+	opts := transactor(keyA)
+	opts.Value = ether(10)
+	tx, err := xchannel.Open(opts, addrB, big.NewInt(20))
+	if err != nil {
+		t.Fatalf("failed to open channel: %v", err)
+	}
+	backend.Commit()
+
+	receipt, _ := backend.TransactionReceipt(context.Background(), tx.Hash())
+	event, err := xchannel.ParseOpened(*receipt.Logs[0])
+	if err != nil {
+		t.Fatalf("failed to parse open event: %v", err)
+	}
+	tt := &tester{
+		t:        t,
+		backend:  backend,
+		contract: xchannel,
+		id:       event.Id,
+	}
+	tt.domain.ChainID, tt.domain.Contract = simChainID, address
+
+	sign := func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return types.SignTx(tx, types.LatestSignerForChainID(chainID), keyTower)
+	}
+	config := &Config{
+		Contracts: []common.Address{address},
+		Clients:   []common.Address{addrA, addrB},
+		Quota:     16,
+	}
+	tt.tower = newTower(config, rawdb.NewMemoryDatabase(), addrTower, sign, func() (Backend, error) {
+		return backend, nil
+	})
+	if err := tt.tower.setup(); err != nil {
+		t.Fatalf("failed to set up tower: %v", err)
+	}
+	return tt
+}
+
+func transactor(key *ecdsa.PrivateKey) *bind.TransactOpts {
+	opts, _ := bind.NewKeyedTransactorWithChainID(key, simChainID)
+	return opts
+}
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Ether))
+}
+
+// sign returns a state of the channel signed by both parties.
+func (tt *tester) sign(nonce uint64, balanceA, balanceB *big.Int) *state {
+	digest := crypto.Keccak256(tt.domain.StateData(tt.id, nonce, balanceA, balanceB, common.Hash{}))
+
+	s := &state{nonce: nonce, balanceA: balanceA, balanceB: balanceB}
+	for _, sig := range []struct {
+		key *ecdsa.PrivateKey
+		out *[]byte
+	}{{keyA, &s.sigA}, {keyB, &s.sigB}} {
+		var err error
+		if *sig.out, err = crypto.Sign(digest, sig.key); err != nil {
+			tt.t.Fatalf("failed to sign: %v", err)
+		}
+		(*sig.out)[crypto.RecoveryIDOffset] += 27
+	}
+	return s
+}
+
+// add hands the tower a justice blob, uploaded by the client with the given key.
+func (tt *tester) add(client *ecdsa.PrivateKey, hint Hint, blob []byte) error {
+	sig, err := crypto.Sign(crypto.Keccak256(UploadData(hint, blob)), client)
+	if err != nil {
+		tt.t.Fatalf("failed to sign upload: %v", err)
+	}
+	return tt.tower.Add(hint, blob, sig)
+}
+
+// backup hands the tower the justice blob contesting a dispute on the old
+// state with the new one, on behalf of partyB.
+func (tt *tester) backup(old, new *state) {
+	tt.t.Helper()
+
+	justice, err := DisputeJustice(tt.domain.Contract, tt.id, new.nonce, new.balanceA, new.balanceB, common.Hash{}, new.sigA, new.sigB)
+	if err != nil {
+		tt.t.Fatalf("failed to create justice: %v", err)
+	}
+	key := StateKey(tt.id, old.sigA, old.sigB)
+	blob, err := Seal(key, justice)
+	if err != nil {
+		tt.t.Fatalf("failed to seal justice: %v", err)
+	}
+	if err := tt.add(keyB, key.Hint(), blob); err != nil {
+		tt.t.Fatalf("failed to add justice: %v", err)
+	}
+}
+
+// dispute raises a dispute on a state on behalf of partyA.
+func (tt *tester) dispute(s *state) {
+	tt.t.Helper()
+
+	if _, err := tt.contract.Dispute(transactor(keyA), tt.id, new(big.Int).SetUint64(s.nonce), s.balanceA, s.balanceB, common.Hash{}, s.sigA, s.sigB); err != nil {
+		tt.t.Fatalf("failed to dispute: %v", err)
+	}
+	tt.backend.Commit()
+}
+
+// nonce returns the nonce of the state the channel is disputed on.
+func (tt *tester) nonce() uint64 {
+	channel, err := tt.contract.Channels(nil, tt.id)
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve channel: %v", err)
+	}
+	return channel.Nonce.Uint64()
+}
+
+func TestSealOpen(t *testing.T) {
+	justice := &Justice{To: common.HexToAddress("0x01"), Data: []byte{0x02, 0x03}, Gas: 4}
+	key := StateKey(common.HexToHash("0x05"), []byte{0x06}, []byte{0x07})
+
+	blob, err := Seal(key, justice)
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	opened, err := open(key, blob)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	if opened.To != justice.To || string(opened.Data) != string(justice.Data) || opened.Gas != justice.Gas {
+		t.Fatalf("justice mismatch: have %+v, want %+v", opened, justice)
+	}
+	if _, err := open(StateKey(common.HexToHash("0x05"), []byte{0x06}, nil), blob); err == nil {
+		t.Fatalf("opened with wrong key")
+	}
+}
+
+func TestContestStaleDispute(t *testing.T) {
+	tt := newTester(t)
+
+	first := tt.sign(1, ether(7), ether(3))
+	second := tt.sign(2, ether(4), ether(6))
+	tt.backup(first, second)
+
+	// PartyA disputes on the stale state, the tower contests
+	tt.dispute(first)
+	tt.tower.sync()
+	tt.backend.Commit()
+
+	if nonce := tt.nonce(); nonce != 2 {
+		t.Fatalf("dispute not contested: nonce %d", nonce)
+	}
+	responses := readResponses(tt.tower.db)
+	if len(responses) != 1 || responses[0].Channel != tt.id || responses[0].Nonce != 1 {
+		t.Fatalf("response mismatch: %+v", responses)
+	}
+	receipt, _ := tt.backend.TransactionReceipt(context.Background(), responses[0].Tx.Hash())
+	if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("justice transaction failed")
+	}
+	// Processing the same blocks again doesn't respond twice
+	writeSyncedBlock(tt.tower.db, 0)
+	tt.tower.sync()
+	if nonce, _ := tt.backend.PendingNonceAt(context.Background(), addrTower); nonce != 1 {
+		t.Fatalf("tower nonce mismatch: have %d, want 1", nonce)
+	}
+}
+
+func TestContestOpeningDispute(t *testing.T) {
+	tt := newTester(t)
+
+	opening := &state{nonce: 0, balanceA: ether(10), balanceB: new(big.Int)}
+	latest := tt.sign(1, ether(2), ether(8))
+	tt.backup(opening, latest)
+
+	if _, err := tt.contract.Dispute(transactor(keyA), tt.id, big.NewInt(0), ether(10), new(big.Int), common.Hash{}, nil, nil); err != nil {
+		t.Fatalf("failed to dispute: %v", err)
+	}
+	tt.backend.Commit()
+	tt.tower.sync()
+	tt.backend.Commit()
+
+	if nonce := tt.nonce(); nonce != 1 {
+		t.Fatalf("dispute not contested: nonce %d", nonce)
+	}
+}
+
+func TestInvalidJustice(t *testing.T) {
+	tt := newTester(t)
+
+	first := tt.sign(1, ether(7), ether(3))
+	second := tt.sign(2, ether(4), ether(6))
+	key := StateKey(tt.id, first.sigA, first.sigB)
+
+	// Garbage, a justice transaction calling another contract and one replaying
+	// the disputed state are all skipped
+	if err := tt.add(keyB, key.Hint(), []byte("garbage")); err != nil {
+		t.Fatalf("failed to add blob: %v", err)
+	}
+	other, _ := DisputeJustice(common.HexToAddress("0xdeadbeef"), tt.id, second.nonce, second.balanceA, second.balanceB, common.Hash{}, second.sigA, second.sigB)
+	replay, _ := DisputeJustice(tt.domain.Contract, tt.id, first.nonce, first.balanceA, first.balanceB, common.Hash{}, first.sigA, first.sigB)
+	for _, justice := range []*Justice{other, replay} {
+		blob, _ := Seal(key, justice)
+		if err := tt.add(keyB, key.Hint(), blob); err != nil {
+			t.Fatalf("failed to add blob: %v", err)
+		}
+	}
+	tt.backup(first, second)
+
+	// No more blobs are accepted for the hint
+	if err := tt.add(keyB, key.Hint(), []byte("more garbage")); err != errTooManyBlobs {
+		t.Fatalf("error mismatch: have %v, want %v", err, errTooManyBlobs)
+	}
+	if err := tt.add(keyB, Hint{}, make([]byte, maxBlobSize+1)); err != errBlobTooLarge {
+		t.Fatalf("error mismatch: have %v, want %v", err, errBlobTooLarge)
+	}
+	tt.dispute(first)
+	tt.tower.sync()
+	tt.backend.Commit()
+
+	if nonce := tt.nonce(); nonce != 2 {
+		t.Fatalf("dispute not contested: nonce %d", nonce)
+	}
+}
+
+func TestUploadAuthorization(t *testing.T) {
+	tt := newTester(t)
+
+	first := tt.sign(1, ether(7), ether(3))
+	second := tt.sign(2, ether(4), ether(6))
+	hint := StateKey(tt.id, first.sigA, first.sigB).Hint()
+
+	// Uploads must be signed by a client of the tower
+	if err := tt.tower.Add(hint, []byte("blob"), nil); err != errInvalidSignature {
+		t.Fatalf("error mismatch: have %v, want %v", err, errInvalidSignature)
+	}
+	if err := tt.add(keyTower, hint, []byte("blob")); err != errUnauthorized {
+		t.Fatalf("error mismatch: have %v, want %v", err, errUnauthorized)
+	}
+	// PartyA, knowing the hint too, fills its slots for it before cheating,
+	// which doesn't keep partyB from backing up its state
+	for i := 0; i < maxBlobsPerHint; i++ {
+		if err := tt.add(keyA, hint, []byte{byte(i)}); err != nil {
+			t.Fatalf("failed to add blob %d: %v", i, err)
+		}
+	}
+	if err := tt.add(keyA, hint, []byte("more")); err != errTooManyBlobs {
+		t.Fatalf("error mismatch: have %v, want %v", err, errTooManyBlobs)
+	}
+	tt.backup(first, second)
+
+	// Clients only store blobs up to their quota
+	for i := maxBlobsPerHint; i < int(tt.tower.config.Quota); i++ {
+		if err := tt.add(keyA, Hint{byte(i)}, []byte{byte(i)}); err != nil {
+			t.Fatalf("failed to add blob %d: %v", i, err)
+		}
+	}
+	if err := tt.add(keyA, Hint{0xff}, []byte("more")); err != errQuotaExceeded {
+		t.Fatalf("error mismatch: have %v, want %v", err, errQuotaExceeded)
+	}
+	if usage := readUsage(tt.tower.db, addrB); usage != 1 {
+		t.Fatalf("partyB usage mismatch: have %d, want 1", usage)
+	}
+	tt.dispute(first)
+	tt.tower.sync()
+	tt.backend.Commit()
+
+	if nonce := tt.nonce(); nonce != 2 {
+		t.Fatalf("dispute not contested: nonce %d", nonce)
+	}
+}
+
+func TestRebroadcastJustice(t *testing.T) {
+	tt := newTester(t)
+
+	first := tt.sign(1, ether(7), ether(3))
+	second := tt.sign(2, ether(4), ether(6))
+	tt.backup(first, second)
+
+	tt.dispute(first)
+	tt.tower.sync()
+
+	responses := readResponses(tt.tower.db)
+	if len(responses) != 1 {
+		t.Fatalf("response count mismatch: have %d, want 1", len(responses))
+	}
+	sent := responses[0].Tx
+
+	// The justice transaction gets dropped, it's sent again on every block and
+	// repriced once pending for too long
+	for i := 1; i <= rebroadcastBlocks; i++ {
+		tt.backend.Rollback()
+		tt.backend.Commit()
+		tt.tower.sync()
+
+		if nonce, _ := tt.backend.PendingNonceAt(context.Background(), addrTower); nonce != 1 {
+			t.Fatalf("block %d: justice transaction not resent", i)
+		}
+	}
+	responses = readResponses(tt.tower.db)
+	if price := responses[0].Tx.GasPrice(); price.Cmp(sent.GasPrice()) <= 0 {
+		t.Fatalf("gas price not raised: have %v, sent %v", price, sent.GasPrice())
+	}
+	if responses[0].Tx.Nonce() != sent.Nonce() || string(responses[0].Tx.Data()) != string(sent.Data()) {
+		t.Fatalf("repriced transaction mismatch")
+	}
+	if responses[0].Done {
+		t.Fatalf("pending justice transaction marked done")
+	}
+	// Once mined, the justice transaction isn't sent anymore
+	tt.backend.Commit()
+	tt.tower.sync()
+
+	if nonce := tt.nonce(); nonce != 2 {
+		t.Fatalf("dispute not contested: nonce %d", nonce)
+	}
+	if responses = readResponses(tt.tower.db); !responses[0].Done {
+		t.Fatalf("mined justice transaction not marked done")
+	}
+}
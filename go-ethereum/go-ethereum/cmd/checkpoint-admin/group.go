@@ -0,0 +1,342 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/checkpointoracle"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandGroupDeploy = cli.Command{
+	Name:  "group-deploy",
+	Usage: "Deploy a new checkpoint oracle approving checkpoints with a group signature",
+	Flags: []cli.Flag{
+		nodeURLFlag,
+		clefURLFlag,
+		signerFlag,
+		groupKeyFlag,
+	},
+	Action: utils.MigrateFlags(groupDeploy),
+}
+
+var commandGroupShare = cli.Command{
+	Name:  "group-share",
+	Usage: "Extract a group manager's share of the checkpoint signing key",
+	Flags: []cli.Flag{
+		managerKeyFlag,
+	},
+	Action: utils.MigrateFlags(groupShare),
+}
+
+var commandGroupSign = cli.Command{
+	Name:  "group-sign",
+	Usage: "Combine the shares of the group managers into a group signature of the checkpoint",
+	Flags: []cli.Flag{
+		nodeURLFlag,
+		groupKeyFlag,
+		sharesFlag,
+		verifyKeysFlag,
+		indexFlag,
+		hashFlag,
+		oracleFlag,
+	},
+	Action: utils.MigrateFlags(groupSign),
+}
+
+var commandGroupPublish = cli.Command{
+	Name:  "group-publish",
+	Usage: "Publish a group signed checkpoint into the oracle",
+	Flags: []cli.Flag{
+		nodeURLFlag,
+		clefURLFlag,
+		signerFlag,
+		indexFlag,
+		groupKeyFlag,
+		signatureFlag,
+	},
+	Action: utils.MigrateFlags(groupPublish),
+}
+
+// groupDeploy deploys a group checkpoint oracle, approving checkpoints with a
+// signature of the committee holding the given master public key.
+//
+// Note the network where the contract is deployed depends on
+// the network where the connected node is located.
+func groupDeploy(ctx *cli.Context) error {
+	key := getGroupKey(ctx)
+
+	// Print a summary to ensure the user understands what they're deploying
+	fmt.Printf("Deploying new group checkpoint oracle:\n\n")
+	fmt.Printf("Committee key => %s\n", crypto.Keccak256Hash(key).Hex())
+
+	// setup clef signer, create an abigen transactor and an RPC client
+	transactor, client := newClefSigner(ctx), newClient(ctx)
+
+	// Deploy and initialize the checkpoint oracle
+	fmt.Println("Sending deploy and initialization requests to Clef...")
+	oracle, txs, err := checkpointoracle.DeployGroupCheckpointOracle(transactor, client, params.CheckpointFrequency, params.CheckpointProcessConfirmations, key)
+	if err != nil {
+		utils.Fatalf("Failed to deploy group checkpoint oracle %v", err)
+	}
+	log.Info("Deployed group checkpoint oracle", "address", oracle.ContractAddr(), "tx", txs[0].Hash().Hex(), "init", txs[1].Hash().Hex())
+
+	return nil
+}
+
+// groupShare extracts the share a group manager contributes to the key signing
+// checkpoints from its group secret key.
+func groupShare(ctx *cli.Context) error {
+	if !ctx.IsSet(managerKeyFlag.Name) {
+		utils.Fatalf("Please specify the group manager key file (--managerkey)")
+	}
+	blob, err := ioutil.ReadFile(ctx.String(managerKeyFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to read group manager key: %v", err)
+	}
+	managerKey, err := hexutil.Decode(strings.TrimSpace(string(blob)))
+	if err != nil {
+		utils.Fatalf("Invalid group manager key: %v", err)
+	}
+	share, err := vm.ExtractGroupKeyShare(managerKey, checkpointoracle.GroupSignerID)
+	if err != nil {
+		utils.Fatalf("Failed to extract key share: %v", err)
+	}
+	fmt.Printf("Share => %s\n", hexutil.Encode(share))
+	return nil
+}
+
+// groupSign combines the shares of a threshold of group managers into a group
+// signature of the specified checkpoint. The shares must be given in the index
+// order of the managers who issued them, starting from the first.
+func groupSign(ctx *cli.Context) error {
+	var (
+		key    = getGroupKey(ctx)
+		shares = getHexList(ctx, sharesFlag)
+
+		chash   common.Hash
+		cindex  uint64
+		address common.Address
+	)
+	if len(shares) == 0 {
+		utils.Fatalf("Please specify the key shares (--shares) to sign with")
+	}
+	// Check the shares against the verify keys of their managers, if given
+	if ctx.IsSet(verifyKeysFlag.Name) {
+		verifyKeys := getHexList(ctx, verifyKeysFlag)
+		if len(verifyKeys) != len(shares) {
+			utils.Fatalf("Verify key count mismatch: have %d, want %d", len(verifyKeys), len(shares))
+		}
+		for i, share := range shares {
+			valid, err := vm.VerifyGroupKeyShare(key, verifyKeys[i], share, vm.PrecompiledGroupID, checkpointoracle.GroupSignerID)
+			if err != nil || !valid {
+				utils.Fatalf("Invalid key share %d: %v", i+1, err)
+			}
+		}
+	}
+	if !ctx.GlobalIsSet(nodeURLFlag.Name) {
+		// Offline mode signing
+		if !ctx.IsSet(hashFlag.Name) {
+			utils.Fatalf("Please specify the checkpoint hash (--hash) to sign in offline mode")
+		}
+		chash = common.HexToHash(ctx.String(hashFlag.Name))
+
+		if !ctx.IsSet(indexFlag.Name) {
+			utils.Fatalf("Please specify checkpoint index (--index) to sign in offline mode")
+		}
+		cindex = ctx.Uint64(indexFlag.Name)
+
+		if !ctx.IsSet(oracleFlag.Name) {
+			utils.Fatalf("Please specify oracle address (--oracle) to sign in offline mode")
+		}
+		address = common.HexToAddress(ctx.String(oracleFlag.Name))
+	} else {
+		// Interactive mode signing, retrieve the data from the remote node
+		node := newRPCClient(ctx.GlobalString(nodeURLFlag.Name))
+
+		checkpoint := getCheckpoint(ctx, node)
+		chash, cindex, address = checkpoint.Hash(), checkpoint.SectionIndex, getContractAddr(node)
+
+		// Check the validity of checkpoint
+		reqCtx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelFn()
+
+		head, err := ethclient.NewClient(node).HeaderByNumber(reqCtx, nil)
+		if err != nil {
+			return err
+		}
+		num := head.Number.Uint64()
+		if num < ((cindex+1)*params.CheckpointFrequency + params.CheckpointProcessConfirmations) {
+			utils.Fatalf("Invalid future checkpoint")
+		}
+		_, oracle := newGroupContract(node)
+		latest, _, h, err := oracle.Contract().GetLatestCheckpoint(nil)
+		if err != nil {
+			return err
+		}
+		if cindex < latest {
+			utils.Fatalf("Checkpoint is too old")
+		}
+		if cindex == latest && (latest != 0 || h.Uint64() != 0) {
+			utils.Fatalf("Stale checkpoint, latest registered %d, given %d", latest, cindex)
+		}
+	}
+	// Print to the user the data they are about to sign
+	fmt.Printf("Oracle     => %s\n", address.Hex())
+	fmt.Printf("Index %4d => %s\n", cindex, chash.Hex())
+	fmt.Printf("Shares     => %d\n", len(shares))
+
+	message := checkpointoracle.GroupMessage(address, cindex, chash)
+	signature, err := vm.SignGroupMessage(key, shares, len(shares), message, vm.PrecompiledGroupID, checkpointoracle.GroupSignerID)
+	if err != nil {
+		utils.Fatalf("Failed to sign checkpoint, err %v", err)
+	}
+	fmt.Printf("Signature  => %s\n", hexutil.Encode(signature))
+	return nil
+}
+
+// groupPublish registers the specified checkpoint which generated by connected
+// node with the group signature of the committee.
+func groupPublish(ctx *cli.Context) error {
+	// Print the checkpoint oracle's current status to make sure we're interacting
+	// with the correct network and contract.
+	groupStatus(ctx)
+
+	key := getGroupKey(ctx)
+	if !ctx.IsSet(signatureFlag.Name) {
+		utils.Fatalf("Please specify the group signature (--signature) to submit")
+	}
+	sig, err := hexutil.Decode(ctx.String(signatureFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid group signature: %v", err)
+	}
+	var (
+		client     = newRPCClient(ctx.GlobalString(nodeURLFlag.Name))
+		_, oracle  = newGroupContract(client)
+		checkpoint = getCheckpoint(ctx, client)
+	)
+	// Retrieve recent header info to protect replay attack
+	reqCtx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	head, err := ethclient.NewClient(client).HeaderByNumber(reqCtx, nil)
+	if err != nil {
+		return err
+	}
+	num := head.Number.Uint64()
+	recent, err := ethclient.NewClient(client).HeaderByNumber(reqCtx, big.NewInt(int64(num-128)))
+	if err != nil {
+		return err
+	}
+	// Print a summary of the operation that's going to be performed
+	fmt.Printf("Publishing %d => %s:\n\n", checkpoint.SectionIndex, checkpoint.Hash().Hex())
+	fmt.Printf("Sentry number => %d\nSentry hash   => %s\n", recent.Number, recent.Hash().Hex())
+
+	// Publish the checkpoint into the oracle
+	fmt.Println("Sending publish request to Clef...")
+	tx, err := oracle.RegisterCheckpoint(newClefSigner(ctx), checkpoint.SectionIndex, checkpoint.Hash().Bytes(), recent.Number, recent.Hash(), key, sig)
+	if err != nil {
+		utils.Fatalf("Register contract failed %v", err)
+	}
+	log.Info("Successfully registered checkpoint", "tx", tx.Hash().Hex())
+	return nil
+}
+
+// groupStatus fetches the configuration and the latest checkpoint of the group
+// checkpoint oracle.
+func groupStatus(ctx *cli.Context) error {
+	addr, oracle := newGroupContract(newRPCClient(ctx.GlobalString(nodeURLFlag.Name)))
+	fmt.Printf("Oracle => %s\n", addr.Hex())
+	fmt.Println()
+
+	size, confirms, keyHash, err := oracle.Contract().GetConfig(nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Section size  => %d\nConfirmations => %d\nCommittee key => %s\n", size, confirms, common.Hash(keyHash).Hex())
+	fmt.Println()
+
+	index, checkpoint, height, err := oracle.Contract().GetLatestCheckpoint(nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Checkpoint (published at #%d) %d => %s\n", height, index, common.Hash(checkpoint).Hex())
+
+	return nil
+}
+
+// getGroupKey retrieves the encoded master public key of the committee from the
+// command line.
+func getGroupKey(ctx *cli.Context) []byte {
+	if !ctx.IsSet(groupKeyFlag.Name) {
+		utils.Fatalf("Please specify the committee master public key (--groupkey)")
+	}
+	key, err := hexutil.Decode(ctx.String(groupKeyFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid committee master public key: %v", err)
+	}
+	if _, err := vm.PackGroupKey(key); err != nil {
+		utils.Fatalf("Invalid committee master public key: %v", err)
+	}
+	return key
+}
+
+// getHexList parses a comma separated list of hex blobs from the command line.
+func getHexList(ctx *cli.Context, flag cli.StringFlag) [][]byte {
+	var list [][]byte
+	for _, item := range strings.Split(ctx.String(flag.Name), ",") {
+		trimmed := strings.TrimSpace(item)
+		if trimmed == "" {
+			continue
+		}
+		blob, err := hexutil.Decode(trimmed)
+		if err != nil {
+			utils.Fatalf("Invalid entry in --%s: '%s'", flag.Name, trimmed)
+		}
+		list = append(list, blob)
+	}
+	return list
+}
+
+// newGroupContract creates a group registrar contract instance with the contract
+// address configured in the remote node.
+func newGroupContract(client *rpc.Client) (common.Address, *checkpointoracle.GroupCheckpointOracle) {
+	addr := getContractAddr(client)
+	if addr == (common.Address{}) {
+		utils.Fatalf("No specified registrar contract address")
+	}
+	contract, err := checkpointoracle.NewGroupCheckpointOracle(addr, ethclient.NewClient(client))
+	if err != nil {
+		utils.Fatalf("Failed to setup registrar contract %s: %v", addr, err)
+	}
+	return addr, contract
+}
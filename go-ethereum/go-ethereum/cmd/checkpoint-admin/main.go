@@ -43,6 +43,10 @@ func init() {
 		commandDeploy,
 		commandSign,
 		commandPublish,
+		commandGroupDeploy,
+		commandGroupShare,
+		commandGroupSign,
+		commandGroupPublish,
 	}
 	app.Flags = []cli.Flag{
 		oracleFlag,
@@ -91,6 +95,26 @@ var (
 		Name:  "signatures",
 		Usage: "Comma separated checkpoint signatures to submit",
 	}
+	groupKeyFlag = cli.StringFlag{
+		Name:  "groupkey",
+		Usage: "Hex encoded master public key of the checkpoint committee",
+	}
+	managerKeyFlag = cli.StringFlag{
+		Name:  "managerkey",
+		Usage: "File containing the hex encoded group secret key of a committee manager",
+	}
+	sharesFlag = cli.StringFlag{
+		Name:  "shares",
+		Usage: "Comma separated key shares of the committee managers, in manager order",
+	}
+	verifyKeysFlag = cli.StringFlag{
+		Name:  "verifykeys",
+		Usage: "Comma separated verify keys of the managers issuing the shares, to check them",
+	}
+	signatureFlag = cli.StringFlag{
+		Name:  "signature",
+		Usage: "Group signature of the checkpoint to submit",
+	}
 )
 
 func main() {
@@ -154,6 +154,12 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 			for _, addr := range evm.ActivePrecompiles() {
 				statedb.AddAddressToAccessList(addr)
 			}
+			for _, el := range msg.AccessList() {
+				statedb.AddAddressToAccessList(el.Address)
+				for _, key := range el.StorageKeys {
+					statedb.AddSlotToAccessList(el.Address, key)
+				}
+			}
 		}
 		snapshot := statedb.Snapshot()
 		// (ret []byte, usedGas uint64, failed bool, err error)
@@ -26,7 +26,10 @@ import (
 
 	"gopkg.in/urfave/cli.v1"
 
+	"github.com/ethereum/go-ethereum/channel"
+	"github.com/ethereum/go-ethereum/channel/watchtower"
 	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
@@ -84,10 +87,12 @@ type whisperDeprecatedConfig struct {
 }
 
 type gethConfig struct {
-	Eth      eth.Config
-	Shh      whisperDeprecatedConfig
-	Node     node.Config
-	Ethstats ethstatsConfig
+	Eth        eth.Config
+	Shh        whisperDeprecatedConfig
+	Node       node.Config
+	Ethstats   ethstatsConfig
+	Channel    channel.Config
+	Watchtower watchtower.Config
 }
 
 func loadConfig(file string, cfg *gethConfig) error {
@@ -119,8 +124,10 @@ func defaultNodeConfig() node.Config {
 func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	// Load defaults.
 	cfg := gethConfig{
-		Eth:  eth.DefaultConfig,
-		Node: defaultNodeConfig(),
+		Eth:        eth.DefaultConfig,
+		Node:       defaultNodeConfig(),
+		Channel:    channel.DefaultConfig,
+		Watchtower: watchtower.DefaultConfig,
 	}
 
 	// Load config file.
@@ -145,6 +152,8 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 		cfg.Ethstats.URL = ctx.GlobalString(utils.EthStatsURLFlag.Name)
 	}
 	utils.SetShhConfig(ctx, stack)
+	utils.SetChannelConfig(ctx, &cfg.Channel)
+	utils.SetWatchtowerConfig(ctx, &cfg.Watchtower)
 
 	return stack, cfg
 }
@@ -173,6 +182,14 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
 	}
+	// Operate payment channels if a channel contract is configured
+	if cfg.Channel.Contract != (common.Address{}) {
+		utils.RegisterChannelService(stack, &cfg.Channel)
+	}
+	// Contest stale channel disputes on behalf of others if requested
+	if ctx.GlobalBool(utils.WatchtowerFlag.Name) {
+		utils.RegisterWatchtowerService(stack, &cfg.Watchtower)
+	}
 	return stack, backend
 }
 
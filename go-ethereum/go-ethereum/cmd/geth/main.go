@@ -89,11 +89,16 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolDeadlineSlotsFlag,
+		utils.TxPoolTimelockJournalFlag,
+		utils.TxPoolTimelockSlotsFlag,
+		utils.TxPoolRevealWindowFlag,
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.ExtendedReceiptsFlag,
 		utils.LightServeFlag,
 		utils.LegacyLightServFlag,
 		utils.LightIngressFlag,
@@ -133,6 +138,8 @@ var (
 		utils.LegacyMinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerfiyFlag,
+		utils.MinerParallelFlag,
+		utils.MinerOrderingFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -148,8 +155,21 @@ var (
 		utils.GoerliFlag,
 		utils.YoloV2Flag,
 		utils.VMEnableDebugFlag,
+		utils.VMParallelImportFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
+		utils.ChannelContractFlag,
+		utils.ChannelAccountFlag,
+		utils.ChannelWatchtowersFlag,
+		utils.ChannelFeeBaseFlag,
+		utils.ChannelFeeRateFlag,
+		utils.ChannelTimelockDeltaFlag,
+		utils.ChannelConfirmationsFlag,
+		utils.WatchtowerFlag,
+		utils.WatchtowerAccountFlag,
+		utils.WatchtowerContractsFlag,
+		utils.WatchtowerClientsFlag,
+		utils.WatchtowerQuotaFlag,
 		utils.FakePoWFlag,
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
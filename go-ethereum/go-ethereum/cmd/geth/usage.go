@@ -48,6 +48,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.ExitWhenSyncedFlag,
 			utils.GCModeFlag,
 			utils.TxLookupLimitFlag,
+			utils.ExtendedReceiptsFlag,
 			utils.EthStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
@@ -101,6 +102,10 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolDeadlineSlotsFlag,
+			utils.TxPoolTimelockJournalFlag,
+			utils.TxPoolTimelockSlotsFlag,
+			utils.TxPoolRevealWindowFlag,
 		},
 	},
 	{
@@ -183,6 +188,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerExtraDataFlag,
 			utils.MinerRecommitIntervalFlag,
 			utils.MinerNoVerfiyFlag,
+			utils.MinerParallelFlag,
+			utils.MinerOrderingFlag,
 		},
 	},
 	{
@@ -193,10 +200,33 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.GpoMaxGasPriceFlag,
 		},
 	},
+	{
+		Name: "PAYMENT CHANNELS",
+		Flags: []cli.Flag{
+			utils.ChannelContractFlag,
+			utils.ChannelAccountFlag,
+			utils.ChannelWatchtowersFlag,
+			utils.ChannelFeeBaseFlag,
+			utils.ChannelFeeRateFlag,
+			utils.ChannelTimelockDeltaFlag,
+			utils.ChannelConfirmationsFlag,
+		},
+	},
+	{
+		Name: "WATCHTOWER",
+		Flags: []cli.Flag{
+			utils.WatchtowerFlag,
+			utils.WatchtowerAccountFlag,
+			utils.WatchtowerContractsFlag,
+			utils.WatchtowerClientsFlag,
+			utils.WatchtowerQuotaFlag,
+		},
+	},
 	{
 		Name: "VIRTUAL MACHINE",
 		Flags: []cli.Flag{
 			utils.VMEnableDebugFlag,
+			utils.VMParallelImportFlag,
 			utils.EVMInterpreterFlag,
 			utils.EWASMInterpreterFlag,
 		},
@@ -0,0 +1,116 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// swap is a standalone daemon running HTLC atomic swaps across two chains,
+// driven through the swap RPC API (e.g. from a geth attach console).
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/swap"
+)
+
+var (
+	datadirFlag = flag.String("datadir", "", "Data directory for the swaps and their secrets")
+	chainsFlag  = flag.String("chains", "", "Comma separated RPC endpoints of the chains to swap across (endpoint[@contract])")
+
+	confirmationsFlag = flag.Uint64("confirmations", swap.DefaultConfig.Confirmations, "Blocks counterparty funds must be buried under before acting")
+	minDeltaFlag      = flag.Duration("mindelta", swap.DefaultConfig.MinDelta, "Minimum time between the timelocks of the two legs of a swap")
+
+	accJSONFlag = flag.String("account.json", "", "Key json file to make the swaps with")
+	accPassFlag = flag.String("account.pass", "", "Decryption password to access the account key")
+
+	httpAddrFlag = flag.String("http.addr", "localhost", "Listener interface for the HTTP API")
+	httpPortFlag = flag.Int("http.port", 8545, "Listener port for the HTTP API")
+
+	logFlag = flag.Int("loglevel", 3, "Log level to use for the swap daemon")
+)
+
+func main() {
+	// Parse the flags and set up the logger to print everything requested
+	flag.Parse()
+	log.Root().SetHandler(log.LvlFilterHandler(log.Lvl(*logFlag), log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	config := swap.DefaultConfig
+	config.Confirmations = *confirmationsFlag
+	config.MinDelta = *minDeltaFlag
+
+	for _, chain := range strings.Split(*chainsFlag, ",") {
+		if chain = strings.TrimSpace(chain); chain == "" {
+			continue
+		}
+		var cfg swap.ChainConfig
+		if idx := strings.LastIndex(chain, "@"); idx >= 0 {
+			if !common.IsHexAddress(chain[idx+1:]) {
+				log.Crit("Invalid HTLC contract address", "chain", chain)
+			}
+			cfg.Contract = common.HexToAddress(chain[idx+1:])
+			chain = chain[:idx]
+		}
+		cfg.Endpoint = chain
+		config.Chains = append(config.Chains, cfg)
+	}
+	if len(config.Chains) < 2 {
+		log.Crit("At least two chains needed to swap across")
+	}
+	// Load up the account key and decrypt it
+	blob, err := ioutil.ReadFile(*accPassFlag)
+	if err != nil {
+		log.Crit("Failed to read account password contents", "file", *accPassFlag, "err", err)
+	}
+	pass := strings.TrimSuffix(string(blob), "\n")
+
+	if blob, err = ioutil.ReadFile(*accJSONFlag); err != nil {
+		log.Crit("Failed to read account key contents", "file", *accJSONFlag, "err", err)
+	}
+	key, err := keystore.DecryptKey(blob, pass)
+	if err != nil {
+		log.Crit("Failed to decrypt account key", "err", err)
+	}
+	// Assemble a node without networking serving the swap API
+	stack, err := node.New(&node.Config{
+		Name:        "swap",
+		DataDir:     *datadirFlag,
+		HTTPHost:    *httpAddrFlag,
+		HTTPPort:    *httpPortFlag,
+		HTTPModules: []string{"swap"},
+		P2P: p2p.Config{
+			NoDiscovery: true,
+			MaxPeers:    0,
+			ListenAddr:  "",
+		},
+	})
+	if err != nil {
+		log.Crit("Failed to create node", "err", err)
+	}
+	if _, err := swap.New(stack, &config, key.PrivateKey); err != nil {
+		log.Crit("Failed to create swap engine", "err", err)
+	}
+	if err := stack.Start(); err != nil {
+		log.Crit("Failed to start swap daemon", "err", err)
+	}
+	log.Info("Swap daemon started", "account", key.Address, "chains", len(config.Chains))
+	stack.Wait()
+}
@@ -33,6 +33,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/channel"
+	"github.com/ethereum/go-ethereum/channel/watchtower"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ethereum/go-ethereum/consensus"
@@ -204,6 +206,10 @@ var (
 		Usage: "Number of recent blocks to maintain transactions index by-hash for (default = index all blocks)",
 		Value: 0,
 	}
+	ExtendedReceiptsFlag = cli.BoolFlag{
+		Name:  "receipts.extended",
+		Usage: "Store the revert reason, return data and call gas of processed transactions (extra disk usage)",
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -343,6 +349,26 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: eth.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolDeadlineSlotsFlag = cli.Uint64Flag{
+		Name:  "txpool.deadlineslots",
+		Usage: "Number of slots reserved for local transactions with an inclusion deadline",
+		Value: eth.DefaultConfig.TxPool.DeadlineSlots,
+	}
+	TxPoolTimelockJournalFlag = cli.StringFlag{
+		Name:  "txpool.timelockjournal",
+		Usage: "Disk journal for timelocked transactions to survive node restarts (disabled if empty)",
+		Value: eth.DefaultConfig.TxPool.TimelockJournal,
+	}
+	TxPoolTimelockSlotsFlag = cli.Uint64Flag{
+		Name:  "txpool.timelockslots",
+		Usage: "Maximum number of timelocked transactions held back",
+		Value: eth.DefaultConfig.TxPool.TimelockSlots,
+	}
+	TxPoolRevealWindowFlag = cli.Uint64Flag{
+		Name:  "txpool.revealwindow",
+		Usage: "Blocks a committed transaction must be revealed and included within (0 = commit-reveal disabled)",
+		Value: eth.DefaultConfig.TxPool.RevealWindow,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -434,6 +460,15 @@ var (
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
 	}
+	MinerParallelFlag = cli.BoolFlag{
+		Name:  "miner.parallel",
+		Usage: "Execute the transactions of mined blocks speculatively in parallel",
+	}
+	MinerOrderingFlag = cli.StringFlag{
+		Name:  "miner.ordering",
+		Usage: `Transaction ordering policy of the miner ("price", "fifo" or "hash")`,
+		Value: miner.OrderingPrice,
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -454,6 +489,10 @@ var (
 		Name:  "vmdebug",
 		Usage: "Record information useful for VM and contract debugging",
 	}
+	VMParallelImportFlag = cli.BoolFlag{
+		Name:  "parallel.import",
+		Usage: "Execute the transactions of imported blocks speculatively in parallel",
+	}
 	InsecureUnlockAllowedFlag = cli.BoolFlag{
 		Name:  "allow-insecure-unlock",
 		Usage: "Allow insecure account unlocking when account-related RPCs are exposed by http",
@@ -468,6 +507,61 @@ var (
 		Usage: "Sets a cap on transaction fee (in ether) that can be sent via the RPC APIs (0 = no cap)",
 		Value: eth.DefaultConfig.RPCTxFeeCap,
 	}
+	// Payment channel settings
+	ChannelContractFlag = cli.StringFlag{
+		Name:  "channel.contract",
+		Usage: "Address of the XChannel contract to operate payment channels on (enables the channel service)",
+	}
+	ChannelAccountFlag = cli.StringFlag{
+		Name:  "channel.account",
+		Usage: "Account to operate payment channels with (default = first account)",
+	}
+	ChannelWatchtowersFlag = cli.StringFlag{
+		Name:  "channel.watchtowers",
+		Usage: "Comma separated watchtower RPC endpoints to back channel states up to",
+	}
+	ChannelFeeBaseFlag = BigFlag{
+		Name:  "channel.fee.base",
+		Usage: "Flat fee charged for forwarding transfers over payment channels",
+		Value: channel.DefaultConfig.FeeBase,
+	}
+	ChannelFeeRateFlag = cli.Uint64Flag{
+		Name:  "channel.fee.rate",
+		Usage: "Fee charged for forwarding transfers over payment channels, in millionths of the amount",
+		Value: channel.DefaultConfig.FeeRate,
+	}
+	ChannelTimelockDeltaFlag = cli.Uint64Flag{
+		Name:  "channel.timelockdelta",
+		Usage: "Minimum number of blocks between the expiration of incoming and forwarded transfers",
+		Value: channel.DefaultConfig.TimelockDelta,
+	}
+	ChannelConfirmationsFlag = cli.Uint64Flag{
+		Name:  "channel.confirmations",
+		Usage: "Number of blocks payment channel contract events must be buried under before they are processed",
+		Value: channel.DefaultConfig.Confirmations,
+	}
+	// Watchtower settings
+	WatchtowerFlag = cli.BoolFlag{
+		Name:  "watchtower",
+		Usage: "Enable the watchtower contesting stale payment channel disputes",
+	}
+	WatchtowerAccountFlag = cli.StringFlag{
+		Name:  "watchtower.account",
+		Usage: "Account to send justice transactions from (default = first account)",
+	}
+	WatchtowerContractsFlag = cli.StringFlag{
+		Name:  "watchtower.contracts",
+		Usage: "Comma separated XChannel contract addresses to watch (default = any)",
+	}
+	WatchtowerClientsFlag = cli.StringFlag{
+		Name:  "watchtower.clients",
+		Usage: "Comma separated accounts allowed to upload justice blobs",
+	}
+	WatchtowerQuotaFlag = cli.Uint64Flag{
+		Name:  "watchtower.quota",
+		Usage: "Maximum number of justice blobs stored per client",
+		Value: watchtower.DefaultConfig.Quota,
+	}
 	// Logging and debug settings
 	EthStatsURLFlag = cli.StringFlag{
 		Name:  "ethstats",
@@ -1353,6 +1447,18 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolDeadlineSlotsFlag.Name) {
+		cfg.DeadlineSlots = ctx.GlobalUint64(TxPoolDeadlineSlotsFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolTimelockJournalFlag.Name) {
+		cfg.TimelockJournal = ctx.GlobalString(TxPoolTimelockJournalFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolTimelockSlotsFlag.Name) {
+		cfg.TimelockSlots = ctx.GlobalUint64(TxPoolTimelockSlotsFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolRevealWindowFlag.Name) {
+		cfg.RevealWindow = ctx.GlobalUint64(TxPoolRevealWindowFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *eth.Config) {
@@ -1416,6 +1522,17 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerNoVerfiyFlag.Name) {
 		cfg.Noverify = ctx.GlobalBool(MinerNoVerfiyFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerParallelFlag.Name) {
+		cfg.Parallel = ctx.GlobalBool(MinerParallelFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerOrderingFlag.Name) {
+		switch ordering := ctx.GlobalString(MinerOrderingFlag.Name); ordering {
+		case miner.OrderingPrice, miner.OrderingFIFO, miner.OrderingHash:
+			cfg.Ordering = ordering
+		default:
+			Fatalf("Invalid %s: %q (want %q, %q or %q)", MinerOrderingFlag.Name, ordering, miner.OrderingPrice, miner.OrderingFIFO, miner.OrderingHash)
+		}
+	}
 }
 
 func setWhitelist(ctx *cli.Context, cfg *eth.Config) {
@@ -1492,6 +1609,71 @@ func SetShhConfig(ctx *cli.Context, stack *node.Node) {
 	}
 }
 
+// SetChannelConfig applies payment channel related command line flags to the config.
+func SetChannelConfig(ctx *cli.Context, cfg *channel.Config) {
+	if ctx.GlobalIsSet(ChannelContractFlag.Name) {
+		addr := ctx.GlobalString(ChannelContractFlag.Name)
+		if !common.IsHexAddress(addr) {
+			Fatalf("Invalid channel contract address %q", addr)
+		}
+		cfg.Contract = common.HexToAddress(addr)
+	}
+	if ctx.GlobalIsSet(ChannelAccountFlag.Name) {
+		addr := ctx.GlobalString(ChannelAccountFlag.Name)
+		if !common.IsHexAddress(addr) {
+			Fatalf("Invalid channel account address %q", addr)
+		}
+		cfg.Account = common.HexToAddress(addr)
+	}
+	if ctx.GlobalIsSet(ChannelWatchtowersFlag.Name) {
+		cfg.Watchtowers = SplitAndTrim(ctx.GlobalString(ChannelWatchtowersFlag.Name))
+	}
+	if ctx.GlobalIsSet(ChannelFeeBaseFlag.Name) {
+		cfg.FeeBase = GlobalBig(ctx, ChannelFeeBaseFlag.Name)
+	}
+	if ctx.GlobalIsSet(ChannelFeeRateFlag.Name) {
+		cfg.FeeRate = ctx.GlobalUint64(ChannelFeeRateFlag.Name)
+	}
+	if ctx.GlobalIsSet(ChannelTimelockDeltaFlag.Name) {
+		cfg.TimelockDelta = ctx.GlobalUint64(ChannelTimelockDeltaFlag.Name)
+	}
+	if ctx.GlobalIsSet(ChannelConfirmationsFlag.Name) {
+		cfg.Confirmations = ctx.GlobalUint64(ChannelConfirmationsFlag.Name)
+	}
+}
+
+// SetWatchtowerConfig applies watchtower related command line flags to the config.
+func SetWatchtowerConfig(ctx *cli.Context, cfg *watchtower.Config) {
+	if ctx.GlobalIsSet(WatchtowerAccountFlag.Name) {
+		addr := ctx.GlobalString(WatchtowerAccountFlag.Name)
+		if !common.IsHexAddress(addr) {
+			Fatalf("Invalid watchtower account address %q", addr)
+		}
+		cfg.Account = common.HexToAddress(addr)
+	}
+	if ctx.GlobalIsSet(WatchtowerContractsFlag.Name) {
+		cfg.Contracts = nil
+		for _, addr := range SplitAndTrim(ctx.GlobalString(WatchtowerContractsFlag.Name)) {
+			if !common.IsHexAddress(addr) {
+				Fatalf("Invalid watchtower contract address %q", addr)
+			}
+			cfg.Contracts = append(cfg.Contracts, common.HexToAddress(addr))
+		}
+	}
+	if ctx.GlobalIsSet(WatchtowerClientsFlag.Name) {
+		cfg.Clients = nil
+		for _, addr := range SplitAndTrim(ctx.GlobalString(WatchtowerClientsFlag.Name)) {
+			if !common.IsHexAddress(addr) {
+				Fatalf("Invalid watchtower client address %q", addr)
+			}
+			cfg.Clients = append(cfg.Clients, common.HexToAddress(addr))
+		}
+	}
+	if ctx.GlobalIsSet(WatchtowerQuotaFlag.Name) {
+		cfg.Quota = ctx.GlobalUint64(WatchtowerQuotaFlag.Name)
+	}
+}
+
 // SetEthConfig applies eth-related command line flags to the config.
 func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	// Avoid conflicting network flags
@@ -1547,6 +1729,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
 	}
+	cfg.ExtendedReceipts = ctx.GlobalBool(ExtendedReceiptsFlag.Name)
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
 	}
@@ -1573,6 +1756,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
 	}
+	if ctx.GlobalIsSet(VMParallelImportFlag.Name) {
+		cfg.ParallelExecution = ctx.GlobalBool(VMParallelImportFlag.Name)
+	}
 
 	if ctx.GlobalIsSet(EWASMInterpreterFlag.Name) {
 		cfg.EWASMInterpreter = ctx.GlobalString(EWASMInterpreterFlag.Name)
@@ -1674,6 +1860,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		if !ctx.GlobalIsSet(MinerGasPriceFlag.Name) && !ctx.GlobalIsSet(LegacyMinerGasPriceFlag.Name) {
 			cfg.Miner.GasPrice = big.NewInt(1)
 		}
+		cfg.Developer = true
 	default:
 		if cfg.NetworkId == 1 {
 			SetDNSDiscoveryDefaults(cfg, params.MainnetGenesisHash)
@@ -1727,6 +1914,22 @@ func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url strin
 	}
 }
 
+// RegisterChannelService configures the payment channel service and adds it to
+// the given node.
+func RegisterChannelService(stack *node.Node, cfg *channel.Config) {
+	if _, err := channel.New(stack, cfg); err != nil {
+		Fatalf("Failed to register the payment channel service: %v", err)
+	}
+}
+
+// RegisterWatchtowerService configures the watchtower and adds it to the given
+// node.
+func RegisterWatchtowerService(stack *node.Node, cfg *watchtower.Config) {
+	if _, err := watchtower.New(stack, cfg); err != nil {
+		Fatalf("Failed to register the watchtower: %v", err)
+	}
+}
+
 // RegisterGraphQLService is a utility function to construct a new service and register it against a node.
 func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, cfg node.Config) {
 	if err := graphql.New(stack, backend, cfg.GraphQLCors, cfg.GraphQLVirtualHosts); err != nil {
@@ -1855,6 +2058,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readOnly bool) (chain *core.B
 		TrieTimeLimit:       eth.DefaultConfig.TrieTimeout,
 		SnapshotLimit:       eth.DefaultConfig.SnapshotCache,
 		Preimages:           ctx.GlobalBool(CachePreimagesFlag.Name),
+		ExtendedReceipts:    ctx.GlobalBool(ExtendedReceiptsFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
@@ -1869,7 +2073,10 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readOnly bool) (chain *core.B
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cache.TrieDirtyLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
-	vmcfg := vm.Config{EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name)}
+	vmcfg := vm.Config{
+		EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name),
+		ParallelExecution:       ctx.GlobalBool(VMParallelImportFlag.Name),
+	}
 	var limit *uint64
 	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) && !readOnly {
 		l := ctx.GlobalUint64(TxLookupLimitFlag.Name)
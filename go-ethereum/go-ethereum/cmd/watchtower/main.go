@@ -0,0 +1,116 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// watchtower is a standalone watchtower contesting stale payment channel
+// disputes on behalf of channel parties, following the chain of a remote node.
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/channel/watchtower"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+var (
+	datadirFlag   = flag.String("datadir", "", "Data directory for the justice blobs and responses")
+	rpcFlag       = flag.String("rpc", "", "RPC endpoint of the node to follow the chain of")
+	contractsFlag = flag.String("contracts", "", "Comma separated channel contract addresses to watch")
+	clientsFlag   = flag.String("clients", "", "Comma separated accounts allowed to upload justice blobs")
+	quotaFlag     = flag.Uint64("quota", watchtower.DefaultConfig.Quota, "Maximum number of justice blobs stored per client")
+
+	accJSONFlag = flag.String("account.json", "", "Key json file to pay for justice transactions with")
+	accPassFlag = flag.String("account.pass", "", "Decryption password to access the account key")
+
+	httpAddrFlag = flag.String("http.addr", "localhost", "Listener interface for the HTTP API")
+	httpPortFlag = flag.Int("http.port", 8545, "Listener port for the HTTP API")
+
+	logFlag = flag.Int("loglevel", 3, "Log level to use for the watchtower")
+)
+
+func main() {
+	// Parse the flags and set up the logger to print everything requested
+	flag.Parse()
+	log.Root().SetHandler(log.LvlFilterHandler(log.Lvl(*logFlag), log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	if *rpcFlag == "" {
+		log.Crit("No RPC endpoint specified")
+	}
+	config := &watchtower.Config{Quota: *quotaFlag}
+	for _, address := range strings.Split(*contractsFlag, ",") {
+		if address = strings.TrimSpace(address); address == "" {
+			continue
+		}
+		if !common.IsHexAddress(address) {
+			log.Crit("Invalid channel contract address", "address", address)
+		}
+		config.Contracts = append(config.Contracts, common.HexToAddress(address))
+	}
+	for _, address := range strings.Split(*clientsFlag, ",") {
+		if address = strings.TrimSpace(address); address == "" {
+			continue
+		}
+		if !common.IsHexAddress(address) {
+			log.Crit("Invalid client address", "address", address)
+		}
+		config.Clients = append(config.Clients, common.HexToAddress(address))
+	}
+	// Load up the account key and decrypt it
+	blob, err := ioutil.ReadFile(*accPassFlag)
+	if err != nil {
+		log.Crit("Failed to read account password contents", "file", *accPassFlag, "err", err)
+	}
+	pass := strings.TrimSuffix(string(blob), "\n")
+
+	if blob, err = ioutil.ReadFile(*accJSONFlag); err != nil {
+		log.Crit("Failed to read account key contents", "file", *accJSONFlag, "err", err)
+	}
+	key, err := keystore.DecryptKey(blob, pass)
+	if err != nil {
+		log.Crit("Failed to decrypt account key", "err", err)
+	}
+	// Assemble a node without networking serving the watchtower API
+	stack, err := node.New(&node.Config{
+		Name:        "watchtower",
+		DataDir:     *datadirFlag,
+		HTTPHost:    *httpAddrFlag,
+		HTTPPort:    *httpPortFlag,
+		HTTPModules: []string{"watchtower"},
+		P2P: p2p.Config{
+			NoDiscovery: true,
+			MaxPeers:    0,
+			ListenAddr:  "",
+		},
+	})
+	if err != nil {
+		log.Crit("Failed to create node", "err", err)
+	}
+	if _, err := watchtower.NewRemote(stack, config, *rpcFlag, key.PrivateKey); err != nil {
+		log.Crit("Failed to create watchtower", "err", err)
+	}
+	if err := stack.Start(); err != nil {
+		log.Crit("Failed to start watchtower", "err", err)
+	}
+	log.Info("Watchtower started", "account", key.Address, "contracts", len(config.Contracts))
+	stack.Wait()
+}
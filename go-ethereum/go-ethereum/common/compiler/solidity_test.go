@@ -76,3 +76,24 @@ func TestSolidityCompileError(t *testing.T) {
 	}
 	t.Logf("error: %v", err)
 }
+
+func TestParseCombinedJSONV8(t *testing.T) {
+	output := `{"contracts":{"test.sol:test":{"abi":[{"inputs":[],"name":"f","outputs":[],"stateMutability":"nonpayable","type":"function"}],"bin":"6080","bin-runtime":"6080","devdoc":{"kind":"dev","methods":{},"version":1},"hashes":{"f()":"26121ff0"},"metadata":"{}","srcmap":"","srcmap-runtime":"","userdoc":{"kind":"user","methods":{},"version":1}}},"version":"0.8.0"}`
+	contracts, err := ParseCombinedJSON([]byte(output), "", "0.8.0", "0.8.0", "")
+	if err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	c, ok := contracts["test.sol:test"]
+	if !ok {
+		t.Fatal("info for contract 'test' not present in result")
+	}
+	if c.Code != "0x6080" {
+		t.Errorf("code mismatch: have %s, want 0x6080", c.Code)
+	}
+	if abi, ok := c.Info.AbiDefinition.([]interface{}); !ok || len(abi) != 1 {
+		t.Errorf("abi definition mismatch: %v", c.Info.AbiDefinition)
+	}
+	if c.Hashes["f()"] != "26121ff0" {
+		t.Errorf("hashes mismatch: %v", c.Hashes)
+	}
+}
@@ -17,6 +17,8 @@
 package clique
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -175,3 +177,57 @@ func (api *API) Status() (*status, error) {
 		NumBlocks:     numBlocks,
 	}, nil
 }
+
+// Liveness returns the sealing activity of every signer over the last window
+// blocks (64 if unspecified, at most 1024): blocks sealed in-turn and
+// out-of-turn, in-turn slots missed, the last block sealed and the average delay
+// past the period.
+func (api *API) Liveness(window *uint64) (*Liveness, error) {
+	blocks := uint64(livenessWindow)
+	if window != nil {
+		blocks = *window
+	}
+	if blocks > maxLivenessWindow {
+		return nil, fmt.Errorf("liveness window too large: %d > %d", blocks, maxLivenessWindow)
+	}
+	return api.clique.liveness(api.chain, api.chain.CurrentHeader(), blocks)
+}
+
+// PublicAPI is the public RPC API of the proof-of-authority scheme, exposed in
+// the clique namespace to allow subscribing to signer liveness reports.
+type PublicAPI struct {
+	tracker *livenessTracker
+}
+
+// SignerLiveness creates a subscription that is notified with the liveness of
+// all signers each time the chain head changes.
+func (api *PublicAPI) SignerLiveness(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if api.tracker == nil {
+		return &rpc.Subscription{}, errors.New("liveness tracker not running")
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		reports := make(chan *Liveness)
+		reportsSub := api.tracker.subscribe(reports)
+		defer reportsSub.Unsubscribe()
+
+		for {
+			select {
+			case report := <-reports:
+				notifier.Notify(rpcSub.ID, report)
+			case <-reportsSub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -179,7 +179,13 @@ type Clique struct {
 
 	signer common.Address // Ethereum address of the signing key
 	signFn SignerFn       // Signer function to authorize hashes with
-	lock   sync.RWMutex   // Protects the signer fields
+	lock   sync.RWMutex   // Protects the signer and development fields
+
+	tracker     *livenessTracker // Signer liveness reporter, started along with the APIs
+	trackerOnce sync.Once        // Ensures the liveness reporter is only started once
+
+	timeOffset time.Duration // Shift of the engine clock, for development chains only
+	sealEmpty  bool          // Whether the next empty block may be sealed on 0-period chains
 
 	// The fields below are for testing only
 	fakeDiff bool // Skip difficulty verifications
@@ -206,6 +212,33 @@ func New(config *params.CliqueConfig, db ethdb.Database) *Clique {
 	}
 }
 
+// SetTimeOffset shifts the clock the engine uses to time new blocks and to reject
+// future ones. It is meant for development chains only, to move through time
+// without waiting for it.
+func (c *Clique) SetTimeOffset(offset time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.timeOffset = offset
+}
+
+// AllowEmptyBlock permits the next empty block to be sealed on chains without a
+// block period, which otherwise only seal blocks with transactions in them.
+func (c *Clique) AllowEmptyBlock() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.sealEmpty = true
+}
+
+// now returns the current time as seen by the engine.
+func (c *Clique) now() time.Time {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return time.Now().Add(c.timeOffset)
+}
+
 // Author implements consensus.Engine, returning the Ethereum address recovered
 // from the signature in the header's extra-data section.
 func (c *Clique) Author(header *types.Header) (common.Address, error) {
@@ -249,7 +282,7 @@ func (c *Clique) verifyHeader(chain consensus.ChainHeaderReader, header *types.H
 	number := header.Number.Uint64()
 
 	// Don't waste time checking blocks from the future
-	if header.Time > uint64(time.Now().Unix()) {
+	if header.Time > uint64(c.now().Unix()) {
 		return consensus.ErrFutureBlock
 	}
 	// Checkpoint blocks need to enforce zero beneficiary
@@ -544,8 +577,8 @@ func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 		return consensus.ErrUnknownAncestor
 	}
 	header.Time = parent.Time + c.config.Period
-	if header.Time < uint64(time.Now().Unix()) {
-		header.Time = uint64(time.Now().Unix())
+	if now := uint64(c.now().Unix()); header.Time < now {
+		header.Time = now
 	}
 	return nil
 }
@@ -588,10 +621,18 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 	if number == 0 {
 		return errUnknownBlock
 	}
-	// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing)
+	// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing),
+	// unless one was explicitly allowed
 	if c.config.Period == 0 && len(block.Transactions()) == 0 {
-		log.Info("Sealing paused, waiting for transactions")
-		return nil
+		c.lock.Lock()
+		allowed := c.sealEmpty
+		c.sealEmpty = false
+		c.lock.Unlock()
+
+		if !allowed {
+			log.Info("Sealing paused, waiting for transactions")
+			return nil
+		}
 	}
 	// Don't hold the signer fields for the entire sealing procedure
 	c.lock.RLock()
@@ -617,7 +658,7 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 		}
 	}
 	// Sweet, the protocol permits us to sign the block, wait for our time
-	delay := time.Unix(int64(header.Time), 0).Sub(time.Now()) // nolint: gosimple
+	delay := time.Unix(int64(header.Time), 0).Sub(c.now())
 	if header.Difficulty.Cmp(diffNoTurn) == 0 {
 		// It's not our turn explicitly to sign, delay it a bit
 		wiggle := time.Duration(len(snap.Signers)/2+1) * wiggleTime
@@ -674,19 +715,32 @@ func (c *Clique) SealHash(header *types.Header) common.Hash {
 	return SealHash(header)
 }
 
-// Close implements consensus.Engine. It's a noop for clique as there are no background threads.
+// Close implements consensus.Engine, terminating the signer liveness reporter
+// if it was started.
 func (c *Clique) Close() error {
+	c.trackerOnce.Do(func() {}) // Prevent a late start after closing
+	if c.tracker != nil {
+		c.tracker.close()
+	}
 	return nil
 }
 
 // APIs implements consensus.Engine, returning the user facing RPC API to allow
-// controlling the signer voting.
+// controlling the signer voting and monitoring signer liveness.
 func (c *Clique) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	c.trackerOnce.Do(func() {
+		c.tracker = newLivenessTracker(c, chain)
+	})
 	return []rpc.API{{
 		Namespace: "clique",
 		Version:   "1.0",
 		Service:   &API{chain: chain, clique: c},
 		Public:    false,
+	}, {
+		Namespace: "clique",
+		Version:   "1.0",
+		Service:   &PublicAPI{tracker: c.tracker},
+		Public:    true,
 	}}
 }
 
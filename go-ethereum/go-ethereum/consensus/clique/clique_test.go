@@ -19,8 +19,10 @@ package clique
 import (
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -111,3 +113,45 @@ func TestReimportMirroredState(t *testing.T) {
 		t.Fatalf("chain head mismatch: have %d, want %d", head, 3)
 	}
 }
+
+// Tests that shifting the clock of the engine moves the timestamps of prepared
+// headers and the point after which headers are rejected as future ones.
+func TestTimeOffset(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		addr   = common.Address{0x01}
+		engine = New(params.AllCliqueProtocolChanges.Clique, db)
+	)
+	genspec := &core.Genesis{ExtraData: make([]byte, extraVanity+common.AddressLength+extraSeal)}
+	copy(genspec.ExtraData[extraVanity:], addr[:])
+	genesis := genspec.MustCommit(db)
+
+	chain, _ := core.NewBlockChain(db, nil, params.AllCliqueProtocolChanges, engine, vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	prepare := func() uint64 {
+		header := &types.Header{ParentHash: genesis.Hash(), Number: big.NewInt(1)}
+		if err := engine.Prepare(chain, header); err != nil {
+			t.Fatalf("failed to prepare header: %v", err)
+		}
+		return header.Time
+	}
+	future := &types.Header{ParentHash: genesis.Hash(), Number: big.NewInt(1), Time: uint64(time.Now().Add(time.Hour).Unix())}
+
+	want := uint64(time.Now().Unix())
+	if have := prepare(); have < want || have > want+1 {
+		t.Fatalf("unshifted timestamp mismatch: have %d, want %d", have, want)
+	}
+	if err := engine.VerifyHeader(chain, future, false); err != consensus.ErrFutureBlock {
+		t.Fatalf("unshifted future header error mismatch: have %v, want %v", err, consensus.ErrFutureBlock)
+	}
+	engine.SetTimeOffset(2 * time.Hour)
+
+	want = uint64(time.Now().Add(2 * time.Hour).Unix())
+	if have := prepare(); have < want || have > want+1 {
+		t.Fatalf("shifted timestamp mismatch: have %d, want %d", have, want)
+	}
+	if err := engine.VerifyHeader(chain, future, false); err == consensus.ErrFutureBlock {
+		t.Fatalf("shifted future header rejected as future block")
+	}
+}
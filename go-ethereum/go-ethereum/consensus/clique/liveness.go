@@ -0,0 +1,214 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	livenessWindow    = 64   // Default number of blocks to aggregate signer liveness over
+	maxLivenessWindow = 1024 // Maximum number of blocks a liveness report may aggregate
+
+	// chainHeadChanSize is the size of channel listening to chain head headers.
+	chainHeadChanSize = 10
+)
+
+// SignerLiveness is the sealing activity of a single signer within a window of
+// recent blocks.
+type SignerLiveness struct {
+	Sealed    uint64  `json:"sealed"`    // Number of blocks sealed by the signer
+	InTurn    uint64  `json:"inturn"`    // Number of blocks sealed while being in-turn
+	OutOfTurn uint64  `json:"outOfTurn"` // Number of blocks sealed while being out-of-turn
+	Missed    uint64  `json:"missed"`    // Number of in-turn slots sealed by someone else
+	LastSeen  uint64  `json:"lastSeen"`  // Number of the last block sealed, zero if none in the window
+	AvgDelay  float64 `json:"avgDelay"`  // Average seconds a sealed block arrived past the period
+}
+
+// Liveness is the per-signer activity report over a window of blocks ending at
+// a given head.
+type Liveness struct {
+	Number  uint64                             `json:"number"`  // Number of the last block in the window
+	Hash    common.Hash                        `json:"hash"`    // Hash of the last block in the window
+	Window  uint64                             `json:"window"`  // Number of blocks aggregated
+	Period  uint64                             `json:"period"`  // Configured block period in seconds
+	Signers map[common.Address]*SignerLiveness `json:"signers"` // Activity of every signer seen
+}
+
+// liveness aggregates the sealing activity of all signers over the window blocks
+// ending with the given header. Signers authorized at the head are always part
+// of the report, even if they did not seal anything.
+func (c *Clique) liveness(chain consensus.ChainHeaderReader, header *types.Header, window uint64) (*Liveness, error) {
+	snap, err := c.snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if number := header.Number.Uint64(); window > number {
+		window = number
+	}
+	report := &Liveness{
+		Number:  header.Number.Uint64(),
+		Hash:    header.Hash(),
+		Window:  window,
+		Period:  c.config.Period,
+		Signers: make(map[common.Address]*SignerLiveness),
+	}
+	stats := func(signer common.Address) *SignerLiveness {
+		if report.Signers[signer] == nil {
+			report.Signers[signer] = new(SignerLiveness)
+		}
+		return report.Signers[signer]
+	}
+	for _, signer := range snap.signers() {
+		stats(signer)
+	}
+	delays := make(map[common.Address]uint64)
+	for i := uint64(0); i < window; i++ {
+		number := header.Number.Uint64()
+		parent := chain.GetHeader(header.ParentHash, number-1)
+		if parent == nil {
+			return nil, fmt.Errorf("missing block %d", number-1)
+		}
+		// The signers allowed to seal a block are the ones authorized by its parent
+		snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+		if err != nil {
+			return nil, err
+		}
+		sealer, err := c.Author(header)
+		if err != nil {
+			return nil, err
+		}
+		sealed := stats(sealer)
+		sealed.Sealed++
+		if sealed.LastSeen < number {
+			sealed.LastSeen = number
+		}
+		if header.Difficulty.Cmp(diffInTurn) == 0 {
+			sealed.InTurn++
+		} else {
+			sealed.OutOfTurn++
+
+			signers := snap.signers()
+			stats(signers[number%uint64(len(signers))]).Missed++
+		}
+		if deadline := parent.Time + c.config.Period; header.Time > deadline {
+			delays[sealer] += header.Time - deadline
+		}
+		header = parent
+	}
+	for signer, delay := range delays {
+		report.Signers[signer].AvgDelay = float64(delay) / float64(report.Signers[signer].Sealed)
+	}
+	return report, nil
+}
+
+// livenessTracker recomputes the signer liveness report whenever the chain head
+// changes, publishing it to the metrics registry and to any subscribers.
+type livenessTracker struct {
+	clique *Clique
+	chain  consensus.ChainHeaderReader
+
+	headCh  chan *types.Header
+	headSub event.Subscription
+
+	feed      event.Feed
+	scope     event.SubscriptionScope
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+// newLivenessTracker creates a liveness tracker and starts its update loop. It
+// returns nil if the chain doesn't notify about head changes.
+func newLivenessTracker(clique *Clique, chain consensus.ChainHeaderReader) *livenessTracker {
+	subscriber, ok := chain.(consensus.ChainHeadSubscriber)
+	if !ok {
+		return nil
+	}
+	tracker := &livenessTracker{
+		clique: clique,
+		chain:  chain,
+		headCh: make(chan *types.Header, chainHeadChanSize),
+		quit:   make(chan struct{}),
+	}
+	tracker.headSub = subscriber.SubscribeChainHeaderEvent(tracker.headCh)
+
+	go tracker.loop()
+	return tracker
+}
+
+// subscribe registers a channel to receive a liveness report for every new head.
+func (t *livenessTracker) subscribe(ch chan<- *Liveness) event.Subscription {
+	return t.scope.Track(t.feed.Subscribe(ch))
+}
+
+// close terminates the update loop and all active subscriptions.
+func (t *livenessTracker) close() {
+	t.closeOnce.Do(func() {
+		t.scope.Close()
+		close(t.quit)
+	})
+}
+
+// loop reports the liveness of the signers each time the chain head changes.
+func (t *livenessTracker) loop() {
+	defer t.headSub.Unsubscribe()
+
+	for {
+		select {
+		case head := <-t.headCh:
+			// Skip over heads already superseded if blocks are imported in bulk
+			for len(t.headCh) > 0 {
+				head = <-t.headCh
+			}
+			report, err := t.clique.liveness(t.chain, head, livenessWindow)
+			if err != nil {
+				continue
+			}
+			if metrics.Enabled {
+				reportLivenessMetrics(report)
+			}
+			t.feed.Send(report)
+
+		case <-t.headSub.Err():
+			return
+
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+// reportLivenessMetrics updates the per-signer gauges from a liveness report.
+func reportLivenessMetrics(report *Liveness) {
+	for signer, stats := range report.Signers {
+		prefix := fmt.Sprintf("clique/signer/%x/", signer)
+
+		metrics.GetOrRegisterGauge(prefix+"sealed", nil).Update(int64(stats.Sealed))
+		metrics.GetOrRegisterGauge(prefix+"inturn", nil).Update(int64(stats.InTurn))
+		metrics.GetOrRegisterGauge(prefix+"outturn", nil).Update(int64(stats.OutOfTurn))
+		metrics.GetOrRegisterGauge(prefix+"missed", nil).Update(int64(stats.Missed))
+		metrics.GetOrRegisterGauge(prefix+"lastseen", nil).Update(int64(stats.LastSeen))
+		metrics.GetOrRegisterGauge(prefix+"delay", nil).Update(int64(stats.AvgDelay * 1000))
+	}
+}
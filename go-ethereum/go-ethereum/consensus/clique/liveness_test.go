@@ -0,0 +1,198 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the signer liveness report correctly attributes sealed blocks,
+// in-turn and out-of-turn seals, missed slots and sealing delays.
+func TestLiveness(t *testing.T) {
+	// Create three signers and sort them to know the in-turn order
+	accounts := newTesterAccountPool()
+
+	names := []string{"A", "B", "C"}
+	signers := make([]common.Address, len(names))
+	for i, name := range names {
+		signers[i] = accounts.address(name)
+	}
+	sort.Sort(signersAscending(signers))
+
+	byAddr := make(map[common.Address]string)
+	for _, name := range names {
+		byAddr[accounts.address(name)] = name
+	}
+	inturn := func(number int) string { return byAddr[signers[number%len(signers)]] }
+
+	genesis := &core.Genesis{
+		ExtraData: make([]byte, extraVanity+common.AddressLength*len(signers)+extraSeal),
+	}
+	for i, signer := range signers {
+		copy(genesis.ExtraData[extraVanity+i*common.AddressLength:], signer[:])
+	}
+	db := rawdb.NewMemoryDatabase()
+	genesis.Commit(db)
+
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 5, Epoch: 30000}
+	engine := New(config.Clique, db)
+	engine.fakeDiff = true
+
+	// Seal block 1 in-turn, then let two out-of-turn signers take over the
+	// slots of blocks 2 and 3, and finish with in-turn blocks 4 and 5
+	sealers := []string{inturn(1), inturn(0), inturn(2), inturn(4), inturn(5)}
+
+	blocks, _ := core.GenerateChain(&config, genesis.ToBlock(db), engine, db, len(sealers), func(i int, gen *core.BlockGen) {})
+	for i, block := range blocks {
+		header := block.Header()
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		header.Difficulty = diffNoTurn
+		if sealers[i] == inturn(i+1) {
+			header.Difficulty = diffInTurn
+		}
+		accounts.sign(header, sealers[i])
+		blocks[i] = block.WithSeal(header)
+	}
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create test chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	report, err := engine.liveness(chain, chain.CurrentHeader(), livenessWindow)
+	if err != nil {
+		t.Fatalf("failed to compute liveness: %v", err)
+	}
+	if report.Window != uint64(len(blocks)) {
+		t.Errorf("window mismatch: have %d, want %d", report.Window, len(blocks))
+	}
+	if len(report.Signers) != len(signers) {
+		t.Fatalf("signer count mismatch: have %d, want %d", len(report.Signers), len(signers))
+	}
+	// Expected activity, derived from the sealing schedule above
+	want := make(map[string]*SignerLiveness)
+	for _, name := range names {
+		want[name] = new(SignerLiveness)
+	}
+	for i, sealer := range sealers {
+		number := uint64(i + 1)
+
+		want[sealer].Sealed++
+		want[sealer].LastSeen = number
+		if sealer == inturn(i+1) {
+			want[sealer].InTurn++
+		} else {
+			want[sealer].OutOfTurn++
+			want[inturn(i+1)].Missed++
+		}
+		// The chain maker spaces blocks 10 seconds apart, 5 above the period
+		want[sealer].AvgDelay = 5
+	}
+	for _, name := range names {
+		have := report.Signers[accounts.address(name)]
+		if *have != *want[name] {
+			t.Errorf("signer %s: liveness mismatch: have %+v, want %+v", name, have, want[name])
+		}
+	}
+}
+
+// Tests that liveness reports are pushed to subscribers whenever the chain head
+// changes, and that oversized report windows are refused.
+func TestLivenessSubscription(t *testing.T) {
+	accounts := newTesterAccountPool()
+	signer := accounts.address("A")
+
+	genesis := &core.Genesis{
+		ExtraData: make([]byte, extraVanity+common.AddressLength+extraSeal),
+	}
+	copy(genesis.ExtraData[extraVanity:], signer[:])
+
+	db := rawdb.NewMemoryDatabase()
+	genesis.Commit(db)
+
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 5, Epoch: 30000}
+	engine := New(config.Clique, db)
+	engine.fakeDiff = true
+
+	blocks, _ := core.GenerateChain(&config, genesis.ToBlock(db), engine, db, 3, func(i int, gen *core.BlockGen) {})
+	for i, block := range blocks {
+		header := block.Header()
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		header.Difficulty = diffInTurn
+		accounts.sign(header, "A")
+		blocks[i] = block.WithSeal(header)
+	}
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create test chain: %v", err)
+	}
+	defer chain.Stop()
+
+	apis := engine.APIs(chain)
+	defer engine.Close()
+
+	for _, api := range apis {
+		if api.Namespace != "clique" {
+			t.Errorf("api %T registered in namespace %q, want clique", api.Service, api.Namespace)
+		}
+	}
+
+	reports := make(chan *Liveness, 1)
+	sub := engine.tracker.subscribe(reports)
+	defer sub.Unsubscribe()
+
+	for i, block := range blocks {
+		if _, err := chain.InsertChain(blocks[i : i+1]); err != nil {
+			t.Fatalf("failed to insert block %d: %v", i, err)
+		}
+		select {
+		case report := <-reports:
+			if report.Hash != block.Hash() || report.Window != uint64(i+1) {
+				t.Errorf("block %d: report mismatch: have #%d [%x] over %d blocks", i, report.Number, report.Hash, report.Window)
+			}
+			if have := report.Signers[signer]; have == nil || have.InTurn != uint64(i+1) {
+				t.Errorf("block %d: signer liveness mismatch: have %+v", i, have)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("block %d: no liveness report", i)
+		}
+	}
+	api := apis[0].Service.(*API)
+	window := uint64(maxLivenessWindow + 1)
+	if _, err := api.Liveness(&window); err == nil {
+		t.Errorf("oversized window accepted")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -55,6 +56,14 @@ type ChainReader interface {
 	GetBlock(hash common.Hash, number uint64) *types.Block
 }
 
+// ChainHeadSubscriber is implemented by the chains able to notify consensus
+// engines about changes of the canonical chain head.
+type ChainHeadSubscriber interface {
+	// SubscribeChainHeaderEvent registers a subscription for the header of every
+	// new canonical chain head.
+	SubscribeChainHeaderEvent(ch chan<- *types.Header) event.Subscription
+}
+
 // Engine is an algorithm agnostic consensus engine.
 type Engine interface {
 	// Author retrieves the Ethereum address of the account that minted the given
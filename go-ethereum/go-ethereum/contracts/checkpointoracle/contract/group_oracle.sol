@@ -0,0 +1,177 @@
+pragma solidity ^0.8.0;
+
+/**
+ * @title GroupCheckpointOracle
+ * @dev Implementation of the blockchain checkpoint registrar, approving
+ * checkpoints with a TIBGS group signature instead of a list of admin ones.
+ *
+ * The checkpoint committee is a TIBGS group, whose managers each hold a share of
+ * the key of the member signing checkpoints. A threshold of them combine their
+ * shares off-chain to sign a checkpoint, the single group signature of which is
+ * verified by the group signature precompile. As the signature approves the
+ * checkpoint on its own, anyone can submit it.
+ *
+ * The precompile takes the '#' separated hex encodings of the elements of the
+ * master public key, the signature and the message signed. Keys and signatures
+ * are passed to the contract as the raw concatenation of their elements, which
+ * the contract hex encodes itself: the master public key as 9 elements of 128
+ * bytes, the signature as 6 elements of 128 bytes followed by 4 of 20 bytes.
+ *
+ * The oracle has no constructor, for its code to be the same on every chain it
+ * is deployed on. It is initialized once after its deployment instead.
+ */
+contract GroupCheckpointOracle {
+    /*
+        Events
+    */
+
+    // NewCheckpoint is emitted when a new checkpoint is registered.
+    event NewCheckpoint(uint64 indexed index, bytes32 checkpointHash, bytes signature);
+
+    /*
+        Public Functions
+    */
+
+    // Initialize sets the checkpointing parameters and the master public key of
+    // the committee. It can only be called once.
+    function Initialize(uint _sectionSize, uint _processConfirms, bytes calldata _key) external {
+        require(sectionSize == 0 && _sectionSize != 0);
+        require(_key.length == 9*128);
+
+        sectionSize = _sectionSize;
+        processConfirms = _processConfirms;
+        keyHash = keccak256(_key);
+    }
+
+    /**
+     * @dev Get latest stable checkpoint information.
+     * @return section index
+     * @return checkpoint hash
+     * @return block height associated with checkpoint
+     */
+    function GetLatestCheckpoint()
+    view
+    public
+    returns(uint64, bytes32, uint) {
+        return (sectionIndex, hash, height);
+    }
+
+    /**
+     * @dev Get the checkpointing parameters.
+     * @return section size
+     * @return process confirmations
+     * @return hash of the master public key of the committee
+     */
+    function GetConfig()
+    view
+    public
+    returns(uint, uint, bytes32) {
+        return (sectionSize, processConfirms, keyHash);
+    }
+
+    // SetCheckpoint sets a new checkpoint. It accepts a group signature of the
+    // committee.
+    // @_recentNumber: a recent blocknumber, for replay protection
+    // @_recentHash : the hash of `_recentNumber`
+    // @_hash : the hash to set at _sectionIndex
+    // @_sectionIndex : the section index to set
+    // @_key : the master public key of the committee
+    // @_sig : the group signature
+    function SetCheckpoint(
+        uint _recentNumber,
+        bytes32 _recentHash,
+        bytes32 _hash,
+        uint64 _sectionIndex,
+        bytes calldata _key,
+        bytes calldata _sig)
+        external
+        returns (bool)
+    {
+        require(sectionSize != 0);
+
+        // These checks replay protection, so it cannot be replayed on forks,
+        // accidentally or intentionally
+        require(blockhash(_recentNumber) == _recentHash);
+
+        // Filter out "future" checkpoint.
+        if (block.number < (_sectionIndex+1)*sectionSize+processConfirms) {
+            return false;
+        }
+        // Filter out "old" announcement
+        if (_sectionIndex < sectionIndex) {
+            return false;
+        }
+        // Filter out "stale" announcement
+        if (_sectionIndex == sectionIndex && (_sectionIndex != 0 || height != 0)) {
+            return false;
+        }
+        // Filter out "invalid" announcement
+        if (_hash == ""){
+            return false;
+        }
+        require(keccak256(_key) == keyHash);
+        require(_sig.length == 6*128 + 4*20);
+
+        // EIP 191 style signatures, the same data the admins of the
+        // CheckpointOracle sign
+        bytes32 signedHash = keccak256(abi.encodePacked(bytes1(0x19), bytes1(0), address(this), _sectionIndex, _hash));
+
+        bytes memory input = new bytes(2*(_key.length + _sig.length + 32) + 19);
+        uint pos = 0;
+        for (uint i = 0; i < 9; i++) {
+            pos = putHex(input, pos, _key[i*128:(i+1)*128]);
+            input[pos++] = "#";
+        }
+        for (uint i = 0; i < 10; i++) {
+            uint start = i < 6 ? i*128 : 6*128 + (i-6)*20;
+            pos = putHex(input, pos, _sig[start:start+(i < 6 ? 128 : 20)]);
+            input[pos++] = "#";
+        }
+        putHex(input, pos, abi.encodePacked(signedHash));
+
+        (bool ok, bytes memory valid) = address(0x13).staticcall(input);
+        require(ok && valid.length == 32 && uint(abi.decode(valid, (bytes32))) == 1);
+
+        hash = _hash;
+        height = block.number;
+        sectionIndex = _sectionIndex;
+        emit NewCheckpoint(_sectionIndex, _hash, _sig);
+        return true;
+    }
+
+    // putHex writes the lowercase hex encoding of some data into out at pos,
+    // returning the position following it.
+    function putHex(bytes memory out, uint pos, bytes memory data) internal pure returns (uint) {
+        bytes16 digits = "0123456789abcdef";
+        unchecked {
+            for (uint i = 0; i < data.length; i++) {
+                uint8 b = uint8(data[i]);
+                out[pos++] = digits[b >> 4];
+                out[pos++] = digits[b & 0x0f];
+            }
+        }
+        return pos;
+    }
+
+    /*
+        Fields
+    */
+
+    // Latest stored section id
+    uint64 sectionIndex;
+
+    // The block height associated with latest registered checkpoint.
+    uint height;
+
+    // The hash of latest registered checkpoint.
+    bytes32 hash;
+
+    // The frequency for creating a checkpoint
+    uint sectionSize;
+
+    // The number of confirmations needed before a checkpoint can be registered.
+    uint processConfirms;
+
+    // The hash of the master public key of the committee.
+    bytes32 keyHash;
+}
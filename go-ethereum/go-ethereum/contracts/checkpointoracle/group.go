@@ -0,0 +1,130 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package checkpointoracle
+
+//go:generate abigen --sol contract/group_oracle.sol --pkg contract --out contract/group_oracle.go
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/checkpointoracle/contract"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// GroupSignerID is the identity of the member of the committee's TIBGS group
+// signing checkpoints, whose secret key the managers of the group hold shares of.
+const GroupSignerID = "checkpoint"
+
+// GroupMessage returns the message the committee signs to approve a checkpoint
+// of the group checkpoint oracle at the given address: the EIP 191 hash the
+// admins of a regular oracle sign, as a string.
+func GroupMessage(oracle common.Address, index uint64, hash common.Hash) string {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, index)
+
+	data := append([]byte{0x19, 0x00}, append(oracle[:], append(buf, hash[:]...)...)...)
+	return string(crypto.Keccak256(data))
+}
+
+// GroupCheckpointOracle is a Go wrapper around an on-chain checkpoint oracle
+// contract approving checkpoints with a group signature.
+type GroupCheckpointOracle struct {
+	address  common.Address
+	contract *contract.GroupCheckpointOracle
+}
+
+// NewGroupCheckpointOracle binds group checkpoint contract and returns a
+// registrar instance.
+func NewGroupCheckpointOracle(contractAddr common.Address, backend bind.ContractBackend) (*GroupCheckpointOracle, error) {
+	c, err := contract.NewGroupCheckpointOracle(contractAddr, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &GroupCheckpointOracle{address: contractAddr, contract: c}, nil
+}
+
+// DeployGroupCheckpointOracle sends the transactions deploying a group checkpoint
+// oracle and initializing it with the encoded master public key of the committee.
+// The oracle is usable once both transactions are mined.
+func DeployGroupCheckpointOracle(opts *bind.TransactOpts, backend bind.ContractBackend, sectionSize, processConfirms uint64, key []byte) (*GroupCheckpointOracle, []*types.Transaction, error) {
+	packed, err := vm.PackGroupKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	addr, deployTx, c, err := contract.DeployGroupCheckpointOracle(opts, backend)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Chain the nonces explicitly, the pending nonce of the backend might not
+	// account for the deployment just sent yet
+	init := *opts
+	init.Nonce = new(big.Int).SetUint64(deployTx.Nonce() + 1)
+
+	initTx, err := c.Initialize(&init, new(big.Int).SetUint64(sectionSize), new(big.Int).SetUint64(processConfirms), packed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &GroupCheckpointOracle{address: addr, contract: c}, []*types.Transaction{deployTx, initTx}, nil
+}
+
+// ContractAddr returns the address of contract.
+func (oracle *GroupCheckpointOracle) ContractAddr() common.Address {
+	return oracle.address
+}
+
+// Contract returns the underlying contract instance.
+func (oracle *GroupCheckpointOracle) Contract() *contract.GroupCheckpointOracle {
+	return oracle.contract
+}
+
+// LookupCheckpointEvents searches checkpoint event for specific section in the
+// given log batches.
+func (oracle *GroupCheckpointOracle) LookupCheckpointEvents(blockLogs [][]*types.Log, section uint64, hash common.Hash) []*contract.GroupCheckpointOracleNewCheckpoint {
+	var events []*contract.GroupCheckpointOracleNewCheckpoint
+
+	for _, logs := range blockLogs {
+		for _, log := range logs {
+			event, err := oracle.contract.ParseNewCheckpoint(*log)
+			if err != nil {
+				continue
+			}
+			if event.Index == section && event.CheckpointHash == hash {
+				events = append(events, event)
+			}
+		}
+	}
+	return events
+}
+
+// RegisterCheckpoint registers the checkpoint with the group signature of the
+// committee, given along with its master public key in their encoded form.
+func (oracle *GroupCheckpointOracle) RegisterCheckpoint(opts *bind.TransactOpts, index uint64, hash []byte, rnum *big.Int, rhash [32]byte, key []byte, sig []byte) (*types.Transaction, error) {
+	packedKey, err := vm.PackGroupKey(key)
+	if err != nil {
+		return nil, err
+	}
+	packedSig, err := vm.PackGroupSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	return oracle.contract.SetCheckpoint(opts, rnum, rhash, common.BytesToHash(hash), index, packedKey, packedSig)
+}
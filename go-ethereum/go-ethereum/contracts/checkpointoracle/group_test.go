@@ -0,0 +1,203 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package checkpointoracle
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// groupSigner stands in for the group signature precompile, accepting the
+// inputs of the signatures it issued.
+type groupSigner struct {
+	signed map[string]bool
+}
+
+func (s *groupSigner) RequiredGas(input []byte) uint64 { return 10 }
+
+func (s *groupSigner) Run(input []byte) ([]byte, error) {
+	if s.signed[string(input)] {
+		return common.LeftPadBytes([]byte{1}, 32), nil
+	}
+	return make([]byte, 32), nil
+}
+
+// sign issues a fake group signature of a message, valid under the given key.
+func (s *groupSigner) sign(key []byte, message string, seed byte) []byte {
+	raw := [][]byte{
+		bytes.Repeat([]byte{seed}, 128), bytes.Repeat([]byte{seed}, 128), bytes.Repeat([]byte{seed}, 128),
+		bytes.Repeat([]byte{seed}, 128), bytes.Repeat([]byte{seed}, 128), bytes.Repeat([]byte{seed}, 128),
+		bytes.Repeat([]byte{seed}, 20), bytes.Repeat([]byte{seed}, 20), bytes.Repeat([]byte{seed}, 20), bytes.Repeat([]byte{seed}, 20),
+	}
+	sig, _ := rlp.EncodeToBytes(raw)
+
+	packedKey, _ := vm.PackGroupKey(key)
+	packedSig, _ := vm.PackGroupSignature(sig)
+	input, _ := vm.GroupSignatureInput(packedKey, packedSig, []byte(message))
+	s.signed[string(input)] = true
+	return sig
+}
+
+// groupKey returns a fake encoded master public key.
+func groupKey(seed byte) []byte {
+	raw := make([][]byte, 9)
+	for i := range raw {
+		raw[i] = bytes.Repeat([]byte{seed + byte(i)}, 128)
+	}
+	key, _ := rlp.EncodeToBytes(raw)
+	return key
+}
+
+func TestGroupCheckpointRegister(t *testing.T) {
+	// Replace the group signature precompile for the duration of the test
+	signer := &groupSigner{signed: make(map[string]bool)}
+	precompile := common.BytesToAddress([]byte{19})
+	for _, precompiles := range []map[common.Address]vm.PrecompiledContract{vm.PrecompiledContractsIstanbul, vm.PrecompiledContractsYoloV2} {
+		defer func(precompiles map[common.Address]vm.PrecompiledContract, orig vm.PrecompiledContract) {
+			precompiles[precompile] = orig
+		}(precompiles, precompiles[precompile])
+		precompiles[precompile] = signer
+	}
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{addr: {Balance: big.NewInt(1000000000000000000)}}, 10000000)
+	defer backend.Close()
+
+	opts, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+
+	committee := groupKey(1)
+	oracle, _, err := DeployGroupCheckpointOracle(opts, backend, sectionSize.Uint64(), processConfirms.Uint64(), committee)
+	if err != nil {
+		t.Fatalf("Failed to deploy group oracle: %v", err)
+	}
+	backend.Commit()
+
+	size, confirms, keyHash, err := oracle.Contract().GetConfig(nil)
+	if err != nil {
+		t.Fatalf("Failed to retrieve config: %v", err)
+	}
+	packed, _ := vm.PackGroupKey(committee)
+	if size.Cmp(sectionSize) != 0 || confirms.Cmp(processConfirms) != 0 || keyHash != crypto.Keccak256Hash(packed) {
+		t.Fatalf("Config mismatch: have %v/%v/%x", size, confirms, keyHash)
+	}
+	// The oracle can't be reinitialized with another committee
+	opts.GasLimit = 1000000
+	if _, err := oracle.Contract().Initialize(opts, sectionSize, processConfirms, packed); err != nil {
+		t.Fatalf("Failed to send initialization: %v", err)
+	}
+	backend.Commit()
+	if receipt, _ := backend.TransactionReceipt(nil, backend.Blockchain().CurrentBlock().Transactions()[0].Hash()); receipt.Status != 0 {
+		t.Errorf("Reinitialized oracle")
+	}
+	// getRecent returns block height and hash of the head parent.
+	getRecent := func() (*big.Int, common.Hash) {
+		header := backend.Blockchain().CurrentHeader()
+		return new(big.Int).Sub(header.Number, big.NewInt(1)), header.ParentHash
+	}
+	// register submits a checkpoint, returning the receipt status of the
+	// transaction once mined.
+	register := func(index uint64, hash common.Hash, key []byte, sig []byte, recentHash *common.Hash) uint64 {
+		number, recent := getRecent()
+		if recentHash != nil {
+			recent = *recentHash
+		}
+		tx, err := oracle.RegisterCheckpoint(opts, index, hash.Bytes(), number, recent, key, sig)
+		if err != nil {
+			t.Fatalf("Failed to send checkpoint: %v", err)
+		}
+		backend.Commit()
+		receipt, _ := backend.TransactionReceipt(nil, tx.Hash())
+		return receipt.Status
+	}
+	// assert checks whether the current contract status is same with the
+	// expected.
+	assert := func(index uint64, hash common.Hash, height uint64, name string) {
+		lindex, lhash, lheight, err := oracle.Contract().GetLatestCheckpoint(nil)
+		if err != nil {
+			t.Fatalf("%s: failed to retrieve latest checkpoint: %v", name, err)
+		}
+		if lindex != index || lhash != hash || lheight.Uint64() != height {
+			t.Errorf("%s: latest checkpoint mismatch: have %d/%x/%d, want %d/%x/%d", name, lindex, lhash, lheight, index, hash, height)
+		}
+	}
+	message := func(index uint64, hash common.Hash) string {
+		return GroupMessage(oracle.ContractAddr(), index, hash)
+	}
+	sig0 := signer.sign(committee, message(0, checkpoint0.Hash()), 1)
+
+	// Future checkpoints are ignored
+	register(0, checkpoint0.Hash(), committee, sig0, nil)
+	assert(0, common.Hash{}, 0, "future checkpoint")
+
+	for i := uint64(0); i < sectionSize.Uint64()+processConfirms.Uint64(); i++ {
+		backend.Commit()
+	}
+	// Replayed, foreign and unsigned checkpoints are refused
+	deadbeef := common.HexToHash("deadbeef")
+	if status := register(0, checkpoint0.Hash(), committee, sig0, &deadbeef); status != 0 {
+		t.Errorf("replay protection: checkpoint accepted")
+	}
+	other := groupKey(2)
+	if status := register(0, checkpoint0.Hash(), other, signer.sign(other, message(0, checkpoint0.Hash()), 1), nil); status != 0 {
+		t.Errorf("foreign committee: checkpoint accepted")
+	}
+	if status := register(0, checkpoint0.Hash(), committee, signer.sign(committee, message(0, checkpoint1.Hash()), 2), nil); status != 0 {
+		t.Errorf("signature of another checkpoint: checkpoint accepted")
+	}
+	assert(0, common.Hash{}, 0, "refused checkpoints")
+
+	// Signed checkpoints are registered, announcing the signature
+	if status := register(0, checkpoint0.Hash(), committee, sig0, nil); status != 1 {
+		t.Fatalf("valid checkpoint: checkpoint refused")
+	}
+	head := backend.Blockchain().CurrentBlock()
+	assert(0, checkpoint0.Hash(), head.NumberU64(), "valid checkpoint")
+
+	logs := backend.Blockchain().GetReceiptsByHash(head.Hash())[0].Logs
+	events := oracle.LookupCheckpointEvents([][]*types.Log{logs}, 0, checkpoint0.Hash())
+	if len(events) != 1 {
+		t.Fatalf("checkpoint event count mismatch: have %d, want 1", len(events))
+	}
+	if packedSig, _ := vm.PackGroupSignature(sig0); !bytes.Equal(events[0].Signature, packedSig) {
+		t.Errorf("announced signature mismatch: have %x, want %x", events[0].Signature, packedSig)
+	}
+	// Stale and old checkpoints are ignored
+	register(0, checkpoint0.Hash(), committee, sig0, nil)
+	assert(0, checkpoint0.Hash(), head.NumberU64(), "stale checkpoint")
+
+	for backend.Blockchain().CurrentHeader().Number.Uint64() < 3*sectionSize.Uint64()+processConfirms.Uint64() {
+		backend.Commit()
+	}
+	if status := register(2, checkpoint2.Hash(), committee, signer.sign(committee, message(2, checkpoint2.Hash()), 3), nil); status != 1 {
+		t.Fatalf("uncontinuous checkpoint: checkpoint refused")
+	}
+	height := backend.Blockchain().CurrentHeader().Number.Uint64()
+
+	register(1, checkpoint1.Hash(), committee, signer.sign(committee, message(1, checkpoint1.Hash()), 4), nil)
+	assert(2, checkpoint2.Hash(), height, "old checkpoint")
+}
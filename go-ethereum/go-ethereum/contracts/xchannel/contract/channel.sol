@@ -0,0 +1,321 @@
+pragma solidity ^0.8.0;
+
+/**
+ * @title XChannel
+ * @author Cross-Channel developers
+ * @dev Two party payment channels with hash-locked transfers.
+ *
+ * Channel states are signed off-chain by both parties as EIP-712 typed data and
+ * only brought on-chain to close the channel, either cooperatively or through a
+ * dispute in which the most recent state wins. Transfers still pending in the
+ * final state are resolved through the secrets revealed before they expired.
+ *
+ * Virtual channels between two parties that both have a channel with the same
+ * hub are funded off-chain, by locks of the ledger channels with the hub that
+ * allocate their amount to the virtual channel. The virtual channel is only
+ * ever brought on-chain to determine how the allocations are split, either on
+ * final balances signed by both parties or through a dispute on its states.
+ */
+contract XChannel {
+    struct Channel {
+        address partyA;    // Party that opened the channel
+        address partyB;    // Counterparty of the channel
+        uint256 depositA;  // Total amount deposited by partyA
+        uint256 depositB;  // Total amount deposited by partyB
+        uint256 challenge; // Length of the dispute window in blocks
+        uint256 status;    // 0: unknown, 1: open, 2: disputed, 3: closed
+        uint256 nonce;     // Nonce of the disputed state
+        uint256 balanceA;  // Balance of partyA in the disputed state
+        uint256 balanceB;  // Balance of partyB in the disputed state
+        bytes32 locksRoot; // Hash of the pending transfers of the disputed state
+        uint256 deadline;  // Block number the dispute window closes at
+    }
+
+    struct Virtual {
+        uint256 status;    // 0: unknown, 2: disputed, 3: closed
+        uint256 nonce;     // Nonce of the disputed state
+        uint256 balanceA;  // Balance of partyA in the disputed state
+        uint256 balanceB;  // Balance of partyB in the disputed state
+        uint256 deadline;  // Block number the dispute window closes at
+    }
+
+    // Pending transfer of a state, refunded to its payer unless the preimage of
+    // its hashlock is revealed by the expiration block. Locks expiring at block
+    // 0 allocate their amount to the virtual channel whose id is the hashlock,
+    // the payer standing in for partyA of the virtual channel.
+    struct Lock {
+        uint256 amount;
+        uint256 expiration;
+        bytes32 hashlock;
+        address payer;
+    }
+
+    bytes32 constant DOMAIN_TYPEHASH = keccak256("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)");
+    bytes32 constant STATE_TYPEHASH = keccak256("State(bytes32 channel,uint256 nonce,uint256 balanceA,uint256 balanceB,bytes32 locksRoot)");
+    bytes32 constant CLOSE_TYPEHASH = keccak256("Close(bytes32 channel,uint256 nonce,uint256 balanceA,uint256 balanceB)");
+
+    mapping(bytes32 => Channel) public channels;
+    mapping(bytes32 => uint256) public secrets; // Block numbers hashlock preimages were revealed at
+    uint256 public counter;
+    mapping(bytes32 => Virtual) public virtuals;
+
+    event Opened(bytes32 indexed id, address indexed partyA, address indexed partyB, uint256 deposit, uint256 challenge);
+    event Deposited(bytes32 indexed id, address indexed party, uint256 total);
+    event Disputed(bytes32 indexed id, uint256 nonce, uint256 deadline);
+    event Closed(bytes32 indexed id, uint256 balanceA, uint256 balanceB);
+    event SecretRevealed(bytes32 indexed hashlock, bytes32 secret);
+    event VirtualDisputed(bytes32 indexed id, uint256 nonce, uint256 deadline);
+    event VirtualClosed(bytes32 indexed id, uint256 balanceA, uint256 balanceB);
+
+    /**
+     * @dev Opens a channel with partyB, funded with the value sent.
+     * @param partyB counterparty of the channel
+     * @param challenge length of the dispute window in blocks
+     * @return id identifier of the new channel
+     */
+    function open(address partyB, uint256 challenge) external payable returns (bytes32 id) {
+        require(partyB != address(0) && partyB != msg.sender);
+        require(challenge < 1 << 64);
+
+        id = keccak256(abi.encode(address(this), msg.sender, partyB, counter++));
+
+        Channel storage c = channels[id];
+        c.partyA = msg.sender;
+        c.partyB = partyB;
+        c.depositA = msg.value;
+        c.challenge = challenge;
+        c.status = 1;
+
+        emit Opened(id, msg.sender, partyB, msg.value, challenge);
+    }
+
+    /**
+     * @dev Adds the value sent to the deposit of partyB. Deposits made after a
+     * state was signed are credited to partyB when settling on that state.
+     */
+    function deposit(bytes32 id) external payable {
+        Channel storage c = channels[id];
+        require(c.status == 1 && msg.sender == c.partyB);
+
+        c.depositB += msg.value;
+        require(c.depositB >= msg.value);
+
+        emit Deposited(id, msg.sender, c.depositB);
+    }
+
+    /**
+     * @dev Closes a channel immediately on final balances signed by both parties.
+     * Only states without pending transfers can be closed on.
+     */
+    function close(bytes32 id, uint256 nonce, uint256 balanceA, uint256 balanceB, bytes calldata sigA, bytes calldata sigB) external {
+        Channel storage c = channels[id];
+        require(c.status == 1 || c.status == 2);
+        require(balanceA + balanceB >= balanceB && balanceA + balanceB == c.depositA + c.depositB);
+
+        bytes32 digest = hashTypedData(keccak256(abi.encode(CLOSE_TYPEHASH, id, nonce, balanceA, balanceB)));
+        require(recover(digest, sigA) == c.partyA);
+        require(recover(digest, sigB) == c.partyB);
+
+        c.status = 3;
+        pay(c.partyA, balanceA);
+        pay(c.partyB, balanceB);
+
+        emit Closed(id, balanceA, balanceB);
+    }
+
+    /**
+     * @dev Starts or continues a dispute on a state signed by both parties. The
+     * first dispute opens the dispute window, within which newer states replace
+     * older ones. The opening state of the channel, nonce 0 with the balances
+     * equal to the deposits, needs no signatures but may only be disputed by the
+     * parties themselves.
+     */
+    function dispute(bytes32 id, uint256 nonce, uint256 balanceA, uint256 balanceB, bytes32 locksRoot, bytes calldata sigA, bytes calldata sigB) external {
+        Channel storage c = channels[id];
+        require(balanceA + balanceB >= balanceB && balanceA + balanceB <= c.depositA + c.depositB);
+
+        if (c.status == 1) {
+            c.status = 2;
+            c.deadline = block.number + c.challenge;
+        } else {
+            require(c.status == 2 && block.number < c.deadline && nonce > c.nonce);
+        }
+        if (nonce == 0) {
+            require(msg.sender == c.partyA || msg.sender == c.partyB);
+            require(balanceA == c.depositA && balanceB == c.depositB && locksRoot == 0);
+        } else {
+            bytes32 digest = hashTypedData(keccak256(abi.encode(STATE_TYPEHASH, id, nonce, balanceA, balanceB, locksRoot)));
+            require(recover(digest, sigA) == c.partyA);
+            require(recover(digest, sigB) == c.partyB);
+        }
+        c.nonce = nonce;
+        c.balanceA = balanceA;
+        c.balanceB = balanceB;
+        c.locksRoot = locksRoot;
+
+        emit Disputed(id, nonce, c.deadline);
+    }
+
+    /**
+     * @dev Pays out a disputed channel once its dispute window closed. The locks
+     * are the abi encoded pending transfers of the disputed state, 128 bytes each,
+     * hashing to its locks root. Transfers whose secret was revealed in time go
+     * to the party being paid, the others are refunded after they expired.
+     * Allocations to virtual channels wait for the virtual channel to be final.
+     */
+    function settle(bytes32 id, bytes calldata locks) external {
+        Channel storage c = channels[id];
+        require(c.status == 2 && block.number >= c.deadline);
+        require(locks.length % 128 == 0 && locks.length < 1 << 32);
+        if (locks.length == 0) {
+            require(c.locksRoot == 0);
+        } else {
+            require(keccak256(locks) == c.locksRoot);
+        }
+        uint256 balanceA = c.balanceA;
+        uint256 balanceB = c.balanceB;
+        uint256 locked;
+
+        for (uint256 i = 0; i < locks.length; i += 128) {
+            Lock memory l = abi.decode(locks[i:i + 128], (Lock));
+
+            locked += l.amount;
+            require(locked >= l.amount);
+
+            bool payerA = l.payer == c.partyA;
+            require(payerA || l.payer == c.partyB);
+
+            if (l.expiration == 0) {
+                Virtual storage v = virtuals[l.hashlock];
+                require(v.status == 3 || (v.status == 2 && block.number >= v.deadline));
+                require(v.balanceA + v.balanceB == l.amount);
+
+                (uint256 toA, uint256 toB) = payerA ? (v.balanceA, v.balanceB) : (v.balanceB, v.balanceA);
+                balanceA += toA;
+                require(balanceA >= toA);
+                balanceB += toB;
+                require(balanceB >= toB);
+                continue;
+            }
+            uint256 revealed = secrets[l.hashlock];
+            bool paid = revealed != 0 && revealed <= l.expiration;
+            if (!paid) {
+                require(block.number > l.expiration);
+            }
+            if (payerA != paid) {
+                balanceA += l.amount;
+                require(balanceA >= l.amount);
+            } else {
+                balanceB += l.amount;
+                require(balanceB >= l.amount);
+            }
+        }
+        uint256 total = c.depositA + c.depositB;
+        uint256 claimed = c.balanceA + c.balanceB + locked;
+        require(claimed >= locked && claimed <= total);
+        balanceB += total - claimed;
+
+        c.status = 3;
+        pay(c.partyA, balanceA);
+        pay(c.partyB, balanceB);
+
+        emit Closed(id, balanceA, balanceB);
+    }
+
+    /**
+     * @dev Reveals the preimage of a hashlock, recording the current block for
+     * the transfers locked with it to be resolved against their expiration.
+     */
+    function reveal(bytes32 secret) external {
+        bytes32 hashlock = keccak256(abi.encodePacked(secret));
+        if (secrets[hashlock] == 0) {
+            secrets[hashlock] = block.number;
+            emit SecretRevealed(hashlock, secret);
+        }
+    }
+
+    /**
+     * @dev Starts or continues a dispute on a state of a virtual channel signed
+     * by both its parties, like dispute does for channels. The balances must add
+     * up to the total the virtual channel was funded with, which along with the
+     * parties, the challenge and a salt make up its id. Virtual channel states
+     * have no pending transfers.
+     */
+    function disputeVirtual(address partyA, address partyB, uint256 total, uint256 challenge, bytes32 salt, uint256 nonce, uint256 balanceA, uint256 balanceB, bytes calldata sigA, bytes calldata sigB) external {
+        require(challenge < 1 << 64);
+        require(balanceA + balanceB >= balanceB && balanceA + balanceB == total);
+
+        bytes32 id = keccak256(abi.encode(address(this), partyA, partyB, total, challenge, salt));
+        Virtual storage v = virtuals[id];
+        if (v.status == 0) {
+            v.status = 2;
+            v.deadline = block.number + challenge;
+        } else {
+            require(v.status == 2 && block.number < v.deadline && nonce > v.nonce);
+        }
+        bytes32 digest = hashTypedData(keccak256(abi.encode(STATE_TYPEHASH, id, nonce, balanceA, balanceB, bytes32(0))));
+        require(recover(digest, sigA) == partyA);
+        require(recover(digest, sigB) == partyB);
+
+        v.nonce = nonce;
+        v.balanceA = balanceA;
+        v.balanceB = balanceB;
+
+        emit VirtualDisputed(id, nonce, v.deadline);
+    }
+
+    /**
+     * @dev Finalises a virtual channel immediately on final balances signed by
+     * both its parties, unless a dispute on it already ended.
+     */
+    function closeVirtual(address partyA, address partyB, uint256 total, uint256 challenge, bytes32 salt, uint256 nonce, uint256 balanceA, uint256 balanceB, bytes calldata sigA, bytes calldata sigB) external {
+        require(balanceA + balanceB >= balanceB && balanceA + balanceB == total);
+
+        bytes32 id = keccak256(abi.encode(address(this), partyA, partyB, total, challenge, salt));
+        Virtual storage v = virtuals[id];
+        require(v.status == 0 || (v.status == 2 && block.number < v.deadline));
+
+        bytes32 digest = hashTypedData(keccak256(abi.encode(CLOSE_TYPEHASH, id, nonce, balanceA, balanceB)));
+        require(recover(digest, sigA) == partyA);
+        require(recover(digest, sigB) == partyB);
+
+        v.status = 3;
+        v.deadline = block.number;
+        v.nonce = nonce;
+        v.balanceA = balanceA;
+        v.balanceB = balanceB;
+
+        emit VirtualClosed(id, balanceA, balanceB);
+    }
+
+    function hashTypedData(bytes32 structHash) internal view returns (bytes32) {
+        uint256 chainId;
+        assembly { chainId := chainid() }
+
+        bytes32 domain = keccak256(abi.encode(DOMAIN_TYPEHASH, keccak256("XChannel"), keccak256("1"), chainId, address(this)));
+        return keccak256(abi.encodePacked("\x19\x01", domain, structHash));
+    }
+
+    function recover(bytes32 digest, bytes memory sig) internal pure returns (address) {
+        require(sig.length == 65);
+
+        bytes32 r;
+        bytes32 s;
+        uint8 v;
+        assembly {
+            r := mload(add(sig, 32))
+            s := mload(add(sig, 64))
+            v := byte(0, mload(add(sig, 96)))
+        }
+        address signer = ecrecover(digest, v, r, s);
+        require(signer != address(0));
+        return signer;
+    }
+
+    function pay(address to, uint256 amount) internal {
+        if (amount > 0) {
+            (bool ok, ) = to.call{value: amount}("");
+            require(ok);
+        }
+    }
+}
@@ -0,0 +1,89 @@
+pragma solidity ^0.8.0;
+
+/**
+ * @title HTLC
+ * @author Cross-Channel developers
+ * @dev Hashed timelock contracts for atomic swaps across chains.
+ *
+ * A swap locks the value sent for a recipient, who can claim it by revealing
+ * the preimage of its hashlock before the timelock, after which the sender can
+ * have it refunded. Claims and refunds can be submitted by anyone, the funds
+ * only ever go to the recipient or the sender respectively.
+ */
+contract HTLC {
+    struct Swap {
+        address sender;    // Account that locked the funds
+        address recipient; // Account the funds can be claimed for
+        uint256 amount;    // Amount locked
+        bytes32 hashlock;  // Hash of the secret unlocking the funds
+        uint256 timelock;  // Timestamp from which the funds can be refunded
+        uint256 status;    // 0: unknown, 1: locked, 2: claimed, 3: refunded
+    }
+
+    mapping(bytes32 => Swap) public swaps;
+
+    event Locked(bytes32 indexed id, address indexed sender, address indexed recipient, uint256 amount, bytes32 hashlock, uint256 timelock);
+    event Claimed(bytes32 indexed id, bytes32 secret);
+    event Refunded(bytes32 indexed id);
+
+    /**
+     * @dev Locks the value sent for a recipient. The id of the swap derives from
+     * its terms, so both parties can compute it in advance.
+     * @param recipient account the funds can be claimed for
+     * @param hashlock keccak256 hash of the secret unlocking the funds
+     * @param timelock timestamp from which the funds can be refunded
+     * @return id identifier of the new swap
+     */
+    function lock(address recipient, bytes32 hashlock, uint256 timelock) external payable returns (bytes32 id) {
+        require(recipient != address(0) && msg.value > 0 && timelock > block.timestamp);
+
+        id = keccak256(abi.encode(address(this), msg.sender, recipient, hashlock, timelock));
+
+        Swap storage s = swaps[id];
+        require(s.status == 0);
+
+        s.sender = msg.sender;
+        s.recipient = recipient;
+        s.amount = msg.value;
+        s.hashlock = hashlock;
+        s.timelock = timelock;
+        s.status = 1;
+
+        emit Locked(id, msg.sender, recipient, msg.value, hashlock, timelock);
+    }
+
+    /**
+     * @dev Pays the funds of a swap out to its recipient, revealing the secret
+     * unlocking them. Only possible before the timelock.
+     */
+    function claim(bytes32 id, bytes32 secret) external {
+        Swap storage s = swaps[id];
+        require(s.status == 1 && block.timestamp < s.timelock);
+        require(keccak256(abi.encodePacked(secret)) == s.hashlock);
+
+        s.status = 2;
+        emit Claimed(id, secret);
+
+        pay(s.recipient, s.amount);
+    }
+
+    /**
+     * @dev Refunds the funds of a swap to its sender once the timelock passed.
+     */
+    function refund(bytes32 id) external {
+        Swap storage s = swaps[id];
+        require(s.status == 1 && block.timestamp >= s.timelock);
+
+        s.status = 3;
+        emit Refunded(id);
+
+        pay(s.sender, s.amount);
+    }
+
+    function pay(address to, uint256 amount) internal {
+        if (amount > 0) {
+            (bool ok, ) = to.call{value: amount}("");
+            require(ok);
+        }
+    }
+}
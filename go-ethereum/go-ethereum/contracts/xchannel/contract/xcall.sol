@@ -0,0 +1,114 @@
+pragma solidity ^0.8.0;
+
+/**
+ * @title XCall
+ * @author Cross-Channel developers
+ * @dev Locked calls for atomic execution across chains, committed in two phases.
+ *
+ * A coordinator prepares a call on every chain involved in a transaction,
+ * locking the value to send along with it, then votes for it. Once all of them
+ * are voted for, it commits them, executing the calls. Otherwise it aborts them,
+ * getting the value back.
+ *
+ * The deadline of a call only bounds its preparation: past it, a call not voted
+ * for can no longer be, and anyone can abort it, so the value locked is never
+ * stuck with a coordinator failing before deciding. A call voted for waits for
+ * the decision of the coordinator, committing it or aborting it at any time, so
+ * a decision to commit can't be undone by the deadline passing on one chain.
+ *
+ * Calls are scoped by coordinator: the id of a call derives from the contract,
+ * the coordinator and the id of the transaction it is part of.
+ */
+contract XCall {
+    struct Call {
+        address coordinator; // Account that prepared the call
+        address target;      // Contract to call
+        uint256 value;       // Value locked, sent along with the call
+        bytes32 dataHash;    // Hash of the calldata
+        uint256 deadline;    // Timestamp from which the call can't be voted for
+        uint256 status;      // 0: unknown, 1: prepared, 2: committed, 3: aborted, 4: voted
+    }
+
+    mapping(bytes32 => Call) public calls;
+
+    event Prepared(bytes32 indexed id, address indexed coordinator, address indexed target, uint256 value, bytes32 dataHash, uint256 deadline);
+    event Voted(bytes32 indexed id);
+    event Committed(bytes32 indexed id);
+    event Aborted(bytes32 indexed id);
+
+    /**
+     * @dev Prepares a call as part of a transaction, locking the value sent.
+     * @param xid identifier of the transaction, unique to the coordinator
+     * @param target contract to call
+     * @param dataHash keccak256 hash of the calldata
+     * @param deadline timestamp from which the call can't be voted for
+     * @return id identifier of the call
+     */
+    function prepare(bytes32 xid, address target, bytes32 dataHash, uint256 deadline) external payable returns (bytes32 id) {
+        require(target != address(0) && deadline > block.timestamp);
+
+        id = keccak256(abi.encode(address(this), msg.sender, xid));
+
+        Call storage c = calls[id];
+        require(c.status == 0);
+
+        c.coordinator = msg.sender;
+        c.target = target;
+        c.value = msg.value;
+        c.dataHash = dataHash;
+        c.deadline = deadline;
+        c.status = 1;
+
+        emit Prepared(id, msg.sender, target, msg.value, dataHash, deadline);
+    }
+
+    /**
+     * @dev Votes for a prepared call, after which only the coordinator can settle
+     * it. Only the coordinator can vote, and only before the deadline.
+     */
+    function vote(bytes32 id) external {
+        Call storage c = calls[id];
+        require(c.status == 1 && msg.sender == c.coordinator && block.timestamp < c.deadline);
+
+        c.status = 4;
+        emit Voted(id);
+    }
+
+    /**
+     * @dev Executes a call voted for, reverting if the call fails. Only the
+     * coordinator can commit.
+     */
+    function commit(bytes32 id, bytes calldata data) external {
+        Call storage c = calls[id];
+        require(c.status == 4 && msg.sender == c.coordinator);
+        require(keccak256(data) == c.dataHash);
+
+        c.status = 2;
+        emit Committed(id);
+
+        (bool ok, ) = c.target.call{value: c.value}(data);
+        require(ok);
+    }
+
+    /**
+     * @dev Aborts a prepared or voted call, refunding the value locked to the
+     * coordinator. Anyone can abort a call not voted for once the deadline passed.
+     */
+    function abort(bytes32 id) external {
+        Call storage c = calls[id];
+        require(c.status == 1 || c.status == 4);
+        require(msg.sender == c.coordinator || (c.status == 1 && block.timestamp >= c.deadline));
+
+        c.status = 3;
+        emit Aborted(id);
+
+        pay(c.coordinator, c.value);
+    }
+
+    function pay(address to, uint256 amount) internal {
+        if (amount > 0) {
+            (bool ok, ) = to.call{value: amount}("");
+            require(ok);
+        }
+    }
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xchannel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+)
+
+// Version is the version of the channel and HTLC contracts. It is the EIP-712
+// domain version channel states are signed under as well, hardcoded in
+// contract/channel.sol, so bumping it invalidates every state already signed.
+const Version = "1"
+
+// XCallVersion is the version of the XCall contract, bumped on any change to its
+// bytecode. It is versioned apart from the other contracts, so changes to XCall
+// leave the domain of channel states alone.
+const XCallVersion = "2"
+
+var (
+	// ChannelCode is the runtime bytecode of the channel contract.
+	ChannelCode = runtimeCode(contract.XChannelBin)
+
+	// HTLCCode is the runtime bytecode of the HTLC contract.
+	HTLCCode = runtimeCode(contract.HTLCBin)
+
+	// XCallCode is the runtime bytecode of the XCall contract.
+	XCallCode = runtimeCode(contract.XCallBin)
+)
+
+var (
+	// ErrNoCode is returned if no contract is deployed at an address.
+	ErrNoCode = errors.New("no contract code at address")
+
+	// ErrCodeMismatch is returned if the contract deployed at an address is not
+	// the one of this version of the contract set.
+	ErrCodeMismatch = errors.New("contract code mismatch")
+)
+
+// runtimeCode returns the code a contract deploys, by running its deployment
+// code. None of the contracts has a constructor, so the code deployed doesn't
+// depend on the deployment.
+func runtimeCode(bin string) []byte {
+	code, _, _, err := runtime.Create(common.FromHex(bin), nil)
+	if err != nil {
+		panic(fmt.Sprintf("invalid contract deployment code: %v", err))
+	}
+	return code
+}
+
+// Deployment is the set of contracts deployed on a chain.
+type Deployment struct {
+	Channel common.Address `json:"channel"`
+	HTLC    common.Address `json:"htlc"`
+	XCall   common.Address `json:"xcall"`
+}
+
+// Deploy sends the transactions deploying the contract set from the account of
+// the given options, returning the addresses the contracts are created at once
+// the transactions are mined.
+func Deploy(opts *bind.TransactOpts, backend bind.ContractBackend) (*Deployment, []*types.Transaction, error) {
+	var (
+		d   = new(Deployment)
+		txs = make([]*types.Transaction, 3)
+		err error
+	)
+	if d.Channel, txs[0], _, err = contract.DeployXChannel(opts, backend); err != nil {
+		return nil, nil, err
+	}
+	// Chain the nonces explicitly, the pending nonce of the backend might not
+	// account for the transactions just sent yet
+	if d.HTLC, txs[1], _, err = contract.DeployHTLC(copyOpts(opts, txs[0].Nonce()+1), backend); err != nil {
+		return nil, nil, err
+	}
+	if d.XCall, txs[2], _, err = contract.DeployXCall(copyOpts(opts, txs[1].Nonce()+1), backend); err != nil {
+		return nil, nil, err
+	}
+	return d, txs, nil
+}
+
+// copyOpts returns a copy of transaction options sending at the given nonce.
+func copyOpts(opts *bind.TransactOpts, nonce uint64) *bind.TransactOpts {
+	cpy := *opts
+	cpy.Nonce = new(big.Int).SetUint64(nonce)
+	return &cpy
+}
+
+// Check verifies that the contracts of a deployment are those of this version of
+// the contract set.
+func (d *Deployment) Check(ctx context.Context, backend bind.ContractCaller) error {
+	for _, c := range []struct {
+		name    string
+		address common.Address
+		code    []byte
+	}{
+		{"channel", d.Channel, ChannelCode},
+		{"HTLC", d.HTLC, HTLCCode},
+		{"XCall", d.XCall, XCallCode},
+	} {
+		if err := CheckCode(ctx, backend, c.address, c.code); err != nil {
+			return fmt.Errorf("%s contract: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+// CheckCode verifies that the code deployed at an address is the given runtime
+// code, one of ChannelCode, HTLCCode or XCallCode.
+func CheckCode(ctx context.Context, backend bind.ContractCaller, address common.Address, code []byte) error {
+	deployed, err := backend.CodeAt(ctx, address, nil)
+	if err != nil {
+		return err
+	}
+	if len(deployed) == 0 {
+		return ErrNoCode
+	}
+	if !bytes.Equal(deployed, code) {
+		return ErrCodeMismatch
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xchannel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDeploy(t *testing.T) {
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		addrA: {Balance: ether(100)},
+	}, 10000000)
+	defer sim.Close()
+
+	d, txs, err := Deploy(transactor(keyA, nil), sim)
+	if err != nil {
+		t.Fatalf("failed to deploy contracts: %v", err)
+	}
+	sim.Commit()
+
+	for i, tx := range txs {
+		receipt, _ := sim.TransactionReceipt(context.Background(), tx.Hash())
+		if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+			t.Fatalf("deployment %d failed", i)
+		}
+	}
+	if err := d.Check(context.Background(), sim); err != nil {
+		t.Fatalf("deployment check failed: %v", err)
+	}
+	// Contracts at the wrong addresses, or none at all, must be detected
+	swapped := &Deployment{Channel: d.HTLC, HTLC: d.Channel, XCall: d.XCall}
+	if err := swapped.Check(context.Background(), sim); !errors.Is(err, ErrCodeMismatch) {
+		t.Fatalf("swapped deployment error mismatch: have %v, want %v", err, ErrCodeMismatch)
+	}
+	if err := CheckCode(context.Background(), sim, common.Address{0xff}, XCallCode); err != ErrNoCode {
+		t.Fatalf("missing contract error mismatch: have %v, want %v", err, ErrNoCode)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xchannel
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core"
+)
+
+// htlcTester is an HTLC contract deployed on a simulated chain, with a swap
+// locked by A for B.
+type htlcTester struct {
+	*tester
+	htlc     *contract.HTLC
+	address  common.Address
+	secret   common.Hash
+	timelock uint64
+	id       common.Hash
+}
+
+func newHTLCTester(t *testing.T, amount *big.Int) *htlcTester {
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		addrA: {Balance: ether(100)},
+		addrB: {Balance: ether(100)},
+	}, 10000000)
+
+	address, _, htlc, err := contract.DeployHTLC(transactor(keyA, nil), sim)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	sim.Commit()
+
+	tt := &htlcTester{
+		tester:   &tester{t: t, sim: sim},
+		htlc:     htlc,
+		address:  address,
+		secret:   common.HexToHash("0x5ec4e7"),
+		timelock: sim.Blockchain().CurrentHeader().Time + 100,
+	}
+	// Locking needs funds, a recipient and a timelock in the future
+	hashlock := Hashlock(tt.secret)
+	tt.reject(htlc.Lock(transactor(keyA, nil), addrB, hashlock, new(big.Int).SetUint64(tt.timelock)))
+	tt.reject(htlc.Lock(transactor(keyA, amount), common.Address{}, hashlock, new(big.Int).SetUint64(tt.timelock)))
+	tt.reject(htlc.Lock(transactor(keyA, amount), addrB, hashlock, big.NewInt(1)))
+
+	tt.send(htlc.Lock(transactor(keyA, amount), addrB, hashlock, new(big.Int).SetUint64(tt.timelock)))
+	tt.id = SwapID(address, addrA, addrB, hashlock, tt.timelock)
+
+	// The same terms can't be locked twice
+	tt.reject(htlc.Lock(transactor(keyA, amount), addrB, hashlock, new(big.Int).SetUint64(tt.timelock)))
+	return tt
+}
+
+// swap returns the status and amount of the swap on-chain.
+func (tt *htlcTester) swap() (uint64, *big.Int) {
+	swap, err := tt.htlc.Swaps(nil, tt.id)
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve swap: %v", err)
+	}
+	if swap.Sender != addrA || swap.Recipient != addrB || swap.Hashlock != Hashlock(tt.secret) || swap.Timelock.Uint64() != tt.timelock {
+		tt.t.Fatalf("swap terms mismatch: %+v", swap)
+	}
+	return swap.Status.Uint64(), swap.Amount
+}
+
+func TestHTLCClaim(t *testing.T) {
+	tt := newHTLCTester(t, ether(3))
+	if status, amount := tt.swap(); status != SwapLocked || amount.Cmp(ether(3)) != 0 {
+		t.Fatalf("swap mismatch: status %d, amount %v", status, amount)
+	}
+	// Only the preimage of the hashlock claims the funds, whoever submits it
+	tt.reject(tt.htlc.Claim(transactor(keyA, nil), tt.id, common.HexToHash("0xdeadbeef")))
+	tt.reject(tt.htlc.Refund(transactor(keyA, nil), tt.id))
+
+	before := tt.balance(addrB)
+	tx, err := tt.htlc.Claim(transactor(keyA, nil), tt.id, tt.secret)
+	tt.send(tx, err)
+
+	if have := new(big.Int).Sub(tt.balance(addrB), before); have.Cmp(ether(3)) != 0 {
+		t.Fatalf("recipient payout mismatch: have %v, want %v", have, ether(3))
+	}
+	if status, _ := tt.swap(); status != SwapClaimed {
+		t.Fatalf("status mismatch: have %d, want %d", status, SwapClaimed)
+	}
+	receipt, _ := tt.sim.TransactionReceipt(context.Background(), tx.Hash())
+	event, err := tt.htlc.ParseClaimed(*receipt.Logs[0])
+	if err != nil || event.Id != tt.id || event.Secret != tt.secret {
+		t.Fatalf("claim event mismatch: %+v, %v", event, err)
+	}
+	// Claimed funds are gone for good
+	tt.reject(tt.htlc.Claim(transactor(keyB, nil), tt.id, tt.secret))
+	tt.sim.AdjustTime(200 * time.Second)
+	tt.reject(tt.htlc.Refund(transactor(keyA, nil), tt.id))
+}
+
+func TestHTLCRefund(t *testing.T) {
+	tt := newHTLCTester(t, ether(3))
+
+	// Past the timelock the funds can't be claimed, only refunded to the sender
+	tt.sim.AdjustTime(200 * time.Second)
+	tt.sim.Commit()
+	tt.reject(tt.htlc.Claim(transactor(keyB, nil), tt.id, tt.secret))
+
+	before := tt.balance(addrA)
+	tt.send(tt.htlc.Refund(transactor(keyB, nil), tt.id))
+
+	if have := new(big.Int).Sub(tt.balance(addrA), before); have.Cmp(ether(3)) != 0 {
+		t.Fatalf("sender refund mismatch: have %v, want %v", have, ether(3))
+	}
+	if status, _ := tt.swap(); status != SwapRefunded {
+		t.Fatalf("status mismatch: have %d, want %d", status, SwapRefunded)
+	}
+	tt.reject(tt.htlc.Refund(transactor(keyB, nil), tt.id))
+}
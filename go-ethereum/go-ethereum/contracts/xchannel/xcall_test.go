@@ -0,0 +1,207 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xchannel
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// xcallTester is an XCall contract deployed on a simulated chain, with a call
+// prepared by A. The call locks funds in an HTLC contract, for B.
+type xcallTester struct {
+	*tester
+	xcall    *contract.XCall
+	address  common.Address
+	htlc     *contract.HTLC
+	target   common.Address
+	data     []byte
+	deadline uint64
+	id       common.Hash
+}
+
+func newXCallTester(t *testing.T, data []byte) *xcallTester {
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		addrA: {Balance: ether(100)},
+		addrB: {Balance: ether(100)},
+	}, 10000000)
+
+	address, _, xcall, err := contract.DeployXCall(transactor(keyA, nil), sim)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	target, _, htlc, err := contract.DeployHTLC(transactor(keyA, nil), sim)
+	if err != nil {
+		t.Fatalf("failed to deploy target: %v", err)
+	}
+	sim.Commit()
+
+	tt := &xcallTester{
+		tester:   &tester{t: t, sim: sim},
+		xcall:    xcall,
+		address:  address,
+		htlc:     htlc,
+		target:   target,
+		deadline: sim.Blockchain().CurrentHeader().Time + 100,
+	}
+	if tt.data = data; tt.data == nil {
+		parsed, _ := abi.JSON(strings.NewReader(contract.HTLCABI))
+		tt.data, _ = parsed.Pack("lock", addrB, Hashlock(common.HexToHash("0x5ec4e7")), new(big.Int).SetUint64(tt.deadline+100))
+	}
+	// Preparing needs a target and a deadline in the future
+	xid := common.HexToHash("0x01")
+	hash := crypto.Keccak256Hash(tt.data)
+	tt.reject(xcall.Prepare(transactor(keyA, ether(3)), xid, common.Address{}, hash, new(big.Int).SetUint64(tt.deadline)))
+	tt.reject(xcall.Prepare(transactor(keyA, ether(3)), xid, target, hash, big.NewInt(1)))
+
+	tt.send(xcall.Prepare(transactor(keyA, ether(3)), xid, target, hash, new(big.Int).SetUint64(tt.deadline)))
+	tt.id = CallID(address, addrA, xid)
+
+	// A transaction can't prepare a call twice, but another coordinator has its own
+	tt.reject(xcall.Prepare(transactor(keyA, ether(3)), xid, target, hash, new(big.Int).SetUint64(tt.deadline)))
+	tt.send(xcall.Prepare(transactor(keyB, nil), xid, target, hash, new(big.Int).SetUint64(tt.deadline)))
+	return tt
+}
+
+// call returns the status of the call on-chain.
+func (tt *xcallTester) call() uint64 {
+	call, err := tt.xcall.Calls(nil, tt.id)
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve call: %v", err)
+	}
+	if call.Coordinator != addrA || call.Target != tt.target || call.Value.Cmp(ether(3)) != 0 || call.DataHash != crypto.Keccak256Hash(tt.data) || call.Deadline.Uint64() != tt.deadline {
+		tt.t.Fatalf("call mismatch: %+v", call)
+	}
+	return call.Status.Uint64()
+}
+
+func TestXCallCommit(t *testing.T) {
+	tt := newXCallTester(t, nil)
+	if status := tt.call(); status != CallPrepared {
+		t.Fatalf("status mismatch: have %d, want %d", status, CallPrepared)
+	}
+	// Only the coordinator votes, and calls are only committed once voted for
+	tt.reject(tt.xcall.Commit(transactor(keyA, nil), tt.id, tt.data))
+	tt.reject(tt.xcall.Vote(transactor(keyB, nil), tt.id))
+	tt.send(tt.xcall.Vote(transactor(keyA, nil), tt.id))
+	tt.reject(tt.xcall.Vote(transactor(keyA, nil), tt.id))
+
+	if status := tt.call(); status != CallVoted {
+		t.Fatalf("status mismatch: have %d, want %d", status, CallVoted)
+	}
+	// Only the coordinator commits, and only the prepared calldata
+	tt.reject(tt.xcall.Commit(transactor(keyB, nil), tt.id, tt.data))
+	tt.reject(tt.xcall.Commit(transactor(keyA, nil), tt.id, append(tt.data, 0x00)))
+
+	tt.send(tt.xcall.Commit(transactor(keyA, nil), tt.id, tt.data))
+	if status := tt.call(); status != CallCommitted {
+		t.Fatalf("status mismatch: have %d, want %d", status, CallCommitted)
+	}
+	// The call went through with the value locked
+	swapID := SwapID(tt.target, tt.address, addrB, Hashlock(common.HexToHash("0x5ec4e7")), tt.deadline+100)
+	if swap, _ := tt.htlc.Swaps(nil, swapID); swap.Status.Uint64() != SwapLocked || swap.Amount.Cmp(ether(3)) != 0 {
+		t.Fatalf("target call mismatch: %+v", swap)
+	}
+	if balance := tt.balance(tt.address); balance.Sign() != 0 {
+		t.Fatalf("funds left in contract: %v", balance)
+	}
+	// Committed calls are final
+	tt.reject(tt.xcall.Commit(transactor(keyA, nil), tt.id, tt.data))
+	tt.reject(tt.xcall.Abort(transactor(keyA, nil), tt.id))
+}
+
+func TestXCallAbort(t *testing.T) {
+	tt := newXCallTester(t, nil)
+
+	// Before the deadline, only the coordinator aborts
+	tt.reject(tt.xcall.Abort(transactor(keyB, nil), tt.id))
+	tt.send(tt.xcall.Abort(transactor(keyA, nil), tt.id))
+
+	if status := tt.call(); status != CallAborted {
+		t.Fatalf("status mismatch: have %d, want %d", status, CallAborted)
+	}
+	if balance := tt.balance(tt.address); balance.Sign() != 0 {
+		t.Fatalf("funds left in contract: %v", balance)
+	}
+	tt.reject(tt.xcall.Vote(transactor(keyA, nil), tt.id))
+	tt.reject(tt.xcall.Commit(transactor(keyA, nil), tt.id, tt.data))
+	tt.reject(tt.xcall.Abort(transactor(keyA, nil), tt.id))
+}
+
+func TestXCallExpiry(t *testing.T) {
+	tt := newXCallTester(t, nil)
+
+	// Past the deadline the call can't be voted for, anyone can abort it
+	tt.sim.AdjustTime(200 * time.Second)
+	tt.sim.Commit()
+	tt.reject(tt.xcall.Vote(transactor(keyA, nil), tt.id))
+	tt.reject(tt.xcall.Commit(transactor(keyA, nil), tt.id, tt.data))
+
+	before := tt.balance(addrA)
+	tt.send(tt.xcall.Abort(transactor(keyB, nil), tt.id))
+
+	if have := new(big.Int).Sub(tt.balance(addrA), before); have.Cmp(ether(3)) != 0 {
+		t.Fatalf("coordinator refund mismatch: have %v, want %v", have, ether(3))
+	}
+	if status := tt.call(); status != CallAborted {
+		t.Fatalf("status mismatch: have %d, want %d", status, CallAborted)
+	}
+}
+
+func TestXCallVotedExpiry(t *testing.T) {
+	tt := newXCallTester(t, nil)
+	tt.send(tt.xcall.Vote(transactor(keyA, nil), tt.id))
+
+	// Past the deadline, a call voted for still awaits the coordinator: nobody
+	// else can abort it, the coordinator can still commit it
+	tt.sim.AdjustTime(100 * time.Second)
+	tt.sim.Commit()
+	if now := tt.sim.Blockchain().CurrentHeader().Time; now < tt.deadline {
+		t.Fatalf("deadline not reached: have %d, want %d", now, tt.deadline)
+	}
+	tt.reject(tt.xcall.Abort(transactor(keyB, nil), tt.id))
+
+	tt.send(tt.xcall.Commit(transactor(keyA, nil), tt.id, tt.data))
+	if status := tt.call(); status != CallCommitted {
+		t.Fatalf("status mismatch: have %d, want %d", status, CallCommitted)
+	}
+}
+
+func TestXCallFailingCall(t *testing.T) {
+	tt := newXCallTester(t, common.FromHex("0xdeadbeef"))
+
+	// A call reverting can't be committed, the call stays voted for until the
+	// coordinator aborts it
+	tt.send(tt.xcall.Vote(transactor(keyA, nil), tt.id))
+	tt.reject(tt.xcall.Commit(transactor(keyA, nil), tt.id, tt.data))
+	if status := tt.call(); status != CallVoted {
+		t.Fatalf("status mismatch: have %d, want %d", status, CallVoted)
+	}
+	tt.send(tt.xcall.Abort(transactor(keyA, nil), tt.id))
+	if status := tt.call(); status != CallAborted {
+		t.Fatalf("status mismatch: have %d, want %d", status, CallAborted)
+	}
+}
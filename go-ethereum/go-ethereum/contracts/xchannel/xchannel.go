@@ -0,0 +1,214 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package xchannel is the on-chain side of the Cross-Channel payment channels:
+// the channel contract and the typed data its parties sign off-chain, as well as
+// the hashed timelock contract swaps across chains are made with and the lock
+// contract calls across chains are committed atomically with.
+//
+// The contracts are written in Solidity in contract/*.sol and bound with abigen.
+// Deploy deploys all of them, and CheckCode tells whether a contract deployed on
+// a chain is the one of this Version, or XCallVersion for the XCall contract.
+package xchannel
+
+//go:generate abigen --sol contract/channel.sol --pkg contract --out contract/channel.go
+//go:generate abigen --sol contract/htlc.sol --pkg contract --out contract/htlc.go
+//go:generate abigen --sol contract/xcall.sol --pkg contract --out contract/xcall.go
+//go:generate gencodec -type Lock -field-override lockMarshaling -out gen_lock_json.go
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Channel statuses as tracked by the contract. Virtual channels are unknown
+// until disputed or closed on-chain.
+const (
+	StatusUnknown  = 0
+	StatusOpen     = 1
+	StatusDisputed = 2
+	StatusClosed   = 3
+)
+
+// Swap statuses as tracked by the HTLC contract.
+const (
+	SwapUnknown  = 0
+	SwapLocked   = 1
+	SwapClaimed  = 2
+	SwapRefunded = 3
+)
+
+// Call statuses as tracked by the XCall contract.
+const (
+	CallUnknown   = 0
+	CallPrepared  = 1
+	CallCommitted = 2
+	CallAborted   = 3
+	CallVoted     = 4
+)
+
+var (
+	domainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	stateTypeHash  = crypto.Keccak256Hash([]byte("State(bytes32 channel,uint256 nonce,uint256 balanceA,uint256 balanceB,bytes32 locksRoot)"))
+	closeTypeHash  = crypto.Keccak256Hash([]byte("Close(bytes32 channel,uint256 nonce,uint256 balanceA,uint256 balanceB)"))
+
+	domainName    = crypto.Keccak256Hash([]byte("XChannel"))
+	domainVersion = crypto.Keccak256Hash([]byte(Version))
+)
+
+// Lock is a pending hash-locked transfer of a channel state. It is refunded to
+// its payer unless the preimage of the hashlock is revealed on-chain by the
+// expiration block.
+//
+// Locks expiring at block 0 allocate their amount to the virtual channel whose
+// id is the hashlock instead, split on the final state of the virtual channel
+// with the payer standing in for its partyA.
+type Lock struct {
+	Amount     *big.Int       `json:"amount" gencodec:"required"`
+	Expiration uint64         `json:"expiration" gencodec:"required"`
+	Hashlock   common.Hash    `json:"hashlock" gencodec:"required"`
+	Payer      common.Address `json:"payer" gencodec:"required"`
+}
+
+type lockMarshaling struct {
+	Amount     *hexutil.Big
+	Expiration hexutil.Uint64
+}
+
+// Virtual reports whether the lock allocates its amount to a virtual channel.
+func (l *Lock) Virtual() bool {
+	return l.Expiration == 0
+}
+
+// Hashlock returns the hashlock a secret unlocks.
+func Hashlock(secret common.Hash) common.Hash {
+	return crypto.Keccak256Hash(secret[:])
+}
+
+// SwapID returns the id of the swap an HTLC contract creates for the given
+// terms, known to both parties before the funds are locked.
+func SwapID(contract, sender, recipient common.Address, hashlock common.Hash, timelock uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(contract[:], 32),
+		common.LeftPadBytes(sender[:], 32),
+		common.LeftPadBytes(recipient[:], 32),
+		hashlock[:],
+		common.LeftPadBytes(new(big.Int).SetUint64(timelock).Bytes(), 32),
+	)
+}
+
+// VirtualID returns the id of a virtual channel between two parties, funded
+// with the given total from the ledger channels of the parties with a hub.
+func VirtualID(contract, partyA, partyB common.Address, total *big.Int, challenge uint64, salt common.Hash) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(contract[:], 32),
+		common.LeftPadBytes(partyA[:], 32),
+		common.LeftPadBytes(partyB[:], 32),
+		math.U256Bytes(new(big.Int).Set(total)),
+		common.LeftPadBytes(new(big.Int).SetUint64(challenge).Bytes(), 32),
+		salt[:],
+	)
+}
+
+// CallID returns the id of the call an XCall contract prepares for a coordinator
+// as part of a transaction.
+func CallID(contract, coordinator common.Address, xid common.Hash) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(contract[:], 32),
+		common.LeftPadBytes(coordinator[:], 32),
+		xid[:],
+	)
+}
+
+// EncodeLocks returns the encoding of a list of locks the contract settles on,
+// the abi encoding of each lock concatenated.
+func EncodeLocks(locks []Lock) []byte {
+	blob := make([]byte, 0, 128*len(locks))
+	for _, lock := range locks {
+		blob = append(blob, math.U256Bytes(new(big.Int).Set(lock.Amount))...)
+		blob = append(blob, common.LeftPadBytes(new(big.Int).SetUint64(lock.Expiration).Bytes(), 32)...)
+		blob = append(blob, lock.Hashlock[:]...)
+		blob = append(blob, common.LeftPadBytes(lock.Payer[:], 32)...)
+	}
+	return blob
+}
+
+// LocksRoot returns the hash committing a state to its pending transfers, zero
+// if there are none.
+func LocksRoot(locks []Lock) common.Hash {
+	if len(locks) == 0 {
+		return common.Hash{}
+	}
+	return crypto.Keccak256Hash(EncodeLocks(locks))
+}
+
+// Domain is the EIP-712 domain of a channel contract deployment, separating the
+// signatures of its channels from those of any other deployment or chain.
+type Domain struct {
+	ChainID  *big.Int
+	Contract common.Address
+}
+
+// Separator returns the EIP-712 domain separator.
+func (d Domain) Separator() common.Hash {
+	return crypto.Keccak256Hash(
+		domainTypeHash[:],
+		domainName[:],
+		domainVersion[:],
+		math.U256Bytes(new(big.Int).Set(d.ChainID)),
+		common.LeftPadBytes(d.Contract[:], 32),
+	)
+}
+
+// StateData returns the EIP-712 encoding of a channel state, the digest signed
+// by the parties being its keccak256 hash.
+func (d Domain) StateData(id common.Hash, nonce uint64, balanceA, balanceB *big.Int, locksRoot common.Hash) []byte {
+	return d.typedData(crypto.Keccak256(
+		stateTypeHash[:],
+		id[:],
+		common.LeftPadBytes(new(big.Int).SetUint64(nonce).Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(balanceA)),
+		math.U256Bytes(new(big.Int).Set(balanceB)),
+		locksRoot[:],
+	))
+}
+
+// CloseData returns the EIP-712 encoding of the final balances of a channel
+// closed cooperatively, the digest signed by the parties being its keccak256
+// hash.
+func (d Domain) CloseData(id common.Hash, nonce uint64, balanceA, balanceB *big.Int) []byte {
+	return d.typedData(crypto.Keccak256(
+		closeTypeHash[:],
+		id[:],
+		common.LeftPadBytes(new(big.Int).SetUint64(nonce).Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(balanceA)),
+		math.U256Bytes(new(big.Int).Set(balanceB)),
+	))
+}
+
+// typedData prefixes a struct hash with the EIP-712 header and domain separator.
+func (d Domain) typedData(structHash []byte) []byte {
+	separator := d.Separator()
+
+	data := make([]byte, 0, 66)
+	data = append(data, 0x19, 0x01)
+	data = append(data, separator[:]...)
+	return append(data, structHash...)
+}
@@ -0,0 +1,410 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xchannel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	keyA, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	keyB, _  = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	addrA    = crypto.PubkeyToAddress(keyA.PublicKey)
+	addrB    = crypto.PubkeyToAddress(keyB.PublicKey)
+	simChain = big.NewInt(1337)
+)
+
+// tester is a channel contract deployed on a simulated chain, with a channel
+// opened between the two test accounts.
+type tester struct {
+	t        *testing.T
+	sim      *backends.SimulatedBackend
+	contract *contract.XChannel
+	domain   Domain
+	id       common.Hash
+}
+
+// newTester deploys the channel contract and opens a channel with the given
+// deposits and challenge period.
+func newTester(t *testing.T, depositA, depositB *big.Int, challenge uint64) *tester {
+	funds := new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		addrA: {Balance: funds},
+		addrB: {Balance: funds},
+	}, 10000000)
+
+	address, _, xchannel, err := contract.DeployXChannel(transactor(keyA, nil), sim)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	sim.Commit()
+
+	tt := &tester{
+		t:        t,
+		sim:      sim,
+		contract: xchannel,
+		domain:   Domain{ChainID: simChain, Contract: address},
+	}
+	tx, err := xchannel.Open(transactor(keyA, depositA), addrB, new(big.Int).SetUint64(challenge))
+	if err != nil {
+		t.Fatalf("failed to open channel: %v", err)
+	}
+	sim.Commit()
+
+	receipt, _ := sim.TransactionReceipt(context.Background(), tx.Hash())
+	if receipt == nil || len(receipt.Logs) == 0 {
+		t.Fatalf("channel not opened")
+	}
+	event, err := xchannel.ParseOpened(*receipt.Logs[0])
+	if err != nil {
+		t.Fatalf("failed to parse open event: %v", err)
+	}
+	tt.id = event.Id
+
+	if depositB.Sign() > 0 {
+		tt.send(xchannel.Deposit(transactor(keyB, depositB), tt.id))
+	}
+	return tt
+}
+
+// transactor returns the options to send a transaction with the given key.
+func transactor(key *ecdsa.PrivateKey, value *big.Int) *bind.TransactOpts {
+	opts, _ := bind.NewKeyedTransactorWithChainID(key, simChain)
+	opts.Value = value
+	return opts
+}
+
+// sign signs typed data the way the contract expects it.
+func sign(key *ecdsa.PrivateKey, data []byte) []byte {
+	sig, err := crypto.Sign(crypto.Keccak256(data), key)
+	if err != nil {
+		panic(err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig
+}
+
+// send mines a transaction, failing the test if it can't be sent or reverts.
+func (tt *tester) send(tx *types.Transaction, err error) {
+	tt.t.Helper()
+
+	if err != nil {
+		tt.t.Fatalf("failed to send transaction: %v", err)
+	}
+	tt.sim.Commit()
+
+	receipt, _ := tt.sim.TransactionReceipt(context.Background(), tx.Hash())
+	if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+		tt.t.Fatalf("transaction failed")
+	}
+}
+
+// reject checks that a transaction is refused by the contract.
+func (tt *tester) reject(tx *types.Transaction, err error) {
+	tt.t.Helper()
+
+	if err == nil {
+		tt.sim.Commit()
+		receipt, _ := tt.sim.TransactionReceipt(context.Background(), tx.Hash())
+		if receipt != nil && receipt.Status == types.ReceiptStatusSuccessful {
+			tt.t.Fatalf("transaction succeeded")
+		}
+	}
+}
+
+// mine mines blocks until the head reaches the given number.
+func (tt *tester) mine(number uint64) {
+	for tt.sim.Blockchain().CurrentBlock().NumberU64() < number {
+		tt.sim.Commit()
+	}
+}
+
+// balance returns the balance of an account.
+func (tt *tester) balance(addr common.Address) *big.Int {
+	balance, err := tt.sim.BalanceAt(context.Background(), addr, nil)
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve balance: %v", err)
+	}
+	return balance
+}
+
+// status returns the on-chain status of the channel.
+func (tt *tester) status() uint64 {
+	channel, err := tt.contract.Channels(nil, tt.id)
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve channel: %v", err)
+	}
+	return channel.Status.Uint64()
+}
+
+// dispute raises a dispute on a state signed by both parties.
+func (tt *tester) dispute(key *ecdsa.PrivateKey, nonce uint64, balanceA, balanceB *big.Int, locks []Lock) (*types.Transaction, error) {
+	root := LocksRoot(locks)
+	data := tt.domain.StateData(tt.id, nonce, balanceA, balanceB, root)
+	return tt.contract.Dispute(transactor(key, nil), tt.id, new(big.Int).SetUint64(nonce), balanceA, balanceB, root, sign(keyA, data), sign(keyB, data))
+}
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Ether))
+}
+
+func TestOpenDeposit(t *testing.T) {
+	tt := newTester(t, ether(10), new(big.Int), 10)
+
+	channel, err := tt.contract.Channels(nil, tt.id)
+	if err != nil {
+		t.Fatalf("failed to retrieve channel: %v", err)
+	}
+	if channel.PartyA != addrA || channel.PartyB != addrB || channel.Challenge.Uint64() != 10 || channel.Status.Uint64() != StatusOpen {
+		t.Fatalf("channel mismatch: %+v", channel)
+	}
+	if channel.DepositA.Cmp(ether(10)) != 0 || channel.DepositB.Sign() != 0 {
+		t.Fatalf("deposits mismatch: have %v/%v, want %v/0", channel.DepositA, channel.DepositB, ether(10))
+	}
+	// Only the counterparty may top up, any number of times
+	tt.reject(tt.contract.Deposit(transactor(keyA, ether(1)), tt.id))
+	tt.reject(tt.contract.Deposit(transactor(keyB, ether(1)), common.Hash{}))
+	tt.send(tt.contract.Deposit(transactor(keyB, ether(2)), tt.id))
+	tt.send(tt.contract.Deposit(transactor(keyB, ether(3)), tt.id))
+
+	if channel, _ = tt.contract.Channels(nil, tt.id); channel.DepositB.Cmp(ether(5)) != 0 {
+		t.Fatalf("deposit mismatch: have %v, want %v", channel.DepositB, ether(5))
+	}
+	if balance := tt.balance(tt.domain.Contract); balance.Cmp(ether(15)) != 0 {
+		t.Fatalf("contract balance mismatch: have %v, want %v", balance, ether(15))
+	}
+	// Channels can't be opened with oneself
+	tt.reject(tt.contract.Open(transactor(keyA, ether(1)), addrA, big.NewInt(10)))
+}
+
+func TestCooperativeClose(t *testing.T) {
+	tt := newTester(t, ether(10), ether(5), 10)
+	if status := tt.status(); status != StatusOpen {
+		t.Fatalf("status mismatch: have %d, want %d", status, StatusOpen)
+	}
+	data := tt.domain.CloseData(tt.id, 3, ether(7), ether(8))
+
+	// Closing with balances not matching the deposits or with a bogus signature
+	// must fail
+	bogus := tt.domain.CloseData(tt.id, 3, ether(7), ether(9))
+	tt.reject(tt.contract.Close(transactor(keyA, nil), tt.id, big.NewInt(3), ether(7), ether(9), sign(keyA, bogus), sign(keyB, bogus)))
+	tt.reject(tt.contract.Close(transactor(keyA, nil), tt.id, big.NewInt(3), ether(7), ether(8), sign(keyA, data), sign(keyA, data)))
+
+	before := tt.balance(addrB)
+	tt.send(tt.contract.Close(transactor(keyA, nil), tt.id, big.NewInt(3), ether(7), ether(8), sign(keyA, data), sign(keyB, data)))
+
+	if status := tt.status(); status != StatusClosed {
+		t.Fatalf("status mismatch: have %d, want %d", status, StatusClosed)
+	}
+	if have, want := tt.balance(addrB), new(big.Int).Add(before, ether(8)); have.Cmp(want) != 0 {
+		t.Fatalf("balance mismatch: have %v, want %v", have, want)
+	}
+	if balance := tt.balance(tt.domain.Contract); balance.Sign() != 0 {
+		t.Fatalf("funds left in contract: %v", balance)
+	}
+	// A closed channel can't be closed again
+	tt.reject(tt.contract.Close(transactor(keyA, nil), tt.id, big.NewInt(3), ether(7), ether(8), sign(keyA, data), sign(keyB, data)))
+}
+
+func TestDisputeSettle(t *testing.T) {
+	tt := newTester(t, ether(10), ether(5), 10)
+
+	var (
+		secret   = common.HexToHash("0x5ec4e7")
+		revealed = Lock{Amount: ether(2), Expiration: 1000, Hashlock: Hashlock(secret), Payer: addrA}
+		expired  = Lock{Amount: ether(1), Expiration: 1, Hashlock: common.HexToHash("0x01"), Payer: addrA}
+		locks    = []Lock{revealed, expired}
+	)
+	// A disputes on an outdated state, which B contests with the latest one
+	tt.send(tt.dispute(keyA, 1, ether(9), ether(6), nil))
+	if status := tt.status(); status != StatusDisputed {
+		t.Fatalf("status mismatch: have %d, want %d", status, StatusDisputed)
+	}
+	tt.send(tt.dispute(keyB, 2, ether(4), ether(8), locks))
+	tt.reject(tt.dispute(keyA, 1, ether(9), ether(6), nil))
+
+	// Settling is only possible once the dispute window closed, and only on the
+	// transfers committed to
+	channel, _ := tt.contract.Channels(nil, tt.id)
+	tt.reject(tt.contract.Settle(transactor(keyB, nil), tt.id, EncodeLocks(locks)))
+
+	tt.send(tt.contract.Reveal(transactor(keyB, nil), secret))
+	if number, _ := tt.contract.Secrets(nil, revealed.Hashlock); number.Sign() == 0 {
+		t.Fatalf("secret not revealed")
+	}
+	tt.mine(channel.Deadline.Uint64())
+	tt.reject(tt.contract.Settle(transactor(keyB, nil), tt.id, EncodeLocks(locks[:1])))
+
+	beforeA, beforeB := tt.balance(addrA), tt.balance(addrB)
+	tt.send(tt.contract.Settle(transactor(keyB, nil), tt.id, EncodeLocks(locks)))
+
+	if status := tt.status(); status != StatusClosed {
+		t.Fatalf("status mismatch: have %d, want %d", status, StatusClosed)
+	}
+	if have, want := tt.balance(addrA), new(big.Int).Add(beforeA, ether(5)); have.Cmp(want) != 0 {
+		t.Fatalf("balance A mismatch: have %v, want %v", have, want)
+	}
+	if have := tt.balance(addrB); have.Cmp(beforeB) <= 0 {
+		t.Fatalf("balance B not credited: have %v, before %v", have, beforeB)
+	}
+	if balance := tt.balance(tt.domain.Contract); balance.Sign() != 0 {
+		t.Fatalf("funds left in contract: %v", balance)
+	}
+}
+
+func TestDisputeOpening(t *testing.T) {
+	tt := newTester(t, ether(10), big.NewInt(0), 5)
+
+	// The opening state needs no signatures, but only the parties can dispute it
+	opening := func(key *ecdsa.PrivateKey) (*types.Transaction, error) {
+		return tt.contract.Dispute(transactor(key, nil), tt.id, big.NewInt(0), ether(10), big.NewInt(0), common.Hash{}, nil, nil)
+	}
+	outsider, _ := crypto.GenerateKey()
+	tt.reject(opening(outsider))
+	tt.send(opening(keyB))
+
+	channel, _ := tt.contract.Channels(nil, tt.id)
+	tt.mine(channel.Deadline.Uint64())
+
+	before := tt.balance(addrA)
+	tt.send(tt.contract.Settle(transactor(keyB, nil), tt.id, nil))
+	if have, want := tt.balance(addrA), new(big.Int).Add(before, ether(10)); have.Cmp(want) != 0 {
+		t.Fatalf("balance mismatch: have %v, want %v", have, want)
+	}
+}
+
+// virtualTester is a virtual channel between partyA of the tested channel and
+// a third party, funded from the tested channel with the counterparty as hub.
+type virtualTester struct {
+	*tester
+	keyC      *ecdsa.PrivateKey
+	total     *big.Int
+	challenge uint64
+	salt      common.Hash
+	id        common.Hash
+}
+
+func newVirtualTester(tt *tester, total *big.Int, challenge uint64) *virtualTester {
+	keyC, _ := crypto.GenerateKey()
+	vt := &virtualTester{tester: tt, keyC: keyC, total: total, challenge: challenge, salt: common.HexToHash("0x5a17")}
+	vt.id = VirtualID(tt.domain.Contract, addrA, crypto.PubkeyToAddress(keyC.PublicKey), total, challenge, vt.salt)
+	return vt
+}
+
+// dispute raises a dispute on a state of the virtual channel signed by both
+// its parties.
+func (vt *virtualTester) dispute(nonce uint64, balanceA, balanceB *big.Int) (*types.Transaction, error) {
+	data := vt.domain.StateData(vt.id, nonce, balanceA, balanceB, common.Hash{})
+	return vt.contract.DisputeVirtual(transactor(keyB, nil), addrA, crypto.PubkeyToAddress(vt.keyC.PublicKey), vt.total,
+		new(big.Int).SetUint64(vt.challenge), vt.salt, new(big.Int).SetUint64(nonce), balanceA, balanceB, sign(keyA, data), sign(vt.keyC, data))
+}
+
+// close finalises the virtual channel on balances signed by both its parties.
+func (vt *virtualTester) close(nonce uint64, balanceA, balanceB *big.Int) (*types.Transaction, error) {
+	data := vt.domain.CloseData(vt.id, nonce, balanceA, balanceB)
+	return vt.contract.CloseVirtual(transactor(keyB, nil), addrA, crypto.PubkeyToAddress(vt.keyC.PublicKey), vt.total,
+		new(big.Int).SetUint64(vt.challenge), vt.salt, new(big.Int).SetUint64(nonce), balanceA, balanceB, sign(keyA, data), sign(vt.keyC, data))
+}
+
+func TestVirtualDispute(t *testing.T) {
+	tt := newTester(t, ether(10), ether(5), 5)
+	vt := newVirtualTester(tt, ether(6), 10)
+
+	// A funds the virtual channel with 3 ether, the hub B with the other 3
+	locks := []Lock{{Amount: ether(6), Hashlock: vt.id, Payer: addrA}}
+	tt.send(tt.dispute(keyA, 1, ether(7), ether(2), locks))
+
+	channel, _ := tt.contract.Channels(nil, tt.id)
+	tt.mine(channel.Deadline.Uint64())
+
+	// The ledger channel can't settle before the virtual channel is final
+	tt.reject(tt.contract.Settle(transactor(keyB, nil), tt.id, EncodeLocks(locks)))
+
+	// Balances not adding up to the total and states not newer are refused
+	tt.reject(vt.dispute(0, ether(3), ether(4)))
+	tt.send(vt.dispute(0, ether(3), ether(3)))
+	tt.send(vt.dispute(2, ether(1), ether(5)))
+	tt.reject(vt.dispute(1, ether(2), ether(4)))
+
+	virtual, _ := tt.contract.Virtuals(nil, vt.id)
+	if virtual.Status.Uint64() != StatusDisputed || virtual.Nonce.Uint64() != 2 {
+		t.Fatalf("virtual channel mismatch: have status %d nonce %d, want %d, 2", virtual.Status, virtual.Nonce, StatusDisputed)
+	}
+	tt.reject(tt.contract.Settle(transactor(keyB, nil), tt.id, EncodeLocks(locks)))
+	tt.mine(virtual.Deadline.Uint64())
+
+	// Once final, A gets its share of the virtual channel, the hub that of C
+	beforeA, beforeB := tt.balance(addrA), tt.balance(addrB)
+	tt.send(tt.contract.Settle(transactor(keyB, nil), tt.id, EncodeLocks(locks)))
+
+	if have, want := tt.balance(addrA), new(big.Int).Add(beforeA, ether(8)); have.Cmp(want) != 0 {
+		t.Fatalf("balance A mismatch: have %v, want %v", have, want)
+	}
+	if have := tt.balance(addrB); have.Cmp(beforeB) <= 0 {
+		t.Fatalf("balance B not credited: have %v, before %v", have, beforeB)
+	}
+	if balance := tt.balance(tt.domain.Contract); balance.Sign() != 0 {
+		t.Fatalf("funds left in contract: %v", balance)
+	}
+	// A virtual channel whose dispute ended can't be closed anymore
+	tt.reject(vt.close(3, ether(6), big.NewInt(0)))
+}
+
+func TestVirtualClose(t *testing.T) {
+	tt := newTester(t, ether(10), ether(5), 5)
+	vt := newVirtualTester(tt, ether(6), 10)
+
+	// B stands in for partyA of the virtual channel, as a hub does towards partyB
+	locks := []Lock{{Amount: ether(6), Hashlock: vt.id, Payer: addrB}}
+	tt.send(tt.dispute(keyA, 1, ether(8), ether(1), locks))
+
+	// Closing overrides a running dispute, after which no dispute is possible
+	tt.send(vt.dispute(0, ether(3), ether(3)))
+	tt.reject(vt.close(4, ether(2), ether(5)))
+	tt.send(vt.close(4, ether(2), ether(4)))
+	tt.reject(vt.dispute(5, ether(6), big.NewInt(0)))
+
+	virtual, _ := tt.contract.Virtuals(nil, vt.id)
+	if virtual.Status.Uint64() != StatusClosed {
+		t.Fatalf("status mismatch: have %d, want %d", virtual.Status, StatusClosed)
+	}
+	channel, _ := tt.contract.Channels(nil, tt.id)
+	tt.mine(channel.Deadline.Uint64())
+
+	// A gets the share of partyB of the virtual channel, B that of partyA
+	beforeA := tt.balance(addrA)
+	tt.send(tt.contract.Settle(transactor(keyB, nil), tt.id, EncodeLocks(locks)))
+
+	if have, want := tt.balance(addrA), new(big.Int).Add(beforeA, ether(12)); have.Cmp(want) != 0 {
+		t.Fatalf("balance A mismatch: have %v, want %v", have, want)
+	}
+	if balance := tt.balance(tt.domain.Contract); balance.Sign() != 0 {
+		t.Fatalf("funds left in contract: %v", balance)
+	}
+}
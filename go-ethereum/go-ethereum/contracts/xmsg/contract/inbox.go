@@ -0,0 +1,684 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// InboxABI is the input ABI used to generate the binding from.
+const InboxABI = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"bytes32\",\"name\":\"id\",\"type\":\"bytes32\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"target\",\"type\":\"address\"}],\"name\":\"Delivered\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"bytes32\",\"name\":\"hash\",\"type\":\"bytes32\"}],\"name\":\"HeaderAdded\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"hash\",\"type\":\"bytes32\"}],\"name\":\"addHeader\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"admin\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"consumed\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes\",\"name\":\"proof\",\"type\":\"bytes\"}],\"name\":\"deliver\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_outbox\",\"type\":\"address\"}],\"name\":\"initialize\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"known\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"outbox\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+// InboxFuncSigs maps the 4-byte function signature to its string representation.
+var InboxFuncSigs = map[string]string{
+	"12ea3f13": "addHeader(bytes32)",
+	"f851a440": "admin()",
+	"4648c943": "consumed(bytes32)",
+	"22133eaf": "deliver(bytes)",
+	"c4d66de8": "initialize(address)",
+	"1f414f7f": "known(bytes32)",
+	"ce11e6ab": "outbox()",
+}
+
+// InboxBin is the compiled bytecode used for deploying new contracts.
+var InboxBin = "0x6080604052348015600f57600080fd5b506108758061001f6000396000f3fe608060405234801561001057600080fd5b506004361061007d5760003560e01c80634648c9431161005b5780634648c943146100e2578063c4d66de814610105578063ce11e6ab14610118578063f851a4401461014357600080fd5b806312ea3f13146100825780631f414f7f1461009757806322133eaf146100cf575b600080fd5b61009561009036600461052c565b610156565b005b6100ba6100a536600461052c565b60026020526000908152604090205460ff1681565b60405190151581526020015b60405180910390f35b6100956100dd366004610545565b6101b0565b6100ba6100f036600461052c565b60036020526000908152604090205460ff1681565b6100956101133660046105d1565b610414565b60015461012b906001600160a01b031681565b6040516001600160a01b0390911681526020016100c6565b60005461012b906001600160a01b031681565b6000546001600160a01b0316331461016d57600080fd5b600081815260026020526040808220805460ff191660011790555182917f564befda554cb0a6afdf182ba391ce9bae1cbb9e4f24f0f50395f4b30f462cf891a250565b60008060156001600160a01b031684846040516101ce9291906105f5565b600060405180830381855afa9150503d8060008114610209576040519150601f19603f3d011682016040523d82523d6000602084013e61020e565b606091505b50915091508180156102235750610180815110155b61022c57600080fd5b60008060008060008060008780602001905181019061024b9190610605565b600088815260026020526040902054979e50949c50929a509098509650945092505060ff16801561028957506001546001600160a01b038781169116145b80156102955750846004145b80156102c057507f13eeab43f4cc5fe7671e4154f4807ab15607a901ee5dd6bafbe6b1076461438284145b80156102d457506001600160a01b03821630145b6102dd57600080fd5b60008060006102ee8b61010061046e565b80602001905181019061030191906106bf565b92509250925046831461031357600080fd5b60008681526003602052604090205460ff161561032f57600080fd5b600086815260036020526040808220805460ff19166001179055516001600160a01b0386169188917f1089d8381cb2b8fe802d1c73deddfba4975ff088d88fe813bb65fdb326e05c079190a3836001600160a01b03168183604051602001610398929190610794565b60408051601f19818403018152908290526103b2916107cb565b6000604051808303816000865af19150503d80600081146103ef576040519150601f19603f3d011682016040523d82523d6000602084013e6103f4565b606091505b5050809c50508b61040457600080fd5b5050505050505050505050505050565b6001546001600160a01b031615801561043557506001600160a01b03811615155b61043e57600080fd5b60008054336001600160a01b031991821617909155600180549091166001600160a01b0392909216919091179055565b606081835161047d91906107fd565b67ffffffffffffffff81111561049557610495610685565b6040519080825280601f01601f1916602001820160405280156104bf576020820181803683370190505b50905060005b815181101561052557836104d98285610816565b815181106104e9576104e9610829565b602001015160f81c60f81b82828151811061050657610506610829565b60200101906001600160f81b031916908160001a9053506001016104c5565b5092915050565b60006020828403121561053e57600080fd5b5035919050565b6000806020838503121561055857600080fd5b823567ffffffffffffffff81111561056f57600080fd5b8301601f8101851361058057600080fd5b803567ffffffffffffffff81111561059757600080fd5b8560208284010111156105a957600080fd5b6020919091019590945092505050565b6001600160a01b03811681146105ce57600080fd5b50565b6000602082840312156105e357600080fd5b81356105ee816105b9565b9392505050565b8183823760009101908152919050565b600080600080600080600080610100898b03121561062257600080fd5b885160208a015160408b0151919950975061063c816105b9565b60608a015160808b015160a08c015160c08d015193995091975095509350610663816105b9565b60e08a0151909250610674816105b9565b809150509295985092959890939650565b634e487b7160e01b600052604160045260246000fd5b60005b838110156106b657818101518382015260200161069e565b50506000910152565b6000806000606084860312156106d457600080fd5b835160208501519093506106e7816105b9565b604085015190925067ffffffffffffffff81111561070457600080fd5b8401601f8101861361071557600080fd5b805167ffffffffffffffff81111561072f5761072f610685565b604051601f8201601f19908116603f0116810167ffffffffffffffff8111828210171561075e5761075e610685565b60405281815282820160200188101561077657600080fd5b61078782602083016020860161069b565b8093505050509250925092565b600083516107a681846020880161069b565b60609390931b6bffffffffffffffffffffffff19169190920190815260140192915050565b600082516107dd81846020870161069b565b9190910192915050565b634e487b7160e01b600052601160045260246000fd5b81810381811115610810576108106107e7565b92915050565b80820180821115610810576108106107e7565b634e487b7160e01b600052603260045260246000fdfea264697066735822122012c3d543fd0b844b87f8be6a1e35058d013c8edd67d8a854df4f391529e7089264736f6c634300081e0033"
+
+// DeployInbox deploys a new Ethereum contract, binding an instance of Inbox to it.
+func DeployInbox(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *Inbox, error) {
+	parsed, err := abi.JSON(strings.NewReader(InboxABI))
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, parsed, common.FromHex(InboxBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &Inbox{InboxCaller: InboxCaller{contract: contract}, InboxTransactor: InboxTransactor{contract: contract}, InboxFilterer: InboxFilterer{contract: contract}}, nil
+}
+
+// Inbox is an auto generated Go binding around an Ethereum contract.
+type Inbox struct {
+	InboxCaller     // Read-only binding to the contract
+	InboxTransactor // Write-only binding to the contract
+	InboxFilterer   // Log filterer for contract events
+}
+
+// InboxCaller is an auto generated read-only Go binding around an Ethereum contract.
+type InboxCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// InboxTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type InboxTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// InboxFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type InboxFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// InboxSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type InboxSession struct {
+	Contract     *Inbox            // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// InboxCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type InboxCallerSession struct {
+	Contract *InboxCaller  // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
+}
+
+// InboxTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type InboxTransactorSession struct {
+	Contract     *InboxTransactor  // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// InboxRaw is an auto generated low-level Go binding around an Ethereum contract.
+type InboxRaw struct {
+	Contract *Inbox // Generic contract binding to access the raw methods on
+}
+
+// InboxCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type InboxCallerRaw struct {
+	Contract *InboxCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// InboxTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type InboxTransactorRaw struct {
+	Contract *InboxTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewInbox creates a new instance of Inbox, bound to a specific deployed contract.
+func NewInbox(address common.Address, backend bind.ContractBackend) (*Inbox, error) {
+	contract, err := bindInbox(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Inbox{InboxCaller: InboxCaller{contract: contract}, InboxTransactor: InboxTransactor{contract: contract}, InboxFilterer: InboxFilterer{contract: contract}}, nil
+}
+
+// NewInboxCaller creates a new read-only instance of Inbox, bound to a specific deployed contract.
+func NewInboxCaller(address common.Address, caller bind.ContractCaller) (*InboxCaller, error) {
+	contract, err := bindInbox(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &InboxCaller{contract: contract}, nil
+}
+
+// NewInboxTransactor creates a new write-only instance of Inbox, bound to a specific deployed contract.
+func NewInboxTransactor(address common.Address, transactor bind.ContractTransactor) (*InboxTransactor, error) {
+	contract, err := bindInbox(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &InboxTransactor{contract: contract}, nil
+}
+
+// NewInboxFilterer creates a new log filterer instance of Inbox, bound to a specific deployed contract.
+func NewInboxFilterer(address common.Address, filterer bind.ContractFilterer) (*InboxFilterer, error) {
+	contract, err := bindInbox(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &InboxFilterer{contract: contract}, nil
+}
+
+// bindInbox binds a generic wrapper to an already deployed contract.
+func bindInbox(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(InboxABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Inbox *InboxRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Inbox.Contract.InboxCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Inbox *InboxRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Inbox.Contract.InboxTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Inbox *InboxRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Inbox.Contract.InboxTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Inbox *InboxCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Inbox.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Inbox *InboxTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Inbox.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Inbox *InboxTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Inbox.Contract.contract.Transact(opts, method, params...)
+}
+
+// Admin is a free data retrieval call binding the contract method 0xf851a440.
+//
+// Solidity: function admin() view returns(address)
+func (_Inbox *InboxCaller) Admin(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Inbox.contract.Call(opts, &out, "admin")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Admin is a free data retrieval call binding the contract method 0xf851a440.
+//
+// Solidity: function admin() view returns(address)
+func (_Inbox *InboxSession) Admin() (common.Address, error) {
+	return _Inbox.Contract.Admin(&_Inbox.CallOpts)
+}
+
+// Admin is a free data retrieval call binding the contract method 0xf851a440.
+//
+// Solidity: function admin() view returns(address)
+func (_Inbox *InboxCallerSession) Admin() (common.Address, error) {
+	return _Inbox.Contract.Admin(&_Inbox.CallOpts)
+}
+
+// Consumed is a free data retrieval call binding the contract method 0x4648c943.
+//
+// Solidity: function consumed(bytes32 ) view returns(bool)
+func (_Inbox *InboxCaller) Consumed(opts *bind.CallOpts, arg0 [32]byte) (bool, error) {
+	var out []interface{}
+	err := _Inbox.contract.Call(opts, &out, "consumed", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// Consumed is a free data retrieval call binding the contract method 0x4648c943.
+//
+// Solidity: function consumed(bytes32 ) view returns(bool)
+func (_Inbox *InboxSession) Consumed(arg0 [32]byte) (bool, error) {
+	return _Inbox.Contract.Consumed(&_Inbox.CallOpts, arg0)
+}
+
+// Consumed is a free data retrieval call binding the contract method 0x4648c943.
+//
+// Solidity: function consumed(bytes32 ) view returns(bool)
+func (_Inbox *InboxCallerSession) Consumed(arg0 [32]byte) (bool, error) {
+	return _Inbox.Contract.Consumed(&_Inbox.CallOpts, arg0)
+}
+
+// Known is a free data retrieval call binding the contract method 0x1f414f7f.
+//
+// Solidity: function known(bytes32 ) view returns(bool)
+func (_Inbox *InboxCaller) Known(opts *bind.CallOpts, arg0 [32]byte) (bool, error) {
+	var out []interface{}
+	err := _Inbox.contract.Call(opts, &out, "known", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// Known is a free data retrieval call binding the contract method 0x1f414f7f.
+//
+// Solidity: function known(bytes32 ) view returns(bool)
+func (_Inbox *InboxSession) Known(arg0 [32]byte) (bool, error) {
+	return _Inbox.Contract.Known(&_Inbox.CallOpts, arg0)
+}
+
+// Known is a free data retrieval call binding the contract method 0x1f414f7f.
+//
+// Solidity: function known(bytes32 ) view returns(bool)
+func (_Inbox *InboxCallerSession) Known(arg0 [32]byte) (bool, error) {
+	return _Inbox.Contract.Known(&_Inbox.CallOpts, arg0)
+}
+
+// Outbox is a free data retrieval call binding the contract method 0xce11e6ab.
+//
+// Solidity: function outbox() view returns(address)
+func (_Inbox *InboxCaller) Outbox(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Inbox.contract.Call(opts, &out, "outbox")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Outbox is a free data retrieval call binding the contract method 0xce11e6ab.
+//
+// Solidity: function outbox() view returns(address)
+func (_Inbox *InboxSession) Outbox() (common.Address, error) {
+	return _Inbox.Contract.Outbox(&_Inbox.CallOpts)
+}
+
+// Outbox is a free data retrieval call binding the contract method 0xce11e6ab.
+//
+// Solidity: function outbox() view returns(address)
+func (_Inbox *InboxCallerSession) Outbox() (common.Address, error) {
+	return _Inbox.Contract.Outbox(&_Inbox.CallOpts)
+}
+
+// AddHeader is a paid mutator transaction binding the contract method 0x12ea3f13.
+//
+// Solidity: function addHeader(bytes32 hash) returns()
+func (_Inbox *InboxTransactor) AddHeader(opts *bind.TransactOpts, hash [32]byte) (*types.Transaction, error) {
+	return _Inbox.contract.Transact(opts, "addHeader", hash)
+}
+
+// AddHeader is a paid mutator transaction binding the contract method 0x12ea3f13.
+//
+// Solidity: function addHeader(bytes32 hash) returns()
+func (_Inbox *InboxSession) AddHeader(hash [32]byte) (*types.Transaction, error) {
+	return _Inbox.Contract.AddHeader(&_Inbox.TransactOpts, hash)
+}
+
+// AddHeader is a paid mutator transaction binding the contract method 0x12ea3f13.
+//
+// Solidity: function addHeader(bytes32 hash) returns()
+func (_Inbox *InboxTransactorSession) AddHeader(hash [32]byte) (*types.Transaction, error) {
+	return _Inbox.Contract.AddHeader(&_Inbox.TransactOpts, hash)
+}
+
+// Deliver is a paid mutator transaction binding the contract method 0x22133eaf.
+//
+// Solidity: function deliver(bytes proof) returns()
+func (_Inbox *InboxTransactor) Deliver(opts *bind.TransactOpts, proof []byte) (*types.Transaction, error) {
+	return _Inbox.contract.Transact(opts, "deliver", proof)
+}
+
+// Deliver is a paid mutator transaction binding the contract method 0x22133eaf.
+//
+// Solidity: function deliver(bytes proof) returns()
+func (_Inbox *InboxSession) Deliver(proof []byte) (*types.Transaction, error) {
+	return _Inbox.Contract.Deliver(&_Inbox.TransactOpts, proof)
+}
+
+// Deliver is a paid mutator transaction binding the contract method 0x22133eaf.
+//
+// Solidity: function deliver(bytes proof) returns()
+func (_Inbox *InboxTransactorSession) Deliver(proof []byte) (*types.Transaction, error) {
+	return _Inbox.Contract.Deliver(&_Inbox.TransactOpts, proof)
+}
+
+// Initialize is a paid mutator transaction binding the contract method 0xc4d66de8.
+//
+// Solidity: function initialize(address _outbox) returns()
+func (_Inbox *InboxTransactor) Initialize(opts *bind.TransactOpts, _outbox common.Address) (*types.Transaction, error) {
+	return _Inbox.contract.Transact(opts, "initialize", _outbox)
+}
+
+// Initialize is a paid mutator transaction binding the contract method 0xc4d66de8.
+//
+// Solidity: function initialize(address _outbox) returns()
+func (_Inbox *InboxSession) Initialize(_outbox common.Address) (*types.Transaction, error) {
+	return _Inbox.Contract.Initialize(&_Inbox.TransactOpts, _outbox)
+}
+
+// Initialize is a paid mutator transaction binding the contract method 0xc4d66de8.
+//
+// Solidity: function initialize(address _outbox) returns()
+func (_Inbox *InboxTransactorSession) Initialize(_outbox common.Address) (*types.Transaction, error) {
+	return _Inbox.Contract.Initialize(&_Inbox.TransactOpts, _outbox)
+}
+
+// InboxDeliveredIterator is returned from FilterDelivered and is used to iterate over the raw logs and unpacked data for Delivered events raised by the Inbox contract.
+type InboxDeliveredIterator struct {
+	Event *InboxDelivered // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *InboxDeliveredIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(InboxDelivered)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(InboxDelivered)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *InboxDeliveredIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *InboxDeliveredIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// InboxDelivered represents a Delivered event raised by the Inbox contract.
+type InboxDelivered struct {
+	Id     [32]byte
+	Target common.Address
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterDelivered is a free log retrieval operation binding the contract event 0x1089d8381cb2b8fe802d1c73deddfba4975ff088d88fe813bb65fdb326e05c07.
+//
+// Solidity: event Delivered(bytes32 indexed id, address indexed target)
+func (_Inbox *InboxFilterer) FilterDelivered(opts *bind.FilterOpts, id [][32]byte, target []common.Address) (*InboxDeliveredIterator, error) {
+
+	var idRule []interface{}
+	for _, idItem := range id {
+		idRule = append(idRule, idItem)
+	}
+	var targetRule []interface{}
+	for _, targetItem := range target {
+		targetRule = append(targetRule, targetItem)
+	}
+
+	logs, sub, err := _Inbox.contract.FilterLogs(opts, "Delivered", idRule, targetRule)
+	if err != nil {
+		return nil, err
+	}
+	return &InboxDeliveredIterator{contract: _Inbox.contract, event: "Delivered", logs: logs, sub: sub}, nil
+}
+
+// WatchDelivered is a free log subscription operation binding the contract event 0x1089d8381cb2b8fe802d1c73deddfba4975ff088d88fe813bb65fdb326e05c07.
+//
+// Solidity: event Delivered(bytes32 indexed id, address indexed target)
+func (_Inbox *InboxFilterer) WatchDelivered(opts *bind.WatchOpts, sink chan<- *InboxDelivered, id [][32]byte, target []common.Address) (event.Subscription, error) {
+
+	var idRule []interface{}
+	for _, idItem := range id {
+		idRule = append(idRule, idItem)
+	}
+	var targetRule []interface{}
+	for _, targetItem := range target {
+		targetRule = append(targetRule, targetItem)
+	}
+
+	logs, sub, err := _Inbox.contract.WatchLogs(opts, "Delivered", idRule, targetRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(InboxDelivered)
+				if err := _Inbox.contract.UnpackLog(event, "Delivered", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDelivered is a log parse operation binding the contract event 0x1089d8381cb2b8fe802d1c73deddfba4975ff088d88fe813bb65fdb326e05c07.
+//
+// Solidity: event Delivered(bytes32 indexed id, address indexed target)
+func (_Inbox *InboxFilterer) ParseDelivered(log types.Log) (*InboxDelivered, error) {
+	event := new(InboxDelivered)
+	if err := _Inbox.contract.UnpackLog(event, "Delivered", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// InboxHeaderAddedIterator is returned from FilterHeaderAdded and is used to iterate over the raw logs and unpacked data for HeaderAdded events raised by the Inbox contract.
+type InboxHeaderAddedIterator struct {
+	Event *InboxHeaderAdded // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *InboxHeaderAddedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(InboxHeaderAdded)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(InboxHeaderAdded)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *InboxHeaderAddedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *InboxHeaderAddedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// InboxHeaderAdded represents a HeaderAdded event raised by the Inbox contract.
+type InboxHeaderAdded struct {
+	Hash [32]byte
+	Raw  types.Log // Blockchain specific contextual infos
+}
+
+// FilterHeaderAdded is a free log retrieval operation binding the contract event 0x564befda554cb0a6afdf182ba391ce9bae1cbb9e4f24f0f50395f4b30f462cf8.
+//
+// Solidity: event HeaderAdded(bytes32 indexed hash)
+func (_Inbox *InboxFilterer) FilterHeaderAdded(opts *bind.FilterOpts, hash [][32]byte) (*InboxHeaderAddedIterator, error) {
+
+	var hashRule []interface{}
+	for _, hashItem := range hash {
+		hashRule = append(hashRule, hashItem)
+	}
+
+	logs, sub, err := _Inbox.contract.FilterLogs(opts, "HeaderAdded", hashRule)
+	if err != nil {
+		return nil, err
+	}
+	return &InboxHeaderAddedIterator{contract: _Inbox.contract, event: "HeaderAdded", logs: logs, sub: sub}, nil
+}
+
+// WatchHeaderAdded is a free log subscription operation binding the contract event 0x564befda554cb0a6afdf182ba391ce9bae1cbb9e4f24f0f50395f4b30f462cf8.
+//
+// Solidity: event HeaderAdded(bytes32 indexed hash)
+func (_Inbox *InboxFilterer) WatchHeaderAdded(opts *bind.WatchOpts, sink chan<- *InboxHeaderAdded, hash [][32]byte) (event.Subscription, error) {
+
+	var hashRule []interface{}
+	for _, hashItem := range hash {
+		hashRule = append(hashRule, hashItem)
+	}
+
+	logs, sub, err := _Inbox.contract.WatchLogs(opts, "HeaderAdded", hashRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(InboxHeaderAdded)
+				if err := _Inbox.contract.UnpackLog(event, "HeaderAdded", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseHeaderAdded is a log parse operation binding the contract event 0x564befda554cb0a6afdf182ba391ce9bae1cbb9e4f24f0f50395f4b30f462cf8.
+//
+// Solidity: event HeaderAdded(bytes32 indexed hash)
+func (_Inbox *InboxFilterer) ParseHeaderAdded(log types.Log) (*InboxHeaderAdded, error) {
+	event := new(InboxHeaderAdded)
+	if err := _Inbox.contract.UnpackLog(event, "HeaderAdded", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
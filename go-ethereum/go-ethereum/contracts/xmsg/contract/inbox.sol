@@ -0,0 +1,95 @@
+pragma solidity ^0.8.0;
+
+/**
+ * @title Inbox
+ * @dev Delivery of the messages sent from an outbox on another chain.
+ *
+ * A message is delivered with the proof of the receipt holding its Message
+ * event, verified by the receipt proof precompile against the header of the
+ * block the receipt is in. The headers of the source chain are fed to the inbox
+ * by its admin, whom the inbox trusts to only feed final blocks of that chain.
+ * The admin can be a contract implementing whatever approval the chains settle
+ * on.
+ *
+ * Every message is delivered at most once: its id is recorded as consumed
+ * before the target is called. Delivery reverts if the call does, leaving the
+ * message to be delivered again later. The target receives the calldata of the
+ * message with the address of its sender on the source chain appended, and is
+ * to check it was called by the inbox.
+ *
+ * The inbox has no constructor, for its code to be the same on every chain it
+ * is deployed on. It is initialized once after its deployment instead.
+ */
+contract Inbox {
+    // Account feeding the headers of the source chain
+    address public admin;
+
+    // Outbox on the source chain the messages are sent from
+    address public outbox;
+
+    // Hashes of the headers of the source chain fed to the inbox
+    mapping(bytes32 => bool) public known;
+
+    // Ids of the messages delivered
+    mapping(bytes32 => bool) public consumed;
+
+    event HeaderAdded(bytes32 indexed hash);
+    event Delivered(bytes32 indexed id, address indexed target);
+
+    // keccak256("Message(bytes32,address,address,uint256,address,bytes)")
+    bytes32 constant MESSAGE_TOPIC = 0x13eeab43f4cc5fe7671e4154f4807ab15607a901ee5dd6bafbe6b10764614382;
+
+    /**
+     * @dev Sets the outbox to deliver the messages of, the caller becoming the
+     * admin. It can only be called once.
+     */
+    function initialize(address _outbox) external {
+        require(outbox == address(0) && _outbox != address(0));
+
+        admin = msg.sender;
+        outbox = _outbox;
+    }
+
+    /**
+     * @dev Adds the hash of a final header of the source chain.
+     */
+    function addHeader(bytes32 hash) external {
+        require(msg.sender == admin);
+
+        known[hash] = true;
+        emit HeaderAdded(hash);
+    }
+
+    /**
+     * @dev Delivers a message to its target.
+     * @param proof RLP encoded receipt proof of the Message event, the input of
+     * the receipt proof precompile
+     */
+    function deliver(bytes calldata proof) external {
+        (bool ok, bytes memory log) = address(0x15).staticcall(proof);
+        require(ok && log.length >= 0x180);
+
+        // The precompile returns the block hash and number, the emitter, the
+        // topic count and topics of the log, then its data
+        (bytes32 blockHash, , address emitter, uint256 topics, bytes32 topic, bytes32 id, address inbox, address target) =
+            abi.decode(log, (bytes32, uint256, address, uint256, bytes32, bytes32, address, address));
+        require(known[blockHash] && emitter == outbox && topics == 4 && topic == MESSAGE_TOPIC && inbox == address(this));
+
+        (uint256 chainId, address sender, bytes memory data) = abi.decode(slice(log, 0x100), (uint256, address, bytes));
+        require(chainId == block.chainid);
+
+        require(!consumed[id]);
+        consumed[id] = true;
+        emit Delivered(id, target);
+
+        (ok, ) = target.call(abi.encodePacked(data, sender));
+        require(ok);
+    }
+
+    function slice(bytes memory data, uint256 start) internal pure returns (bytes memory out) {
+        out = new bytes(data.length - start);
+        for (uint256 i = 0; i < out.length; i++) {
+            out[i] = data[start + i];
+        }
+    }
+}
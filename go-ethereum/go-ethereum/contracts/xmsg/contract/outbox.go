@@ -0,0 +1,412 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// OutboxABI is the input ABI used to generate the binding from.
+const OutboxABI = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"bytes32\",\"name\":\"id\",\"type\":\"bytes32\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"inbox\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"target\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"bytes\",\"name\":\"data\",\"type\":\"bytes\"}],\"name\":\"Message\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"nonce\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"inbox\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"target\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"data\",\"type\":\"bytes\"}],\"name\":\"send\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"id\",\"type\":\"bytes32\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+
+// OutboxFuncSigs maps the 4-byte function signature to its string representation.
+var OutboxFuncSigs = map[string]string{
+	"affed0e0": "nonce()",
+	"80fd1c44": "send(uint256,address,address,bytes)",
+}
+
+// OutboxBin is the compiled bytecode used for deploying new contracts.
+var OutboxBin = "0x6080604052348015600f57600080fd5b506102678061001f6000396000f3fe608060405234801561001057600080fd5b50600436106100365760003560e01c806380fd1c441461003b578063affed0e014610060575b600080fd5b61004e610049366004610126565b610069565b60405190815260200160405180910390f35b61004e60005481565b60008054604080514660208083019190915230828401526060808301859052835180840390910181526080909201909252805191012091806100aa836101c3565b9190505550836001600160a01b0316856001600160a01b0316827f13eeab43f4cc5fe7671e4154f4807ab15607a901ee5dd6bafbe6b10764614382893388886040516100f994939291906101ea565b60405180910390a495945050505050565b80356001600160a01b038116811461012157600080fd5b919050565b60008060008060006080868803121561013e57600080fd5b8535945061014e6020870161010a565b935061015c6040870161010a565b9250606086013567ffffffffffffffff81111561017857600080fd5b8601601f8101881361018957600080fd5b803567ffffffffffffffff8111156101a057600080fd5b8860208284010111156101b257600080fd5b959894975092955050506020019190565b6000600182016101e357634e487b7160e01b600052601160045260246000fd5b5060010190565b8481526001600160a01b03841660208201526060604082018190528101829052818360808301376000818301608090810191909152601f909201601f19160101939250505056fea264697066735822122026f0d397f2a10a85ba553d72070d532538d0a0e680a9febb42c13a41864f795764736f6c634300081e0033"
+
+// DeployOutbox deploys a new Ethereum contract, binding an instance of Outbox to it.
+func DeployOutbox(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *Outbox, error) {
+	parsed, err := abi.JSON(strings.NewReader(OutboxABI))
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, parsed, common.FromHex(OutboxBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &Outbox{OutboxCaller: OutboxCaller{contract: contract}, OutboxTransactor: OutboxTransactor{contract: contract}, OutboxFilterer: OutboxFilterer{contract: contract}}, nil
+}
+
+// Outbox is an auto generated Go binding around an Ethereum contract.
+type Outbox struct {
+	OutboxCaller     // Read-only binding to the contract
+	OutboxTransactor // Write-only binding to the contract
+	OutboxFilterer   // Log filterer for contract events
+}
+
+// OutboxCaller is an auto generated read-only Go binding around an Ethereum contract.
+type OutboxCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// OutboxTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type OutboxTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// OutboxFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type OutboxFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// OutboxSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type OutboxSession struct {
+	Contract     *Outbox           // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// OutboxCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type OutboxCallerSession struct {
+	Contract *OutboxCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
+}
+
+// OutboxTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type OutboxTransactorSession struct {
+	Contract     *OutboxTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// OutboxRaw is an auto generated low-level Go binding around an Ethereum contract.
+type OutboxRaw struct {
+	Contract *Outbox // Generic contract binding to access the raw methods on
+}
+
+// OutboxCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type OutboxCallerRaw struct {
+	Contract *OutboxCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// OutboxTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type OutboxTransactorRaw struct {
+	Contract *OutboxTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewOutbox creates a new instance of Outbox, bound to a specific deployed contract.
+func NewOutbox(address common.Address, backend bind.ContractBackend) (*Outbox, error) {
+	contract, err := bindOutbox(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Outbox{OutboxCaller: OutboxCaller{contract: contract}, OutboxTransactor: OutboxTransactor{contract: contract}, OutboxFilterer: OutboxFilterer{contract: contract}}, nil
+}
+
+// NewOutboxCaller creates a new read-only instance of Outbox, bound to a specific deployed contract.
+func NewOutboxCaller(address common.Address, caller bind.ContractCaller) (*OutboxCaller, error) {
+	contract, err := bindOutbox(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxCaller{contract: contract}, nil
+}
+
+// NewOutboxTransactor creates a new write-only instance of Outbox, bound to a specific deployed contract.
+func NewOutboxTransactor(address common.Address, transactor bind.ContractTransactor) (*OutboxTransactor, error) {
+	contract, err := bindOutbox(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxTransactor{contract: contract}, nil
+}
+
+// NewOutboxFilterer creates a new log filterer instance of Outbox, bound to a specific deployed contract.
+func NewOutboxFilterer(address common.Address, filterer bind.ContractFilterer) (*OutboxFilterer, error) {
+	contract, err := bindOutbox(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxFilterer{contract: contract}, nil
+}
+
+// bindOutbox binds a generic wrapper to an already deployed contract.
+func bindOutbox(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(OutboxABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Outbox *OutboxRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Outbox.Contract.OutboxCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Outbox *OutboxRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Outbox.Contract.OutboxTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Outbox *OutboxRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Outbox.Contract.OutboxTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Outbox *OutboxCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Outbox.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Outbox *OutboxTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Outbox.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Outbox *OutboxTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Outbox.Contract.contract.Transact(opts, method, params...)
+}
+
+// Nonce is a free data retrieval call binding the contract method 0xaffed0e0.
+//
+// Solidity: function nonce() view returns(uint256)
+func (_Outbox *OutboxCaller) Nonce(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _Outbox.contract.Call(opts, &out, "nonce")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Nonce is a free data retrieval call binding the contract method 0xaffed0e0.
+//
+// Solidity: function nonce() view returns(uint256)
+func (_Outbox *OutboxSession) Nonce() (*big.Int, error) {
+	return _Outbox.Contract.Nonce(&_Outbox.CallOpts)
+}
+
+// Nonce is a free data retrieval call binding the contract method 0xaffed0e0.
+//
+// Solidity: function nonce() view returns(uint256)
+func (_Outbox *OutboxCallerSession) Nonce() (*big.Int, error) {
+	return _Outbox.Contract.Nonce(&_Outbox.CallOpts)
+}
+
+// Send is a paid mutator transaction binding the contract method 0x80fd1c44.
+//
+// Solidity: function send(uint256 chainId, address inbox, address target, bytes data) returns(bytes32 id)
+func (_Outbox *OutboxTransactor) Send(opts *bind.TransactOpts, chainId *big.Int, inbox common.Address, target common.Address, data []byte) (*types.Transaction, error) {
+	return _Outbox.contract.Transact(opts, "send", chainId, inbox, target, data)
+}
+
+// Send is a paid mutator transaction binding the contract method 0x80fd1c44.
+//
+// Solidity: function send(uint256 chainId, address inbox, address target, bytes data) returns(bytes32 id)
+func (_Outbox *OutboxSession) Send(chainId *big.Int, inbox common.Address, target common.Address, data []byte) (*types.Transaction, error) {
+	return _Outbox.Contract.Send(&_Outbox.TransactOpts, chainId, inbox, target, data)
+}
+
+// Send is a paid mutator transaction binding the contract method 0x80fd1c44.
+//
+// Solidity: function send(uint256 chainId, address inbox, address target, bytes data) returns(bytes32 id)
+func (_Outbox *OutboxTransactorSession) Send(chainId *big.Int, inbox common.Address, target common.Address, data []byte) (*types.Transaction, error) {
+	return _Outbox.Contract.Send(&_Outbox.TransactOpts, chainId, inbox, target, data)
+}
+
+// OutboxMessageIterator is returned from FilterMessage and is used to iterate over the raw logs and unpacked data for Message events raised by the Outbox contract.
+type OutboxMessageIterator struct {
+	Event *OutboxMessage // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *OutboxMessageIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(OutboxMessage)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(OutboxMessage)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *OutboxMessageIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *OutboxMessageIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// OutboxMessage represents a Message event raised by the Outbox contract.
+type OutboxMessage struct {
+	Id      [32]byte
+	Inbox   common.Address
+	Target  common.Address
+	ChainId *big.Int
+	Sender  common.Address
+	Data    []byte
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterMessage is a free log retrieval operation binding the contract event 0x13eeab43f4cc5fe7671e4154f4807ab15607a901ee5dd6bafbe6b10764614382.
+//
+// Solidity: event Message(bytes32 indexed id, address indexed inbox, address indexed target, uint256 chainId, address sender, bytes data)
+func (_Outbox *OutboxFilterer) FilterMessage(opts *bind.FilterOpts, id [][32]byte, inbox []common.Address, target []common.Address) (*OutboxMessageIterator, error) {
+
+	var idRule []interface{}
+	for _, idItem := range id {
+		idRule = append(idRule, idItem)
+	}
+	var inboxRule []interface{}
+	for _, inboxItem := range inbox {
+		inboxRule = append(inboxRule, inboxItem)
+	}
+	var targetRule []interface{}
+	for _, targetItem := range target {
+		targetRule = append(targetRule, targetItem)
+	}
+
+	logs, sub, err := _Outbox.contract.FilterLogs(opts, "Message", idRule, inboxRule, targetRule)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxMessageIterator{contract: _Outbox.contract, event: "Message", logs: logs, sub: sub}, nil
+}
+
+// WatchMessage is a free log subscription operation binding the contract event 0x13eeab43f4cc5fe7671e4154f4807ab15607a901ee5dd6bafbe6b10764614382.
+//
+// Solidity: event Message(bytes32 indexed id, address indexed inbox, address indexed target, uint256 chainId, address sender, bytes data)
+func (_Outbox *OutboxFilterer) WatchMessage(opts *bind.WatchOpts, sink chan<- *OutboxMessage, id [][32]byte, inbox []common.Address, target []common.Address) (event.Subscription, error) {
+
+	var idRule []interface{}
+	for _, idItem := range id {
+		idRule = append(idRule, idItem)
+	}
+	var inboxRule []interface{}
+	for _, inboxItem := range inbox {
+		inboxRule = append(inboxRule, inboxItem)
+	}
+	var targetRule []interface{}
+	for _, targetItem := range target {
+		targetRule = append(targetRule, targetItem)
+	}
+
+	logs, sub, err := _Outbox.contract.WatchLogs(opts, "Message", idRule, inboxRule, targetRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(OutboxMessage)
+				if err := _Outbox.contract.UnpackLog(event, "Message", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseMessage is a log parse operation binding the contract event 0x13eeab43f4cc5fe7671e4154f4807ab15607a901ee5dd6bafbe6b10764614382.
+//
+// Solidity: event Message(bytes32 indexed id, address indexed inbox, address indexed target, uint256 chainId, address sender, bytes data)
+func (_Outbox *OutboxFilterer) ParseMessage(log types.Log) (*OutboxMessage, error) {
+	event := new(OutboxMessage)
+	if err := _Outbox.contract.UnpackLog(event, "Message", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,33 @@
+pragma solidity ^0.8.0;
+
+/**
+ * @title Outbox
+ * @dev Messages sent from this chain to contracts on other chains.
+ *
+ * Sending a message emits a Message event, which is all there is to it: the
+ * receipt holding the event is proven to the inbox on the destination chain,
+ * which delivers the message to its target. The id of a message derives from
+ * the chain, the outbox and the sequence number of the message, so no two
+ * messages of an outbox share one.
+ */
+contract Outbox {
+    // Number of messages sent
+    uint256 public nonce;
+
+    event Message(bytes32 indexed id, address indexed inbox, address indexed target, uint256 chainId, address sender, bytes data);
+
+    /**
+     * @dev Sends a message to a contract on another chain.
+     * @param chainId id of the destination chain
+     * @param inbox inbox delivering the message on the destination chain
+     * @param target contract to call with the message
+     * @param data calldata of the call, to which the inbox appends the sender
+     * @return id identifier of the message
+     */
+    function send(uint256 chainId, address inbox, address target, bytes calldata data) external returns (bytes32 id) {
+        id = keccak256(abi.encode(block.chainid, address(this), nonce));
+        nonce++;
+
+        emit Message(id, inbox, target, chainId, msg.sender, data);
+    }
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package xmsg is the on-chain side of the messages passed across chains: the
+// outbox contract messages are sent from, announcing them with Message events,
+// and the inbox contract delivering them on the destination chain once proven
+// with the receipts holding the events.
+package xmsg
+
+//go:generate abigen --sol contract/outbox.sol --pkg contract --out contract/outbox.go
+//go:generate abigen --sol contract/inbox.sol --pkg contract --out contract/inbox.go
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xmsg/contract"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// MessageTopic is the topic of the Message events of the outbox, hardcoded in
+// contract/inbox.sol.
+var MessageTopic = crypto.Keccak256Hash([]byte("Message(bytes32,address,address,uint256,address,bytes)"))
+
+// MessageID returns the id of the message sent from an outbox with the given
+// nonce.
+func MessageID(chainID *big.Int, outbox common.Address, nonce uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(chainID.Bytes(), 32),
+		common.LeftPadBytes(outbox[:], 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(nonce).Bytes(), 32),
+	)
+}
+
+// Outbox is a Go wrapper around an outbox contract.
+type Outbox struct {
+	address  common.Address
+	contract *contract.Outbox
+}
+
+// NewOutbox binds an outbox contract.
+func NewOutbox(address common.Address, backend bind.ContractBackend) (*Outbox, error) {
+	c, err := contract.NewOutbox(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Outbox{address: address, contract: c}, nil
+}
+
+// DeployOutbox sends the transaction deploying an outbox.
+func DeployOutbox(opts *bind.TransactOpts, backend bind.ContractBackend) (*Outbox, *types.Transaction, error) {
+	address, tx, c, err := contract.DeployOutbox(opts, backend)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Outbox{address: address, contract: c}, tx, nil
+}
+
+// Address returns the address of the contract.
+func (o *Outbox) Address() common.Address {
+	return o.address
+}
+
+// Contract returns the underlying contract instance.
+func (o *Outbox) Contract() *contract.Outbox {
+	return o.contract
+}
+
+// Send sends a message to a target contract through the inbox of another chain.
+func (o *Outbox) Send(opts *bind.TransactOpts, chainID *big.Int, inbox, target common.Address, data []byte) (*types.Transaction, error) {
+	return o.contract.Send(opts, chainID, inbox, target, data)
+}
+
+// LookupMessages returns the messages the outbox announced in the given logs.
+func (o *Outbox) LookupMessages(logs []*types.Log) []*contract.OutboxMessage {
+	var msgs []*contract.OutboxMessage
+	for _, log := range logs {
+		if log.Address != o.address || len(log.Topics) == 0 || log.Topics[0] != MessageTopic {
+			continue
+		}
+		msg, err := o.contract.ParseMessage(*log)
+		if err != nil {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// Inbox is a Go wrapper around an inbox contract.
+type Inbox struct {
+	address  common.Address
+	contract *contract.Inbox
+}
+
+// NewInbox binds an inbox contract.
+func NewInbox(address common.Address, backend bind.ContractBackend) (*Inbox, error) {
+	c, err := contract.NewInbox(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Inbox{address: address, contract: c}, nil
+}
+
+// DeployInbox sends the transactions deploying an inbox and initializing it with
+// the outbox on the source chain to deliver the messages of. The account of the
+// given options becomes the admin feeding the inbox with source headers. The
+// inbox is usable once both transactions are mined.
+func DeployInbox(opts *bind.TransactOpts, backend bind.ContractBackend, outbox common.Address) (*Inbox, []*types.Transaction, error) {
+	address, deployTx, c, err := contract.DeployInbox(opts, backend)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Chain the nonces explicitly, the pending nonce of the backend might not
+	// account for the deployment just sent yet
+	init := *opts
+	init.Nonce = new(big.Int).SetUint64(deployTx.Nonce() + 1)
+
+	initTx, err := c.Initialize(&init, outbox)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Inbox{address: address, contract: c}, []*types.Transaction{deployTx, initTx}, nil
+}
+
+// Address returns the address of the contract.
+func (i *Inbox) Address() common.Address {
+	return i.address
+}
+
+// Contract returns the underlying contract instance.
+func (i *Inbox) Contract() *contract.Inbox {
+	return i.contract
+}
+
+// Deliver delivers the message whose event a receipt proof proves.
+func (i *Inbox) Deliver(opts *bind.TransactOpts, proof *vm.ReceiptLogProof) (*types.Transaction, error) {
+	input, err := rlp.EncodeToBytes(proof)
+	if err != nil {
+		return nil, err
+	}
+	return i.contract.Deliver(opts, input)
+}
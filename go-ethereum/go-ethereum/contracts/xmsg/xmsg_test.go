@@ -0,0 +1,307 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xmsg
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xmsg/contract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/xcall"
+)
+
+var (
+	keyA, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	keyB, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	addrA   = crypto.PubkeyToAddress(keyA.PublicKey)
+	addrB   = crypto.PubkeyToAddress(keyB.PublicKey)
+
+	chainID = big.NewInt(1337)
+
+	// recorderCode logs its calldata under the topic of its caller.
+	recorderCode = common.FromHex("61000c80600c6000396000f336600060003733366000a100")
+
+	// reverterCode reverts on any call.
+	reverterCode = common.FromHex("61000580600c6000396000f360006000fd")
+)
+
+func transactor(key *ecdsa.PrivateKey) *bind.TransactOpts {
+	opts, _ := bind.NewKeyedTransactorWithChainID(key, chainID)
+	return opts
+}
+
+// msgTester is an outbox and an inbox delivering its messages, deployed on the
+// same simulated chain.
+type msgTester struct {
+	t      *testing.T
+	sim    *backends.SimulatedBackend
+	outbox *Outbox
+	inbox  *Inbox
+
+	recorder common.Address
+	reverter common.Address
+}
+
+func newMsgTester(t *testing.T) *msgTester {
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		addrA: {Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)},
+		addrB: {Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)},
+	}, 10000000)
+
+	tt := &msgTester{t: t, sim: sim}
+	outbox, _, err := DeployOutbox(transactor(keyA), sim)
+	if err != nil {
+		t.Fatalf("failed to deploy outbox: %v", err)
+	}
+	sim.Commit()
+	inbox, _, err := DeployInbox(transactor(keyA), sim, outbox.Address())
+	if err != nil {
+		t.Fatalf("failed to deploy inbox: %v", err)
+	}
+	sim.Commit()
+	tt.outbox, tt.inbox = outbox, inbox
+	tt.recorder = tt.create(recorderCode)
+	tt.reverter = tt.create(reverterCode)
+	return tt
+}
+
+// create deploys raw contract code.
+func (tt *msgTester) create(code []byte) common.Address {
+	ctx := context.Background()
+	nonce, _ := tt.sim.PendingNonceAt(ctx, addrA)
+	tx, _ := types.SignTx(types.NewContractCreation(nonce, new(big.Int), 100000, big.NewInt(1), code), types.NewEIP155Signer(chainID), keyA)
+	if err := tt.sim.SendTransaction(ctx, tx); err != nil {
+		tt.t.Fatalf("failed to deploy code: %v", err)
+	}
+	tt.sim.Commit()
+	return crypto.CreateAddress(addrA, nonce)
+}
+
+// send sends a message from B, returning it along with the proof of its event.
+func (tt *msgTester) send(chain *big.Int, inbox, target common.Address, data []byte) (*contract.OutboxMessage, *vm.ReceiptLogProof) {
+	return tt.sendFrom(tt.outbox, chain, inbox, target, data)
+}
+
+func (tt *msgTester) sendFrom(outbox *Outbox, chain *big.Int, inbox, target common.Address, data []byte) (*contract.OutboxMessage, *vm.ReceiptLogProof) {
+	tx, err := outbox.Send(transactor(keyB), chain, inbox, target, data)
+	if err != nil {
+		tt.t.Fatalf("failed to send message: %v", err)
+	}
+	tt.sim.Commit()
+
+	receipt, _ := tt.sim.TransactionReceipt(context.Background(), tx.Hash())
+	msgs := outbox.LookupMessages(receipt.Logs)
+	if len(msgs) != 1 {
+		tt.t.Fatalf("message count mismatch: have %d, want 1", len(msgs))
+	}
+	return msgs[0], tt.prove(receipt, 0)
+}
+
+// prove creates the proof of a log of a receipt.
+func (tt *msgTester) prove(receipt *types.Receipt, logIndex uint64) *vm.ReceiptLogProof {
+	ctx := context.Background()
+	proof, err := xcall.ProveReceipt(ctx, tt.sim, receipt)
+	if err != nil {
+		tt.t.Fatalf("failed to prove receipt: %v", err)
+	}
+	header, _ := tt.sim.HeaderByHash(ctx, receipt.BlockHash)
+	enc, _ := rlp.EncodeToBytes(header)
+
+	nodes := make([][]byte, len(proof.Nodes))
+	for i, node := range proof.Nodes {
+		nodes[i] = node
+	}
+	return &vm.ReceiptLogProof{Header: enc, Index: proof.Index, Nodes: nodes, LogIndex: logIndex}
+}
+
+// deliver delivers a message, returning the receipt of the delivery or nil if
+// it was refused.
+func (tt *msgTester) deliver(proof *vm.ReceiptLogProof) *types.Receipt {
+	opts := transactor(keyA)
+	opts.GasLimit = 1000000
+	tx, err := tt.inbox.Deliver(opts, proof)
+	if err != nil {
+		tt.t.Fatalf("failed to send delivery: %v", err)
+	}
+	tt.sim.Commit()
+	receipt, _ := tt.sim.TransactionReceipt(context.Background(), tx.Hash())
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil
+	}
+	return receipt
+}
+
+// addHeader feeds the inbox with the header a proof is checked against.
+func (tt *msgTester) addHeader(proof *vm.ReceiptLogProof) {
+	if _, err := tt.inbox.Contract().AddHeader(transactor(keyA), crypto.Keccak256Hash(proof.Header)); err != nil {
+		tt.t.Fatalf("failed to add header: %v", err)
+	}
+	tt.sim.Commit()
+}
+
+func (tt *msgTester) consumed(id common.Hash) bool {
+	consumed, err := tt.inbox.Contract().Consumed(nil, id)
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve consumed status: %v", err)
+	}
+	return consumed
+}
+
+// Tests that messages are delivered exactly once to their target, and only once
+// proven in a block of the source chain known to the inbox.
+func TestDeliver(t *testing.T) {
+	tt := newMsgTester(t)
+	defer tt.sim.Close()
+
+	if admin, _ := tt.inbox.Contract().Admin(nil); admin != addrA {
+		t.Errorf("admin mismatch: have %x, want %x", admin, addrA)
+	}
+	if outbox, _ := tt.inbox.Contract().Outbox(nil); outbox != tt.outbox.Address() {
+		t.Errorf("outbox mismatch: have %x, want %x", outbox, tt.outbox.Address())
+	}
+	// The inbox can't be reinitialized
+	if _, err := tt.inbox.Contract().Initialize(transactor(keyB), addrB); err == nil {
+		t.Errorf("reinitialized inbox")
+	}
+	data := []byte("cross-chain message")
+	msg, proof := tt.send(chainID, tt.inbox.Address(), tt.recorder, data)
+	if want := MessageID(chainID, tt.outbox.Address(), 0); msg.Id != want {
+		t.Errorf("message id mismatch: have %x, want %x", msg.Id, want)
+	}
+	if msg.Sender != addrB || msg.Target != tt.recorder || msg.Inbox != tt.inbox.Address() || msg.ChainId.Cmp(chainID) != 0 || !bytes.Equal(msg.Data, data) {
+		t.Errorf("message mismatch: %+v", msg)
+	}
+	if nonce, _ := tt.outbox.Contract().Nonce(nil); nonce.Uint64() != 1 {
+		t.Errorf("nonce mismatch: have %d, want 1", nonce)
+	}
+	// Messages of unknown blocks are refused, and only the admin feeds headers
+	if tt.deliver(proof) != nil {
+		t.Fatalf("delivered message of unknown block")
+	}
+	if _, err := tt.inbox.Contract().AddHeader(transactor(keyB), crypto.Keccak256Hash(proof.Header)); err == nil {
+		t.Errorf("header added by other than the admin")
+	}
+	tt.addHeader(proof)
+	if known, _ := tt.inbox.Contract().Known(nil, crypto.Keccak256Hash(proof.Header)); !known {
+		t.Fatalf("header not known")
+	}
+	// Tampered proofs are refused
+	tampered := *proof
+	tampered.Index++
+	if tt.deliver(&tampered) != nil {
+		t.Errorf("delivered message with tampered proof")
+	}
+	// The message is delivered with its sender appended, and only once
+	receipt := tt.deliver(proof)
+	if receipt == nil {
+		t.Fatalf("message refused")
+	}
+	var delivered bool
+	for _, log := range receipt.Logs {
+		if log.Address == tt.recorder {
+			delivered = true
+			if log.Topics[0] != common.BytesToHash(tt.inbox.Address().Bytes()) {
+				t.Errorf("caller mismatch: have %x, want %x", log.Topics[0], tt.inbox.Address())
+			}
+			if want := append(append([]byte{}, data...), addrB.Bytes()...); !bytes.Equal(log.Data, want) {
+				t.Errorf("calldata mismatch: have %x, want %x", log.Data, want)
+			}
+		}
+	}
+	if !delivered {
+		t.Errorf("target not called")
+	}
+	if !tt.consumed(msg.Id) {
+		t.Errorf("message not consumed")
+	}
+	if tt.deliver(proof) != nil {
+		t.Errorf("message delivered twice")
+	}
+}
+
+// Tests that the inbox only delivers the messages sent to it from its outbox.
+func TestDeliverForeign(t *testing.T) {
+	tt := newMsgTester(t)
+	defer tt.sim.Close()
+
+	other, _, err := DeployOutbox(transactor(keyA), tt.sim)
+	if err != nil {
+		t.Fatalf("failed to deploy outbox: %v", err)
+	}
+	tt.sim.Commit()
+
+	for i, send := range []func() (*contract.OutboxMessage, *vm.ReceiptLogProof){
+		func() (*contract.OutboxMessage, *vm.ReceiptLogProof) {
+			return tt.sendFrom(other, chainID, tt.inbox.Address(), tt.recorder, nil)
+		},
+		func() (*contract.OutboxMessage, *vm.ReceiptLogProof) {
+			return tt.send(big.NewInt(1), tt.inbox.Address(), tt.recorder, nil)
+		},
+		func() (*contract.OutboxMessage, *vm.ReceiptLogProof) {
+			return tt.send(chainID, tt.recorder, tt.recorder, nil)
+		},
+	} {
+		msg, proof := send()
+		tt.addHeader(proof)
+		if tt.deliver(proof) != nil {
+			t.Errorf("test %d: delivered foreign message", i)
+		}
+		if tt.consumed(msg.Id) {
+			t.Errorf("test %d: foreign message consumed", i)
+		}
+	}
+	// Logs other than messages aren't delivered either, even if naming the inbox
+	_, proof := tt.send(chainID, tt.inbox.Address(), tt.recorder, nil)
+	tt.addHeader(proof)
+	receipt := tt.deliver(proof)
+	if receipt == nil {
+		t.Fatalf("message refused")
+	}
+	for i := range receipt.Logs {
+		proof := tt.prove(receipt, uint64(i))
+		tt.addHeader(proof)
+		if tt.deliver(proof) != nil {
+			t.Errorf("delivered log %d of delivery", i)
+		}
+	}
+}
+
+// Tests that messages whose call fails are left to be delivered again.
+func TestDeliverFailed(t *testing.T) {
+	tt := newMsgTester(t)
+	defer tt.sim.Close()
+
+	msg, proof := tt.send(chainID, tt.inbox.Address(), tt.reverter, []byte("fail"))
+	tt.addHeader(proof)
+	if tt.deliver(proof) != nil {
+		t.Fatalf("delivered message with failing call")
+	}
+	if tt.consumed(msg.Id) {
+		t.Errorf("failed message consumed")
+	}
+}
@@ -130,6 +130,7 @@ type CacheConfig struct {
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
+	ExtendedReceipts    bool          // Whether to store the extended receipts of processed transactions
 
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
@@ -179,9 +180,12 @@ type BlockChain struct {
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
+	headerFeed    event.Feed
 	logsFeed      event.Feed
 	blockProcFeed event.Feed
+	stateDiffFeed event.Feed
 	scope         event.SubscriptionScope
+	diffScope     event.SubscriptionScope // State diffs are only recorded while subscribed to
 	genesisBlock  *types.Block
 
 	chainmu sync.RWMutex // blockchain insertion lock
@@ -598,6 +602,8 @@ func (bc *BlockChain) SetHeadBeyondRoot(head uint64, root common.Hash) (uint64,
 			rawdb.DeleteBody(db, hash, num)
 			rawdb.DeleteReceipts(db, hash, num)
 		}
+		// Extended receipts are never frozen, remove them from the active store
+		rawdb.DeleteExtendedReceipts(db, hash, num)
 		// Todo(rjl493456442) txlookup, bloombits, etc
 	}
 	// If SetHead was only called as a chain reparation method, try to skip
@@ -935,6 +941,16 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	return receipts
 }
 
+// GetExtendedReceiptsByHash retrieves the extended receipts for all transactions
+// in a given block, nil if they weren't recorded.
+func (bc *BlockChain) GetExtendedReceiptsByHash(hash common.Hash) types.ExtendedReceipts {
+	number := rawdb.ReadHeaderNumber(bc.db, hash)
+	if number == nil {
+		return nil
+	}
+	return rawdb.ReadExtendedReceipts(bc.db, hash, *number)
+}
+
 // GetBlocksFromHash returns the block corresponding to hash and up to n-1 ancestors.
 // [deprecated by eth/62]
 func (bc *BlockChain) GetBlocksFromHash(hash common.Hash, n int) (blocks []*types.Block) {
@@ -997,6 +1013,7 @@ func (bc *BlockChain) Stop() {
 	}
 	// Unsubscribe all subscriptions registered from blockchain
 	bc.scope.Close()
+	bc.diffScope.Close()
 	close(bc.quit)
 	bc.StopInsert()
 	bc.wg.Wait()
@@ -1525,6 +1542,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteTd(blockBatch, block.Hash(), block.NumberU64(), externTd)
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	if bc.cacheConfig.ExtendedReceipts {
+		rawdb.WriteExtendedReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	}
 	rawdb.WritePreimages(blockBatch, state.Preimages())
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
@@ -1628,6 +1648,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 		if len(logs) > 0 {
 			bc.logsFeed.Send(logs)
 		}
+		if diff := state.StateDiff(); diff != nil {
+			bc.stateDiffFeed.Send(StateDiffEvent{Block: block, Diff: diff})
+		}
 		// In theory we should fire a ChainHeadEvent when we inject
 		// a canonical block, but sometimes we can insert a batch of
 		// canonicial blocks. Avoid firing too much ChainHeadEvents,
@@ -1635,6 +1658,7 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 		// event here.
 		if emitHeadEvent {
 			bc.chainHeadFeed.Send(ChainHeadEvent{Block: block})
+			bc.headerFeed.Send(block.Header())
 		}
 	} else {
 		bc.chainSideFeed.Send(ChainSideEvent{Block: block})
@@ -1720,6 +1744,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 	defer func() {
 		if lastCanon != nil && bc.CurrentBlock().Hash() == lastCanon.Hash() {
 			bc.chainHeadFeed.Send(ChainHeadEvent{lastCanon})
+			bc.headerFeed.Send(lastCanon.Header())
 		}
 	}()
 	// Start the parallel header verifier
@@ -1868,6 +1893,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		if err != nil {
 			return it.index, err
 		}
+		if bc.RecordsStateDiffs() {
+			statedb.StartDiff()
+		}
 		// If we have a followup block, run that against the current state to pre-cache
 		// transactions and probabilistically some of the account/storage trie nodes.
 		var followupInterrupt uint32
@@ -2541,6 +2569,12 @@ func (bc *BlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Su
 	return bc.scope.Track(bc.chainHeadFeed.Subscribe(ch))
 }
 
+// SubscribeChainHeaderEvent registers a subscription for the header of every new
+// canonical chain head, implementing consensus.ChainHeadSubscriber.
+func (bc *BlockChain) SubscribeChainHeaderEvent(ch chan<- *types.Header) event.Subscription {
+	return bc.scope.Track(bc.headerFeed.Subscribe(ch))
+}
+
 // SubscribeChainSideEvent registers a subscription of ChainSideEvent.
 func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Subscription {
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
@@ -2551,6 +2585,18 @@ func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscript
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
 }
 
+// SubscribeStateDiffEvent registers a subscription of StateDiffEvent. The state
+// diffs of blocks are only recorded while there are subscribers.
+func (bc *BlockChain) SubscribeStateDiffEvent(ch chan<- StateDiffEvent) event.Subscription {
+	return bc.diffScope.Track(bc.stateDiffFeed.Subscribe(ch))
+}
+
+// RecordsStateDiffs returns whether the state diffs of blocks written into the
+// chain are to be recorded, for anyone subscribed to them.
+func (bc *BlockChain) RecordsStateDiffs() bool {
+	return bc.diffScope.Count() > 0
+}
+
 // SubscribeBlockProcessingEvent registers a subscription of bool where true means
 // block processing has started while false means it has stopped.
 func (bc *BlockChain) SubscribeBlockProcessingEvent(ch chan<- bool) event.Subscription {
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -3030,3 +3031,123 @@ func TestInitThenFailCreateContract(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the extended receipts of processed transactions are only stored if
+// enabled, recording the outcome of their topmost calls.
+func TestExtendedReceipts(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		reverter = common.HexToAddress("0xaaaa")
+		gspec    = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				addr: {Balance: big.NewInt(1000000000000000)},
+				// Reverts with the single byte 0xaa: MSTORE8(0, 0xaa) REVERT(0, 1)
+				reverter: {Code: common.FromHex("60aa60005360016000fd"), Balance: common.Big0},
+			},
+		}
+	)
+	for _, enabled := range []bool{false, true} {
+		db := rawdb.NewMemoryDatabase()
+		genesis := gspec.MustCommit(db)
+
+		blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, b *BlockGen) {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), reverter, new(big.Int), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+			b.AddTx(tx)
+		})
+		cacheConfig := *defaultCacheConfig
+		cacheConfig.ExtendedReceipts = enabled
+
+		chain, err := NewBlockChain(db, &cacheConfig, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create tester chain: %v", err)
+		}
+		if _, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("failed to insert chain: %v", err)
+		}
+		extended := chain.GetExtendedReceiptsByHash(blocks[0].Hash())
+		chain.Stop()
+
+		if !enabled {
+			if extended != nil {
+				t.Fatalf("extended receipts stored while disabled: %v", extended)
+			}
+			continue
+		}
+		if len(extended) != 1 {
+			t.Fatalf("extended receipt count mismatch: have %d, want 1", len(extended))
+		}
+		if extended[0].Err != vm.ErrExecutionReverted.Error() {
+			t.Errorf("error mismatch: have %q, want %q", extended[0].Err, vm.ErrExecutionReverted)
+		}
+		if !bytes.Equal(extended[0].ReturnData, []byte{0xaa}) {
+			t.Errorf("return data mismatch: have %x, want aa", extended[0].ReturnData)
+		}
+		// 4 pushes, the MSTORE8 and one word of memory, REVERT itself is free
+		if extended[0].CallGas != 18 {
+			t.Errorf("call gas mismatch: have %d, want %d", extended[0].CallGas, 18)
+		}
+	}
+}
+
+// Tests that the state diffs of imported blocks are only recorded while being
+// subscribed to, and that they report the changes made by the block.
+func TestStateDiffEvents(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		to      = common.Address{0x01}
+		funds   = big.NewInt(1000000000000000)
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr: {Balance: funds}}}
+		db      = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, func(i int, b *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), to, big.NewInt(1000), params.TxGas, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if chain.RecordsStateDiffs() {
+		t.Fatalf("state diffs recorded without subscribers")
+	}
+	if _, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	diffs := make(chan StateDiffEvent, 1)
+	sub := chain.SubscribeStateDiffEvent(diffs)
+	if !chain.RecordsStateDiffs() {
+		t.Fatalf("state diffs not recorded with subscribers")
+	}
+	if _, err := chain.InsertChain(blocks[1:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	select {
+	case ev := <-diffs:
+		if ev.Block.Hash() != blocks[1].Hash() {
+			t.Fatalf("diff of wrong block: have %x, want %x", ev.Block.Hash(), blocks[1].Hash())
+		}
+		sender := ev.Diff[addr]
+		if sender == nil || sender.Nonce == nil || sender.Nonce.From != 1 || sender.Nonce.To != 2 {
+			t.Errorf("sender nonce diff mismatch: have %+v", sender)
+		}
+		recipient := ev.Diff[to]
+		if recipient == nil || recipient.Balance == nil || recipient.Balance.From.ToInt().Int64() != 1000 || recipient.Balance.To.ToInt().Int64() != 2000 {
+			t.Errorf("recipient balance diff mismatch: have %+v", recipient)
+		}
+		if _, ok := ev.Diff[blocks[1].Coinbase()]; !ok {
+			t.Errorf("coinbase reward missing from diff")
+		}
+	default:
+		t.Fatalf("no state diff event for imported block")
+	}
+	sub.Unsubscribe()
+	if chain.RecordsStateDiffs() {
+		t.Fatalf("state diffs recorded after unsubscribing")
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// bundlePoolSlots is the maximum number of bundles tracked by the bundle pool.
+	bundlePoolSlots = 1024
+
+	// bundleMaxTxs is the maximum number of transactions a single bundle may
+	// contain, bounding the cost of simulating it for every block.
+	bundleMaxTxs = 64
+)
+
+var (
+	// ErrBundleEmpty is returned if a bundle without any transactions is submitted.
+	ErrBundleEmpty = errors.New("empty bundle")
+
+	// ErrBundleOversized is returned if a bundle contains more transactions than
+	// the pool accepts.
+	ErrBundleOversized = errors.New("bundle exceeds transaction limit")
+
+	// ErrBundleRange is returned if a bundle's target block range is inverted.
+	ErrBundleRange = errors.New("invalid bundle block range")
+
+	// ErrBundleExpired is returned if a bundle's target block range is already
+	// behind the chain head.
+	ErrBundleExpired = errors.New("bundle block range expired")
+
+	// ErrBundleKnown is returned if the bundle is already contained in the pool.
+	ErrBundleKnown = errors.New("bundle already known")
+
+	// ErrBundlePoolFull is returned if the bundle pool has no room for new bundles.
+	ErrBundlePoolFull = errors.New("bundle pool is full")
+)
+
+var (
+	bundleGauge         = metrics.NewRegisteredGauge("bundlepool/bundles", nil)
+	bundleDiscardMeter  = metrics.NewRegisteredMeter("bundlepool/discard", nil)  // Dropped due to a failed simulation
+	bundleEvictionMeter = metrics.NewRegisteredMeter("bundlepool/eviction", nil) // Dropped due to an expired block range
+	bundleMinedMeter    = metrics.NewRegisteredMeter("bundlepool/mined", nil)    // Dropped due to the transactions being mined
+)
+
+// Bundle is an ordered list of transactions that has to be included into a block
+// atomically: either all of them in the given order, or none at all.
+type Bundle struct {
+	Txs       types.Transactions // Transactions to include, in order
+	MinBlock  uint64             // First block number the bundle may be included in
+	MaxBlock  uint64             // Last block number the bundle may be included in
+	Reverting []common.Hash      // Transactions allowed to revert without failing the bundle
+
+	hash common.Hash
+	seq  uint64 // Arrival order within the pool
+}
+
+// Hash returns the identifier of the bundle, the hash of its transaction hashes.
+func (b *Bundle) Hash() common.Hash {
+	if b.hash == (common.Hash{}) {
+		hashes := make([][]byte, len(b.Txs))
+		for i, tx := range b.Txs {
+			hashes[i] = tx.Hash().Bytes()
+		}
+		b.hash = crypto.Keccak256Hash(hashes...)
+	}
+	return b.hash
+}
+
+// CanRevert reports whether the revert policy of the bundle allows the given
+// transaction to revert without invalidating the whole bundle.
+func (b *Bundle) CanRevert(hash common.Hash) bool {
+	for _, reverting := range b.Reverting {
+		if reverting == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// BundlePool tracks the transaction bundles waiting to be included by the miner.
+// Bundles are kept aside from the transaction pool: their transactions are not
+// propagated to the network, nor are they subject to the pool's pricing rules.
+type BundlePool struct {
+	chain    blockChain
+	signer   types.Signer
+	gasPrice *big.Int // Minimum gas price of the bundled transactions
+
+	bundles map[common.Hash]*Bundle
+	seq     uint64
+	mu      sync.RWMutex
+}
+
+// NewBundlePool creates a new bundle pool on top of the given chain, accepting
+// transactions paying at least the given gas price.
+func NewBundlePool(chainconfig *params.ChainConfig, chain blockChain, priceLimit uint64) *BundlePool {
+	return &BundlePool{
+		chain:    chain,
+		signer:   types.LatestSigner(chainconfig),
+		gasPrice: new(big.Int).SetUint64(priceLimit),
+		bundles:  make(map[common.Hash]*Bundle),
+	}
+}
+
+// SetGasPrice updates the minimum gas price required of the transactions of new
+// bundles. Bundles already pooled are kept.
+func (pool *BundlePool) SetGasPrice(price *big.Int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.gasPrice = new(big.Int).Set(price)
+	log.Info("Bundle pool price threshold updated", "price", price)
+}
+
+// Add validates a bundle and inserts it into the pool. The transactions are only
+// checked for valid signatures and prices, their executability is verified by
+// the miner when simulating the bundle on top of the pending block.
+func (pool *BundlePool) Add(bundle *Bundle) error {
+	if len(bundle.Txs) == 0 {
+		return ErrBundleEmpty
+	}
+	if len(bundle.Txs) > bundleMaxTxs {
+		return ErrBundleOversized
+	}
+	if bundle.MinBlock > bundle.MaxBlock {
+		return ErrBundleRange
+	}
+	head := pool.chain.CurrentBlock().NumberU64()
+	if bundle.MaxBlock <= head {
+		return ErrBundleExpired
+	}
+	for _, tx := range bundle.Txs {
+		if _, err := types.Sender(pool.signer, tx); err != nil {
+			return ErrInvalidSender
+		}
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for _, tx := range bundle.Txs {
+		if tx.GasPriceIntCmp(pool.gasPrice) < 0 {
+			return ErrUnderpriced
+		}
+	}
+	hash := bundle.Hash()
+	if pool.bundles[hash] != nil {
+		return ErrBundleKnown
+	}
+	if len(pool.bundles) >= bundlePoolSlots {
+		pool.prune(head + 1)
+		if len(pool.bundles) >= bundlePoolSlots {
+			return ErrBundlePoolFull
+		}
+	}
+	pool.seq++
+	bundle.seq = pool.seq
+	pool.bundles[hash] = bundle
+	bundleGauge.Update(int64(len(pool.bundles)))
+
+	log.Trace("Pooled new transaction bundle", "hash", hash, "txs", len(bundle.Txs), "min", bundle.MinBlock, "max", bundle.MaxBlock)
+	return nil
+}
+
+// Remove drops a bundle from the pool, typically after a failed simulation.
+func (pool *BundlePool) Remove(hash common.Hash) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.bundles[hash] != nil {
+		delete(pool.bundles, hash)
+		bundleDiscardMeter.Mark(1)
+		bundleGauge.Update(int64(len(pool.bundles)))
+	}
+}
+
+// Reset drops the bundles that have already been mined into the chain whose
+// state is given, detected by their transactions' nonces being used up. Such
+// bundles can never apply again, so they are not counted as discarded.
+func (pool *BundlePool) Reset(statedb *state.StateDB) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for hash, bundle := range pool.bundles {
+		for _, tx := range bundle.Txs {
+			from, _ := types.Sender(pool.signer, tx) // already validated
+			if tx.Nonce() < statedb.GetNonce(from) {
+				delete(pool.bundles, hash)
+				bundleMinedMeter.Mark(1)
+				break
+			}
+		}
+	}
+	bundleGauge.Update(int64(len(pool.bundles)))
+}
+
+// Bundles retrieves the bundles that may be included into the block with the
+// given number, in their order of arrival. Bundles whose block range ended before
+// the given block are evicted.
+func (pool *BundlePool) Bundles(number uint64) []*Bundle {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.prune(number)
+
+	var bundles []*Bundle
+	for _, bundle := range pool.bundles {
+		if bundle.MinBlock <= number {
+			bundles = append(bundles, bundle)
+		}
+	}
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].seq < bundles[j].seq })
+	return bundles
+}
+
+// Len returns the number of bundles currently tracked.
+func (pool *BundlePool) Len() int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return len(pool.bundles)
+}
+
+// prune evicts all the bundles that can no longer be included into the block
+// with the given number or any later one.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *BundlePool) prune(number uint64) {
+	for hash, bundle := range pool.bundles {
+		if bundle.MaxBlock < number {
+			delete(pool.bundles, hash)
+			bundleEvictionMeter.Mark(1)
+		}
+	}
+	bundleGauge.Update(int64(len(pool.bundles)))
+}
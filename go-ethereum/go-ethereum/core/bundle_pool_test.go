@@ -0,0 +1,123 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that bundles are validated on insertion and served for the blocks
+// within their target range only, in their order of arrival.
+func TestBundlePool(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	pool := NewBundlePool(params.TestChainConfig, &testBlockChain{statedb, 1000000, new(event.Feed)}, 1)
+
+	key, _ := crypto.GenerateKey()
+	unsigned := types.NewTransaction(0, common.Address{}, nil, 21000, nil, nil)
+
+	oversized := make(types.Transactions, bundleMaxTxs+1)
+	for i := range oversized {
+		oversized[i] = transaction(uint64(i), 21000, key)
+	}
+
+	tests := []struct {
+		bundle *Bundle
+		err    error
+	}{
+		{&Bundle{MinBlock: 1, MaxBlock: 1}, ErrBundleEmpty},
+		{&Bundle{Txs: types.Transactions{transaction(0, 21000, key)}, MinBlock: 2, MaxBlock: 1}, ErrBundleRange},
+		{&Bundle{Txs: types.Transactions{transaction(0, 21000, key)}, MinBlock: 0, MaxBlock: 0}, ErrBundleExpired},
+		{&Bundle{Txs: types.Transactions{unsigned}, MinBlock: 1, MaxBlock: 1}, ErrInvalidSender},
+		{&Bundle{Txs: oversized, MinBlock: 1, MaxBlock: 1}, ErrBundleOversized},
+		{&Bundle{Txs: types.Transactions{transaction(0, 21000, key), pricedTransaction(1, 21000, new(big.Int), key)}, MinBlock: 1, MaxBlock: 1}, ErrUnderpriced},
+		{&Bundle{Txs: types.Transactions{transaction(0, 21000, key), transaction(1, 21000, key)}, MinBlock: 1, MaxBlock: 2}, nil},
+		{&Bundle{Txs: types.Transactions{transaction(0, 21000, key), transaction(1, 21000, key)}, MinBlock: 1, MaxBlock: 2}, ErrBundleKnown},
+		{&Bundle{Txs: types.Transactions{transaction(2, 21000, key)}, MinBlock: 2, MaxBlock: 3}, nil},
+	}
+	for i, tt := range tests {
+		if err := pool.Add(tt.bundle); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	first, second := tests[6].bundle, tests[8].bundle
+
+	check := func(number uint64, want ...*Bundle) {
+		t.Helper()
+
+		have := pool.Bundles(number)
+		if len(have) != len(want) {
+			t.Fatalf("block %d: bundle count mismatch: have %d, want %d", number, len(have), len(want))
+		}
+		for i := range want {
+			if have[i].Hash() != want[i].Hash() {
+				t.Errorf("block %d: bundle %d mismatch: have %x, want %x", number, i, have[i].Hash(), want[i].Hash())
+			}
+		}
+	}
+	check(1, first)
+	check(2, first, second)
+	check(3, second)
+	if pool.Len() != 1 {
+		t.Errorf("expired bundles not evicted: have %d, want %d", pool.Len(), 1)
+	}
+	pool.Remove(second.Hash())
+	check(3)
+
+	// Raising the price threshold rejects the bundles paying less
+	pool.SetGasPrice(big.NewInt(2))
+	if err := pool.Add(&Bundle{Txs: types.Transactions{transaction(3, 21000, key)}, MinBlock: 3, MaxBlock: 3}); err != ErrUnderpriced {
+		t.Errorf("underpriced bundle error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+}
+
+// Tests that bundles whose transactions were mined are dropped on reset, without
+// being counted as discarded.
+func TestBundlePoolReset(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	pool := NewBundlePool(params.TestChainConfig, &testBlockChain{statedb, 1000000, new(event.Feed)}, 1)
+
+	defer func(meter metrics.Meter) { bundleDiscardMeter = meter }(bundleDiscardMeter)
+	bundleDiscardMeter = metrics.NewMeterForced()
+
+	key, _ := crypto.GenerateKey()
+	mined := &Bundle{Txs: types.Transactions{transaction(0, 21000, key), transaction(1, 21000, key)}, MinBlock: 1, MaxBlock: 2}
+	pending := &Bundle{Txs: types.Transactions{transaction(2, 21000, key)}, MinBlock: 1, MaxBlock: 2}
+	for _, bundle := range []*Bundle{mined, pending} {
+		if err := pool.Add(bundle); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+	}
+	statedb.SetNonce(crypto.PubkeyToAddress(key.PublicKey), 2)
+	pool.Reset(statedb)
+
+	if bundles := pool.Bundles(1); len(bundles) != 1 || bundles[0].Hash() != pending.Hash() {
+		t.Errorf("mined bundle not dropped: have %d bundles", len(bundles))
+	}
+	if n := bundleDiscardMeter.Count(); n != 0 {
+		t.Errorf("mined bundle counted as discarded: have %d", n)
+	}
+}
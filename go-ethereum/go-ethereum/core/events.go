@@ -18,12 +18,17 @@ package core
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// DeadlineExpiredEvent is posted when transactions are dropped from the pool
+// because their inclusion deadline passed before they made it into a block.
+type DeadlineExpiredEvent struct{ Txs []*types.Transaction }
+
 // NewMinedBlockEvent is posted when a block has been imported.
 type NewMinedBlockEvent struct{ Block *types.Block }
 
@@ -41,3 +46,10 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// StateDiffEvent is posted when a block is written into the canonical chain,
+// carrying the changes it made to the state.
+type StateDiffEvent struct {
+	Block *types.Block
+	Diff  state.StateDiff
+}
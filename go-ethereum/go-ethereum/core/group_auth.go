@@ -0,0 +1,101 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// groupAuthCacheSize is the number of group signature verification results to
+// keep, sparing the pairings when a transaction is verified again, e.g. when
+// mined after having been pooled.
+const groupAuthCacheSize = 4096
+
+// groupAuthCache caches the group signature verification results by the hash
+// of the signed message and the authorization.
+var groupAuthCache, _ = lru.New(groupAuthCacheSize)
+
+// verifyGroupSignature checks a TIBGS group signature, replaceable by tests.
+var verifyGroupSignature = vm.VerifyGroupSignature
+
+// VerifyGroupAuth checks the group signature of an anonymous transaction over
+// its sender hash, against the master public key of the group it was issued
+// under. Transactions not authorized by a group are accepted as is.
+func VerifyGroupAuth(signer types.Signer, tx *types.Transaction) error {
+	auth := tx.GroupAuth()
+	if auth == nil {
+		return nil
+	}
+	message := signer.Hash(tx)
+
+	blob, _ := rlp.EncodeToBytes([]interface{}{message, auth.ID, auth.Key, auth.Sig})
+	id := crypto.Keccak256Hash(blob)
+	if valid, ok := groupAuthCache.Get(id); ok {
+		if !valid.(bool) {
+			return ErrGroupAuth
+		}
+		return nil
+	}
+	valid, err := verifyGroupSignature(auth.Key, auth.Sig, message.Hex(), auth.ID)
+	valid = valid && err == nil
+	groupAuthCache.Add(id, valid)
+
+	if !valid {
+		return ErrGroupAuth
+	}
+	return nil
+}
+
+// preverifyGroupAuth checks the group signature of an anonymous transaction
+// ahead of its pool validation, so that the pairings aren't computed under the
+// pool lock: validation then hits the verification cache.
+//
+// As anyone may make up group signatures, the pairings are only computed for
+// transactions passing the cheap checks first: a valid fee payer signature, a
+// fee payer able to pay for the gas and a nonce not yet used. Transactions
+// unable to pay for their verification are left for validation to reject.
+func (pool *TxPool) preverifyGroupAuth(tx *types.Transaction) error {
+	if !tx.Anonymous() || tx.Gas() < params.TxGas+params.TxGroupAuthGas {
+		return nil
+	}
+	from, err := types.Sender(pool.signer, tx)
+	if err != nil {
+		return ErrInvalidSender
+	}
+	payer, err := types.FeePayer(pool.signer, tx)
+	if err != nil {
+		return types.ErrInvalidFeePayer
+	}
+	pool.mu.Lock()
+	nonce, balance := pool.currentState.GetNonce(from), pool.currentState.GetBalance(payer)
+	pool.mu.Unlock()
+
+	if nonce > tx.Nonce() {
+		return ErrNonceTooLow
+	}
+	if fee := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas())); balance.Cmp(fee) < 0 {
+		return ErrInsufficientFunds
+	}
+	return VerifyGroupAuth(pool.signer, tx)
+}
@@ -630,6 +630,47 @@ func DeleteReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadExtendedReceipts retrieves the extended receipts of all transactions in a
+// block, in transaction order. Nil is returned if the node didn't record them.
+func ReadExtendedReceipts(db ethdb.Reader, hash common.Hash, number uint64) types.ExtendedReceipts {
+	data, _ := db.Get(blockExtReceiptsKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var receipts types.ExtendedReceipts
+	if err := rlp.DecodeBytes(data, &receipts); err != nil {
+		log.Error("Invalid extended receipt array RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return receipts
+}
+
+// WriteExtendedReceipts stores the extended parts of all the transaction receipts
+// belonging to a block. Receipts lacking them are stored as empty ones.
+func WriteExtendedReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64, receipts types.Receipts) {
+	extended := make(types.ExtendedReceipts, len(receipts))
+	for i, receipt := range receipts {
+		if extended[i] = receipt.Extended; extended[i] == nil {
+			extended[i] = new(types.ExtendedReceipt)
+		}
+	}
+	bytes, err := rlp.EncodeToBytes(extended)
+	if err != nil {
+		log.Crit("Failed to encode block extended receipts", "err", err)
+	}
+	if err := db.Put(blockExtReceiptsKey(number, hash), bytes); err != nil {
+		log.Crit("Failed to store block extended receipts", "err", err)
+	}
+}
+
+// DeleteExtendedReceipts removes all extended receipt data associated with a
+// block hash.
+func DeleteExtendedReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(blockExtReceiptsKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block extended receipts", "err", err)
+	}
+}
+
 // ReadBlock retrieves an entire block corresponding to the hash, assembling it
 // back from the stored header and body. If either the header or body could not
 // be retrieved nil is returned.
@@ -688,6 +729,7 @@ func WriteAncientBlock(db ethdb.AncientWriter, block *types.Block, receipts type
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteExtendedReceipts(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
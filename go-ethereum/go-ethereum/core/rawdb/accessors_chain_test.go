@@ -342,6 +342,40 @@ func TestBlockReceiptStorage(t *testing.T) {
 	}
 }
 
+// Tests extended receipt storage and retrieval operations.
+func TestExtendedReceiptStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	receipts := types.Receipts{
+		{Extended: &types.ExtendedReceipt{ReturnData: []byte{0x01, 0x02}, CallGas: 21}},
+		{Extended: &types.ExtendedReceipt{ReturnData: []byte{0xaa}, Err: "execution reverted", CallGas: 18}},
+		{}, // Receipt without extended parts, e.g. received via fast sync
+	}
+	hash := common.BytesToHash([]byte{0x03, 0x14})
+	if rs := ReadExtendedReceipts(db, hash, 0); rs != nil {
+		t.Fatalf("non existent extended receipts returned: %v", rs)
+	}
+	WriteExtendedReceipts(db, hash, 0, receipts)
+	rs := ReadExtendedReceipts(db, hash, 0)
+	if len(rs) != len(receipts) {
+		t.Fatalf("extended receipt count mismatch: have %d, want %d", len(rs), len(receipts))
+	}
+	for i, receipt := range receipts {
+		want := receipt.Extended
+		if want == nil {
+			want = new(types.ExtendedReceipt)
+		}
+		if !bytes.Equal(rs[i].ReturnData, want.ReturnData) || rs[i].Err != want.Err || rs[i].CallGas != want.CallGas {
+			t.Errorf("extended receipt %d mismatch: have %+v, want %+v", i, rs[i], want)
+		}
+	}
+	// Deleting the block also deletes its extended receipts
+	DeleteBlock(db, hash, 0)
+	if rs := ReadExtendedReceipts(db, hash, 0); rs != nil {
+		t.Fatalf("deleted extended receipts returned: %v", rs)
+	}
+}
+
 func checkReceiptsRLP(have, want types.Receipts) error {
 	if len(have) != len(want) {
 		return fmt.Errorf("receipts sizes mismatch: have %d, want %d", len(have), len(want))
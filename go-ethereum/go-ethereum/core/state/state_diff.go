@@ -0,0 +1,211 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// StateDiff is the set of accounts changed over a span of state transitions,
+// typically the processing of a block, with their values before and after.
+type StateDiff map[common.Address]*AccountDiff
+
+// AccountDiff is the change of a single account. Fields left unchanged are nil.
+//
+// Storage slots are only reported if they were written to. Slots cleared by the
+// destruction of their account are not listed individually.
+type AccountDiff struct {
+	Balance *BalanceDiff                 `json:"balance,omitempty"`
+	Nonce   *NonceDiff                   `json:"nonce,omitempty"`
+	Code    *CodeDiff                    `json:"code,omitempty"`
+	Storage map[common.Hash]*StorageDiff `json:"storage,omitempty"`
+}
+
+// BalanceDiff is the change of an account balance.
+type BalanceDiff struct {
+	From *hexutil.Big `json:"from"`
+	To   *hexutil.Big `json:"to"`
+}
+
+// NonceDiff is the change of an account nonce.
+type NonceDiff struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+// CodeDiff is the change of an account code.
+type CodeDiff struct {
+	From hexutil.Bytes `json:"from"`
+	To   hexutil.Bytes `json:"to"`
+}
+
+// StorageDiff is the change of a storage slot.
+type StorageDiff struct {
+	From common.Hash `json:"from"`
+	To   common.Hash `json:"to"`
+}
+
+// diffOrigin holds the values of an account as they were before the first change
+// to them since state diff recording started. Values not changed yet are nil.
+type diffOrigin struct {
+	balance *big.Int
+	nonce   *uint64
+	code    *[]byte
+	storage map[common.Hash]common.Hash
+}
+
+// copy returns a deep copy of the origin values.
+func (o *diffOrigin) copy() *diffOrigin {
+	cpy := &diffOrigin{
+		nonce:   o.nonce,
+		code:    o.code,
+		storage: make(map[common.Hash]common.Hash, len(o.storage)),
+	}
+	if o.balance != nil {
+		cpy.balance = new(big.Int).Set(o.balance)
+	}
+	for key, value := range o.storage {
+		cpy.storage[key] = value
+	}
+	return cpy
+}
+
+// setBalance records the original balance, unless already known.
+func (o *diffOrigin) setBalance(balance *big.Int) {
+	if o.balance == nil {
+		o.balance = new(big.Int).Set(balance)
+	}
+}
+
+// setNonce records the original nonce, unless already known.
+func (o *diffOrigin) setNonce(nonce uint64) {
+	if o.nonce == nil {
+		o.nonce = &nonce
+	}
+}
+
+// setCode records the original code, unless already known.
+func (o *diffOrigin) setCode(code []byte) {
+	if o.code == nil {
+		o.code = &code
+	}
+}
+
+// setState records the original value of a storage slot, unless already known.
+func (o *diffOrigin) setState(key, value common.Hash) {
+	if _, ok := o.storage[key]; !ok {
+		o.storage[key] = value
+	}
+}
+
+// StartDiff starts recording the changes made to the state, for them to be
+// retrieved via StateDiff. Any previous recording is discarded.
+func (s *StateDB) StartDiff() {
+	s.diffOrigins = make(map[common.Address]*diffOrigin)
+}
+
+// recordDiff records the original values of everything changed by the entries
+// of the journal, which is about to be discarded. As changes reverted within
+// the journal are already gone, only lasting ones are recorded.
+func (s *StateDB) recordDiff() {
+	origin := func(addr common.Address) *diffOrigin {
+		o, ok := s.diffOrigins[addr]
+		if !ok {
+			o = &diffOrigin{storage: make(map[common.Hash]common.Hash)}
+			s.diffOrigins[addr] = o
+		}
+		return o
+	}
+	for _, entry := range s.journal.entries {
+		switch ch := entry.(type) {
+		case createObjectChange:
+			// The account didn't exist at all before
+			o := origin(*ch.account)
+			o.setBalance(common.Big0)
+			o.setNonce(0)
+			o.setCode(nil)
+		case resetObjectChange:
+			o := origin(ch.prev.address)
+			if ch.prev.deleted {
+				o.setBalance(common.Big0)
+				o.setNonce(0)
+				o.setCode(nil)
+			} else {
+				o.setBalance(ch.prev.Balance())
+				o.setNonce(ch.prev.Nonce())
+				o.setCode(ch.prev.Code(s.db))
+			}
+		case suicideChange:
+			origin(*ch.account).setBalance(ch.prevbalance)
+		case balanceChange:
+			origin(*ch.account).setBalance(ch.prev)
+		case nonceChange:
+			origin(*ch.account).setNonce(ch.prev)
+		case codeChange:
+			origin(*ch.account).setCode(ch.prevcode)
+		case storageChange:
+			origin(*ch.account).setState(ch.key, ch.prevalue)
+		}
+	}
+}
+
+// StateDiff returns the changes made to all accounts since StartDiff, leaving
+// out any changed back to their original values. Only finalised changes are
+// included. Nil is returned if no diff is being recorded.
+func (s *StateDB) StateDiff() StateDiff {
+	if s.diffOrigins == nil {
+		return nil
+	}
+	diff := make(StateDiff)
+	for addr, origin := range s.diffOrigins {
+		account := new(AccountDiff)
+		if origin.balance != nil {
+			if balance := s.GetBalance(addr); balance.Cmp(origin.balance) != 0 {
+				account.Balance = &BalanceDiff{
+					From: (*hexutil.Big)(new(big.Int).Set(origin.balance)),
+					To:   (*hexutil.Big)(new(big.Int).Set(balance)),
+				}
+			}
+		}
+		if origin.nonce != nil {
+			if nonce := s.GetNonce(addr); nonce != *origin.nonce {
+				account.Nonce = &NonceDiff{From: hexutil.Uint64(*origin.nonce), To: hexutil.Uint64(nonce)}
+			}
+		}
+		if origin.code != nil {
+			if code := s.GetCode(addr); !bytes.Equal(code, *origin.code) {
+				account.Code = &CodeDiff{From: common.CopyBytes(*origin.code), To: common.CopyBytes(code)}
+			}
+		}
+		for key, value := range origin.storage {
+			if current := s.GetState(addr, key); current != value {
+				if account.Storage == nil {
+					account.Storage = make(map[common.Hash]*StorageDiff)
+				}
+				account.Storage[key] = &StorageDiff{From: value, To: current}
+			}
+		}
+		if account.Balance != nil || account.Nonce != nil || account.Code != nil || account.Storage != nil {
+			diff[addr] = account
+		}
+	}
+	return diff
+}
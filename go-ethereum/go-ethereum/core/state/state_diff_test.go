@@ -0,0 +1,113 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+// Tests that state diffs record the lasting changes made to accounts, with their
+// values from before the recording started.
+func TestStateDiff(t *testing.T) {
+	var (
+		existing = common.Address{0x01}
+		created  = common.Address{0x02}
+		reverted = common.Address{0x03}
+		restored = common.Address{0x04}
+		slot     = common.Hash{0xaa}
+	)
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	state.SetBalance(existing, big.NewInt(100))
+	state.SetNonce(existing, 1)
+	state.SetState(existing, slot, common.Hash{0x01})
+	state.SetBalance(reverted, big.NewInt(5))
+	state.SetBalance(restored, big.NewInt(7))
+	root, _ := state.Commit(false)
+	state, _ = New(root, state.db, nil)
+
+	if diff := state.StateDiff(); diff != nil {
+		t.Fatalf("diff returned without recording: %v", diff)
+	}
+	state.StartDiff()
+
+	// Change an existing account over multiple transactions
+	state.AddBalance(existing, big.NewInt(10))
+	state.SetState(existing, slot, common.Hash{0x02})
+	state.Finalise(true)
+	state.SetNonce(existing, 2)
+	state.SetCode(existing, []byte{0x60, 0x00})
+	state.SetState(existing, slot, common.Hash{0x03})
+	state.Finalise(true)
+
+	// Create a new account and touch others in reverted or undone ways
+	state.SetBalance(created, big.NewInt(1))
+
+	snap := state.Snapshot()
+	state.SetBalance(reverted, big.NewInt(50))
+	state.RevertToSnapshot(snap)
+
+	state.SetBalance(restored, big.NewInt(70))
+	state.Finalise(true)
+	state.SetBalance(restored, big.NewInt(7))
+	state.Finalise(true)
+
+	diff := state.StateDiff()
+	if len(diff) != 2 {
+		t.Fatalf("changed account count mismatch: have %d, want 2", len(diff))
+	}
+	account := diff[existing]
+	if account == nil {
+		t.Fatalf("existing account missing from diff")
+	}
+	if account.Balance == nil || account.Balance.From.ToInt().Int64() != 100 || account.Balance.To.ToInt().Int64() != 110 {
+		t.Errorf("balance diff mismatch: have %+v", account.Balance)
+	}
+	if account.Nonce == nil || account.Nonce.From != 1 || account.Nonce.To != 2 {
+		t.Errorf("nonce diff mismatch: have %+v", account.Nonce)
+	}
+	if account.Code == nil || len(account.Code.From) != 0 || !bytes.Equal(account.Code.To, []byte{0x60, 0x00}) {
+		t.Errorf("code diff mismatch: have %+v", account.Code)
+	}
+	if slotDiff := account.Storage[slot]; slotDiff == nil || slotDiff.From != (common.Hash{0x01}) || slotDiff.To != (common.Hash{0x03}) {
+		t.Errorf("storage diff mismatch: have %+v", slotDiff)
+	}
+	account = diff[created]
+	if account == nil {
+		t.Fatalf("created account missing from diff")
+	}
+	if account.Balance == nil || account.Balance.From.ToInt().Sign() != 0 || account.Balance.To.ToInt().Int64() != 1 {
+		t.Errorf("created balance diff mismatch: have %+v", account.Balance)
+	}
+	if account.Nonce != nil || account.Code != nil || account.Storage != nil {
+		t.Errorf("unchanged fields of created account reported: %+v", account)
+	}
+	// Copies carry on recording independently
+	cpy := state.Copy()
+	cpy.SetBalance(reverted, big.NewInt(6))
+	cpy.Finalise(true)
+	if _, ok := cpy.StateDiff()[reverted]; !ok {
+		t.Errorf("change on copy missing from its diff")
+	}
+	if _, ok := state.StateDiff()[reverted]; ok {
+		t.Errorf("change on copy leaked into the original diff")
+	}
+}
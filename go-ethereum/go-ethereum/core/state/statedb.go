@@ -102,6 +102,10 @@ type StateDB struct {
 	validRevisions []revision
 	nextRevisionId int
 
+	// Original values of the accounts changed since state diff recording started,
+	// nil if no diff is being recorded
+	diffOrigins map[common.Address]*diffOrigin
+
 	// Measurements gathered during execution for debugging purposes
 	AccountReads         time.Duration
 	AccountHashes        time.Duration
@@ -708,6 +712,14 @@ func (s *StateDB) Copy() *StateDB {
 	// However, it doesn't cost us much to copy an empty list, so we do it anyway
 	// to not blow up if we ever decide copy it in the middle of a transaction
 	state.accessList = s.accessList.Copy()
+
+	// Carry over any state diff being recorded
+	if s.diffOrigins != nil {
+		state.diffOrigins = make(map[common.Address]*diffOrigin, len(s.diffOrigins))
+		for addr, origin := range s.diffOrigins {
+			state.diffOrigins[addr] = origin.copy()
+		}
+	}
 	return state
 }
 
@@ -773,6 +785,10 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 		s.stateObjectsPending[addr] = struct{}{}
 		s.stateObjectsDirty[addr] = struct{}{}
 	}
+	// Record the lasting changes for any state diff before dropping the journal
+	if s.diffOrigins != nil {
+		s.recordDiff()
+	}
 	// Invalidate journal because reverting across transactions is not allowed.
 	s.clearJournalAndRefund()
 }
@@ -0,0 +1,455 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	parallelTxMeter       = metrics.NewRegisteredMeter("chain/parallel/txs", nil)
+	parallelConflictMeter = metrics.NewRegisteredMeter("chain/parallel/conflicts", nil)
+)
+
+// accessKind is the part of the state touched by a read or a write.
+type accessKind byte
+
+const (
+	accessBalance accessKind = iota // Account balance
+	accessNonce                     // Account nonce
+	accessCode                      // Account code
+	accessAccount                   // Existence or emptiness of an account (any account field)
+	accessStorage                   // Single storage slot
+)
+
+// accessKey identifies a single piece of state accessed by a transaction.
+type accessKey struct {
+	addr common.Address
+	kind accessKind
+	slot common.Hash
+}
+
+// accessBase is the state of an account at the time a transaction first
+// accessed it, used to turn absolute balances into mergeable deltas and to
+// detect changes to the existence or emptiness of the account.
+type accessBase struct {
+	exists  bool
+	empty   bool
+	balance *big.Int
+}
+
+// accessTracker is a vm.StateDB wrapping a state database and recording the
+// read and write sets of the transaction executed on it.
+//
+// Balance changes done through AddBalance and SubBalance are commutative and
+// are only recorded as writes, merged as deltas. Every other write is also
+// recorded as a read, so that two transactions overwriting the same value are
+// always ordered.
+type accessTracker struct {
+	*state.StateDB
+
+	reads   map[accessKey]struct{}
+	writes  map[accessKey]struct{}
+	base    map[common.Address]*accessBase
+	created map[common.Address]struct{}
+	unsafe  bool // Whether the execution cannot be merged and must be repeated
+}
+
+// newAccessTracker wraps a state database to track the state accesses of a
+// single transaction.
+func newAccessTracker(statedb *state.StateDB) *accessTracker {
+	return &accessTracker{
+		StateDB: statedb,
+		reads:   make(map[accessKey]struct{}),
+		writes:  make(map[accessKey]struct{}),
+		base:    make(map[common.Address]*accessBase),
+		created: make(map[common.Address]struct{}),
+	}
+}
+
+// touch records the pre-transaction state of an account on first access.
+func (t *accessTracker) touch(addr common.Address) {
+	if _, ok := t.base[addr]; !ok {
+		t.base[addr] = &accessBase{
+			exists:  t.StateDB.Exist(addr),
+			empty:   t.StateDB.Empty(addr),
+			balance: new(big.Int).Set(t.StateDB.GetBalance(addr)),
+		}
+	}
+}
+
+func (t *accessTracker) read(addr common.Address, kind accessKind, slot common.Hash) {
+	t.touch(addr)
+	t.reads[accessKey{addr, kind, slot}] = struct{}{}
+}
+
+func (t *accessTracker) write(addr common.Address, kind accessKind, slot common.Hash) {
+	t.touch(addr)
+	t.writes[accessKey{addr, kind, slot}] = struct{}{}
+}
+
+func (t *accessTracker) CreateAccount(addr common.Address) {
+	t.read(addr, accessAccount, common.Hash{})
+	if t.base[addr].exists {
+		// Recreating an existing account wipes its storage, which can't be
+		// expressed as a set of slot writes. Leave it to serial execution.
+		t.unsafe = true
+	}
+	t.created[addr] = struct{}{}
+	t.write(addr, accessAccount, common.Hash{})
+	t.StateDB.CreateAccount(addr)
+}
+
+func (t *accessTracker) SubBalance(addr common.Address, amount *big.Int) {
+	t.write(addr, accessBalance, common.Hash{})
+	t.StateDB.SubBalance(addr, amount)
+}
+
+func (t *accessTracker) AddBalance(addr common.Address, amount *big.Int) {
+	t.write(addr, accessBalance, common.Hash{})
+	t.StateDB.AddBalance(addr, amount)
+}
+
+func (t *accessTracker) GetBalance(addr common.Address) *big.Int {
+	t.read(addr, accessBalance, common.Hash{})
+	return t.StateDB.GetBalance(addr)
+}
+
+func (t *accessTracker) GetNonce(addr common.Address) uint64 {
+	t.read(addr, accessNonce, common.Hash{})
+	return t.StateDB.GetNonce(addr)
+}
+
+func (t *accessTracker) SetNonce(addr common.Address, nonce uint64) {
+	t.read(addr, accessNonce, common.Hash{})
+	t.write(addr, accessNonce, common.Hash{})
+	t.StateDB.SetNonce(addr, nonce)
+}
+
+func (t *accessTracker) GetCodeHash(addr common.Address) common.Hash {
+	t.read(addr, accessCode, common.Hash{})
+	return t.StateDB.GetCodeHash(addr)
+}
+
+func (t *accessTracker) GetCode(addr common.Address) []byte {
+	t.read(addr, accessCode, common.Hash{})
+	return t.StateDB.GetCode(addr)
+}
+
+func (t *accessTracker) SetCode(addr common.Address, code []byte) {
+	t.read(addr, accessCode, common.Hash{})
+	t.write(addr, accessCode, common.Hash{})
+	t.StateDB.SetCode(addr, code)
+}
+
+func (t *accessTracker) GetCodeSize(addr common.Address) int {
+	t.read(addr, accessCode, common.Hash{})
+	return t.StateDB.GetCodeSize(addr)
+}
+
+func (t *accessTracker) GetCommittedState(addr common.Address, slot common.Hash) common.Hash {
+	t.read(addr, accessStorage, slot)
+	return t.StateDB.GetCommittedState(addr, slot)
+}
+
+func (t *accessTracker) GetState(addr common.Address, slot common.Hash) common.Hash {
+	t.read(addr, accessStorage, slot)
+	return t.StateDB.GetState(addr, slot)
+}
+
+func (t *accessTracker) SetState(addr common.Address, slot common.Hash, value common.Hash) {
+	t.read(addr, accessStorage, slot)
+	t.write(addr, accessStorage, slot)
+	t.StateDB.SetState(addr, slot, value)
+}
+
+func (t *accessTracker) Suicide(addr common.Address) bool {
+	t.read(addr, accessAccount, common.Hash{})
+	t.write(addr, accessAccount, common.Hash{})
+	return t.StateDB.Suicide(addr)
+}
+
+func (t *accessTracker) HasSuicided(addr common.Address) bool {
+	t.read(addr, accessAccount, common.Hash{})
+	return t.StateDB.HasSuicided(addr)
+}
+
+func (t *accessTracker) Exist(addr common.Address) bool {
+	t.read(addr, accessAccount, common.Hash{})
+	return t.StateDB.Exist(addr)
+}
+
+func (t *accessTracker) Empty(addr common.Address) bool {
+	t.read(addr, accessAccount, common.Hash{})
+	return t.StateDB.Empty(addr)
+}
+
+func (t *accessTracker) ForEachStorage(addr common.Address, cb func(key, value common.Hash) bool) error {
+	t.unsafe = true
+	return t.StateDB.ForEachStorage(addr, cb)
+}
+
+// conflicts reports whether any state read by the tracked transaction has been
+// written by one of the transactions already applied before it.
+func (t *accessTracker) conflicts(written map[accessKey]struct{}, accounts map[common.Address]struct{}) bool {
+	for key := range t.reads {
+		if key.kind == accessAccount {
+			if _, ok := accounts[key.addr]; ok {
+				return true
+			}
+			continue
+		}
+		if _, ok := written[key]; ok {
+			return true
+		}
+		if _, ok := written[accessKey{addr: key.addr, kind: accessAccount}]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// record adds the writes of the tracked transaction to the block's write set.
+//
+// Accounts are only marked as changed as a whole if they were created,
+// destructed, or their existence or emptiness changed. Transactions merely
+// paying the same recipient thus don't conflict with each other, their balance
+// deltas being merged instead.
+func (t *accessTracker) record(written map[accessKey]struct{}, accounts map[common.Address]struct{}) {
+	for key := range t.writes {
+		written[key] = struct{}{}
+		switch {
+		case key.kind == accessAccount:
+			accounts[key.addr] = struct{}{}
+		case key.kind != accessStorage:
+			base := t.base[key.addr]
+			if base.exists != t.StateDB.Exist(key.addr) || base.empty != t.StateDB.Empty(key.addr) {
+				accounts[key.addr] = struct{}{}
+			}
+		}
+	}
+}
+
+// merge replays the final values of everything written by the tracked
+// transaction onto dst. It is only valid if the transaction does not conflict
+// with anything applied to dst since the tracked state was copied.
+func (t *accessTracker) merge(dst *state.StateDB) {
+	dirty := make(map[common.Address][]accessKey)
+	for key := range t.writes {
+		dirty[key.addr] = append(dirty[key.addr], key)
+	}
+	for addr, keys := range dirty {
+		base := t.base[addr]
+		if !t.StateDB.Exist(addr) {
+			// The account was destructed or, being empty, removed by EIP-158
+			if base.exists {
+				dst.Suicide(addr)
+			}
+			continue
+		}
+		if _, ok := t.created[addr]; ok && !base.exists {
+			dst.CreateAccount(addr)
+		}
+		delta := new(big.Int).Sub(t.StateDB.GetBalance(addr), base.balance)
+		if delta.Sign() < 0 {
+			dst.SubBalance(addr, delta.Neg(delta))
+		} else {
+			dst.AddBalance(addr, delta)
+		}
+		for _, key := range keys {
+			switch key.kind {
+			case accessNonce:
+				dst.SetNonce(addr, t.StateDB.GetNonce(addr))
+			case accessCode:
+				dst.SetCode(addr, t.StateDB.GetCode(addr))
+			case accessStorage:
+				dst.SetState(addr, key.slot, t.StateDB.GetState(addr, key.slot))
+			}
+		}
+	}
+	for hash, preimage := range t.StateDB.Preimages() {
+		dst.AddPreimage(hash, preimage)
+	}
+}
+
+// speculation is the outcome of executing a transaction on a private copy of
+// the state, together with its tracked state accesses.
+type speculation struct {
+	msg     types.Message
+	invalid error // Error converting the transaction into a message
+	result  *ExecutionResult
+	err     error
+	tracker *accessTracker
+}
+
+// executeTracked runs a message on top of a tracked state database. The state is
+// finalised afterwards, but no receipt is created.
+func executeTracked(config *params.ChainConfig, blockContext vm.BlockContext, tracker *accessTracker, header *types.Header, msg types.Message, gp *GasPool, cfg vm.Config) (*ExecutionResult, error) {
+	evm := vm.NewEVM(blockContext, NewEVMTxContext(msg), tracker, config, cfg)
+	if config.IsYoloV2(header.Number) {
+		tracker.AddAddressToAccessList(msg.From())
+		if dst := msg.To(); dst != nil {
+			tracker.AddAddressToAccessList(*dst)
+		}
+		for _, addr := range evm.ActivePrecompiles() {
+			tracker.AddAddressToAccessList(addr)
+		}
+		for _, el := range msg.AccessList() {
+			tracker.AddAddressToAccessList(el.Address)
+			for _, key := range el.StorageKeys {
+				tracker.AddSlotToAccessList(el.Address, key)
+			}
+		}
+	}
+	result, err := ApplyMessage(evm, msg, gp)
+	if err != nil {
+		return nil, err
+	}
+	tracker.Finalise(true)
+	return result, nil
+}
+
+// ApplyTransactionsParallel applies a list of transactions in order to statedb,
+// executing them speculatively in parallel on copies of the state and tracking
+// their read and write sets. Transactions are committed in order; one that
+// read state written by an earlier transaction of the list is re-executed on
+// top of the updated state. The resulting state, receipts and gas usage are
+// the same as those of serial execution.
+//
+// Both returned slices are of the same length as txs. A transaction that fails
+// with a consensus error leaves the state untouched and gets a nil receipt.
+// Parallel execution relies on per-transaction state finalisation, hence it
+// must only be used for blocks past Byzantium.
+func ApplyTransactionsParallel(config *params.ChainConfig, bc ChainContext, author *common.Address, gp *GasPool, statedb *state.StateDB, header *types.Header, blockHash common.Hash, txs types.Transactions, txIndex int, usedGas *uint64, cfg vm.Config) ([]*types.Receipt, []error) {
+	var (
+		signer       = types.MakeSigner(config, header.Number)
+		blockContext = NewEVMBlockContext(header, bc, author)
+		specs        = make([]*speculation, len(txs))
+		tasks        = make(chan int, len(txs))
+		pend         sync.WaitGroup
+	)
+	// Execute all the transactions on private copies of the pre-state
+	for i := range txs {
+		specs[i] = &speculation{tracker: newAccessTracker(statedb.Copy())}
+		tasks <- i
+	}
+	close(tasks)
+
+	threads := runtime.NumCPU()
+	if threads > len(txs) {
+		threads = len(txs)
+	}
+	for i := 0; i < threads; i++ {
+		pend.Add(1)
+		go func() {
+			defer pend.Done()
+			for i := range tasks {
+				spec := specs[i]
+				if spec.msg, spec.invalid = txs[i].AsMessage(signer); spec.invalid != nil {
+					continue
+				}
+				if txs[i].Anonymous() {
+					if spec.invalid = VerifyGroupAuth(signer, txs[i]); spec.invalid != nil {
+						continue
+					}
+				}
+				spec.tracker.Prepare(txs[i].Hash(), blockHash, txIndex+i)
+				spec.result, spec.err = executeTracked(config, blockContext, spec.tracker, header, spec.msg, new(GasPool).AddGas(header.GasLimit), cfg)
+			}
+		}()
+	}
+	pend.Wait()
+
+	// Commit the transactions in order, repeating any conflicting ones
+	var (
+		receipts = make([]*types.Receipt, len(txs))
+		errs     = make([]error, len(txs))
+		written  = make(map[accessKey]struct{})
+		accounts = make(map[common.Address]struct{})
+	)
+	for i, tx := range txs {
+		spec := specs[i]
+		if spec.invalid != nil {
+			errs[i] = spec.invalid
+			continue
+		}
+		statedb.Prepare(tx.Hash(), blockHash, txIndex)
+
+		if spec.tracker.unsafe || spec.tracker.conflicts(written, accounts) {
+			parallelConflictMeter.Mark(1)
+
+			snap := statedb.Snapshot()
+			spec.tracker = newAccessTracker(statedb)
+			spec.result, spec.err = executeTracked(config, blockContext, spec.tracker, header, spec.msg, gp, cfg)
+			if spec.err != nil {
+				statedb.RevertToSnapshot(snap)
+			}
+		} else if spec.err == nil {
+			if gp.Gas() < spec.msg.Gas() {
+				spec.err = ErrGasLimitReached
+			} else {
+				gp.SubGas(spec.result.UsedGas)
+
+				spec.tracker.merge(statedb)
+				for _, log := range spec.tracker.GetLogs(tx.Hash()) {
+					statedb.AddLog(&types.Log{
+						Address:     log.Address,
+						Topics:      log.Topics,
+						Data:        log.Data,
+						BlockNumber: log.BlockNumber,
+					})
+				}
+				statedb.Finalise(true)
+			}
+		}
+		parallelTxMeter.Mark(1)
+		if spec.err != nil {
+			errs[i] = spec.err
+			continue
+		}
+		spec.tracker.record(written, accounts)
+		*usedGas += spec.result.UsedGas
+
+		receipt := types.NewReceipt(nil, spec.result.Failed(), *usedGas)
+		receipt.Type = tx.Type()
+		receipt.TxHash = tx.Hash()
+		receipt.GasUsed = spec.result.UsedGas
+		if spec.msg.To() == nil {
+			receipt.ContractAddress = crypto.CreateAddress(spec.msg.From(), tx.Nonce())
+		}
+		receipt.Logs = statedb.GetLogs(tx.Hash())
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		receipt.BlockHash = blockHash
+		receipt.BlockNumber = header.Number
+		receipt.TransactionIndex = uint(txIndex)
+		receipt.Extended = newExtendedReceipt(spec.result)
+
+		receipts[i] = receipt
+		txIndex++
+	}
+	return receipts, errs
+}
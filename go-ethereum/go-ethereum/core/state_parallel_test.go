@@ -0,0 +1,170 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// forceConflictMeter replaces the conflict meter with one counting even if
+// metrics are disabled, returning a function restoring the original.
+func forceConflictMeter() func() {
+	meter := parallelConflictMeter
+	parallelConflictMeter = metrics.NewMeterForced()
+	return func() {
+		parallelConflictMeter.Stop()
+		parallelConflictMeter = meter
+	}
+}
+
+// Tests that a chain generated with serial transaction execution is imported
+// with the exact same state and receipts when executing in parallel, both with
+// independent and conflicting transactions.
+func TestParallelExecution(t *testing.T) {
+	var (
+		signer  = types.HomesteadSigner{}
+		keys    = make([]*ecdsa.PrivateKey, 4)
+		addrs   = make([]common.Address, len(keys))
+		funds   = big.NewInt(1000000000000000000)
+		counter = common.HexToAddress("0xc0de")
+		sink    = common.HexToAddress("0x5111c")
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				// PUSH1 0 SLOAD PUSH1 1 ADD PUSH1 0 SSTORE STOP
+				counter: {Balance: new(big.Int), Code: common.FromHex("0x60005460010160005500")},
+			},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		gspec.Alloc[addrs[i]] = GenesisAccount{Balance: funds}
+	}
+	genesis := gspec.MustCommit(db)
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, func(i int, block *BlockGen) {
+		for j, key := range keys {
+			sender := addrs[j]
+			sign := func(tx *types.Transaction) *types.Transaction {
+				signed, _ := types.SignTx(tx, signer, key)
+				return signed
+			}
+			// Independent transfers touching a shared recipient only through
+			// commutative balance additions
+			block.AddTx(sign(types.NewTransaction(block.TxNonce(sender), sink, big.NewInt(1000), params.TxGas, nil, nil)))
+
+			// Transfers creating fresh accounts and transfers between senders
+			fresh := common.BigToAddress(big.NewInt(int64(0x1000 + i*len(keys) + j)))
+			block.AddTx(sign(types.NewTransaction(block.TxNonce(sender), fresh, big.NewInt(1), params.TxGas, nil, nil)))
+			block.AddTx(sign(types.NewTransaction(block.TxNonce(sender), addrs[(j+1)%len(addrs)], big.NewInt(5), params.TxGas, nil, nil)))
+
+			// Storage conflicts on a shared counter
+			block.AddTx(sign(types.NewTransaction(block.TxNonce(sender), counter, new(big.Int), 100000, nil, nil)))
+
+			// Contract creation deploying the counter code
+			code := common.FromHex("0x6960005460010160005500600052600a6016f3")
+			block.AddTx(sign(types.NewContractCreation(block.TxNonce(sender), new(big.Int), 100000, nil, code)))
+		}
+	})
+	defer forceConflictMeter()()
+
+	// Import the chain into a fresh database with parallel execution enabled
+	pdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(pdb)
+
+	chain, err := NewBlockChain(pdb, nil, gspec.Config, ethash.NewFaker(), vm.Config{ParallelExecution: true}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	// Block import validates the state root, receipt root, bloom and gas usage
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to import with parallel execution: %v", n, err)
+	}
+	state, _ := chain.State()
+	if have, want := state.GetState(counter, common.Hash{}), common.BigToHash(big.NewInt(int64(len(blocks)*len(keys)))); have != want {
+		t.Errorf("counter mismatch: have %x, want %x", have, want)
+	}
+	if parallelConflictMeter.Count() == 0 {
+		t.Errorf("no conflicts detected on the shared counter")
+	}
+}
+
+// Tests that transactions paying the same existing recipient are merged without
+// being re-executed, their balance changes being commutative.
+func TestParallelSharedRecipient(t *testing.T) {
+	var (
+		signer = types.HomesteadSigner{}
+		keys   = make([]*ecdsa.PrivateKey, 8)
+		funds  = big.NewInt(1000000000000000000)
+		sink   = common.HexToAddress("0x5111c")
+		miner  = common.HexToAddress("0xc0ffee")
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				sink:  {Balance: big.NewInt(1)},
+				miner: {Balance: big.NewInt(1)},
+			},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		gspec.Alloc[crypto.PubkeyToAddress(keys[i].PublicKey)] = GenesisAccount{Balance: funds}
+	}
+	genesis := gspec.MustCommit(db)
+
+	// The coinbase exists too, so that the fee payments don't conflict either
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, func(i int, block *BlockGen) {
+		block.SetCoinbase(miner)
+		for _, key := range keys {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(crypto.PubkeyToAddress(key.PublicKey)), sink, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+			block.AddTx(tx)
+		}
+	})
+	defer forceConflictMeter()()
+
+	pdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(pdb)
+
+	chain, err := NewBlockChain(pdb, nil, gspec.Config, ethash.NewFaker(), vm.Config{ParallelExecution: true}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to import with parallel execution: %v", n, err)
+	}
+	if conflicts := parallelConflictMeter.Count(); conflicts != 0 {
+		t.Errorf("conflict count mismatch: have %d, want 0", conflicts)
+	}
+}
@@ -71,6 +71,20 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
 		misc.ApplyDAOHardFork(statedb)
 	}
+	// Execute the transactions in parallel if requested and possible (tracers
+	// are not thread safe and receipts prior to Byzantium need the roots)
+	if cfg.ParallelExecution && !cfg.Debug && p.config.IsByzantium(header.Number) {
+		txs := block.Transactions()
+		receipts, errs := ApplyTransactionsParallel(p.config, p.bc, nil, gp, statedb, header, block.Hash(), txs, 0, usedGas, cfg)
+		for i, err := range errs {
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, txs[i].Hash().Hex(), err)
+			}
+			allLogs = append(allLogs, receipts[i].Logs...)
+		}
+		p.engine.Finalize(p.bc, header, statedb, txs, block.Uncles())
+		return receipts, allLogs, *usedGas, nil
+	}
 	blockContext := NewEVMBlockContext(header, p.bc, nil)
 	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, p.config, cfg)
 	// Iterate over and process the individual transactions
@@ -94,6 +108,12 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 }
 
 func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainContext, author *common.Address, gp *GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (*types.Receipt, error) {
+	// Anonymous transactions are only valid with a verified group signature
+	if tx.Anonymous() {
+		if err := VerifyGroupAuth(types.MakeSigner(config, header.Number), tx); err != nil {
+			return nil, err
+		}
+	}
 	// Create a new context to be used in the EVM environment
 	// gyh:
 	{
@@ -112,6 +132,12 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 		for _, addr := range evm.ActivePrecompiles() {
 			statedb.AddAddressToAccessList(addr)
 		}
+		for _, el := range msg.AccessList() {
+			statedb.AddAddressToAccessList(el.Address)
+			for _, key := range el.StorageKeys {
+				statedb.AddSlotToAccessList(el.Address, key)
+			}
+		}
 	}
 
 	// Update the evm with the new transaction context.
@@ -135,6 +161,7 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	// Create a new receipt for the transaction, storing the intermediate root and gas used by the tx
 	// based on the eip phase, we're passing whether the root touch-delete accounts.
 	receipt := types.NewReceipt(root, result.Failed(), *usedGas)
+	receipt.Type = tx.Type()
 	receipt.TxHash = tx.Hash()
 	receipt.GasUsed = result.UsedGas
 	// if the transaction created a contract, store the creation address in the receipt.
@@ -147,6 +174,7 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 	receipt.BlockHash = statedb.BlockHash()
 	receipt.BlockNumber = header.Number
 	receipt.TransactionIndex = uint(statedb.TxIndex())
+	receipt.Extended = newExtendedReceipt(result)
 
 	//gyh:
 
@@ -194,3 +222,16 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, config, cfg)
 	return applyTransaction(msg, config, bc, author, gp, statedb, header, tx, usedGas, vmenv)
 }
+
+// newExtendedReceipt records the outcome of the topmost call of a transaction
+// for the extended receipt store.
+func newExtendedReceipt(result *ExecutionResult) *types.ExtendedReceipt {
+	ext := &types.ExtendedReceipt{
+		ReturnData: result.ReturnData,
+		CallGas:    result.CallGas,
+	}
+	if result.Err != nil {
+		ext.Err = result.Err.Error()
+	}
+	return ext
+}
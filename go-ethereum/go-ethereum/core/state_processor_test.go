@@ -17,6 +17,8 @@
 package core
 
 import (
+	"bytes"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -150,3 +152,94 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	// Assemble and return the final block for sealing
 	return types.NewBlock(header, txs, nil, receipts, new(trie.Trie))
 }
+
+// Tests that the gas of sponsored transactions is charged to, and refunded to,
+// the fee payer, while the sender only pays the transferred value.
+func TestStateProcessorSponsored(t *testing.T) {
+	var (
+		signer       = types.LatestSigner(params.TestChainConfig)
+		senderKey, _ = crypto.GenerateKey()
+		payerKey, _  = crypto.GenerateKey()
+		sender       = crypto.PubkeyToAddress(senderKey.PublicKey)
+		payer        = crypto.PubkeyToAddress(payerKey.PublicKey)
+		db           = rawdb.NewMemoryDatabase()
+		gspec        = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				sender: {Balance: big.NewInt(1000)},
+				payer:  {Balance: big.NewInt(1000000)},
+			},
+		}
+		genesis       = gspec.MustCommit(db)
+		blockchain, _ = NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	)
+	defer blockchain.Stop()
+
+	tx, _ := types.SignTx(types.NewSponsoredTransaction(0, common.Address{1}, big.NewInt(100), 0, new(big.Int), nil), signer, senderKey)
+	tx, _ = tx.WithFeeParams(params.TxGas*2, big.NewInt(10))
+	tx, _ = types.SignFeePayer(tx, signer, payerKey)
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, b *BlockGen) {
+		b.AddTx(tx)
+	})
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert sponsored transaction: %v", err)
+	}
+	state, _ := blockchain.State()
+	if have, want := state.GetBalance(sender), big.NewInt(900); have.Cmp(want) != 0 {
+		t.Errorf("sender balance mismatch: have %v, want %v", have, want)
+	}
+	if have, want := state.GetBalance(payer), big.NewInt(1000000-int64(params.TxGas)*10); have.Cmp(want) != 0 {
+		t.Errorf("fee payer balance mismatch: have %v, want %v", have, want)
+	}
+	if have, want := state.GetNonce(sender), uint64(1); have != want {
+		t.Errorf("sender nonce mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that anonymous transactions are processed on behalf of the group account
+// with the gas charged to the fee payer, and that blocks carrying invalid group
+// signatures are rejected.
+func TestStateProcessorAnonymous(t *testing.T) {
+	// Swap out the pairing based verification, accepting a fixed signature
+	defer func(verify func([]byte, []byte, string, string) (bool, error)) { verifyGroupSignature = verify }(verifyGroupSignature)
+	verifyGroupSignature = func(key, sig []byte, message string, grpID string) (bool, error) {
+		return bytes.Equal(sig, []byte("valid")), nil
+	}
+	var (
+		signer      = types.LatestSigner(params.TestChainConfig)
+		payerKey, _ = crypto.GenerateKey()
+		payer       = crypto.PubkeyToAddress(payerKey.PublicKey)
+		db          = rawdb.NewMemoryDatabase()
+		gspec       = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{payer: {Balance: big.NewInt(10000000)}},
+		}
+		genesis       = gspec.MustCommit(db)
+		blockchain, _ = NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	)
+	defer blockchain.Stop()
+
+	anonymous := func(sig string) *types.Transaction {
+		tx, _ := types.NewSponsoredTransaction(0, common.Address{1}, new(big.Int), params.TxGas+params.TxGroupAuthGas, big.NewInt(10), nil).WithGroupAuth(&types.GroupAuth{ID: "computer", Sig: []byte(sig)})
+		tx, _ = types.SignFeePayer(tx, signer, payerKey)
+		return tx
+	}
+	if _, err := blockchain.InsertChain(types.Blocks{GenerateBadBlock(genesis, ethash.NewFaker(), types.Transactions{anonymous("invalid")})}); !errors.Is(err, ErrGroupAuth) {
+		t.Fatalf("invalid group signature error mismatch: have %v, want %v", err, ErrGroupAuth)
+	}
+	tx := anonymous("valid")
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, b *BlockGen) {
+		b.AddTx(tx)
+	})
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert anonymous transaction: %v", err)
+	}
+	state, _ := blockchain.State()
+	if have, want := state.GetNonce(tx.GroupAuth().Address()), uint64(1); have != want {
+		t.Errorf("group nonce mismatch: have %v, want %v", have, want)
+	}
+	if have, want := state.GetBalance(payer), big.NewInt(10000000-int64(params.TxGas+params.TxGroupAuthGas)*10); have.Cmp(want) != 0 {
+		t.Errorf("fee payer balance mismatch: have %v, want %v", have, want)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// commitmentSlots is the maximum number of outstanding transaction commitments.
+const commitmentSlots = 1024
+
+var (
+	// ErrCommitRevealDisabled is returned if a commitment or reveal is submitted
+	// while the commit-reveal submission path is not enabled.
+	ErrCommitRevealDisabled = errors.New("commit-reveal disabled")
+
+	// ErrUnknownCommitment is returned if a transaction is revealed without any
+	// matching commitment.
+	ErrUnknownCommitment = errors.New("unknown commitment")
+
+	// ErrCommitmentPending is returned if a transaction is revealed before its
+	// commitment was ordered into a block.
+	ErrCommitmentPending = errors.New("commitment not yet ordered")
+)
+
+// txCommitment is a commitment to a transaction, the hash of the signed
+// transaction, submitted ahead of the transaction itself. Commitments are
+// ordered by the local miner: one is considered included in the first block
+// following its submission. The committed transaction may only be revealed
+// afterwards, and has to be included within the reveal window.
+//
+// Until then, the content of the transaction is neither propagated nor visible
+// to anyone, so it cannot be front-run. Revealed transactions are included by
+// the miner ahead of the public ones, in the order of their commitments.
+type txCommitment struct {
+	seq    uint64             // Submission order of the commitment
+	number uint64             // Block the commitment was ordered in, zero until then
+	tx     *types.Transaction // Revealed transaction, nil until revealed
+}
+
+// Commit registers the commitment to a transaction, the hash of the signed
+// transaction to be revealed later via Reveal.
+func (pool *TxPool) Commit(hash common.Hash) error {
+	if pool.config.RevealWindow == 0 {
+		return ErrCommitRevealDisabled
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.commitments[hash] != nil {
+		return ErrAlreadyKnown
+	}
+	if len(pool.commitments) >= commitmentSlots {
+		return ErrTxPoolOverflow
+	}
+	pool.commitSeq++
+	pool.commitments[hash] = &txCommitment{seq: pool.commitSeq}
+
+	log.Trace("Registered transaction commitment", "hash", hash)
+	return nil
+}
+
+// Reveal discloses a transaction whose commitment was already ordered into a
+// block. The transaction is kept aside from the public pool and is included
+// by the local miner in commitment order.
+func (pool *TxPool) Reveal(tx *types.Transaction) error {
+	if pool.config.RevealWindow == 0 {
+		return ErrCommitRevealDisabled
+	}
+	hash := tx.Hash()
+	if _, err := types.Sender(pool.signer, tx); err != nil {
+		return ErrInvalidSender
+	}
+	if err := pool.preverifyGroupAuth(tx); err != nil {
+		return err
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	commitment := pool.commitments[hash]
+	switch {
+	case commitment == nil:
+		return ErrUnknownCommitment
+	case commitment.number == 0:
+		return ErrCommitmentPending
+	case commitment.tx != nil:
+		return ErrAlreadyKnown
+	}
+	if err := pool.validateTx(tx, true); err != nil {
+		return err
+	}
+	commitment.tx = tx
+
+	log.Trace("Revealed committed transaction", "hash", hash, "committed", commitment.number)
+	return nil
+}
+
+// Revealed retrieves the revealed committed transactions, grouped by account and
+// sorted by nonce, together with the ordering to include them in: the order the
+// commitments were submitted in.
+func (pool *TxPool) Revealed() (map[common.Address]types.Transactions, types.TxOrdering) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	var (
+		txs  = make(map[common.Address]types.Transactions)
+		seqs = make(map[common.Hash]uint64)
+	)
+	for hash, commitment := range pool.commitments {
+		if commitment.tx == nil {
+			continue
+		}
+		addr, _ := types.Sender(pool.signer, commitment.tx) // already validated
+		txs[addr] = append(txs[addr], commitment.tx)
+		seqs[hash] = commitment.seq
+	}
+	for _, list := range txs {
+		sort.Sort(types.TxByNonce(list))
+	}
+	return txs, func(a, b *types.Transaction) bool {
+		return seqs[a.Hash()] < seqs[b.Hash()]
+	}
+}
+
+// processCommitments orders the fresh commitments into the given head, and drops
+// the ones already included or outside of their reveal window.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) processCommitments(head *types.Header) {
+	number := head.Number.Uint64()
+	for hash, commitment := range pool.commitments {
+		if commitment.number == 0 {
+			commitment.number = number
+			continue
+		}
+		if commitment.tx != nil {
+			addr, _ := types.Sender(pool.signer, commitment.tx) // already validated
+			if pool.currentState.GetNonce(addr) > commitment.tx.Nonce() {
+				delete(pool.commitments, hash)
+				continue
+			}
+		}
+		if number >= commitment.number+pool.config.RevealWindow {
+			log.Debug("Dropping commitment past its reveal window", "hash", hash, "committed", commitment.number, "revealed", commitment.tx != nil)
+			delete(pool.commitments, hash)
+		}
+	}
+}
@@ -168,6 +168,62 @@ func (journal *txJournal) rotate(all map[common.Address]types.Transactions) erro
 	return nil
 }
 
+// deadlineEntry is the inclusion deadline of a local transaction, journaled
+// alongside the transaction itself.
+type deadlineEntry struct {
+	Hash     common.Hash
+	Deadline uint64
+}
+
+// loadDeadlines parses the inclusion deadlines of the journaled transactions.
+func (journal *txJournal) loadDeadlines() (map[common.Hash]uint64, error) {
+	// Skip the parsing if the deadline journal doesn't exist at all
+	path := journal.path + ".deadlines"
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	input, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	var (
+		stream    = rlp.NewStream(input, 0)
+		deadlines = make(map[common.Hash]uint64)
+	)
+	for {
+		entry := new(deadlineEntry)
+		if err = stream.Decode(entry); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			break
+		}
+		deadlines[entry.Hash] = entry.Deadline
+	}
+	return deadlines, err
+}
+
+// saveDeadlines regenerates the journal of the inclusion deadlines. As the set
+// is expected to be small, it is regenerated in full on every change.
+func (journal *txJournal) saveDeadlines(deadlines map[common.Hash]uint64) error {
+	path := journal.path + ".deadlines"
+	replacement, err := os.OpenFile(path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	for hash, deadline := range deadlines {
+		if err = rlp.Encode(replacement, &deadlineEntry{Hash: hash, Deadline: deadline}); err != nil {
+			replacement.Close()
+			return err
+		}
+	}
+	replacement.Close()
+
+	return os.Rename(path+".new", path)
+}
+
 // close flushes the transaction journal contents to disk and closes the file.
 func (journal *txJournal) close() error {
 	var err error
@@ -84,6 +84,10 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrDeadlineExpired is returned if a transaction is submitted with an
+	// inclusion deadline that the chain head has already reached.
+	ErrDeadlineExpired = errors.New("inclusion deadline expired")
 )
 
 var (
@@ -111,6 +115,7 @@ var (
 	invalidTxMeter     = metrics.NewRegisteredMeter("txpool/invalid", nil)
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 	overflowedTxMeter  = metrics.NewRegisteredMeter("txpool/overflowed", nil)
+	expiredTxMeter     = metrics.NewRegisteredMeter("txpool/expired", nil) // Dropped due to passed inclusion deadline
 
 	pendingGauge = metrics.NewRegisteredGauge("txpool/pending", nil)
 	queuedGauge  = metrics.NewRegisteredGauge("txpool/queued", nil)
@@ -154,6 +159,13 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	DeadlineSlots uint64 // Number of slots reserved for transactions with an inclusion deadline
+
+	TimelockJournal string // Journal of timelocked transactions to survive node restarts (empty = disabled)
+	TimelockSlots   uint64 // Maximum number of timelocked transactions held back
+
+	RevealWindow uint64 // Blocks a committed transaction must be revealed and included within (0 = commit-reveal disabled)
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -171,6 +183,11 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	DeadlineSlots: 256,
+
+	TimelockJournal: "timelocked.rlp",
+	TimelockSlots:   1024,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -209,6 +226,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
 	}
+	if conf.TimelockSlots < 1 {
+		log.Warn("Sanitizing invalid txpool timelock slots", "provided", conf.TimelockSlots, "updated", DefaultTxPoolConfig.TimelockSlots)
+		conf.TimelockSlots = DefaultTxPoolConfig.TimelockSlots
+	}
 	return conf
 }
 
@@ -225,11 +246,14 @@ type TxPool struct {
 	chain       blockChain
 	gasPrice    *big.Int
 	txFeed      event.Feed
+	expiredFeed event.Feed
 	scope       event.SubscriptionScope
 	signer      types.Signer
 	mu          sync.RWMutex
 
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
+	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
+	feePayer bool // Fork indicator whether we are accepting sponsored transactions.
 
 	currentState  *state.StateDB // Current state in the blockchain head
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
@@ -244,6 +268,16 @@ type TxPool struct {
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price
 
+	deadlines map[common.Hash]uint64 // Inclusion deadlines (block numbers) of urgent transactions
+
+	sponsored map[common.Address]map[common.Hash]*big.Int // Gas fees committed by fee payers to pooled transactions
+
+	timelocked      map[common.Hash]*timelockedTx // Local transactions held back until their condition holds
+	timelockJournal *timelockJournal              // Journal of timelocked transactions to back up to disk
+
+	commitments map[common.Hash]*txCommitment // Commitments to transactions to be revealed later
+	commitSeq   uint64                        // Submission counter to order the commitments
+
 	chainHeadCh     chan ChainHeadEvent
 	chainHeadSub    event.Subscription
 	reqResetCh      chan *txpoolResetRequest
@@ -269,11 +303,15 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		config:          config,
 		chainconfig:     chainconfig,
 		chain:           chain,
-		signer:          types.NewEIP155Signer(chainconfig.ChainID),
+		signer:          types.LatestSigner(chainconfig),
 		pending:         make(map[common.Address]*txList),
 		queue:           make(map[common.Address]*txList),
 		beats:           make(map[common.Address]time.Time),
 		all:             newTxLookup(),
+		deadlines:       make(map[common.Hash]uint64),
+		sponsored:       make(map[common.Address]map[common.Hash]*big.Int),
+		timelocked:      make(map[common.Hash]*timelockedTx),
+		commitments:     make(map[common.Hash]*txCommitment),
 		chainHeadCh:     make(chan ChainHeadEvent, chainHeadChanSize),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
@@ -304,6 +342,33 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		if err := pool.journal.rotate(pool.local()); err != nil {
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
+		// Restore the deadlines of the reloaded transactions not expired since
+		deadlines, err := pool.journal.loadDeadlines()
+		if err != nil {
+			log.Warn("Failed to load transaction deadline journal", "err", err)
+		}
+		head := chain.CurrentBlock().NumberU64()
+
+		pool.mu.Lock()
+		for hash, deadline := range deadlines {
+			if deadline > head && pool.all.Get(hash) != nil {
+				pool.deadlines[hash] = deadline
+			}
+		}
+		pool.journalDeadlines()
+		pool.mu.Unlock()
+	}
+	// If timelocked transaction journaling is enabled, reload the held back ones
+	if !config.NoLocals && config.TimelockJournal != "" {
+		pool.timelockJournal = newTimelockJournal(config.TimelockJournal)
+
+		txs, err := pool.timelockJournal.load()
+		if err != nil {
+			log.Warn("Failed to load timelocked transaction journal", "err", err)
+		}
+		for _, tx := range txs {
+			pool.timelocked[tx.Tx.Hash()] = tx
+		}
 	}
 
 	// Subscribe events from blockchain and start the main event loop.
@@ -385,6 +450,7 @@ func (pool *TxPool) loop() {
 				if err := pool.journal.rotate(pool.local()); err != nil {
 					log.Warn("Failed to rotate local tx journal", "err", err)
 				}
+				pool.journalDeadlines()
 				pool.mu.Unlock()
 			}
 		}
@@ -412,6 +478,12 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscripti
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeDeadlineExpiredEvent registers a subscription of DeadlineExpiredEvent
+// and starts sending event to the given channel.
+func (pool *TxPool) SubscribeDeadlineExpiredEvent(ch chan<- DeadlineExpiredEvent) event.Subscription {
+	return pool.scope.Track(pool.expiredFeed.Subscribe(ch))
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -523,6 +595,14 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
+	// Accept access list transactions only once EIP-2718/2930 activates.
+	if !pool.eip2718 && tx.Type() == types.AccessListTxType {
+		return ErrTxTypeNotSupported
+	}
+	// Accept sponsored transactions only once the fee payer fork activates.
+	if !pool.feePayer && tx.Sponsored() {
+		return ErrTxTypeNotSupported
+	}
 	// Reject transactions over defined size to prevent DOS attacks
 	if uint64(tx.Size()) > txMaxSize {
 		return ErrOversizedData
@@ -550,21 +630,124 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		return ErrNonceTooLow
 	}
 	// Transactor should have enough funds to cover the costs
-	// cost == V + GP * GL
-	if pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
+	// cost == V + GP * GL, or only V if the gas is sponsored
+	if pool.currentState.GetBalance(from).Cmp(tx.SenderCost()) < 0 {
 		return ErrInsufficientFunds
 	}
+	// The fee payer of a sponsored transaction should cover the gas
+	if tx.Sponsored() {
+		payer, err := types.FeePayer(pool.signer, tx)
+		if err != nil {
+			return types.ErrInvalidFeePayer
+		}
+		// Fee payers may sponsor many transactions, so they have to cover the
+		// gas of all of them, not just the one at hand
+		fee := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+		if payer == from {
+			fee = tx.Cost()
+		} else {
+			fee.Add(fee, pool.sponsoredSpend(payer, tx.Hash()))
+		}
+		if pool.currentState.GetBalance(payer).Cmp(fee) < 0 {
+			return ErrInsufficientFunds
+		}
+	}
 	// Ensure the transaction has more gas than the basic tx fee.
-	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, true, pool.istanbul)
+	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
 	if err != nil {
 		return err
 	}
+	if tx.Anonymous() {
+		intrGas += params.TxGroupAuthGas
+	}
 	if tx.Gas() < intrGas {
 		return ErrIntrinsicGas
 	}
+	// Verify the group signature of anonymous transactions last, it's expensive.
+	// Callers verify it ahead without holding the lock, so this is a cache hit.
+	if tx.Anonymous() {
+		return VerifyGroupAuth(pool.signer, tx)
+	}
 	return nil
 }
 
+// sponsoredSpend sums the gas fees the given fee payer committed to across the
+// pooled transactions it sponsors, except the given one, pruning the ones no
+// longer in the pool.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) sponsoredSpend(payer common.Address, skip common.Hash) *big.Int {
+	spend := new(big.Int)
+	for hash, fee := range pool.sponsored[payer] {
+		if pool.all.Get(hash) == nil {
+			delete(pool.sponsored[payer], hash)
+			continue
+		}
+		if hash != skip {
+			spend.Add(spend, fee)
+		}
+	}
+	if len(pool.sponsored[payer]) == 0 {
+		delete(pool.sponsored, payer)
+	}
+	return spend
+}
+
+// trackSponsored records the gas fee of a freshly pooled transaction against
+// its fee payer, if sponsored by an account other than its sender.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) trackSponsored(from common.Address, tx *types.Transaction) {
+	if !tx.Sponsored() {
+		return
+	}
+	payer, _ := types.FeePayer(pool.signer, tx) // already validated
+	if payer == from {
+		return
+	}
+	if pool.sponsored[payer] == nil {
+		pool.sponsored[payer] = make(map[common.Hash]*big.Int)
+	}
+	pool.sponsored[payer][tx.Hash()] = new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+}
+
+// demoteUnsponsored drops sponsored transactions, the most expensive first, from
+// fee payers that can no longer cover the gas of all the ones they sponsor.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) demoteUnsponsored() {
+	for payer := range pool.sponsored {
+		var (
+			balance = pool.currentState.GetBalance(payer)
+			spend   = pool.sponsoredSpend(payer, common.Hash{})
+		)
+		if spend.Cmp(balance) <= 0 {
+			continue
+		}
+		hashes := make([]common.Hash, 0, len(pool.sponsored[payer]))
+		for hash := range pool.sponsored[payer] {
+			hashes = append(hashes, hash)
+		}
+		fees := pool.sponsored[payer]
+		sort.Slice(hashes, func(i, j int) bool { return fees[hashes[i]].Cmp(fees[hashes[j]]) > 0 })
+
+		for _, hash := range hashes {
+			if spend.Cmp(balance) <= 0 {
+				break
+			}
+			spend.Sub(spend, fees[hash])
+			delete(fees, hash)
+
+			log.Trace("Removed unsponsored transaction", "hash", hash, "payer", payer)
+			pool.removeTx(hash, true)
+			pendingNofundsMeter.Mark(1)
+		}
+		if len(fees) == 0 {
+			delete(pool.sponsored, payer)
+		}
+	}
+}
+
 // add validates a transaction and inserts it into the non-executable queue for later
 // pending promotion and execution. If the transaction is a replacement for an already
 // pending or queued one, it overwrites the previous transaction if its price is higher.
@@ -590,8 +773,17 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		invalidTxMeter.Mark(1)
 		return false, err
 	}
-	// If the transaction pool is full, discard underpriced transactions
-	if uint64(pool.all.Count()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
+	// If the transaction pool is full, discard underpriced transactions. Transactions
+	// with an inclusion deadline may use the reserved slots instead, which aren't
+	// available to any other transaction.
+	var (
+		reserved = pool.deadlineSlots(hash)
+		capacity = pool.config.GlobalSlots + pool.config.GlobalQueue + reserved
+	)
+	if _, urgent := pool.deadlines[hash]; urgent && reserved+uint64(numSlots(tx)) <= pool.config.DeadlineSlots {
+		capacity += uint64(numSlots(tx))
+	}
+	if uint64(pool.all.Count()+numSlots(tx)) > capacity {
 		// If the new transaction is underpriced, don't accept it
 		if !isLocal && pool.priced.Underpriced(tx) {
 			log.Trace("Discarding underpriced transaction", "hash", hash, "price", tx.GasPrice())
@@ -601,7 +793,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		// New transaction is better than our worse ones, make room for it.
 		// If it's a local transaction, forcibly discard all available transactions.
 		// Otherwise if we can't make enough room for new one, abort the operation.
-		drop, success := pool.priced.Discard(pool.all.Slots()-int(pool.config.GlobalSlots+pool.config.GlobalQueue)+numSlots(tx), isLocal)
+		drop, success := pool.priced.Discard(pool.all.Slots()-int(pool.config.GlobalSlots+pool.config.GlobalQueue+reserved)+numSlots(tx), isLocal)
 
 		// Special case, we still can't make the room for the new remote one.
 		if !isLocal && !success {
@@ -633,6 +825,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
+		pool.trackSponsored(from, tx)
 		pool.journalTx(from, tx)
 		pool.queueTxEvent(tx)
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
@@ -646,6 +839,8 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	if err != nil {
 		return false, err
 	}
+	pool.trackSponsored(from, tx)
+
 	// Mark local addresses and journal local transactions
 	if local && !pool.locals.contains(from) {
 		log.Info("Setting new local account", "address", from)
@@ -770,6 +965,211 @@ func (pool *TxPool) AddLocal(tx *types.Transaction) error {
 	return errs[0]
 }
 
+// AddLocalWithDeadline enqueues a single local transaction into the pool, tagging
+// it with the number of the last block it must be included in. Such transactions
+// may use the reserved deadline slots of the pool, are prioritised by the miner
+// and are dropped with a DeadlineExpiredEvent once the deadline passes.
+func (pool *TxPool) AddLocalWithDeadline(tx *types.Transaction, deadline uint64) error {
+	if deadline <= pool.chain.CurrentBlock().NumberU64() {
+		return ErrDeadlineExpired
+	}
+	if _, err := types.Sender(pool.signer, tx); err != nil {
+		invalidTxMeter.Mark(1)
+		return ErrInvalidSender
+	}
+	if err := pool.preverifyGroupAuth(tx); err != nil {
+		invalidTxMeter.Mark(1)
+		return err
+	}
+	// Tag the transaction and add it atomically, so that it's never counted as
+	// stale by the reserved slot accounting
+	hash := tx.Hash()
+
+	pool.mu.Lock()
+	old, exists := pool.deadlines[hash]
+	if !exists || deadline < old {
+		pool.deadlines[hash] = deadline
+	}
+	errs, dirty := pool.addTxsLocked([]*types.Transaction{tx}, !pool.config.NoLocals)
+	if err := errs[0]; err != nil && err != ErrAlreadyKnown {
+		if exists {
+			pool.deadlines[hash] = old
+		} else {
+			delete(pool.deadlines, hash)
+		}
+	} else {
+		pool.journalDeadlines()
+	}
+	pool.mu.Unlock()
+
+	<-pool.requestPromoteExecutables(dirty)
+	return errs[0]
+}
+
+// deadlineSlots returns the number of reserved slots taken by the transactions
+// with an inclusion deadline, besides the one with the given hash. Deadlines of
+// transactions no longer in the pool are forgotten before counting.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) deadlineSlots(skip common.Hash) uint64 {
+	var slots uint64
+	for hash := range pool.deadlines {
+		if hash == skip {
+			continue
+		}
+		tx := pool.all.Get(hash)
+		if tx == nil {
+			delete(pool.deadlines, hash)
+			continue
+		}
+		slots += uint64(numSlots(tx))
+	}
+	if slots > pool.config.DeadlineSlots {
+		slots = pool.config.DeadlineSlots
+	}
+	return slots
+}
+
+// journalDeadlines regenerates the journal of the inclusion deadlines of the
+// local transactions, if enabled.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) journalDeadlines() {
+	if pool.journal == nil {
+		return
+	}
+	if err := pool.journal.saveDeadlines(pool.deadlines); err != nil {
+		log.Warn("Failed to journal transaction deadlines", "err", err)
+	}
+}
+
+// AddLocalWithCondition accepts a signed local transaction which may not be
+// included before the given condition holds. Until then the transaction is held
+// back outside of the pool, neither propagated nor mined, and may be cancelled.
+// If the condition already holds, the transaction is added to the pool directly.
+func (pool *TxPool) AddLocalWithCondition(tx *types.Transaction, cond TxCondition) error {
+	if cond.Met(pool.chain.CurrentBlock().Header()) {
+		return pool.AddLocal(tx)
+	}
+	hash := tx.Hash()
+	if _, err := types.Sender(pool.signer, tx); err != nil {
+		return ErrInvalidSender
+	}
+	if err := pool.preverifyGroupAuth(tx); err != nil {
+		return err
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.timelocked[hash] != nil || pool.all.Get(hash) != nil {
+		return ErrAlreadyKnown
+	}
+	if uint64(len(pool.timelocked)) >= pool.config.TimelockSlots {
+		return ErrTxPoolOverflow
+	}
+	if err := pool.validateTx(tx, true); err != nil {
+		return err
+	}
+	pool.timelocked[hash] = &timelockedTx{Tx: tx, Condition: cond}
+	pool.journalTimelocked()
+
+	log.Trace("Holding back timelocked transaction", "hash", hash, "block", cond.Block, "time", cond.Time)
+	return nil
+}
+
+// CancelTimelocked drops a held back timelocked transaction, reporting whether
+// it was found. Transactions already released into the pool can't be cancelled.
+func (pool *TxPool) CancelTimelocked(hash common.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.timelocked[hash] == nil {
+		return false
+	}
+	delete(pool.timelocked, hash)
+	pool.journalTimelocked()
+	return true
+}
+
+// Timelocked retrieves the held back timelocked transactions, grouped by account
+// and sorted by nonce.
+func (pool *TxPool) Timelocked() map[common.Address]types.Transactions {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	txs := make(map[common.Address]types.Transactions)
+	for _, timelocked := range pool.timelocked {
+		addr, _ := types.Sender(pool.signer, timelocked.Tx) // already validated
+		txs[addr] = append(txs[addr], timelocked.Tx)
+	}
+	for _, list := range txs {
+		sort.Sort(types.TxByNonce(list))
+	}
+	return txs
+}
+
+// releaseTimelocked moves the timelocked transactions whose condition holds on
+// top of the given head into the pool. Transactions the pool rejects are held
+// back for a retry on the next head, unless they can never become valid again.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) releaseTimelocked(head *types.Header) {
+	var released int
+	for hash, timelocked := range pool.timelocked {
+		if !timelocked.Condition.Met(head) {
+			continue
+		}
+		_, err := pool.add(timelocked.Tx, !pool.config.NoLocals)
+		switch err {
+		case nil:
+			log.Debug("Released timelocked transaction", "hash", hash, "number", head.Number, "time", head.Time)
+		case ErrAlreadyKnown:
+			log.Debug("Timelocked transaction already pooled", "hash", hash)
+		case ErrNonceTooLow:
+			log.Warn("Dropping stale timelocked transaction", "hash", hash, "err", err)
+		default:
+			log.Warn("Failed to release timelocked transaction, retrying", "hash", hash, "err", err)
+			continue
+		}
+		delete(pool.timelocked, hash)
+		released++
+	}
+	if released > 0 {
+		pool.journalTimelocked()
+	}
+}
+
+// journalTimelocked regenerates the timelocked transaction journal, if enabled.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) journalTimelocked() {
+	if pool.timelockJournal == nil {
+		return
+	}
+	txs := make([]*timelockedTx, 0, len(pool.timelocked))
+	for _, tx := range pool.timelocked {
+		txs = append(txs, tx)
+	}
+	if err := pool.timelockJournal.save(txs); err != nil {
+		log.Warn("Failed to journal timelocked transactions", "err", err)
+	}
+}
+
+// Deadlines retrieves the inclusion deadlines of all the transactions currently
+// in the pool that were submitted with one.
+func (pool *TxPool) Deadlines() map[common.Hash]uint64 {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	deadlines := make(map[common.Hash]uint64, len(pool.deadlines))
+	for hash, deadline := range pool.deadlines {
+		if pool.all.Get(hash) != nil {
+			deadlines[hash] = deadline
+		}
+	}
+	return deadlines
+}
+
 // AddRemotes enqueues a batch of transactions into the pool if they are valid. If the
 // senders are not among the locally tracked ones, full pricing constraints will apply.
 //
@@ -822,6 +1222,12 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 			invalidTxMeter.Mark(1)
 			continue
 		}
+		// Verify group signatures before obtaining the lock too, they're costly
+		if err := pool.preverifyGroupAuth(tx); err != nil {
+			errs[i] = err
+			invalidTxMeter.Mark(1)
+			continue
+		}
 		// Accumulate all unknown transactions for deeper processing
 		news = append(news, tx)
 	}
@@ -950,6 +1356,32 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 	}
 }
 
+// Clear drops all the transactions from the pool and resets it onto the current
+// chain head. It is meant for development chains rewound on demand, where the
+// pooled transactions no longer apply. Held back timelocked transactions are
+// kept, as they are only released when their condition holds.
+func (pool *TxPool) Clear() {
+	pool.mu.Lock()
+	var hashes []common.Hash
+	pool.all.Range(func(hash common.Hash, tx *types.Transaction, local bool) bool {
+		hashes = append(hashes, hash)
+		return true
+	}, true, true)
+	for _, hash := range hashes {
+		pool.removeTx(hash, true)
+	}
+	// Rotate the journal too, lest the dropped local transactions come back on restart
+	if pool.journal != nil {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate local tx journal", "err", err)
+		}
+		pool.journalDeadlines()
+	}
+	pool.mu.Unlock()
+
+	<-pool.requestReset(nil, pool.chain.CurrentBlock().Header())
+}
+
 // requestPromoteExecutables requests a pool reset to the new head block.
 // The returned channel is closed when the reset has occurred.
 func (pool *TxPool) requestReset(oldHead *types.Header, newHead *types.Header) chan struct{} {
@@ -1064,10 +1496,20 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 		promoteAddrs = dirtyAccounts.flatten()
 	}
 	pool.mu.Lock()
+	var head *types.Header
 	if reset != nil {
 		// Reset from the old head to the new, rescheduling any reorged transactions
 		pool.reset(reset.oldHead, reset.newHead)
 
+		// Release any timelocked transactions that became includable
+		if head = reset.newHead; head == nil {
+			head = pool.chain.CurrentBlock().Header()
+		}
+		pool.releaseTimelocked(head)
+
+		// Order fresh commitments into the new head and drop the stale ones
+		pool.processCommitments(head)
+
 		// Nonces were reset, discard any events that became stale
 		for addr := range events {
 			events[addr].Forward(pool.pendingNonces.get(addr))
@@ -1087,8 +1529,11 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 	// If a new block appeared, validate the pool of pending transactions. This will
 	// remove any transaction that has been included in the block or was invalidated
 	// because of another transaction (e.g. higher gas price).
+	var expired []*types.Transaction
 	if reset != nil {
 		pool.demoteUnexecutables()
+		pool.demoteUnsponsored()
+		expired = pool.expireDeadlines(head.Number.Uint64())
 	}
 	// Ensure pool.queue and pool.pending sizes stay within the configured limits.
 	pool.truncatePending()
@@ -1116,6 +1561,36 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 		}
 		pool.txFeed.Send(NewTxsEvent{txs})
 	}
+	if len(expired) > 0 {
+		pool.expiredFeed.Send(DeadlineExpiredEvent{expired})
+	}
+}
+
+// expireDeadlines drops all the transactions whose inclusion deadline has been
+// reached by the given head without them being included, returning them. It
+// also forgets the deadlines of transactions no longer in the pool.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) expireDeadlines(head uint64) []*types.Transaction {
+	var expired []*types.Transaction
+	for hash, deadline := range pool.deadlines {
+		tx := pool.all.Get(hash)
+		if tx == nil {
+			delete(pool.deadlines, hash)
+			continue
+		}
+		if head >= deadline {
+			log.Debug("Dropping transaction past its deadline", "hash", hash, "deadline", deadline, "head", head)
+			pool.removeTx(hash, true)
+			delete(pool.deadlines, hash)
+			expired = append(expired, tx)
+		}
+	}
+	if len(expired) > 0 {
+		pool.journalDeadlines()
+	}
+	expiredTxMeter.Mark(int64(len(expired)))
+	return expired
 }
 
 // reset retrieves the current state of the blockchain and ensures the content
@@ -1204,6 +1679,8 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// Update all fork indicator by next pending block number.
 	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
 	pool.istanbul = pool.chainconfig.IsIstanbul(next)
+	pool.eip2718 = pool.chainconfig.IsYoloV2(next)
+	pool.feePayer = pool.chainconfig.IsFeePayer(next)
 }
 
 // promoteExecutables moves transactions that have become processable from the
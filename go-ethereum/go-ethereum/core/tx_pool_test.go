@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
@@ -31,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
@@ -44,6 +46,7 @@ var testTxPoolConfig TxPoolConfig
 func init() {
 	testTxPoolConfig = DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
+	testTxPoolConfig.TimelockJournal = ""
 }
 
 type testBlockChain struct {
@@ -1933,6 +1936,727 @@ func TestTransactionSlotCount(t *testing.T) {
 	}
 }
 
+// Tests that transactions with an inclusion deadline can use the reserved pool
+// slots, and that they are dropped with an event once the deadline passes.
+func TestTransactionDeadlines(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.GlobalSlots = 1
+	config.GlobalQueue = 1
+	config.DeadlineSlots = 1
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	expired := make(chan DeadlineExpiredEvent, 1)
+	sub := pool.SubscribeDeadlineExpiredEvent(expired)
+	defer sub.Unsubscribe()
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	// Fill up the pool with remote transactions
+	if err := pool.AddRemotesSync([]*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(10), remote),
+		pricedTransaction(1, 100000, big.NewInt(10), remote),
+	}); err[0] != nil || err[1] != nil {
+		t.Fatalf("failed to add remote transactions: %v", err)
+	}
+	// Deadlines already reached by the head must be rejected
+	urgent := transaction(0, 100000, local)
+	if err := pool.AddLocalWithDeadline(urgent, 0); err != ErrDeadlineExpired {
+		t.Fatalf("expired deadline error mismatch: have %v, want %v", err, ErrDeadlineExpired)
+	}
+	// An urgent transaction must fit in the reserved slots without evicting anything
+	if err := pool.AddLocalWithDeadline(urgent, 2); err != nil {
+		t.Fatalf("failed to add urgent transaction: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	if deadlines := pool.Deadlines(); len(deadlines) != 1 || deadlines[urgent.Hash()] != 2 {
+		t.Fatalf("deadlines mismatch: have %v, want %x:2", deadlines, urgent.Hash())
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Advance the chain up to the deadline and ensure the transaction is dropped
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(1), GasLimit: 1000000})
+	if pool.Get(urgent.Hash()) == nil {
+		t.Fatalf("urgent transaction dropped before its deadline")
+	}
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(2), GasLimit: 1000000})
+	if pool.Get(urgent.Hash()) != nil {
+		t.Fatalf("urgent transaction not dropped after its deadline")
+	}
+	select {
+	case ev := <-expired:
+		if len(ev.Txs) != 1 || ev.Txs[0].Hash() != urgent.Hash() {
+			t.Fatalf("expired transactions mismatch: have %v, want %x", ev.Txs, urgent.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("deadline expiry event not fired")
+	}
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 2)
+	}
+	if deadlines := pool.Deadlines(); len(deadlines) != 0 {
+		t.Fatalf("stale deadlines retained: %v", deadlines)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that timelocked transactions are held back until their condition holds,
+// survive restarts through their journal and can be cancelled before release.
+func TestTransactionTimelock(t *testing.T) {
+	t.Parallel()
+
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+
+	file.Close()
+	os.Remove(journal)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.TimelockJournal = journal
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(addr, big.NewInt(1000000000))
+
+	// Hold back a transaction by block and one by time, with an immediate one too
+	byBlock, byTime, now := transaction(0, 100000, key), transaction(1, 100000, key), transaction(2, 100000, key)
+	if err := pool.AddLocalWithCondition(byBlock, TxCondition{Block: 2}); err != nil {
+		t.Fatalf("failed to add block timelocked transaction: %v", err)
+	}
+	if err := pool.AddLocalWithCondition(byTime, TxCondition{Time: 1000}); err != nil {
+		t.Fatalf("failed to add time timelocked transaction: %v", err)
+	}
+	if err := pool.AddLocalWithCondition(now, TxCondition{Block: 1}); err != nil {
+		t.Fatalf("failed to add unlocked transaction: %v", err)
+	}
+	if err := pool.AddLocalWithCondition(byBlock, TxCondition{Block: 2}); err != ErrAlreadyKnown {
+		t.Fatalf("duplicate timelocked error mismatch: have %v, want %v", err, ErrAlreadyKnown)
+	}
+	if pool.Has(byBlock.Hash()) || pool.Has(byTime.Hash()) || !pool.Has(now.Hash()) {
+		t.Fatalf("timelocked transactions not held back")
+	}
+	if txs := pool.Timelocked()[addr]; len(txs) != 2 {
+		t.Fatalf("timelocked transaction count mismatch: have %d, want %d", len(txs), 2)
+	}
+	pool.Stop()
+
+	// Restart the pool and ensure the timelocked transactions are reloaded
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if txs := pool.Timelocked()[addr]; len(txs) != 2 || txs[0].Hash() != byBlock.Hash() || txs[1].Hash() != byTime.Hash() {
+		t.Fatalf("timelocked transactions not reloaded: have %v", txs)
+	}
+	// Cancel the time locked one and release the block locked one
+	if !pool.CancelTimelocked(byTime.Hash()) {
+		t.Fatalf("failed to cancel timelocked transaction")
+	}
+	if pool.CancelTimelocked(byTime.Hash()) {
+		t.Fatalf("cancelled timelocked transaction twice")
+	}
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(1), GasLimit: 1000000})
+	if !pool.Has(byBlock.Hash()) {
+		t.Fatalf("timelocked transaction not released")
+	}
+	if pool.Has(byTime.Hash()) {
+		t.Fatalf("cancelled transaction released")
+	}
+	if txs := pool.Timelocked(); len(txs) != 0 {
+		t.Fatalf("released transactions still held back: %v", txs)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that timelocked transactions rejected by the pool on release are held
+// back for a retry, unless their nonce was already used up.
+func TestTransactionTimelockRetry(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	funded, _ := crypto.GenerateKey()
+	broke, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(funded.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(broke.PublicKey), big.NewInt(1000000000))
+
+	stale, unfunded := transaction(0, 100000, funded), transaction(0, 100000, broke)
+	for _, tx := range []*types.Transaction{stale, unfunded} {
+		if err := pool.AddLocalWithCondition(tx, TxCondition{Block: 2}); err != nil {
+			t.Fatalf("failed to add timelocked transaction: %v", err)
+		}
+	}
+	// Use up the nonce of the first account and drain the second one
+	statedb.SetNonce(crypto.PubkeyToAddress(funded.PublicKey), 1)
+	statedb.SetBalance(crypto.PubkeyToAddress(broke.PublicKey), new(big.Int))
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(1), GasLimit: 1000000})
+
+	if pool.Has(stale.Hash()) || pool.Has(unfunded.Hash()) {
+		t.Fatalf("invalid timelocked transactions released")
+	}
+	if txs := pool.Timelocked(); len(txs) != 1 || len(txs[crypto.PubkeyToAddress(broke.PublicKey)]) != 1 {
+		t.Fatalf("timelocked transactions mismatch: have %v, want the unfunded one only", txs)
+	}
+	// Fund the second account and ensure its transaction is released
+	statedb.AddBalance(crypto.PubkeyToAddress(broke.PublicKey), big.NewInt(1000000000))
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(2), GasLimit: 1000000})
+
+	if !pool.Has(unfunded.Hash()) {
+		t.Fatalf("timelocked transaction not released on retry")
+	}
+	if txs := pool.Timelocked(); len(txs) != 0 {
+		t.Fatalf("released transactions still held back: %v", txs)
+	}
+}
+
+// Tests that committed transactions can only be revealed once their commitment
+// was ordered into a block, are kept out of the public pool, and are dropped if
+// not included within the reveal window.
+func TestTransactionCommitReveal(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	// Commit-reveal is disabled by default
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	if err := pool.Commit(common.Hash{}); err != ErrCommitRevealDisabled {
+		t.Fatalf("disabled commit error mismatch: have %v, want %v", err, ErrCommitRevealDisabled)
+	}
+	pool.Stop()
+
+	config := testTxPoolConfig
+	config.RevealWindow = 2
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	first, _ := crypto.GenerateKey()
+	second, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(first.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(second.PublicKey), big.NewInt(1000000000))
+
+	late, early := transaction(0, 100000, first), transaction(0, 100000, second)
+	if err := pool.Reveal(early); err != ErrUnknownCommitment {
+		t.Fatalf("uncommitted reveal error mismatch: have %v, want %v", err, ErrUnknownCommitment)
+	}
+	for _, tx := range []*types.Transaction{early, late} {
+		if err := pool.Commit(tx.Hash()); err != nil {
+			t.Fatalf("failed to commit transaction: %v", err)
+		}
+	}
+	if err := pool.Reveal(early); err != ErrCommitmentPending {
+		t.Fatalf("premature reveal error mismatch: have %v, want %v", err, ErrCommitmentPending)
+	}
+	// Order the commitments into a block and reveal in reverse order
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(1), GasLimit: 1000000})
+	for _, tx := range []*types.Transaction{late, early} {
+		if err := pool.Reveal(tx); err != nil {
+			t.Fatalf("failed to reveal transaction: %v", err)
+		}
+	}
+	if pool.Has(early.Hash()) || pool.Has(late.Hash()) {
+		t.Fatalf("revealed transactions leaked into the public pool")
+	}
+	revealed, order := pool.Revealed()
+	if len(revealed) != 2 {
+		t.Fatalf("revealed account count mismatch: have %d, want %d", len(revealed), 2)
+	}
+	if !order(early, late) || order(late, early) {
+		t.Fatalf("revealed transactions not ordered by commitment")
+	}
+	// Advance past the reveal window and ensure the commitments are dropped
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(2), GasLimit: 1000000})
+	if revealed, _ := pool.Revealed(); len(revealed) != 2 {
+		t.Fatalf("revealed transactions dropped within the reveal window")
+	}
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(3), GasLimit: 1000000})
+	if revealed, _ := pool.Revealed(); len(revealed) != 0 {
+		t.Fatalf("revealed transactions not dropped past the reveal window")
+	}
+}
+
+// Tests that sponsored transactions are funded by their fee payers: the sender
+// only needs to cover the value, the fee payer the gas.
+func TestTransactionSponsored(t *testing.T) {
+	t.Parallel()
+
+	pool, senderKey := setupTxPool()
+	defer pool.Stop()
+
+	payerKey, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(senderKey.PublicKey)
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+
+	signer := types.LatestSigner(params.TestChainConfig)
+	tx, _ := types.SignTx(types.NewSponsoredTransaction(0, common.Address{}, big.NewInt(100), 100000, big.NewInt(1), nil), signer, senderKey)
+
+	// A missing fee payer signature is rejected
+	pool.currentState.AddBalance(sender, big.NewInt(100))
+	if err := pool.AddRemote(tx); err != types.ErrInvalidFeePayer {
+		t.Fatalf("unsigned fee payer error mismatch: have %v, want %v", err, types.ErrInvalidFeePayer)
+	}
+	// An underfunded fee payer is rejected, the sender's funds don't count
+	tx, _ = types.SignFeePayer(tx, signer, payerKey)
+	pool.currentState.AddBalance(payer, big.NewInt(99999))
+	if err := pool.AddRemote(tx); err != ErrInsufficientFunds {
+		t.Fatalf("underfunded fee payer error mismatch: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	// A funded fee payer is accepted
+	pool.currentState.AddBalance(payer, big.NewInt(1))
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("failed to add sponsored transaction: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that sponsored transactions are only accepted once the fee payer fork
+// is activated.
+func TestTransactionSponsoredFork(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.FeePayerBlock = nil
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	pool := NewTxPool(testTxPoolConfig, &config, &testBlockChain{statedb, 10000000, new(event.Feed)})
+	defer pool.Stop()
+
+	senderKey, _ := crypto.GenerateKey()
+	payerKey, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(senderKey.PublicKey), big.NewInt(1000000))
+	statedb.AddBalance(crypto.PubkeyToAddress(payerKey.PublicKey), big.NewInt(1000000))
+
+	signer := types.LatestSigner(params.TestChainConfig)
+	tx, _ := types.SignTx(types.NewSponsoredTransaction(0, common.Address{}, big.NewInt(100), 100000, big.NewInt(1), nil), signer, senderKey)
+	tx, _ = types.SignFeePayer(tx, signer, payerKey)
+
+	// Before the fork the pool's signer can't even recover the sender
+	if err := pool.AddRemote(tx); err != ErrInvalidSender {
+		t.Fatalf("pre-fork sponsored transaction error mismatch: have %v, want %v", err, ErrInvalidSender)
+	}
+	if _, err := types.Sender(types.MakeSigner(&config, common.Big1), tx); err != types.ErrTxTypeNotSupported {
+		t.Fatalf("pre-fork sponsored sender error mismatch: have %v, want %v", err, types.ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that fee payers have to cover the gas of all the transactions they
+// sponsor, and that their sponsored transactions are dropped if they no longer
+// can after a reset.
+func TestTransactionSponsoredSpend(t *testing.T) {
+	t.Parallel()
+
+	pool, firstKey := setupTxPool()
+	defer pool.Stop()
+
+	secondKey, _ := crypto.GenerateKey()
+	payerKey, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+
+	signer := types.LatestSigner(params.TestChainConfig)
+	sponsored := func(key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewSponsoredTransaction(0, common.Address{}, new(big.Int), 100000, big.NewInt(1), nil), signer, key)
+		tx, _ = types.SignFeePayer(tx, signer, payerKey)
+		return tx
+	}
+	first, second := sponsored(firstKey), sponsored(secondKey)
+
+	// The fee payer can only cover one of the transactions
+	pool.currentState.AddBalance(payer, big.NewInt(150000))
+	if err := pool.addRemoteSync(first); err != nil {
+		t.Fatalf("failed to add first sponsored transaction: %v", err)
+	}
+	if err := pool.AddRemote(second); err != ErrInsufficientFunds {
+		t.Fatalf("overcommitted fee payer error mismatch: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	// Fund both of them, then drain the fee payer and ensure one gets dropped
+	pool.currentState.AddBalance(payer, big.NewInt(50000))
+	if err := pool.addRemoteSync(second); err != nil {
+		t.Fatalf("failed to add second sponsored transaction: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 2)
+	}
+	pool.currentState.SetBalance(payer, big.NewInt(150000))
+	<-pool.requestReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending+queued != 1 {
+		t.Fatalf("pooled transactions mismatched: have %d, want %d", pending+queued, 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that anonymous transactions are only accepted with a valid group
+// signature, and that they are charged for its verification.
+func TestTransactionAnonymous(t *testing.T) {
+	pool, payerKey := setupTxPool()
+	defer pool.Stop()
+
+	// Swap out the pairing based verification, accepting a fixed signature and
+	// tracking whether it's run under the pool lock
+	var (
+		verified int
+		locked   bool
+	)
+	defer func(verify func([]byte, []byte, string, string) (bool, error)) { verifyGroupSignature = verify }(verifyGroupSignature)
+	verifyGroupSignature = func(key, sig []byte, message string, grpID string) (bool, error) {
+		verified++
+
+		done := make(chan struct{})
+		go func() {
+			pool.mu.RLock()
+			pool.mu.RUnlock()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(100 * time.Millisecond):
+			locked = true
+		}
+		return bytes.Equal(sig, []byte("valid")), nil
+	}
+
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+	pool.currentState.AddBalance(payer, big.NewInt(1000000000))
+
+	signer := types.LatestSigner(params.TestChainConfig)
+	anonymous := func(gas uint64, sig string) *types.Transaction {
+		tx, _ := types.NewSponsoredTransaction(0, common.Address{}, new(big.Int), gas, big.NewInt(1), nil).WithGroupAuth(&types.GroupAuth{ID: "computer", Sig: []byte(sig)})
+		tx, _ = types.SignFeePayer(tx, signer, payerKey)
+		return tx
+	}
+	// Transactions failing the cheap checks are rejected ahead of any pairing
+	poorKey, _ := crypto.GenerateKey()
+	poor, _ := types.SignFeePayer(anonymous(params.TxGas+params.TxGroupAuthGas, "valid"), signer, poorKey)
+	if err := pool.AddRemote(poor); err != ErrInsufficientFunds {
+		t.Fatalf("underfunded fee payer error mismatch: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	group := (&types.GroupAuth{ID: "computer"}).Address()
+	pool.currentState.SetNonce(group, 1)
+	if err := pool.AddRemote(anonymous(params.TxGas+params.TxGroupAuthGas, "valid")); err != ErrNonceTooLow {
+		t.Fatalf("stale nonce error mismatch: have %v, want %v", err, ErrNonceTooLow)
+	}
+	pool.currentState.SetNonce(group, 0)
+	if verified != 0 {
+		t.Fatalf("group signature verified ahead of the cheap checks")
+	}
+	if err := pool.AddRemote(anonymous(params.TxGas+params.TxGroupAuthGas, "invalid")); err != ErrGroupAuth {
+		t.Fatalf("invalid group signature error mismatch: have %v, want %v", err, ErrGroupAuth)
+	}
+	if err := pool.AddRemote(anonymous(params.TxGas, "valid")); err != ErrIntrinsicGas {
+		t.Fatalf("uncharged verification error mismatch: have %v, want %v", err, ErrIntrinsicGas)
+	}
+	tx := anonymous(params.TxGas+params.TxGroupAuthGas, "valid")
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("failed to add anonymous transaction: %v", err)
+	}
+	// The signatures should have been verified once each, outside the lock
+	if verified != 2 {
+		t.Errorf("group signature verification count mismatch: have %d, want %d", verified, 2)
+	}
+	if locked {
+		t.Errorf("group signature verified under the pool lock")
+	}
+	if err := VerifyGroupAuth(signer, tx); err != nil || verified != 2 {
+		t.Errorf("group signature verification not cached: err %v, count %d", err, verified)
+	}
+	if pending := pool.pending[group]; pending == nil || pending.Len() != 1 {
+		t.Fatalf("anonymous transaction not pending for the group account")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that anonymous transactions are accepted with genuine group signatures
+// and rejected with signatures of other messages, through the actual pairings.
+func TestTransactionAnonymousPairing(t *testing.T) {
+	pool, payerKey := setupTxPool()
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(payerKey.PublicKey), big.NewInt(1000000000))
+
+	// Set up a group with a single manager, issuing the key of a single member
+	mpk, _, gski, _, _, err := vm.NewSetup(1, 1, vm.PrecompiledGroupID)
+	if err != nil {
+		t.Fatalf("failed to set up group: %v", err)
+	}
+	key := vm.EncodeGroupKey(mpk)
+	share := vm.EncodeGroupKeyShare(vm.ExtShare(gski, "member"))
+
+	signer := types.LatestSigner(params.TestChainConfig)
+	anonymous := func(nonce uint64, message common.Hash) *types.Transaction {
+		sig, err := vm.SignGroupMessage(key, [][]byte{share}, 1, message.Hex(), vm.PrecompiledGroupID, "member")
+		if err != nil {
+			t.Fatalf("failed to sign group message: %v", err)
+		}
+		tx, _ := types.NewSponsoredTransaction(nonce, common.Address{}, new(big.Int), params.TxGas+params.TxGroupAuthGas, big.NewInt(1), nil).WithGroupAuth(&types.GroupAuth{ID: vm.PrecompiledGroupID, Key: key, Sig: sig})
+		tx, _ = types.SignFeePayer(tx, signer, payerKey)
+		return tx
+	}
+	// Sign the sender hash of another transaction, then the right one
+	other := types.NewSponsoredTransaction(1, common.Address{}, new(big.Int), 0, nil, nil)
+	if err := pool.AddRemote(anonymous(0, signer.Hash(other))); err != ErrGroupAuth {
+		t.Fatalf("misdirected group signature error mismatch: have %v, want %v", err, ErrGroupAuth)
+	}
+	unsigned := types.NewSponsoredTransaction(0, common.Address{}, new(big.Int), 0, nil, nil)
+	if err := pool.addRemoteSync(anonymous(0, signer.Hash(unsigned))); err != nil {
+		t.Fatalf("failed to add anonymous transaction: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+}
+
+// Tests that typed transactions are only accepted once EIP-2718 is activated.
+func TestTransactionTyped(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	accesses := types.AccessList{{Address: common.Address{1}, StorageKeys: []common.Hash{{2}}}}
+	tx, _ := types.SignNewTx(key, types.NewEIP2930Signer(params.TestChainConfig.ChainID), &types.AccessListTx{
+		ChainID:    params.TestChainConfig.ChainID,
+		Gas:        params.TxGas + params.TxAccessListAddressGas + params.TxAccessListStorageKeyGas,
+		GasPrice:   big.NewInt(1),
+		To:         &common.Address{},
+		AccessList: accesses,
+	})
+	// Before the fork the pool's signer can't even recover the sender
+	if err := pool.AddRemote(tx); err != ErrInvalidSender {
+		t.Fatalf("pre-fork typed transaction error mismatch: have %v, want %v", err, ErrInvalidSender)
+	}
+	// Activate the fork and ensure the transaction is accepted, charged for its accesses
+	config := *params.TestChainConfig
+	config.YoloV2Block = big.NewInt(0)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	pool = NewTxPool(testTxPoolConfig, &config, &testBlockChain{statedb, 10000000, new(event.Feed)})
+	defer pool.Stop()
+
+	underpaid, _ := types.SignNewTx(key, types.NewEIP2930Signer(config.ChainID), &types.AccessListTx{
+		ChainID:    config.ChainID,
+		Gas:        params.TxGas + params.TxAccessListAddressGas,
+		GasPrice:   big.NewInt(1),
+		To:         &common.Address{},
+		AccessList: accesses,
+	})
+	if err := pool.AddRemote(underpaid); err != ErrIntrinsicGas {
+		t.Fatalf("uncharged access list error mismatch: have %v, want %v", err, ErrIntrinsicGas)
+	}
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("failed to add typed transaction: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that clearing the pool drops both pending and queued transactions, but
+// keeps the held back timelocked ones.
+func TestTransactionPoolClear(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(addr, big.NewInt(1000000000))
+
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add pending transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(transaction(2, 100000, key)); err != nil {
+		t.Fatalf("failed to add queued transaction: %v", err)
+	}
+	timelocked := transaction(1, 100000, key)
+	if err := pool.AddLocalWithCondition(timelocked, TxCondition{Block: 100}); err != nil {
+		t.Fatalf("failed to add timelocked transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 1, 1)
+	}
+	pool.Clear()
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("pool stats mismatch after clear: have %d/%d, want %d/%d", pending, queued, 0, 0)
+	}
+	if txs := pool.Timelocked()[addr]; len(txs) != 1 || txs[0].Hash() != timelocked.Hash() {
+		t.Fatalf("timelocked transaction dropped")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that cleared local transactions are dropped from the journal too, and
+// are not reloaded when the pool is restarted.
+func TestTransactionPoolClearJournal(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+	defer os.Remove(journal + ".deadlines")
+
+	file.Close()
+	os.Remove(journal)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Journal = journal
+	config.Rejournal = time.Hour
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	key, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		if err := pool.AddLocal(transaction(nonce, 100000, key)); err != nil {
+			t.Fatalf("failed to add local transaction %d: %v", nonce, err)
+		}
+	}
+	pool.Clear()
+	pool.Stop()
+
+	// Restart the pool and ensure the cleared transactions are gone for good
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("pool stats mismatch after restart: have %d/%d, want %d/%d", pending, queued, 0, 0)
+	}
+}
+
+// Tests that the deadlines of transactions no longer in the pool don't take up
+// the reserved slots, and that deadlines survive restarts through the journal.
+func TestTransactionDeadlineSlots(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+	defer os.Remove(journal + ".deadlines")
+
+	file.Close()
+	os.Remove(journal)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.GlobalSlots = 1
+	config.GlobalQueue = 1
+	config.DeadlineSlots = 1
+	config.Journal = journal
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	// Fill up the pool with remote transactions, and the reserved slot with an
+	// urgent one
+	if err := pool.AddRemotesSync([]*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(10), keys[0]),
+		pricedTransaction(1, 100000, big.NewInt(10), keys[0]),
+	}); err[0] != nil || err[1] != nil {
+		t.Fatalf("failed to add remote transactions: %v", err)
+	}
+	stale := transaction(0, 100000, keys[1])
+	if err := pool.AddLocalWithDeadline(stale, 10); err != nil {
+		t.Fatalf("failed to add urgent transaction: %v", err)
+	}
+	// Drop the urgent transaction without the deadline being cleaned up by a
+	// reset, and ensure the next one still gets the reserved slot
+	pool.mu.Lock()
+	pool.removeTx(stale.Hash(), true)
+	pool.mu.Unlock()
+
+	urgent := transaction(0, 100000, keys[2])
+	if err := pool.AddLocalWithDeadline(urgent, 10); err != nil {
+		t.Fatalf("failed to add urgent transaction: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	if deadlines := pool.Deadlines(); len(deadlines) != 1 || deadlines[urgent.Hash()] != 10 {
+		t.Fatalf("deadlines mismatch: have %v, want %x:10", deadlines, urgent.Hash())
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Restart the pool and ensure the deadline is restored with the transaction
+	pool.Stop()
+
+	blockchain = &testBlockChain{statedb, 1000000, new(event.Feed)}
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if pool.Get(urgent.Hash()) == nil {
+		t.Fatalf("urgent transaction not reloaded")
+	}
+	if deadlines := pool.Deadlines(); len(deadlines) != 1 || deadlines[urgent.Hash()] != 10 {
+		t.Fatalf("reloaded deadlines mismatch: have %v, want %x:10", deadlines, urgent.Hash())
+	}
+}
+
 // Benchmarks the speed of validating the contents of the pending queue of the
 // transaction pool.
 func BenchmarkPendingDemotion100(b *testing.B)   { benchmarkPendingDemotion(b, 100) }
@@ -0,0 +1,106 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// TxCondition is a release condition of a timelocked transaction. The transaction
+// is held back by the pool until both the block and the time conditions hold.
+type TxCondition struct {
+	Block uint64 // Number of the first block the transaction may be included in (0 = any)
+	Time  uint64 // Timestamp the chain head must reach before inclusion (0 = any)
+}
+
+// Met reports whether a transaction with this condition may be included into
+// the block following the given head.
+func (c TxCondition) Met(head *types.Header) bool {
+	return head.Number.Uint64()+1 >= c.Block && head.Time >= c.Time
+}
+
+// timelockedTx is a signed transaction held back until its condition holds.
+type timelockedTx struct {
+	Tx        *types.Transaction
+	Condition TxCondition
+}
+
+// timelockJournal is the disk backup of the timelocked transactions, allowing
+// them to survive node restarts. As the set is expected to be small, the journal
+// is regenerated in full on every change.
+type timelockJournal struct {
+	path string // Filesystem path to store the transactions at
+}
+
+// newTimelockJournal creates a new timelocked transaction journal.
+func newTimelockJournal(path string) *timelockJournal {
+	return &timelockJournal{
+		path: path,
+	}
+}
+
+// load parses a timelocked transaction journal dump from disk.
+func (journal *timelockJournal) load() ([]*timelockedTx, error) {
+	// Skip the parsing if the journal file doesn't exist at all
+	if _, err := os.Stat(journal.path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	input, err := os.Open(journal.path)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	var (
+		stream = rlp.NewStream(input, 0)
+		txs    []*timelockedTx
+	)
+	for {
+		tx := new(timelockedTx)
+		if err = stream.Decode(tx); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			break
+		}
+		txs = append(txs, tx)
+	}
+	log.Info("Loaded timelocked transaction journal", "transactions", len(txs))
+	return txs, err
+}
+
+// save regenerates the journal with the given timelocked transactions.
+func (journal *timelockJournal) save(txs []*timelockedTx) error {
+	replacement, err := os.OpenFile(journal.path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		if err = rlp.Encode(replacement, tx); err != nil {
+			replacement.Close()
+			return err
+		}
+	}
+	replacement.Close()
+
+	return os.Rename(journal.path+".new", journal.path)
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AnonymousTx is the data of anonymous transactions, authorized by a group
+// signature instead of the sender's and paid for by a fee payer.
+type AnonymousTx struct {
+	ChainID  *big.Int        // destination chain ID
+	Nonce    uint64          // nonce of the group account
+	GasPrice *big.Int        // wei per gas, set by the fee payer
+	Gas      uint64          // gas limit, set by the fee payer
+	To       *common.Address `rlp:"nil"` // nil means contract creation
+	Value    *big.Int        // wei amount
+	Data     []byte          // contract invocation input data
+	Group    GroupAuth       // group authorization of the sender
+
+	PayerV, PayerR, PayerS *big.Int // fee payer signature values
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *AnonymousTx) copy() TxData {
+	cpy := &AnonymousTx{
+		Nonce: tx.Nonce,
+		To:    tx.To, // TODO: copy pointed-to address
+		Data:  common.CopyBytes(tx.Data),
+		Gas:   tx.Gas,
+		Group: GroupAuth{
+			ID:  tx.Group.ID,
+			Key: common.CopyBytes(tx.Group.Key),
+			Sig: common.CopyBytes(tx.Group.Sig),
+		},
+		// These are copied below.
+		Value:    new(big.Int),
+		ChainID:  new(big.Int),
+		GasPrice: new(big.Int),
+		PayerV:   new(big.Int),
+		PayerR:   new(big.Int),
+		PayerS:   new(big.Int),
+	}
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.ChainID != nil {
+		cpy.ChainID.Set(tx.ChainID)
+	}
+	if tx.GasPrice != nil {
+		cpy.GasPrice.Set(tx.GasPrice)
+	}
+	if tx.PayerV != nil {
+		cpy.PayerV.Set(tx.PayerV)
+	}
+	if tx.PayerR != nil {
+		cpy.PayerR.Set(tx.PayerR)
+	}
+	if tx.PayerS != nil {
+		cpy.PayerS.Set(tx.PayerS)
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+
+func (tx *AnonymousTx) txType() byte           { return AnonymousTxType }
+func (tx *AnonymousTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *AnonymousTx) accessList() AccessList { return nil }
+func (tx *AnonymousTx) data() []byte           { return tx.Data }
+func (tx *AnonymousTx) gas() uint64            { return tx.Gas }
+func (tx *AnonymousTx) gasPrice() *big.Int     { return tx.GasPrice }
+func (tx *AnonymousTx) value() *big.Int        { return tx.Value }
+func (tx *AnonymousTx) nonce() uint64          { return tx.Nonce }
+func (tx *AnonymousTx) to() *common.Address    { return tx.To }
+
+// rawSignatureValues returns zero values, anonymous transactions carry no sender
+// signature.
+func (tx *AnonymousTx) rawSignatureValues() (v, r, s *big.Int) {
+	return new(big.Int), new(big.Int), new(big.Int)
+}
+
+func (tx *AnonymousTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID = chainID
+}
+
+// accessors for sponsoredTxData.
+
+func (tx *AnonymousTx) rawFeePayerSignatureValues() (v, r, s *big.Int) {
+	return tx.PayerV, tx.PayerR, tx.PayerS
+}
+
+func (tx *AnonymousTx) setFeePayerSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.PayerV, tx.PayerR, tx.PayerS = chainID, v, r, s
+}
+
+func (tx *AnonymousTx) setFeeParams(gas uint64, gasPrice *big.Int) {
+	tx.Gas, tx.GasPrice = gas, gasPrice
+	tx.PayerV, tx.PayerR, tx.PayerS = new(big.Int), new(big.Int), new(big.Int)
+}
@@ -0,0 +1,270 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Sponsored (fee delegated) transactions are signed by two parties: the sender
+// signs the nonce, recipient, value and payload, authorizing the call, while the
+// fee payer signs the gas price and limit on top of the sender's signature,
+// paying for the gas. The sender is charged the value only.
+//
+// Sponsored transactions are EIP-2718 typed transactions of their own type,
+// carrying the fee payer signature next to the sender's. They are only accepted
+// by the fee payer signer, from the fee payer fork on.
+
+var (
+	// ErrNotSponsored is returned if a fee payer signature is requested for a
+	// regular transaction.
+	ErrNotSponsored = errors.New("transaction not sponsored")
+
+	// ErrInvalidFeePayer is returned if the fee payer data of a sponsored
+	// transaction is malformed.
+	ErrInvalidFeePayer = errors.New("invalid fee payer")
+
+	// ErrSponsoredUnprotected is returned if a sponsored transaction is signed or
+	// its sender requested with a signer without replay protection.
+	ErrSponsoredUnprotected = errors.New("sponsored transaction requires EIP155 replay protection")
+)
+
+// sponsoredTxData is implemented by the data of the transaction types whose gas
+// is paid by a fee payer, SponsoredTx and AnonymousTx.
+type sponsoredTxData interface {
+	TxData
+
+	rawFeePayerSignatureValues() (v, r, s *big.Int)
+	setFeePayerSignatureValues(chainID, v, r, s *big.Int)
+
+	// setFeeParams sets the gas price and limit, clearing the fee payer signature
+	setFeeParams(gas uint64, gasPrice *big.Int)
+}
+
+// NewSponsoredTransaction creates a message call transaction whose gas is paid
+// by a fee payer. The gas price and limit are the fee payer's to decide, they
+// may be left empty when the sender signs.
+func NewSponsoredTransaction(nonce uint64, to common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
+	return newSponsoredTransaction(nonce, &to, amount, gasLimit, gasPrice, data)
+}
+
+// NewSponsoredContractCreation creates a contract creation transaction whose gas
+// is paid by a fee payer.
+func NewSponsoredContractCreation(nonce uint64, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
+	return newSponsoredTransaction(nonce, nil, amount, gasLimit, gasPrice, data)
+}
+
+func newSponsoredTransaction(nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
+	return NewTx(&SponsoredTx{
+		Nonce:    nonce,
+		To:       to,
+		Value:    amount,
+		Gas:      gasLimit,
+		GasPrice: gasPrice,
+		Data:     data,
+	})
+}
+
+// Sponsored returns whether the gas of the transaction is paid by a fee payer.
+func (tx *Transaction) Sponsored() bool {
+	_, ok := tx.inner.(sponsoredTxData)
+	return ok
+}
+
+// WithFeeParams returns a copy of the sponsored transaction with the gas price
+// and limit set by the fee payer. The sender signature remains valid as it does
+// not cover them, any previous fee payer signature is cleared.
+func (tx *Transaction) WithFeeParams(gasLimit uint64, gasPrice *big.Int) (*Transaction, error) {
+	if !tx.Sponsored() {
+		return nil, ErrNotSponsored
+	}
+	cpy := tx.inner.copy().(sponsoredTxData)
+	cpy.setFeeParams(gasLimit, new(big.Int).Set(gasPrice))
+	return &Transaction{inner: cpy, time: tx.time}, nil
+}
+
+// RawFeePayerSignatureValues returns the V, R, S signature values of the fee
+// payer, or nils if the transaction is not sponsored. The return values should
+// not be modified by the caller.
+func (tx *Transaction) RawFeePayerSignatureValues() (v, r, s *big.Int) {
+	inner, ok := tx.inner.(sponsoredTxData)
+	if !ok {
+		return nil, nil, nil
+	}
+	return inner.rawFeePayerSignatureValues()
+}
+
+// sponsoredHash returns the hash to be signed by the sender of a sponsored
+// transaction, or by the group of an anonymous one. It omits the gas price and
+// limit, which are for the fee payer to set. Both types share it, so that a
+// sponsored transaction may be authorized by a group instead of its sender.
+func sponsoredHash(tx *Transaction, chainId *big.Int) common.Hash {
+	return prefixedRlpHash(
+		SponsoredTxType,
+		[]interface{}{
+			chainId,
+			tx.Nonce(),
+			tx.To(),
+			tx.Value(),
+			tx.Data(),
+		})
+}
+
+// feePayerSigner implements Signer accepting sponsored and anonymous transactions
+// next to the transactions of the signer it extends.
+type feePayerSigner struct{ Signer }
+
+// NewFeePayerSigner returns a signer that accepts sponsored and anonymous
+// transactions on top of the transactions the given signer accepts. Sponsored
+// transactions require replay protection, so the given signer should have a
+// chain ID for any of them to be valid.
+func NewFeePayerSigner(signer Signer) Signer {
+	return feePayerSigner{signer}
+}
+
+func (s feePayerSigner) Equal(s2 Signer) bool {
+	x, ok := s2.(feePayerSigner)
+	return ok && x.Signer.Equal(s.Signer)
+}
+
+func (s feePayerSigner) Sender(tx *Transaction) (common.Address, error) {
+	if !tx.Sponsored() {
+		return s.Signer.Sender(tx)
+	}
+	chainID := s.ChainID()
+	if chainID == nil {
+		return common.Address{}, ErrSponsoredUnprotected
+	}
+	if tx.ChainId().Cmp(chainID) != 0 {
+		return common.Address{}, ErrInvalidChainId
+	}
+	// Anonymous transactions carry no sender signature, only the group
+	// authorization (verified separately, as it's expensive)
+	if auth := tx.GroupAuth(); auth != nil {
+		return auth.Address(), nil
+	}
+	// Sponsored transactions use 0 and 1 as their recovery id, like the access
+	// list ones
+	V, R, S := tx.RawSignatureValues()
+	V = new(big.Int).Add(V, big.NewInt(27))
+	return recoverPlain(s.Hash(tx), R, S, V, true)
+}
+
+func (s feePayerSigner) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	switch txdata := tx.inner.(type) {
+	case *SponsoredTx:
+		chainID := s.ChainID()
+		if chainID == nil {
+			return nil, nil, nil, ErrSponsoredUnprotected
+		}
+		// Check that chain ID of tx matches the signer. We also accept ID zero here,
+		// because it indicates that the chain ID was not specified in the tx.
+		if txdata.ChainID.Sign() != 0 && txdata.ChainID.Cmp(chainID) != 0 {
+			return nil, nil, nil, ErrInvalidChainId
+		}
+		R, S, _ = decodeSignature(sig)
+		V = big.NewInt(int64(sig[64]))
+		return R, S, V, nil
+	case *AnonymousTx:
+		// Anonymous transactions are authorized by their group alone
+		return nil, nil, nil, ErrInvalidGroupAuth
+	default:
+		return s.Signer.SignatureValues(tx, sig)
+	}
+}
+
+// Hash returns the hash to be signed by the sender.
+// It does not uniquely identify the transaction.
+func (s feePayerSigner) Hash(tx *Transaction) common.Hash {
+	if !tx.Sponsored() {
+		return s.Signer.Hash(tx)
+	}
+	return sponsoredHash(tx, s.ChainID())
+}
+
+// FeePayerHash returns the hash to be signed by the fee payer of a sponsored
+// transaction. It covers the sender's authorization, including its signature or
+// group authorization, and the gas price and limit.
+func FeePayerHash(signer Signer, tx *Transaction) common.Hash {
+	return rlpHash(feePayerPreimage(signer, tx))
+}
+
+// FeePayerSigningData returns the RLP encoded data whose Keccak256 hash is signed
+// by the fee payer, for wallets that hash the signed data themselves.
+func FeePayerSigningData(signer Signer, tx *Transaction) []byte {
+	data, _ := rlp.EncodeToBytes(feePayerPreimage(signer, tx))
+	return data
+}
+
+func feePayerPreimage(signer Signer, tx *Transaction) []interface{} {
+	v, r, s := tx.RawSignatureValues()
+	preimage := []interface{}{
+		signer.Hash(tx),
+		v,
+		r,
+		s,
+		tx.inner.gasPrice(),
+		tx.inner.gas(),
+	}
+	if auth := tx.GroupAuth(); auth != nil {
+		preimage = append(preimage, auth)
+	}
+	return preimage
+}
+
+// FeePayer returns the address paying the gas of the transaction: the address
+// derived from the fee payer signature for sponsored transactions, the sender
+// otherwise.
+//
+// FeePayer may cache the address, the same way Sender does.
+func FeePayer(signer Signer, tx *Transaction) (common.Address, error) {
+	if !tx.Sponsored() {
+		return Sender(signer, tx)
+	}
+	if sc := tx.payer.Load(); sc != nil {
+		sigCache := sc.(sigCache)
+		if sigCache.signer.Equal(signer) {
+			return sigCache.from, nil
+		}
+	}
+	// Fee payer signatures use 0 and 1 as their recovery id
+	v, r, s := tx.RawFeePayerSignatureValues()
+	v = new(big.Int).Add(v, big.NewInt(27))
+	addr, err := recoverPlain(FeePayerHash(signer, tx), r, s, v, true)
+	if err != nil {
+		return common.Address{}, err
+	}
+	tx.payer.Store(sigCache{signer: signer, from: addr})
+	return addr, nil
+}
+
+// SignFeePayer signs a sponsored transaction as its fee payer, using the given
+// signer and private key.
+func SignFeePayer(tx *Transaction, s Signer, prv *ecdsa.PrivateKey) (*Transaction, error) {
+	h := FeePayerHash(s, tx)
+	sig, err := crypto.Sign(h[:], prv)
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithFeePayerSignature(s, sig)
+}
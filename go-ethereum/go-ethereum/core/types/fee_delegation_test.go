@@ -0,0 +1,219 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that sponsored transactions can be signed by the sender and the fee
+// payer independently, and that both can be recovered afterwards.
+func TestSponsoredSigning(t *testing.T) {
+	senderKey, _ := crypto.GenerateKey()
+	payerKey, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(senderKey.PublicKey)
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+
+	signer := NewFeePayerSigner(NewEIP155Signer(big.NewInt(18)))
+
+	// The sender signs without knowing the gas parameters
+	tx, err := SignTx(NewSponsoredTransaction(0, common.Address{1}, big.NewInt(10), 0, new(big.Int), nil), signer, senderKey)
+	if err != nil {
+		t.Fatalf("failed to sign as sender: %v", err)
+	}
+	if !tx.Sponsored() {
+		t.Fatalf("transaction not sponsored")
+	}
+	// The fee payer fills them in and signs on top
+	tx, err = tx.WithFeeParams(21000, big.NewInt(2))
+	if err != nil {
+		t.Fatalf("failed to set fee parameters: %v", err)
+	}
+	tx, err = SignFeePayer(tx, signer, payerKey)
+	if err != nil {
+		t.Fatalf("failed to sign as fee payer: %v", err)
+	}
+	if from, err := Sender(signer, tx); err != nil || from != sender {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, sender)
+	}
+	if from, err := FeePayer(signer, tx); err != nil || from != payer {
+		t.Fatalf("fee payer mismatch: have %x (%v), want %x", from, err, payer)
+	}
+	if have, want := tx.SenderCost(), big.NewInt(10); have.Cmp(want) != 0 {
+		t.Errorf("sender cost mismatch: have %v, want %v", have, want)
+	}
+	// Changing the fee parameters invalidates the fee payer signature only
+	changed, _ := tx.WithFeeParams(21000, big.NewInt(3))
+	if from, err := Sender(signer, changed); err != nil || from != sender {
+		t.Errorf("sender mismatch after fee change: have %x (%v), want %x", from, err, sender)
+	}
+	if from, _ := FeePayer(signer, changed); from == payer {
+		t.Errorf("fee payer signature survived fee change")
+	}
+	// Sponsored transactions are only known to the fee payer signer, and require
+	// replay protection
+	if _, err := Sender(NewEIP155Signer(big.NewInt(18)), tx); err != ErrTxTypeNotSupported {
+		t.Errorf("eip155 sender error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	if _, err := Sender(NewFeePayerSigner(HomesteadSigner{}), tx); err != ErrSponsoredUnprotected {
+		t.Errorf("homestead sender error mismatch: have %v, want %v", err, ErrSponsoredUnprotected)
+	}
+	if _, err := Sender(NewFeePayerSigner(NewEIP155Signer(big.NewInt(19))), tx); err != ErrInvalidChainId {
+		t.Errorf("foreign chain sender error mismatch: have %v, want %v", err, ErrInvalidChainId)
+	}
+	// Regular transactions are their own fee payers
+	regular, _ := SignTx(NewTransaction(0, common.Address{1}, big.NewInt(10), 21000, big.NewInt(2), nil), signer, senderKey)
+	if from, err := FeePayer(signer, regular); err != nil || from != sender {
+		t.Errorf("regular fee payer mismatch: have %x (%v), want %x", from, err, sender)
+	}
+	if _, err := regular.WithFeePayerSignature(signer, make([]byte, 65)); err != ErrNotSponsored {
+		t.Errorf("regular fee payer signature error mismatch: have %v, want %v", err, ErrNotSponsored)
+	}
+}
+
+// Tests that sponsored transactions survive both RLP and JSON round trips.
+func TestSponsoredEncoding(t *testing.T) {
+	senderKey, _ := crypto.GenerateKey()
+	payerKey, _ := crypto.GenerateKey()
+	signer := NewFeePayerSigner(NewEIP155Signer(big.NewInt(18)))
+
+	tx, _ := SignTx(NewSponsoredContractCreation(3, big.NewInt(10), 50000, big.NewInt(2), []byte("abcdef")), signer, senderKey)
+	tx, _ = SignFeePayer(tx, signer, payerKey)
+
+	blob, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	if blob[0] != SponsoredTxType {
+		t.Errorf("transaction type mismatch: have %d, want %d", blob[0], SponsoredTxType)
+	}
+	if blob, err = rlp.EncodeToBytes(tx); err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	decoded := new(Transaction)
+	if err := rlp.DecodeBytes(blob, decoded); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if decoded.Hash() != tx.Hash() || !decoded.Sponsored() {
+		t.Errorf("rlp round trip mismatch: have %v, want %v", decoded, tx)
+	}
+	if have, _ := FeePayer(signer, decoded); have != crypto.PubkeyToAddress(payerKey.PublicKey) {
+		t.Errorf("rlp round trip fee payer mismatch: have %x", have)
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	parsed := new(Transaction)
+	if err := json.Unmarshal(data, parsed); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if parsed.Hash() != tx.Hash() {
+		t.Errorf("json round trip mismatch: have %v, want %v", parsed, tx)
+	}
+	// Regular transactions must not grow a fee payer element
+	regular, _ := SignTx(NewTransaction(0, common.Address{1}, big.NewInt(10), 21000, big.NewInt(2), nil), signer, senderKey)
+	var fields []rlp.RawValue
+	blob, _ = rlp.EncodeToBytes(regular)
+	if err := rlp.DecodeBytes(blob, &fields); err != nil {
+		t.Fatalf("failed to decode regular transaction: %v", err)
+	}
+	if len(fields) != 9 {
+		t.Errorf("regular transaction field count mismatch: have %d, want 9", len(fields))
+	}
+}
+
+// Tests that anonymous transactions are sent by their group account, sponsored
+// by the fee payer, and that the fee payer signature covers the group signature.
+func TestAnonymousTransaction(t *testing.T) {
+	payerKey, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+	signer := NewFeePayerSigner(NewEIP155Signer(big.NewInt(18)))
+
+	auth := &GroupAuth{ID: "computer", Key: []byte{0x01, 0x02}, Sig: []byte{0x03, 0x04}}
+	tx, err := NewSponsoredTransaction(0, common.Address{1}, big.NewInt(10), 0, new(big.Int), nil).WithGroupAuth(auth)
+	if err != nil {
+		t.Fatalf("failed to authorize by group: %v", err)
+	}
+	tx, _ = tx.WithFeeParams(121000, big.NewInt(2))
+	tx, _ = SignFeePayer(tx, signer, payerKey)
+
+	if !tx.Anonymous() {
+		t.Fatalf("transaction not anonymous")
+	}
+	if from, err := Sender(signer, tx); err != nil || from != auth.Address() {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, auth.Address())
+	}
+	if from, err := FeePayer(signer, tx); err != nil || from != payer {
+		t.Fatalf("fee payer mismatch: have %x (%v), want %x", from, err, payer)
+	}
+	// Different groups act through different accounts
+	other := &GroupAuth{ID: "other", Key: auth.Key, Sig: auth.Sig}
+	if other.Address() == auth.Address() {
+		t.Errorf("group accounts collide across identities")
+	}
+	// Replacing the group signature invalidates the fee payer signature
+	replaced, _ := tx.WithGroupAuth(&GroupAuth{ID: auth.ID, Key: auth.Key, Sig: []byte{0x05}})
+	replaced, _ = replaced.WithFeePayerSignature(signer, mustFeePayerSig(t, tx))
+	if from, _ := FeePayer(signer, replaced); from == payer {
+		t.Errorf("fee payer signature survived group signature change")
+	}
+	// Anonymous transactions can't carry a sender signature
+	if _, err := SignTx(tx, signer, payerKey); err != ErrInvalidGroupAuth {
+		t.Errorf("signed anonymous error mismatch: have %v, want %v", err, ErrInvalidGroupAuth)
+	}
+	// The group authorization survives both RLP and JSON round trips
+	if blob, _ := tx.MarshalBinary(); blob[0] != AnonymousTxType {
+		t.Errorf("transaction type mismatch: have %d, want %d", blob[0], AnonymousTxType)
+	}
+	blob, _ := rlp.EncodeToBytes(tx)
+	decoded := new(Transaction)
+	if err := rlp.DecodeBytes(blob, decoded); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if decoded.Hash() != tx.Hash() || !decoded.Anonymous() {
+		t.Errorf("rlp round trip mismatch: have %v, want %v", decoded, tx)
+	}
+	data, _ := json.Marshal(tx)
+	parsed := new(Transaction)
+	if err := json.Unmarshal(data, parsed); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if parsed.Hash() != tx.Hash() || !parsed.Anonymous() {
+		t.Errorf("json round trip mismatch: have %v, want %v", parsed, tx)
+	}
+}
+
+// mustFeePayerSig extracts the fee payer signature of a sponsored transaction
+// in the [R || S || V] format.
+func mustFeePayerSig(t *testing.T, tx *Transaction) []byte {
+	v, r, s := tx.RawFeePayerSignatureValues()
+	if v == nil {
+		t.Fatalf("transaction not sponsored")
+	}
+	sig := make([]byte, 65)
+	copy(sig[32-len(r.Bytes()):32], r.Bytes())
+	copy(sig[64-len(s.Bytes()):64], s.Bytes())
+	sig[64] = byte(v.Uint64())
+	return sig
+}
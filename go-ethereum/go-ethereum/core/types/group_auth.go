@@ -0,0 +1,117 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Anonymous transactions are authorized by a member of a TIBGS group instead of
+// an individual account. The group signature proves that one of the members
+// signed the transaction without revealing which one, yet the group managers
+// can jointly open it to trace the member.
+//
+// The sender of an anonymous transaction is the group account, derived from the
+// group's identity and master public key. As the group account has no key of
+// its own, anonymous transactions are always sponsored: the gas is paid by an
+// outside sponsor or the group's treasury account, signing as the fee payer.
+// Anonymous transactions are EIP-2718 typed transactions of their own type,
+// carrying the group authorization in place of the sender signature. The fee
+// payer signature covers it.
+//
+// The group signature is only checked for well-formedness here, its verification
+// is up to the transaction pool and the state processor.
+
+// ErrInvalidGroupAuth is returned if the group authorization of an anonymous
+// transaction is malformed.
+var ErrInvalidGroupAuth = errors.New("invalid group authorization")
+
+// GroupAuth is the TIBGS group signature authorizing an anonymous transaction.
+type GroupAuth struct {
+	ID  string // Identity of the group within its master key
+	Key []byte // Encoded TIBGS master public key of the group
+	Sig []byte // Encoded TIBGS group signature of the sender hash
+}
+
+// Address returns the group account the authorization acts on behalf of.
+func (a *GroupAuth) Address() common.Address {
+	return common.BytesToAddress(rlpHash([]interface{}{a.ID, a.Key}).Bytes()[12:])
+}
+
+// MarshalJSON encodes the group authorization with hex encoded key and signature.
+func (a GroupAuth) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"id":  a.ID,
+		"key": hexutil.Bytes(a.Key),
+		"sig": hexutil.Bytes(a.Sig),
+	})
+}
+
+// UnmarshalJSON decodes the group authorization with hex encoded key and signature.
+func (a *GroupAuth) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		ID  *string        `json:"id"`
+		Key *hexutil.Bytes `json:"key"`
+		Sig *hexutil.Bytes `json:"sig"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.ID == nil || dec.Key == nil || dec.Sig == nil {
+		return ErrInvalidGroupAuth
+	}
+	a.ID, a.Key, a.Sig = *dec.ID, *dec.Key, *dec.Sig
+	return nil
+}
+
+// Anonymous returns whether the transaction is authorized by a group signature.
+func (tx *Transaction) Anonymous() bool {
+	return tx.Type() == AnonymousTxType
+}
+
+// GroupAuth returns the group authorization of an anonymous transaction, or nil
+// for regular transactions. The return value should not be modified by the caller.
+func (tx *Transaction) GroupAuth() *GroupAuth {
+	if inner, ok := tx.inner.(*AnonymousTx); ok {
+		return &inner.Group
+	}
+	return nil
+}
+
+// WithGroupAuth returns an anonymous copy of the sponsored transaction, authorized
+// by the given group signature instead of the sender's. Any sender and fee payer
+// signatures are cleared.
+func (tx *Transaction) WithGroupAuth(auth *GroupAuth) (*Transaction, error) {
+	if !tx.Sponsored() {
+		return nil, ErrNotSponsored
+	}
+	inner := &AnonymousTx{
+		ChainID:  tx.ChainId(),
+		Nonce:    tx.Nonce(),
+		GasPrice: tx.inner.gasPrice(),
+		Gas:      tx.Gas(),
+		To:       tx.inner.to(),
+		Value:    tx.inner.value(),
+		Data:     tx.inner.data(),
+		Group:    *auth,
+	}
+	return &Transaction{inner: inner.copy(), time: tx.time}, nil
+}
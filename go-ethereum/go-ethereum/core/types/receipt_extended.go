@@ -0,0 +1,33 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+// ExtendedReceipt records the outcome of a transaction's top-level call which
+// the consensus receipt doesn't carry: the data returned by the call (or handed
+// to REVERT), the error aborting it and the gas the call itself consumed.
+//
+// Extended receipts are not part of consensus and are never exchanged with other
+// nodes; a node may opt into storing them alongside its regular receipts.
+type ExtendedReceipt struct {
+	ReturnData []byte // Data returned by the call, or supplied to REVERT
+	Err        string // Error aborting the call, empty if it succeeded
+	CallGas    uint64 // Gas used by the call, excluding intrinsic gas and refunds
+}
+
+// ExtendedReceipts is the list of extended receipts of a block, in transaction
+// order.
+type ExtendedReceipts []*ExtendedReceipt
@@ -154,9 +154,17 @@ func encodeAsV3StoredReceiptRLP(want *Receipt) ([]byte, error) {
 // Tests that receipt data can be correctly derived from the contextual infos
 func TestDeriveFields(t *testing.T) {
 	// Create a few transactions to have receipts for
+	to3 := common.HexToAddress("0x3")
 	txs := Transactions{
 		NewContractCreation(1, big.NewInt(1), 1, big.NewInt(1), nil),
 		NewTransaction(2, common.HexToAddress("0x2"), big.NewInt(2), 2, big.NewInt(2), nil),
+		NewTx(&AccessListTx{
+			Nonce:    3,
+			To:       &to3,
+			Value:    big.NewInt(3),
+			Gas:      3,
+			GasPrice: big.NewInt(3),
+		}),
 	}
 	// Create the corresponding receipts
 	receipts := Receipts{
@@ -182,6 +190,18 @@ func TestDeriveFields(t *testing.T) {
 			ContractAddress: common.BytesToAddress([]byte{0x02, 0x22, 0x22}),
 			GasUsed:         2,
 		},
+		&Receipt{
+			Type:              AccessListTxType,
+			PostState:         common.Hash{3}.Bytes(),
+			CumulativeGasUsed: 6,
+			Logs: []*Log{
+				{Address: common.BytesToAddress([]byte{0x33})},
+				{Address: common.BytesToAddress([]byte{0x03, 0x33})},
+			},
+			TxHash:          txs[2].Hash(),
+			ContractAddress: common.BytesToAddress([]byte{0x03, 0x33, 0x33}),
+			GasUsed:         3,
+		},
 	}
 	// Clear all the computed fields and re-derive them
 	number := big.NewInt(1)
@@ -196,6 +216,9 @@ func TestDeriveFields(t *testing.T) {
 
 	logIndex := uint(0)
 	for i := range receipts {
+		if receipts[i].Type != txs[i].Type() {
+			t.Errorf("receipts[%d].Type = %d, want %d", i, receipts[i].Type, txs[i].Type())
+		}
 		if receipts[i].TxHash != txs[i].Hash() {
 			t.Errorf("receipts[%d].TxHash = %s, want %s", i, receipts[i].TxHash.String(), txs[i].Hash().String())
 		}
@@ -254,6 +277,7 @@ func clearComputedFieldsOnReceipts(t *testing.T, receipts Receipts) {
 func clearComputedFieldsOnReceipt(t *testing.T, receipt *Receipt) {
 	t.Helper()
 
+	receipt.Type = 0
 	receipt.TxHash = common.Hash{}
 	receipt.BlockHash = common.Hash{}
 	receipt.BlockNumber = big.NewInt(math.MaxUint32)
@@ -281,3 +305,71 @@ func clearComputedFieldsOnLog(t *testing.T, log *Log) {
 	log.TxIndex = math.MaxUint32
 	log.Index = math.MaxUint32
 }
+
+// Tests that typed receipts are encoded as their type followed by the RLP of
+// the consensus fields, wrapped in an RLP string within lists, and decode back.
+func TestTypedReceiptEncoding(t *testing.T) {
+	receipt := &Receipt{
+		Type:              AccessListTxType,
+		Status:            ReceiptStatusSuccessful,
+		CumulativeGasUsed: 1,
+		Logs: []*Log{
+			{
+				Address: common.BytesToAddress([]byte{0x11}),
+				Topics:  []common.Hash{common.HexToHash("dead"), common.HexToHash("beef")},
+				Data:    []byte{0x01, 0x00, 0xff},
+			},
+		},
+	}
+	receipt.Bloom = CreateBloom(Receipts{receipt})
+
+	// The canonical encoding is the type byte and the legacy payload
+	legacy := *receipt
+	legacy.Type = LegacyTxType
+	payload, err := rlp.EncodeToBytes(&legacy)
+	if err != nil {
+		t.Fatalf("failed to encode legacy receipt: %v", err)
+	}
+	enc, err := receipt.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode typed receipt: %v", err)
+	}
+	if want := append([]byte{AccessListTxType}, payload...); !bytes.Equal(enc, want) {
+		t.Fatalf("canonical encoding mismatch: have %x, want %x", enc, want)
+	}
+	if have := (Receipts{receipt}).GetRlp(0); !bytes.Equal(have, enc) {
+		t.Fatalf("derivable encoding mismatch: have %x, want %x", have, enc)
+	}
+	var dec Receipt
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode canonical encoding: %v", err)
+	}
+	if dec.Type != AccessListTxType || dec.Status != receipt.Status || dec.CumulativeGasUsed != receipt.CumulativeGasUsed || dec.Bloom != receipt.Bloom {
+		t.Fatalf("canonical decoding mismatch: have %+v, want %+v", dec, receipt)
+	}
+	// Within lists, the typed receipt is an RLP string
+	list, err := rlp.EncodeToBytes(Receipts{receipt, &legacy})
+	if err != nil {
+		t.Fatalf("failed to encode receipt list: %v", err)
+	}
+	var decList []*Receipt
+	if err := rlp.DecodeBytes(list, &decList); err != nil {
+		t.Fatalf("failed to decode receipt list: %v", err)
+	}
+	if len(decList) != 2 || decList[0].Type != AccessListTxType || decList[1].Type != LegacyTxType {
+		t.Fatalf("receipt list types mismatch: %+v", decList)
+	}
+	if !reflect.DeepEqual(decList[0].Logs, receipt.Logs) {
+		t.Fatalf("receipt logs mismatch: have %+v, want %+v", decList[0].Logs, receipt.Logs)
+	}
+	// Receipts of sponsored transactions keep their type
+	for _, typ := range []byte{SponsoredTxType, AnonymousTxType} {
+		if err := dec.UnmarshalBinary(append([]byte{typ}, payload...)); err != nil || dec.Type != typ {
+			t.Fatalf("sponsored receipt type mismatch: have %d (%v), want %d", dec.Type, err, typ)
+		}
+	}
+	// Unknown receipt types are rejected
+	if err := dec.UnmarshalBinary(append([]byte{0x7f}, payload...)); err != ErrTxTypeNotSupported {
+		t.Fatalf("unknown type error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
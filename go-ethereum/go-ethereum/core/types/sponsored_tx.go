@@ -0,0 +1,121 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SponsoredTx is the data of sponsored transactions, whose gas is paid by a fee
+// payer instead of the sender.
+type SponsoredTx struct {
+	ChainID  *big.Int        // destination chain ID
+	Nonce    uint64          // nonce of sender account
+	GasPrice *big.Int        // wei per gas, set by the fee payer
+	Gas      uint64          // gas limit, set by the fee payer
+	To       *common.Address `rlp:"nil"` // nil means contract creation
+	Value    *big.Int        // wei amount
+	Data     []byte          // contract invocation input data
+	V, R, S  *big.Int        // sender signature values
+
+	PayerV, PayerR, PayerS *big.Int // fee payer signature values
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *SponsoredTx) copy() TxData {
+	cpy := &SponsoredTx{
+		Nonce: tx.Nonce,
+		To:    tx.To, // TODO: copy pointed-to address
+		Data:  common.CopyBytes(tx.Data),
+		Gas:   tx.Gas,
+		// These are copied below.
+		Value:    new(big.Int),
+		ChainID:  new(big.Int),
+		GasPrice: new(big.Int),
+		V:        new(big.Int),
+		R:        new(big.Int),
+		S:        new(big.Int),
+		PayerV:   new(big.Int),
+		PayerR:   new(big.Int),
+		PayerS:   new(big.Int),
+	}
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.ChainID != nil {
+		cpy.ChainID.Set(tx.ChainID)
+	}
+	if tx.GasPrice != nil {
+		cpy.GasPrice.Set(tx.GasPrice)
+	}
+	if tx.V != nil {
+		cpy.V.Set(tx.V)
+	}
+	if tx.R != nil {
+		cpy.R.Set(tx.R)
+	}
+	if tx.S != nil {
+		cpy.S.Set(tx.S)
+	}
+	if tx.PayerV != nil {
+		cpy.PayerV.Set(tx.PayerV)
+	}
+	if tx.PayerR != nil {
+		cpy.PayerR.Set(tx.PayerR)
+	}
+	if tx.PayerS != nil {
+		cpy.PayerS.Set(tx.PayerS)
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+
+func (tx *SponsoredTx) txType() byte           { return SponsoredTxType }
+func (tx *SponsoredTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *SponsoredTx) accessList() AccessList { return nil }
+func (tx *SponsoredTx) data() []byte           { return tx.Data }
+func (tx *SponsoredTx) gas() uint64            { return tx.Gas }
+func (tx *SponsoredTx) gasPrice() *big.Int     { return tx.GasPrice }
+func (tx *SponsoredTx) value() *big.Int        { return tx.Value }
+func (tx *SponsoredTx) nonce() uint64          { return tx.Nonce }
+func (tx *SponsoredTx) to() *common.Address    { return tx.To }
+
+func (tx *SponsoredTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *SponsoredTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.V, tx.R, tx.S = chainID, v, r, s
+}
+
+// accessors for sponsoredTxData.
+
+func (tx *SponsoredTx) rawFeePayerSignatureValues() (v, r, s *big.Int) {
+	return tx.PayerV, tx.PayerR, tx.PayerS
+}
+
+func (tx *SponsoredTx) setFeePayerSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.PayerV, tx.PayerR, tx.PayerS = chainID, v, r, s
+}
+
+func (tx *SponsoredTx) setFeeParams(gas uint64, gasPrice *big.Int) {
+	tx.Gas, tx.GasPrice = gas, gasPrice
+	tx.PayerV, tx.PayerR, tx.PayerS = new(big.Int), new(big.Int), new(big.Int)
+}
@@ -0,0 +1,279 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// txJSON is the JSON representation of transactions.
+type txJSON struct {
+	Type hexutil.Uint64 `json:"type"`
+
+	// Common transaction fields:
+	Nonce    *hexutil.Uint64 `json:"nonce"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     *hexutil.Bytes  `json:"input"`
+	V        *hexutil.Big    `json:"v"`
+	R        *hexutil.Big    `json:"r"`
+	S        *hexutil.Big    `json:"s"`
+	To       *common.Address `json:"to"`
+
+	// Access list transaction fields:
+	ChainID    *hexutil.Big `json:"chainId,omitempty"`
+	AccessList *AccessList  `json:"accessList,omitempty"`
+
+	// Sponsored transaction fields:
+	PayerV *hexutil.Big `json:"payerV,omitempty"`
+	PayerR *hexutil.Big `json:"payerR,omitempty"`
+	PayerS *hexutil.Big `json:"payerS,omitempty"`
+
+	// Anonymous transaction fields:
+	Group *GroupAuth `json:"group,omitempty"`
+
+	// Only used for encoding:
+	Hash common.Hash `json:"hash"`
+}
+
+// MarshalJSON marshals as JSON with a hash.
+func (t *Transaction) MarshalJSON() ([]byte, error) {
+	var enc txJSON
+	// These are set for all tx types.
+	enc.Hash = t.Hash()
+	enc.Type = hexutil.Uint64(t.Type())
+
+	// Other fields are set conditionally depending on tx type.
+	switch tx := t.inner.(type) {
+	case *LegacyTx:
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = t.To()
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
+	case *AccessListTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainID)
+		enc.AccessList = &tx.AccessList
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = t.To()
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
+	case *SponsoredTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainID)
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = t.To()
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
+		enc.PayerV = (*hexutil.Big)(tx.PayerV)
+		enc.PayerR = (*hexutil.Big)(tx.PayerR)
+		enc.PayerS = (*hexutil.Big)(tx.PayerS)
+	case *AnonymousTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainID)
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = t.To()
+		v, r, s := tx.rawSignatureValues()
+		enc.V = (*hexutil.Big)(v)
+		enc.R = (*hexutil.Big)(r)
+		enc.S = (*hexutil.Big)(s)
+		enc.Group = &tx.Group
+		enc.PayerV = (*hexutil.Big)(tx.PayerV)
+		enc.PayerR = (*hexutil.Big)(tx.PayerR)
+		enc.PayerS = (*hexutil.Big)(tx.PayerS)
+	}
+	return json.Marshal(&enc)
+}
+
+// UnmarshalJSON unmarshals from JSON.
+func (t *Transaction) UnmarshalJSON(input []byte) error {
+	var dec txJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	// Decode / verify fields according to transaction type.
+	var inner TxData
+	switch dec.Type {
+	case LegacyTxType:
+		var itx LegacyTx
+		inner = &itx
+		if err := dec.decodeCommon(&itx.Nonce, &itx.GasPrice, &itx.Gas, &itx.Value, &itx.Data, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		if withSignature(itx.V, itx.R, itx.S) {
+			var V byte
+			if isProtectedV(itx.V) {
+				chainID := deriveChainId(itx.V).Uint64()
+				V = byte(itx.V.Uint64() - 35 - 2*chainID)
+			} else {
+				V = byte(itx.V.Uint64() - 27)
+			}
+			if !crypto.ValidateSignatureValues(V, itx.R, itx.S, false) {
+				return ErrInvalidSig
+			}
+		}
+
+	case AccessListTxType:
+		var itx AccessListTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		if dec.AccessList != nil {
+			itx.AccessList = *dec.AccessList
+		}
+		if err := dec.decodeCommon(&itx.Nonce, &itx.GasPrice, &itx.Gas, &itx.Value, &itx.Data, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		if withSignature(itx.V, itx.R, itx.S) {
+			if !itx.V.IsUint64() || itx.V.Uint64() > 1 || !crypto.ValidateSignatureValues(byte(itx.V.Uint64()), itx.R, itx.S, false) {
+				return ErrInvalidSig
+			}
+		}
+
+	case SponsoredTxType:
+		var itx SponsoredTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		if err := dec.decodeCommon(&itx.Nonce, &itx.GasPrice, &itx.Gas, &itx.Value, &itx.Data, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		if withSignature(itx.V, itx.R, itx.S) {
+			if !itx.V.IsUint64() || itx.V.Uint64() > 1 || !crypto.ValidateSignatureValues(byte(itx.V.Uint64()), itx.R, itx.S, false) {
+				return ErrInvalidSig
+			}
+		}
+		if err := dec.decodeFeePayer(&itx.PayerV, &itx.PayerR, &itx.PayerS); err != nil {
+			return err
+		}
+
+	case AnonymousTxType:
+		var itx AnonymousTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		var v, r, s *big.Int
+		if err := dec.decodeCommon(&itx.Nonce, &itx.GasPrice, &itx.Gas, &itx.Value, &itx.Data, &v, &r, &s); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		if dec.Group == nil || withSignature(v, r, s) {
+			return ErrInvalidGroupAuth
+		}
+		itx.Group = *dec.Group
+		if err := dec.decodeFeePayer(&itx.PayerV, &itx.PayerR, &itx.PayerS); err != nil {
+			return err
+		}
+
+	default:
+		return ErrTxTypeNotSupported
+	}
+
+	// Now set the inner transaction.
+	t.setDecoded(inner, 0)
+
+	// TODO: check hash here?
+	return nil
+}
+
+// decodeCommon decodes the fields shared by all transaction types.
+func (dec *txJSON) decodeCommon(nonce *uint64, gasPrice **big.Int, gas *uint64, value **big.Int, data *[]byte, v, r, s **big.Int) error {
+	if dec.Nonce == nil {
+		return errors.New("missing required field 'nonce' in transaction")
+	}
+	*nonce = uint64(*dec.Nonce)
+	if dec.GasPrice == nil {
+		return errors.New("missing required field 'gasPrice' in transaction")
+	}
+	*gasPrice = (*big.Int)(dec.GasPrice)
+	if dec.Gas == nil {
+		return errors.New("missing required field 'gas' in transaction")
+	}
+	*gas = uint64(*dec.Gas)
+	if dec.Value == nil {
+		return errors.New("missing required field 'value' in transaction")
+	}
+	*value = (*big.Int)(dec.Value)
+	if dec.Data == nil {
+		return errors.New("missing required field 'input' in transaction")
+	}
+	*data = *dec.Data
+	if dec.V == nil {
+		return errors.New("missing required field 'v' in transaction")
+	}
+	*v = (*big.Int)(dec.V)
+	if dec.R == nil {
+		return errors.New("missing required field 'r' in transaction")
+	}
+	*r = (*big.Int)(dec.R)
+	if dec.S == nil {
+		return errors.New("missing required field 's' in transaction")
+	}
+	*s = (*big.Int)(dec.S)
+	return nil
+}
+
+// decodeFeePayer decodes the fee payer signature of sponsored transactions.
+func (dec *txJSON) decodeFeePayer(v, r, s **big.Int) error {
+	if dec.PayerV == nil || dec.PayerR == nil || dec.PayerS == nil {
+		return ErrInvalidFeePayer
+	}
+	*v, *r, *s = (*big.Int)(dec.PayerV), (*big.Int)(dec.PayerR), (*big.Int)(dec.PayerS)
+	if withSignature(*v, *r, *s) {
+		if !(*v).IsUint64() || (*v).Uint64() > 1 || !crypto.ValidateSignatureValues(byte((*v).Uint64()), *r, *s, false) {
+			return ErrInvalidFeePayer
+		}
+	}
+	return nil
+}
+
+// withSignature reports whether any of the signature values is set.
+func withSignature(v, r, s *big.Int) bool {
+	return v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0
+}
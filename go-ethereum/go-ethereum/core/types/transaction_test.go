@@ -20,7 +20,9 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"encoding/json"
+	"fmt"
 	"math/big"
+	"reflect"
 	"testing"
 	"time"
 
@@ -50,6 +52,23 @@ var (
 		HomesteadSigner{},
 		common.Hex2Bytes("98ff921201554726367d2be8c804a7ff89ccf285ebc57dff8ae4c44b9c19ac4a8887321be575c8095f789dd4c743dfe42c1820f9231f98a962b210e3ac2452a301"),
 	)
+
+	testAddr = common.HexToAddress("b94f5374fce5edbc8e2a8697c15331677e6ebf0b")
+
+	emptyEip2718Tx = NewTx(&AccessListTx{
+		ChainID:  big.NewInt(1),
+		Nonce:    3,
+		To:       &testAddr,
+		Value:    big.NewInt(10),
+		Gas:      25000,
+		GasPrice: big.NewInt(1),
+		Data:     common.FromHex("5544"),
+	})
+
+	signedEip2718Tx, _ = emptyEip2718Tx.WithSignature(
+		NewEIP2930Signer(big.NewInt(1)),
+		common.Hex2Bytes("c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b266032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d3752101"),
+	)
 )
 
 func TestTransactionSigHash(t *testing.T) {
@@ -73,6 +92,258 @@ func TestTransactionEncode(t *testing.T) {
 	}
 }
 
+func TestEIP2718TransactionSigHash(t *testing.T) {
+	s := NewEIP2930Signer(big.NewInt(1))
+	if s.Hash(emptyEip2718Tx) != common.HexToHash("49b486f0ec0a60dfbbca2d30cb07c9e8ffb2a2ff41f29a1ab6737475f6ff69f3") {
+		t.Errorf("empty EIP-2718 transaction hash mismatch, got %x", s.Hash(emptyEip2718Tx))
+	}
+	if s.Hash(signedEip2718Tx) != common.HexToHash("49b486f0ec0a60dfbbca2d30cb07c9e8ffb2a2ff41f29a1ab6737475f6ff69f3") {
+		t.Errorf("signed EIP-2718 transaction hash mismatch, got %x", s.Hash(signedEip2718Tx))
+	}
+}
+
+func TestEIP2718TransactionEncode(t *testing.T) {
+	// RLP representation
+	{
+		have, err := rlp.EncodeToBytes(signedEip2718Tx)
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+		want := common.FromHex("b86601f8630103018261a894b94f5374fce5edbc8e2a8697c15331677e6ebf0b0a825544c001a0c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b2660a032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d37521")
+		if !bytes.Equal(have, want) {
+			t.Errorf("encoded RLP mismatch, got %x", have)
+		}
+	}
+	// Binary representation
+	{
+		have, err := signedEip2718Tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+		want := common.FromHex("01f8630103018261a894b94f5374fce5edbc8e2a8697c15331677e6ebf0b0a825544c001a0c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b2660a032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d37521")
+		if !bytes.Equal(have, want) {
+			t.Errorf("encoded binary mismatch, got %x", have)
+		}
+	}
+}
+
+// Tests that the signers accept exactly the transaction types they support, and
+// that access list transactions are bound to the signer's chain.
+func TestEIP2930Signer(t *testing.T) {
+	var (
+		key, _  = defaultTestKey()
+		keyAddr = crypto.PubkeyToAddress(key.PublicKey)
+		signer1 = NewEIP2930Signer(big.NewInt(1))
+		signer2 = NewEIP2930Signer(big.NewInt(2))
+		tx0     = NewTx(&AccessListTx{Nonce: 1})
+		tx1     = NewTx(&AccessListTx{ChainID: big.NewInt(1), Nonce: 1})
+	)
+	tests := []struct {
+		tx             *Transaction
+		signer         Signer
+		wantSignerHash common.Hash
+		wantSenderErr  error
+		wantSignErr    error
+	}{
+		{
+			// This checks that the chain ID is filled in when signing
+			tx:             tx0,
+			signer:         signer1,
+			wantSignerHash: common.HexToHash("846ad7672f2a3a40c1f959cd4a8ad21786d620077084d84c8d7c077714caa139"),
+			wantSenderErr:  ErrInvalidChainId,
+		},
+		{
+			tx:             tx1,
+			signer:         signer1,
+			wantSenderErr:  ErrInvalidSig,
+			wantSignerHash: common.HexToHash("846ad7672f2a3a40c1f959cd4a8ad21786d620077084d84c8d7c077714caa139"),
+		},
+		{
+			// This checks what happens when trying to sign an unsigned tx for the wrong chain.
+			tx:             tx1,
+			signer:         signer2,
+			wantSenderErr:  ErrInvalidChainId,
+			wantSignerHash: common.HexToHash("367967247499343401261d718ed5aa4c9486583e4d89251afce47f4a33c33362"),
+			wantSignErr:    ErrInvalidChainId,
+		},
+		{
+			// Older signers don't support typed transactions at all
+			tx:             tx1,
+			signer:         NewEIP155Signer(big.NewInt(1)),
+			wantSenderErr:  ErrTxTypeNotSupported,
+			wantSignerHash: common.HexToHash("846ad7672f2a3a40c1f959cd4a8ad21786d620077084d84c8d7c077714caa139"),
+			wantSignErr:    ErrTxTypeNotSupported,
+		},
+	}
+	for i, test := range tests {
+		// The EIP-155 signer hashes the legacy fields only, skip its hash check
+		if _, legacy := test.signer.(EIP155Signer); !legacy {
+			if sigHash := test.signer.Hash(test.tx); sigHash != test.wantSignerHash {
+				t.Errorf("test %d: wrong sig hash: got %x, want %x", i, sigHash, test.wantSignerHash)
+			}
+		}
+		sender, err := Sender(test.signer, test.tx)
+		if err != test.wantSenderErr {
+			t.Errorf("test %d: wrong Sender error %q", i, err)
+		}
+		if err == nil && sender != keyAddr {
+			t.Errorf("test %d: wrong sender address %x", i, sender)
+		}
+		signedTx, err := SignTx(test.tx, test.signer, key)
+		if err != test.wantSignErr {
+			t.Fatalf("test %d: wrong SignTx error %q", i, err)
+		}
+		if signedTx != nil {
+			if signedTx.Hash() == test.tx.Hash() {
+				t.Errorf("test %d: signed transaction has the same hash as the unsigned one", i)
+			}
+			if sender, err := Sender(test.signer, signedTx); err != nil || sender != keyAddr {
+				t.Errorf("test %d: wrong signed sender %x: %v", i, sender, err)
+			}
+		}
+	}
+}
+
+// Tests that all transaction types survive their canonical, RLP and JSON encodings.
+func TestTransactionCoding(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	var (
+		signer    = NewEIP2930Signer(common.Big1)
+		addr      = common.HexToAddress("0x0000000000000000000000000000000000000001")
+		recipient = common.HexToAddress("095e7baea6a6c7c4c2dfeb977efac326af552d87")
+		accesses  = AccessList{{Address: addr, StorageKeys: []common.Hash{{0}}}}
+	)
+	for i := uint64(0); i < 500; i++ {
+		var txdata TxData
+		switch i % 5 {
+		case 0:
+			// Legacy tx.
+			txdata = &LegacyTx{
+				Nonce:    i,
+				To:       &recipient,
+				Gas:      1,
+				GasPrice: big.NewInt(2),
+				Data:     []byte("abcdef"),
+			}
+		case 1:
+			// Legacy tx contract creation.
+			txdata = &LegacyTx{
+				Nonce:    i,
+				Gas:      1,
+				GasPrice: big.NewInt(2),
+				Data:     []byte("abcdef"),
+			}
+		case 2:
+			// Tx with non-zero access list.
+			txdata = &AccessListTx{
+				ChainID:    big.NewInt(1),
+				Nonce:      i,
+				To:         &recipient,
+				Gas:        123457,
+				GasPrice:   big.NewInt(10),
+				AccessList: accesses,
+				Data:       []byte("abcdef"),
+			}
+		case 3:
+			// Tx with empty access list.
+			txdata = &AccessListTx{
+				ChainID:  big.NewInt(1),
+				Nonce:    i,
+				To:       &recipient,
+				Gas:      123457,
+				GasPrice: big.NewInt(10),
+				Data:     []byte("abcdef"),
+			}
+		case 4:
+			// Contract creation with access list.
+			txdata = &AccessListTx{
+				ChainID:    big.NewInt(1),
+				Nonce:      i,
+				Gas:        123457,
+				GasPrice:   big.NewInt(10),
+				AccessList: accesses,
+			}
+		}
+		tx, err := SignNewTx(key, signer, txdata)
+		if err != nil {
+			t.Fatalf("could not sign transaction: %v", err)
+		}
+		// RLP
+		parsedTx, err := encodeDecodeBinary(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, parsedTx, tx)
+
+		// JSON
+		parsedTx, err = encodeDecodeJSON(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, parsedTx, tx)
+	}
+	// Within lists, legacy transactions are RLP lists and typed ones strings
+	txs := Transactions{rightvrsTx, signedEip2718Tx}
+	enc, err := rlp.EncodeToBytes(txs)
+	if err != nil {
+		t.Fatalf("failed to encode transaction list: %v", err)
+	}
+	var dec Transactions
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatalf("failed to decode transaction list: %v", err)
+	}
+	if len(dec) != 2 {
+		t.Fatalf("transaction count mismatch: have %d, want 2", len(dec))
+	}
+	for i := range txs {
+		assertEqual(t, dec[i], txs[i])
+	}
+}
+
+func encodeDecodeJSON(tx *Transaction) (*Transaction, error) {
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return nil, fmt.Errorf("json encoding failed: %v", err)
+	}
+	var parsedTx = &Transaction{}
+	if err := json.Unmarshal(data, &parsedTx); err != nil {
+		return nil, fmt.Errorf("json decoding failed: %v", err)
+	}
+	return parsedTx, nil
+}
+
+func encodeDecodeBinary(tx *Transaction) (*Transaction, error) {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("rlp encoding failed: %v", err)
+	}
+	var parsedTx = &Transaction{}
+	if err := parsedTx.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("rlp decoding failed: %v", err)
+	}
+	return parsedTx, nil
+}
+
+func assertEqual(t *testing.T, have, want *Transaction) {
+	t.Helper()
+
+	if have.Hash() != want.Hash() {
+		t.Fatalf("parsed tx differs from original tx, want %v, got %v", want, have)
+	}
+	if have.Type() != want.Type() {
+		t.Fatalf("tx type mismatch: have %d, want %d", have.Type(), want.Type())
+	}
+	if have.ChainId().Cmp(want.ChainId()) != 0 {
+		t.Fatalf("invalid chain id, want %d, got %d", want.ChainId(), have.ChainId())
+	}
+	if !reflect.DeepEqual(have.AccessList(), want.AccessList()) {
+		t.Fatalf("access list wrong!")
+	}
+}
+
 func decodeTx(data []byte) (*Transaction, error) {
 	var tx Transaction
 	t, err := &tx, rlp.Decode(bytes.NewReader(data), &tx)
@@ -219,6 +490,63 @@ func TestTransactionTimeSort(t *testing.T) {
 	}
 }
 
+// Tests that transactions can be ordered first-in-first-out or by hash across
+// accounts, regardless of their prices, while still honouring account nonces.
+func TestTransactionOrderNonceSort(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := HomesteadSigner{}
+
+	for _, ordering := range []TxOrdering{TxByTime, TxByHash} {
+		// Generate a batch of transactions with increasing prices and decreasing
+		// creation times, to make sure neither of them is used for ordering
+		groups := map[common.Address]Transactions{}
+		for i, key := range keys {
+			addr := crypto.PubkeyToAddress(key.PublicKey)
+			for nonce := uint64(0); nonce < 3; nonce++ {
+				tx, _ := SignTx(NewTransaction(nonce, common.Address{}, big.NewInt(100), 100, big.NewInt(int64(i)), nil), signer, key)
+				tx.time = time.Unix(int64(len(keys)*3-i*3+int(nonce)), 0)
+
+				groups[addr] = append(groups[addr], tx)
+			}
+		}
+		txset := NewTransactionsByOrderAndNonce(signer, groups, ordering)
+
+		txs := Transactions{}
+		for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+			txs = append(txs, tx)
+			txset.Shift()
+		}
+		if len(txs) != 3*len(keys) {
+			t.Fatalf("expected %d transactions, found %d", 3*len(keys), len(txs))
+		}
+		nonces := make(map[common.Address]uint64)
+		for i, txi := range txs {
+			from, _ := Sender(signer, txi)
+			if txi.Nonce() != nonces[from] {
+				t.Errorf("invalid nonce ordering: tx #%d (A=%x N=%v) expected nonce %d", i, from[:4], txi.Nonce(), nonces[from])
+			}
+			nonces[from]++
+		}
+		// Every account's first transaction must come out in policy order
+		var heads Transactions
+		seen := make(map[common.Address]bool)
+		for _, tx := range txs {
+			if from, _ := Sender(signer, tx); !seen[from] {
+				seen[from] = true
+				heads = append(heads, tx)
+			}
+		}
+		for i := 1; i < len(heads); i++ {
+			if ordering(heads[i], heads[i-1]) {
+				t.Errorf("invalid head ordering: tx #%d sorts before tx #%d", i, i-1)
+			}
+		}
+	}
+}
+
 // TestTransactionJSON tests serializing/de-serializing to/from JSON.
 func TestTransactionJSON(t *testing.T) {
 	key, err := crypto.GenerateKey()
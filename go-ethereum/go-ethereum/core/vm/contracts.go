@@ -120,11 +120,18 @@ var PrecompiledContractsYoloV2 = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{20}): &verhfProof{},
 }
 
+// PrecompiledContractsReceiptProof contains the pre-compiled contracts added to
+// the ones of the release in use from the receipt proof fork.
+var PrecompiledContractsReceiptProof = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{21}): &receiptProof{},
+}
+
 var (
-	PrecompiledAddressesYoloV2    []common.Address
-	PrecompiledAddressesIstanbul  []common.Address
-	PrecompiledAddressesByzantium []common.Address
-	PrecompiledAddressesHomestead []common.Address
+	PrecompiledAddressesReceiptProof []common.Address
+	PrecompiledAddressesYoloV2       []common.Address
+	PrecompiledAddressesIstanbul     []common.Address
+	PrecompiledAddressesByzantium    []common.Address
+	PrecompiledAddressesHomestead    []common.Address
 )
 
 func init() {
@@ -140,6 +147,9 @@ func init() {
 	for k := range PrecompiledContractsYoloV2 {
 		PrecompiledAddressesYoloV2 = append(PrecompiledAddressesYoloV2, k)
 	}
+	for k := range PrecompiledContractsReceiptProof {
+		PrecompiledAddressesReceiptProof = append(PrecompiledAddressesReceiptProof, k)
+	}
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
@@ -1835,7 +1845,7 @@ func (c *veriGroupsign) Run(input []byte) ([]byte, error) {
 	ssig.pok.s3.SetBytes(s3_get)
 
 	time_start := time.Now()
-	grpID := "computer" //默认就是这个
+	grpID := PrecompiledGroupID //默认就是这个
 	msg := G(mess_get)
 	IG := G(grpID)
 
@@ -45,16 +45,21 @@ type (
 // ActivePrecompiles returns the addresses of the precompiles enabled with the current
 // configuration
 func (evm *EVM) ActivePrecompiles() []common.Address {
+	var precompiles []common.Address
 	switch {
 	case evm.chainRules.IsYoloV2:
-		return PrecompiledAddressesYoloV2
+		precompiles = PrecompiledAddressesYoloV2
 	case evm.chainRules.IsIstanbul:
-		return PrecompiledAddressesIstanbul
+		precompiles = PrecompiledAddressesIstanbul
 	case evm.chainRules.IsByzantium:
-		return PrecompiledAddressesByzantium
+		precompiles = PrecompiledAddressesByzantium
 	default:
-		return PrecompiledAddressesHomestead
+		precompiles = PrecompiledAddressesHomestead
 	}
+	if evm.chainRules.IsReceiptProof {
+		precompiles = append(append([]common.Address{}, precompiles...), PrecompiledAddressesReceiptProof...)
+	}
+	return precompiles
 }
 
 func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
@@ -70,6 +75,9 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 		precompiles = PrecompiledContractsHomestead
 	}
 	p, ok := precompiles[addr]
+	if !ok && evm.chainRules.IsReceiptProof {
+		p, ok = PrecompiledContractsReceiptProof[addr]
+	}
 	return p, ok
 }
 
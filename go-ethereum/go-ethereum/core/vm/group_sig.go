@@ -0,0 +1,396 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/Nik-U/pbc"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// errGroupKeyEncoding is returned if an encoded TIBGS master public key is
+	// malformed.
+	errGroupKeyEncoding = errors.New("invalid group key encoding")
+
+	// errGroupSigEncoding is returned if an encoded TIBGS group signature is
+	// malformed.
+	errGroupSigEncoding = errors.New("invalid group signature encoding")
+
+	// errGroupSigShares is returned if a group signature is to be opened with
+	// less shares than the threshold.
+	errGroupSigShares = errors.New("not enough opening shares")
+
+	// errGroupSigSigner is returned if an opened group signature doesn't match
+	// any of the candidate members.
+	errGroupSigSigner = errors.New("group signature signer not found")
+
+	// errGroupManagerKeyEncoding is returned if an encoded TIBGS group secret key
+	// of a group manager is malformed.
+	errGroupManagerKeyEncoding = errors.New("invalid group manager key encoding")
+
+	// errGroupVerifyKeyEncoding is returned if an encoded TIBGS group verify key
+	// of a group manager is malformed.
+	errGroupVerifyKeyEncoding = errors.New("invalid group verify key encoding")
+
+	// errGroupKeyShareEncoding is returned if an encoded share of the secret key
+	// of a member is malformed.
+	errGroupKeyShareEncoding = errors.New("invalid group key share encoding")
+)
+
+// PrecompiledGroupID is the identity of the group the group signature precompile
+// verifies signatures under.
+const PrecompiledGroupID = "computer"
+
+// groupElementSize and groupScalarSize are the sizes of the G1, G2 and GT and of
+// the Zr elements under the pairing parameters in use.
+const (
+	groupElementSize = 128
+	groupScalarSize  = 20
+)
+
+// groupSigSizes are the sizes of the elements of a group signature, in the order
+// of its encoding.
+var groupSigSizes = []int{
+	groupElementSize, groupElementSize, groupElementSize, groupElementSize, groupElementSize, groupElementSize,
+	groupScalarSize, groupScalarSize, groupScalarSize, groupScalarSize,
+}
+
+// groupKeySize and groupSigSize are the sizes of a packed master public key and
+// of a packed group signature.
+const (
+	groupKeySize = 9 * groupElementSize
+	groupSigSize = 6*groupElementSize + 4*groupScalarSize
+)
+
+// The TIBGS keys and signatures are encoded as RLP lists of their elements, in
+// the order of the struct fields.
+
+// EncodeGroupKey encodes a TIBGS master public key.
+func EncodeGroupKey(mpk *TIBGSMasterPublicKey) []byte {
+	return encodeElements(mpk.g, mpk.g2, mpk.h1, mpk.u0, mpk.u1, mpk.u2, mpk.u3, mpk.u4, mpk.n)
+}
+
+// DecodeGroupKey decodes a TIBGS master public key.
+func DecodeGroupKey(enc []byte) (*TIBGSMasterPublicKey, error) {
+	mpk := &TIBGSMasterPublicKey{
+		g:  pairing.NewG1(),
+		g2: pairing.NewG2(),
+		h1: pairing.NewG1(),
+		u0: pairing.NewG2(),
+		u1: pairing.NewG2(),
+		u2: pairing.NewG2(),
+		u3: pairing.NewG2(),
+		u4: pairing.NewG2(),
+		n:  pairing.NewGT(),
+	}
+	if err := decodeElements(enc, mpk.g, mpk.g2, mpk.h1, mpk.u0, mpk.u1, mpk.u2, mpk.u3, mpk.u4, mpk.n); err != nil {
+		return nil, errGroupKeyEncoding
+	}
+	return mpk, nil
+}
+
+// EncodeGroupSignature encodes a TIBGS group signature.
+func EncodeGroupSignature(sig *TIBGSSIG) []byte {
+	return encodeElements(sig.c0, sig.c5, sig.c6, sig.e1, sig.e2, sig.e3, sig.pok.c, sig.pok.s1, sig.pok.s2, sig.pok.s3)
+}
+
+// DecodeGroupSignature decodes a TIBGS group signature.
+func DecodeGroupSignature(enc []byte) (*TIBGSSIG, error) {
+	sig := &TIBGSSIG{
+		c0: pairing.NewG2(),
+		c5: pairing.NewG1(),
+		c6: pairing.NewG2(),
+		e1: pairing.NewG1(),
+		e2: pairing.NewG2(),
+		e3: pairing.NewGT(),
+		pok: TIBGSPOK{
+			c:  pairing.NewZr(),
+			s1: pairing.NewZr(),
+			s2: pairing.NewZr(),
+			s3: pairing.NewZr(),
+		},
+	}
+	if err := decodeElements(enc, sig.c0, sig.c5, sig.c6, sig.e1, sig.e2, sig.e3, sig.pok.c, sig.pok.s1, sig.pok.s2, sig.pok.s3); err != nil {
+		return nil, errGroupSigEncoding
+	}
+	return sig, nil
+}
+
+// VerifyGroupSignature checks whether the encoded group signature was issued
+// over the message by a member of the given group, under the encoded master
+// public key.
+func VerifyGroupSignature(key, sig []byte, message string, grpID string) (bool, error) {
+	mpk, err := DecodeGroupKey(key)
+	if err != nil {
+		return false, err
+	}
+	ssig, err := DecodeGroupSignature(sig)
+	if err != nil {
+		return false, err
+	}
+	return Verify(ssig, mpk, message, grpID), nil
+}
+
+// OpenGroupSignaturePart computes a group manager's share of the opening of an
+// encoded group signature.
+func OpenGroupSignaturePart(gski *TIBGSGroupSecretKeyi, sig []byte) (*TIBGSOK, error) {
+	ssig, err := DecodeGroupSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	return OpenPart(gski, ssig), nil
+}
+
+// OpenGroupSignature combines the opening shares of a threshold of managers to
+// trace which of the candidate members issued an encoded group signature. The
+// shares are expected from the first threshold managers, in index order.
+func OpenGroupSignature(parts []*TIBGSOK, threshold int, members []string, key, sig []byte) (string, error) {
+	mpk, err := DecodeGroupKey(key)
+	if err != nil {
+		return "", err
+	}
+	ssig, err := DecodeGroupSignature(sig)
+	if err != nil {
+		return "", err
+	}
+	if len(parts) < threshold {
+		return "", errGroupSigShares
+	}
+	gama := Open(parts, threshold)
+	for _, member := range members {
+		if FindUser([]string{member}, gama, ssig, mpk) == member {
+			return member, nil
+		}
+	}
+	return "", errGroupSigSigner
+}
+
+// EncodeGroupManagerKey encodes the TIBGS group secret key of a group manager.
+func EncodeGroupManagerKey(gski *TIBGSGroupSecretKeyi) []byte {
+	return encodeElements(gski.a0i, gski.a2i, gski.a3i, gski.a4i, gski.a5i)
+}
+
+// DecodeGroupManagerKey decodes the TIBGS group secret key of a group manager.
+func DecodeGroupManagerKey(enc []byte) (*TIBGSGroupSecretKeyi, error) {
+	gski := &TIBGSGroupSecretKeyi{
+		a0i: pairing.NewG2(),
+		a2i: pairing.NewG2(),
+		a3i: pairing.NewG2(),
+		a4i: pairing.NewG2(),
+		a5i: pairing.NewG1(),
+	}
+	if err := decodeElements(enc, gski.a0i, gski.a2i, gski.a3i, gski.a4i, gski.a5i); err != nil {
+		return nil, errGroupManagerKeyEncoding
+	}
+	return gski, nil
+}
+
+// EncodeGroupVerifyKey encodes the TIBGS group verify key of a group manager.
+func EncodeGroupVerifyKey(gvki *TIBGSGroupVerifyKeyi) []byte {
+	return encodeElements(gvki.gai)
+}
+
+// DecodeGroupVerifyKey decodes the TIBGS group verify key of a group manager.
+func DecodeGroupVerifyKey(enc []byte) (*TIBGSGroupVerifyKeyi, error) {
+	gvki := &TIBGSGroupVerifyKeyi{gai: pairing.NewG1()}
+	if err := decodeElements(enc, gvki.gai); err != nil {
+		return nil, errGroupVerifyKeyEncoding
+	}
+	return gvki, nil
+}
+
+// EncodeGroupKeyShare encodes a group manager's share of the secret key of a
+// member.
+func EncodeGroupKeyShare(uski *TIBGSUserSecretKey) []byte {
+	return encodeElements(uski.b0, uski.b3, uski.b4, uski.b5)
+}
+
+// DecodeGroupKeyShare decodes a group manager's share of the secret key of a
+// member.
+func DecodeGroupKeyShare(enc []byte) (*TIBGSUserSecretKey, error) {
+	uski := &TIBGSUserSecretKey{
+		b0: pairing.NewG2(),
+		b3: pairing.NewG2(),
+		b4: pairing.NewG2(),
+		b5: pairing.NewG1(),
+	}
+	if err := decodeElements(enc, uski.b0, uski.b3, uski.b4, uski.b5); err != nil {
+		return nil, errGroupKeyShareEncoding
+	}
+	return uski, nil
+}
+
+// ExtractGroupKeyShare computes a group manager's share of the secret key of a
+// member from its encoded group secret key.
+func ExtractGroupKeyShare(managerKey []byte, userID string) ([]byte, error) {
+	gski, err := DecodeGroupManagerKey(managerKey)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeGroupKeyShare(ExtShare(gski, userID)), nil
+}
+
+// VerifyGroupKeyShare checks an encoded share of the secret key of a member
+// against the encoded verify key of the group manager that issued it.
+func VerifyGroupKeyShare(key, verifyKey, share []byte, grpID, userID string) (bool, error) {
+	mpk, err := DecodeGroupKey(key)
+	if err != nil {
+		return false, err
+	}
+	gvki, err := DecodeGroupVerifyKey(verifyKey)
+	if err != nil {
+		return false, err
+	}
+	uski, err := DecodeGroupKeyShare(share)
+	if err != nil {
+		return false, err
+	}
+	return VerifyShare(uski, gvki, mpk, grpID, userID), nil
+}
+
+// SignGroupMessage combines the shares of a threshold of managers into the
+// secret key of a member and issues an encoded group signature of the message
+// with it. The shares are expected from the first threshold managers, in index
+// order.
+func SignGroupMessage(key []byte, shares [][]byte, threshold int, message, grpID, userID string) ([]byte, error) {
+	mpk, err := DecodeGroupKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if threshold <= 0 || len(shares) < threshold {
+		return nil, errGroupSigShares
+	}
+	uskis := make([]*TIBGSUserSecretKey, threshold)
+	for i := range uskis {
+		if uskis[i], err = DecodeGroupKeyShare(shares[i]); err != nil {
+			return nil, err
+		}
+	}
+	usk := ReconstKey(uskis, threshold, mpk, grpID, userID)
+	return EncodeGroupSignature(Sign(mpk, usk, message, grpID, userID)), nil
+}
+
+// The group signature precompile takes the '#' separated hex encodings of the
+// elements of the master public key, in the order g, g2, u0, u1, u2, u3, u4, n,
+// h1, of the elements of the signature, in the order of its encoding, and of
+// the message. Contracts passing keys and signatures to it take them packed as
+// the raw concatenation of their elements in that order.
+
+// PackGroupKey converts an encoded TIBGS master public key to its packed form.
+func PackGroupKey(key []byte) ([]byte, error) {
+	var raw [][]byte
+	if err := rlp.DecodeBytes(key, &raw); err != nil || len(raw) != 9 {
+		return nil, errGroupKeyEncoding
+	}
+	packed := make([]byte, 0, len(raw)*groupElementSize)
+	for _, i := range []int{0, 1, 3, 4, 5, 6, 7, 8, 2} {
+		if len(raw[i]) != groupElementSize {
+			return nil, errGroupKeyEncoding
+		}
+		packed = append(packed, raw[i]...)
+	}
+	return packed, nil
+}
+
+// PackGroupSignature converts an encoded TIBGS group signature to its packed
+// form.
+func PackGroupSignature(sig []byte) ([]byte, error) {
+	var raw [][]byte
+	if err := rlp.DecodeBytes(sig, &raw); err != nil || len(raw) != len(groupSigSizes) {
+		return nil, errGroupSigEncoding
+	}
+	var packed []byte
+	for i, size := range groupSigSizes {
+		if len(raw[i]) != size {
+			return nil, errGroupSigEncoding
+		}
+		packed = append(packed, raw[i]...)
+	}
+	return packed, nil
+}
+
+// UnpackGroupSignature converts a packed TIBGS group signature back to its
+// encoding.
+func UnpackGroupSignature(packed []byte) ([]byte, error) {
+	raw := make([][]byte, len(groupSigSizes))
+	for i, size := range groupSigSizes {
+		if len(packed) < size {
+			return nil, errGroupSigEncoding
+		}
+		raw[i], packed = packed[:size], packed[size:]
+	}
+	if len(packed) > 0 {
+		return nil, errGroupSigEncoding
+	}
+	enc, _ := rlp.EncodeToBytes(raw)
+	return enc, nil
+}
+
+// GroupSignatureInput returns the input of the group signature precompile for
+// verifying a signature of a message under a master public key, both packed by
+// PackGroupSignature and PackGroupKey.
+func GroupSignatureInput(key, sig, message []byte) ([]byte, error) {
+	if len(key) != groupKeySize {
+		return nil, errGroupKeyEncoding
+	}
+	if len(sig) != groupSigSize {
+		return nil, errGroupSigEncoding
+	}
+	var fields []string
+	for ; len(key) > 0; key = key[groupElementSize:] {
+		fields = append(fields, hex.EncodeToString(key[:groupElementSize]))
+	}
+	for _, size := range groupSigSizes {
+		fields = append(fields, hex.EncodeToString(sig[:size]))
+		sig = sig[size:]
+	}
+	fields = append(fields, hex.EncodeToString(message))
+	return []byte(strings.Join(fields, "#")), nil
+}
+
+// encodeElements encodes a list of pairing elements.
+func encodeElements(elems ...*pbc.Element) []byte {
+	raw := make([][]byte, len(elems))
+	for i, el := range elems {
+		raw[i] = el.Bytes()
+	}
+	enc, _ := rlp.EncodeToBytes(raw)
+	return enc
+}
+
+// decodeElements decodes a list of pairing elements into the given, initialized
+// ones, checking the encoded lengths.
+func decodeElements(enc []byte, elems ...*pbc.Element) error {
+	var raw [][]byte
+	if err := rlp.DecodeBytes(enc, &raw); err != nil {
+		return err
+	}
+	if len(raw) != len(elems) {
+		return errors.New("element count mismatch")
+	}
+	for i, el := range elems {
+		if len(raw[i]) != el.BytesLen() {
+			return errors.New("element length mismatch")
+		}
+		el.SetBytes(raw[i])
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// groupElements returns the RLP encoding of elements of the given sizes, each
+// filled with its index.
+func groupElements(sizes ...int) []byte {
+	raw := make([][]byte, len(sizes))
+	for i, size := range sizes {
+		raw[i] = bytes.Repeat([]byte{byte(i)}, size)
+	}
+	enc, _ := rlp.EncodeToBytes(raw)
+	return enc
+}
+
+// Tests that keys and signatures are packed in the order the group signature
+// precompile takes their elements.
+func TestPackGroupSignature(t *testing.T) {
+	key := groupElements(128, 128, 128, 128, 128, 128, 128, 128, 128)
+	sig := groupElements(groupSigSizes...)
+
+	packedKey, err := PackGroupKey(key)
+	if err != nil {
+		t.Fatalf("failed to pack key: %v", err)
+	}
+	packedSig, err := PackGroupSignature(sig)
+	if err != nil {
+		t.Fatalf("failed to pack signature: %v", err)
+	}
+	if len(packedSig) != 6*128+4*20 {
+		t.Fatalf("packed signature length mismatch: have %d, want %d", len(packedSig), 6*128+4*20)
+	}
+	unpacked, err := UnpackGroupSignature(packedSig)
+	if err != nil {
+		t.Fatalf("failed to unpack signature: %v", err)
+	}
+	if !bytes.Equal(unpacked, sig) {
+		t.Errorf("unpacked signature mismatch: have %x, want %x", unpacked, sig)
+	}
+	// The key elements are reordered as g, g2, u0, u1, u2, u3, u4, n, h1
+	input, err := GroupSignatureInput(packedKey, packedSig, []byte("msg"))
+	if err != nil {
+		t.Fatalf("failed to create precompile input: %v", err)
+	}
+	fields := strings.Split(string(input), "#")
+	if len(fields) != 20 {
+		t.Fatalf("input field count mismatch: have %d, want 20", len(fields))
+	}
+	for i, elem := range []int{0, 1, 3, 4, 5, 6, 7, 8, 2} {
+		if want := hex.EncodeToString(bytes.Repeat([]byte{byte(elem)}, 128)); fields[i] != want {
+			t.Errorf("key field %d mismatch: have %s, want element %d", i, fields[i], elem)
+		}
+	}
+	for i, size := range groupSigSizes {
+		if want := hex.EncodeToString(bytes.Repeat([]byte{byte(i)}, size)); fields[9+i] != want {
+			t.Errorf("signature field %d mismatch: have %s, want %s", i, fields[9+i], want)
+		}
+	}
+	if fields[19] != hex.EncodeToString([]byte("msg")) {
+		t.Errorf("message field mismatch: have %s", fields[19])
+	}
+	// Elements of unexpected sizes must be rejected
+	if _, err := PackGroupKey(groupElements(128, 128, 20, 128, 128, 128, 128, 128, 128)); err == nil {
+		t.Errorf("packed key with a short element")
+	}
+	if _, err := PackGroupSignature(groupElements(groupSigSizes[:9]...)); err == nil {
+		t.Errorf("packed signature with a missing element")
+	}
+	if _, err := UnpackGroupSignature(packedSig[1:]); err == nil {
+		t.Errorf("unpacked short signature")
+	}
+	if _, err := GroupSignatureInput(packedKey[1:], packedSig, nil); err != errGroupKeyEncoding {
+		t.Errorf("short key error mismatch: have %v, want %v", err, errGroupKeyEncoding)
+	}
+	if _, err := GroupSignatureInput(packedKey, packedSig[1:], nil); err != errGroupSigEncoding {
+		t.Errorf("short signature error mismatch: have %v, want %v", err, errGroupSigEncoding)
+	}
+}
@@ -31,6 +31,7 @@ type Config struct {
 	Tracer                  Tracer // Opcode logger
 	NoRecursion             bool   // Disables call, callcode, delegate call and create
 	EnablePreimageRecording bool   // Enables recording of SHA3/keccak preimages
+	ParallelExecution       bool   // Enables speculative parallel execution of block transactions
 
 	JumpTable [256]*operation // EVM instruction table, automatically populated if unset
 
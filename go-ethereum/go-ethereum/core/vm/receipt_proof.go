@@ -0,0 +1,118 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var (
+	// errReceiptProofEncoding is returned if the input of the receipt proof
+	// precompile is malformed.
+	errReceiptProofEncoding = errors.New("invalid receipt proof encoding")
+
+	// errReceiptProofMissing is returned if a receipt proof proves the absence of
+	// the receipt.
+	errReceiptProofMissing = errors.New("receipt not in block")
+
+	// errReceiptProofLog is returned if the log proven is out of the range of the
+	// logs of the receipt.
+	errReceiptProofLog = errors.New("log index out of range")
+)
+
+// ReceiptLogProof is the input of the receipt proof precompile, RLP encoded: a
+// merkle proof of a receipt being included in the receipt trie of a block, and
+// the index of the log of the receipt to return.
+//
+// The precompile doesn't tell whether the header is one of a canonical chain,
+// callers check the hash of the block it returns against the headers they know.
+// The precompile is only available on chains past their receipt proof fork, see
+// params.ChainConfig.ReceiptProofBlock.
+type ReceiptLogProof struct {
+	Header   []byte   // RLP encoded header of the block the receipt is in
+	Index    uint64   // Index of the receipt in the block
+	Nodes    [][]byte // Trie nodes on the path to the receipt
+	LogIndex uint64   // Index of the log in the receipt
+}
+
+// The receipt proof precompile returns the hash and number of the block, the
+// address of the contract emitting the log, the number of topics of the log
+// followed by four words holding them, zero filled, and the log data.
+const (
+	receiptProofHeaderSize = 8 * 32
+	receiptProofMaxTopics  = 4
+)
+
+// receiptProof implements the receipt proof precompile, verifying the inclusion
+// of a receipt in a block and returning one of its logs.
+type receiptProof struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *receiptProof) RequiredGas(input []byte) uint64 {
+	return params.ReceiptProofBaseGas + uint64(len(input)+31)/32*params.ReceiptProofPerWordGas
+}
+
+func (c *receiptProof) Run(input []byte) ([]byte, error) {
+	var proof ReceiptLogProof
+	if err := rlp.DecodeBytes(input, &proof); err != nil {
+		return nil, errReceiptProofEncoding
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(proof.Header, header); err != nil {
+		return nil, errReceiptProofEncoding
+	}
+	nodes := memorydb.New()
+	for _, node := range proof.Nodes {
+		nodes.Put(crypto.Keccak256(node), node)
+	}
+	key, _ := rlp.EncodeToBytes(uint(proof.Index))
+	value, err := trie.VerifyProof(header.ReceiptHash, key, nodes)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errReceiptProofMissing
+	}
+	receipt := new(types.Receipt)
+	if err := receipt.UnmarshalBinary(value); err != nil {
+		return nil, errReceiptProofEncoding
+	}
+	if proof.LogIndex >= uint64(len(receipt.Logs)) {
+		return nil, errReceiptProofLog
+	}
+	log := receipt.Logs[proof.LogIndex]
+	if len(log.Topics) > receiptProofMaxTopics {
+		return nil, errReceiptProofLog
+	}
+	output := make([]byte, receiptProofHeaderSize, receiptProofHeaderSize+len(log.Data))
+	copy(output[0:32], crypto.Keccak256(proof.Header))
+	copy(output[32:64], common.LeftPadBytes(header.Number.Bytes(), 32))
+	copy(output[64:96], common.LeftPadBytes(log.Address[:], 32))
+	output[127] = byte(len(log.Topics))
+	for i, topic := range log.Topics {
+		copy(output[128+32*i:], topic[:])
+	}
+	return append(output, log.Data...), nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// proofList collects the trie nodes of a proof.
+type proofList [][]byte
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// Tests that the receipt proof precompile returns the logs of the receipts
+// proven against a header, and rejects invalid proofs.
+func TestReceiptProof(t *testing.T) {
+	// Create a block of receipts with a log each, and a log-less one
+	var receipts types.Receipts
+	for i := 0; i < 20; i++ {
+		receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: uint64(21000 * (i + 1))}
+		if i != 7 {
+			receipt.Logs = []*types.Log{{
+				Address: common.BytesToAddress([]byte{byte(i)}),
+				Topics:  []common.Hash{common.BytesToHash([]byte{1, byte(i)}), common.BytesToHash([]byte{2, byte(i)})},
+				Data:    bytes.Repeat([]byte{byte(i)}, i),
+			}}
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		receipts = append(receipts, receipt)
+	}
+	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	header := &types.Header{Number: big.NewInt(1234), ReceiptHash: types.DeriveSha(receipts, tr), Difficulty: big.NewInt(1)}
+	headerRLP, _ := rlp.EncodeToBytes(header)
+
+	prove := func(index, logIndex uint64) []byte {
+		var nodes proofList
+		key, _ := rlp.EncodeToBytes(uint(index))
+		if err := tr.Prove(key, 0, &nodes); err != nil {
+			t.Fatalf("failed to prove receipt %d: %v", index, err)
+		}
+		input, _ := rlp.EncodeToBytes(&ReceiptLogProof{Header: headerRLP, Index: index, Nodes: nodes, LogIndex: logIndex})
+		return input
+	}
+	precompile := new(receiptProof)
+	for i, receipt := range receipts {
+		if i == 7 {
+			continue
+		}
+		output, err := precompile.Run(prove(uint64(i), 0))
+		if err != nil {
+			t.Fatalf("receipt %d: failed to verify proof: %v", i, err)
+		}
+		log := receipt.Logs[0]
+		want := make([]byte, 0, receiptProofHeaderSize+len(log.Data))
+		want = append(want, header.Hash().Bytes()...)
+		want = append(want, common.LeftPadBytes(header.Number.Bytes(), 32)...)
+		want = append(want, common.LeftPadBytes(log.Address[:], 32)...)
+		want = append(want, common.LeftPadBytes([]byte{2}, 32)...)
+		want = append(want, log.Topics[0][:]...)
+		want = append(want, log.Topics[1][:]...)
+		want = append(want, make([]byte, 64)...)
+		want = append(want, log.Data...)
+		if !bytes.Equal(output, want) {
+			t.Errorf("receipt %d: output mismatch:\nhave %x\nwant %x", i, output, want)
+		}
+	}
+	// Invalid proofs and missing logs are rejected
+	if _, err := precompile.Run(prove(7, 0)); err != errReceiptProofLog {
+		t.Errorf("log-less receipt: error mismatch: have %v, want %v", err, errReceiptProofLog)
+	}
+	if _, err := precompile.Run(prove(3, 1)); err != errReceiptProofLog {
+		t.Errorf("missing log: error mismatch: have %v, want %v", err, errReceiptProofLog)
+	}
+	if _, err := precompile.Run(prove(uint64(len(receipts)), 0)); err != errReceiptProofMissing {
+		t.Errorf("missing receipt: error mismatch: have %v, want %v", err, errReceiptProofMissing)
+	}
+	if _, err := precompile.Run(prove(3, 0)[1:]); err != errReceiptProofEncoding {
+		t.Errorf("malformed input: error mismatch: have %v, want %v", err, errReceiptProofEncoding)
+	}
+	var proof ReceiptLogProof
+	rlp.DecodeBytes(prove(3, 0), &proof)
+	proof.Index = 4
+	input, _ := rlp.EncodeToBytes(&proof)
+	if _, err := precompile.Run(input); err == nil {
+		t.Errorf("proof of another receipt verified")
+	}
+	other := *header
+	other.ReceiptHash = common.Hash{0x01}
+	proof.Index = 3
+	proof.Header, _ = rlp.EncodeToBytes(&other)
+	input, _ = rlp.EncodeToBytes(&proof)
+	if _, err := precompile.Run(input); err == nil {
+		t.Errorf("proof verified against another header")
+	}
+}
+
+// Tests that the receipt proof precompile is only available from its fork.
+func TestReceiptProofFork(t *testing.T) {
+	config := *params.TestChainConfig
+	config.ReceiptProofBlock = big.NewInt(10)
+
+	addr := common.BytesToAddress([]byte{21})
+	for _, test := range []struct {
+		number uint64
+		active bool
+	}{
+		{0, false},
+		{9, false},
+		{10, true},
+		{11, true},
+	} {
+		evm := NewEVM(BlockContext{BlockNumber: new(big.Int).SetUint64(test.number)}, TxContext{}, nil, &config, Config{})
+		if _, ok := evm.precompile(addr); ok != test.active {
+			t.Errorf("block %d: precompile availability mismatch: have %v, want %v", test.number, ok, test.active)
+		}
+		var listed bool
+		for _, active := range evm.ActivePrecompiles() {
+			listed = listed || active == addr
+		}
+		if listed != test.active {
+			t.Errorf("block %d: precompile listing mismatch: have %v, want %v", test.number, listed, test.active)
+		}
+	}
+}
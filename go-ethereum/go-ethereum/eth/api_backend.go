@@ -175,6 +175,10 @@ func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (type
 	return b.eth.blockchain.GetReceiptsByHash(hash), nil
 }
 
+func (b *EthAPIBackend) GetExtendedReceipts(ctx context.Context, hash common.Hash) (types.ExtendedReceipts, error) {
+	return b.eth.blockchain.GetExtendedReceiptsByHash(hash), nil
+}
+
 func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
 	receipts := b.eth.blockchain.GetReceiptsByHash(hash)
 	if receipts == nil {
@@ -207,6 +211,10 @@ func (b *EthAPIBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.
 	return b.eth.miner.SubscribePendingLogs(ch)
 }
 
+func (b *EthAPIBackend) SubscribeStateDiffEvent(ch chan<- core.StateDiffEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeStateDiffEvent(ch)
+}
+
 func (b *EthAPIBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.eth.BlockChain().SubscribeChainEvent(ch)
 }
@@ -227,6 +235,30 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.AddLocal(signedTx)
 }
 
+func (b *EthAPIBackend) SendTxWithDeadline(ctx context.Context, signedTx *types.Transaction, deadline uint64) error {
+	return b.eth.txPool.AddLocalWithDeadline(signedTx, deadline)
+}
+
+func (b *EthAPIBackend) SendTxWithCondition(ctx context.Context, signedTx *types.Transaction, cond core.TxCondition) error {
+	return b.eth.txPool.AddLocalWithCondition(signedTx, cond)
+}
+
+func (b *EthAPIBackend) CancelTimelockedTx(hash common.Hash) bool {
+	return b.eth.txPool.CancelTimelocked(hash)
+}
+
+func (b *EthAPIBackend) CommitTx(ctx context.Context, hash common.Hash) error {
+	return b.eth.txPool.Commit(hash)
+}
+
+func (b *EthAPIBackend) RevealTx(ctx context.Context, signedTx *types.Transaction) error {
+	return b.eth.txPool.Reveal(signedTx)
+}
+
+func (b *EthAPIBackend) SendBundle(ctx context.Context, bundle *core.Bundle) error {
+	return b.eth.bundlePool.Add(bundle)
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending, err := b.eth.txPool.Pending()
 	if err != nil {
@@ -260,6 +292,10 @@ func (b *EthAPIBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.eth.TxPool().Content()
 }
 
+func (b *EthAPIBackend) TxPoolTimelocked() map[common.Address]types.Transactions {
+	return b.eth.TxPool().Timelocked()
+}
+
 func (b *EthAPIBackend) TxPool() *core.TxPool {
 	return b.eth.TxPool()
 }
@@ -268,6 +304,10 @@ func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.S
 	return b.eth.TxPool().SubscribeNewTxsEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeDeadlineExpiredEvent(ch chan<- core.DeadlineExpiredEvent) event.Subscription {
+	return b.eth.TxPool().SubscribeDeadlineExpiredEvent(ch)
+}
+
 func (b *EthAPIBackend) Downloader() *downloader.Downloader {
 	return b.eth.Downloader()
 }
@@ -0,0 +1,188 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// callCounter increments and returns storage slot 0 when called without
+	// data, and reverts with the reason "boom" when called with any data.
+	callCounter = hexutil.MustDecode("0x366016576001600054018060005560005260206000f35b6064602360003960646000fd" +
+		"08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"626f6f6d00000000000000000000000000000000000000000000000000000000")
+
+	// callClock returns the number and the time of the block it's called in.
+	callClock = hexutil.MustDecode("0x436000524260205260406000f3")
+
+	callCounterAddr = common.HexToAddress("0xc0")
+	callClockAddr   = common.HexToAddress("0xc1")
+)
+
+// newTestCallEthereum creates an Ethereum service with just enough set up to
+// execute calls on top of a chain with a few empty blocks.
+func newTestCallEthereum(t *testing.T) *Ethereum {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &core.Genesis{Config: params.TestChainConfig}
+	)
+	genesis := gspec.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	config := DefaultConfig
+	eth := &Ethereum{config: &config, blockchain: chain}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	return eth
+}
+
+// callWord returns the 32 byte big endian encoding of the given number.
+func callWord(n uint64) hexutil.Bytes {
+	return common.BigToHash(new(big.Int).SetUint64(n)).Bytes()
+}
+
+// Tests that calls simulated in a sequence see the state changes made by the
+// calls before them, that overrides are applied and that failures are reported
+// per call.
+func TestCallMany(t *testing.T) {
+	eth := newTestCallEthereum(t)
+	defer eth.blockchain.Stop()
+
+	var (
+		api      = ethapi.NewPublicBlockChainAPI(eth.APIBackend)
+		code     = hexutil.Bytes(callCounter)
+		clock    = hexutil.Bytes(callClock)
+		data     = hexutil.Bytes{0x01}
+		number   = (*hexutil.Big)(big.NewInt(1000))
+		time     = hexutil.Uint64(123456)
+		latest   = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		override = &ethapi.StateOverride{
+			callCounterAddr: ethapi.OverrideAccount{Code: &code},
+			callClockAddr:   ethapi.OverrideAccount{Code: &clock},
+		}
+	)
+	calls := []ethapi.CallArgs{
+		{To: &callCounterAddr},
+		{To: &callCounterAddr},
+		{To: &callCounterAddr, Data: &data},
+		{To: &callCounterAddr},
+		{To: &callClockAddr},
+	}
+	results, err := api.CallMany(context.Background(), calls, latest, override, &ethapi.BlockOverrides{Number: number, Time: &time})
+	if err != nil {
+		t.Fatalf("failed to execute calls: %v", err)
+	}
+	if len(results) != len(calls) {
+		t.Fatalf("result count mismatch: have %d, want %d", len(results), len(calls))
+	}
+	for i, want := range []uint64{1, 2} {
+		if res := results[i]; res.Error != "" || string(res.ReturnData) != string(callWord(want)) {
+			t.Errorf("call %d: result mismatch: have %x (error %q), want %x", i, res.ReturnData, res.Error, callWord(want))
+		}
+	}
+	if res := results[2]; res.Error != vm.ErrExecutionReverted.Error() || res.RevertReason != "boom" {
+		t.Errorf("call 2: failure mismatch: have error %q reason %q, want error %q reason %q", res.Error, res.RevertReason, vm.ErrExecutionReverted, "boom")
+	}
+	// The reverted call must not have changed the state nor stopped the sequence
+	if res := results[3]; res.Error != "" || string(res.ReturnData) != string(callWord(3)) {
+		t.Errorf("call 3: result mismatch: have %x (error %q), want %x", res.ReturnData, res.Error, callWord(3))
+	}
+	if want := append(callWord(1000), callWord(123456)...); string(results[4].ReturnData) != string(want) {
+		t.Errorf("call 4: block context mismatch: have %x, want %x", results[4].ReturnData, want)
+	}
+	// The chain state must be left untouched
+	statedb, _ := eth.blockchain.State()
+	if statedb.GetCodeSize(callCounterAddr) != 0 {
+		t.Errorf("overridden code leaked into the chain state")
+	}
+	// Conflicting overrides must be rejected
+	slots := map[common.Hash]common.Hash{}
+	conflict := &ethapi.StateOverride{callCounterAddr: ethapi.OverrideAccount{Code: &code, State: &slots, StateDiff: &slots}}
+	if _, err := api.CallMany(context.Background(), calls, latest, conflict, nil); err == nil {
+		t.Errorf("conflicting state overrides accepted")
+	}
+}
+
+// Tests that traced calls in a sequence see the state changes made by the calls
+// before them and that overrides are applied.
+func TestTraceCallMany(t *testing.T) {
+	eth := newTestCallEthereum(t)
+	defer eth.blockchain.Stop()
+
+	var (
+		api    = NewPrivateDebugAPI(eth)
+		code   = hexutil.Bytes(callCounter)
+		data   = hexutil.Bytes{0x01}
+		number = (*hexutil.Big)(big.NewInt(1000))
+		slots  = map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(10))}
+		latest = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	config := &TraceCallConfig{
+		StateOverrides: &ethapi.StateOverride{
+			callCounterAddr: ethapi.OverrideAccount{Code: &code, StateDiff: &slots},
+		},
+		BlockOverrides: &ethapi.BlockOverrides{Number: number},
+	}
+	calls := []ethapi.CallArgs{
+		{To: &callCounterAddr},
+		{To: &callCounterAddr, Data: &data},
+		{To: &callCounterAddr},
+	}
+	traces, err := api.TraceCallMany(context.Background(), calls, latest, config)
+	if err != nil {
+		t.Fatalf("failed to trace calls: %v", err)
+	}
+	if len(traces) != len(calls) {
+		t.Fatalf("trace count mismatch: have %d, want %d", len(traces), len(calls))
+	}
+	for i, want := range []struct {
+		failed bool
+		ret    []byte
+	}{
+		{false, callWord(11)},
+		{true, callCounter[35:]},
+		{false, callWord(12)},
+	} {
+		res := traces[i].(*ethapi.ExecutionResult)
+		if res.Failed != want.failed || res.ReturnValue != common.Bytes2Hex(want.ret) {
+			t.Errorf("call %d: trace mismatch: have failed %v return %s, want failed %v return %x", i, res.Failed, res.ReturnValue, want.failed, want.ret)
+		}
+		if len(res.StructLogs) == 0 {
+			t.Errorf("call %d: no execution steps traced", i)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core"
+)
+
+// devMineTimeout is the maximum time to wait for a block requested via evm_mine
+// to be sealed.
+const devMineTimeout = 10 * time.Second
+
+var (
+	errDevUnsupported = errors.New("time travel requires a clique developer chain")
+	errDevNotMining   = errors.New("miner not running")
+)
+
+// devSnapshot is a chain snapshot taken via evm_snapshot.
+type devSnapshot struct {
+	number uint64        // Head block number at the time of the snapshot
+	offset time.Duration // Clock offset at the time of the snapshot
+}
+
+// PrivateDevAPI provides time travel and chain snapshots on developer chains,
+// mirroring the evm_* methods of Ganache. It allows timeouts depending on block
+// numbers and timestamps to be exercised without waiting for them.
+type PrivateDevAPI struct {
+	e *Ethereum
+
+	offset    time.Duration // Total shift of the clock used to time new blocks
+	snapshots []devSnapshot // Snapshots taken, the id of each being its index + 1
+	lock      sync.Mutex
+}
+
+// NewPrivateDevAPI creates a new time travel and chain snapshot API.
+func NewPrivateDevAPI(e *Ethereum) *PrivateDevAPI {
+	return &PrivateDevAPI{e: e}
+}
+
+// engine returns the clique engine of the developer chain.
+func (api *PrivateDevAPI) engine() (*clique.Clique, error) {
+	engine, ok := api.e.engine.(*clique.Clique)
+	if !ok {
+		return nil, errDevUnsupported
+	}
+	return engine, nil
+}
+
+// setOffset shifts the clock of both the consensus engine and the miner.
+//
+// Note, this method assumes the api lock is held!
+func (api *PrivateDevAPI) setOffset(engine *clique.Clique, offset time.Duration) {
+	api.offset = offset
+	engine.SetTimeOffset(offset)
+	api.e.miner.SetTimeOffset(offset)
+}
+
+// IncreaseTime moves the clock used to time new blocks forward by the given
+// number of seconds. It returns the total shift of the clock in seconds.
+func (api *PrivateDevAPI) IncreaseTime(seconds uint64) (uint64, error) {
+	engine, err := api.engine()
+	if err != nil {
+		return 0, err
+	}
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	api.setOffset(engine, api.offset+time.Duration(seconds)*time.Second)
+	return uint64(api.offset / time.Second), nil
+}
+
+// Mine seals a new block on top of the current head, even if there are no
+// transactions to include, and returns its hash. If a timestamp is given, the
+// clock is moved for the block to be sealed at that time.
+func (api *PrivateDevAPI) Mine(ctx context.Context, timestamp *uint64) (common.Hash, error) {
+	engine, err := api.engine()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !api.e.IsMining() {
+		return common.Hash{}, errDevNotMining
+	}
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	parent := api.e.blockchain.CurrentHeader()
+	if timestamp != nil {
+		if *timestamp <= parent.Time {
+			return common.Hash{}, fmt.Errorf("timestamp %d not after parent %d", *timestamp, parent.Time)
+		}
+		api.setOffset(engine, time.Until(time.Unix(int64(*timestamp), 0)))
+	}
+	// Subscribe to the new head before requesting it to avoid missing it
+	heads := make(chan core.ChainHeadEvent, 1)
+	sub := api.e.blockchain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	if pending, _ := api.e.txPool.Stats(); pending == 0 {
+		engine.AllowEmptyBlock()
+	}
+	api.e.miner.Recommit()
+
+	ctx, cancel := context.WithTimeout(ctx, devMineTimeout)
+	defer cancel()
+	for {
+		select {
+		case head := <-heads:
+			if head.Block.NumberU64() > parent.Number.Uint64() {
+				return head.Block.Hash(), nil
+			}
+		case err := <-sub.Err():
+			return common.Hash{}, err
+		case <-ctx.Done():
+			return common.Hash{}, ctx.Err()
+		}
+	}
+}
+
+// Snapshot records the current head of the chain and the clock shift, for them
+// to be restored via Revert. It returns the id of the snapshot.
+func (api *PrivateDevAPI) Snapshot() hexutil.Uint64 {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	api.snapshots = append(api.snapshots, devSnapshot{
+		number: api.e.blockchain.CurrentBlock().NumberU64(),
+		offset: api.offset,
+	})
+	return hexutil.Uint64(len(api.snapshots))
+}
+
+// Revert rewinds the chain and the clock to the given snapshot, dropping all
+// the pooled transactions. The snapshot and all the ones taken after it are
+// discarded. It returns false if the snapshot is unknown.
+func (api *PrivateDevAPI) Revert(id hexutil.Uint64) (bool, error) {
+	engine, err := api.engine()
+	if err != nil {
+		return false, err
+	}
+	api.lock.Lock()
+	defer api.lock.Unlock()
+
+	if id == 0 || int(id) > len(api.snapshots) {
+		return false, nil
+	}
+	snapshot := api.snapshots[id-1]
+	api.snapshots = api.snapshots[:id-1]
+
+	if err := api.e.blockchain.SetHead(snapshot.number); err != nil {
+		return false, err
+	}
+	if head := api.e.blockchain.CurrentBlock().NumberU64(); head != snapshot.number {
+		return false, fmt.Errorf("state of block %d unavailable, rewound to %d", snapshot.number, head)
+	}
+	api.e.txPool.Clear()
+	api.setOffset(engine, snapshot.offset)
+	api.e.miner.Recommit()
+
+	return true, nil
+}
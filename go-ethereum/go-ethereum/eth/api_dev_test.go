@@ -0,0 +1,138 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the clock of a developer chain can be moved, that blocks can be
+// mined on demand and that the chain can be reverted to a snapshot.
+func TestDevTimeTravel(t *testing.T) {
+	stack, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	developer, _ := ks.NewAccount("")
+	if err := ks.Unlock(developer, ""); err != nil {
+		t.Fatalf("failed to unlock developer account: %v", err)
+	}
+	config := DefaultConfig
+	config.Genesis = core.DeveloperGenesisBlock(0, developer.Address)
+	config.Miner.Etherbase = developer.Address
+	config.Miner.GasPrice = big.NewInt(1)
+	config.Developer = true
+
+	ethereum, err := New(stack, &config)
+	if err != nil {
+		t.Fatalf("failed to create ethereum service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	if err := ethereum.StartMining(1); err != nil {
+		t.Fatalf("failed to start mining: %v", err)
+	}
+	for start := time.Now(); !ethereum.IsMining(); time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("miner not started")
+		}
+	}
+	var (
+		api   = NewPrivateDevAPI(ethereum)
+		chain = ethereum.BlockChain()
+		ctx   = context.Background()
+	)
+	snapshot := api.Snapshot()
+
+	// Mine an empty block on the current clock
+	if _, err := api.Mine(ctx, nil); err != nil {
+		t.Fatalf("failed to mine block: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.NumberU64() != 1 || head.Time() > uint64(time.Now().Unix()) {
+		t.Fatalf("block 1 mismatch: number %d, time %d", head.NumberU64(), head.Time())
+	}
+	// Move the clock forward and mine on it
+	if total, err := api.IncreaseTime(3600); err != nil || total != 3600 {
+		t.Fatalf("failed to increase time: total %d, err %v", total, err)
+	}
+	if _, err := api.Mine(ctx, nil); err != nil {
+		t.Fatalf("failed to mine block: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.NumberU64() != 2 || head.Time() < uint64(time.Now().Unix())+3600 {
+		t.Fatalf("block 2 mismatch: number %d, time %d", head.NumberU64(), head.Time())
+	}
+	// Mine a block at an explicit time, transactions following it
+	timestamp := uint64(time.Now().Unix()) + 86400
+	if _, err := api.Mine(ctx, &timestamp); err != nil {
+		t.Fatalf("failed to mine block: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.NumberU64() != 3 || head.Time() != timestamp {
+		t.Fatalf("block 3 mismatch: number %d, time %d, want %d", head.NumberU64(), head.Time(), timestamp)
+	}
+	past := timestamp - 1
+	if _, err := api.Mine(ctx, &past); err == nil {
+		t.Fatalf("mined block before its parent")
+	}
+	tx, err := ks.SignTx(developer, types.NewTransaction(0, developer.Address, big.NewInt(1), params.TxGas, big.NewInt(1), nil), nil)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	heads := make(chan core.ChainHeadEvent, 1)
+	sub := chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	if err := ethereum.TxPool().AddLocal(tx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	select {
+	case head := <-heads:
+		if head.Block.NumberU64() != 4 || head.Block.Time() < timestamp || len(head.Block.Transactions()) != 1 {
+			t.Fatalf("block 4 mismatch: number %d, time %d, txs %d", head.Block.NumberU64(), head.Block.Time(), len(head.Block.Transactions()))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("transaction not mined")
+	}
+	// Revert everything and ensure the chain restarts from the genesis
+	if ok, err := api.Revert(snapshot); !ok || err != nil {
+		t.Fatalf("failed to revert: ok %v, err %v", ok, err)
+	}
+	if head := chain.CurrentBlock().NumberU64(); head != 0 {
+		t.Fatalf("head mismatch after revert: have %d, want %d", head, 0)
+	}
+	if ok, _ := api.Revert(snapshot); ok {
+		t.Fatalf("reverted to a discarded snapshot")
+	}
+	if _, err := api.Mine(ctx, nil); err != nil {
+		t.Fatalf("failed to mine block: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.NumberU64() != 1 || head.Time() > uint64(time.Now().Unix()) {
+		t.Fatalf("block 1 mismatch after revert: number %d, time %d", head.NumberU64(), head.Time())
+	}
+}
@@ -740,10 +740,64 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	return api.traceTx(ctx, msg, vmctx, statedb, config)
 }
 
+// TraceCallConfig is the config for traceCall and traceCallMany. On top of the
+// regular trace config, it allows overriding the state and the block context
+// the calls are executed in.
+type TraceCallConfig struct {
+	TraceConfig
+	StateOverrides *ethapi.StateOverride
+	BlockOverrides *ethapi.BlockOverrides
+}
+
+// traceConfig returns the plain trace config, nil if none was given.
+func (config *TraceCallConfig) traceConfig() *TraceConfig {
+	if config == nil {
+		return nil
+	}
+	return &config.TraceConfig
+}
+
 // TraceCall lets you trace a given eth_call. It collects the structured logs created during the execution of EVM
 // if the given transaction was added on top of the provided block and returns them as a JSON object.
 // You can provide -2 as a block number to trace on top of the pending block.
-func (api *PrivateDebugAPI) TraceCall(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (interface{}, error) {
+func (api *PrivateDebugAPI) TraceCall(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
+	statedb, header, err := api.callEnv(ctx, blockNrOrHash, config)
+	if err != nil {
+		return nil, err
+	}
+	// Execute the trace
+	msg := args.ToMessage(api.eth.APIBackend.RPCGasCap())
+	vmctx := core.NewEVMBlockContext(header, api.eth.blockchain, nil)
+	return api.traceTx(ctx, msg, vmctx, statedb, config.traceConfig())
+}
+
+// TraceCallMany lets you trace a sequence of eth_calls executed in order on top
+// of the provided block, each call seeing the state changes made by the ones
+// before it. It returns the trace of every call.
+func (api *PrivateDebugAPI) TraceCallMany(ctx context.Context, calls []ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) ([]interface{}, error) {
+	statedb, header, err := api.callEnv(ctx, blockNrOrHash, config)
+	if err != nil {
+		return nil, err
+	}
+	// Execute the traces, finalising the changes of each call for the next one
+	var (
+		vmctx              = core.NewEVMBlockContext(header, api.eth.blockchain, nil)
+		deleteEmptyObjects = api.eth.blockchain.Config().IsEIP158(header.Number)
+		results            = make([]interface{}, len(calls))
+	)
+	for i, args := range calls {
+		msg := args.ToMessage(api.eth.APIBackend.RPCGasCap())
+		if results[i], err = api.traceTx(ctx, msg, vmctx, statedb, config.traceConfig()); err != nil {
+			return nil, fmt.Errorf("call %d: %v", i, err)
+		}
+		statedb.Finalise(deleteEmptyObjects)
+	}
+	return results, nil
+}
+
+// callEnv returns the state and header to trace calls on top of the given block,
+// with the overrides of the config applied.
+func (api *PrivateDebugAPI) callEnv(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (*state.StateDB, *types.Header, error) {
 	// First try to retrieve the state
 	statedb, header, err := api.eth.APIBackend.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
 	if err != nil {
@@ -755,23 +809,25 @@ func (api *PrivateDebugAPI) TraceCall(ctx context.Context, args ethapi.CallArgs,
 			block = api.eth.blockchain.GetBlockByNumber(uint64(number))
 		}
 		if block == nil {
-			return nil, fmt.Errorf("block %v not found: %v", blockNrOrHash, err)
+			return nil, nil, fmt.Errorf("block %v not found: %v", blockNrOrHash, err)
 		}
 		// try to recompute the state
 		reexec := defaultTraceReexec
 		if config != nil && config.Reexec != nil {
 			reexec = *config.Reexec
 		}
-		_, _, statedb, err = api.computeTxEnv(block, 0, reexec)
-		if err != nil {
-			return nil, err
+		if statedb, err = api.computeStateDB(block, reexec); err != nil {
+			return nil, nil, err
 		}
+		header = block.Header()
 	}
-
-	// Execute the trace
-	msg := args.ToMessage(api.eth.APIBackend.RPCGasCap())
-	vmctx := core.NewEVMBlockContext(header, api.eth.blockchain, nil)
-	return api.traceTx(ctx, msg, vmctx, statedb, config)
+	if config != nil {
+		if err := config.StateOverrides.Apply(statedb); err != nil {
+			return nil, nil, err
+		}
+		header = config.BlockOverrides.Apply(header)
+	}
+	return statedb, header, nil
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
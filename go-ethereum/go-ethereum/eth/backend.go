@@ -60,6 +60,7 @@ type Ethereum struct {
 
 	// Handlers
 	txPool          *core.TxPool
+	bundlePool      *core.BundlePool
 	blockchain      *core.BlockChain
 	protocolManager *ProtocolManager
 	dialCandidates  enode.Iterator
@@ -158,6 +159,7 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	var (
 		vmConfig = vm.Config{
 			EnablePreimageRecording: config.EnablePreimageRecording,
+			ParallelExecution:       config.ParallelExecution,
 			EWASMInterpreter:        config.EWASMInterpreter,
 			EVMInterpreter:          config.EVMInterpreter,
 		}
@@ -171,6 +173,7 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
+			ExtendedReceipts:    config.ExtendedReceipts,
 		}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, eth.engine, vmConfig, eth.shouldPreserve, &config.TxLookupLimit)
@@ -188,7 +191,11 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.TimelockJournal != "" {
+		config.TxPool.TimelockJournal = stack.ResolvePath(config.TxPool.TimelockJournal)
+	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
+	eth.bundlePool = core.NewBundlePool(chainConfig, eth.blockchain, config.TxPool.PriceLimit)
 
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
@@ -295,8 +302,8 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
-	// Append all the local APIs and return
-	return append(apis, []rpc.API{
+	// Append all the local APIs
+	apis = append(apis, []rpc.API{
 		{
 			Namespace: "eth",
 			Version:   "1.0",
@@ -342,6 +349,15 @@ func (s *Ethereum) APIs() []rpc.API {
 			Public:    true,
 		},
 	}...)
+	// Expose the time travel and chain snapshot methods on developer chains
+	if s.config.Developer {
+		apis = append(apis, rpc.API{
+			Namespace: "evm",
+			Version:   "1.0",
+			Service:   NewPrivateDevAPI(s),
+		})
+	}
+	return apis
 }
 
 func (s *Ethereum) ResetWithGenesisBlock(gb *types.Block) {
@@ -451,11 +467,12 @@ func (s *Ethereum) StartMining(threads int) error {
 	}
 	// If the miner was not running, initialize it
 	if !s.IsMining() {
-		// Propagate the initial price point to the transaction pool
+		// Propagate the initial price point to the transaction and bundle pools
 		s.lock.RLock()
 		price := s.gasPrice
 		s.lock.RUnlock()
 		s.txPool.SetGasPrice(price)
+		s.bundlePool.SetGasPrice(price)
 
 		// Configure the local mining address
 		eb, err := s.Etherbase()
@@ -500,6 +517,7 @@ func (s *Ethereum) Miner() *miner.Miner { return s.miner }
 func (s *Ethereum) AccountManager() *accounts.Manager  { return s.accountManager }
 func (s *Ethereum) BlockChain() *core.BlockChain       { return s.blockchain }
 func (s *Ethereum) TxPool() *core.TxPool               { return s.txPool }
+func (s *Ethereum) BundlePool() *core.BundlePool       { return s.bundlePool }
 func (s *Ethereum) EventMux() *event.TypeMux           { return s.eventMux }
 func (s *Ethereum) Engine() consensus.Engine           { return s.engine }
 func (s *Ethereum) ChainDb() ethdb.Database            { return s.chainDb }
@@ -122,6 +122,12 @@ type Config struct {
 
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 
+	// Whether to store revert reasons and return data of processed transactions
+	ExtendedReceipts bool `toml:",omitempty"`
+
+	// Whether to expose the time travel and chain snapshot APIs of developer chains
+	Developer bool `toml:",omitempty"`
+
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
 
@@ -166,6 +172,9 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Enables speculative parallel execution of imported block transactions
+	ParallelExecution bool
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
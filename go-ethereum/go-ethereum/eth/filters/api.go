@@ -28,6 +28,8 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
@@ -271,6 +273,67 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc
 	return rpcSub, nil
 }
 
+// StateDiffCriteria restricts a state diff subscription to a set of accounts.
+type StateDiffCriteria struct {
+	Addresses []common.Address `json:"addresses"`
+}
+
+// StateDiff is a notification of a state diff subscription, holding the changes
+// a block made to the state of the watched accounts.
+type StateDiff struct {
+	BlockHash   common.Hash     `json:"blockHash"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+	Accounts    state.StateDiff `json:"accounts"`
+}
+
+// StateDiffs creates a subscription that fires with the changes each block written
+// into the canonical chain made to the state. If the criteria name accounts, only
+// their changes are reported and blocks not changing any of them are skipped.
+func (api *PublicFilterAPI) StateDiffs(ctx context.Context, crit *StateDiffCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		diffs := make(chan core.StateDiffEvent, chainEvChanSize)
+		diffsSub := api.backend.SubscribeStateDiffEvent(diffs)
+
+		for {
+			select {
+			case ev := <-diffs:
+				accounts := ev.Diff
+				if crit != nil && len(crit.Addresses) > 0 {
+					accounts = make(state.StateDiff)
+					for _, addr := range crit.Addresses {
+						if diff, ok := ev.Diff[addr]; ok {
+							accounts[addr] = diff
+						}
+					}
+					if len(accounts) == 0 {
+						continue
+					}
+				}
+				notifier.Notify(rpcSub.ID, &StateDiff{
+					BlockHash:   ev.Block.Hash(),
+					BlockNumber: hexutil.Uint64(ev.Block.NumberU64()),
+					Accounts:    accounts,
+				})
+			case <-rpcSub.Err():
+				diffsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				diffsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // FilterCriteria represents a request to create a new filter.
 // Same as ethereum.FilterQuery but with UnmarshalJSON() method.
 type FilterCriteria ethereum.FilterQuery
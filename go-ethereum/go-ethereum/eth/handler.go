@@ -557,6 +557,10 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			// Retrieve the requested block body, stopping if enough was found
 			if data := pm.blockchain.GetBodyRLP(hash); len(data) != 0 {
+				// Withhold bodies the peer can't decode, carrying typed transactions
+				if !p.supportsTxs(pm.blockchain.GetBody(hash).Transactions) {
+					continue
+				}
 				bodies = append(bodies, data)
 				bytes += len(data)
 			}
@@ -664,6 +668,10 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 					continue
 				}
 			}
+			// Withhold receipts the peer can't decode, of typed transactions
+			if body := pm.blockchain.GetBody(hash); body != nil && !p.supportsTxs(body.Transactions) {
+				continue
+			}
 			// If known, encode and queue for response packet
 			if encoded, err := rlp.EncodeToBytes(results); err != nil {
 				log.Error("Failed to encode receipt", "err", err)
@@ -777,9 +785,10 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			} else if err != nil {
 				return errResp(ErrDecode, "msg %v: %v", msg, err)
 			}
-			// Retrieve the requested transaction, skipping if unknown to us
+			// Retrieve the requested transaction, skipping if unknown to us or
+			// not supported by the peer
 			tx := pm.txpool.Get(hash)
-			if tx == nil {
+			if tx == nil || !p.supportsTxType(tx.Type()) {
 				continue
 			}
 			// If known, encode and queue for response packet
@@ -808,6 +817,9 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			if tx == nil {
 				return errResp(ErrDecode, "transaction %d is nil", i)
 			}
+			if !p.supportsTxType(tx.Type()) {
+				return errResp(ErrDecode, "transaction %d: type %d not supported on eth/%d", i, tx.Type(), p.version)
+			}
 			p.MarkTransaction(tx.Hash())
 		}
 		pm.txFetcher.Enqueue(p.id, txs, msg.Code == PooledTransactionsMsg)
@@ -824,6 +836,15 @@ func (pm *ProtocolManager) BroadcastBlock(block *types.Block, propagate bool) {
 	hash := block.Hash()
 	peers := pm.peers.PeersWithoutBlock(hash)
 
+	// Skip the peers unable to decode the block, carrying typed transactions
+	supported := peers[:0]
+	for _, peer := range peers {
+		if peer.supportsTxs(block.Transactions()) {
+			supported = append(supported, peer)
+		}
+	}
+	peers = supported
+
 	// If propagation is requested, send to a subset of the peer
 	if propagate {
 		// Calculate the TD of the block (it's not imported yet, so block.Td is not valid)
@@ -866,7 +887,9 @@ func (pm *ProtocolManager) BroadcastTransactions(txs types.Transactions, propaga
 			// Send the block to a subset of our peers
 			transfer := peers[:int(math.Sqrt(float64(len(peers))))]
 			for _, peer := range transfer {
-				txset[peer] = append(txset[peer], tx.Hash())
+				if peer.supportsTxType(tx.Type()) {
+					txset[peer] = append(txset[peer], tx.Hash())
+				}
 			}
 			log.Trace("Broadcast transaction", "hash", tx.Hash(), "recipients", len(peers))
 		}
@@ -879,7 +902,9 @@ func (pm *ProtocolManager) BroadcastTransactions(txs types.Transactions, propaga
 	for _, tx := range txs {
 		peers := pm.peers.PeersWithoutTx(tx.Hash())
 		for _, peer := range peers {
-			annos[peer] = append(annos[peer], tx.Hash())
+			if peer.supportsTxType(tx.Type()) {
+				annos[peer] = append(annos[peer], tx.Hash())
+			}
 		}
 	}
 	for peer, hashes := range annos {
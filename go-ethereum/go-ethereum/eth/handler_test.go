@@ -269,6 +269,59 @@ func testGetBlockBodies(t *testing.T, protocol int) {
 	}
 }
 
+// Tests that block bodies and receipts carrying typed transactions are withheld
+// from peers on protocols not supporting them.
+func TestGetTypedBlockBodies65(t *testing.T)   { testGetTypedBlockBodies(t, eth65) }
+func TestGetTypedBlockBodies2718(t *testing.T) { testGetTypedBlockBodies(t, eth2718) }
+
+func testGetTypedBlockBodies(t *testing.T, protocol int) {
+	config := *params.TestChainConfig
+	config.YoloV2Block = big.NewInt(0)
+
+	// Include a legacy transaction in the first block and a typed one in the second
+	signer := types.NewEIP2930Signer(config.ChainID)
+	generator := func(i int, block *core.BlockGen) {
+		var tx *types.Transaction
+		if i == 0 {
+			tx, _ = types.SignTx(types.NewTransaction(block.TxNonce(testBank), common.Address{}, big.NewInt(1), params.TxGas, nil, nil), signer, testBankKey)
+		} else {
+			tx, _ = types.SignNewTx(testBankKey, signer, &types.AccessListTx{
+				ChainID: config.ChainID,
+				Nonce:   block.TxNonce(testBank),
+				To:      &common.Address{},
+				Gas:     params.TxGas,
+			})
+		}
+		block.AddTx(tx)
+	}
+	pm, _, err := newTestProtocolManagerWithConfig(&config, downloader.FullSync, 2, generator, nil)
+	if err != nil {
+		t.Fatalf("failed to create protocol manager: %v", err)
+	}
+	defer pm.Stop()
+
+	peer, _ := newTestPeer("peer", protocol, pm, true)
+	defer peer.close()
+
+	legacy, typed := pm.blockchain.GetBlockByNumber(1), pm.blockchain.GetBlockByNumber(2)
+	hashes := []common.Hash{legacy.Hash(), typed.Hash()}
+
+	bodies := []*blockBody{{Transactions: legacy.Transactions(), Uncles: legacy.Uncles()}}
+	receipts := []types.Receipts{pm.blockchain.GetReceiptsByHash(legacy.Hash())}
+	if protocol >= eth2718 {
+		bodies = append(bodies, &blockBody{Transactions: typed.Transactions(), Uncles: typed.Uncles()})
+		receipts = append(receipts, pm.blockchain.GetReceiptsByHash(typed.Hash()))
+	}
+	p2p.Send(peer.app, GetBlockBodiesMsg, hashes)
+	if err := p2p.ExpectMsg(peer.app, BlockBodiesMsg, bodies); err != nil {
+		t.Errorf("bodies mismatch: %v", err)
+	}
+	p2p.Send(peer.app, GetReceiptsMsg, hashes)
+	if err := p2p.ExpectMsg(peer.app, ReceiptsMsg, receipts); err != nil {
+		t.Errorf("receipts mismatch: %v", err)
+	}
+}
+
 // Tests that the node state database can be retrieved based on hashes.
 func TestGetNodeData63(t *testing.T) { testGetNodeData(t, 63) }
 func TestGetNodeData64(t *testing.T) { testGetNodeData(t, 64) }
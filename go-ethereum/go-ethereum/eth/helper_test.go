@@ -53,12 +53,18 @@ var (
 // with the given number of blocks already known, and potential notification
 // channels for different events.
 func newTestProtocolManager(mode downloader.SyncMode, blocks int, generator func(int, *core.BlockGen), newtx chan<- []*types.Transaction) (*ProtocolManager, ethdb.Database, error) {
+	return newTestProtocolManagerWithConfig(params.TestChainConfig, mode, blocks, generator, newtx)
+}
+
+// newTestProtocolManagerWithConfig creates a new protocol manager for testing
+// purposes like newTestProtocolManager, on a chain with the given config.
+func newTestProtocolManagerWithConfig(config *params.ChainConfig, mode downloader.SyncMode, blocks int, generator func(int, *core.BlockGen), newtx chan<- []*types.Transaction) (*ProtocolManager, ethdb.Database, error) {
 	var (
 		evmux  = new(event.TypeMux)
 		engine = ethash.NewFaker()
 		db     = rawdb.NewMemoryDatabase()
 		gspec  = &core.Genesis{
-			Config: params.TestChainConfig,
+			Config: config,
 			Alloc:  core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000)}},
 		}
 		genesis       = gspec.MustCommit(db)
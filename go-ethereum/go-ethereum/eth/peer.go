@@ -126,6 +126,23 @@ func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter, getPooledTx func(ha
 	}
 }
 
+// supportsTxType returns whether transactions of the given type may be exchanged
+// with the peer. Typed transactions are only supported from eth/2718 on.
+func (p *peer) supportsTxType(typ uint8) bool {
+	return typ == types.LegacyTxType || p.version >= eth2718
+}
+
+// supportsTxs returns whether all the given transactions may be exchanged with
+// the peer, e.g. as part of a block body.
+func (p *peer) supportsTxs(txs types.Transactions) bool {
+	for _, tx := range txs {
+		if !p.supportsTxType(tx.Type()) {
+			return false
+		}
+	}
+	return true
+}
+
 // broadcastBlocks is a write loop that multiplexes blocks and block accouncements
 // to the remote peer. The goal is to have an async writer that does not lock up
 // node internals and at the same time rate limits queued data.
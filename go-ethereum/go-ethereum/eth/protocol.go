@@ -34,16 +34,21 @@ const (
 	eth63 = 63
 	eth64 = 64
 	eth65 = 65
+
+	// eth2718 has the same messages as eth65, also carrying EIP-2718 typed
+	// transactions. It's numbered after the EIP instead of sequentially, as
+	// upstream eth/66 and later versions have different semantics.
+	eth2718 = 2718
 )
 
 // protocolName is the official short name of the protocol used during capability negotiation.
 const protocolName = "eth"
 
 // ProtocolVersions are the supported versions of the eth protocol (first is primary).
-var ProtocolVersions = []uint{eth65, eth64, eth63}
+var ProtocolVersions = []uint{eth2718, eth65, eth64, eth63}
 
 // protocolLengths are the number of implemented message corresponding to different protocol versions.
-var protocolLengths = map[uint]uint64{eth65: 17, eth64: 17, eth63: 17}
+var protocolLengths = map[uint]uint64{eth2718: 17, eth65: 17, eth64: 17, eth63: 17}
 
 const protocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -19,6 +19,7 @@ package eth
 import (
 	"fmt"
 	"math/big"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -245,9 +246,10 @@ func TestForkIDSplit(t *testing.T) {
 }
 
 // This test checks that received transactions are added to the local pool.
-func TestRecvTransactions63(t *testing.T) { testRecvTransactions(t, 63) }
-func TestRecvTransactions64(t *testing.T) { testRecvTransactions(t, 64) }
-func TestRecvTransactions65(t *testing.T) { testRecvTransactions(t, 65) }
+func TestRecvTransactions63(t *testing.T)   { testRecvTransactions(t, 63) }
+func TestRecvTransactions64(t *testing.T)   { testRecvTransactions(t, 64) }
+func TestRecvTransactions65(t *testing.T)   { testRecvTransactions(t, 65) }
+func TestRecvTransactions2718(t *testing.T) { testRecvTransactions(t, eth2718) }
 
 func testRecvTransactions(t *testing.T, protocol int) {
 	txAdded := make(chan []*types.Transaction)
@@ -273,10 +275,52 @@ func testRecvTransactions(t *testing.T, protocol int) {
 	}
 }
 
+// This test checks that typed transactions are only accepted from peers on
+// protocols supporting them, and that other peers sending them are dropped.
+func TestRecvTypedTransactions65(t *testing.T)   { testRecvTypedTransactions(t, 65) }
+func TestRecvTypedTransactions2718(t *testing.T) { testRecvTypedTransactions(t, eth2718) }
+
+func testRecvTypedTransactions(t *testing.T, protocol int) {
+	txAdded := make(chan []*types.Transaction)
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, txAdded)
+	pm.acceptTxs = 1 // mark synced to accept transactions
+	p, errc := newTestPeer("peer", protocol, pm, true)
+	defer pm.Stop()
+	defer p.close()
+
+	tx, _ := types.SignNewTx(testAccount, types.NewEIP2930Signer(params.TestChainConfig.ChainID), &types.AccessListTx{
+		ChainID:  params.TestChainConfig.ChainID,
+		Gas:      100000,
+		GasPrice: big.NewInt(0),
+	})
+	if err := p2p.Send(p.app, TransactionMsg, []interface{}{tx}); err != nil {
+		t.Fatalf("send error: %v", err)
+	}
+	select {
+	case added := <-txAdded:
+		if protocol < eth2718 {
+			t.Fatalf("typed transaction accepted on eth/%d", protocol)
+		}
+		if len(added) != 1 || added[0].Hash() != tx.Hash() {
+			t.Errorf("added wrong transactions: got %v, want %v", added, tx.Hash())
+		}
+	case err := <-errc:
+		if protocol >= eth2718 {
+			t.Fatalf("peer dropped on eth/%d: %v", protocol, err)
+		}
+		if !strings.Contains(err.Error(), "not supported") {
+			t.Errorf("drop error mismatch: have %v, want unsupported type", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("typed transaction neither added nor rejected within 2 seconds")
+	}
+}
+
 // This test checks that pending transactions are sent.
-func TestSendTransactions63(t *testing.T) { testSendTransactions(t, 63) }
-func TestSendTransactions64(t *testing.T) { testSendTransactions(t, 64) }
-func TestSendTransactions65(t *testing.T) { testSendTransactions(t, 65) }
+func TestSendTransactions63(t *testing.T)   { testSendTransactions(t, 63) }
+func TestSendTransactions64(t *testing.T)   { testSendTransactions(t, 64) }
+func TestSendTransactions65(t *testing.T)   { testSendTransactions(t, 65) }
+func TestSendTransactions2718(t *testing.T) { testSendTransactions(t, eth2718) }
 
 func testSendTransactions(t *testing.T, protocol int) {
 	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
@@ -327,6 +371,8 @@ func testSendTransactions(t *testing.T, protocol int) {
 					}
 				}
 			case 65:
+				fallthrough
+			case eth2718:
 				msg, err := p.app.ReadMsg()
 				if err != nil {
 					t.Errorf("%v: read error: %v", p.Peer, err)
@@ -36,14 +36,18 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/light"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/tyler-smith/go-bip39"
 )
 
@@ -106,8 +110,9 @@ func NewPublicTxPoolAPI(b Backend) *PublicTxPoolAPI {
 // Content returns the transactions contained within the transaction pool.
 func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransaction {
 	content := map[string]map[string]map[string]*RPCTransaction{
-		"pending": make(map[string]map[string]*RPCTransaction),
-		"queued":  make(map[string]map[string]*RPCTransaction),
+		"pending":    make(map[string]map[string]*RPCTransaction),
+		"queued":     make(map[string]map[string]*RPCTransaction),
+		"timelocked": make(map[string]map[string]*RPCTransaction),
 	}
 	pending, queue := s.b.TxPoolContent()
 
@@ -127,6 +132,14 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 		}
 		content["queued"][account.Hex()] = dump
 	}
+	// Flatten the timelocked transactions
+	for account, txs := range s.b.TxPoolTimelocked() {
+		dump := make(map[string]*RPCTransaction)
+		for _, tx := range txs {
+			dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+		}
+		content["timelocked"][account.Hex()] = dump
+	}
 	return content
 }
 
@@ -387,7 +400,13 @@ func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs
 		log.Warn("Failed transaction send attempt", "from", args.From, "to", args.To, "value", args.Value.ToInt(), "err", err)
 		return common.Hash{}, err
 	}
-	return SubmitTransaction(ctx, s.b, signed)
+	// The fee payer of a sponsored transaction needs to be unlocked separately
+	if args.FeePayer != nil {
+		if signed, err = signFeePayer(s.am, s.b.ChainConfig(), *args.FeePayer, signed); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return submitTransaction(ctx, s.b, signed, args.Deadline)
 }
 
 // SignTransaction will create a transaction from the given arguments and
@@ -415,7 +434,7 @@ func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args SendTxArgs
 		log.Warn("Failed transaction sign attempt", "from", args.From, "to", args.To, "value", args.Value.ToInt(), "err", err)
 		return nil, err
 	}
-	data, err := rlp.EncodeToBytes(signed)
+	data, err := signed.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -800,13 +819,13 @@ func (args *CallArgs) ToMessage(globalGasCap uint64) types.Message {
 	return msg
 }
 
-// account indicates the overriding fields of account during the execution of
-// a message call.
+// OverrideAccount indicates the overriding fields of account during the execution
+// of a message call.
 // Note, state and stateDiff can't be specified at the same time. If state is
 // set, message execution will only use the data in the given state. Otherwise
 // if statDiff is set, all diff will be applied first and then execute the call
 // message.
-type account struct {
+type OverrideAccount struct {
 	Nonce     *hexutil.Uint64              `json:"nonce"`
 	Code      *hexutil.Bytes               `json:"code"`
 	Balance   **hexutil.Big                `json:"balance"`
@@ -814,15 +833,15 @@ type account struct {
 	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
 }
 
-func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides map[common.Address]account, vmCfg vm.Config, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
-	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
+// StateOverride is the collection of overridden accounts.
+type StateOverride map[common.Address]OverrideAccount
 
-	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
-	if state == nil || err != nil {
-		return nil, err
+// Apply overrides the fields of specified accounts into the given state.
+func (diff *StateOverride) Apply(state *state.StateDB) error {
+	if diff == nil {
+		return nil
 	}
-	// Override the fields of specified contracts before execution.
-	for addr, account := range overrides {
+	for addr, account := range *diff {
 		// Override account nonce.
 		if account.Nonce != nil {
 			state.SetNonce(addr, uint64(*account.Nonce))
@@ -836,7 +855,7 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 			state.SetBalance(addr, (*big.Int)(*account.Balance))
 		}
 		if account.State != nil && account.StateDiff != nil {
-			return nil, fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
 		}
 		// Replace entire state if caller requires.
 		if account.State != nil {
@@ -849,6 +868,43 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 			}
 		}
 	}
+	return nil
+}
+
+// BlockOverrides is a set of header fields to override when executing calls
+// on top of a block.
+type BlockOverrides struct {
+	Number *hexutil.Big    `json:"number"`
+	Time   *hexutil.Uint64 `json:"time"`
+}
+
+// Apply returns a copy of the header with the overridden fields replaced. The
+// original header is returned if there is nothing to override.
+func (diff *BlockOverrides) Apply(header *types.Header) *types.Header {
+	if diff == nil || (diff.Number == nil && diff.Time == nil) {
+		return header
+	}
+	header = types.CopyHeader(header)
+	if diff.Number != nil {
+		header.Number = new(big.Int).Set(diff.Number.ToInt())
+	}
+	if diff.Time != nil {
+		header.Time = uint64(*diff.Time)
+	}
+	return header
+}
+
+func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, vmCfg vm.Config, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// Override the fields of specified contracts before execution.
+	if err := overrides.Apply(state); err != nil {
+		return nil, err
+	}
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
 	var cancel context.CancelFunc
@@ -861,6 +917,13 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 	// this makes sure resources are cleaned up.
 	defer cancel()
 
+	return applyCall(ctx, b, args, state, header, timeout, globalGasCap)
+}
+
+// applyCall executes a single call on top of the given state and header. The
+// timeout is only used to report an aborted execution, the context is expected
+// to be cancelled by the caller when it expires.
+func applyCall(ctx context.Context, b Backend, args CallArgs, state *state.StateDB, header *types.Header, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
 	// Get a new instance of the EVM.
 	msg := args.ToMessage(globalGasCap)
 	evm, vmError, err := b.GetEVM(ctx, msg, state, header)
@@ -927,12 +990,8 @@ func (e *revertError) ErrorData() interface{} {
 //
 // Note, this function doesn't make and changes in the state/blockchain and is
 // useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *map[common.Address]account) (hexutil.Bytes, error) {
-	var accounts map[common.Address]account
-	if overrides != nil {
-		accounts = *overrides
-	}
-	result, err := DoCall(ctx, s.b, args, blockNrOrHash, accounts, vm.Config{}, 5*time.Second, s.b.RPCGasCap())
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride) (hexutil.Bytes, error) {
+	result, err := DoCall(ctx, s.b, args, blockNrOrHash, overrides, vm.Config{}, 5*time.Second, s.b.RPCGasCap())
 	if err != nil {
 		return nil, err
 	}
@@ -943,6 +1002,64 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOr
 	return result.Return(), result.Err
 }
 
+// CallResult is the outcome of a single call simulated by CallMany.
+type CallResult struct {
+	ReturnData   hexutil.Bytes  `json:"returnData"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Error        string         `json:"error,omitempty"`
+	RevertReason string         `json:"revertReason,omitempty"`
+}
+
+// CallMany executes the given calls in order on the state of the given block,
+// each call seeing the state changes made by the ones before it. The state and
+// the block context may be overridden before the first call is executed.
+//
+// Calls failing during execution (e.g. reverting) are reported in their result
+// and do not stop the sequence. Calls that can't be executed at all abort it.
+//
+// Note, this function doesn't make and changes in the state/blockchain and is
+// useful to simulate a sequence of transactions before sending them.
+func (s *PublicBlockChainAPI) CallMany(ctx context.Context, calls []CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides) ([]*CallResult, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM calls finished", "calls", len(calls), "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, err
+	}
+	header = blockOverrides.Apply(header)
+
+	// The whole sequence shares the timeout of a single eth_call
+	timeout := 5 * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]*CallResult, 0, len(calls))
+	for i, args := range calls {
+		result, err := applyCall(ctx, s.b, args, state, header, timeout, s.b.RPCGasCap())
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		// Finalise the changes so they are visible to the next call
+		state.Finalise(s.b.ChainConfig().IsEIP158(header.Number))
+
+		res := &CallResult{
+			ReturnData: result.ReturnData,
+			GasUsed:    hexutil.Uint64(result.UsedGas),
+		}
+		if result.Err != nil {
+			res.Error = result.Err.Error()
+			if reason, err := abi.UnpackRevert(result.Revert()); err == nil {
+				res.RevertReason = reason
+			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
 func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, gasCap uint64) (hexutil.Uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
@@ -1205,20 +1322,25 @@ func (s *PublicBlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Bloc
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash        *common.Hash    `json:"blockHash"`
-	BlockNumber      *hexutil.Big    `json:"blockNumber"`
-	From             common.Address  `json:"from"`
-	Gas              hexutil.Uint64  `json:"gas"`
-	GasPrice         *hexutil.Big    `json:"gasPrice"`
-	Hash             common.Hash     `json:"hash"`
-	Input            hexutil.Bytes   `json:"input"`
-	Nonce            hexutil.Uint64  `json:"nonce"`
-	To               *common.Address `json:"to"`
-	TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
-	Value            *hexutil.Big    `json:"value"`
-	V                *hexutil.Big    `json:"v"`
-	R                *hexutil.Big    `json:"r"`
-	S                *hexutil.Big    `json:"s"`
+	BlockHash        *common.Hash      `json:"blockHash"`
+	BlockNumber      *hexutil.Big      `json:"blockNumber"`
+	From             common.Address    `json:"from"`
+	Gas              hexutil.Uint64    `json:"gas"`
+	GasPrice         *hexutil.Big      `json:"gasPrice"`
+	Hash             common.Hash       `json:"hash"`
+	Input            hexutil.Bytes     `json:"input"`
+	Nonce            hexutil.Uint64    `json:"nonce"`
+	To               *common.Address   `json:"to"`
+	TransactionIndex *hexutil.Uint64   `json:"transactionIndex"`
+	Value            *hexutil.Big      `json:"value"`
+	V                *hexutil.Big      `json:"v"`
+	R                *hexutil.Big      `json:"r"`
+	S                *hexutil.Big      `json:"s"`
+	Type             hexutil.Uint64    `json:"type"`
+	Accesses         *types.AccessList `json:"accessList,omitempty"`
+	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
+	FeePayer         *common.Address   `json:"feePayer,omitempty"`
+	GroupAuth        *types.GroupAuth  `json:"groupAuth,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1226,7 +1348,7 @@ type RPCTransaction struct {
 func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber uint64, index uint64) *RPCTransaction {
 	var signer types.Signer = types.FrontierSigner{}
 	if tx.Protected() {
-		signer = types.NewEIP155Signer(tx.ChainId())
+		signer = types.LatestSignerForChainID(tx.ChainId())
 	}
 	from, _ := types.Sender(signer, tx)
 	v, r, s := tx.RawSignatureValues()
@@ -1243,7 +1365,21 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		V:        (*hexutil.Big)(v),
 		R:        (*hexutil.Big)(r),
 		S:        (*hexutil.Big)(s),
+		Type:     hexutil.Uint64(tx.Type()),
+	}
+	if tx.Type() != types.LegacyTxType {
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
 	}
+	if tx.Type() == types.AccessListTxType {
+		al := tx.AccessList()
+		result.Accesses = &al
+	}
+	if tx.Sponsored() {
+		payer, _ := types.FeePayer(signer, tx)
+		result.FeePayer = &payer
+	}
+	// Expose the group signature of anonymous transactions for the managers to open
+	result.GroupAuth = tx.GroupAuth()
 	if blockHash != (common.Hash{}) {
 		result.BlockHash = &blockHash
 		result.BlockNumber = (*hexutil.Big)(new(big.Int).SetUint64(blockNumber))
@@ -1272,7 +1408,7 @@ func newRPCRawTransactionFromBlockIndex(b *types.Block, index uint64) hexutil.By
 	if index >= uint64(len(txs)) {
 		return nil
 	}
-	blob, _ := rlp.EncodeToBytes(txs[index])
+	blob, _ := txs[index].MarshalBinary()
 	return blob
 }
 
@@ -1398,8 +1534,8 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByHash(ctx context.Context,
 			return nil, nil
 		}
 	}
-	// Serialize to RLP and return
-	return rlp.EncodeToBytes(tx)
+	// Serialize to the canonical encoding and return
+	return tx.MarshalBinary()
 }
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
@@ -1419,7 +1555,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 
 	var signer types.Signer = types.FrontierSigner{}
 	if tx.Protected() {
-		signer = types.NewEIP155Signer(tx.ChainId())
+		signer = types.LatestSignerForChainID(tx.ChainId())
 	}
 	from, _ := types.Sender(signer, tx)
 
@@ -1435,6 +1571,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 		"contractAddress":   nil,
 		"logs":              receipt.Logs,
 		"logsBloom":         receipt.Bloom,
+		"type":              hexutil.Uint(tx.Type()),
 	}
 
 	// Assign receipt status or post state.
@@ -1450,9 +1587,83 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	// Sponsored transactions report the account that paid for the gas
+	if tx.Sponsored() {
+		fields["feePayer"], _ = types.FeePayer(signer, tx)
+	}
+	// Nodes keeping extended receipts also report the outcome of the topmost call
+	extended, err := s.b.GetExtendedReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(extended) > int(index) {
+		ext := extended[index]
+		fields["returnData"] = hexutil.Bytes(ext.ReturnData)
+		fields["callGasUsed"] = hexutil.Uint64(ext.CallGas)
+		if ext.Err != "" {
+			fields["error"] = ext.Err
+		}
+		if ext.Err == vm.ErrExecutionReverted.Error() {
+			if reason, err := abi.UnpackRevert(ext.ReturnData); err == nil {
+				fields["revertReason"] = reason
+			}
+		}
+	}
 	return fields, nil
 }
 
+// GetReceiptProof returns the merkle proof of the receipt of a transaction being
+// included in the receipt trie of its block, along with the RLP encoded header of
+// the block to check it against and the consensus encoding of the receipt.
+func (s *PublicTransactionPoolAPI) GetReceiptProof(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	tx, blockHash, blockNumber, index, err := s.b.GetTransaction(ctx, hash)
+	if err != nil || tx == nil {
+		return nil, nil
+	}
+	header, err := s.b.HeaderByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := s.b.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) <= int(index) {
+		return nil, nil
+	}
+	// Rebuild the receipt trie of the block to prove the receipt with
+	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	if types.DeriveSha(receipts, tr) != header.ReceiptHash {
+		return nil, errors.New("receipts don't match block receipt root")
+	}
+	var nodes light.NodeList
+	key, _ := rlp.EncodeToBytes(uint(index))
+	if err := tr.Prove(key, 0, &nodes); err != nil {
+		return nil, err
+	}
+	proof := make([]hexutil.Bytes, len(nodes))
+	for i, node := range nodes {
+		proof[i] = hexutil.Bytes(node)
+	}
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := receipts[index].MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"blockHash":        blockHash,
+		"blockNumber":      hexutil.Uint64(blockNumber),
+		"transactionHash":  hash,
+		"transactionIndex": hexutil.Uint64(index),
+		"header":           hexutil.Bytes(enc),
+		"receipt":          hexutil.Bytes(receipt),
+		"proof":            proof,
+	}, nil
+}
+
 // sign is a helper function that signs a transaction with the private key of the given address.
 func (s *PublicTransactionPoolAPI) sign(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
 	// Look up the wallet containing the requested signer
@@ -1466,6 +1677,25 @@ func (s *PublicTransactionPoolAPI) sign(addr common.Address, tx *types.Transacti
 	return wallet.SignTx(account, tx, s.b.ChainConfig().ChainID)
 }
 
+// signFeePayer is a helper function that signs a sponsored transaction as its
+// fee payer, with the key of the given address.
+func signFeePayer(am *accounts.Manager, config *params.ChainConfig, payer common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	// Look up the wallet containing the requested fee payer
+	account := accounts.Account{Address: payer}
+
+	wallet, err := am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	// Request the wallet to sign the fee payer data
+	signer := types.LatestSignerForChainID(config.ChainID)
+	sig, err := wallet.SignData(account, accounts.MimetypeFeePayer, types.FeePayerSigningData(signer, tx))
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithFeePayerSignature(signer, sig)
+}
+
 // SendTxArgs represents the arguments to sumbit a new transaction into the transaction pool.
 type SendTxArgs struct {
 	From     common.Address  `json:"from"`
@@ -1478,6 +1708,18 @@ type SendTxArgs struct {
 	// newer name and should be preferred by clients.
 	Data  *hexutil.Bytes `json:"data"`
 	Input *hexutil.Bytes `json:"input"`
+
+	// Deadline is an optional number of the last block the transaction must be
+	// included in. It is a local pool hint and is not part of the signed data.
+	Deadline *hexutil.Uint64 `json:"deadline"`
+
+	// FeePayer is the optional account paying for the gas of the transaction,
+	// turning it into a sponsored one.
+	FeePayer *common.Address `json:"feePayer"`
+
+	// AccessList is the optional EIP-2930 access list, turning the transaction
+	// into an access list one.
+	AccessList *types.AccessList `json:"accessList"`
 }
 
 // setDefaults is a helper function that fills in default values for unspecified tx fields.
@@ -1502,6 +1744,9 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 	if args.Data != nil && args.Input != nil && !bytes.Equal(*args.Data, *args.Input) {
 		return errors.New(`both "data" and "input" are set and not equal. Please use "input" to pass transaction call data`)
 	}
+	if args.AccessList != nil && args.FeePayer != nil {
+		return errors.New("access list transactions cannot be sponsored")
+	}
 	if args.To == nil {
 		// Contract creation
 		var input []byte
@@ -1529,6 +1774,10 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 			Value:    args.Value,
 			Data:     input,
 		}
+		if args.FeePayer != nil {
+			// The sender doesn't pay for the gas, don't cap it by its balance
+			callArgs.GasPrice = nil
+		}
 		pendingBlockNr := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
 		estimated, err := DoEstimateGas(ctx, b, callArgs, pendingBlockNr, b.RPCGasCap())
 		if err != nil {
@@ -1547,6 +1796,23 @@ func (args *SendTxArgs) toTransaction() *types.Transaction {
 	} else if args.Data != nil {
 		input = *args.Data
 	}
+	if args.FeePayer != nil {
+		if args.To == nil {
+			return types.NewSponsoredContractCreation(uint64(*args.Nonce), (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
+		}
+		return types.NewSponsoredTransaction(uint64(*args.Nonce), *args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
+	}
+	if args.AccessList != nil {
+		return types.NewTx(&types.AccessListTx{
+			To:         args.To,
+			Nonce:      uint64(*args.Nonce),
+			Gas:        uint64(*args.Gas),
+			GasPrice:   (*big.Int)(args.GasPrice),
+			Value:      (*big.Int)(args.Value),
+			Data:       input,
+			AccessList: *args.AccessList,
+		})
+	}
 	if args.To == nil {
 		return types.NewContractCreation(uint64(*args.Nonce), (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
 	}
@@ -1555,13 +1821,22 @@ func (args *SendTxArgs) toTransaction() *types.Transaction {
 
 // SubmitTransaction is a helper function that submits tx to txPool and logs a message.
 func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
+	return submitTransaction(ctx, b, tx, nil)
+}
+
+// submitTransaction is a helper function that submits tx to txPool, tagging it
+// with an inclusion deadline if one was requested.
+func submitTransaction(ctx context.Context, b Backend, tx *types.Transaction, deadline *hexutil.Uint64) (common.Hash, error) {
 	// If the transaction fee cap is already specified, ensure the
 	// fee of the given transaction is _reasonable_.
 	if err := checkTxFee(tx.GasPrice(), tx.Gas(), b.RPCTxFeeCap()); err != nil {
 		return common.Hash{}, err
 	}
-
-	if err := b.SendTx(ctx, tx); err != nil {
+	if deadline != nil {
+		if err := b.SendTxWithDeadline(ctx, tx, uint64(*deadline)); err != nil {
+			return common.Hash{}, err
+		}
+	} else if err := b.SendTx(ctx, tx); err != nil {
 		return common.Hash{}, err
 	}
 	if tx.To() == nil {
@@ -1608,7 +1883,12 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 	if err != nil {
 		return common.Hash{}, err
 	}
-	return SubmitTransaction(ctx, s.b, signed)
+	if args.FeePayer != nil {
+		if signed, err = signFeePayer(s.b.AccountManager(), s.b.ChainConfig(), *args.FeePayer, signed); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return submitTransaction(ctx, s.b, signed, args.Deadline)
 }
 
 // FillTransaction fills the defaults (nonce, gas, gasPrice) on a given unsigned transaction,
@@ -1620,7 +1900,7 @@ func (s *PublicTransactionPoolAPI) FillTransaction(ctx context.Context, args Sen
 	}
 	// Assemble the transaction and obtain rlp
 	tx := args.toTransaction()
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -1631,12 +1911,163 @@ func (s *PublicTransactionPoolAPI) FillTransaction(ctx context.Context, args Sen
 // The sender is responsible for signing the transaction and using the correct nonce.
 func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
 	tx := new(types.Transaction)
-	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
 		return common.Hash{}, err
 	}
 	return SubmitTransaction(ctx, s.b, tx)
 }
 
+// SendRawTransactionWithDeadline will add the signed transaction to the transaction
+// pool, requiring it to be included at the latest in the block with the given
+// number. The transaction is prioritised by the miner and dropped once the
+// deadline passes, which is reported through the expiredTransactions subscription.
+func (s *PublicTransactionPoolAPI) SendRawTransactionWithDeadline(ctx context.Context, encodedTx hexutil.Bytes, deadline hexutil.Uint64) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
+		return common.Hash{}, err
+	}
+	return submitTransaction(ctx, s.b, tx, &deadline)
+}
+
+// TxConditionArgs represents the release condition of a timelocked transaction.
+type TxConditionArgs struct {
+	Block *hexutil.Uint64 `json:"block"` // First block the transaction may be included in
+	Time  *hexutil.Uint64 `json:"time"`  // Timestamp the chain head must reach before inclusion
+}
+
+// SendRawTransactionConditional will hold back the signed transaction until the
+// given condition holds, and only then add it to the transaction pool. Until it
+// is released, the transaction is not propagated and can be cancelled by its
+// sender with eth_cancelTimelockedTransaction.
+func (s *PublicTransactionPoolAPI) SendRawTransactionConditional(ctx context.Context, encodedTx hexutil.Bytes, args TxConditionArgs) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
+		return common.Hash{}, err
+	}
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+		return common.Hash{}, err
+	}
+	var cond core.TxCondition
+	if args.Block != nil {
+		cond.Block = uint64(*args.Block)
+	}
+	if args.Time != nil {
+		cond.Time = uint64(*args.Time)
+	}
+	if err := s.b.SendTxWithCondition(ctx, tx, cond); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted timelocked transaction", "fullhash", tx.Hash().Hex(), "block", cond.Block, "time", cond.Time)
+	return tx.Hash(), nil
+}
+
+// CancelTimelockedTransaction drops a timelocked transaction that has not been
+// released into the transaction pool yet, reporting whether it was found. Only
+// the sender can cancel a transaction: sig is its signature of the transaction
+// hash, as eth_sign makes it.
+func (s *PublicTransactionPoolAPI) CancelTimelockedTransaction(hash common.Hash, sig hexutil.Bytes) (bool, error) {
+	var (
+		sender common.Address
+		found  bool
+	)
+	for account, txs := range s.b.TxPoolTimelocked() {
+		for _, tx := range txs {
+			if tx.Hash() == hash {
+				sender, found = account, true
+			}
+		}
+	}
+	if !found {
+		return false, nil
+	}
+	if len(sig) != crypto.SignatureLength {
+		return false, fmt.Errorf("signature must be %d bytes long", crypto.SignatureLength)
+	}
+	if sig[crypto.RecoveryIDOffset] != 27 && sig[crypto.RecoveryIDOffset] != 28 {
+		return false, fmt.Errorf("invalid Ethereum signature (V is not 27 or 28)")
+	}
+	rsig := common.CopyBytes(sig)
+	rsig[crypto.RecoveryIDOffset] -= 27 // Transform yellow paper V from 27/28 to 0/1
+
+	rpk, err := crypto.SigToPub(accounts.TextHash(hash.Bytes()), rsig)
+	if err != nil {
+		return false, err
+	}
+	if crypto.PubkeyToAddress(*rpk) != sender {
+		return false, errors.New("cancellation not signed by the transaction sender")
+	}
+	return s.b.CancelTimelockedTx(hash), nil
+}
+
+// SendBundleArgs represents the arguments to submit an atomic transaction bundle.
+type SendBundleArgs struct {
+	Txs               []hexutil.Bytes `json:"txs"`               // RLP encoded signed transactions, in order
+	MinBlock          *hexutil.Uint64 `json:"minBlock"`          // First block to include the bundle in, defaults to the next one
+	MaxBlock          hexutil.Uint64  `json:"maxBlock"`          // Last block to include the bundle in
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"` // Transactions allowed to revert
+}
+
+// SendBundle submits an ordered list of signed transactions to be included into
+// a block within the given range, atomically. If any transaction fails, or
+// reverts without being listed in revertingTxHashes, the bundle is discarded.
+// It returns the hash identifying the bundle.
+func (s *PublicTransactionPoolAPI) SendBundle(ctx context.Context, args SendBundleArgs) (common.Hash, error) {
+	bundle := &core.Bundle{
+		Txs:       make(types.Transactions, len(args.Txs)),
+		MinBlock:  s.b.CurrentBlock().NumberU64() + 1,
+		MaxBlock:  uint64(args.MaxBlock),
+		Reverting: args.RevertingTxHashes,
+	}
+	if args.MinBlock != nil {
+		bundle.MinBlock = uint64(*args.MinBlock)
+	}
+	for i, encoded := range args.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(encoded); err != nil {
+			return common.Hash{}, fmt.Errorf("transaction %d: %v", i, err)
+		}
+		if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+			return common.Hash{}, fmt.Errorf("transaction %d: %v", i, err)
+		}
+		bundle.Txs[i] = tx
+	}
+	if err := s.b.SendBundle(ctx, bundle); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted transaction bundle", "hash", bundle.Hash(), "txs", len(bundle.Txs), "min", bundle.MinBlock, "max", bundle.MaxBlock)
+	return bundle.Hash(), nil
+}
+
+// ExpiredTransactions creates a subscription that is triggered each time local
+// transactions are dropped from the pool because their inclusion deadline passed.
+func (s *PublicTransactionPoolAPI) ExpiredTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		expired := make(chan core.DeadlineExpiredEvent, 16)
+		sub := s.b.SubscribeDeadlineExpiredEvent(expired)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-expired:
+				for _, tx := range ev.Txs {
+					notifier.Notify(rpcSub.ID, tx.Hash())
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
 // Sign calculates an ECDSA signature for:
 // keccack256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
@@ -1692,7 +2123,33 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Sen
 	if err != nil {
 		return nil, err
 	}
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &SignTransactionResult{data, tx}, nil
+}
+
+// SignTransactionAsFeePayer signs a sponsored transaction, already signed by its
+// sender, as the fee payer. The node needs to have the private key of the fee
+// payer and it needs to be unlocked.
+func (s *PublicTransactionPoolAPI) SignTransactionAsFeePayer(ctx context.Context, encodedTx hexutil.Bytes, payer common.Address) (*SignTransactionResult, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
+		return nil, err
+	}
+	if !tx.Sponsored() {
+		return nil, types.ErrNotSponsored
+	}
+	// Before actually sign the transaction, ensure the transaction fee is reasonable.
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+		return nil, err
+	}
+	tx, err := signFeePayer(s.b.AccountManager(), s.b.ChainConfig(), payer, tx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -1716,7 +2173,7 @@ func (s *PublicTransactionPoolAPI) PendingTransactions() ([]*RPCTransaction, err
 	for _, tx := range pending {
 		var signer types.Signer = types.HomesteadSigner{}
 		if tx.Protected() {
-			signer = types.NewEIP155Signer(tx.ChainId())
+			signer = types.LatestSignerForChainID(tx.ChainId())
 		}
 		from, _ := types.Sender(signer, tx)
 		if _, exists := accounts[from]; exists {
@@ -1757,7 +2214,7 @@ func (s *PublicTransactionPoolAPI) Resend(ctx context.Context, sendArgs SendTxAr
 	for _, p := range pending {
 		var signer types.Signer = types.HomesteadSigner{}
 		if p.Protected() {
-			signer = types.NewEIP155Signer(p.ChainId())
+			signer = types.LatestSignerForChainID(p.ChainId())
 		}
 		wantSigHash := signer.Hash(matchTx)
 
@@ -1954,3 +2411,41 @@ func toHexSlice(b [][]byte) []string {
 	}
 	return r
 }
+
+// PrivateTxCommitAPI provides the commit-reveal transaction submission path of
+// the local miner. Commitments are anonymous and take up miner resources without
+// costing anything, so the API is not considered safe for public use.
+type PrivateTxCommitAPI struct {
+	b Backend
+}
+
+// NewPrivateTxCommitAPI creates a new commit-reveal transaction API.
+func NewPrivateTxCommitAPI(b Backend) *PrivateTxCommitAPI {
+	return &PrivateTxCommitAPI{b}
+}
+
+// CommitTransaction registers a commitment to a signed transaction, its hash, to
+// be revealed later with miner_revealTransaction. The reveal is only accepted once
+// the commitment was ordered into a block, and the transaction has to be included
+// within the configured reveal window.
+func (s *PrivateTxCommitAPI) CommitTransaction(ctx context.Context, hash common.Hash) error {
+	return s.b.CommitTx(ctx, hash)
+}
+
+// RevealTransaction discloses a previously committed signed transaction. It is
+// not propagated to the network, but included by the local miner ahead of the
+// public transactions, in the order of the commitments.
+func (s *PrivateTxCommitAPI) RevealTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
+		return common.Hash{}, err
+	}
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+		return common.Hash{}, err
+	}
+	if err := s.b.RevealTx(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Revealed committed transaction", "fullhash", tx.Hash().Hex())
+	return tx.Hash(), nil
+}
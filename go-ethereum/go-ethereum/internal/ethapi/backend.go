@@ -62,21 +62,31 @@ type Backend interface {
 	StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error)
 	StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error)
 	GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error)
+	GetExtendedReceipts(ctx context.Context, hash common.Hash) (types.ExtendedReceipts, error)
 	GetTd(ctx context.Context, hash common.Hash) *big.Int
 	GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header) (*vm.EVM, func() error, error)
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
 	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
 	SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription
+	SubscribeStateDiffEvent(ch chan<- core.StateDiffEvent) event.Subscription
 
 	// Transaction pool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
+	SendTxWithDeadline(ctx context.Context, signedTx *types.Transaction, deadline uint64) error
+	SendBundle(ctx context.Context, bundle *core.Bundle) error
+	SendTxWithCondition(ctx context.Context, signedTx *types.Transaction, cond core.TxCondition) error
+	CancelTimelockedTx(hash common.Hash) bool
+	CommitTx(ctx context.Context, hash common.Hash) error
+	RevealTx(ctx context.Context, signedTx *types.Transaction) error
 	GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error)
 	GetPoolTransactions() (types.Transactions, error)
 	GetPoolTransaction(txHash common.Hash) *types.Transaction
 	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
+	TxPoolTimelocked() map[common.Address]types.Transactions
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
+	SubscribeDeadlineExpiredEvent(chan<- core.DeadlineExpiredEvent) event.Subscription
 
 	// Filter API
 	BloomStatus() (uint64, uint64)
@@ -132,6 +142,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateAccountAPI(apiBackend, nonceLock),
 			Public:    false,
+		}, {
+			Namespace: "miner",
+			Version:   "1.0",
+			Service:   NewPrivateTxCommitAPI(apiBackend),
+			Public:    false,
 		},
 	}
 }
@@ -25,6 +25,7 @@ var Modules = map[string]string{
 	"ethash":     EthashJs,
 	"debug":      DebugJs,
 	"eth":        EthJs,
+	"evm":        EvmJs,
 	"miner":      MinerJs,
 	"net":        NetJs,
 	"personal":   PersonalJs,
@@ -34,6 +35,10 @@ var Modules = map[string]string{
 	"txpool":     TxpoolJs,
 	"les":        LESJs,
 	"lespay":     LESPayJs,
+	"channel":    ChannelJs,
+	"watchtower": WatchtowerJs,
+	"swap":       SwapJs,
+	"xcall":      XCallJs,
 }
 
 const ChequebookJs = `
@@ -108,6 +113,12 @@ web3._extend({
 			call: 'clique_status',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'liveness',
+			call: 'clique_liveness',
+			params: 1,
+			inputFormatter: [null]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -437,6 +448,12 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceCallMany',
+			call: 'debug_traceCallMany',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -459,6 +476,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null],
 		}),
+		new web3._extend.Method({
+			name: 'stateDiff',
+			call: 'debug_stateDiff',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter],
+		}),
 		new web3._extend.Method({
 			name: 'getModifiedAccountsByHash',
 			call: 'debug_getModifiedAccountsByHash',
@@ -496,12 +519,39 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
 		}),
+		new web3._extend.Method({
+			name: 'sendRawTransactionConditional',
+			call: 'eth_sendRawTransactionConditional',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'cancelTimelockedTransaction',
+			call: 'eth_cancelTimelockedTransaction',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'sendBundle',
+			call: 'eth_sendBundle',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'sendRawTransactionWithDeadline',
+			call: 'eth_sendRawTransactionWithDeadline',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'signTransaction',
 			call: 'eth_signTransaction',
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'signTransactionAsFeePayer',
+			call: 'eth_signTransactionAsFeePayer',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputAddressFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'estimateGas',
 			call: 'eth_estimateGas',
@@ -509,6 +559,12 @@ web3._extend({
 			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputBlockNumberFormatter],
 			outputFormatter: web3._extend.utils.toDecimal
 		}),
+		new web3._extend.Method({
+			name: 'callMany',
+			call: 'eth_callMany',
+			params: 4,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'submitTransaction',
 			call: 'eth_submitTransaction',
@@ -563,6 +619,11 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getReceiptProof',
+			call: 'eth_getReceiptProof',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -621,6 +682,16 @@ web3._extend({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
 		}),
+		new web3._extend.Method({
+			name: 'commitTransaction',
+			call: 'miner_commitTransaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'revealTransaction',
+			call: 'miner_revealTransaction',
+			params: 1
+		}),
 	],
 	properties: []
 });
@@ -907,3 +978,218 @@ web3._extend({
 	]
 });
 `
+
+const EvmJs = `
+web3._extend({
+	property: 'evm',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'increaseTime',
+			call: 'evm_increaseTime',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'mine',
+			call: 'evm_mine',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'snapshot',
+			call: 'evm_snapshot',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'revert',
+			call: 'evm_revert',
+			params: 1
+		}),
+	]
+});
+`
+
+const ChannelJs = `
+web3._extend({
+	property: 'channel',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'open',
+			call: 'channel_open',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'deposit',
+			call: 'channel_deposit',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'pay',
+			call: 'channel_pay',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'receive',
+			call: 'channel_receive',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'reveal',
+			call: 'channel_reveal',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'close',
+			call: 'channel_close',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'invoice',
+			call: 'channel_invoice',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'routes',
+			call: 'channel_routes',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.utils.fromDecimal, null]
+		}),
+		new web3._extend.Method({
+			name: 'send',
+			call: 'channel_send',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'transfer',
+			call: 'channel_transfer',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'openVirtual',
+			call: 'channel_openVirtual',
+			params: 5,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'payVirtual',
+			call: 'channel_payVirtual',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'closeVirtual',
+			call: 'channel_closeVirtual',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getVirtual',
+			call: 'channel_virtual',
+			params: 1
+		}),
+	],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'channels',
+			getter: 'channel_list'
+		}),
+		new web3._extend.Property({
+			name: 'transfers',
+			getter: 'channel_transfers'
+		}),
+		new web3._extend.Property({
+			name: 'virtuals',
+			getter: 'channel_virtuals'
+		}),
+	]
+});
+`
+
+const WatchtowerJs = `
+web3._extend({
+	property: 'watchtower',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'add',
+			call: 'watchtower_add',
+			params: 3
+		}),
+	],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'status',
+			getter: 'watchtower_status'
+		}),
+		new web3._extend.Property({
+			name: 'responses',
+			getter: 'watchtower_responses'
+		}),
+	]
+});
+`
+
+const SwapJs = `
+web3._extend({
+	property: 'swap',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'initiate',
+			call: 'swap_initiate',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'participate',
+			call: 'swap_participate',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'status',
+			call: 'swap_status',
+			params: 1
+		}),
+	],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'list',
+			getter: 'swap_list'
+		}),
+	]
+});
+`
+
+const XCallJs = `
+web3._extend({
+	property: 'xcall',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'begin',
+			call: 'xcall_begin',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'status',
+			call: 'xcall_status',
+			params: 1
+		}),
+	],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'list',
+			getter: 'xcall_list'
+		}),
+	]
+});
+`
@@ -358,5 +358,5 @@ func (api *PrivateLightAPI) GetCheckpointContractAddress() (string, error) {
 	if api.backend.oracle == nil {
 		return "", errNotActivated
 	}
-	return api.backend.oracle.Contract().ContractAddr().Hex(), nil
+	return api.backend.oracle.Address().Hex(), nil
 }
@@ -156,6 +156,10 @@ func (b *LesApiBackend) GetReceipts(ctx context.Context, hash common.Hash) (type
 	return nil, nil
 }
 
+func (b *LesApiBackend) GetExtendedReceipts(ctx context.Context, hash common.Hash) (types.ExtendedReceipts, error) {
+	return nil, nil // Extended receipts are local to the executing node
+}
+
 func (b *LesApiBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
 	if number := rawdb.ReadHeaderNumber(b.eth.chainDb, hash); number != nil {
 		return light.GetBlockLogs(ctx, b.eth.odr, hash, *number)
@@ -180,6 +184,30 @@ func (b *LesApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.Add(ctx, signedTx)
 }
 
+func (b *LesApiBackend) SendTxWithDeadline(ctx context.Context, signedTx *types.Transaction, deadline uint64) error {
+	return errors.New("inclusion deadlines are not supported by light clients")
+}
+
+func (b *LesApiBackend) SendTxWithCondition(ctx context.Context, signedTx *types.Transaction, cond core.TxCondition) error {
+	return errors.New("timelocked transactions are not supported by light clients")
+}
+
+func (b *LesApiBackend) CancelTimelockedTx(hash common.Hash) bool {
+	return false
+}
+
+func (b *LesApiBackend) CommitTx(ctx context.Context, hash common.Hash) error {
+	return errors.New("commit-reveal is not supported by light clients")
+}
+
+func (b *LesApiBackend) RevealTx(ctx context.Context, signedTx *types.Transaction) error {
+	return errors.New("commit-reveal is not supported by light clients")
+}
+
+func (b *LesApiBackend) SendBundle(ctx context.Context, bundle *core.Bundle) error {
+	return errors.New("transaction bundles are not supported by light clients")
+}
+
 func (b *LesApiBackend) RemoveTx(txHash common.Hash) {
 	b.eth.txPool.RemoveTx(txHash)
 }
@@ -208,10 +236,22 @@ func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.eth.txPool.Content()
 }
 
+func (b *LesApiBackend) TxPoolTimelocked() map[common.Address]types.Transactions {
+	return nil
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
 
+func (b *LesApiBackend) SubscribeDeadlineExpiredEvent(ch chan<- core.DeadlineExpiredEvent) event.Subscription {
+	// Light clients never accept transactions with deadlines, nothing to expire
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
 func (b *LesApiBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.eth.blockchain.SubscribeChainEvent(ch)
 }
@@ -235,6 +275,13 @@ func (b *LesApiBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.
 	})
 }
 
+func (b *LesApiBackend) SubscribeStateDiffEvent(ch chan<- core.StateDiffEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
 func (b *LesApiBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.eth.blockchain.SubscribeRemovedLogsEvent(ch)
 }
@@ -21,6 +21,7 @@ package checkpointoracle
 
 import (
 	"encoding/binary"
+	"math/big"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -28,17 +29,22 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/contracts/checkpointoracle"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 )
 
+// verifyGroupSignature checks a TIBGS group signature, replaceable by tests.
+var verifyGroupSignature = vm.VerifyGroupSignature
+
 // CheckpointOracle is responsible for offering the latest stable checkpoint
 // generated and announced by the contract admins on-chain. The checkpoint can
 // be verified by clients locally during the checkpoint syncing.
 type CheckpointOracle struct {
 	config   *params.CheckpointOracleConfig
 	contract *checkpointoracle.CheckpointOracle
+	group    *checkpointoracle.GroupCheckpointOracle // Contract of group signed checkpoints, if so configured
 
 	running  int32                                 // Flag whether the contract backend is set or not
 	getLocal func(uint64) params.TrustedCheckpoint // Function used to retrieve local checkpoint
@@ -60,7 +66,16 @@ func New(config *params.CheckpointOracleConfig, getLocal func(uint64) params.Tru
 // Start binds the contract backend, initializes the oracle instance
 // and marks the status as available.
 func (oracle *CheckpointOracle) Start(backend bind.ContractBackend) {
-	contract, err := checkpointoracle.NewCheckpointOracle(oracle.config.Address, backend)
+	var (
+		contract *checkpointoracle.CheckpointOracle
+		group    *checkpointoracle.GroupCheckpointOracle
+		err      error
+	)
+	if oracle.GroupSigned() {
+		group, err = checkpointoracle.NewGroupCheckpointOracle(oracle.config.Address, backend)
+	} else {
+		contract, err = checkpointoracle.NewCheckpointOracle(oracle.config.Address, backend)
+	}
 	if err != nil {
 		log.Error("Oracle contract binding failed", "err", err)
 		return
@@ -69,7 +84,7 @@ func (oracle *CheckpointOracle) Start(backend bind.ContractBackend) {
 		log.Error("Already bound and listening to registrar")
 		return
 	}
-	oracle.contract = contract
+	oracle.contract, oracle.group = contract, group
 }
 
 // IsRunning returns an indicator whether the oracle is running.
@@ -77,11 +92,37 @@ func (oracle *CheckpointOracle) IsRunning() bool {
 	return atomic.LoadInt32(&oracle.running) == 1
 }
 
-// Contract returns the underlying raw checkpoint oracle contract.
+// Address returns the address of the checkpoint oracle contract.
+func (oracle *CheckpointOracle) Address() common.Address {
+	return oracle.config.Address
+}
+
+// GroupSigned returns whether the checkpoints are approved by a group signature
+// of a committee instead of by a set of signers.
+func (oracle *CheckpointOracle) GroupSigned() bool {
+	return len(oracle.config.GroupKey) > 0
+}
+
+// Contract returns the underlying raw checkpoint oracle contract, nil if the
+// checkpoints are group signed.
 func (oracle *CheckpointOracle) Contract() *checkpointoracle.CheckpointOracle {
 	return oracle.contract
 }
 
+// GroupContract returns the underlying raw group checkpoint oracle contract, nil
+// if the checkpoints are approved by a set of signers.
+func (oracle *CheckpointOracle) GroupContract() *checkpointoracle.GroupCheckpointOracle {
+	return oracle.group
+}
+
+// latestCheckpoint retrieves the latest checkpoint registered in the contract.
+func (oracle *CheckpointOracle) latestCheckpoint() (uint64, [32]byte, *big.Int, error) {
+	if oracle.group != nil {
+		return oracle.group.Contract().GetLatestCheckpoint(nil)
+	}
+	return oracle.contract.Contract().GetLatestCheckpoint(nil)
+}
+
 // StableCheckpoint returns the stable checkpoint which was generated by local
 // indexers and announced by trusted signers.
 func (oracle *CheckpointOracle) StableCheckpoint() (*params.TrustedCheckpoint, uint64) {
@@ -92,7 +133,7 @@ func (oracle *CheckpointOracle) StableCheckpoint() (*params.TrustedCheckpoint, u
 	}
 	// Look it up properly
 	// Retrieve the latest checkpoint from the contract, abort if empty
-	latest, hash, height, err := oracle.contract.Contract().GetLatestCheckpoint(nil)
+	latest, hash, height, err := oracle.latestCheckpoint()
 	oracle.lastCheckTime = time.Now()
 	if err != nil || (latest == 0 && hash == [32]byte{}) {
 		oracle.lastCheckPointHeight = 0
@@ -168,3 +209,19 @@ func (oracle *CheckpointOracle) VerifySigners(index uint64, hash [32]byte, signa
 	}
 	return true, signers
 }
+
+// VerifyGroupSignature checks whether the packed group signature announced by
+// the contract for a checkpoint was issued by the committee.
+func (oracle *CheckpointOracle) VerifyGroupSignature(index uint64, hash [32]byte, signature []byte) bool {
+	sig, err := vm.UnpackGroupSignature(signature)
+	if err != nil {
+		return false
+	}
+	message := checkpointoracle.GroupMessage(oracle.config.Address, index, hash)
+	valid, err := verifyGroupSignature(oracle.config.GroupKey, sig, message, vm.PrecompiledGroupID)
+	if err != nil || !valid {
+		log.Warn("Invalid group signature of checkpoint", "index", index, "err", err)
+		return false
+	}
+	return true
+}
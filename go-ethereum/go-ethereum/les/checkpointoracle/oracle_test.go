@@ -0,0 +1,79 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package checkpointoracle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/checkpointoracle"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that group signatures announced by the contract are verified against
+// the configured committee key, for the message of the announced checkpoint.
+func TestVerifyGroupSignature(t *testing.T) {
+	raw := make([][]byte, 10)
+	for i := range raw {
+		size := 128
+		if i >= 6 {
+			size = 20
+		}
+		raw[i] = bytes.Repeat([]byte{byte(i)}, size)
+	}
+	sig, _ := rlp.EncodeToBytes(raw)
+	packed, err := vm.PackGroupSignature(sig)
+	if err != nil {
+		t.Fatalf("failed to pack signature: %v", err)
+	}
+	var (
+		key    = []byte{0x01, 0x02, 0x03}
+		addr   = common.HexToAddress("0x0102030405060708091011121314151617181920")
+		hash   = common.HexToHash("deadbeef")
+		config = &params.CheckpointOracleConfig{Address: addr, GroupKey: key}
+	)
+	defer func(orig func([]byte, []byte, string, string) (bool, error)) {
+		verifyGroupSignature = orig
+	}(verifyGroupSignature)
+
+	verifyGroupSignature = func(k, s []byte, message string, grpID string) (bool, error) {
+		return bytes.Equal(k, key) && bytes.Equal(s, sig) && grpID == vm.PrecompiledGroupID &&
+			message == checkpointoracle.GroupMessage(addr, 1, hash), nil
+	}
+	oracle := New(config, nil)
+	if !oracle.GroupSigned() {
+		t.Fatalf("oracle with a committee key not group signed")
+	}
+	if !oracle.VerifyGroupSignature(1, hash, packed) {
+		t.Errorf("valid group signature refused")
+	}
+	if oracle.VerifyGroupSignature(2, hash, packed) {
+		t.Errorf("group signature of another section accepted")
+	}
+	if oracle.VerifyGroupSignature(1, common.Hash{}, packed) {
+		t.Errorf("group signature of another checkpoint accepted")
+	}
+	if oracle.VerifyGroupSignature(1, hash, packed[1:]) {
+		t.Errorf("malformed group signature accepted")
+	}
+	if New(&params.CheckpointOracleConfig{Address: addr}, nil).GroupSigned() {
+		t.Errorf("oracle without a committee key group signed")
+	}
+}
@@ -160,7 +160,7 @@ func (c *lesCommons) setupOracle(node *node.Node, genesis common.Hash, ethconfig
 		log.Info("Checkpoint registrar is not enabled")
 		return nil
 	}
-	if config.Address == (common.Address{}) || uint64(len(config.Signers)) < config.Threshold {
+	if config.Address == (common.Address{}) || (len(config.GroupKey) == 0 && uint64(len(config.Signers)) < config.Threshold) {
 		log.Warn("Invalid checkpoint registrar config")
 		return nil
 	}
@@ -168,6 +168,10 @@ func (c *lesCommons) setupOracle(node *node.Node, genesis common.Hash, ethconfig
 	rpcClient, _ := node.Attach()
 	client := ethclient.NewClient(rpcClient)
 	oracle.Start(client)
-	log.Info("Configured checkpoint registrar", "address", config.Address, "signers", len(config.Signers), "threshold", config.Threshold)
+	if oracle.GroupSigned() {
+		log.Info("Configured group checkpoint registrar", "address", config.Address)
+	} else {
+		log.Info("Configured checkpoint registrar", "address", config.Address, "signers", len(config.Signers), "threshold", config.Threshold)
+	}
 	return oracle
 }
@@ -66,6 +66,14 @@ func (h *clientHandler) validateCheckpoint(peer *serverPeer) error {
 	if err != nil {
 		return err
 	}
+	if h.backend.oracle.GroupSigned() {
+		events := h.backend.oracle.GroupContract().LookupCheckpointEvents(logs, peer.checkpoint.SectionIndex, peer.checkpoint.Hash())
+		if len(events) == 0 || !h.backend.oracle.VerifyGroupSignature(events[0].Index, events[0].CheckpointHash, events[0].Signature) {
+			return errInvalidCheckpoint
+		}
+		log.Warn("Verified advertised checkpoint", "peer", peer.id, "committee", "group")
+		return nil
+	}
 	events := h.backend.oracle.Contract().LookupCheckpointEvents(logs, peer.checkpoint.SectionIndex, peer.checkpoint.Hash())
 	if len(events) == 0 {
 		return errInvalidCheckpoint
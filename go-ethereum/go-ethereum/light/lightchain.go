@@ -57,6 +57,7 @@ type LightChain struct {
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
+	headerFeed    event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
@@ -361,6 +362,7 @@ func (lc *LightChain) postChainEvents(events []interface{}) {
 		case core.ChainEvent:
 			if lc.CurrentHeader().Hash() == ev.Hash {
 				lc.chainHeadFeed.Send(core.ChainHeadEvent{Block: ev.Block})
+				lc.headerFeed.Send(ev.Block.Header())
 			}
 			lc.chainFeed.Send(ev)
 		case core.ChainSideEvent:
@@ -556,6 +558,12 @@ func (lc *LightChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) eve
 	return lc.scope.Track(lc.chainHeadFeed.Subscribe(ch))
 }
 
+// SubscribeChainHeaderEvent registers a subscription for the header of every new
+// canonical chain head, implementing consensus.ChainHeadSubscriber.
+func (lc *LightChain) SubscribeChainHeaderEvent(ch chan<- *types.Header) event.Subscription {
+	return lc.scope.Track(lc.headerFeed.Subscribe(ch))
+}
+
 // SubscribeChainSideEvent registers a subscription of ChainSideEvent.
 func (lc *LightChain) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription {
 	return lc.scope.Track(lc.chainSideFeed.Subscribe(ch))
@@ -32,7 +32,6 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
-	"github.com/ethereum/go-ethereum/rlp"
 )
 
 const (
@@ -69,6 +68,8 @@ type TxPool struct {
 	clearIdx     uint64                               // earliest block nr that can contain mined tx info
 
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
+	eip2718  bool // Fork indicator whether we are in the eip2718 stage.
+	feePayer bool // Fork indicator whether we are in the fee payer stage.
 }
 
 // TxRelayBackend provides an interface to the mechanism that forwards transacions
@@ -90,7 +91,7 @@ type TxRelayBackend interface {
 func NewTxPool(config *params.ChainConfig, chain *LightChain, relay TxRelayBackend) *TxPool {
 	pool := &TxPool{
 		config:      config,
-		signer:      types.NewEIP155Signer(config.ChainID),
+		signer:      types.LatestSigner(config),
 		nonce:       make(map[common.Address]uint64),
 		pending:     make(map[common.Hash]*types.Transaction),
 		mined:       make(map[common.Hash][]*types.Transaction),
@@ -314,6 +315,8 @@ func (pool *TxPool) setNewHead(head *types.Header) {
 	// Update fork indicator by next pending block number
 	next := new(big.Int).Add(head.Number, big.NewInt(1))
 	pool.istanbul = pool.config.IsIstanbul(next)
+	pool.eip2718 = pool.config.IsYoloV2(next)
+	pool.feePayer = pool.config.IsFeePayer(next)
 }
 
 // Stop stops the light transaction pool
@@ -343,6 +346,14 @@ func (pool *TxPool) Stats() (pending int) {
 
 // validateTx checks whether a transaction is valid according to the consensus rules.
 func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error {
+	// Accept access list transactions only once EIP-2718/2930 activates.
+	if !pool.eip2718 && tx.Type() == types.AccessListTxType {
+		return core.ErrTxTypeNotSupported
+	}
+	// Accept sponsored transactions only once the fee payer fork activates.
+	if !pool.feePayer && tx.Sponsored() {
+		return core.ErrTxTypeNotSupported
+	}
 	// Validate sender
 	var (
 		from common.Address
@@ -375,19 +386,42 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	}
 
 	// Transactor should have enough funds to cover the costs
-	// cost == V + GP * GL
-	if b := currentState.GetBalance(from); b.Cmp(tx.Cost()) < 0 {
+	// cost == V + GP * GL, or only V if the gas is sponsored
+	if b := currentState.GetBalance(from); b.Cmp(tx.SenderCost()) < 0 {
 		return core.ErrInsufficientFunds
 	}
+	// The fee payer of a sponsored transaction should cover the gas
+	if tx.Sponsored() {
+		payer, err := types.FeePayer(pool.signer, tx)
+		if err != nil {
+			return types.ErrInvalidFeePayer
+		}
+		fee := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+		if payer == from {
+			fee = tx.Cost()
+		}
+		if b := currentState.GetBalance(payer); b.Cmp(fee) < 0 {
+			return core.ErrInsufficientFunds
+		}
+	}
 
 	// Should supply enough intrinsic gas
-	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, true, pool.istanbul)
+	gas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
 	if err != nil {
 		return err
 	}
+	if tx.Anonymous() {
+		gas += params.TxGroupAuthGas
+	}
 	if tx.Gas() < gas {
 		return core.ErrIntrinsicGas
 	}
+	// Verify the group signature of anonymous transactions last, it's expensive
+	if tx.Anonymous() {
+		if err := core.VerifyGroupAuth(pool.signer, tx); err != nil {
+			return err
+		}
+	}
 	return currentState.Error()
 }
 
@@ -431,7 +465,7 @@ func (pool *TxPool) Add(ctx context.Context, tx *types.Transaction) error {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return err
 	}
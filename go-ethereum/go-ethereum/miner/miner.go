@@ -38,6 +38,7 @@ import (
 type Backend interface {
 	BlockChain() *core.BlockChain
 	TxPool() *core.TxPool
+	BundlePool() *core.BundlePool
 }
 
 // Config is the configuration parameters of mining.
@@ -50,6 +51,23 @@ type Config struct {
 	GasPrice  *big.Int       // Minimum gas price for mining a transaction
 	Recommit  time.Duration  // The time interval for miner to re-create mining work.
 	Noverify  bool           // Disable remote mining solution verification(only useful in ethash).
+	Parallel  bool           // Execute the transactions of mined blocks speculatively in parallel
+	Ordering  string         // Transaction ordering policy (price, fifo or hash)
+}
+
+// Transaction ordering policies of the miner.
+const (
+	OrderingPrice = "price" // Highest gas price first, the default
+	OrderingFIFO  = "fifo"  // First seen first, by the local arrival time
+	OrderingHash  = "hash"  // Deterministic order by transaction hash
+)
+
+// ordering returns the transaction ordering policy in use.
+func (c *Config) ordering() string {
+	if c.Ordering == "" {
+		return OrderingPrice
+	}
+	return c.Ordering
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -179,6 +197,18 @@ func (miner *Miner) SetRecommitInterval(interval time.Duration) {
 	miner.worker.setRecommitInterval(interval)
 }
 
+// SetTimeOffset shifts the clock used to time new blocks. It is meant for
+// development chains only, to move through time without waiting for it.
+func (miner *Miner) SetTimeOffset(offset time.Duration) {
+	miner.worker.setTimeOffset(offset)
+}
+
+// Recommit discards the current sealing work and starts anew on top of the
+// current chain head.
+func (miner *Miner) Recommit() {
+	miner.worker.recommit()
+}
+
 // Pending returns the currently pending block and associated state.
 func (miner *Miner) Pending() (*types.Block, *state.StateDB) {
 	return miner.worker.pending()
@@ -19,7 +19,9 @@ package miner
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum/experiment"
+	"math"
 	"math/big"
 	"sync"
 	"sync/atomic"
@@ -76,6 +78,10 @@ const (
 
 	// staleThreshold is the maximum depth of the acceptable stale block.
 	staleThreshold = 7
+
+	// parallelBatchSize is the maximum number of transactions executed together
+	// when parallel transaction execution is enabled.
+	parallelBatchSize = 256
 )
 
 // environment is the worker's current environment and holds all of the current state information.
@@ -156,9 +162,10 @@ type worker struct {
 	remoteUncles map[common.Hash]*types.Block // A set of side blocks as the possible uncle blocks.
 	unconfirmed  *unconfirmedBlocks           // A set of locally mined blocks pending canonicalness confirmations.
 
-	mu       sync.RWMutex // The lock used to protect the coinbase and extra fields
-	coinbase common.Address
-	extra    []byte
+	mu         sync.RWMutex // The lock used to protect the coinbase, extra and time offset fields
+	coinbase   common.Address
+	extra      []byte
+	timeOffset time.Duration // Shift of the clock used to time new blocks, for development chains only
 
 	pendingMu    sync.RWMutex
 	pendingTasks map[common.Hash]*task
@@ -170,6 +177,7 @@ type worker struct {
 	// atomic status counters
 	running int32 // The indicator whether the consensus engine is running or not.
 	newTxs  int32 // New arrival transaction count since last sealing work submitting.
+	reseal  int32 // The indicator whether the next sealing work is submitted even if a duplicate.
 
 	// noempty is the flag used to control whether the feature of pre-seal empty
 	// block is enabled. The default value is false(pre-seal is enabled by default).
@@ -251,6 +259,30 @@ func (w *worker) setExtra(extra []byte) {
 	w.extra = extra
 }
 
+// setTimeOffset sets the shift of the clock used to time new blocks.
+func (w *worker) setTimeOffset(offset time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timeOffset = offset
+}
+
+// now returns the current time, shifted by the time offset.
+func (w *worker) now() time.Time {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return time.Now().Add(w.timeOffset)
+}
+
+// recommit requests new sealing work on top of the current chain head, to be
+// submitted to the consensus engine even if it is the same as the previous one.
+func (w *worker) recommit() {
+	atomic.StoreInt32(&w.reseal, 1)
+	select {
+	case w.startCh <- struct{}{}:
+	default:
+	}
+}
+
 // setRecommitInterval updates the interval for miner sealing work recommitting.
 func (w *worker) setRecommitInterval(interval time.Duration) {
 	w.resubmitIntervalCh <- interval
@@ -371,12 +403,12 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 		select {
 		case <-w.startCh:
 			clearPending(w.chain.CurrentBlock().NumberU64())
-			timestamp = time.Now().Unix()
+			timestamp = w.now().Unix()
 			commit(false, commitInterruptNewHead)
 
 		case head := <-w.chainHeadCh:
 			clearPending(head.Block.NumberU64())
-			timestamp = time.Now().Unix()
+			timestamp = w.now().Unix()
 			commit(false, commitInterruptNewHead)
 
 		case <-timer.C:
@@ -497,7 +529,7 @@ func (w *worker) mainLoop() {
 					acc, _ := types.Sender(w.current.signer, tx)
 					txs[acc] = append(txs[acc], tx)
 				}
-				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs)
+				txset := w.orderTransactions(txs)
 				tcount := w.current.tcount
 				w.commitTransactions(txset, coinbase, nil)
 				// Only update the snapshot if any new transactons were added
@@ -510,7 +542,7 @@ func (w *worker) mainLoop() {
 				// submit mining work here since all empty submission will be rejected
 				// by clique. Of course the advance sealing(empty submission) is disabled.
 				if w.chainConfig.Clique != nil && w.chainConfig.Clique.Period == 0 {
-					w.commitNewWork(nil, true, time.Now().Unix())
+					w.commitNewWork(nil, true, w.now().Unix())
 				}
 			}
 			atomic.AddInt32(&w.newTxs, int32(len(ev.Txs)))
@@ -549,9 +581,9 @@ func (w *worker) taskLoop() {
 			if w.newTaskHook != nil {
 				w.newTaskHook(task)
 			}
-			// Reject duplicate sealing work due to resubmitting.
+			// Reject duplicate sealing work due to resubmitting, unless explicitly requested.
 			sealHash := w.engine.SealHash(task.block.Header())
-			if sealHash == prev {
+			if reseal := atomic.SwapInt32(&w.reseal, 0) == 1; sealHash == prev && !reseal {
 				continue
 			}
 			// Interrupt previous sealing operation
@@ -656,8 +688,11 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 	if err != nil {
 		return err
 	}
+	if w.chain.RecordsStateDiffs() {
+		state.StartDiff()
+	}
 	env := &environment{
-		signer:    types.NewEIP155Signer(w.chainConfig.ChainID),
+		signer:    types.MakeSigner(w.chainConfig, header.Number),
 		state:     state,
 		ancestors: mapset.NewSet(),
 		family:    mapset.NewSet(),
@@ -747,7 +782,206 @@ func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Addres
 	return receipt.Logs, nil
 }
 
-func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coinbase common.Address, interrupt *int32) bool {
+// sendPendingLogs pushes the logs of freshly committed pending transactions to
+// the pending log subscribers.
+func (w *worker) sendPendingLogs(logs []*types.Log) {
+	if !w.isRunning() && len(logs) > 0 {
+		// We don't push the pendingLogsEvent while we are mining. The reason is that
+		// when we are mining, the worker will regenerate a mining block every 3 seconds.
+		// In order to avoid pushing the repeated pendingLog, we disable the pending log pushing.
+
+		// make a copy, the state caches the logs and these logs get "upgraded" from pending to mined
+		// logs by filling in the block hash when the block was mined by the local miner. This can
+		// cause a race condition if a log was "upgraded" before the PendingLogsEvent is processed.
+		cpy := make([]*types.Log, len(logs))
+		for i, l := range logs {
+			cpy[i] = new(types.Log)
+			*cpy[i] = *l
+		}
+		w.pendingLogsFeed.Send(cpy)
+	}
+}
+
+// errBundleReverted is returned if a bundled transaction reverts without the
+// bundle allowing it to.
+var errBundleReverted = errors.New("bundled transaction reverted")
+
+// commitBundles includes all the bundles eligible for the current block, in
+// their order of arrival. Bundles already mined into the parent chain are
+// dropped, bundles that can never apply are discarded from the pool. Bundles
+// failing for the block at hand only, e.g. for lack of gas or because of a
+// nonce gap, are kept for the later blocks of their range.
+func (w *worker) commitBundles(coinbase common.Address) {
+	pool := w.eth.BundlePool()
+	pool.Reset(w.current.state)
+
+	var coalescedLogs []*types.Log
+	for _, bundle := range pool.Bundles(w.current.header.Number.Uint64()) {
+		logs, err := w.commitBundle(bundle, coinbase)
+		if err != nil {
+			if bundleFailed(err) {
+				log.Debug("Discarding failed transaction bundle", "hash", bundle.Hash(), "err", err)
+				pool.Remove(bundle.Hash())
+			} else {
+				log.Trace("Skipping transaction bundle", "hash", bundle.Hash(), "err", err)
+			}
+			continue
+		}
+		coalescedLogs = append(coalescedLogs, logs...)
+	}
+	w.sendPendingLogs(coalescedLogs)
+}
+
+// bundleFailed reports whether a bundle simulation error is permanent: one of
+// its transactions reverted against the bundle's policy, has a nonce already
+// used or an invalid signature.
+func bundleFailed(err error) bool {
+	for _, permanent := range []error{errBundleReverted, core.ErrNonceTooLow, types.ErrInvalidSig, types.ErrInvalidChainId, core.ErrGroupAuth} {
+		if errors.Is(err, permanent) {
+			return true
+		}
+	}
+	return false
+}
+
+// commitBundle simulates a bundle on top of the current block, keeping all of
+// its transactions if every one of them applies and does not revert (unless the
+// bundle allows it to). Otherwise the block is rolled back to where it was.
+func (w *worker) commitBundle(bundle *core.Bundle, coinbase common.Address) ([]*types.Log, error) {
+	if w.current.gasPool == nil {
+		w.current.gasPool = new(core.GasPool).AddGas(w.current.header.GasLimit)
+	}
+	// The state journal doesn't survive transaction boundaries, roll back by copy
+	var (
+		state   = w.current.state.Copy()
+		gasPool = *w.current.gasPool
+		gasUsed = w.current.header.GasUsed
+		count   = len(w.current.txs)
+	)
+	rollback := func() {
+		w.current.state = state
+		*w.current.gasPool = gasPool
+		w.current.header.GasUsed = gasUsed
+		w.current.txs = w.current.txs[:count]
+		w.current.receipts = w.current.receipts[:count]
+	}
+	var coalescedLogs []*types.Log
+	for i, tx := range bundle.Txs {
+		if tx.Protected() && !w.chainConfig.IsEIP155(w.current.header.Number) {
+			rollback()
+			return nil, fmt.Errorf("transaction %x replay protected before EIP155", tx.Hash())
+		}
+		w.current.state.Prepare(tx.Hash(), common.Hash{}, w.current.tcount+i)
+
+		logs, err := w.commitTransaction(tx, coinbase)
+		if err != nil {
+			rollback()
+			return nil, fmt.Errorf("transaction %x failed: %w", tx.Hash(), err)
+		}
+		if receipt := w.current.receipts[len(w.current.receipts)-1]; receipt.Status == types.ReceiptStatusFailed && !bundle.CanRevert(tx.Hash()) {
+			rollback()
+			return nil, fmt.Errorf("transaction %x: %w", tx.Hash(), errBundleReverted)
+		}
+		coalescedLogs = append(coalescedLogs, logs...)
+	}
+	w.current.tcount += len(bundle.Txs)
+	return coalescedLogs, nil
+}
+
+// orderedTransactions is a set of pending transactions from multiple accounts,
+// yielding them in the order of a policy while honouring account nonces.
+type orderedTransactions interface {
+	Peek() *types.Transaction
+	Shift()
+	Pop()
+}
+
+// orderTransactions sorts the pending transactions according to the configured
+// ordering policy. The input map is reowned by the returned set.
+func (w *worker) orderTransactions(txs map[common.Address]types.Transactions) orderedTransactions {
+	switch w.config.Ordering {
+	case OrderingFIFO:
+		return types.NewTransactionsByOrderAndNonce(w.current.signer, txs, types.TxByTime)
+	case OrderingHash:
+		return types.NewTransactionsByOrderAndNonce(w.current.signer, txs, types.TxByHash)
+	default:
+		return types.NewTransactionsByPriceAndNonce(w.current.signer, txs)
+	}
+}
+
+// bySlack returns a transaction ordering that prefers the accounts closest to
+// missing an inclusion deadline. As a transaction can only be included after all
+// its nonce predecessors, each one inherits the earliest deadline of itself and
+// any of its successors. Ties are broken by gas price.
+func bySlack(txs map[common.Address]types.Transactions, deadlines map[common.Hash]uint64) types.TxOrdering {
+	effective := make(map[common.Hash]uint64)
+	for _, list := range txs {
+		earliest := uint64(math.MaxUint64)
+		for i := len(list) - 1; i >= 0; i-- {
+			if deadline, ok := deadlines[list[i].Hash()]; ok && deadline < earliest {
+				earliest = deadline
+			}
+			effective[list[i].Hash()] = earliest
+		}
+	}
+	return func(a, b *types.Transaction) bool {
+		if da, db := effective[a.Hash()], effective[b.Hash()]; da != db {
+			return da < db
+		}
+		return a.GasPrice().Cmp(b.GasPrice()) > 0
+	}
+}
+
+// commitTransactionBatch pulls a batch of transactions fitting into the remaining
+// block gas and applies them speculatively in parallel. It returns the logs of
+// the included transactions, or false if there were no transactions left.
+func (w *worker) commitTransactionBatch(txs orderedTransactions, coinbase common.Address) ([]*types.Log, bool) {
+	var (
+		batch types.Transactions
+		gas   = w.current.gasPool.Gas()
+	)
+	for len(batch) < parallelBatchSize {
+		tx := txs.Peek()
+		if tx == nil {
+			break
+		}
+		// Skip replay protected transactions and accounts not fitting in the
+		// block any more, the same way serial execution would
+		if tx.Protected() && !w.chainConfig.IsEIP155(w.current.header.Number) {
+			log.Trace("Ignoring reply protected transaction", "hash", tx.Hash(), "eip155", w.chainConfig.EIP155Block)
+			txs.Pop()
+			continue
+		}
+		if tx.Gas() > gas {
+			log.Trace("Gas limit exceeded for current block", "hash", tx.Hash())
+			txs.Pop()
+			continue
+		}
+		gas -= tx.Gas()
+		batch = append(batch, tx)
+		txs.Shift()
+	}
+	if len(batch) == 0 {
+		return nil, false
+	}
+	receipts, errs := core.ApplyTransactionsParallel(w.chainConfig, w.chain, &coinbase, w.current.gasPool, w.current.state, w.current.header, common.Hash{}, batch, w.current.tcount, &w.current.header.GasUsed, *w.chain.GetVMConfig())
+
+	var logs []*types.Log
+	for i, tx := range batch {
+		if errs[i] != nil {
+			log.Debug("Transaction failed, skipped", "hash", tx.Hash(), "err", errs[i])
+			continue
+		}
+		w.current.txs = append(w.current.txs, tx)
+		w.current.receipts = append(w.current.receipts, receipts[i])
+		w.current.tcount++
+
+		logs = append(logs, receipts[i].Logs...)
+	}
+	return logs, true
+}
+
+func (w *worker) commitTransactions(txs orderedTransactions, coinbase common.Address, interrupt *int32) bool {
 	// Short circuit if current is nil
 	if w.current == nil {
 		return true
@@ -785,6 +1019,15 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 			log.Trace("Not enough gas for further transactions", "have", w.current.gasPool, "want", params.TxGas)
 			break
 		}
+		// Execute a whole batch of transactions in parallel if requested
+		if w.config.Parallel && w.chainConfig.IsByzantium(w.current.header.Number) {
+			logs, ok := w.commitTransactionBatch(txs, coinbase)
+			if !ok {
+				break
+			}
+			coalescedLogs = append(coalescedLogs, logs...)
+			continue
+		}
 		// Retrieve the next transaction and abort if all done
 		tx := txs.Peek()
 		if tx == nil {
@@ -837,21 +1080,8 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		}
 	}
 
-	if !w.isRunning() && len(coalescedLogs) > 0 {
-		// We don't push the pendingLogsEvent while we are mining. The reason is that
-		// when we are mining, the worker will regenerate a mining block every 3 seconds.
-		// In order to avoid pushing the repeated pendingLog, we disable the pending log pushing.
+	w.sendPendingLogs(coalescedLogs)
 
-		// make a copy, the state caches the logs and these logs get "upgraded" from pending to mined
-		// logs by filling in the block hash when the block was mined by the local miner. This can
-		// cause a race condition if a log was "upgraded" before the PendingLogsEvent is processed.
-		cpy := make([]*types.Log, len(coalescedLogs))
-		for i, l := range coalescedLogs {
-			cpy[i] = new(types.Log)
-			*cpy[i] = *l
-		}
-		w.pendingLogsFeed.Send(cpy)
-	}
 	// Notify resubmit loop to decrease resubmitting interval if current interval is larger
 	// than the user-specified one.
 	if interrupt != nil {
@@ -872,7 +1102,7 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		timestamp = int64(parent.Time() + 1)
 	}
 	// this will ensure we're not going off too far in the future
-	if now := time.Now().Unix(); timestamp > now+1 {
+	if now := time.Now().Add(w.timeOffset).Unix(); timestamp > now+1 {
 		wait := time.Duration(timestamp-now) * time.Second
 		log.Info("Mining too far in the future", "wait", common.PrettyDuration(wait))
 		time.Sleep(wait)
@@ -959,29 +1189,58 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		log.Error("Failed to fetch pending transactions", "err", err)
 		return
 	}
+	// Include the eligible bundles first, each one all-or-nothing. They bypass
+	// the pending set, so they need including even if it's empty.
+	w.commitBundles(w.coinbase)
+
+	// Include the revealed committed transactions next, in commitment order
+	if revealed, order := w.eth.TxPool().Revealed(); len(revealed) > 0 {
+		txs := types.NewTransactionsByOrderAndNonce(w.current.signer, revealed, order)
+		if w.commitTransactions(txs, w.coinbase, interrupt) {
+			return
+		}
+	}
 	// Short circuit if there is no available pending transactions.
 	// But if we disable empty precommit already, ignore it. Since
 	// empty block is necessary to keep the liveness of the network.
-	if len(pending) == 0 && atomic.LoadUint32(&w.noempty) == 0 {
+	if len(pending) == 0 && w.current.tcount == 0 && atomic.LoadUint32(&w.noempty) == 0 {
 		w.updateSnapshot()
 		return
 	}
-	// Split the pending transactions into locals and remotes
-	localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
+
+	// Split the pending transactions into urgent ones, locals and remotes
+	deadlines := w.eth.TxPool().Deadlines()
+
+	urgentTxs, localTxs, remoteTxs := make(map[common.Address]types.Transactions), make(map[common.Address]types.Transactions), pending
+	for account, txs := range remoteTxs {
+		for _, tx := range txs {
+			if _, ok := deadlines[tx.Hash()]; ok {
+				delete(remoteTxs, account)
+				urgentTxs[account] = txs
+				break
+			}
+		}
+	}
 	for _, account := range w.eth.TxPool().Locals() {
 		if txs := remoteTxs[account]; len(txs) > 0 {
 			delete(remoteTxs, account)
 			localTxs[account] = txs
 		}
 	}
+	if len(urgentTxs) > 0 {
+		txs := types.NewTransactionsByOrderAndNonce(w.current.signer, urgentTxs, bySlack(urgentTxs, deadlines))
+		if w.commitTransactions(txs, w.coinbase, interrupt) {
+			return
+		}
+	}
 	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, localTxs)
+		txs := w.orderTransactions(localTxs)
 		if w.commitTransactions(txs, w.coinbase, interrupt) {
 			return
 		}
 	}
 	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, remoteTxs)
+		txs := w.orderTransactions(remoteTxs)
 		if w.commitTransactions(txs, w.coinbase, interrupt) {
 			return
 		}
@@ -1001,7 +1260,7 @@ func (w *worker) commit(uncles []*types.Header, interval func(), update bool, st
 		for _, v := range w.current.txs {
 			txHashs = append(txHashs, v.Hash().Hex())
 		}
-		_ = experiment.Record(map[string]interface{}{"Type": "BlockGen", "TransactionHashs": txHashs})
+		_ = experiment.Record(map[string]interface{}{"Type": "BlockGen", "TransactionHashs": txHashs, "Ordering": w.config.ordering()})
 	}
 	s := w.current.state.Copy()
 	block, err := w.engine.FinalizeAndAssemble(w.chain, w.current.header, s, w.current.txs, uncles, receipts)
@@ -17,8 +17,11 @@
 package miner
 
 import (
+	"bytes"
+	"crypto/ecdsa"
 	"math/big"
 	"math/rand"
+	"sort"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -36,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 const (
@@ -61,6 +65,9 @@ var (
 	testUserKey, _  = crypto.GenerateKey()
 	testUserAddress = crypto.PubkeyToAddress(testUserKey.PublicKey)
 
+	// Funded accounts sending concurrently, to test the ordering policies
+	testOrderKeys = make([]*ecdsa.PrivateKey, 3)
+
 	// Test transactions
 	pendingTxs []*types.Transaction
 	newTxs     []*types.Transaction
@@ -73,8 +80,12 @@ var (
 )
 
 func init() {
+	for i := range testOrderKeys {
+		testOrderKeys[i], _ = crypto.GenerateKey()
+	}
 	testTxPoolConfig = core.DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
+	testTxPoolConfig.TimelockJournal = ""
 	ethashChainConfig = params.TestChainConfig
 	cliqueChainConfig = params.TestChainConfig
 	cliqueChainConfig.Clique = &params.CliqueConfig{
@@ -92,6 +103,7 @@ func init() {
 type testWorkerBackend struct {
 	db         ethdb.Database
 	txPool     *core.TxPool
+	bundlePool *core.BundlePool
 	chain      *core.BlockChain
 	testTxFeed event.Feed
 	genesis    *core.Genesis
@@ -103,6 +115,9 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 		Config: chainConfig,
 		Alloc:  core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}},
 	}
+	for _, key := range testOrderKeys {
+		gspec.Alloc[crypto.PubkeyToAddress(key.PublicKey)] = core.GenesisAccount{Balance: testBankFunds}
+	}
 
 	switch e := engine.(type) {
 	case *clique.Clique:
@@ -141,6 +156,7 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 		db:         db,
 		chain:      chain,
 		txPool:     txpool,
+		bundlePool: core.NewBundlePool(chainConfig, chain, testTxPoolConfig.PriceLimit),
 		genesis:    &gspec,
 		uncleBlock: blocks[0],
 	}
@@ -148,6 +164,7 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 
 func (b *testWorkerBackend) BlockChain() *core.BlockChain { return b.chain }
 func (b *testWorkerBackend) TxPool() *core.TxPool         { return b.txPool }
+func (b *testWorkerBackend) BundlePool() *core.BundlePool { return b.bundlePool }
 
 func (b *testWorkerBackend) newRandomUncle() *types.Block {
 	var parent *types.Block
@@ -501,3 +518,244 @@ func testAdjustInterval(t *testing.T, chainConfig *params.ChainConfig, engine co
 		t.Error("interval reset timeout")
 	}
 }
+
+// Tests that bundles are included into the pending block atomically, with the
+// failing ones being discarded without leaving any of their transactions behind,
+// unless they may still apply to a later block.
+func TestCommitBundles(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	transfer := func(nonce uint64, value int64) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, testUserAddress, big.NewInt(value), params.TxGas, big.NewInt(1), nil), types.HomesteadSigner{}, testBankKey)
+		return tx
+	}
+	// The second transaction of the first bundle has a nonce gap, skipping it
+	// for now. The last bundle reuses a nonce of the valid one, failing it.
+	gapped := &core.Bundle{Txs: types.Transactions{transfer(0, 1), transfer(5, 1)}, MinBlock: 1, MaxBlock: 2}
+	valid := &core.Bundle{Txs: types.Transactions{transfer(0, 2), transfer(1, 2)}, MinBlock: 1, MaxBlock: 1}
+	stale := &core.Bundle{Txs: types.Transactions{transfer(1, 3)}, MinBlock: 1, MaxBlock: 1}
+
+	for _, bundle := range []*core.Bundle{gapped, valid, stale} {
+		if err := b.bundlePool.Add(bundle); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+	}
+	w.commitNewWork(nil, false, time.Now().Unix())
+
+	// Only the valid bundle should be included, superseding the pooled transaction
+	if len(w.current.txs) != len(valid.Txs) {
+		t.Fatalf("included transaction count mismatch: have %d, want %d", len(w.current.txs), len(valid.Txs))
+	}
+	for i, tx := range valid.Txs {
+		if w.current.txs[i].Hash() != tx.Hash() {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, w.current.txs[i].Hash(), tx.Hash())
+		}
+	}
+	if have, want := w.current.state.GetBalance(testUserAddress), big.NewInt(4); have.Cmp(want) != 0 {
+		t.Errorf("recipient balance mismatch: have %v, want %v", have, want)
+	}
+	// The gapped bundle should be kept, the stale one discarded
+	bundles := b.bundlePool.Bundles(1)
+	if len(bundles) != 2 || bundles[0].Hash() != gapped.Hash() || bundles[1].Hash() != valid.Hash() {
+		t.Errorf("pooled bundles mismatch: have %d bundles", len(bundles))
+	}
+}
+
+// Tests that bundles are mined even if the transaction pool is empty, that their
+// logs are reported as pending and that they are dropped once mined.
+func TestCommitBundlesEmptyPool(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	w := newWorker(testConfig, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	defer w.close()
+	w.setEtherbase(testBankAddress)
+
+	logsCh := make(chan []*types.Log, 1)
+	sub := w.pendingLogsFeed.Subscribe(logsCh)
+	defer sub.Unsubscribe()
+
+	// Deploy the test contract and emit an event from it within a single bundle
+	signer := types.HomesteadSigner{}
+	deploy, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), testGas, nil, common.FromHex(testCode)), signer, testBankKey)
+	call, _ := types.SignTx(types.NewTransaction(1, crypto.CreateAddress(testBankAddress, 0), big.NewInt(0), testGas, nil, common.FromHex("0x98a213cf0000000000000000000000000000000000000000000000000000000000000001")), signer, testBankKey)
+
+	// The pending block of a stopped worker has no coinbase, keep the bundle free
+	// so that the block can be imported as is
+	b.bundlePool.SetGasPrice(new(big.Int))
+
+	bundle := &core.Bundle{Txs: types.Transactions{deploy, call}, MinBlock: 1, MaxBlock: 2}
+	if err := b.bundlePool.Add(bundle); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	w.commitNewWork(nil, false, time.Now().Unix())
+
+	if len(w.current.txs) != len(bundle.Txs) {
+		t.Fatalf("included transaction count mismatch: have %d, want %d", len(w.current.txs), len(bundle.Txs))
+	}
+	select {
+	case logs := <-logsCh:
+		if len(logs) != 1 || logs[0].TxHash != call.Hash() {
+			t.Errorf("pending logs mismatch: have %d logs", len(logs))
+		}
+	case <-time.After(time.Second):
+		t.Errorf("no pending logs reported for the bundle")
+	}
+	// Mine the pending block and ensure the bundle is dropped
+	block, err := engine.FinalizeAndAssemble(b.chain, w.current.header, w.current.state, w.current.txs, nil, w.current.receipts)
+	if err != nil {
+		t.Fatalf("failed to assemble block: %v", err)
+	}
+	if _, err := b.chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	w.commitNewWork(nil, false, time.Now().Unix())
+
+	if n := b.bundlePool.Len(); n != 0 {
+		t.Errorf("mined bundle not dropped: have %d bundles", n)
+	}
+	if len(w.current.txs) != 0 {
+		t.Errorf("mined bundle included again: have %d transactions", len(w.current.txs))
+	}
+}
+
+// Tests that revealed committed transactions are mined even if the pending set
+// of the transaction pool is empty.
+func TestCommitRevealedEmptyPool(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	b.txPool.Stop()
+
+	config := testTxPoolConfig
+	config.RevealWindow = 4
+	b.txPool = core.NewTxPool(config, ethashChainConfig, b.chain)
+	defer b.txPool.Stop()
+
+	// Commit to a transaction and order the commitment into a block
+	tx, _ := types.SignTx(types.NewTransaction(0, testUserAddress, big.NewInt(1000), params.TxGas, nil, nil), types.HomesteadSigner{}, testBankKey)
+	if err := b.txPool.Commit(tx.Hash()); err != nil {
+		t.Fatalf("failed to commit transaction: %v", err)
+	}
+	blocks, _ := core.GenerateChain(ethashChainConfig, b.chain.CurrentBlock(), engine, b.db, 1, nil)
+	if _, err := b.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		err := b.txPool.Reveal(tx)
+		if err == nil {
+			break
+		}
+		if err != core.ErrCommitmentPending || time.Since(start) > time.Second {
+			t.Fatalf("failed to reveal transaction: %v", err)
+		}
+	}
+	w := newWorker(testConfig, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	defer w.close()
+	w.setEtherbase(testBankAddress)
+
+	w.commitNewWork(nil, false, time.Now().Unix())
+	if len(w.current.txs) != 1 || w.current.txs[0].Hash() != tx.Hash() {
+		t.Fatalf("revealed transaction not included: have %d transactions", len(w.current.txs))
+	}
+}
+
+// Tests that the pending block assembled with parallel transaction execution is
+// the same as the one assembled serially.
+func TestCommitTransactionsParallel(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	build := func(parallel bool) *environment {
+		backend := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+		config := *testConfig
+		config.Parallel = parallel
+
+		w := newWorker(&config, ethashChainConfig, engine, backend, new(event.TypeMux), nil, false)
+		defer w.close()
+		w.setEtherbase(testBankAddress)
+
+		for i := 0; i < 8; i++ {
+			backend.txPool.AddLocal(backend.newRandomTx(i%4 == 0))
+		}
+		w.commitNewWork(nil, false, time.Now().Unix())
+		return w.current
+	}
+	serial, parallel := build(false), build(true)
+
+	if len(parallel.txs) != 8 || len(parallel.txs) != len(serial.txs) {
+		t.Fatalf("included transaction count mismatch: have %d, want %d", len(parallel.txs), len(serial.txs))
+	}
+	if have, want := parallel.header.GasUsed, serial.header.GasUsed; have != want {
+		t.Errorf("gas used mismatch: have %d, want %d", have, want)
+	}
+	if have, want := types.DeriveSha(types.Receipts(parallel.receipts), new(trie.Trie)), types.DeriveSha(types.Receipts(serial.receipts), new(trie.Trie)); have != want {
+		t.Errorf("receipt root mismatch: have %x, want %x", have, want)
+	}
+	if have, want := parallel.state.IntermediateRoot(true), serial.state.IntermediateRoot(true); have != want {
+		t.Errorf("state root mismatch: have %x, want %x", have, want)
+	}
+}
+
+// Tests that the pending block includes the transactions of distinct accounts
+// in the order of the configured ordering policy.
+func TestTransactionOrdering(t *testing.T) {
+	// Send transactions in turn from each account, with prices unrelated to the
+	// order of arrival
+	var (
+		prices = []int64{1, 3, 2}
+		txs    = make([]*types.Transaction, len(testOrderKeys))
+	)
+	for i, key := range testOrderKeys {
+		tx := types.NewTransaction(0, testUserAddress, big.NewInt(1), params.TxGas, big.NewInt(prices[i]), nil)
+		txs[i], _ = types.SignTx(tx, types.HomesteadSigner{}, key)
+		time.Sleep(time.Millisecond)
+	}
+	byHash := []*types.Transaction{txs[0], txs[1], txs[2]}
+	sort.Slice(byHash, func(i, j int) bool {
+		hi, hj := byHash[i].Hash(), byHash[j].Hash()
+		return bytes.Compare(hi[:], hj[:]) < 0
+	})
+	tests := []struct {
+		ordering string
+		want     []*types.Transaction
+	}{
+		{"", []*types.Transaction{txs[1], txs[2], txs[0]}},
+		{OrderingPrice, []*types.Transaction{txs[1], txs[2], txs[0]}},
+		{OrderingFIFO, []*types.Transaction{txs[0], txs[1], txs[2]}},
+		{OrderingHash, byHash},
+	}
+	for _, tt := range tests {
+		engine := ethash.NewFaker()
+		backend := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+		config := *testConfig
+		config.Ordering = tt.ordering
+
+		w := newWorker(&config, ethashChainConfig, engine, backend, new(event.TypeMux), nil, false)
+		w.setEtherbase(testBankAddress)
+		for _, err := range backend.txPool.AddLocals(txs) {
+			if err != nil {
+				t.Fatalf("ordering %q: failed to add transaction: %v", tt.ordering, err)
+			}
+		}
+		w.commitNewWork(nil, false, time.Now().Unix())
+
+		if len(w.current.txs) != len(tt.want) {
+			t.Errorf("ordering %q: included transaction count mismatch: have %d, want %d", tt.ordering, len(w.current.txs), len(tt.want))
+		} else {
+			for i, tx := range tt.want {
+				if w.current.txs[i].Hash() != tx.Hash() {
+					t.Errorf("ordering %q: transaction %d mismatch: have %x, want %x", tt.ordering, i, w.current.txs[i].Hash(), tx.Hash())
+				}
+			}
+		}
+		w.close()
+		engine.Close()
+	}
+}
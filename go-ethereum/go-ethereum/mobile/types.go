@@ -245,20 +245,21 @@ func NewTransaction(nonce int64, to *Address, amount *BigInt, gasLimit int64, ga
 	return &Transaction{types.NewTransaction(uint64(nonce), to.address, amount.bigint, uint64(gasLimit), gasPrice.bigint, common.CopyBytes(data))}
 }
 
-// NewTransactionFromRLP parses a transaction from an RLP data dump.
+// NewTransactionFromRLP parses a transaction from its canonical encoding: an RLP
+// data dump for legacy transactions, the type and payload for typed ones.
 func NewTransactionFromRLP(data []byte) (*Transaction, error) {
 	tx := &Transaction{
 		tx: new(types.Transaction),
 	}
-	if err := rlp.DecodeBytes(common.CopyBytes(data), tx.tx); err != nil {
+	if err := tx.tx.UnmarshalBinary(common.CopyBytes(data)); err != nil {
 		return nil, err
 	}
 	return tx, nil
 }
 
-// EncodeRLP encodes a transaction into an RLP data dump.
+// EncodeRLP encodes a transaction into its canonical encoding.
 func (tx *Transaction) EncodeRLP() ([]byte, error) {
-	return rlp.EncodeToBytes(tx.tx)
+	return tx.tx.MarshalBinary()
 }
 
 // NewTransactionFromJSON parses a transaction from a JSON data dump.
@@ -299,7 +300,7 @@ func (tx *Transaction) GetSigHash() *Hash { return &Hash{types.HomesteadSigner{}
 func (tx *Transaction) GetFrom(chainID *BigInt) (address *Address, _ error) {
 	var signer types.Signer = types.HomesteadSigner{}
 	if chainID != nil {
-		signer = types.NewEIP155Signer(chainID.bigint)
+		signer = types.LatestSignerForChainID(chainID.bigint)
 	}
 	from, err := types.Sender(signer, tx.tx)
 	return &Address{from}, err
@@ -315,7 +316,7 @@ func (tx *Transaction) GetTo() *Address {
 func (tx *Transaction) WithSignature(sig []byte, chainID *BigInt) (signedTx *Transaction, _ error) {
 	var signer types.Signer = types.HomesteadSigner{}
 	if chainID != nil {
-		signer = types.NewEIP155Signer(chainID.bigint)
+		signer = types.LatestSignerForChainID(chainID.bigint)
 	}
 	rawTx, err := tx.tx.WithSignature(signer, common.CopyBytes(sig))
 	return &Transaction{rawTx}, err
@@ -342,20 +343,21 @@ type Receipt struct {
 	receipt *types.Receipt
 }
 
-// NewReceiptFromRLP parses a transaction receipt from an RLP data dump.
+// NewReceiptFromRLP parses a transaction receipt from its canonical consensus
+// encoding.
 func NewReceiptFromRLP(data []byte) (*Receipt, error) {
 	r := &Receipt{
 		receipt: new(types.Receipt),
 	}
-	if err := rlp.DecodeBytes(common.CopyBytes(data), r.receipt); err != nil {
+	if err := r.receipt.UnmarshalBinary(common.CopyBytes(data)); err != nil {
 		return nil, err
 	}
 	return r, nil
 }
 
-// EncodeRLP encodes a transaction receipt into an RLP data dump.
+// EncodeRLP encodes a transaction receipt into its canonical consensus encoding.
 func (r *Receipt) EncodeRLP() ([]byte, error) {
-	return rlp.EncodeToBytes(r.receipt)
+	return r.receipt.MarshalBinary()
 }
 
 // NewReceiptFromJSON parses a transaction receipt from a JSON data dump.
@@ -30,11 +30,15 @@ const (
 	CallNewAccountGas     uint64 = 25000 // Paid for CALL when the destination address didn't exist prior.
 	TxGas                 uint64 = 21000 // Per transaction not creating a contract. NOTE: Not payable on data of calls between transactions.
 	TxGasContractCreation uint64 = 53000 // Per transaction that creates a contract. NOTE: Not payable on data of calls between transactions.
+	TxGroupAuthGas        uint64 = 90000 // Per transaction authorized by a group signature, covering the pairing based verification.
 	TxDataZeroGas         uint64 = 4     // Per byte of data attached to a transaction that equals zero. NOTE: Not payable on data of calls between transactions.
 	QuadCoeffDiv          uint64 = 512   // Divisor for the quadratic particle of the memory cost equation.
 	LogDataGas            uint64 = 8     // Per byte in a LOG* operation's data.
 	CallStipend           uint64 = 2300  // Free gas given at beginning of call.
 
+	TxAccessListAddressGas    uint64 = 2400 // Per address specified in EIP 2930 access list
+	TxAccessListStorageKeyGas uint64 = 1900 // Per storage key specified in EIP 2930 access list
+
 	Sha3Gas     uint64 = 30 // Once per SHA3 operation.
 	Sha3WordGas uint64 = 6  // Once per word of the SHA3 operation's data.
 
@@ -136,6 +140,9 @@ const (
 	Bls12381MapG2Gas          uint64 = 110000 // Gas price for BLS12-381 mapping field element to G2 operation
 	VeriGroupsign             uint64 = 10     //gyh
 	VerProofGas            	  uint64 = 10     //gyh
+
+	ReceiptProofBaseGas    uint64 = 5000 // Base price for a receipt inclusion proof verification
+	ReceiptProofPerWordGas uint64 = 12   // Per-word price for a receipt inclusion proof verification
 )
 
 // Gas discount table for BLS12-381 G1 and G2 multi exponentiation operations
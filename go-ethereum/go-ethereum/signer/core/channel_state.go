@@ -0,0 +1,273 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The EIP-712 domain of the XChannel contract, matching contracts/xchannel.
+const (
+	channelDomainName    = "XChannel"
+	channelDomainVersion = "1"
+)
+
+// ChannelState is an off-chain state of a payment channel of an XChannel
+// contract, as sent for signing with the application/x-channel-state content
+// type. It is signed as the EIP-712 typed data the contract verifies.
+type ChannelState struct {
+	ChainID  *math.HexOrDecimal256 `json:"chainId"`
+	Contract common.Address        `json:"contract"`
+	Channel  common.Hash           `json:"channel"`
+	PartyA   common.Address        `json:"partyA"`
+	PartyB   common.Address        `json:"partyB"`
+	Nonce    math.HexOrDecimal64   `json:"nonce"`
+	BalanceA *math.HexOrDecimal256 `json:"balanceA"`
+	BalanceB *math.HexOrDecimal256 `json:"balanceB"`
+	Locks    []ChannelStateLock    `json:"locks"`
+	Final    bool                  `json:"final"` // Whether the state closes the channel cooperatively
+}
+
+// ChannelStateLock is a pending hash-locked transfer of a channel state. Locks
+// expiring at block 0 fund the virtual channel with the hashlock as id instead.
+type ChannelStateLock struct {
+	Amount     *math.HexOrDecimal256 `json:"amount"`
+	Expiration math.HexOrDecimal64   `json:"expiration"`
+	Hashlock   common.Hash           `json:"hashlock"`
+	Payer      common.Address        `json:"payer"`
+}
+
+// ChannelLock is a pending transfer of a channel state, from the point of view
+// of the signer.
+type ChannelLock struct {
+	Incoming   bool         `json:"incoming"` // Whether the counterparty is the payer
+	Amount     *hexutil.Big `json:"amount"`
+	Expiration uint64       `json:"expiration"`
+	Hashlock   common.Hash  `json:"hashlock"`
+	Virtual    bool         `json:"virtual"` // Whether the lock funds the virtual channel with the hashlock as id
+}
+
+// ChannelStateInfo is a channel state decoded from the point of view of the
+// signer, for UIs to display and rules to enforce policies on.
+type ChannelStateInfo struct {
+	ChainID             *hexutil.Big   `json:"chainId"`
+	Contract            common.Address `json:"contract"`
+	Channel             common.Hash    `json:"channel"`
+	Party               string         `json:"party"` // "A" or "B"
+	Counterparty        common.Address `json:"counterparty"`
+	Nonce               uint64         `json:"nonce"`
+	Balance             *hexutil.Big   `json:"balance"`
+	CounterpartyBalance *hexutil.Big   `json:"counterpartyBalance"`
+	Incoming            *hexutil.Big   `json:"incoming"` // Total of the incoming locks
+	Outgoing            *hexutil.Big   `json:"outgoing"` // Total of the outgoing locks
+	Locks               []ChannelLock  `json:"locks"`
+	Final               bool           `json:"final"`
+}
+
+// UnmarshalChannelState decodes a channel state given either as a JSON object or
+// as the hex encoding of its JSON serialization, as sent by external signers.
+func UnmarshalChannelState(data interface{}) (*ChannelState, error) {
+	var blob []byte
+	switch v := data.(type) {
+	case string:
+		decoded, err := hexutil.Decode(v)
+		if err != nil {
+			return nil, err
+		}
+		blob = decoded
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		blob = encoded
+	default:
+		return nil, fmt.Errorf("input for %v must be an object or an hex-encoded string", ApplicationChannelState.Mime)
+	}
+	state := new(ChannelState)
+	if err := json.Unmarshal(blob, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// validate checks the state for fields missing or out of range.
+func (s *ChannelState) validate() error {
+	if s.ChainID == nil {
+		return errors.New("channel state chain id missing")
+	}
+	if s.BalanceA == nil || s.BalanceB == nil {
+		return errors.New("channel state balance missing")
+	}
+	if (*big.Int)(s.BalanceA).Sign() < 0 || (*big.Int)(s.BalanceB).Sign() < 0 {
+		return errors.New("negative channel state balance")
+	}
+	if s.PartyA == (common.Address{}) || s.PartyB == (common.Address{}) || s.PartyA == s.PartyB {
+		return errors.New("invalid channel parties")
+	}
+	if s.Final && len(s.Locks) > 0 {
+		return errors.New("final channel state with pending locks")
+	}
+	for i, lock := range s.Locks {
+		if lock.Amount == nil || (*big.Int)(lock.Amount).Sign() <= 0 {
+			return fmt.Errorf("channel lock %d: invalid amount", i)
+		}
+		if lock.Payer != s.PartyA && lock.Payer != s.PartyB {
+			return fmt.Errorf("channel lock %d: payer %x not a party", i, lock.Payer)
+		}
+	}
+	return nil
+}
+
+// Info decodes the state from the point of view of one of its parties.
+func (s *ChannelState) Info(signer common.Address) (*ChannelStateInfo, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	info := &ChannelStateInfo{
+		ChainID:  (*hexutil.Big)(s.ChainID),
+		Contract: s.Contract,
+		Channel:  s.Channel,
+		Nonce:    uint64(s.Nonce),
+		Incoming: new(hexutil.Big),
+		Outgoing: new(hexutil.Big),
+		Locks:    make([]ChannelLock, 0, len(s.Locks)),
+		Final:    s.Final,
+	}
+	switch signer {
+	case s.PartyA:
+		info.Party, info.Counterparty = "A", s.PartyB
+		info.Balance, info.CounterpartyBalance = (*hexutil.Big)(s.BalanceA), (*hexutil.Big)(s.BalanceB)
+	case s.PartyB:
+		info.Party, info.Counterparty = "B", s.PartyA
+		info.Balance, info.CounterpartyBalance = (*hexutil.Big)(s.BalanceB), (*hexutil.Big)(s.BalanceA)
+	default:
+		return nil, fmt.Errorf("signer %x not a party of the channel", signer)
+	}
+	for _, lock := range s.Locks {
+		incoming := lock.Payer != signer
+		if incoming {
+			info.Incoming.ToInt().Add(info.Incoming.ToInt(), (*big.Int)(lock.Amount))
+		} else {
+			info.Outgoing.ToInt().Add(info.Outgoing.ToInt(), (*big.Int)(lock.Amount))
+		}
+		info.Locks = append(info.Locks, ChannelLock{
+			Incoming:   incoming,
+			Amount:     (*hexutil.Big)(lock.Amount),
+			Expiration: uint64(lock.Expiration),
+			Hashlock:   lock.Hashlock,
+			Virtual:    lock.Expiration == 0,
+		})
+	}
+	return info, nil
+}
+
+// locksRoot returns the hash committing the state to its locks, the keccak256
+// hash of the abi encoding of each lock concatenated, or zero without locks.
+func (s *ChannelState) locksRoot() common.Hash {
+	if len(s.Locks) == 0 {
+		return common.Hash{}
+	}
+	blob := make([]byte, 0, 128*len(s.Locks))
+	for _, lock := range s.Locks {
+		blob = append(blob, math.U256Bytes(new(big.Int).Set((*big.Int)(lock.Amount)))...)
+		blob = append(blob, common.LeftPadBytes(new(big.Int).SetUint64(uint64(lock.Expiration)).Bytes(), 32)...)
+		blob = append(blob, lock.Hashlock[:]...)
+		blob = append(blob, common.LeftPadBytes(lock.Payer[:], 32)...)
+	}
+	return crypto.Keccak256Hash(blob)
+}
+
+// ToTypedData converts the state to the EIP-712 typed data signed for it, the
+// State type or, for final states, the Close type.
+func (s *ChannelState) ToTypedData() TypedData {
+	typedData := TypedData{
+		Types: Types{
+			"EIP712Domain": []Type{
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+		},
+		Domain: TypedDataDomain{
+			Name:              channelDomainName,
+			Version:           channelDomainVersion,
+			ChainId:           s.ChainID,
+			VerifyingContract: s.Contract.Hex(),
+		},
+		Message: TypedDataMessage{
+			"channel":  s.Channel.Hex(),
+			"nonce":    fmt.Sprintf("%d", uint64(s.Nonce)),
+			"balanceA": (*big.Int)(s.BalanceA).String(),
+			"balanceB": (*big.Int)(s.BalanceB).String(),
+		},
+	}
+	fields := []Type{
+		{Name: "channel", Type: "bytes32"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "balanceA", Type: "uint256"},
+		{Name: "balanceB", Type: "uint256"},
+	}
+	if s.Final {
+		typedData.PrimaryType = "Close"
+	} else {
+		typedData.PrimaryType = "State"
+		fields = append(fields, Type{Name: "locksRoot", Type: "bytes32"})
+		typedData.Message["locksRoot"] = s.locksRoot().Hex()
+	}
+	typedData.Types[typedData.PrimaryType] = fields
+	return typedData
+}
+
+// Messages returns the state formatted for display, from the point of view of
+// the signer.
+func (info *ChannelStateInfo) Messages() []*NameValueType {
+	description := "This is a request to sign an off-chain payment channel state"
+	if info.Final {
+		description = "This is a request to sign the final state of a payment channel, closing it cooperatively"
+	}
+	messages := []*NameValueType{
+		{Name: description, Typ: "description", Value: ""},
+		{Name: "Channel", Typ: "bytes32", Value: info.Channel.Hex()},
+		{Name: "Channel contract", Typ: "address", Value: fmt.Sprintf("%s (chain %v)", info.Contract.Hex(), info.ChainID.ToInt())},
+		{Name: "Counterparty", Typ: "address", Value: info.Counterparty.Hex()},
+		{Name: "Nonce", Typ: "uint64", Value: fmt.Sprintf("%d", info.Nonce)},
+		{Name: "Your balance", Typ: "uint256", Value: info.Balance.ToInt().String()},
+		{Name: "Counterparty balance", Typ: "uint256", Value: info.CounterpartyBalance.ToInt().String()},
+	}
+	for _, lock := range info.Locks {
+		name := "Outgoing lock"
+		if lock.Incoming {
+			name = "Incoming lock"
+		}
+		value := fmt.Sprintf("%v wei, hashlock %s, expires at block %d", lock.Amount.ToInt(), lock.Hashlock.Hex(), lock.Expiration)
+		if lock.Virtual {
+			value = fmt.Sprintf("%v wei, funding virtual channel %s", lock.Amount.ToInt(), lock.Hashlock.Hex())
+		}
+		messages = append(messages, &NameValueType{Name: name, Typ: "lock", Value: value})
+	}
+	return messages
+}
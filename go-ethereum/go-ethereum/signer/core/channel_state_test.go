@@ -0,0 +1,180 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core"
+)
+
+var (
+	channelPartyA = common.HexToAddress("0xa11ce00000000000000000000000000000000000")
+	channelPartyB = common.HexToAddress("0xb0b0000000000000000000000000000000000000")
+)
+
+// channelState returns a state of a channel between the given parties, with an
+// outgoing and an incoming lock for partyA.
+func channelState(partyA, partyB common.Address) *core.ChannelState {
+	return &core.ChannelState{
+		ChainID:  math.NewHexOrDecimal256(1337),
+		Contract: common.HexToAddress("0xc0ffee0000000000000000000000000000000000"),
+		Channel:  common.HexToHash("0xc4a77e1"),
+		PartyA:   partyA,
+		PartyB:   partyB,
+		Nonce:    7,
+		BalanceA: (*math.HexOrDecimal256)(big.NewInt(600)),
+		BalanceB: (*math.HexOrDecimal256)(big.NewInt(300)),
+		Locks: []core.ChannelStateLock{
+			{Amount: math.NewHexOrDecimal256(50), Expiration: 100, Hashlock: common.HexToHash("0x01"), Payer: partyA},
+			{Amount: math.NewHexOrDecimal256(50), Expiration: 0, Hashlock: common.HexToHash("0x02"), Payer: partyB},
+		},
+	}
+}
+
+// typedDataEncoding returns the EIP-712 encoding signed for some typed data.
+func typedDataEncoding(t *testing.T, typedData core.TypedData) []byte {
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		t.Fatalf("failed to hash domain: %v", err)
+	}
+	typedDataHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		t.Fatalf("failed to hash message: %v", err)
+	}
+	return []byte(fmt.Sprintf("\x19\x01%s%s", string(domainSeparator), string(typedDataHash)))
+}
+
+// Tests that the typed data of channel states encodes to what the channel
+// contract verifies signatures on.
+func TestChannelStateTypedData(t *testing.T) {
+	state := channelState(channelPartyA, channelPartyB)
+
+	var locks []xchannel.Lock
+	for _, lock := range state.Locks {
+		locks = append(locks, xchannel.Lock{
+			Amount:     (*big.Int)(lock.Amount),
+			Expiration: uint64(lock.Expiration),
+			Hashlock:   lock.Hashlock,
+			Payer:      lock.Payer,
+		})
+	}
+	domain := xchannel.Domain{ChainID: big.NewInt(1337), Contract: state.Contract}
+
+	want := domain.StateData(state.Channel, 7, big.NewInt(600), big.NewInt(300), xchannel.LocksRoot(locks))
+	if have := typedDataEncoding(t, state.ToTypedData()); !bytes.Equal(have, want) {
+		t.Errorf("state encoding mismatch:\nhave %x\nwant %x", have, want)
+	}
+	state.Locks, state.Final = nil, true
+
+	want = domain.CloseData(state.Channel, 7, big.NewInt(600), big.NewInt(300))
+	if have := typedDataEncoding(t, state.ToTypedData()); !bytes.Equal(have, want) {
+		t.Errorf("close encoding mismatch:\nhave %x\nwant %x", have, want)
+	}
+}
+
+// Tests that channel states are decoded from the point of view of the signer.
+func TestChannelStateInfo(t *testing.T) {
+	state := channelState(channelPartyA, channelPartyB)
+
+	info, err := state.Info(channelPartyB)
+	if err != nil {
+		t.Fatalf("failed to decode state: %v", err)
+	}
+	if info.Party != "B" || info.Counterparty != channelPartyA || info.Nonce != 7 {
+		t.Errorf("party mismatch: have %s/%x/%d", info.Party, info.Counterparty, info.Nonce)
+	}
+	if info.Balance.ToInt().Int64() != 300 || info.CounterpartyBalance.ToInt().Int64() != 600 {
+		t.Errorf("balance mismatch: have %v/%v, want 300/600", info.Balance, info.CounterpartyBalance)
+	}
+	if info.Incoming.ToInt().Int64() != 50 || info.Outgoing.ToInt().Int64() != 50 {
+		t.Errorf("lock totals mismatch: have %v/%v, want 50/50", info.Incoming, info.Outgoing)
+	}
+	if !info.Locks[0].Incoming || info.Locks[0].Virtual || info.Locks[1].Incoming || !info.Locks[1].Virtual {
+		t.Errorf("lock mismatch: %+v", info.Locks)
+	}
+	// States not involving the signer, or malformed, must be refused
+	if _, err := state.Info(common.HexToAddress("0xdead")); err == nil {
+		t.Errorf("decoded state for a foreign signer")
+	}
+	state.Locks[0].Payer = common.HexToAddress("0xdead")
+	if _, err := state.Info(channelPartyA); err == nil {
+		t.Errorf("decoded state with a lock paid by a stranger")
+	}
+	state = channelState(channelPartyA, channelPartyB)
+	state.Final = true
+	if _, err := state.Info(channelPartyA); err == nil {
+		t.Errorf("decoded final state with pending locks")
+	}
+}
+
+func TestSignChannelState(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control.approveCh <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.NewMixedcaseAddress(list[0])
+	state := channelState(list[0], channelPartyB)
+
+	// Sign the state given as the hex encoding of its JSON, as external signers
+	// send it
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	signature, err := api.SignData(context.Background(), core.ApplicationChannelState.Mime, a, hexutil.Encode(mustMarshal(t, state)))
+	if err != nil {
+		t.Fatalf("failed to sign channel state: %v", err)
+	}
+	if signature[64] != 27 && signature[64] != 28 {
+		t.Fatalf("invalid recovery id: %d", signature[64])
+	}
+	signature[64] -= 27
+
+	digest := crypto.Keccak256(typedDataEncoding(t, state.ToTypedData()))
+	pub, err := crypto.SigToPub(digest, signature)
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pub); signer != list[0] {
+		t.Errorf("signer mismatch: have %x, want %x", signer, list[0])
+	}
+	// States of channels the account isn't a party of are refused without prompt
+	if _, err := api.SignData(context.Background(), core.ApplicationChannelState.Mime, a, hexutil.Encode(mustMarshal(t, channelState(channelPartyA, channelPartyB)))); err == nil {
+		t.Errorf("signed state of a foreign channel")
+	}
+}
+
+// mustMarshal returns the JSON encoding of a value, failing the test on error.
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	blob, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return blob
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// FeePayerData is the data a fee payer signs to sponsor a transaction, as sent
+// for signing with the application/x-fee-payer content type: the sender's
+// authorization of the transaction together with the gas price and limit.
+type FeePayerData struct {
+	SigHash  common.Hash // Hash signed by the sender
+	V, R, S  *big.Int    // Signature of the sender, zero if group authorized
+	GasPrice *big.Int
+	Gas      uint64
+	Group    []rlp.RawValue `rlp:"tail"` // Group authorization of anonymous senders
+}
+
+// UnmarshalFeePayerData decodes the hex encoded RLP fee payer data.
+func UnmarshalFeePayerData(data interface{}) (*FeePayerData, []byte, error) {
+	stringData, ok := data.(string)
+	if !ok {
+		return nil, nil, fmt.Errorf("input for %v must be an hex-encoded string", ApplicationFeePayer.Mime)
+	}
+	blob, err := hexutil.Decode(stringData)
+	if err != nil {
+		return nil, nil, err
+	}
+	payer := new(FeePayerData)
+	if err := rlp.DecodeBytes(blob, payer); err != nil {
+		return nil, nil, err
+	}
+	return payer, blob, nil
+}
+
+// Sender recovers the address of the sponsored sender, if it's authorized by a
+// signature rather than a group signature.
+func (d *FeePayerData) Sender() (common.Address, bool) {
+	if len(d.Group) > 0 || d.V == nil || d.R == nil || d.S == nil {
+		return common.Address{}, false
+	}
+	// Sponsored transactions use 0 and 1 as their recovery id
+	if !d.V.IsUint64() || d.V.Uint64() > 1 || !crypto.ValidateSignatureValues(byte(d.V.Uint64()), d.R, d.S, true) {
+		return common.Address{}, false
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig[32-len(d.R.Bytes()):32], d.R.Bytes())
+	copy(sig[64-len(d.S.Bytes()):64], d.S.Bytes())
+	sig[64] = byte(d.V.Uint64())
+
+	pub, err := crypto.SigToPub(d.SigHash[:], sig)
+	if err != nil {
+		return common.Address{}, false
+	}
+	return crypto.PubkeyToAddress(*pub), true
+}
+
+// Messages returns the fee payer data in a human readable form for UIs.
+func (d *FeePayerData) Messages() []*NameValueType {
+	sender := "anonymous (group authorized)"
+	if addr, ok := d.Sender(); ok {
+		sender = addr.Hex()
+	}
+	fee := new(big.Int).Mul(d.GasPrice, new(big.Int).SetUint64(d.Gas))
+	return []*NameValueType{
+		{
+			Name:  "This is a request to pay the gas of a transaction sent by another account",
+			Typ:   "description",
+			Value: "",
+		},
+		{Name: "Sender", Typ: "address", Value: sender},
+		{Name: "Transaction hash signed by the sender", Typ: "hash", Value: d.SigHash.Hex()},
+		{Name: "Gas price", Typ: "uint256", Value: d.GasPrice.String()},
+		{Name: "Gas limit", Typ: "uint64", Value: fmt.Sprintf("%d", d.Gas)},
+		{Name: "Maximum fee", Typ: "uint256", Value: fee.String()},
+	}
+}
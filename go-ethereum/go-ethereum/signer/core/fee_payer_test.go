@@ -0,0 +1,75 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core"
+)
+
+func TestSignFeePayer(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control.approveCh <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sign a sponsored transaction as its sender, with the fee parameters set
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(1337))
+
+	tx, err := types.SignTx(types.NewSponsoredTransaction(0, common.Address{0x01}, big.NewInt(1), 0, nil, nil), signer, key)
+	if err != nil {
+		t.Fatalf("failed to sign sponsored transaction: %v", err)
+	}
+	if tx, err = tx.WithFeeParams(21000, big.NewInt(10)); err != nil {
+		t.Fatalf("failed to set fee parameters: %v", err)
+	}
+	data := types.FeePayerSigningData(signer, tx)
+
+	payer, _, err := core.UnmarshalFeePayerData(hexutil.Encode(data))
+	if err != nil {
+		t.Fatalf("failed to decode fee payer data: %v", err)
+	}
+	if sender, ok := payer.Sender(); !ok || sender != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("sponsored sender mismatch: have %x, want %x", sender, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	// Sign the fee payer data through the signer and attach it to the transaction
+	control.approveCh <- "Y"
+	control.inputCh <- "a_long_password"
+	signature, err := api.SignData(context.Background(), core.ApplicationFeePayer.Mime, common.NewMixedcaseAddress(list[0]), hexutil.Encode(data))
+	if err != nil {
+		t.Fatalf("failed to sign fee payer data: %v", err)
+	}
+	if signature[64] > 1 {
+		t.Fatalf("invalid recovery id: %d", signature[64])
+	}
+	if tx, err = tx.WithFeePayerSignature(signer, signature); err != nil {
+		t.Fatalf("failed to attach fee payer signature: %v", err)
+	}
+	if have, err := types.FeePayer(signer, tx); err != nil || have != list[0] {
+		t.Errorf("fee payer mismatch: have %x (%v), want %x", have, err, list[0])
+	}
+}
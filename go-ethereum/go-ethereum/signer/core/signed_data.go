@@ -58,6 +58,14 @@ var (
 		accounts.MimetypeClique,
 		0x02,
 	}
+	ApplicationChannelState = SigFormat{
+		accounts.MimetypeChannelState,
+		0x01,
+	}
+	ApplicationFeePayer = SigFormat{
+		accounts.MimetypeFeePayer,
+		0x03,
+	}
 	TextPlain = SigFormat{
 		accounts.MimetypeTextPlain,
 		0x45,
@@ -258,6 +266,44 @@ func (api *SignerAPI) determineSignatureFormat(ctx context.Context, contentType
 		// Clique uses V on the form 0 or 1
 		useEthereumV = false
 		req = &SignDataRequest{ContentType: mediaType, Rawdata: cliqueRlp, Messages: messages, Hash: sighash}
+	case ApplicationChannelState.Mime:
+		// Payment channel states are EIP-712 typed data decoded for display
+		state, err := UnmarshalChannelState(data)
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		info, err := state.Info(addr.Address())
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		typedData := state.ToTypedData()
+		domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		typedDataHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		rawData := []byte(fmt.Sprintf("\x19\x01%s%s", string(domainSeparator), string(typedDataHash)))
+		typedMessages, err := typedData.Format()
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		messages := append(info.Messages(), typedMessages...)
+		req = &SignDataRequest{ContentType: mediaType, Rawdata: rawData, Messages: messages, Hash: crypto.Keccak256(rawData), ChannelState: info}
+	case ApplicationFeePayer.Mime:
+		// Sponsored transactions are authorized by the fee payer signing their fee data
+		payer, rawData, err := UnmarshalFeePayerData(data)
+		if err != nil {
+			return nil, useEthereumV, err
+		}
+		if payer.GasPrice == nil {
+			return nil, useEthereumV, fmt.Errorf("fee payer gas price missing")
+		}
+		// Fee payer signatures use V on the form 0 or 1, as transactions do
+		useEthereumV = false
+		req = &SignDataRequest{ContentType: mediaType, Rawdata: rawData, Messages: payer.Messages(), Hash: crypto.Keccak256(rawData)}
 	default: // also case TextPlain.Mime:
 		// Calculates an Ethereum ECDSA signature for:
 		// hash = keccak256("\x19${byteVersion}Ethereum Signed Message:\n${message length}${message}")
@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/signer/core"
@@ -625,3 +626,132 @@ function ApproveSignData(r){
 		t.Fatalf("Expected approved")
 	}
 }
+
+const ExampleChannelPolicy = `
+	function big(str){
+		if(str.slice(0,2) == "0x"){ return new BigNumber(str.slice(2),16)}
+		return new BigNumber(str)
+	}
+
+	function ApproveSignData(r){
+		var state = r.channel_state
+		if(!state){
+			return "Manual"
+		}
+		var key = "channel/" + state.channel
+		var stored = storage.get(key)
+
+		// Incoming locks of other channels our new outgoing locks forward
+		var forwarded = []
+		if(stored != ""){
+			var last = JSON.parse(stored)
+
+			// Never sign a state older than one signed before
+			if(state.nonce < last.nonce){
+				return "Reject"
+			}
+			// Never let our balance go down, unless by new outgoing locks
+			// matching incoming locks of at least the same amount
+			var covered = new BigNumber(0)
+			state.locks.forEach(function(lock){
+				if(lock.incoming || last.locks.indexOf(lock.hashlock) >= 0){
+					return
+				}
+				var incoming = storage.get("incoming/" + lock.hashlock)
+				if(incoming != "" && big(incoming).gte(big(lock.amount))){
+					covered = covered.plus(big(lock.amount))
+					forwarded.push(lock.hashlock)
+				}
+			})
+			if(big(state.balance).plus(covered).lt(big(last.balance))){
+				return "Reject"
+			}
+		}
+		// Approved, each incoming lock can be forwarded once
+		forwarded.forEach(function(hashlock){ storage.put("incoming/" + hashlock, "") })
+
+		var outgoing = []
+		state.locks.forEach(function(lock){
+			if(lock.incoming){
+				storage.put("incoming/" + lock.hashlock, lock.amount)
+			} else {
+				outgoing.push(lock.hashlock)
+			}
+		})
+		storage.put(key, JSON.stringify({nonce: state.nonce, balance: state.balance, locks: outgoing}))
+		return "Approve"
+	}
+`
+
+func TestChannelStatePolicy(t *testing.T) {
+	r, err := initRuleEngine(ExampleChannelPolicy)
+	if err != nil {
+		t.Fatalf("Couldn't create evaluator %v", err)
+	}
+	var (
+		hub, _   = mixAddr("0x694267f14675d7e1b9494fd8d72fefe1755710fa")
+		payer    = common.HexToAddress("0xa11ce00000000000000000000000000000000000")
+		payee    = common.HexToAddress("0xb0b0000000000000000000000000000000000000")
+		inbound  = common.HexToHash("0x01")  // Channel from the payer to the hub
+		outbound = common.HexToHash("0x02")  // Channel from the hub to the payee
+		other    = common.HexToHash("0x03")  // Another channel from the hub to the payee
+		forward  = common.HexToHash("0xf0")  // Hashlock of the payment routed through the hub
+		bogus    = common.HexToHash("0xbad") // Hashlock of no incoming payment
+	)
+	lock := func(hashlock common.Hash, payer common.Address) core.ChannelStateLock {
+		return core.ChannelStateLock{Amount: math.NewHexOrDecimal256(1), Expiration: 100, Hashlock: hashlock, Payer: payer}
+	}
+	tests := []struct {
+		channel  common.Hash
+		partyA   common.Address
+		nonce    uint64
+		balance  int64 // Balance of the hub
+		locks    []core.ChannelStateLock
+		approved bool
+	}{
+		// First state of a channel
+		{outbound, payee, 1, 10, nil, true},
+		// Paying without incoming payment
+		{outbound, payee, 2, 9, nil, false},
+		// Receiving a locked payment in the other channel
+		{inbound, payer, 1, 5, []core.ChannelStateLock{lock(forward, payer)}, true},
+		// Forwarding it
+		{outbound, payee, 2, 9, []core.ChannelStateLock{lock(forward, hub.Address())}, true},
+		// Signing an older state
+		{outbound, payee, 1, 10, nil, false},
+		// Adding a lock forwarding no incoming payment
+		{outbound, payee, 3, 8, []core.ChannelStateLock{lock(forward, hub.Address()), lock(bogus, hub.Address())}, false},
+		// Forwarding the same incoming payment over another channel
+		{other, payee, 1, 10, nil, true},
+		{other, payee, 2, 9, []core.ChannelStateLock{lock(forward, hub.Address())}, false},
+		// Settling the forwarded lock to the payee
+		{outbound, payee, 3, 9, nil, true},
+	}
+	for i, tt := range tests {
+		state := &core.ChannelState{
+			ChainID:  math.NewHexOrDecimal256(1),
+			Channel:  tt.channel,
+			PartyA:   tt.partyA,
+			PartyB:   hub.Address(),
+			Nonce:    math.HexOrDecimal64(tt.nonce),
+			BalanceA: math.NewHexOrDecimal256(10),
+			BalanceB: math.NewHexOrDecimal256(tt.balance),
+			Locks:    tt.locks,
+		}
+		info, err := state.Info(hub.Address())
+		if err != nil {
+			t.Fatalf("test %d: failed to decode state: %v", i, err)
+		}
+		resp, err := r.ApproveSignData(&core.SignDataRequest{
+			ContentType:  core.ApplicationChannelState.Mime,
+			Address:      *hub,
+			ChannelState: info,
+		})
+		if err != nil {
+			t.Fatalf("test %d: unexpected error %v", i, err)
+		}
+		if resp.Approved != tt.approved {
+			t.Errorf("test %d: approval mismatch: have %v, want %v", i, resp.Approved, tt.approved)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package swap
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// PrivateSwapAPI provides access to the atomic swaps of the local account.
+type PrivateSwapAPI struct {
+	e *Engine
+}
+
+// NewPrivateSwapAPI creates a new swap API.
+func NewPrivateSwapAPI(e *Engine) *PrivateSwapAPI {
+	return &PrivateSwapAPI{e: e}
+}
+
+// InitiateArgs are the arguments to initiate a swap with.
+type InitiateArgs struct {
+	ChainA      hexutil.Uint64 `json:"chainA"`
+	ChainB      hexutil.Uint64 `json:"chainB"`
+	Participant common.Address `json:"participant"`
+	AmountA     *hexutil.Big   `json:"amountA"`
+	AmountB     *hexutil.Big   `json:"amountB"`
+	Timeout     hexutil.Uint64 `json:"timeout"` // Seconds the participant has to lock its funds
+}
+
+// Initiate starts a swap, returning the terms to hand to the participant.
+func (api *PrivateSwapAPI) Initiate(ctx context.Context, args InitiateArgs) (*Terms, error) {
+	return api.e.Initiate(ctx, uint64(args.ChainA), uint64(args.ChainB), args.Participant,
+		(*big.Int)(args.AmountA), (*big.Int)(args.AmountB), time.Duration(args.Timeout)*time.Second)
+}
+
+// Participate joins a swap on the terms handed over by its initiator, returning
+// the id of the swap.
+func (api *PrivateSwapAPI) Participate(ctx context.Context, terms Terms) (common.Hash, error) {
+	if err := api.e.Participate(ctx, &terms); err != nil {
+		return common.Hash{}, err
+	}
+	return terms.Hashlock, nil
+}
+
+// RPCSwap is a swap as reported over RPC.
+type RPCSwap struct {
+	ID     common.Hash  `json:"id"`
+	Role   Role         `json:"role"`
+	Status Status       `json:"status"`
+	Terms  *Terms       `json:"terms"`
+	TxA    *common.Hash `json:"txA"` // Transaction awaiting inclusion on chain A
+	TxB    *common.Hash `json:"txB"` // Transaction awaiting inclusion on chain B
+}
+
+// newRPCSwap returns the RPC representation of a swap. The secret is left out,
+// the initiator revealing it being what completes the swap.
+func newRPCSwap(s *Swap) *RPCSwap {
+	swap := &RPCSwap{
+		ID:     s.ID(),
+		Role:   s.Role,
+		Status: s.Status,
+		Terms:  &s.Terms,
+	}
+	if s.TxA != (common.Hash{}) {
+		swap.TxA = &s.TxA
+	}
+	if s.TxB != (common.Hash{}) {
+		swap.TxB = &s.TxB
+	}
+	return swap
+}
+
+// Status returns the swap with the given id.
+func (api *PrivateSwapAPI) Status(id common.Hash) (*RPCSwap, error) {
+	s, err := api.e.Swap(id)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCSwap(s), nil
+}
+
+// List returns all the swaps of the local account.
+func (api *PrivateSwapAPI) List() []*RPCSwap {
+	swaps := api.e.Swaps()
+
+	list := make([]*RPCSwap, 0, len(swaps))
+	for _, s := range swaps {
+		list = append(list, newRPCSwap(s))
+	}
+	return list
+}
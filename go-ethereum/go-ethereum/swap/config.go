@@ -0,0 +1,58 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package swap
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultConfig contains the default settings of the swap engine.
+var DefaultConfig = Config{
+	Confirmations: 12,
+	MinDelta:      time.Hour,
+	PollInterval:  5 * time.Second,
+}
+
+// ChainConfig contains the settings of a chain swaps are made on.
+type ChainConfig struct {
+	// Endpoint is the RPC endpoint of a node of the chain.
+	Endpoint string
+
+	// Contract is the address of the HTLC contract used on the chain. One is
+	// deployed from the local account if unset.
+	Contract common.Address `toml:",omitempty"`
+}
+
+// Config contains the configuration options of the swap engine.
+type Config struct {
+	// Chains are the chains swaps can be made across.
+	Chains []ChainConfig
+
+	// Confirmations is the number of blocks the funds locked by the counterparty
+	// must be buried under before acting on them.
+	Confirmations uint64
+
+	// MinDelta is the minimum time between the timelocks on chain B and chain A
+	// a participant accepts, bounding the time it has left to claim on chain A
+	// once the secret is revealed on chain B.
+	MinDelta time.Duration
+
+	// PollInterval is the interval the chains are polled for swap progress at.
+	PollInterval time.Duration
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package swap
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	swapPrefix     = []byte("s") // swapPrefix + hashlock -> swap
+	contractPrefix = []byte("c") // contractPrefix + chain id -> deployed HTLC contract
+)
+
+// swapKey = swapPrefix + hashlock
+func swapKey(id common.Hash) []byte {
+	return append(append([]byte{}, swapPrefix...), id[:]...)
+}
+
+// contractKey = contractPrefix + chain id (uint64 big endian)
+func contractKey(chainID uint64) []byte {
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], chainID)
+	return append(append([]byte{}, contractPrefix...), enc[:]...)
+}
+
+// readSwap retrieves the swap with the given id, nil if unknown.
+func readSwap(db ethdb.KeyValueReader, id common.Hash) *Swap {
+	data, _ := db.Get(swapKey(id))
+	if len(data) == 0 {
+		return nil
+	}
+	s := new(Swap)
+	if err := rlp.DecodeBytes(data, s); err != nil {
+		log.Error("Invalid swap RLP", "id", id, "err", err)
+		return nil
+	}
+	return s
+}
+
+// writeSwap stores a swap.
+func writeSwap(db ethdb.KeyValueWriter, s *Swap) {
+	data, err := rlp.EncodeToBytes(s)
+	if err != nil {
+		log.Crit("Failed to RLP encode swap", "err", err)
+	}
+	if err := db.Put(swapKey(s.ID()), data); err != nil {
+		log.Crit("Failed to store swap", "err", err)
+	}
+}
+
+// readSwaps retrieves all the stored swaps.
+func readSwaps(db ethdb.Iteratee) []*Swap {
+	it := db.NewIterator(swapPrefix, nil)
+	defer it.Release()
+
+	var swaps []*Swap
+	for it.Next() {
+		if len(it.Key()) != len(swapPrefix)+common.HashLength {
+			continue
+		}
+		s := new(Swap)
+		if err := rlp.DecodeBytes(it.Value(), s); err != nil {
+			log.Error("Invalid swap RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		swaps = append(swaps, s)
+	}
+	return swaps
+}
+
+// readContract retrieves the address of the HTLC contract deployed on a chain,
+// zero if none.
+func readContract(db ethdb.KeyValueReader, chainID uint64) common.Address {
+	data, _ := db.Get(contractKey(chainID))
+	return common.BytesToAddress(data)
+}
+
+// writeContract stores the address of the HTLC contract deployed on a chain.
+func writeContract(db ethdb.KeyValueWriter, chainID uint64, address common.Address) {
+	if err := db.Put(contractKey(chainID), address[:]); err != nil {
+		log.Crit("Failed to store contract address", "err", err)
+	}
+}
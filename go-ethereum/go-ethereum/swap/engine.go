@@ -0,0 +1,621 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package swap implements atomic swaps of ether across two chains through the
+// HTLC contract.
+//
+// The initiator of a swap generates a secret and locks its funds on chain A under
+// the hash of the secret. Once they are confirmed, the participant locks its own
+// funds on chain B under the same hashlock with a shorter timelock. The initiator
+// claims those, revealing the secret on chain B, which the participant then uses
+// to claim the funds on chain A. Either party gets its funds refunded once the
+// timelock passed if the swap doesn't go through.
+//
+// The engine drives the swaps of the local account from the on-chain state of
+// their two legs, persisting them so they survive restarts.
+package swap
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// stepTimeout is the maximum time a round of advancing the swaps may take.
+const stepTimeout = 30 * time.Second
+
+var (
+	errUnknownChain   = errors.New("unknown chain")
+	errSameChain      = errors.New("swap chains must differ")
+	errInvalidAmount  = errors.New("invalid swap amount")
+	errInvalidTimeout = errors.New("invalid swap timeout")
+	errNotParticipant = errors.New("local account not the swap participant")
+	errKnownSwap      = errors.New("swap already known")
+	errUnknownSwap    = errors.New("unknown swap")
+	errInvalidHTLC    = errors.New("not an HTLC contract")
+	errShortTimelocks = errors.New("timelocks too close to each other")
+	errExpired        = errors.New("swap timelock too close")
+)
+
+// Backend is the chain access needed by the swap engine.
+type Backend interface {
+	bind.ContractBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// chain is a chain swaps are made on.
+type chain struct {
+	id       *big.Int
+	backend  Backend
+	address  common.Address // Local HTLC contract, the one swaps are initiated in
+	contract *contract.HTLC
+}
+
+// leg is the on-chain state of one side of a swap.
+type leg struct {
+	status uint64
+	amount *big.Int
+	head   *types.Header // Chain head the state was retrieved at
+}
+
+// Engine drives the atomic swaps of the local account.
+type Engine struct {
+	config  *Config
+	db      ethdb.Database // Database holding the swaps
+	key     *ecdsa.PrivateKey
+	account common.Address
+	dial    func(endpoint string) (Backend, error)
+
+	chains map[uint64]*chain
+	lock   sync.Mutex // Lock serialising swap updates
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a swap engine operating the given key on chains reached through
+// their RPC endpoints, and registers it with the node.
+func New(stack *node.Node, config *Config, key *ecdsa.PrivateKey) (*Engine, error) {
+	db, err := stack.OpenDatabase("swaps", 16, 16, "swap/db/")
+	if err != nil {
+		return nil, err
+	}
+	dial := func(endpoint string) (Backend, error) {
+		return ethclient.Dial(endpoint)
+	}
+	e := newEngine(config, db, key, dial)
+
+	stack.RegisterAPIs(e.APIs())
+	stack.RegisterLifecycle(e)
+	return e, nil
+}
+
+// newEngine creates a swap engine operating the given key.
+func newEngine(config *Config, db ethdb.Database, key *ecdsa.PrivateKey, dial func(endpoint string) (Backend, error)) *Engine {
+	return &Engine{
+		config:  config,
+		db:      db,
+		key:     key,
+		account: crypto.PubkeyToAddress(key.PublicKey),
+		dial:    dial,
+		chains:  make(map[uint64]*chain),
+		quit:    make(chan struct{}),
+	}
+}
+
+// APIs returns the RPC APIs the swap engine offers.
+func (e *Engine) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "swap",
+			Version:   "1.0",
+			Service:   NewPrivateSwapAPI(e),
+			Public:    false,
+		},
+	}
+}
+
+// Start implements node.Lifecycle, connecting to the chains and starting to
+// drive the swaps.
+func (e *Engine) Start() error {
+	if err := e.setup(); err != nil {
+		return err
+	}
+	e.wg.Add(1)
+	go e.loop()
+
+	log.Info("Started swap engine", "account", e.account, "chains", len(e.chains))
+	return nil
+}
+
+// setup connects to the chains and binds their HTLC contracts, deploying those
+// not configured nor deployed before.
+func (e *Engine) setup() error {
+	ctx, cancel := context.WithTimeout(context.Background(), stepTimeout)
+	defer cancel()
+
+	for _, config := range e.config.Chains {
+		backend, err := e.dial(config.Endpoint)
+		if err != nil {
+			return err
+		}
+		id, err := backend.ChainID(ctx)
+		if err != nil {
+			return err
+		}
+		address := config.Contract
+		if address == (common.Address{}) {
+			address = readContract(e.db, id.Uint64())
+		} else if err := xchannel.CheckCode(ctx, backend, address, xchannel.HTLCCode); err == xchannel.ErrCodeMismatch {
+			log.Warn("Unknown HTLC contract deployed", "chain", id, "address", address, "version", xchannel.Version)
+		}
+		if address == (common.Address{}) {
+			opts, _ := bind.NewKeyedTransactorWithChainID(e.key, id)
+			opts.Context = ctx
+
+			if address, _, _, err = contract.DeployHTLC(opts, backend); err != nil {
+				return err
+			}
+			writeContract(e.db, id.Uint64(), address)
+			log.Info("Deployed HTLC contract", "chain", id, "address", address)
+		}
+		htlc, err := contract.NewHTLC(address, backend)
+		if err != nil {
+			return err
+		}
+		e.chains[id.Uint64()] = &chain{id: id, backend: backend, address: address, contract: htlc}
+	}
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the engine.
+func (e *Engine) Stop() error {
+	close(e.quit)
+	e.wg.Wait()
+
+	log.Info("Swap engine stopped")
+	return nil
+}
+
+// loop advances the active swaps periodically.
+func (e *Engine) loop() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.process()
+		case <-e.quit:
+			return
+		}
+	}
+}
+
+// process advances all the active swaps.
+func (e *Engine) process() {
+	ctx, cancel := context.WithTimeout(context.Background(), stepTimeout)
+	defer cancel()
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for _, s := range readSwaps(e.db) {
+		if s.Status != StatusActive {
+			continue
+		}
+		if err := e.step(ctx, s); err != nil {
+			log.Warn("Failed to advance swap", "id", s.ID(), "err", err)
+		}
+		writeSwap(e.db, s)
+	}
+}
+
+// Initiate starts a swap of the given amount on chain A against the given amount
+// of the participant on chain B. The participant has timeout to lock its funds
+// and the local account as long again to claim them, the funds on chain A being
+// locked for twice the timeout. The terms are returned for the participant to
+// join the swap with.
+func (e *Engine) Initiate(ctx context.Context, chainA, chainB uint64, participant common.Address, amountA, amountB *big.Int, timeout time.Duration) (*Terms, error) {
+	a, b := e.chains[chainA], e.chains[chainB]
+	if a == nil || b == nil {
+		return nil, errUnknownChain
+	}
+	if chainA == chainB {
+		return nil, errSameChain
+	}
+	if amountA == nil || amountA.Sign() <= 0 || amountB == nil || amountB.Sign() <= 0 {
+		return nil, errInvalidAmount
+	}
+	if timeout < e.config.MinDelta {
+		return nil, errInvalidTimeout
+	}
+	headA, err := a.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	headB, err := b.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	var secret common.Hash
+	if _, err := rand.Read(secret[:]); err != nil {
+		return nil, err
+	}
+	s := &Swap{
+		Terms: Terms{
+			Hashlock:    xchannel.Hashlock(secret),
+			Initiator:   e.account,
+			Participant: participant,
+			ChainA:      chainA,
+			ContractA:   a.address,
+			AmountA:     new(big.Int).Set(amountA),
+			TimelockA:   headA.Time + 2*uint64(timeout/time.Second),
+			ChainB:      chainB,
+			ContractB:   b.address,
+			AmountB:     new(big.Int).Set(amountB),
+			TimelockB:   headB.Time + uint64(timeout/time.Second),
+		},
+		Role:   RoleInitiator,
+		Status: StatusActive,
+		Secret: secret,
+		FromA:  headA.Number.Uint64(),
+		FromB:  headB.Number.Uint64(),
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	writeSwap(e.db, s)
+	log.Info("Initiated swap", "id", s.ID(), "participant", participant, "chainA", chainA, "amountA", amountA, "chainB", chainB, "amountB", amountB)
+
+	terms := s.Terms
+	return &terms, nil
+}
+
+// Participate joins a swap as its participant. Its own funds are only locked
+// once those of the initiator are confirmed on chain A.
+func (e *Engine) Participate(ctx context.Context, terms *Terms) error {
+	a, b := e.chains[terms.ChainA], e.chains[terms.ChainB]
+	if a == nil || b == nil {
+		return errUnknownChain
+	}
+	if terms.ChainA == terms.ChainB {
+		return errSameChain
+	}
+	if terms.Participant != e.account {
+		return errNotParticipant
+	}
+	if terms.AmountA == nil || terms.AmountA.Sign() <= 0 || terms.AmountB == nil || terms.AmountB.Sign() <= 0 {
+		return errInvalidAmount
+	}
+	if terms.TimelockA < terms.TimelockB+uint64(e.config.MinDelta/time.Second) {
+		return errShortTimelocks
+	}
+	// Only accept contracts the funds are known to be safe in
+	for _, leg := range []struct {
+		chain   *chain
+		address common.Address
+	}{{a, terms.ContractA}, {b, terms.ContractB}} {
+		code, err := leg.chain.backend.CodeAt(ctx, leg.address, nil)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(code, xchannel.HTLCCode) {
+			return errInvalidHTLC
+		}
+	}
+	headB, err := b.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if headB.Time+uint64(e.config.MinDelta/time.Second) > terms.TimelockB {
+		return errExpired
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if readSwap(e.db, terms.Hashlock) != nil {
+		return errKnownSwap
+	}
+	s := &Swap{
+		Terms:  *terms,
+		Role:   RoleParticipant,
+		Status: StatusActive,
+		FromB:  headB.Number.Uint64(),
+	}
+	writeSwap(e.db, s)
+	log.Info("Joined swap", "id", s.ID(), "initiator", terms.Initiator, "chainA", terms.ChainA, "amountA", terms.AmountA, "chainB", terms.ChainB, "amountB", terms.AmountB)
+	return nil
+}
+
+// Swap returns the swap with the given id.
+func (e *Engine) Swap(id common.Hash) (*Swap, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	s := readSwap(e.db, id)
+	if s == nil {
+		return nil, errUnknownSwap
+	}
+	return s, nil
+}
+
+// Swaps returns all the swaps of the local account.
+func (e *Engine) Swaps() []*Swap {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return readSwaps(e.db)
+}
+
+// step advances a swap by at most one transaction per chain, acting on the
+// on-chain state of its two legs.
+func (e *Engine) step(ctx context.Context, s *Swap) error {
+	a, b := e.chains[s.Terms.ChainA], e.chains[s.Terms.ChainB]
+	if a == nil || b == nil {
+		return errUnknownChain
+	}
+	// Wait for the transactions sent before to be included
+	for _, pending := range []struct {
+		chain *chain
+		tx    *common.Hash
+	}{{a, &s.TxA}, {b, &s.TxB}} {
+		if *pending.tx == (common.Hash{}) {
+			continue
+		}
+		receipt, err := pending.chain.backend.TransactionReceipt(ctx, *pending.tx)
+		if err != nil && err != ethereum.NotFound {
+			return err
+		}
+		if receipt == nil {
+			return nil
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			log.Warn("Swap transaction failed", "id", s.ID(), "chain", pending.chain.id, "tx", *pending.tx)
+		}
+		*pending.tx = common.Hash{}
+	}
+	legA, err := e.leg(ctx, a, s.Terms.ContractA, s.Terms.idA())
+	if err != nil {
+		return err
+	}
+	legB, err := e.leg(ctx, b, s.Terms.ContractB, s.Terms.idB())
+	if err != nil {
+		return err
+	}
+	if s.Role == RoleInitiator {
+		return e.stepInitiator(ctx, s, a, b, legA, legB)
+	}
+	return e.stepParticipant(ctx, s, a, b, legA, legB)
+}
+
+// stepInitiator advances a swap initiated by the local account: locking on
+// chain A, claiming on chain B once the participant's funds are confirmed, or
+// refunding on chain A once its timelock passed.
+func (e *Engine) stepInitiator(ctx context.Context, s *Swap, a, b *chain, legA, legB *leg) error {
+	terms := &s.Terms
+
+	switch legA.status {
+	case xchannel.SwapUnknown:
+		if legA.head.Time >= terms.TimelockA {
+			s.Status = StatusAborted
+			log.Warn("Swap timed out before locking", "id", s.ID())
+			return nil
+		}
+		tx, err := e.htlc(a, terms.ContractA).Lock(e.transactOpts(ctx, a, terms.AmountA), terms.Participant, terms.Hashlock, new(big.Int).SetUint64(terms.TimelockA))
+		if err != nil {
+			return err
+		}
+		s.TxA = tx.Hash()
+		log.Info("Locking swap funds", "id", s.ID(), "chain", a.id, "amount", terms.AmountA, "tx", tx.Hash())
+		return nil
+
+	case xchannel.SwapRefunded:
+		s.Status = StatusRefunded
+		log.Info("Swap refunded", "id", s.ID(), "chain", a.id)
+		return nil
+	}
+	switch legB.status {
+	case xchannel.SwapClaimed:
+		s.Status = StatusCompleted
+		log.Info("Swap completed", "id", s.ID(), "chain", b.id, "amount", terms.AmountB)
+		return nil
+
+	case xchannel.SwapLocked:
+		if legB.amount.Cmp(terms.AmountB) < 0 || legB.head.Time >= terms.TimelockB {
+			break
+		}
+		confirmed, err := e.confirmed(ctx, b, terms.ContractB, terms.idB(), s.FromB, legB.head)
+		if err != nil || !confirmed {
+			return err
+		}
+		tx, err := e.htlc(b, terms.ContractB).Claim(e.transactOpts(ctx, b, nil), terms.idB(), s.Secret)
+		if err != nil {
+			return err
+		}
+		s.TxB = tx.Hash()
+		log.Info("Claiming swap funds", "id", s.ID(), "chain", b.id, "amount", terms.AmountB, "tx", tx.Hash())
+		return nil
+	}
+	if legA.status == xchannel.SwapLocked && legA.head.Time >= terms.TimelockA {
+		tx, err := e.htlc(a, terms.ContractA).Refund(e.transactOpts(ctx, a, nil), terms.idA())
+		if err != nil {
+			return err
+		}
+		s.TxA = tx.Hash()
+		log.Info("Refunding swap funds", "id", s.ID(), "chain", a.id, "amount", terms.AmountA, "tx", tx.Hash())
+	}
+	return nil
+}
+
+// stepParticipant advances a swap joined by the local account: locking on
+// chain B once the initiator's funds are confirmed, claiming on chain A once the
+// secret was revealed on chain B, or refunding on chain B once its timelock
+// passed.
+func (e *Engine) stepParticipant(ctx context.Context, s *Swap, a, b *chain, legA, legB *leg) error {
+	terms := &s.Terms
+
+	switch legB.status {
+	case xchannel.SwapUnknown:
+		// Don't lock unless the initiator has, leaving time to follow its claim
+		if legB.head.Time+uint64(e.config.MinDelta/time.Second) > terms.TimelockB || legA.head.Time >= terms.TimelockA {
+			s.Status = StatusAborted
+			log.Warn("Swap timed out before locking", "id", s.ID())
+			return nil
+		}
+		if legA.status != xchannel.SwapLocked || legA.amount.Cmp(terms.AmountA) < 0 {
+			return nil
+		}
+		confirmed, err := e.confirmed(ctx, a, terms.ContractA, terms.idA(), s.FromA, legA.head)
+		if err != nil || !confirmed {
+			return err
+		}
+		tx, err := e.htlc(b, terms.ContractB).Lock(e.transactOpts(ctx, b, terms.AmountB), terms.Initiator, terms.Hashlock, new(big.Int).SetUint64(terms.TimelockB))
+		if err != nil {
+			return err
+		}
+		s.TxB = tx.Hash()
+		log.Info("Locking swap funds", "id", s.ID(), "chain", b.id, "amount", terms.AmountB, "tx", tx.Hash())
+		return nil
+
+	case xchannel.SwapRefunded:
+		s.Status = StatusRefunded
+		log.Info("Swap refunded", "id", s.ID(), "chain", b.id)
+		return nil
+
+	case xchannel.SwapClaimed:
+		if s.Secret == (common.Hash{}) {
+			secret, err := e.secret(ctx, b, terms.ContractB, terms.idB(), s.FromB)
+			if err != nil {
+				return err
+			}
+			s.Secret = secret
+			log.Info("Learned swap secret", "id", s.ID(), "chain", b.id)
+		}
+	}
+	switch legA.status {
+	case xchannel.SwapClaimed:
+		s.Status = StatusCompleted
+		log.Info("Swap completed", "id", s.ID(), "chain", a.id, "amount", terms.AmountA)
+		return nil
+
+	case xchannel.SwapLocked:
+		if s.Secret == (common.Hash{}) || legA.head.Time >= terms.TimelockA {
+			break
+		}
+		tx, err := e.htlc(a, terms.ContractA).Claim(e.transactOpts(ctx, a, nil), terms.idA(), s.Secret)
+		if err != nil {
+			return err
+		}
+		s.TxA = tx.Hash()
+		log.Info("Claiming swap funds", "id", s.ID(), "chain", a.id, "amount", terms.AmountA, "tx", tx.Hash())
+		return nil
+	}
+	if legB.status == xchannel.SwapLocked && legB.head.Time >= terms.TimelockB {
+		tx, err := e.htlc(b, terms.ContractB).Refund(e.transactOpts(ctx, b, nil), terms.idB())
+		if err != nil {
+			return err
+		}
+		s.TxB = tx.Hash()
+		log.Info("Refunding swap funds", "id", s.ID(), "chain", b.id, "amount", terms.AmountB, "tx", tx.Hash())
+	}
+	return nil
+}
+
+// htlc binds the HTLC contract at the given address on a chain, which may be
+// one deployed by the counterparty.
+func (e *Engine) htlc(c *chain, address common.Address) *contract.HTLC {
+	if address == c.address {
+		return c.contract
+	}
+	htlc, _ := contract.NewHTLC(address, c.backend)
+	return htlc
+}
+
+// leg retrieves the on-chain state of a leg of a swap.
+func (e *Engine) leg(ctx context.Context, c *chain, address common.Address, id common.Hash) (*leg, error) {
+	head, err := c.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	swap, err := e.htlc(c, address).Swaps(&bind.CallOpts{Context: ctx}, id)
+	if err != nil {
+		return nil, err
+	}
+	return &leg{status: swap.Status.Uint64(), amount: swap.Amount, head: head}, nil
+}
+
+// confirmed reports whether the funds of a leg were locked at least the
+// configured number of blocks before the head.
+func (e *Engine) confirmed(ctx context.Context, c *chain, address common.Address, id common.Hash, from uint64, head *types.Header) (bool, error) {
+	it, err := e.htlc(c, address).FilterLocked(&bind.FilterOpts{Start: from, Context: ctx}, [][32]byte{id}, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	defer it.Close()
+
+	if !it.Next() {
+		return false, it.Error()
+	}
+	return it.Event.Raw.BlockNumber+e.config.Confirmations <= head.Number.Uint64(), nil
+}
+
+// secret retrieves the secret revealed by the claim of a leg.
+func (e *Engine) secret(ctx context.Context, c *chain, address common.Address, id common.Hash, from uint64) (common.Hash, error) {
+	it, err := e.htlc(c, address).FilterClaimed(&bind.FilterOpts{Start: from, Context: ctx}, [][32]byte{id})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer it.Close()
+
+	if !it.Next() {
+		if err := it.Error(); err != nil {
+			return common.Hash{}, err
+		}
+		return common.Hash{}, ethereum.NotFound
+	}
+	return it.Event.Secret, nil
+}
+
+// transactOpts returns the options to send a transaction on a chain.
+func (e *Engine) transactOpts(ctx context.Context, c *chain, value *big.Int) *bind.TransactOpts {
+	opts, _ := bind.NewKeyedTransactorWithChainID(e.key, c.id)
+	opts.Context = ctx
+	opts.Value = value
+	return opts
+}
@@ -0,0 +1,313 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package swap
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	keyA, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	keyB, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	addrA   = crypto.PubkeyToAddress(keyA.PublicKey)
+	addrB   = crypto.PubkeyToAddress(keyB.PublicKey)
+)
+
+// simBackend is a simulated chain with the chain id lookup the engine needs.
+type simBackend struct {
+	*backends.SimulatedBackend
+	id *big.Int
+}
+
+func (b *simBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return b.id, nil
+}
+
+// newSimBackend creates a simulated chain with the given chain id, funding both
+// test accounts.
+func newSimBackend(id int64) *simBackend {
+	config := *params.AllEthashProtocolChanges
+	config.ChainID = big.NewInt(id)
+
+	funds := new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+	return &simBackend{
+		SimulatedBackend: backends.NewSimulatedBackendWithConfig(rawdb.NewMemoryDatabase(), &config, core.GenesisAlloc{
+			addrA: {Balance: funds},
+			addrB: {Balance: funds},
+		}, 10000000),
+		id: config.ChainID,
+	}
+}
+
+// tester runs the swap engines of an initiator and a participant across two
+// simulated chains. The engines are driven manually instead of polling.
+type tester struct {
+	t      *testing.T
+	chains map[string]*simBackend
+	config *Config
+
+	initiator, participant     *Engine
+	initiatorDB, participantDB ethdb.Database
+}
+
+func newTester(t *testing.T) *tester {
+	tt := &tester{
+		t: t,
+		chains: map[string]*simBackend{
+			"a": newSimBackend(1),
+			"b": newSimBackend(2),
+		},
+		config: &Config{
+			Chains:        []ChainConfig{{Endpoint: "a"}, {Endpoint: "b"}},
+			Confirmations: 2,
+			MinDelta:      time.Minute,
+		},
+		initiatorDB:   rawdb.NewMemoryDatabase(),
+		participantDB: rawdb.NewMemoryDatabase(),
+	}
+	tt.initiator = tt.engine(tt.initiatorDB, keyA)
+	tt.participant = tt.engine(tt.participantDB, keyB)
+	tt.commit()
+	return tt
+}
+
+// engine creates and sets up a swap engine.
+func (tt *tester) engine(db ethdb.Database, key *ecdsa.PrivateKey) *Engine {
+	tt.t.Helper()
+
+	e := newEngine(tt.config, db, key, func(endpoint string) (Backend, error) {
+		return tt.chains[endpoint], nil
+	})
+	if err := e.setup(); err != nil {
+		tt.t.Fatalf("failed to set up engine: %v", err)
+	}
+	return e
+}
+
+// commit mines a block on both chains.
+func (tt *tester) commit() {
+	for _, chain := range tt.chains {
+		chain.Commit()
+	}
+}
+
+// run advances the given engines and mines blocks until the swap is no longer
+// active on any of them.
+func (tt *tester) run(id common.Hash, engines ...*Engine) {
+	tt.t.Helper()
+
+	for i := 0; i < 50; i++ {
+		active := false
+		for _, e := range engines {
+			e.process()
+			if s, _ := e.Swap(id); s.Status == StatusActive {
+				active = true
+			}
+		}
+		if !active {
+			return
+		}
+		tt.commit()
+	}
+	tt.t.Fatalf("swap still active")
+}
+
+// initiate starts a swap of 3 ether on chain a against 5 ether on chain b.
+func (tt *tester) initiate() *Terms {
+	tt.t.Helper()
+
+	terms, err := tt.initiator.Initiate(context.Background(), 1, 2, addrB, ether(3), ether(5), 10*time.Minute)
+	if err != nil {
+		tt.t.Fatalf("failed to initiate swap: %v", err)
+	}
+	return terms
+}
+
+// check verifies the status of a swap on an engine.
+func (tt *tester) check(e *Engine, id common.Hash, status Status) {
+	tt.t.Helper()
+
+	s, err := e.Swap(id)
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve swap: %v", err)
+	}
+	if s.Status != status {
+		tt.t.Errorf("%s: status mismatch: have %v, want %v", s.Role, s.Status, status)
+	}
+}
+
+// onchain returns the status of an HTLC swap on a chain.
+func (tt *tester) onchain(chain string, address common.Address, id common.Hash) uint64 {
+	tt.t.Helper()
+
+	htlc, _ := contract.NewHTLC(address, tt.chains[chain])
+	swap, err := htlc.Swaps(nil, id)
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve swap: %v", err)
+	}
+	return swap.Status.Uint64()
+}
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Ether))
+}
+
+func TestSwap(t *testing.T) {
+	tt := newTester(t)
+
+	terms := tt.initiate()
+	if err := tt.participant.Participate(context.Background(), terms); err != nil {
+		t.Fatalf("failed to join swap: %v", err)
+	}
+	// The participant doesn't lock before the initiator's funds are confirmed
+	tt.initiator.process()
+	tt.commit()
+	tt.participant.process()
+	if s, _ := tt.participant.Swap(terms.Hashlock); s.TxB != (common.Hash{}) {
+		t.Fatalf("participant locked before confirmation")
+	}
+	if status := tt.onchain("a", terms.ContractA, terms.idA()); status != xchannel.SwapLocked {
+		t.Fatalf("initiator funds not locked: status %d", status)
+	}
+	tt.run(terms.Hashlock, tt.initiator, tt.participant)
+
+	tt.check(tt.initiator, terms.Hashlock, StatusCompleted)
+	tt.check(tt.participant, terms.Hashlock, StatusCompleted)
+	if status := tt.onchain("a", terms.ContractA, terms.idA()); status != xchannel.SwapClaimed {
+		t.Errorf("chain a status mismatch: have %d, want %d", status, xchannel.SwapClaimed)
+	}
+	if status := tt.onchain("b", terms.ContractB, terms.idB()); status != xchannel.SwapClaimed {
+		t.Errorf("chain b status mismatch: have %d, want %d", status, xchannel.SwapClaimed)
+	}
+	// The participant learned the secret from the claim on chain b
+	if s, _ := tt.participant.Swap(terms.Hashlock); xchannel.Hashlock(s.Secret) != terms.Hashlock {
+		t.Errorf("participant secret mismatch")
+	}
+}
+
+func TestSwapRestart(t *testing.T) {
+	tt := newTester(t)
+
+	terms := tt.initiate()
+	if err := tt.participant.Participate(context.Background(), terms); err != nil {
+		t.Fatalf("failed to join swap: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		tt.initiator.process()
+		tt.participant.process()
+		tt.commit()
+	}
+	if status := tt.onchain("b", terms.ContractB, terms.idB()); status != xchannel.SwapLocked {
+		t.Fatalf("participant funds not locked: status %d", status)
+	}
+	// Both engines pick the swap up where they left it, reusing their contracts
+	tt.initiator = tt.engine(tt.initiatorDB, keyA)
+	tt.participant = tt.engine(tt.participantDB, keyB)
+	if address := tt.initiator.chains[1].address; address != terms.ContractA {
+		t.Fatalf("contract redeployed: have %x, want %x", address, terms.ContractA)
+	}
+	tt.run(terms.Hashlock, tt.initiator, tt.participant)
+
+	tt.check(tt.initiator, terms.Hashlock, StatusCompleted)
+	tt.check(tt.participant, terms.Hashlock, StatusCompleted)
+}
+
+func TestSwapInitiatorRefund(t *testing.T) {
+	tt := newTester(t)
+
+	// The participant never joins, the initiator gets its funds back after the
+	// timelock
+	terms := tt.initiate()
+	tt.initiator.process()
+	tt.commit()
+
+	tt.chains["a"].AdjustTime(20 * time.Minute)
+	tt.run(terms.Hashlock, tt.initiator)
+
+	tt.check(tt.initiator, terms.Hashlock, StatusRefunded)
+	if status := tt.onchain("a", terms.ContractA, terms.idA()); status != xchannel.SwapRefunded {
+		t.Errorf("chain a status mismatch: have %d, want %d", status, xchannel.SwapRefunded)
+	}
+}
+
+func TestSwapParticipantRefund(t *testing.T) {
+	tt := newTester(t)
+
+	terms := tt.initiate()
+	if err := tt.participant.Participate(context.Background(), terms); err != nil {
+		t.Fatalf("failed to join swap: %v", err)
+	}
+	// The initiator goes offline once the participant locked its funds
+	for tt.onchain("b", terms.ContractB, terms.idB()) != xchannel.SwapLocked {
+		tt.initiator.process()
+		tt.participant.process()
+		tt.commit()
+	}
+	tt.chains["b"].AdjustTime(10 * time.Minute)
+	tt.run(terms.Hashlock, tt.participant)
+	tt.check(tt.participant, terms.Hashlock, StatusRefunded)
+
+	// Past its own timelock, the initiator comes back for a refund too
+	tt.chains["a"].AdjustTime(20 * time.Minute)
+	tt.run(terms.Hashlock, tt.initiator)
+	tt.check(tt.initiator, terms.Hashlock, StatusRefunded)
+}
+
+func TestParticipateInvalid(t *testing.T) {
+	tt := newTester(t)
+	terms := tt.initiate()
+
+	tests := []struct {
+		tamper func(terms *Terms)
+		err    error
+	}{
+		{func(terms *Terms) { terms.Participant = addrA }, errNotParticipant},
+		{func(terms *Terms) { terms.ChainB = 3 }, errUnknownChain},
+		{func(terms *Terms) { terms.ChainB = terms.ChainA }, errSameChain},
+		{func(terms *Terms) { terms.AmountB = new(big.Int) }, errInvalidAmount},
+		{func(terms *Terms) { terms.TimelockA = terms.TimelockB + 30 }, errShortTimelocks},
+		{func(terms *Terms) { terms.ContractA = common.HexToAddress("0xdeadbeef") }, errInvalidHTLC},
+		{func(terms *Terms) { terms.TimelockB -= 10 * 60; terms.TimelockA -= 10 * 60 }, errExpired},
+	}
+	for i, test := range tests {
+		tampered := *terms
+		test.tamper(&tampered)
+		if err := tt.participant.Participate(context.Background(), &tampered); err != test.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+	if err := tt.participant.Participate(context.Background(), terms); err != nil {
+		t.Fatalf("failed to join swap: %v", err)
+	}
+	if err := tt.participant.Participate(context.Background(), terms); err != errKnownSwap {
+		t.Fatalf("error mismatch: have %v, want %v", err, errKnownSwap)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package swap
+
+//go:generate gencodec -type Terms -field-override termsMarshaling -out gen_terms_json.go
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+)
+
+// Role is the side of a swap the local account is on.
+type Role uint8
+
+const (
+	RoleInitiator   Role = iota + 1 // Holds the secret, locks funds on chain A first
+	RoleParticipant                 // Locks funds on chain B once those on chain A are confirmed
+)
+
+// String implements fmt.Stringer.
+func (r Role) String() string {
+	switch r {
+	case RoleInitiator:
+		return "initiator"
+	case RoleParticipant:
+		return "participant"
+	default:
+		return fmt.Sprintf("unknown(%d)", r)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Role) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// Status is the stage of its lifecycle a swap is in.
+type Status uint8
+
+const (
+	StatusActive    Status = iota + 1 // Funds being locked or claimed
+	StatusCompleted                   // Funds of the counterparty claimed
+	StatusRefunded                    // Own funds refunded after the timelock
+	StatusAborted                     // Timed out before own funds were locked
+)
+
+// String implements fmt.Stringer.
+func (s Status) String() string {
+	switch s {
+	case StatusActive:
+		return "active"
+	case StatusCompleted:
+		return "completed"
+	case StatusRefunded:
+		return "refunded"
+	case StatusAborted:
+		return "aborted"
+	default:
+		return fmt.Sprintf("unknown(%d)", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Terms are the terms of a swap agreed between its parties: the initiator locks
+// AmountA on chain A for the participant, who in return locks AmountB on chain B
+// for the initiator, both under the same hashlock. The timelock on chain B is
+// the shorter one, so the participant learning the secret from the initiator's
+// claim on chain B still has time to claim on chain A.
+type Terms struct {
+	Hashlock    common.Hash    `json:"hashlock" gencodec:"required"`
+	Initiator   common.Address `json:"initiator" gencodec:"required"`
+	Participant common.Address `json:"participant" gencodec:"required"`
+
+	ChainA    uint64         `json:"chainA" gencodec:"required"`
+	ContractA common.Address `json:"contractA" gencodec:"required"`
+	AmountA   *big.Int       `json:"amountA" gencodec:"required"`
+	TimelockA uint64         `json:"timelockA" gencodec:"required"`
+
+	ChainB    uint64         `json:"chainB" gencodec:"required"`
+	ContractB common.Address `json:"contractB" gencodec:"required"`
+	AmountB   *big.Int       `json:"amountB" gencodec:"required"`
+	TimelockB uint64         `json:"timelockB" gencodec:"required"`
+}
+
+type termsMarshaling struct {
+	ChainA    hexutil.Uint64
+	AmountA   *hexutil.Big
+	TimelockA hexutil.Uint64
+	ChainB    hexutil.Uint64
+	AmountB   *hexutil.Big
+	TimelockB hexutil.Uint64
+}
+
+// idA returns the id of the HTLC swap the initiator locks funds in on chain A.
+func (t *Terms) idA() common.Hash {
+	return xchannel.SwapID(t.ContractA, t.Initiator, t.Participant, t.Hashlock, t.TimelockA)
+}
+
+// idB returns the id of the HTLC swap the participant locks funds in on chain B.
+func (t *Terms) idB() common.Hash {
+	return xchannel.SwapID(t.ContractB, t.Participant, t.Initiator, t.Hashlock, t.TimelockB)
+}
+
+// Swap is the local view of a swap the local account is party to.
+type Swap struct {
+	Terms  Terms
+	Role   Role
+	Status Status
+	Secret common.Hash // Preimage of the hashlock, zero until known
+
+	FromA uint64 // Block to search the swap events on chain A from
+	FromB uint64 // Block to search the swap events on chain B from
+
+	TxA common.Hash // Transaction sent on chain A awaiting inclusion, if any
+	TxB common.Hash // Transaction sent on chain B awaiting inclusion, if any
+}
+
+// ID returns the identifier of the swap, its hashlock.
+func (s *Swap) ID() common.Hash {
+	return s.Terms.Hashlock
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xcall
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// PrivateXCallAPI provides access to the cross-chain transactions of the
+// coordinator.
+type PrivateXCallAPI struct {
+	c *Coordinator
+}
+
+// NewPrivateXCallAPI creates a new cross-chain call API.
+func NewPrivateXCallAPI(c *Coordinator) *PrivateXCallAPI {
+	return &PrivateXCallAPI{c: c}
+}
+
+// CallArgs are the arguments of a call to make as part of a transaction.
+type CallArgs struct {
+	Chain  hexutil.Uint64 `json:"chain"`
+	Target common.Address `json:"target"`
+	Value  *hexutil.Big   `json:"value"`
+	Data   hexutil.Bytes  `json:"data"`
+}
+
+// Begin starts a transaction making the given calls, returning its id. Calls not
+// prepared within timeout seconds get the transaction aborted.
+func (api *PrivateXCallAPI) Begin(ctx context.Context, calls []CallArgs, timeout hexutil.Uint64) (common.Hash, error) {
+	list := make([]*Call, len(calls))
+	for i, args := range calls {
+		list[i] = &Call{
+			Chain:  uint64(args.Chain),
+			Target: args.Target,
+			Value:  (*big.Int)(args.Value),
+			Data:   args.Data,
+		}
+	}
+	return api.c.Begin(ctx, list, time.Duration(timeout)*time.Second)
+}
+
+// RPCCall is a call of a transaction as reported over RPC.
+type RPCCall struct {
+	Chain    hexutil.Uint64  `json:"chain"`
+	Target   common.Address  `json:"target"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     hexutil.Bytes   `json:"data"`
+	Deadline hexutil.Uint64  `json:"deadline"`
+	Tx       *common.Hash    `json:"tx"`       // Last transaction sent for the call
+	Prepared *common.Hash    `json:"prepared"` // Block the call was proven prepared in
+	Proof    []hexutil.Bytes `json:"proof"`    // Receipt trie nodes proving the call prepared
+	Settled  bool            `json:"settled"`
+}
+
+// RPCTransaction is a transaction as reported over RPC.
+type RPCTransaction struct {
+	ID     common.Hash `json:"id"`
+	Status Status      `json:"status"`
+	Calls  []*RPCCall  `json:"calls"`
+}
+
+// newRPCTransaction returns the RPC representation of a transaction.
+func newRPCTransaction(tx *Transaction) *RPCTransaction {
+	rpcTx := &RPCTransaction{ID: tx.ID, Status: tx.Status}
+	for _, call := range tx.Calls {
+		rpcCall := &RPCCall{
+			Chain:    hexutil.Uint64(call.Chain),
+			Target:   call.Target,
+			Value:    (*hexutil.Big)(call.Value),
+			Data:     call.Data,
+			Deadline: hexutil.Uint64(call.Deadline),
+			Settled:  call.Settled,
+		}
+		if call.Tx != (common.Hash{}) {
+			hash := call.Tx
+			rpcCall.Tx = &hash
+		}
+		if call.Proof != nil {
+			hash := call.Proof.BlockHash
+			rpcCall.Prepared = &hash
+			for _, node := range call.Proof.Nodes {
+				rpcCall.Proof = append(rpcCall.Proof, hexutil.Bytes(node))
+			}
+		}
+		rpcTx.Calls = append(rpcTx.Calls, rpcCall)
+	}
+	return rpcTx
+}
+
+// Status returns the transaction with the given id.
+func (api *PrivateXCallAPI) Status(id common.Hash) (*RPCTransaction, error) {
+	tx, err := api.c.Transaction(id)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCTransaction(tx), nil
+}
+
+// List returns all the transactions of the coordinator.
+func (api *PrivateXCallAPI) List() []*RPCTransaction {
+	txs := api.c.Transactions()
+
+	list := make([]*RPCTransaction, 0, len(txs))
+	for _, tx := range txs {
+		list = append(list, newRPCTransaction(tx))
+	}
+	return list
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xcall
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultConfig contains the default settings of the coordinator.
+var DefaultConfig = Config{
+	Confirmations: 12,
+	CommitMargin:  10 * time.Minute,
+	PollInterval:  5 * time.Second,
+}
+
+// ChainConfig contains the settings of a chain calls are made on.
+type ChainConfig struct {
+	// Endpoint is the RPC endpoint of a node of the chain.
+	Endpoint string
+
+	// Contract is the address of the XCall contract used on the chain. One is
+	// deployed from the coordinator account if unset.
+	Contract common.Address `toml:",omitempty"`
+}
+
+// Config contains the configuration options of the coordinator.
+type Config struct {
+	// Chains are the chains transactions can span.
+	Chains []ChainConfig
+
+	// Confirmations is the number of blocks a prepared call must be buried under
+	// before it is voted for.
+	Confirmations uint64
+
+	// CommitMargin is the minimum time left before the deadlines of all calls
+	// for a transaction to be committed, bounding the time the commits have to
+	// make it on-chain. Transactions not prepared by then are aborted.
+	CommitMargin time.Duration
+
+	// PollInterval is the interval the chains are polled for progress at.
+	PollInterval time.Duration
+}
@@ -0,0 +1,648 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package xcall implements atomic contract calls across chains, committed in two
+// phases through the XCall contract.
+//
+// A transaction is a set of calls, each on a different chain. The coordinator
+// first prepares every call, locking the value to send along in the XCall
+// contract of its chain. Once its preparation is buried under enough blocks and
+// the call would go through, the coordinator votes for it on-chain. With all
+// calls voted for, the transaction is committed. Otherwise it is aborted.
+//
+// The deadline of a call only lets anyone abort it while it's not voted for,
+// the coordinator deciding the fate of calls voted for whenever it gets to. A
+// commit decision, taken with all votes on-chain, can thus no longer be undone
+// by the deadline of a call, and an abort decision reaches all calls as the
+// coordinator can abort them at any time. Transactions not voted for in time
+// get aborted.
+//
+// Votes and decisions are logged in a write-ahead log before being acted upon,
+// transactions sent right after. On restart the coordinator rebuilds its
+// transactions from the log and reconciles them with the on-chain state of
+// their calls, picking up where it left.
+package xcall
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// stepTimeout is the maximum time a round of advancing the transactions may take.
+const stepTimeout = 30 * time.Second
+
+var (
+	errNoCalls            = errors.New("transaction without calls")
+	errUnknownChain       = errors.New("unknown chain")
+	errDuplicateChain     = errors.New("multiple calls on the same chain")
+	errInvalidTarget      = errors.New("invalid call target")
+	errInvalidTimeout     = errors.New("timeout within commit margin")
+	errUnknownTransaction = errors.New("unknown transaction")
+	errNonCanonical       = errors.New("receipt not in canonical chain")
+	errInvalidProof       = errors.New("receipt doesn't prove call prepared")
+)
+
+var xcallABI, _ = abi.JSON(strings.NewReader(contract.XCallABI))
+
+// Backend is the chain access needed by the coordinator.
+type Backend interface {
+	bind.ContractBackend
+	ReceiptBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// chain is a chain calls are made on.
+type chain struct {
+	id       *big.Int
+	backend  Backend
+	address  common.Address // XCall contract the calls are made through
+	contract *contract.XCall
+}
+
+// callState is the on-chain state of a call.
+type callState struct {
+	status uint64
+	head   *types.Header // Chain head the state was retrieved at
+}
+
+// Coordinator drives transactions across chains.
+type Coordinator struct {
+	config  *Config
+	db      ethdb.Database // Database holding the transaction logs
+	key     *ecdsa.PrivateKey
+	account common.Address
+	dial    func(endpoint string) (Backend, error)
+
+	chains map[uint64]*chain
+	txs    map[common.Hash]*Transaction
+	lock   sync.Mutex // Lock serialising transaction updates
+
+	crash func(rec *record) // Test hook invoked before a record is logged
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a coordinator operating the given key on chains reached through
+// their RPC endpoints, and registers it with the node.
+func New(stack *node.Node, config *Config, key *ecdsa.PrivateKey) (*Coordinator, error) {
+	db, err := stack.OpenDatabase("xcall", 16, 16, "xcall/db/")
+	if err != nil {
+		return nil, err
+	}
+	dial := func(endpoint string) (Backend, error) {
+		return ethclient.Dial(endpoint)
+	}
+	c := newCoordinator(config, db, key, dial)
+
+	stack.RegisterAPIs(c.APIs())
+	stack.RegisterLifecycle(c)
+	return c, nil
+}
+
+// newCoordinator creates a coordinator operating the given key.
+func newCoordinator(config *Config, db ethdb.Database, key *ecdsa.PrivateKey, dial func(endpoint string) (Backend, error)) *Coordinator {
+	return &Coordinator{
+		config:  config,
+		db:      db,
+		key:     key,
+		account: crypto.PubkeyToAddress(key.PublicKey),
+		dial:    dial,
+		chains:  make(map[uint64]*chain),
+		txs:     make(map[common.Hash]*Transaction),
+		quit:    make(chan struct{}),
+	}
+}
+
+// APIs returns the RPC APIs the coordinator offers.
+func (c *Coordinator) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "xcall",
+			Version:   "1.0",
+			Service:   NewPrivateXCallAPI(c),
+			Public:    false,
+		},
+	}
+}
+
+// Start implements node.Lifecycle, connecting to the chains, recovering the
+// logged transactions and starting to drive them.
+func (c *Coordinator) Start() error {
+	if err := c.setup(); err != nil {
+		return err
+	}
+	c.wg.Add(1)
+	go c.loop()
+
+	log.Info("Started cross-chain call coordinator", "account", c.account, "chains", len(c.chains), "transactions", len(c.txs))
+	return nil
+}
+
+// setup connects to the chains and binds their XCall contracts, deploying those
+// not configured nor deployed before, then rebuilds the transactions logged.
+func (c *Coordinator) setup() error {
+	ctx, cancel := context.WithTimeout(context.Background(), stepTimeout)
+	defer cancel()
+
+	for _, config := range c.config.Chains {
+		backend, err := c.dial(config.Endpoint)
+		if err != nil {
+			return err
+		}
+		id, err := backend.ChainID(ctx)
+		if err != nil {
+			return err
+		}
+		address := config.Contract
+		if address == (common.Address{}) {
+			address = readContract(c.db, id.Uint64())
+		} else if err := xchannel.CheckCode(ctx, backend, address, xchannel.XCallCode); err == xchannel.ErrCodeMismatch {
+			log.Warn("Unknown XCall contract deployed", "chain", id, "address", address, "version", xchannel.XCallVersion)
+		}
+		if address == (common.Address{}) {
+			opts, _ := bind.NewKeyedTransactorWithChainID(c.key, id)
+			opts.Context = ctx
+
+			if address, _, _, err = contract.DeployXCall(opts, backend); err != nil {
+				return err
+			}
+			writeContract(c.db, id.Uint64(), address)
+			log.Info("Deployed XCall contract", "chain", id, "address", address)
+		}
+		xcall, err := contract.NewXCall(address, backend)
+		if err != nil {
+			return err
+		}
+		c.chains[id.Uint64()] = &chain{id: id, backend: backend, address: address, contract: xcall}
+	}
+	for _, tx := range readTransactions(c.db) {
+		c.txs[tx.ID] = tx
+	}
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the coordinator.
+func (c *Coordinator) Stop() error {
+	close(c.quit)
+	c.wg.Wait()
+
+	log.Info("Cross-chain call coordinator stopped")
+	return nil
+}
+
+// loop advances the unfinished transactions periodically.
+func (c *Coordinator) loop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.process()
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// process advances all the unfinished transactions.
+func (c *Coordinator) process() {
+	ctx, cancel := context.WithTimeout(context.Background(), stepTimeout)
+	defer cancel()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, tx := range c.txs {
+		var err error
+		switch tx.Status {
+		case StatusPreparing:
+			err = c.prepare(ctx, tx)
+		case StatusCommitting, StatusAborting:
+			err = c.settle(ctx, tx)
+		}
+		if err != nil {
+			log.Warn("Failed to advance transaction", "id", tx.ID, "status", tx.Status, "err", err)
+		}
+	}
+}
+
+// Begin starts a transaction making the given calls, at most one per chain.
+// Calls not voted for within the timeout get the transaction aborted, the
+// deadline of each call being set that far ahead on its chain.
+func (c *Coordinator) Begin(ctx context.Context, calls []*Call, timeout time.Duration) (common.Hash, error) {
+	if len(calls) == 0 {
+		return common.Hash{}, errNoCalls
+	}
+	if timeout <= c.config.CommitMargin {
+		return common.Hash{}, errInvalidTimeout
+	}
+	tx := &Transaction{Status: StatusPreparing}
+	for _, call := range calls {
+		ch := c.chains[call.Chain]
+		if ch == nil {
+			return common.Hash{}, errUnknownChain
+		}
+		for _, prev := range tx.Calls {
+			if prev.Chain == call.Chain {
+				return common.Hash{}, errDuplicateChain
+			}
+		}
+		if call.Target == (common.Address{}) {
+			return common.Hash{}, errInvalidTarget
+		}
+		head, err := ch.backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		value := new(big.Int)
+		if call.Value != nil {
+			value.Set(call.Value)
+		}
+		tx.Calls = append(tx.Calls, &Call{
+			Chain:    call.Chain,
+			Target:   call.Target,
+			Value:    value,
+			Data:     common.CopyBytes(call.Data),
+			Deadline: head.Time + uint64(timeout/time.Second),
+		})
+	}
+	if _, err := rand.Read(tx.ID[:]); err != nil {
+		return common.Hash{}, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	writeTransaction(c.db, tx)
+	c.txs[tx.ID] = tx
+
+	log.Info("Began cross-chain transaction", "id", tx.ID, "calls", len(tx.Calls))
+	return tx.ID, nil
+}
+
+// Transaction returns the transaction with the given id.
+func (c *Coordinator) Transaction(id common.Hash) (*Transaction, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	tx := c.txs[id]
+	if tx == nil {
+		return nil, errUnknownTransaction
+	}
+	return tx.copy(), nil
+}
+
+// Transactions returns all the transactions of the coordinator.
+func (c *Coordinator) Transactions() []*Transaction {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	txs := make([]*Transaction, 0, len(c.txs))
+	for _, tx := range c.txs {
+		txs = append(txs, tx.copy())
+	}
+	return txs
+}
+
+// append logs a record of a transaction and applies it.
+func (c *Coordinator) append(tx *Transaction, rec *record) {
+	if c.crash != nil {
+		c.crash(rec)
+	}
+	writeRecord(c.db, tx.ID, tx.records, rec)
+	tx.records++
+	tx.apply(rec)
+}
+
+// prepare advances a transaction being prepared, deciding to commit it once all
+// its calls are voted for on-chain, or to abort it if one can't be.
+func (c *Coordinator) prepare(ctx context.Context, tx *Transaction) error {
+	var (
+		failed error
+		voted  = true
+	)
+	for i := range tx.Calls {
+		ok, abort, err := c.prepareCall(ctx, tx, i)
+		if err != nil {
+			if failed == nil {
+				failed = err
+			}
+			voted = false
+			continue
+		}
+		if abort {
+			c.append(tx, &record{Kind: recordAbort})
+			log.Warn("Aborting cross-chain transaction", "id", tx.ID)
+			return nil
+		}
+		voted = voted && ok
+	}
+	// Commit only with all votes on-chain, out of reach of the call deadlines
+	if failed != nil {
+		return failed
+	}
+	if !voted {
+		return nil
+	}
+	c.append(tx, &record{Kind: recordCommit})
+	log.Info("Committing cross-chain transaction", "id", tx.ID)
+	return nil
+}
+
+// prepareCall advances the preparation of a call, preparing it or voting for it
+// once prepared. It reports whether the call is voted for on-chain, or whether
+// the transaction must be aborted instead.
+func (c *Coordinator) prepareCall(ctx context.Context, tx *Transaction, i int) (bool, bool, error) {
+	call, ch := tx.Calls[i], c.chains[tx.Calls[i].Chain]
+	if ch == nil {
+		return false, false, errUnknownChain
+	}
+	state, err := c.state(ctx, ch, tx)
+	if err != nil {
+		return false, false, err
+	}
+	id := tx.id(ch.address, c.account)
+
+	// Votes not all in before the deadlines get the transaction aborted, which
+	// the coordinator can still do for the calls voted for
+	if state.head.Time+uint64(c.config.CommitMargin/time.Second) >= call.Deadline {
+		log.Warn("Cross-chain transaction not prepared in time", "id", tx.ID, "chain", ch.id)
+		return false, true, nil
+	}
+	if state.status == xchannel.CallVoted {
+		if call.Proof == nil {
+			// Voted for by a transaction sent but not logged before a crash
+			receipt, err := c.prepared(ctx, ch, id)
+			if err != nil {
+				return false, false, err
+			}
+			proof, err := c.prove(ctx, ch, receipt, id)
+			if err != nil {
+				return false, false, err
+			}
+			c.append(tx, &record{Kind: recordVote, Call: uint64(i), Proof: proof})
+		}
+		return true, false, nil
+	}
+	// Wait for the transaction sent before to be included
+	var receipt *types.Receipt
+	if call.Tx != (common.Hash{}) {
+		if receipt, err = ch.backend.TransactionReceipt(ctx, call.Tx); err != nil && err != ethereum.NotFound {
+			return false, false, err
+		}
+		if receipt == nil {
+			return false, false, nil
+		}
+	}
+	switch state.status {
+	case xchannel.CallUnknown:
+		if receipt != nil {
+			log.Warn("Call preparation failed", "id", tx.ID, "chain", ch.id, "tx", call.Tx)
+			return false, true, nil
+		}
+		sent, err := ch.contract.Prepare(c.transactOpts(ctx, ch, call.Value), tx.ID, call.Target, crypto.Keccak256Hash(call.Data), new(big.Int).SetUint64(call.Deadline))
+		if err != nil {
+			return false, false, err
+		}
+		c.append(tx, &record{Kind: recordPrepare, Call: uint64(i), Hash: sent.Hash()})
+		log.Info("Preparing call", "id", tx.ID, "chain", ch.id, "target", call.Target, "tx", sent.Hash())
+		return false, false, nil
+
+	case xchannel.CallPrepared:
+		proof := call.Proof
+		if proof != nil {
+			// The vote was sent once the preparation got confirmed, but failed
+			log.Warn("Call vote failed, retrying", "id", tx.ID, "chain", ch.id, "tx", call.Tx)
+		} else {
+			if receipt == nil {
+				// Prepared by a transaction sent but not logged before a crash
+				if receipt, err = c.prepared(ctx, ch, id); err != nil {
+					return false, false, err
+				}
+			}
+			if receipt.BlockNumber.Uint64()+c.config.Confirmations > state.head.Number.Uint64() {
+				return false, false, nil
+			}
+			if proof, err = c.prove(ctx, ch, receipt, id); err != nil {
+				return false, false, err
+			}
+		}
+		// Only vote for calls going through. Any failure counts as a no, aborting
+		// being always safe.
+		msg := ethereum.CallMsg{From: ch.address, To: &call.Target, Value: call.Value, Data: call.Data}
+		if _, err := ch.backend.CallContract(ctx, msg, nil); err != nil {
+			log.Warn("Prepared call fails", "id", tx.ID, "chain", ch.id, "err", err)
+			return false, true, nil
+		}
+		sent, err := ch.contract.Vote(c.transactOpts(ctx, ch, nil), id)
+		if err != nil {
+			return false, false, err
+		}
+		c.append(tx, &record{Kind: recordVote, Call: uint64(i), Hash: sent.Hash(), Proof: proof})
+		log.Info("Voting for call", "id", tx.ID, "chain", ch.id, "block", proof.BlockHash, "tx", sent.Hash())
+		return false, false, nil
+
+	default:
+		log.Warn("Call settled before decision", "id", tx.ID, "chain", ch.id, "status", state.status)
+		return false, true, nil
+	}
+}
+
+// settle advances a decided transaction, committing or aborting its calls as
+// decided, and ends it once all of them are settled.
+func (c *Coordinator) settle(ctx context.Context, tx *Transaction) error {
+	var failed error
+	for i, call := range tx.Calls {
+		if call.Settled {
+			continue
+		}
+		if err := c.settleCall(ctx, tx, i); err != nil && failed == nil {
+			failed = err
+		}
+	}
+	for _, call := range tx.Calls {
+		if !call.Settled {
+			return failed
+		}
+	}
+	c.append(tx, &record{Kind: recordEnd})
+	log.Info("Cross-chain transaction finished", "id", tx.ID, "status", tx.Status)
+	return nil
+}
+
+// settleCall advances the settlement of a call, committing or aborting it as
+// decided. Transactions sent for the call failing are retried.
+func (c *Coordinator) settleCall(ctx context.Context, tx *Transaction, i int) error {
+	call, ch := tx.Calls[i], c.chains[tx.Calls[i].Chain]
+	if ch == nil {
+		return errUnknownChain
+	}
+	state, err := c.state(ctx, ch, tx)
+	if err != nil {
+		return err
+	}
+	// Wait for the transaction sent before to be included
+	if call.Tx != (common.Hash{}) {
+		receipt, err := ch.backend.TransactionReceipt(ctx, call.Tx)
+		if err != nil && err != ethereum.NotFound {
+			return err
+		}
+		if receipt == nil {
+			return nil
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful && (state.status == xchannel.CallPrepared || state.status == xchannel.CallVoted) {
+			log.Warn("Call transaction failed, retrying", "id", tx.ID, "chain", ch.id, "tx", call.Tx)
+		}
+	}
+	id := tx.id(ch.address, c.account)
+
+	switch state.status {
+	case xchannel.CallVoted, xchannel.CallPrepared:
+		if tx.Status == StatusCommitting {
+			if state.status == xchannel.CallVoted {
+				sent, err := ch.contract.Commit(c.transactOpts(ctx, ch, nil), id, call.Data)
+				if err != nil {
+					return err
+				}
+				c.append(tx, &record{Kind: recordSettle, Call: uint64(i), Hash: sent.Hash()})
+				log.Info("Committing call", "id", tx.ID, "chain", ch.id, "tx", sent.Hash())
+				return nil
+			}
+			// Only a reorg takes back a vote the decision was based on, vote
+			// again while the deadline allows
+			if state.head.Time < call.Deadline {
+				sent, err := ch.contract.Vote(c.transactOpts(ctx, ch, nil), id)
+				if err != nil {
+					return err
+				}
+				c.append(tx, &record{Kind: recordSettle, Call: uint64(i), Hash: sent.Hash()})
+				log.Warn("Voting again for committed call", "id", tx.ID, "chain", ch.id, "tx", sent.Hash())
+				return nil
+			}
+			log.Error("Committed call vote reorged out past its deadline", "id", tx.ID, "chain", ch.id)
+		}
+		sent, err := ch.contract.Abort(c.transactOpts(ctx, ch, nil), id)
+		if err != nil {
+			return err
+		}
+		c.append(tx, &record{Kind: recordSettle, Call: uint64(i), Hash: sent.Hash()})
+		log.Info("Aborting call", "id", tx.ID, "chain", ch.id, "tx", sent.Hash())
+		return nil
+
+	case xchannel.CallUnknown:
+		if tx.Status == StatusCommitting {
+			log.Error("Committed call not prepared", "id", tx.ID, "chain", ch.id)
+		}
+	}
+	c.append(tx, &record{Kind: recordSettled, Call: uint64(i), Outcome: state.status})
+	log.Info("Call settled", "id", tx.ID, "chain", ch.id, "status", state.status)
+	return nil
+}
+
+// state retrieves the on-chain state of the call of a transaction on a chain.
+func (c *Coordinator) state(ctx context.Context, ch *chain, tx *Transaction) (*callState, error) {
+	head, err := ch.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	call, err := ch.contract.Calls(&bind.CallOpts{Context: ctx}, tx.id(ch.address, c.account))
+	if err != nil {
+		return nil, err
+	}
+	return &callState{status: call.Status.Uint64(), head: head}, nil
+}
+
+// prepared retrieves the receipt of the transaction that prepared a call.
+func (c *Coordinator) prepared(ctx context.Context, ch *chain, id common.Hash) (*types.Receipt, error) {
+	it, err := ch.contract.FilterPrepared(&bind.FilterOpts{Context: ctx}, [][32]byte{id}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	if !it.Next() {
+		if err := it.Error(); err != nil {
+			return nil, err
+		}
+		return nil, ethereum.NotFound
+	}
+	return ch.backend.TransactionReceipt(ctx, it.Event.Raw.TxHash)
+}
+
+// prove creates the inclusion proof of the receipt preparing a call, checking
+// it against the header of its block. The header comes from the same endpoint
+// as the receipt: the proof keeps a record of the preparation voted for and
+// catches inconsistent answers, but doesn't guard against a dishonest endpoint,
+// the coordinator trusting the endpoints of its chains anyway.
+func (c *Coordinator) prove(ctx context.Context, ch *chain, receipt *types.Receipt, id common.Hash) (*ReceiptProof, error) {
+	header, err := ch.backend.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if header.Hash() != receipt.BlockHash {
+		return nil, errNonCanonical
+	}
+	proof, err := ProveReceipt(ctx, ch.backend, receipt)
+	if err != nil {
+		return nil, err
+	}
+	proven, err := proof.Verify(header)
+	if err != nil {
+		return nil, err
+	}
+	if proven.Status != types.ReceiptStatusSuccessful {
+		return nil, errInvalidProof
+	}
+	event := xcallABI.Events["Prepared"].ID
+	for _, l := range proven.Logs {
+		if l.Address == ch.address && len(l.Topics) > 1 && l.Topics[0] == event && l.Topics[1] == id {
+			return proof, nil
+		}
+	}
+	return nil, errInvalidProof
+}
+
+// transactOpts returns the options to send a transaction on a chain.
+func (c *Coordinator) transactOpts(ctx context.Context, ch *chain, value *big.Int) *bind.TransactOpts {
+	opts, _ := bind.NewKeyedTransactorWithChainID(c.key, ch.id)
+	opts.Context = ctx
+	opts.Value = value
+	return opts
+}
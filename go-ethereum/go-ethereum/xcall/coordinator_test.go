@@ -0,0 +1,524 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xcall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+	"github.com/ethereum/go-ethereum/contracts/xchannel/contract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	keyA, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	keyB, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	addrA   = crypto.PubkeyToAddress(keyA.PublicKey)
+	addrB   = crypto.PubkeyToAddress(keyB.PublicKey)
+
+	errChainDown = errors.New("chain unreachable")
+	errCrash     = errors.New("coordinator crashed")
+
+	htlcABI, _ = abi.JSON(strings.NewReader(contract.HTLCABI))
+	hashlock   = xchannel.Hashlock(common.HexToHash("0x5ec4e7"))
+
+	// toggle reverts when called without data while slot 0 is set, and stores the
+	// word it's called with in slot 0 otherwise.
+	toggle     = common.FromHex("0x3615600c57600035600055005b600054601457005b600080fd")
+	toggleAddr = common.HexToAddress("0xc0")
+)
+
+// testChain is a simulated chain which can be made unreachable.
+type testChain struct {
+	*backends.SimulatedBackend
+	id   *big.Int
+	down bool
+
+	htlc   *contract.HTLC // Contract the calls in the tests are made to
+	target common.Address
+	sent   *types.Transaction // Last transaction sent to the chain
+}
+
+// newTestChain creates a simulated chain with the given chain id, funding both
+// test accounts and deploying the HTLC contract as call target.
+func newTestChain(id int64) *testChain {
+	config := *params.AllEthashProtocolChanges
+	config.ChainID = big.NewInt(id)
+
+	funds := new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+	sim := backends.NewSimulatedBackendWithConfig(rawdb.NewMemoryDatabase(), &config, core.GenesisAlloc{
+		addrA:      {Balance: funds},
+		addrB:      {Balance: funds},
+		toggleAddr: {Code: toggle, Balance: new(big.Int)},
+	}, 10000000)
+
+	opts, _ := bind.NewKeyedTransactorWithChainID(keyB, config.ChainID)
+	target, _, htlc, err := contract.DeployHTLC(opts, sim)
+	if err != nil {
+		panic(err)
+	}
+	sim.Commit()
+	return &testChain{SimulatedBackend: sim, id: config.ChainID, htlc: htlc, target: target}
+}
+
+func (c *testChain) ChainID(ctx context.Context) (*big.Int, error) {
+	return c.id, nil
+}
+
+func (c *testChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if c.down {
+		return nil, errChainDown
+	}
+	return c.SimulatedBackend.HeaderByNumber(ctx, number)
+}
+
+func (c *testChain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if c.down {
+		return nil, errChainDown
+	}
+	return c.SimulatedBackend.TransactionReceipt(ctx, hash)
+}
+
+func (c *testChain) CallContract(ctx context.Context, call ethereum.CallMsg, number *big.Int) ([]byte, error) {
+	if c.down {
+		return nil, errChainDown
+	}
+	return c.SimulatedBackend.CallContract(ctx, call, number)
+}
+
+func (c *testChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if c.down {
+		return errChainDown
+	}
+	c.sent = tx
+	return c.SimulatedBackend.SendTransaction(ctx, tx)
+}
+
+// tester runs a coordinator across simulated chains. The coordinator is driven
+// manually instead of polling.
+type tester struct {
+	t      *testing.T
+	chains []*testChain
+	config *Config
+	db     ethdb.Database
+	coord  *Coordinator
+}
+
+func newTester(t *testing.T, chains int) *tester {
+	tt := &tester{
+		t: t,
+		config: &Config{
+			Confirmations: 2,
+			CommitMargin:  time.Minute,
+		},
+		db: rawdb.NewMemoryDatabase(),
+	}
+	for i := 0; i < chains; i++ {
+		tt.chains = append(tt.chains, newTestChain(int64(i+1)))
+		tt.config.Chains = append(tt.config.Chains, ChainConfig{Endpoint: fmt.Sprint(i)})
+	}
+	tt.restart()
+	tt.commit()
+	return tt
+}
+
+// restart creates and sets up a coordinator on the database of the previous one.
+func (tt *tester) restart() {
+	tt.t.Helper()
+
+	tt.coord = newCoordinator(tt.config, tt.db, keyA, func(endpoint string) (Backend, error) {
+		var index int
+		fmt.Sscan(endpoint, &index)
+		return tt.chains[index], nil
+	})
+	if err := tt.coord.setup(); err != nil {
+		tt.t.Fatalf("failed to set up coordinator: %v", err)
+	}
+}
+
+// commit mines a block on all chains.
+func (tt *tester) commit() {
+	for _, chain := range tt.chains {
+		chain.Commit()
+	}
+}
+
+// begin starts a transaction locking an ether in the HTLC contract of every
+// chain. Calls with replaced data revert.
+func (tt *tester) begin(replace map[int][]byte) common.Hash {
+	tt.t.Helper()
+
+	var calls []*Call
+	for i, chain := range tt.chains {
+		data, _ := htlcABI.Pack("lock", addrB, hashlock, big.NewInt(1<<40))
+		if replace[i] != nil {
+			data = replace[i]
+		}
+		calls = append(calls, &Call{Chain: chain.id.Uint64(), Target: chain.target, Value: big.NewInt(params.Ether), Data: data})
+	}
+	id, err := tt.coord.Begin(context.Background(), calls, 10*time.Minute)
+	if err != nil {
+		tt.t.Fatalf("failed to begin transaction: %v", err)
+	}
+	return id
+}
+
+// status returns the status of a transaction.
+func (tt *tester) status(id common.Hash) Status {
+	tt.t.Helper()
+
+	tx, err := tt.coord.Transaction(id)
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve transaction: %v", err)
+	}
+	return tx.Status
+}
+
+// run advances the coordinator and mines blocks until the transaction reaches
+// the given status.
+func (tt *tester) run(id common.Hash, status Status) {
+	tt.t.Helper()
+
+	for i := 0; i < 50; i++ {
+		tt.coord.process()
+		if tt.status(id) == status {
+			return
+		}
+		tt.commit()
+	}
+	tt.t.Fatalf("transaction status mismatch: have %v, want %v", tt.status(id), status)
+}
+
+// runCrashing advances the coordinator like run, crashing it before its n-th
+// log record. It reports whether the coordinator crashed.
+func (tt *tester) runCrashing(id common.Hash, status Status, n int) (crashed bool) {
+	tt.t.Helper()
+
+	var records int
+	tt.coord.crash = func(rec *record) {
+		if records == n {
+			panic(errCrash)
+		}
+		records++
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errCrash {
+				panic(r)
+			}
+			crashed = true
+		}
+	}()
+	tt.run(id, status)
+	return false
+}
+
+// call returns the on-chain status of the call of a transaction on a chain.
+func (tt *tester) call(id common.Hash, index int) uint64 {
+	tt.t.Helper()
+
+	chain := tt.coord.chains[tt.chains[index].id.Uint64()]
+	call, err := chain.contract.Calls(nil, xchannel.CallID(chain.address, addrA, id))
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve call: %v", err)
+	}
+	return call.Status.Uint64()
+}
+
+// abort aborts the call of a transaction on a chain as a third party, reporting
+// whether the contract accepted it.
+func (tt *tester) abort(id common.Hash, index int) error {
+	chain := tt.chains[index]
+	address := tt.coord.chains[chain.id.Uint64()].address
+
+	xcall, _ := contract.NewXCall(address, chain.SimulatedBackend)
+	opts, _ := bind.NewKeyedTransactorWithChainID(keyB, chain.id)
+	if _, err := xcall.Abort(opts, xchannel.CallID(address, addrA, id)); err != nil {
+		return err
+	}
+	chain.Commit()
+	return nil
+}
+
+// toggle sets or clears the flag making calls to the toggle contract revert.
+func (tt *tester) toggle(index int, set bool) {
+	tt.t.Helper()
+
+	chain := tt.chains[index]
+	word := common.Hash{}
+	if set {
+		word[31] = 1
+	}
+	opts, _ := bind.NewKeyedTransactorWithChainID(keyB, chain.id)
+	if _, err := bind.NewBoundContract(toggleAddr, abi.ABI{}, chain, chain, chain).RawTransact(opts, word[:]); err != nil {
+		tt.t.Fatalf("failed to toggle: %v", err)
+	}
+	chain.Commit()
+}
+
+// check verifies the outcome of a transaction on every chain: the calls being
+// committed or aborted, and the ether locked in the HTLC or refunded.
+func (tt *tester) check(id common.Hash, committed ...bool) {
+	tt.t.Helper()
+
+	for i, chain := range tt.chains {
+		address := tt.coord.chains[chain.id.Uint64()].address
+
+		status, want := tt.call(id, i), uint64(xchannel.CallAborted)
+		if committed[i] {
+			want = xchannel.CallCommitted
+		}
+		if status != want && !(want == xchannel.CallAborted && status == xchannel.CallUnknown) {
+			tt.t.Errorf("chain %d: call status mismatch: have %d, want %d", i, status, want)
+		}
+		swap, _ := chain.htlc.Swaps(nil, xchannel.SwapID(chain.target, address, addrB, hashlock, 1<<40))
+		if locked := swap.Status.Uint64() == xchannel.SwapLocked; locked != committed[i] {
+			tt.t.Errorf("chain %d: target call mismatch: locked %v, committed %v", i, locked, committed[i])
+		}
+		if balance, _ := chain.BalanceAt(context.Background(), address, nil); balance.Sign() != 0 {
+			tt.t.Errorf("chain %d: funds left in contract: %v", i, balance)
+		}
+	}
+}
+
+func TestCommit(t *testing.T) {
+	tt := newTester(t, 3)
+
+	id := tt.begin(nil)
+	tt.run(id, StatusCommitted)
+	tt.check(id, true, true, true)
+
+	// Every call was voted for with a valid proof of its preparation
+	tx, _ := tt.coord.Transaction(id)
+	for i, call := range tx.Calls {
+		header, _ := tt.chains[i].HeaderByHash(context.Background(), call.Proof.BlockHash)
+		if _, err := call.Proof.Verify(header); err != nil {
+			t.Errorf("call %d: invalid proof: %v", i, err)
+		}
+	}
+	// The log rebuilds the finished transaction
+	tt.restart()
+	if status := tt.status(id); status != StatusCommitted {
+		t.Errorf("replayed status mismatch: have %v, want %v", status, StatusCommitted)
+	}
+}
+
+func TestAbortFailingCall(t *testing.T) {
+	tt := newTester(t, 3)
+
+	// A call that would revert doesn't get voted for, aborting all
+	id := tt.begin(map[int][]byte{1: common.FromHex("0xdeadbeef")})
+	tt.run(id, StatusAborted)
+	tt.check(id, false, false, false)
+}
+
+func TestAbortChainDown(t *testing.T) {
+	tt := newTester(t, 3)
+
+	// Chain 2 can't be reached to prepare its call, the others get prepared
+	id := tt.begin(nil)
+	tt.chains[2].down = true
+	for i := 0; i < 5; i++ {
+		tt.coord.process()
+		tt.commit()
+	}
+	if status := tt.status(id); status != StatusPreparing {
+		t.Fatalf("status mismatch: have %v, want %v", status, StatusPreparing)
+	}
+	// Past the commit margin the transaction aborts, but can't finish without
+	// checking the call of the unreachable chain
+	tt.chains[0].AdjustTime(10 * time.Minute)
+	tt.run(id, StatusAborting)
+	for i := 0; i < 5; i++ {
+		tt.coord.process()
+		tt.commit()
+	}
+	if status := tt.status(id); status != StatusAborting {
+		t.Fatalf("status mismatch: have %v, want %v", status, StatusAborting)
+	}
+	tt.chains[2].down = false
+	tt.run(id, StatusAborted)
+	tt.check(id, false, false, false)
+}
+
+func TestDeadlineAfterDecision(t *testing.T) {
+	tt := newTester(t, 3)
+
+	// Chain 2 becomes unreachable once the transaction is decided, coming back
+	// after the deadline of its call
+	id := tt.begin(nil)
+	tt.run(id, StatusCommitting)
+	tt.chains[2].down = true
+	for i := 0; i < 5; i++ {
+		tt.coord.process()
+		tt.commit()
+	}
+	tt.chains[2].AdjustTime(10 * time.Minute)
+	tt.chains[2].Commit()
+	tt.chains[2].down = false
+
+	// The call was voted for, the deadline doesn't let anyone abort it anymore
+	if err := tt.abort(id, 2); err == nil {
+		t.Fatalf("call voted for aborted past its deadline")
+	}
+	tt.run(id, StatusCommitted)
+	tt.check(id, true, true, true)
+}
+
+func TestDeadlineBeforeDecision(t *testing.T) {
+	tt := newTester(t, 3)
+
+	// Chain 2 becomes unreachable once its call is prepared, before voting for it
+	id := tt.begin(nil)
+	tt.coord.process()
+	tt.commit()
+	if status := tt.call(id, 2); status != xchannel.CallPrepared {
+		t.Fatalf("call status mismatch: have %d, want %d", status, xchannel.CallPrepared)
+	}
+	tt.chains[2].down = true
+	for i := 0; i < 5; i++ {
+		tt.coord.process()
+		tt.commit()
+	}
+	if status := tt.status(id); status != StatusPreparing {
+		t.Fatalf("status mismatch: have %v, want %v", status, StatusPreparing)
+	}
+	// Past the deadline anyone can abort the call not voted for, the votes for
+	// the other calls only get the coordinator to abort them too
+	tt.chains[2].AdjustTime(10 * time.Minute)
+	tt.chains[2].Commit()
+	if err := tt.abort(id, 2); err != nil {
+		t.Fatalf("failed to abort call past its deadline: %v", err)
+	}
+	tt.chains[2].down = false
+
+	tt.run(id, StatusAborted)
+	tt.check(id, false, false, false)
+}
+
+func TestRetryFailedCommit(t *testing.T) {
+	tt := newTester(t, 1)
+	chain := tt.chains[0]
+
+	id, err := tt.coord.Begin(context.Background(), []*Call{{Chain: chain.id.Uint64(), Target: toggleAddr}}, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	tt.run(id, StatusCommitting)
+
+	// The call gets to revert right before the commit is mined
+	tt.coord.process()
+	commit := chain.sent
+	chain.Rollback()
+	tt.toggle(0, true)
+	if err := chain.SendTransaction(context.Background(), commit); err != nil {
+		t.Fatalf("failed to resend commit: %v", err)
+	}
+	chain.Commit()
+	if receipt, _ := chain.TransactionReceipt(context.Background(), commit.Hash()); receipt.Status != types.ReceiptStatusFailed {
+		t.Fatalf("commit succeeded")
+	}
+	for i := 0; i < 5; i++ {
+		tt.coord.process()
+		tt.commit()
+	}
+	if status := tt.call(id, 0); status != xchannel.CallVoted {
+		t.Fatalf("call status mismatch: have %d, want %d", status, xchannel.CallVoted)
+	}
+	// The commit is retried until it goes through
+	tt.toggle(0, false)
+	tt.run(id, StatusCommitted)
+	if status := tt.call(id, 0); status != xchannel.CallCommitted {
+		t.Fatalf("call status mismatch: have %d, want %d", status, xchannel.CallCommitted)
+	}
+}
+
+func TestCrashRecovery(t *testing.T) {
+	tests := []struct {
+		name      string
+		replace   map[int][]byte
+		status    Status
+		committed []bool
+	}{
+		{"commit", nil, StatusCommitted, []bool{true, true, true}},
+		{"abort", map[int][]byte{2: common.FromHex("0xdeadbeef")}, StatusAborted, []bool{false, false, false}},
+	}
+	for _, test := range tests {
+		// Crash the coordinator before each of the records it logs in turn
+		for n := 0; ; n++ {
+			tt := newTester(t, 3)
+
+			id := tt.begin(test.replace)
+			if !tt.runCrashing(id, test.status, n) {
+				if n == 0 {
+					t.Fatalf("%s: no records logged", test.name)
+				}
+				break
+			}
+			tt.commit()
+			tt.restart()
+			tt.run(id, test.status)
+
+			t.Run(fmt.Sprintf("%s-%d", test.name, n), func(t *testing.T) {
+				tt.t = t
+				tt.check(id, test.committed...)
+			})
+		}
+	}
+}
+
+func TestBeginInvalid(t *testing.T) {
+	tt := newTester(t, 2)
+
+	call := func(chain uint64, target common.Address) *Call {
+		return &Call{Chain: chain, Target: target, Value: new(big.Int)}
+	}
+	target := tt.chains[0].target
+
+	tests := []struct {
+		calls   []*Call
+		timeout time.Duration
+		err     error
+	}{
+		{nil, 10 * time.Minute, errNoCalls},
+		{[]*Call{call(1, target)}, time.Minute, errInvalidTimeout},
+		{[]*Call{call(3, target)}, 10 * time.Minute, errUnknownChain},
+		{[]*Call{call(1, target), call(1, target)}, 10 * time.Minute, errDuplicateChain},
+		{[]*Call{call(1, common.Address{})}, 10 * time.Minute, errInvalidTarget},
+	}
+	for i, test := range tests {
+		if _, err := tt.coord.Begin(context.Background(), test.calls, test.timeout); err != test.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+}
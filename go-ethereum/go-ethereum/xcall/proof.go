@@ -0,0 +1,100 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xcall
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var (
+	errReceiptsMismatch = errors.New("receipts don't match block receipt root")
+	errHeaderMismatch   = errors.New("proof not for given header")
+	errReceiptMissing   = errors.New("receipt not in block")
+)
+
+// ReceiptBackend is the chain access needed to prove a receipt.
+type ReceiptBackend interface {
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// ReceiptProof is a merkle proof of a receipt being included in the receipt trie
+// of a block.
+type ReceiptProof struct {
+	BlockHash common.Hash
+	Index     uint64         // Index of the receipt in the block
+	Nodes     light.NodeList // Trie nodes on the path to the receipt
+}
+
+// ProveReceipt creates an inclusion proof for a receipt, rebuilding the receipt
+// trie of its block from the receipts of all the transactions in it.
+func ProveReceipt(ctx context.Context, backend ReceiptBackend, receipt *types.Receipt) (*ReceiptProof, error) {
+	block, err := backend.BlockByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	receipts := make(types.Receipts, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		if receipts[i], err = backend.TransactionReceipt(ctx, tx.Hash()); err != nil {
+			return nil, err
+		}
+	}
+	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	for i := range receipts {
+		key, _ := rlp.EncodeToBytes(uint(i))
+		tr.Update(key, receipts.GetRlp(i))
+	}
+	if tr.Hash() != block.ReceiptHash() {
+		return nil, errReceiptsMismatch
+	}
+	proof := &ReceiptProof{BlockHash: block.Hash(), Index: uint64(receipt.TransactionIndex)}
+
+	key, _ := rlp.EncodeToBytes(uint(receipt.TransactionIndex))
+	if err := tr.Prove(key, 0, &proof.Nodes); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// Verify checks the proof against the header of the block the receipt is in,
+// returning the receipt proven.
+func (p *ReceiptProof) Verify(header *types.Header) (*types.Receipt, error) {
+	if header.Hash() != p.BlockHash {
+		return nil, errHeaderMismatch
+	}
+	key, _ := rlp.EncodeToBytes(uint(p.Index))
+	value, err := trie.VerifyProof(header.ReceiptHash, key, p.Nodes.NodeSet())
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errReceiptMissing
+	}
+	receipt := new(types.Receipt)
+	if err := receipt.UnmarshalBinary(value); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
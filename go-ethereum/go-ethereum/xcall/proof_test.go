@@ -0,0 +1,83 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xcall
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/light"
+)
+
+func TestReceiptProof(t *testing.T) {
+	chain := newTestChain(1)
+	ctx := context.Background()
+
+	// Fill a block with transfers, the receipts of which to prove
+	nonce, _ := chain.PendingNonceAt(ctx, addrB)
+	signer := types.NewEIP155Signer(chain.id)
+
+	var txs []*types.Transaction
+	for i := 0; i < 20; i++ {
+		tx, _ := types.SignTx(types.NewTransaction(nonce+uint64(i), addrA, big.NewInt(1), 21000, big.NewInt(1), nil), signer, keyB)
+		if err := chain.SendTransaction(ctx, tx); err != nil {
+			t.Fatalf("failed to send transaction: %v", err)
+		}
+		txs = append(txs, tx)
+	}
+	chain.Commit()
+	header, _ := chain.HeaderByNumber(ctx, nil)
+
+	for i, tx := range txs {
+		receipt, _ := chain.TransactionReceipt(ctx, tx.Hash())
+		proof, err := ProveReceipt(ctx, chain, receipt)
+		if err != nil {
+			t.Fatalf("tx %d: failed to prove receipt: %v", i, err)
+		}
+		proven, err := proof.Verify(header)
+		if err != nil {
+			t.Fatalf("tx %d: failed to verify proof: %v", i, err)
+		}
+		if proven.CumulativeGasUsed != receipt.CumulativeGasUsed || proven.Status != receipt.Status || proven.Bloom != receipt.Bloom {
+			t.Errorf("tx %d: proven receipt mismatch: have %+v, want %+v", i, proven, receipt)
+		}
+	}
+	// Proofs don't hold against other blocks, nor once tampered with
+	receipt, _ := chain.TransactionReceipt(ctx, txs[3].Hash())
+	proof, _ := ProveReceipt(ctx, chain, receipt)
+
+	parent, _ := chain.HeaderByNumber(ctx, new(big.Int).Sub(header.Number, big.NewInt(1)))
+	if _, err := proof.Verify(parent); err != errHeaderMismatch {
+		t.Errorf("error mismatch for other block: have %v, want %v", err, errHeaderMismatch)
+	}
+	tampered := *proof
+	tampered.Nodes = append(light.NodeList{}, proof.Nodes...)
+	last := len(tampered.Nodes) - 1
+	tampered.Nodes[last] = common.CopyBytes(tampered.Nodes[last])
+	tampered.Nodes[last][len(tampered.Nodes[last])-1] ^= 0x01
+	if _, err := tampered.Verify(header); err == nil {
+		t.Errorf("tampered proof verified")
+	}
+	tampered = *proof
+	tampered.Index = uint64(len(txs))
+	if _, err := tampered.Verify(header); err == nil {
+		t.Errorf("proof verified for missing receipt")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xcall
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xchannel"
+)
+
+// Status is the stage of its lifecycle a transaction is in.
+type Status uint8
+
+const (
+	StatusPreparing  Status = iota + 1 // Calls being prepared, no decision taken yet
+	StatusCommitting                   // Decided to commit, calls being committed
+	StatusAborting                     // Decided to abort, calls being aborted
+	StatusCommitted                    // All calls committed
+	StatusAborted                      // No call committed
+	StatusMixed                        // Some calls committed, others aborted after losing their vote to a reorg
+)
+
+// String implements fmt.Stringer.
+func (s Status) String() string {
+	switch s {
+	case StatusPreparing:
+		return "preparing"
+	case StatusCommitting:
+		return "committing"
+	case StatusAborting:
+		return "aborting"
+	case StatusCommitted:
+		return "committed"
+	case StatusAborted:
+		return "aborted"
+	case StatusMixed:
+		return "mixed"
+	default:
+		return fmt.Sprintf("unknown(%d)", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Call is a contract call of a transaction, made on one of its chains through
+// the XCall contract of the coordinator.
+type Call struct {
+	Chain    uint64
+	Target   common.Address
+	Value    *big.Int
+	Data     []byte
+	Deadline uint64 // Timestamp from which the call can be aborted by anyone, unless voted for
+
+	// Progress of the call, rebuilt from the log
+	Tx      common.Hash   `rlp:"-"` // Last transaction sent for the call
+	Proof   *ReceiptProof `rlp:"-"` // Proof of the call being prepared, once voted for
+	Settled bool          `rlp:"-"` // Whether the call was committed or aborted
+	Outcome uint64        `rlp:"-"` // On-chain status of the call once settled
+}
+
+// Transaction is a set of calls across chains, executed all or none.
+type Transaction struct {
+	ID    common.Hash // Transaction id, unique to the coordinator
+	Calls []*Call
+
+	Status  Status `rlp:"-"`
+	records uint32 // Number of records logged for the transaction
+}
+
+// id returns the id a call of the transaction has in the XCall contract at the
+// given address.
+func (tx *Transaction) id(contract, coordinator common.Address) common.Hash {
+	return xchannel.CallID(contract, coordinator, tx.ID)
+}
+
+// apply updates the progress of the transaction with a record of its log.
+func (tx *Transaction) apply(rec *record) {
+	switch rec.Kind {
+	case recordPrepare, recordSettle:
+		tx.Calls[rec.Call].Tx = rec.Hash
+
+	case recordVote:
+		call := tx.Calls[rec.Call]
+		if rec.Hash != (common.Hash{}) {
+			call.Tx = rec.Hash
+		}
+		call.Proof = rec.Proof
+
+	case recordCommit:
+		tx.Status = StatusCommitting
+
+	case recordAbort:
+		tx.Status = StatusAborting
+
+	case recordSettled:
+		call := tx.Calls[rec.Call]
+		call.Settled, call.Outcome = true, rec.Outcome
+
+	case recordEnd:
+		var committed int
+		for _, call := range tx.Calls {
+			if call.Outcome == xchannel.CallCommitted {
+				committed++
+			}
+		}
+		switch committed {
+		case len(tx.Calls):
+			tx.Status = StatusCommitted
+		case 0:
+			tx.Status = StatusAborted
+		default:
+			tx.Status = StatusMixed
+		}
+	}
+}
+
+// copy returns a deep copy of the transaction.
+func (tx *Transaction) copy() *Transaction {
+	cpy := *tx
+	cpy.Calls = make([]*Call, len(tx.Calls))
+	for i, call := range tx.Calls {
+		c := *call
+		c.Value = new(big.Int).Set(call.Value)
+		c.Data = common.CopyBytes(call.Data)
+		cpy.Calls[i] = &c
+	}
+	return &cpy
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xcall
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	txPrefix       = []byte("t") // txPrefix + transaction id -> transaction calls
+	recordPrefix   = []byte("r") // recordPrefix + transaction id + seq (uint32 big endian) -> log record
+	contractPrefix = []byte("c") // contractPrefix + chain id -> deployed XCall contract
+)
+
+// recordKind is the kind of a log record.
+type recordKind uint8
+
+const (
+	recordPrepare recordKind = iota + 1 // Prepare transaction sent for a call
+	recordVote                          // Vote transaction sent for a prepared call, proof of inclusion attached
+	recordCommit                        // Decision to commit the transaction
+	recordAbort                         // Decision to abort the transaction
+	recordSettle                        // Commit or abort transaction sent for a call
+	recordSettled                       // Call committed or aborted, on-chain status attached
+	recordEnd                           // Transaction finished
+)
+
+// record is an entry of the write-ahead log of a transaction. The log of a
+// transaction starts with its calls, followed by the records of its progress in
+// the order they were appended, from which the state of the transaction is
+// rebuilt on startup.
+type record struct {
+	Kind    recordKind
+	Call    uint64        // Index of the call the record is about
+	Hash    common.Hash   // Transaction sent for the call
+	Outcome uint64        // On-chain status of a settled call
+	Proof   *ReceiptProof `rlp:"nil"`
+}
+
+// txKey = txPrefix + transaction id
+func txKey(id common.Hash) []byte {
+	return append(append([]byte{}, txPrefix...), id[:]...)
+}
+
+// recordKey = recordPrefix + transaction id + seq (uint32 big endian)
+func recordKey(id common.Hash, seq uint32) []byte {
+	var enc [4]byte
+	binary.BigEndian.PutUint32(enc[:], seq)
+	return append(append(append([]byte{}, recordPrefix...), id[:]...), enc[:]...)
+}
+
+// contractKey = contractPrefix + chain id (uint64 big endian)
+func contractKey(chainID uint64) []byte {
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], chainID)
+	return append(append([]byte{}, contractPrefix...), enc[:]...)
+}
+
+// writeTransaction starts the log of a transaction with its calls.
+func writeTransaction(db ethdb.KeyValueWriter, tx *Transaction) {
+	data, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		log.Crit("Failed to RLP encode transaction", "err", err)
+	}
+	if err := db.Put(txKey(tx.ID), data); err != nil {
+		log.Crit("Failed to store transaction", "err", err)
+	}
+}
+
+// writeRecord appends a record to the log of a transaction.
+func writeRecord(db ethdb.KeyValueWriter, id common.Hash, seq uint32, rec *record) {
+	data, err := rlp.EncodeToBytes(rec)
+	if err != nil {
+		log.Crit("Failed to RLP encode log record", "err", err)
+	}
+	if err := db.Put(recordKey(id, seq), data); err != nil {
+		log.Crit("Failed to store log record", "err", err)
+	}
+}
+
+// readTransactions retrieves all the transactions logged, replaying their logs
+// to rebuild their progress.
+func readTransactions(db ethdb.Database) []*Transaction {
+	it := db.NewIterator(txPrefix, nil)
+	defer it.Release()
+
+	var txs []*Transaction
+	for it.Next() {
+		if len(it.Key()) != len(txPrefix)+common.HashLength {
+			continue
+		}
+		tx := new(Transaction)
+		if err := rlp.DecodeBytes(it.Value(), tx); err != nil {
+			log.Error("Invalid transaction RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		tx.Status = StatusPreparing
+		replay(db, tx)
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// replay applies the records logged for a transaction in order.
+func replay(db ethdb.Iteratee, tx *Transaction) {
+	prefix := append(append([]byte{}, recordPrefix...), tx.ID[:]...)
+
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(prefix)+4 {
+			continue
+		}
+		if seq := binary.BigEndian.Uint32(it.Key()[len(prefix):]); seq != tx.records {
+			log.Error("Gap in transaction log", "id", tx.ID, "have", seq, "want", tx.records)
+			return
+		}
+		rec := new(record)
+		if err := rlp.DecodeBytes(it.Value(), rec); err != nil {
+			log.Error("Invalid log record RLP", "id", tx.ID, "seq", tx.records, "err", err)
+			return
+		}
+		if rec.Call >= uint64(len(tx.Calls)) {
+			log.Error("Log record for unknown call", "id", tx.ID, "seq", tx.records, "call", rec.Call)
+			return
+		}
+		tx.apply(rec)
+		tx.records++
+	}
+}
+
+// readContract retrieves the address of the XCall contract deployed on a chain,
+// zero if none.
+func readContract(db ethdb.KeyValueReader, chainID uint64) common.Address {
+	data, _ := db.Get(contractKey(chainID))
+	return common.BytesToAddress(data)
+}
+
+// writeContract stores the address of the XCall contract deployed on a chain.
+func writeContract(db ethdb.KeyValueWriter, chainID uint64, address common.Address) {
+	if err := db.Put(contractKey(chainID), address[:]); err != nil {
+		log.Crit("Failed to store contract address", "err", err)
+	}
+}
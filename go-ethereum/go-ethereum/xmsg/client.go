@@ -0,0 +1,75 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xmsg
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Source is the access to the chain messages are sent from.
+type Source interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+	ReceiptProof(ctx context.Context, txHash common.Hash) (*ReceiptProof, error)
+}
+
+// Destination is the access to the chain messages are delivered on.
+type Destination interface {
+	bind.ContractBackend
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// Client is an RPC client of a chain, messages can be relayed from and to.
+type Client struct {
+	*ethclient.Client
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	c, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given RPC client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{Client: ethclient.NewClient(c), c: c}
+}
+
+// ReceiptProof returns the proof of the receipt of a transaction being included
+// in its block.
+func (c *Client) ReceiptProof(ctx context.Context, txHash common.Hash) (*ReceiptProof, error) {
+	var proof *ReceiptProof
+	if err := c.c.CallContext(ctx, &proof, "eth_getReceiptProof", txHash); err != nil {
+		return nil, err
+	}
+	if proof == nil {
+		return nil, ethereum.NotFound
+	}
+	return proof, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xmsg
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newTestNode starts a node serving a chain of blocks with a few transfers each.
+func newTestNode(t *testing.T) (*node.Node, []*types.Block) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := &core.Genesis{
+		Config: params.AllEthashProtocolChanges,
+		Alloc:  core.GenesisAlloc{addrA: {Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)}},
+	}
+	signer := types.NewEIP155Signer(genesis.Config.ChainID)
+	blocks, _ := core.GenerateChain(genesis.Config, genesis.MustCommit(db), ethash.NewFaker(), db, 3, func(i int, g *core.BlockGen) {
+		for j := 0; j < 5; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(g.TxNonce(addrA), addrB, big.NewInt(1), params.TxGas, big.NewInt(1), nil), signer, keyA)
+			g.AddTx(tx)
+		}
+	})
+	n, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("can't create new node: %v", err)
+	}
+	config := &eth.Config{Genesis: genesis}
+	config.Ethash.PowMode = ethash.ModeFake
+	ethservice, err := eth.New(n, config)
+	if err != nil {
+		t.Fatalf("can't create new ethereum service: %v", err)
+	}
+	if err := n.Start(); err != nil {
+		t.Fatalf("can't start test node: %v", err)
+	}
+	if _, err := ethservice.BlockChain().InsertChain(blocks); err != nil {
+		t.Fatalf("can't import test blocks: %v", err)
+	}
+	return n, blocks
+}
+
+// Tests that the receipt proofs served over RPC prove the receipts of the
+// transactions.
+func TestClientReceiptProof(t *testing.T) {
+	n, blocks := newTestNode(t)
+	defer n.Close()
+	rpc, _ := n.Attach()
+	client := NewClient(rpc)
+	defer client.Close()
+
+	ctx := context.Background()
+	for _, block := range blocks {
+		for i, tx := range block.Transactions() {
+			proof, err := client.ReceiptProof(ctx, tx.Hash())
+			if err != nil {
+				t.Fatalf("failed to retrieve proof of %x: %v", tx.Hash(), err)
+			}
+			if proof.BlockHash != block.Hash() || uint64(proof.BlockNumber) != block.NumberU64() || proof.TransactionHash != tx.Hash() || int(proof.TransactionIndex) != i {
+				t.Errorf("proof of %x: position mismatch: %+v", tx.Hash(), proof)
+			}
+			receipt, err := proof.Verify()
+			if err != nil {
+				t.Fatalf("proof of %x: invalid: %v", tx.Hash(), err)
+			}
+			want, _ := client.TransactionReceipt(ctx, tx.Hash())
+			if receipt.CumulativeGasUsed != want.CumulativeGasUsed || receipt.Status != want.Status {
+				t.Errorf("proof of %x: receipt mismatch: have %+v, want %+v", tx.Hash(), receipt, want)
+			}
+			// Proofs aren't valid for other positions or blocks
+			other := *proof
+			other.TransactionIndex = hexutil.Uint64((i + 1) % len(block.Transactions()))
+			if _, err := other.Verify(); err == nil {
+				t.Errorf("proof of %x: valid for index %d", tx.Hash(), other.TransactionIndex)
+			}
+			other = *proof
+			other.BlockHash = common.Hash{}
+			if _, err := other.Verify(); err == nil {
+				t.Errorf("proof of %x: valid for other block", tx.Hash())
+			}
+		}
+	}
+	if _, err := client.ReceiptProof(ctx, common.Hash{1}); err != ethereum.NotFound {
+		t.Errorf("unknown transaction: error mismatch: have %v, want %v", err, ethereum.NotFound)
+	}
+}
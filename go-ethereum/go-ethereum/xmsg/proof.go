@@ -0,0 +1,107 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xmsg
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/xmsg"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var (
+	errHeaderMismatch  = errors.New("header doesn't match block hash")
+	errReceiptMismatch = errors.New("receipt doesn't match proof")
+	errReceiptMissing  = errors.New("receipt not in block")
+	errMessageMissing  = errors.New("message not in receipt")
+)
+
+// ReceiptProof is the proof of the receipt of a transaction being included in
+// its block, as returned by eth_getReceiptProof.
+type ReceiptProof struct {
+	BlockHash        common.Hash     `json:"blockHash"`
+	BlockNumber      hexutil.Uint64  `json:"blockNumber"`
+	TransactionHash  common.Hash     `json:"transactionHash"`
+	TransactionIndex hexutil.Uint64  `json:"transactionIndex"`
+	Header           hexutil.Bytes   `json:"header"`  // RLP encoded header of the block
+	Receipt          hexutil.Bytes   `json:"receipt"` // Consensus encoding of the receipt
+	Proof            []hexutil.Bytes `json:"proof"`   // Receipt trie nodes on the path to the receipt
+}
+
+// Verify checks the proof against the header it carries, returning the receipt
+// proven. It does the checks of the receipt proof precompile, so that proofs the
+// inbox would refuse aren't relayed.
+func (p *ReceiptProof) Verify() (*types.Receipt, error) {
+	if crypto.Keccak256Hash(p.Header) != p.BlockHash {
+		return nil, errHeaderMismatch
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(p.Header, header); err != nil {
+		return nil, err
+	}
+	nodes := memorydb.New()
+	for _, node := range p.Proof {
+		nodes.Put(crypto.Keccak256(node), node)
+	}
+	key, _ := rlp.EncodeToBytes(uint(p.TransactionIndex))
+	value, err := trie.VerifyProof(header.ReceiptHash, key, nodes)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errReceiptMissing
+	}
+	if len(p.Receipt) > 0 && string(value) != string(p.Receipt) {
+		return nil, errReceiptMismatch
+	}
+	receipt := new(types.Receipt)
+	if err := receipt.UnmarshalBinary(value); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// LogProof verifies the proof and returns the input of the inbox delivering the
+// message with the given id, sent from the given outbox.
+func (p *ReceiptProof) LogProof(outbox common.Address, id common.Hash) (*vm.ReceiptLogProof, error) {
+	receipt, err := p.Verify()
+	if err != nil {
+		return nil, err
+	}
+	for i, log := range receipt.Logs {
+		if log.Address == outbox && len(log.Topics) > 1 && log.Topics[0] == xmsg.MessageTopic && log.Topics[1] == id {
+			nodes := make([][]byte, len(p.Proof))
+			for j, node := range p.Proof {
+				nodes[j] = node
+			}
+			return &vm.ReceiptLogProof{
+				Header:   p.Header,
+				Index:    uint64(p.TransactionIndex),
+				Nodes:    nodes,
+				LogIndex: uint64(i),
+			}, nil
+		}
+	}
+	return nil, errMessageMissing
+}
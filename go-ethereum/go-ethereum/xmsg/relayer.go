@@ -0,0 +1,263 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package xmsg implements the relayer shipping the messages sent from an outbox
+// on one chain to the inbox delivering them on another, along with the proofs of
+// the receipts holding them.
+//
+// Messages are picked up from the Message events of the outbox once their block
+// is confirmed, proven with eth_getReceiptProof on the source chain and delivered
+// through the inbox on the destination chain. The relayer can also act as the
+// admin of the inbox, feeding it the headers of the blocks holding the messages.
+// Relaying is idempotent: messages are checked against the consumed set of the
+// inbox, so any number of relayers may ship the same messages.
+package xmsg
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/xmsg"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Config is the configuration of a relayer.
+type Config struct {
+	Outbox        common.Address // Outbox on the source chain
+	Inbox         common.Address // Inbox on the destination chain
+	Confirmations uint64         // Blocks on top of a message before relaying it
+	FeedHeaders   bool           // Whether to feed the inbox the headers, as its admin
+	FromBlock     uint64         // Source block to start looking for messages at
+	PollInterval  time.Duration  // Interval between relaying rounds
+}
+
+// DefaultConfig is the default relayer configuration.
+var DefaultConfig = Config{
+	Confirmations: 12,
+	PollInterval:  15 * time.Second,
+}
+
+// message is a message waiting to be delivered.
+type message struct {
+	id     common.Hash
+	txHash common.Hash         // Source transaction sending the message
+	proof  *vm.ReceiptLogProof // Proof of the message, retrieved once
+	block  common.Hash         // Source block the message was sent in
+	sent   *common.Hash        // Pending delivery transaction
+}
+
+// Relayer ships the messages of an outbox to an inbox.
+type Relayer struct {
+	config Config
+	source Source
+	dest   Destination
+	opts   *bind.TransactOpts
+	inbox  *xmsg.Inbox
+
+	next    uint64                      // Next source block to look for messages in
+	queue   []*message                  // Messages waiting for delivery, in sending order
+	headers map[common.Hash]common.Hash // Pending header transactions by block hash
+}
+
+// New creates a relayer sending the transactions of the destination chain with
+// the given options.
+func New(config Config, source Source, dest Destination, opts *bind.TransactOpts) (*Relayer, error) {
+	inbox, err := xmsg.NewInbox(config.Inbox, dest)
+	if err != nil {
+		return nil, err
+	}
+	return &Relayer{
+		config:  config,
+		source:  source,
+		dest:    dest,
+		opts:    opts,
+		inbox:   inbox,
+		next:    config.FromBlock,
+		headers: make(map[common.Hash]common.Hash),
+	}, nil
+}
+
+// Pending returns the number of messages waiting for delivery.
+func (r *Relayer) Pending() int {
+	return len(r.queue)
+}
+
+// Run relays messages until the context is cancelled.
+func (r *Relayer) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Step(ctx); err != nil {
+			log.Warn("Failed to relay messages", "err", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Step does a round of relaying: it picks up the messages sent in the blocks
+// confirmed since the last round, and attempts to deliver all the messages not
+// delivered yet. Messages failing delivery are retried in the next round.
+func (r *Relayer) Step(ctx context.Context) error {
+	if err := r.collect(ctx); err != nil {
+		return err
+	}
+	queue := r.queue[:0]
+	for _, msg := range r.queue {
+		done, err := r.relay(ctx, msg)
+		if err != nil {
+			log.Warn("Failed to relay message", "id", msg.id, "tx", msg.txHash, "err", err)
+		}
+		if !done {
+			queue = append(queue, msg)
+		}
+	}
+	r.queue = queue
+	return nil
+}
+
+// collect queues the messages to the inbox sent in the blocks confirmed since the
+// last round.
+func (r *Relayer) collect(ctx context.Context) error {
+	head, err := r.source.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if head.Number.Uint64() < r.next+r.config.Confirmations {
+		return nil
+	}
+	last := head.Number.Uint64() - r.config.Confirmations
+
+	logs, err := r.source.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(r.next),
+		ToBlock:   new(big.Int).SetUint64(last),
+		Addresses: []common.Address{r.config.Outbox},
+		Topics:    [][]common.Hash{{xmsg.MessageTopic}, nil, {r.config.Inbox.Hash()}},
+	})
+	if err != nil {
+		return err
+	}
+	for _, l := range logs {
+		if l.Removed || len(l.Topics) != 4 {
+			continue
+		}
+		r.queue = append(r.queue, &message{id: l.Topics[1], txHash: l.TxHash, block: l.BlockHash})
+		log.Debug("Queued cross-chain message", "id", l.Topics[1], "block", l.BlockNumber)
+	}
+	r.next = last + 1
+	return nil
+}
+
+// relay advances the delivery of a message, reporting whether it's done with.
+func (r *Relayer) relay(ctx context.Context, msg *message) (bool, error) {
+	call := &bind.CallOpts{Context: ctx}
+	consumed, err := r.inbox.Contract().Consumed(call, msg.id)
+	if err != nil {
+		return false, err
+	}
+	if consumed {
+		log.Info("Delivered cross-chain message", "id", msg.id)
+		return true, nil
+	}
+	// Wait for any delivery sent, retrying it once failed
+	if msg.sent != nil {
+		receipt, err := r.dest.TransactionReceipt(ctx, *msg.sent)
+		if err != nil && err != ethereum.NotFound {
+			return false, err
+		}
+		if receipt == nil {
+			return false, nil
+		}
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			// Delivered, though not yet visible to the calls above
+			return false, nil
+		}
+		log.Warn("Cross-chain message delivery failed", "id", msg.id, "tx", *msg.sent)
+		msg.sent = nil
+	}
+	if msg.proof == nil {
+		proof, err := r.source.ReceiptProof(ctx, msg.txHash)
+		if err != nil {
+			return false, err
+		}
+		if proof.BlockHash != msg.block {
+			// Reorged away since the message was picked up
+			log.Warn("Dropped cross-chain message of reorged block", "id", msg.id, "block", msg.block)
+			return true, nil
+		}
+		if msg.proof, err = proof.LogProof(r.config.Outbox, msg.id); err != nil {
+			return false, err
+		}
+	}
+	// Make sure the inbox knows the block of the message
+	known, err := r.inbox.Contract().Known(call, msg.block)
+	if err != nil {
+		return false, err
+	}
+	if !known {
+		if r.config.FeedHeaders {
+			return false, r.feed(ctx, msg.block)
+		}
+		return false, nil
+	}
+	opts := *r.opts
+	opts.Context = ctx
+	tx, err := r.inbox.Deliver(&opts, msg.proof)
+	if err != nil {
+		return false, err
+	}
+	hash := tx.Hash()
+	msg.sent = &hash
+	log.Debug("Sent cross-chain message delivery", "id", msg.id, "tx", hash)
+	return false, nil
+}
+
+// feed adds a source block to the inbox, unless already being added.
+func (r *Relayer) feed(ctx context.Context, block common.Hash) error {
+	if hash, ok := r.headers[block]; ok {
+		receipt, err := r.dest.TransactionReceipt(ctx, hash)
+		if err != nil && err != ethereum.NotFound {
+			return err
+		}
+		if receipt == nil {
+			return nil
+		}
+		delete(r.headers, block)
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			return nil
+		}
+		log.Warn("Failed to add header to inbox", "block", block, "tx", hash)
+	}
+	opts := *r.opts
+	opts.Context = ctx
+	tx, err := r.inbox.Contract().AddHeader(&opts, block)
+	if err != nil {
+		return err
+	}
+	r.headers[block] = tx.Hash()
+	log.Debug("Sent header to inbox", "block", block, "tx", tx.Hash())
+	return nil
+}
@@ -0,0 +1,257 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package xmsg
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/xmsg"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/xcall"
+)
+
+var (
+	keyA, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	keyB, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	addrA   = crypto.PubkeyToAddress(keyA.PublicKey)
+	addrB   = crypto.PubkeyToAddress(keyB.PublicKey)
+
+	// recorderCode logs its calldata under the topic of its caller.
+	recorderCode = common.FromHex("61000c80600c6000396000f336600060003733366000a100")
+)
+
+// testChain is a simulated chain serving receipt proofs.
+type testChain struct {
+	*backends.SimulatedBackend
+	id *big.Int
+}
+
+func newTestChain(id int64) *testChain {
+	config := *params.AllEthashProtocolChanges
+	config.ChainID = big.NewInt(id)
+
+	funds := new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+	sim := backends.NewSimulatedBackendWithConfig(rawdb.NewMemoryDatabase(), &config, core.GenesisAlloc{
+		addrA: {Balance: funds},
+		addrB: {Balance: funds},
+	}, 10000000)
+	return &testChain{SimulatedBackend: sim, id: config.ChainID}
+}
+
+func (c *testChain) transactor(key *ecdsa.PrivateKey) *bind.TransactOpts {
+	opts, _ := bind.NewKeyedTransactorWithChainID(key, c.id)
+	return opts
+}
+
+// ReceiptProof proves a receipt the way eth_getReceiptProof does.
+func (c *testChain) ReceiptProof(ctx context.Context, txHash common.Hash) (*ReceiptProof, error) {
+	receipt, err := c.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := xcall.ProveReceipt(ctx, c, receipt)
+	if err != nil {
+		return nil, err
+	}
+	header, _ := c.HeaderByHash(ctx, receipt.BlockHash)
+	enc, _ := rlp.EncodeToBytes(header)
+	blob, _ := receipt.MarshalBinary()
+
+	nodes := make([]hexutil.Bytes, len(proof.Nodes))
+	for i, node := range proof.Nodes {
+		nodes[i] = hexutil.Bytes(node)
+	}
+	return &ReceiptProof{
+		BlockHash:        receipt.BlockHash,
+		BlockNumber:      hexutil.Uint64(receipt.BlockNumber.Uint64()),
+		TransactionHash:  txHash,
+		TransactionIndex: hexutil.Uint64(receipt.TransactionIndex),
+		Header:           enc,
+		Receipt:          blob,
+		Proof:            nodes,
+	}, nil
+}
+
+// relayTester relays messages between two simulated chains, A being the admin of
+// the inbox and relaying, B sending the messages.
+type relayTester struct {
+	t        *testing.T
+	src, dst *testChain
+	outbox   *xmsg.Outbox
+	inbox    *xmsg.Inbox
+	recorder common.Address
+}
+
+func newRelayTester(t *testing.T) *relayTester {
+	tt := &relayTester{t: t, src: newTestChain(1), dst: newTestChain(2)}
+
+	outbox, _, err := xmsg.DeployOutbox(tt.src.transactor(keyA), tt.src)
+	if err != nil {
+		t.Fatalf("failed to deploy outbox: %v", err)
+	}
+	tt.src.Commit()
+	inbox, _, err := xmsg.DeployInbox(tt.dst.transactor(keyA), tt.dst, outbox.Address())
+	if err != nil {
+		t.Fatalf("failed to deploy inbox: %v", err)
+	}
+	tt.dst.Commit()
+	tt.outbox, tt.inbox = outbox, inbox
+
+	ctx := context.Background()
+	nonce, _ := tt.dst.PendingNonceAt(ctx, addrA)
+	tx, _ := types.SignTx(types.NewContractCreation(nonce, new(big.Int), 100000, big.NewInt(1), recorderCode), types.NewEIP155Signer(tt.dst.id), keyA)
+	if err := tt.dst.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("failed to deploy recorder: %v", err)
+	}
+	tt.dst.Commit()
+	tt.recorder = crypto.CreateAddress(addrA, nonce)
+	return tt
+}
+
+func (tt *relayTester) close() {
+	tt.src.Close()
+	tt.dst.Close()
+}
+
+func (tt *relayTester) relayer(confirmations uint64, feed bool) *Relayer {
+	r, err := New(Config{
+		Outbox:        tt.outbox.Address(),
+		Inbox:         tt.inbox.Address(),
+		Confirmations: confirmations,
+		FeedHeaders:   feed,
+	}, tt.src, tt.dst, tt.dst.transactor(keyA))
+	if err != nil {
+		tt.t.Fatalf("failed to create relayer: %v", err)
+	}
+	return r
+}
+
+// send sends a message from B to the given inbox, returning its id.
+func (tt *relayTester) send(inbox common.Address, data []byte) common.Hash {
+	tx, err := tt.outbox.Send(tt.src.transactor(keyB), tt.dst.id, inbox, tt.recorder, data)
+	if err != nil {
+		tt.t.Fatalf("failed to send message: %v", err)
+	}
+	tt.src.Commit()
+	receipt, _ := tt.src.TransactionReceipt(context.Background(), tx.Hash())
+	return tt.outbox.LookupMessages(receipt.Logs)[0].Id
+}
+
+// step runs a round of relaying and mines its transactions.
+func (tt *relayTester) step(r *Relayer) {
+	if err := r.Step(context.Background()); err != nil {
+		tt.t.Fatalf("failed to relay: %v", err)
+	}
+	tt.dst.Commit()
+}
+
+func (tt *relayTester) consumed(id common.Hash) bool {
+	consumed, err := tt.inbox.Contract().Consumed(nil, id)
+	if err != nil {
+		tt.t.Fatalf("failed to retrieve consumed status: %v", err)
+	}
+	return consumed
+}
+
+// Tests that the relayer delivers the confirmed messages to the inbox, feeding
+// it the headers of their blocks.
+func TestRelay(t *testing.T) {
+	tt := newRelayTester(t)
+	defer tt.close()
+
+	r := tt.relayer(2, true)
+	first := tt.send(tt.inbox.Address(), []byte("first"))
+	second := tt.send(tt.inbox.Address(), []byte("second"))
+	other := tt.send(addrB, []byte("other inbox"))
+
+	// The second message isn't confirmed yet
+	tt.step(r)
+	if r.Pending() != 1 {
+		t.Fatalf("pending mismatch: have %d, want 1", r.Pending())
+	}
+	tt.step(r) // header fed
+	tt.step(r) // delivered
+	if !tt.consumed(first) {
+		t.Fatalf("first message not delivered")
+	}
+	tt.src.Commit()
+	for i := 0; i < 3; i++ {
+		tt.step(r)
+	}
+	if !tt.consumed(second) {
+		t.Fatalf("second message not delivered")
+	}
+	if tt.consumed(other) {
+		t.Errorf("delivered message to other inbox")
+	}
+	if r.Pending() != 0 {
+		t.Errorf("pending mismatch: have %d, want 0", r.Pending())
+	}
+	// The target got the messages with their senders appended
+	logs, err := tt.dst.FilterLogs(context.Background(), ethereum.FilterQuery{Addresses: []common.Address{tt.recorder}})
+	if err != nil {
+		t.Fatalf("failed to retrieve deliveries: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("delivery count mismatch: have %d, want 2", len(logs))
+	}
+	for i, data := range []string{"first", "second"} {
+		if want := append([]byte(data), addrB.Bytes()...); string(logs[i].Data) != string(want) {
+			t.Errorf("delivery %d: data mismatch: have %x, want %x", i, logs[i].Data, want)
+		}
+	}
+}
+
+// Tests that relayers not feeding headers wait for the inbox to know the blocks
+// of the messages, and that messages delivered by others are dropped.
+func TestRelayKnownHeaders(t *testing.T) {
+	tt := newRelayTester(t)
+	defer tt.close()
+
+	r, feeder := tt.relayer(0, false), tt.relayer(0, true)
+	id := tt.send(tt.inbox.Address(), []byte("message"))
+
+	for i := 0; i < 3; i++ {
+		tt.step(r)
+	}
+	if tt.consumed(id) || r.Pending() != 1 {
+		t.Fatalf("message delivered without known header")
+	}
+	for i := 0; i < 2; i++ {
+		tt.step(feeder)
+	}
+	if !tt.consumed(id) {
+		t.Fatalf("message not delivered")
+	}
+	tt.step(r)
+	if r.Pending() != 0 {
+		t.Errorf("pending mismatch: have %d, want 0", r.Pending())
+	}
+}
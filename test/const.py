@@ -1,6 +1,9 @@
 #!/usr/bin/env python3
 # -*- coding: utf-8 -*-
 #相应的所需要提供的信息，例如server的账户密码以及ip等
+import json
+import os
+import subprocess
 import threading
 
 USERNAME = ''  # username of servers
@@ -10,5 +13,20 @@ NODE_COUNT =  # 主机个数 * MAXPAYLOAD   #所有节点数:最大为主机数
 IP_CONFIG = 'ip.txt'  # server IPs
 SECONDS_IN_A_DAY = 60 * 60 * 24
 SEMAPHORE = threading.BoundedSemaphore(15)
-ABI = ''
-BIN = ''
+
+
+def compile_contract(source, name):
+    """Compiles a contract with solc, returning its ABI and deployment code."""
+    out = subprocess.check_output(['solc', '--combined-json', 'abi,bin', '--optimize', '--evm-version', 'istanbul', source])
+    contracts = json.loads(out)['contracts']
+    contract = next(c for key, c in contracts.items() if key.split(':')[-1] == name)
+    abi = contract['abi']
+    if isinstance(abi, str):  # solc before 0.8 encodes the ABI as a string
+        abi = json.loads(abi)
+    return abi, '0x' + contract['bin']
+
+
+# ABI/BIN of the contract under test, compiled from its source
+CONTRACT_SOURCE = os.path.normpath(os.path.join(os.path.dirname(os.path.abspath(__file__)),
+                                                '..', 'go-ethereum', 'go-ethereum', 'contracts', 'xchannel', 'contract', 'channel.sol'))
+ABI, BIN = compile_contract(CONTRACT_SOURCE, 'XChannel')